@@ -21,8 +21,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-logr/logr"
 	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,7 +34,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
+	"github.com/operator-framework/operator-sdk/internal/fieldcache"
 	"github.com/operator-framework/operator-sdk/internal/log/zap"
+	"github.com/operator-framework/operator-sdk/internal/ratelimiter"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/controller"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/flags"
@@ -40,6 +44,7 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/ansible/proxy/controllermap"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/runner"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/watches"
+	"github.com/operator-framework/operator-sdk/pkg/predicate"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 )
 
@@ -123,19 +128,49 @@ func main() {
 		options.Namespace = metav1.NamespaceAll
 	}
 
-	// Create a new manager to provide shared dependencies and start components
-	mgr, err := manager.New(cfg, options)
-	if err != nil {
-		log.Error(err, "Failed to create a new manager.")
+	if _, err := predicate.NewFieldFilterPredicate(f.WatchFieldSelector); err != nil {
+		log.Error(err, "Invalid --watch-field-selector.")
 		os.Exit(1)
 	}
 
-	cMap := controllermap.NewControllerMap()
 	watches, err := watches.Load(f.WatchesFile, f.MaxConcurrentReconciles, f.AnsibleVerbosity)
 	if err != nil {
 		log.Error(err, "Failed to load watches.")
 		os.Exit(1)
 	}
+
+	// A watch's fieldSelector overrides --watch-field-selector. The cache only
+	// lists and watches custom resources matching their kind's field selector.
+	fieldSelectors := map[schema.GroupVersionKind]string{}
+	for _, w := range watches {
+		fieldSelector := w.FieldSelector
+		if fieldSelector == "" {
+			fieldSelector = f.WatchFieldSelector
+		}
+		if fieldSelector != "" {
+			fieldSelectors[w.GroupVersionKind] = fieldSelector
+		}
+	}
+	if len(fieldSelectors) != 0 {
+		newCache := options.NewCache
+		if newCache == nil {
+			newCache = cache.New
+		}
+		var namespaces []string
+		if strings.Contains(namespace, ",") {
+			namespaces = strings.Split(namespace, ",")
+		}
+		options.NewCache = fieldcache.NewCacheFunc(newCache, namespaces, fieldSelectors)
+	}
+
+	// Create a new manager to provide shared dependencies and start components
+	mgr, err := manager.New(cfg, options)
+	if err != nil {
+		log.Error(err, "Failed to create a new manager.")
+		os.Exit(1)
+	}
+
+	cMap := controllermap.NewControllerMap()
 	for _, w := range watches {
 		runner, err := runner.New(w)
 		if err != nil {
@@ -143,6 +178,23 @@ func main() {
 			os.Exit(1)
 		}
 
+		// A watch's logLevel, if set, overrides --zap-level for its controller.
+		var logger logr.Logger
+		if w.LogLevel != "" {
+			lvl, err := zap.ParseLevel(w.LogLevel)
+			if err != nil {
+				log.Error(err, "Invalid log level", "GVK", w.GroupVersionKind.String())
+				os.Exit(1)
+			}
+			logger = zap.LoggerWithLevel(lvl)
+		}
+
+		rateLimiter, err := ratelimiter.New(f.ReconcileQPS, f.ReconcileBurst)
+		if err != nil {
+			log.Error(err, "Invalid --reconcile-qps or --reconcile-burst.")
+			os.Exit(1)
+		}
+
 		ctr := controller.Add(mgr, controller.Options{
 			GVK:                     w.GroupVersionKind,
 			Runner:                  runner,
@@ -151,16 +203,26 @@ func main() {
 			MaxConcurrentReconciles: w.MaxConcurrentReconciles,
 			ReconcilePeriod:         w.ReconcilePeriod,
 			Selector:                w.Selector,
+			FieldSelector:           fieldSelectors[w.GroupVersionKind],
+			SecondaryWatches:        w.SecondaryWatches,
+			Logger:                  logger,
+			RateLimiter:             rateLimiter,
 		})
 		if ctr == nil {
 			log.Error(fmt.Errorf("failed to add controller for GVK %v", w.GroupVersionKind.String()), "")
 			os.Exit(1)
 		}
 
+		// Secondary watches are already owner-mapped, so the proxy must not
+		// add another watch for the same resources.
+		owMap := controllermap.NewWatchMap()
+		for _, gvk := range w.SecondaryWatches {
+			owMap.Store(gvk)
+		}
 		cMap.Store(w.GroupVersionKind, &controllermap.Contents{Controller: *ctr,
 			WatchDependentResources:     w.WatchDependentResources,
 			WatchClusterScopedResources: w.WatchClusterScopedResources,
-			OwnerWatchMap:               controllermap.NewWatchMap(),
+			OwnerWatchMap:               owMap,
 			AnnotationWatchMap:          controllermap.NewWatchMap(),
 		}, w.Blacklist)
 	}
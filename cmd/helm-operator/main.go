@@ -22,6 +22,7 @@ import (
 
 	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,12 +31,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
+	"github.com/operator-framework/operator-sdk/internal/fieldcache"
 	"github.com/operator-framework/operator-sdk/internal/log/zap"
+	"github.com/operator-framework/operator-sdk/internal/ratelimiter"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/helm/controller"
 	"github.com/operator-framework/operator-sdk/pkg/helm/flags"
 	"github.com/operator-framework/operator-sdk/pkg/helm/release"
 	"github.com/operator-framework/operator-sdk/pkg/helm/watches"
+	"github.com/operator-framework/operator-sdk/pkg/predicate"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 )
 
@@ -112,9 +116,8 @@ func main() {
 		options.Namespace = metav1.NamespaceAll
 	}
 
-	mgr, err := manager.New(cfg, options)
-	if err != nil {
-		log.Error(err, "Failed to create a new manager.")
+	if _, err := predicate.NewFieldFilterPredicate(f.WatchFieldSelector); err != nil {
+		log.Error(err, "Invalid --watch-field-selector.")
 		os.Exit(1)
 	}
 
@@ -123,16 +126,57 @@ func main() {
 		log.Error(err, "Failed to create new manager factories.")
 		os.Exit(1)
 	}
+
+	// A watch's fieldSelector overrides --watch-field-selector. The cache only
+	// lists and watches custom resources matching their kind's field selector.
+	fieldSelectors := map[schema.GroupVersionKind]string{}
+	for _, w := range ws {
+		fieldSelector := w.FieldSelector
+		if fieldSelector == "" {
+			fieldSelector = f.WatchFieldSelector
+		}
+		if fieldSelector != "" {
+			fieldSelectors[w.GroupVersionKind] = fieldSelector
+		}
+	}
+	if len(fieldSelectors) != 0 {
+		newCache := options.NewCache
+		if newCache == nil {
+			newCache = cache.New
+		}
+		var namespaces []string
+		if strings.Contains(namespace, ",") {
+			namespaces = strings.Split(namespace, ",")
+		}
+		options.NewCache = fieldcache.NewCacheFunc(newCache, namespaces, fieldSelectors)
+	}
+
+	mgr, err := manager.New(cfg, options)
+	if err != nil {
+		log.Error(err, "Failed to create a new manager.")
+		os.Exit(1)
+	}
+
 	for _, w := range ws {
+		rateLimiter, err := ratelimiter.New(f.ReconcileQPS, f.ReconcileBurst)
+		if err != nil {
+			log.Error(err, "Invalid --reconcile-qps or --reconcile-burst.")
+			os.Exit(1)
+		}
+
 		// Register the controller with the factory.
-		err := controller.Add(mgr, controller.WatchOptions{
+		err = controller.Add(mgr, controller.WatchOptions{
 			Namespace:               namespace,
 			GVK:                     w.GroupVersionKind,
 			ManagerFactory:          release.NewManagerFactory(mgr, w.ChartDir),
 			ReconcilePeriod:         f.ReconcilePeriod,
 			WatchDependentResources: *w.WatchDependentResources,
 			OverrideValues:          w.OverrideValues,
+			StatusFields:            w.StatusFields,
 			MaxConcurrentReconciles: f.MaxConcurrentReconciles,
+			FieldSelector:           fieldSelectors[w.GroupVersionKind],
+			ImmutableValues:         w.ImmutableValues,
+			RateLimiter:             rateLimiter,
 		})
 		if err != nil {
 			log.Error(err, "Failed to add manager factory to controller.")
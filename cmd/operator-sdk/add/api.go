@@ -76,28 +76,17 @@ is generated as a 'validation' object.`,
 
 func apiRun(cmd *cobra.Command, args []string) error {
 
-	projutil.MustInProjectRoot()
+	projutil.MustInProjectRootWithType(projutil.OperatorTypeAnsible)
 
-	operatorType := projutil.GetOperatorType()
-	if operatorType == projutil.OperatorTypeUnknown {
-		return projutil.ErrUnknownOperatorType{}
-	}
 	// Verify the incoming flags.
-	if err := apiFlags.VerifyCommonFlags(operatorType); err != nil {
+	if err := apiFlags.VerifyCommonFlags(projutil.OperatorTypeAnsible); err != nil {
 		return err
 	}
 
 	log.Infof("Generating api version %s for kind %s.", apiFlags.APIVersion, apiFlags.Kind)
 
-	switch operatorType {
-	case projutil.OperatorTypeGo:
-		return fmt.Errorf("the `add api` command is not supported for Go operators")
-	case projutil.OperatorTypeHelm:
-		return fmt.Errorf("the `add api` command is not supported for Helm operators")
-	case projutil.OperatorTypeAnsible:
-		if err := doAnsibleAPIScaffold(); err != nil {
-			return err
-		}
+	if err := doAnsibleAPIScaffold(); err != nil {
+		return err
 	}
 	log.Info("API generation complete.")
 	return nil
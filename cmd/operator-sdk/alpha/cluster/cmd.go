@@ -0,0 +1,36 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCmd returns the 'cluster' command, which manages local test clusters.
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Manage local kind clusters with OLM installed for testing operators",
+		Long: `The 'operator-sdk alpha cluster' command creates and deletes local kind clusters
+with the Operator Lifecycle Manager (OLM) installed, for testing operators.
+The 'kind' binary must be installed, see https://kind.sigs.k8s.io.`,
+	}
+
+	cmd.AddCommand(
+		newCreateCmd(),
+		newDeleteCmd(),
+	)
+	return cmd
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm"
+	"github.com/operator-framework/operator-sdk/internal/util/kindutil"
+)
+
+const createExamples = `
+  # Create a cluster named "operator-sdk" with the latest OLM version:
+  $ operator-sdk alpha cluster create
+
+  # Create a cluster named "test" running Kubernetes v1.19.1 with OLM v0.16.1:
+  $ operator-sdk alpha cluster create --name test --kubernetes-version v1.19.1 --olm-version 0.16.1
+`
+
+type createCmd struct {
+	name string
+	opts kindutil.CreateOptions
+	mgr  *olm.Manager
+}
+
+func newCreateCmd() *cobra.Command {
+	c := &createCmd{mgr: &olm.Manager{}}
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a kind cluster and install OLM in it",
+		Long: `Running 'alpha cluster create' creates a kind cluster, installs OLM in it,
+and prints the kubeconfig context to use the cluster with. kind sets the current
+context of your kubeconfig to the new cluster's context.`,
+		Example: createExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+
+			if err := c.run(); err != nil {
+				log.Fatal(err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.name, "name", kindutil.DefaultClusterName, "Name of the cluster")
+	fs.StringVar(&c.opts.KubernetesVersion, "kubernetes-version", "",
+		"Kubernetes version of the cluster, ex. v1.19.1. Defaults to the kind version's default")
+	fs.StringVar(&c.opts.Wait, "wait", kindutil.DefaultWait, "Time to wait for the cluster's control plane to be ready")
+	fs.StringVar(&c.mgr.Version, "olm-version", olm.DefaultVersion, "Version of OLM resources to install")
+	fs.StringVar(&c.mgr.OLMNamespace, "olm-namespace", olm.DefaultOLMNamespace, "Namespace where OLM is to be installed")
+	c.mgr.AddToFlagSet(fs)
+
+	return cmd
+}
+
+func (c createCmd) run() error {
+	log.Infof("Creating cluster %q", c.name)
+	if err := kindutil.CreateCluster(c.name, c.opts); err != nil {
+		return fmt.Errorf("error creating cluster %q: %v", c.name, err)
+	}
+
+	// Install OLM with the new cluster's context, regardless of the current context.
+	cfg, err := kindutil.RESTConfig(c.name)
+	if err != nil {
+		return fmt.Errorf("error getting config for cluster %q: %v", c.name, err)
+	}
+	if c.mgr.Client, err = olm.ClientForConfig(cfg); err != nil {
+		return fmt.Errorf("error creating client for cluster %q: %v", c.name, err)
+	}
+	if err := c.mgr.Install(); err != nil {
+		return fmt.Errorf("error installing OLM version %q in cluster %q: %v "+
+			"(run 'operator-sdk alpha cluster delete --name %s' to delete the cluster)", c.mgr.Version, c.name, err, c.name)
+	}
+
+	ctx := kindutil.KubeContext(c.name)
+	fmt.Printf("\nCluster %q is ready. Use it with kubeconfig context %q, ex.\n\n", c.name, ctx)
+	fmt.Printf("  $ kubectl config use-context %s\n", ctx)
+	return nil
+}
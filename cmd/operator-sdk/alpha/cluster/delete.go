@@ -0,0 +1,50 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/util/kindutil"
+)
+
+func newDeleteCmd() *cobra.Command {
+	var name string
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a kind cluster created by 'alpha cluster create'",
+		Example: `
+  # Delete the cluster named "test":
+  $ operator-sdk alpha cluster delete --name test
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+
+			log.Infof("Deleting cluster %q", name)
+			if err := kindutil.DeleteCluster(name); err != nil {
+				log.Fatalf("Error deleting cluster %q: %v", name, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", kindutil.DefaultClusterName, "Name of the cluster")
+	return cmd
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alpha
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/alpha/cluster"
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/alpha/csvfromcluster"
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/alpha/listapis"
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/alpha/migratelayout"
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/alpha/releasenotes"
+)
+
+// NewCmd returns the 'alpha' command, which groups experimental subcommands.
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alpha",
+		Short: "Run an alpha subcommand",
+		Long: `The 'operator-sdk alpha' command groups subcommands that are experimental.
+Their behavior, flags, and output may change between releases.`,
+	}
+
+	cmd.AddCommand(
+		cluster.NewCmd(),
+		csvfromcluster.NewCmd(),
+		listapis.NewCmd(),
+		migratelayout.NewCmd(),
+		releasenotes.NewCmd(),
+	)
+	return cmd
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvfromcluster
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const longHelp = `
+Running 'alpha csv-from-cluster' reads an operator's live Deployment, the ServiceAccount its pods run as,
+and the Roles and ClusterRoles bound to that ServiceAccount, and writes a draft ClusterServiceVersion
+with an install strategy built from them. Use it as a starting point to package an operator that was not
+scaffolded by the SDK, ex. in a 'config/manifests/bases' CSV.
+
+Rules granted by RoleBindings in '--namespace' become the CSV's 'permissions', and rules granted by
+ClusterRoleBindings become its 'clusterPermissions'. UI metadata, ex. the provider, maintainers, and
+description, cannot be read from the cluster; these fields are set to placeholder values, listed in a
+comment at the top of the output, which must be completed manually. 'operator-sdk bundle validate'
+warns about placeholders left in a bundle.
+`
+
+const examples = `
+  # Write a draft CSV for the memcached-operator-controller-manager Deployment to stdout:
+  $ operator-sdk alpha csv-from-cluster --namespace memcached-operator-system \
+      --deployment memcached-operator-controller-manager
+
+  # Write it to a kustomize base, naming the operator:
+  $ operator-sdk alpha csv-from-cluster --namespace memcached-operator-system \
+      --deployment memcached-operator-controller-manager \
+      --operator-name memcached-operator \
+      --output-file config/manifests/bases/memcached-operator.clusterserviceversion.yaml
+`
+
+type csvFromClusterCmd struct {
+	kubeconfig   string
+	namespace    string
+	deployment   string
+	operatorName string
+	outputFile   string
+}
+
+// NewCmd returns the 'csv-from-cluster' command.
+func NewCmd() *cobra.Command {
+	c := &csvFromClusterCmd{}
+	cmd := &cobra.Command{
+		Use:     "csv-from-cluster",
+		Short:   "Generates a draft ClusterServiceVersion from an operator's live Deployment and RBAC",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+
+			if err := c.validate(); err != nil {
+				return fmt.Errorf("invalid command options: %v", err)
+			}
+
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating ClusterServiceVersion: %v", err)
+			}
+
+			return nil
+		},
+	}
+
+	c.addFlagsTo(cmd.Flags())
+
+	return cmd
+}
+
+func (c *csvFromClusterCmd) addFlagsTo(fs *pflag.FlagSet) {
+	fs.StringVar(&c.kubeconfig, "kubeconfig", "", "The file path to kubernetes configuration file. "+
+		"Defaults to location specified by $KUBECONFIG, or to default file rules if not set")
+	fs.StringVar(&c.namespace, "namespace", "", "Namespace of the operator's Deployment. "+
+		"Defaults to the kubeconfig context's namespace")
+	fs.StringVar(&c.deployment, "deployment", "", "Name of the operator's Deployment (required)")
+	fs.StringVar(&c.operatorName, "operator-name", "", "Name of the operator, used in the CSV's name. "+
+		"Defaults to the Deployment's name")
+	fs.StringVar(&c.outputFile, "output-file", "", "File to write the ClusterServiceVersion to. Defaults to stdout")
+}
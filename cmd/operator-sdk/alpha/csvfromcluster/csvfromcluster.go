@@ -0,0 +1,245 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvfromcluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion/bases"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// manualFields are the CSV fields set to placeholder values by the default base,
+// which cannot be read from the cluster.
+var manualFields = []string{
+	"metadata.name",
+	"metadata.annotations.alm-examples",
+	"metadata.annotations.capabilities",
+	"spec.version",
+	"spec.description",
+	"spec.displayName",
+	"spec.icon",
+	"spec.installModes",
+	"spec.keywords",
+	"spec.links",
+	"spec.maintainers",
+	"spec.maturity",
+	"spec.provider",
+	"spec.customresourcedefinitions",
+}
+
+func (c csvFromClusterCmd) validate() error {
+	if c.deployment == "" {
+		return errors.New("--deployment must be set")
+	}
+	if errs := validation.NameIsDNSSubdomain(c.deployment, false); len(errs) != 0 {
+		return fmt.Errorf("invalid --deployment %q: %s", c.deployment, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (c csvFromClusterCmd) run() error {
+	cfg, namespace, err := k8sutil.GetKubeconfigAndNamespace(c.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("error getting kubeconfig: %v", err)
+	}
+	if c.namespace != "" {
+		namespace = c.namespace
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating client: %v", err)
+	}
+
+	operatorName := c.operatorName
+	if operatorName == "" {
+		operatorName = c.deployment
+	}
+	csv, err := csvFromCluster(context.TODO(), client, namespace, c.deployment, operatorName)
+	if err != nil {
+		return err
+	}
+	b, err := marshalDraft(csv, namespace, c.deployment)
+	if err != nil {
+		return err
+	}
+
+	if c.outputFile == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return ioutil.WriteFile(c.outputFile, b, 0644)
+}
+
+// csvFromCluster returns a draft CSV for operatorName, with an install strategy built from the Deployment
+// named deployment in namespace and the rules bound to its pods' ServiceAccount. All other fields are
+// set to the default base's placeholder values.
+func csvFromCluster(ctx context.Context, client kubernetes.Interface, namespace, deployment,
+	operatorName string) (*v1alpha1.ClusterServiceVersion, error) {
+	dep, err := client.AppsV1().Deployments(namespace).Get(ctx, deployment, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting Deployment %s/%s: %v", namespace, deployment, err)
+	}
+	saName := dep.Spec.Template.Spec.ServiceAccountName
+	if saName == "" {
+		saName = "default"
+	}
+	if _, err := client.CoreV1().ServiceAccounts(namespace).Get(ctx, saName, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("error getting ServiceAccount %s/%s of Deployment %s: %v", namespace, saName, deployment, err)
+	}
+	rules, clusterRules, err := getBoundRules(ctx, client, namespace, saName)
+	if err != nil {
+		return nil, err
+	}
+
+	csv, err := bases.ClusterServiceVersion{OperatorName: operatorName}.GetBase()
+	if err != nil {
+		return nil, fmt.Errorf("error getting ClusterServiceVersion base: %v", err)
+	}
+	strategy := &csv.Spec.InstallStrategy
+	strategy.StrategyName = v1alpha1.InstallStrategyNameDeployment
+	strategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{
+		{Name: dep.GetName(), Spec: dep.Spec},
+	}
+	if len(rules) != 0 {
+		strategy.StrategySpec.Permissions = []v1alpha1.StrategyDeploymentPermissions{
+			{ServiceAccountName: saName, Rules: rules},
+		}
+	}
+	if len(clusterRules) != 0 {
+		strategy.StrategySpec.ClusterPermissions = []v1alpha1.StrategyDeploymentPermissions{
+			{ServiceAccountName: saName, Rules: clusterRules},
+		}
+	}
+	return csv, nil
+}
+
+// getBoundRules returns the rules of the Roles and ClusterRoles bound to the ServiceAccount saName in namespace
+// by a RoleBinding in namespace, and those of the ClusterRoles bound to it by a ClusterRoleBinding.
+// Bindings are read in name order, and bindings to roles that do not exist are skipped, since they grant nothing.
+func getBoundRules(ctx context.Context, client kubernetes.Interface, namespace,
+	saName string) (rules, clusterRules []rbacv1.PolicyRule, err error) {
+	roleBindings, err := client.RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing RoleBindings in namespace %s: %v", namespace, err)
+	}
+	sort.Slice(roleBindings.Items, func(i, j int) bool {
+		return roleBindings.Items[i].GetName() < roleBindings.Items[j].GetName()
+	})
+	for _, binding := range roleBindings.Items {
+		if !bindsServiceAccount(binding.Subjects, namespace, namespace, saName) {
+			continue
+		}
+		roleRules, err := getRoleRules(ctx, client, namespace, binding.RoleRef)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting roleRef of RoleBinding %s/%s: %v", namespace, binding.GetName(), err)
+		}
+		rules = append(rules, roleRules...)
+	}
+
+	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing ClusterRoleBindings: %v", err)
+	}
+	sort.Slice(clusterRoleBindings.Items, func(i, j int) bool {
+		return clusterRoleBindings.Items[i].GetName() < clusterRoleBindings.Items[j].GetName()
+	})
+	for _, binding := range clusterRoleBindings.Items {
+		if !bindsServiceAccount(binding.Subjects, "", namespace, saName) {
+			continue
+		}
+		roleRules, err := getRoleRules(ctx, client, namespace, binding.RoleRef)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting roleRef of ClusterRoleBinding %s: %v", binding.GetName(), err)
+		}
+		clusterRules = append(clusterRules, roleRules...)
+	}
+	return rules, clusterRules, nil
+}
+
+// bindsServiceAccount returns true if subjects contain the ServiceAccount saName in namespace.
+// A subject without a namespace is in defaultNamespace, which is the namespace of a RoleBinding
+// and empty for a ClusterRoleBinding, whose ServiceAccount subjects must have a namespace.
+func bindsServiceAccount(subjects []rbacv1.Subject, defaultNamespace, namespace, saName string) bool {
+	for _, subject := range subjects {
+		subjectNamespace := subject.Namespace
+		if subjectNamespace == "" {
+			subjectNamespace = defaultNamespace
+		}
+		if subject.Kind == rbacv1.ServiceAccountKind && subject.Name == saName && subjectNamespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// getRoleRules returns the rules of the Role in namespace or ClusterRole referenced by ref,
+// or no rules if it does not exist.
+func getRoleRules(ctx context.Context, client kubernetes.Interface, namespace string,
+	ref rbacv1.RoleRef) ([]rbacv1.PolicyRule, error) {
+	switch ref.Kind {
+	case "Role":
+		role, err := client.RbacV1().Roles(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		return role.Rules, nil
+	case "ClusterRole":
+		role, err := client.RbacV1().ClusterRoles().Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		return role.Rules, nil
+	}
+	return nil, fmt.Errorf("unknown roleRef kind %q", ref.Kind)
+}
+
+func ignoreNotFound(err error) error {
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// marshalDraft marshals csv, prefixed by a comment listing the fields to complete manually.
+func marshalDraft(csv *v1alpha1.ClusterServiceVersion, namespace, deployment string) ([]byte, error) {
+	b, err := yaml.Marshal(csv)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling ClusterServiceVersion: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "# Draft ClusterServiceVersion generated from Deployment %s/%s.\n", namespace, deployment)
+	fmt.Fprintln(buf, "# spec.install was read from the cluster. Complete these placeholder fields manually:")
+	for _, field := range manualFields {
+		fmt.Fprintf(buf, "#   - %s\n", field)
+	}
+	buf.Write(b)
+	return buf.Bytes(), nil
+}
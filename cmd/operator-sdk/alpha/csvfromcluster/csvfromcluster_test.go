@@ -0,0 +1,149 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvfromcluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const testNamespace = "memcached-operator-system"
+
+var (
+	leaderElectionRule = rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "create"}}
+	memcachedRule      = rbacv1.PolicyRule{APIGroups: []string{"cache.example.com"}, Resources: []string{"memcacheds"}, Verbs: []string{"*"}}
+	proxyRule          = rbacv1.PolicyRule{APIGroups: []string{"authentication.k8s.io"}, Resources: []string{"tokenreviews"}, Verbs: []string{"create"}}
+)
+
+func saSubject(namespace, name string) rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: name}
+}
+
+func newClusterObjects() []runtime.Object {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: testNamespace, Name: name}
+	}
+	return []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: meta("memcached-operator-controller-manager"),
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						ServiceAccountName: "controller-manager",
+						Containers:         []corev1.Container{{Name: "manager", Image: "quay.io/example/memcached-operator:v0.0.1"}},
+					},
+				},
+			},
+		},
+		&corev1.ServiceAccount{ObjectMeta: meta("controller-manager")},
+		&rbacv1.Role{ObjectMeta: meta("leader-election-role"), Rules: []rbacv1.PolicyRule{leaderElectionRule}},
+		&rbacv1.RoleBinding{
+			ObjectMeta: meta("leader-election-rolebinding"),
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "leader-election-role"},
+			Subjects:   []rbacv1.Subject{saSubject("", "controller-manager")},
+		},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "manager-role"}, Rules: []rbacv1.PolicyRule{memcachedRule}},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "manager-rolebinding"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "manager-role"},
+			Subjects:   []rbacv1.Subject{saSubject(testNamespace, "controller-manager")},
+		},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "proxy-role"}, Rules: []rbacv1.PolicyRule{proxyRule}},
+		// Binds a ClusterRole in the namespace only, so its rules are namespaced permissions.
+		&rbacv1.RoleBinding{
+			ObjectMeta: meta("proxy-rolebinding"),
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "proxy-role"},
+			Subjects:   []rbacv1.Subject{saSubject(testNamespace, "controller-manager")},
+		},
+		// Bindings for other ServiceAccounts and missing roles grant the operator nothing.
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-rolebinding"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "proxy-role"},
+			Subjects:   []rbacv1.Subject{saSubject("other", "controller-manager"), saSubject(testNamespace, "default")},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: meta("missing-rolebinding"),
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "missing-role"},
+			Subjects:   []rbacv1.Subject{saSubject(testNamespace, "controller-manager")},
+		},
+	}
+}
+
+func TestCSVFromCluster(t *testing.T) {
+	client := fake.NewSimpleClientset(newClusterObjects()...)
+	csv, err := csvFromCluster(context.TODO(), client, testNamespace, "memcached-operator-controller-manager", "memcached-operator")
+	require.NoError(t, err)
+
+	assert.Equal(t, "memcached-operator.vX.Y.Z", csv.GetName())
+	strategy := csv.Spec.InstallStrategy
+	assert.Equal(t, v1alpha1.InstallStrategyNameDeployment, strategy.StrategyName)
+	require.Len(t, strategy.StrategySpec.DeploymentSpecs, 1)
+	dep := strategy.StrategySpec.DeploymentSpecs[0]
+	assert.Equal(t, "memcached-operator-controller-manager", dep.Name)
+	assert.Equal(t, "quay.io/example/memcached-operator:v0.0.1", dep.Spec.Template.Spec.Containers[0].Image)
+
+	assert.Equal(t, []v1alpha1.StrategyDeploymentPermissions{
+		{ServiceAccountName: "controller-manager", Rules: []rbacv1.PolicyRule{leaderElectionRule, proxyRule}},
+	}, strategy.StrategySpec.Permissions)
+	assert.Equal(t, []v1alpha1.StrategyDeploymentPermissions{
+		{ServiceAccountName: "controller-manager", Rules: []rbacv1.PolicyRule{memcachedRule}},
+	}, strategy.StrategySpec.ClusterPermissions)
+}
+
+func TestCSVFromClusterErrors(t *testing.T) {
+	client := fake.NewSimpleClientset(newClusterObjects()...)
+	_, err := csvFromCluster(context.TODO(), client, testNamespace, "missing", "memcached-operator")
+	assert.Contains(t, err.Error(), "error getting Deployment memcached-operator-system/missing")
+
+	require.NoError(t, client.CoreV1().ServiceAccounts(testNamespace).Delete(context.TODO(), "controller-manager", metav1.DeleteOptions{}))
+	_, err = csvFromCluster(context.TODO(), client, testNamespace, "memcached-operator-controller-manager", "memcached-operator")
+	assert.Contains(t, err.Error(), "error getting ServiceAccount memcached-operator-system/controller-manager")
+}
+
+func TestMarshalDraft(t *testing.T) {
+	client := fake.NewSimpleClientset(newClusterObjects()...)
+	csv, err := csvFromCluster(context.TODO(), client, testNamespace, "memcached-operator-controller-manager", "memcached-operator")
+	require.NoError(t, err)
+	b, err := marshalDraft(csv, testNamespace, "memcached-operator-controller-manager")
+	require.NoError(t, err)
+
+	out := string(b)
+	assert.True(t, strings.HasPrefix(out, "# Draft ClusterServiceVersion generated from Deployment "+
+		"memcached-operator-system/memcached-operator-controller-manager.\n"))
+	for _, field := range manualFields {
+		assert.Contains(t, out, "#   - "+field+"\n")
+	}
+	decoded := v1alpha1.ClusterServiceVersion{}
+	require.NoError(t, yaml.Unmarshal(b, &decoded))
+	assert.Equal(t, csv.Spec.InstallStrategy, decoded.Spec.InstallStrategy)
+}
+
+func TestValidate(t *testing.T) {
+	assert.EqualError(t, csvFromClusterCmd{}.validate(), "--deployment must be set")
+	assert.Contains(t, csvFromClusterCmd{deployment: "Controller_Manager"}.validate().Error(), `invalid --deployment "Controller_Manager"`)
+	assert.NoError(t, csvFromClusterCmd{deployment: "controller-manager"}.validate())
+}
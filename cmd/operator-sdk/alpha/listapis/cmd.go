@@ -0,0 +1,117 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listapis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `
+Running 'alpha list-apis' in a project root prints the group, version, kind, and plural
+resource name of each API the project defines. APIs are read from the 'resources' in the
+PROJECT file, or, for legacy projects without one, from the API packages in 'pkg/apis' or
+'apis' laid out as '<group>/<version>'.
+`
+
+const examples = `
+  # Print the project's APIs as a table:
+  $ operator-sdk alpha list-apis
+  GROUP              VERSION   KIND        PLURAL
+  cache.example.com  v1alpha1  Memcached   memcacheds
+
+  # Print them as JSON:
+  $ operator-sdk alpha list-apis --output json
+`
+
+// Output formats.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type listAPIsCmd struct {
+	output string
+}
+
+// NewCmd returns the 'list-apis' command.
+func NewCmd() *cobra.Command {
+	c := &listAPIsCmd{}
+	cmd := &cobra.Command{
+		Use:     "list-apis",
+		Short:   "Lists the group, version, and kind of each API a project defines",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if c.output != outputTable && c.output != outputJSON {
+				return fmt.Errorf("invalid value for output flag: %q must be one of [%s, %s]",
+					c.output, outputTable, outputJSON)
+			}
+
+			projutil.MustInProjectRoot()
+			rs, err := projutil.ListAPIs()
+			if err != nil {
+				log.Fatalf("Error listing APIs: %v", err)
+			}
+			if err := c.print(os.Stdout, rs); err != nil {
+				log.Fatalf("Error printing APIs: %v", err)
+			}
+
+			return nil
+		},
+	}
+
+	c.addFlagsTo(cmd.Flags())
+
+	return cmd
+}
+
+func (c *listAPIsCmd) addFlagsTo(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.output, "output", "o", outputTable, "Output format. One of: [table, json]")
+}
+
+// print writes rs to w in c's output format.
+func (c listAPIsCmd) print(w io.Writer, rs []projutil.Resource) error {
+	if c.output == outputJSON {
+		if rs == nil {
+			rs = []projutil.Resource{}
+		}
+		b, err := json.MarshalIndent(rs, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tVERSION\tKIND\tPLURAL")
+	for _, r := range rs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Group, r.Version, r.Kind, r.Plural)
+	}
+	return tw.Flush()
+}
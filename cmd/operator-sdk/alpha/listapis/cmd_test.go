@@ -0,0 +1,57 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listapis
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+var testAPIs = []projutil.Resource{
+	{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached", Plural: "memcacheds"},
+	{Group: "ship.example.com", Version: "v1beta1", Kind: "Frigate", Plural: "frigates"},
+}
+
+func TestPrintTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, listAPIsCmd{output: outputTable}.print(&buf, testAPIs))
+	assert.Equal(t, "GROUP              VERSION   KIND       PLURAL\n"+
+		"cache.example.com  v1alpha1  Memcached  memcacheds\n"+
+		"ship.example.com   v1beta1   Frigate    frigates\n", buf.String())
+}
+
+func TestPrintJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, listAPIsCmd{output: outputJSON}.print(&buf, testAPIs[:1]))
+	assert.JSONEq(t, `[{"group":"cache.example.com","version":"v1alpha1","kind":"Memcached","plural":"memcacheds"}]`,
+		buf.String())
+
+	buf.Reset()
+	require.NoError(t, listAPIsCmd{output: outputJSON}.print(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestInvalidOutput(t *testing.T) {
+	cmd := NewCmd()
+	cmd.SetArgs([]string{"--output", "yaml"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	assert.EqualError(t, cmd.Execute(), `invalid value for output flag: "yaml" must be one of [table, json]`)
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migratelayout
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `
+Running 'alpha migrate-layout' converts a Go project using the legacy 'cmd/manager' and
+'build/Dockerfile' layout to the kubebuilder layout used by projects created with
+'operator-sdk init'. It must be run in the project root, and:
+
+  - Creates a PROJECT file from the project's API groups, versions, and kinds
+  - Moves APIs in 'pkg/apis/<group>/<version>' to 'api/<version>', or to
+    'apis/<group>/<version>' if the project has more than one API group
+  - Merges controllers in 'pkg/controller/<name>' into the 'controllers' package
+  - Updates import paths of moved packages in all Go files
+  - Moves CRDs, samples, RBAC, and the operator Deployment in 'deploy' into a kustomize
+    'config' directory structure
+
+Changes that cannot be made automatically, such as replacing 'cmd/manager/main.go', are
+reported as manual follow-ups.
+
+By default no files are changed: the planned changes and follow-ups are printed.
+Set '--dry-run=false' to make the changes.
+`
+
+const examples = `
+  # Print the changes that would convert the project to the kubebuilder layout:
+  $ operator-sdk alpha migrate-layout
+
+  # Make those changes:
+  $ operator-sdk alpha migrate-layout --dry-run=false
+`
+
+type migrateLayoutCmd struct {
+	repo   string
+	dryRun bool
+}
+
+// NewCmd returns the 'migrate-layout' command.
+func NewCmd() *cobra.Command {
+	c := &migrateLayoutCmd{}
+	cmd := &cobra.Command{
+		Use:     "migrate-layout",
+		Short:   "Converts a legacy Go operator project to the kubebuilder layout",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+
+			if err := c.validate(); err != nil {
+				return fmt.Errorf("invalid command options: %v", err)
+			}
+
+			if err := c.run(); err != nil {
+				log.Fatalf("Error migrating project layout: %v", err)
+			}
+
+			return nil
+		},
+	}
+
+	c.addFlagsTo(cmd.Flags())
+
+	return cmd
+}
+
+func (c *migrateLayoutCmd) addFlagsTo(fs *pflag.FlagSet) {
+	fs.StringVar(&c.repo, "repo", "", "Go module path of the project. Defaults to the module path in go.mod")
+	fs.BoolVar(&c.dryRun, "dry-run", true, "Print the planned changes without making them")
+}
+
+func (c *migrateLayoutCmd) validate() error {
+	if !projutil.IsLegacyOperatorGo() {
+		return errors.New("migrate-layout must be run in the root of a legacy Go project " +
+			"containing cmd/manager/main.go and no PROJECT file")
+	}
+	if c.repo == "" {
+		c.repo = projutil.GetGoPkg()
+	}
+	return nil
+}
+
+func (c migrateLayoutCmd) run() error {
+	m, err := planMigration(".", c.repo)
+	if err != nil {
+		return err
+	}
+	m.print(os.Stdout)
+
+	if c.dryRun {
+		fmt.Println("\nDry run: no files were changed. Set --dry-run=false to make these changes.")
+		return nil
+	}
+	if err := m.apply(); err != nil {
+		return err
+	}
+	fmt.Println("\nProject migrated to the kubebuilder layout. Complete the manual follow-ups above.")
+	return nil
+}
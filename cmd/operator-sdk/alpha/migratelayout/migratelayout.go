@@ -0,0 +1,532 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migratelayout
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+const (
+	// goLayout is the PROJECT layout of Go projects scaffolded by 'operator-sdk init'.
+	goLayout = "go.kubebuilder.io/v2"
+
+	legacyAPIsDir        = "pkg/apis"
+	legacyControllersDir = "pkg/controller"
+	legacyDeployDir      = "deploy"
+	legacyCRDsDir        = "deploy/crds"
+	legacyOperatorFile   = "deploy/operator.yaml"
+	legacyCatalogDir     = "deploy/olm-catalog"
+	legacyDockerfile     = "build/Dockerfile"
+	legacyMainFile       = "cmd/manager/main.go"
+
+	controllersPkg = "controllers"
+)
+
+var (
+	groupNameRe = regexp.MustCompile(`(?m)^// \+groupName=(\S+)`)
+	registerRe  = regexp.MustCompile(`SchemeBuilder\.Register\(([^)]*)\)`)
+	kindRe      = regexp.MustCompile(`&(\w+)\{\}`)
+	packageRe   = regexp.MustCompile(`(?m)^package \w+`)
+)
+
+// move relocates a file, optionally renaming its Go package.
+type move struct {
+	from, to string
+	// pkg, if set, replaces the file's package clause.
+	pkg string
+}
+
+// migration is the set of changes that convert a legacy Go project rooted at
+// root to the kubebuilder layout.
+type migration struct {
+	root string
+	repo string
+
+	config *config.Config
+	moves  []move
+	// files are created with their contents, keyed by project-relative path.
+	files map[string][]byte
+	// importPaths maps each legacy package import path to its new path.
+	importPaths map[string]string
+	// followUps are changes that must be made by hand.
+	followUps []string
+}
+
+// legacyAPI is an API group version in pkg/apis/<group>/<version>.
+type legacyAPI struct {
+	dir     string
+	group   string
+	version string
+	kinds   []string
+}
+
+// planMigration returns the migration of the legacy Go project rooted at root,
+// with Go module path repo. No files are modified.
+func planMigration(root, repo string) (*migration, error) {
+	m := &migration{
+		root:        root,
+		repo:        repo,
+		files:       make(map[string][]byte),
+		importPaths: make(map[string]string),
+	}
+
+	apis, err := findAPIs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.planAPIs(apis); err != nil {
+		return nil, err
+	}
+	if err := m.planControllers(); err != nil {
+		return nil, err
+	}
+	if err := m.planConfig(); err != nil {
+		return nil, err
+	}
+	if err := m.planFollowUps(); err != nil {
+		return nil, err
+	}
+
+	b, err := m.config.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling PROJECT file: %v", err)
+	}
+	m.files["PROJECT"] = b
+
+	return m, m.checkTargets()
+}
+
+// findAPIs returns all API group versions in the legacy APIs directory.
+func findAPIs(root string) (apis []legacyAPI, err error) {
+	groups, err := readDirs(filepath.Join(root, legacyAPIsDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		versions, err := readDirs(filepath.Join(root, legacyAPIsDir, group))
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			api := legacyAPI{dir: path.Join(legacyAPIsDir, group, version), version: version}
+			files, err := goFiles(filepath.Join(root, api.dir))
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				b, err := ioutil.ReadFile(filepath.Join(root, api.dir, file))
+				if err != nil {
+					return nil, err
+				}
+				if match := groupNameRe.FindSubmatch(b); match != nil {
+					api.group = string(match[1])
+				}
+				for _, register := range registerRe.FindAllSubmatch(b, -1) {
+					for _, kind := range kindRe.FindAllSubmatch(register[1], -1) {
+						if k := string(kind[1]); !strings.HasSuffix(k, "List") {
+							api.kinds = append(api.kinds, k)
+						}
+					}
+				}
+			}
+			if api.group == "" {
+				return nil, fmt.Errorf("no +groupName marker found in %s", api.dir)
+			}
+			apis = append(apis, api)
+		}
+	}
+	return apis, nil
+}
+
+// planAPIs moves each API to api/<version>, or apis/<group>/<version> if the
+// project has more than one group, and configures their resources and domain.
+func (m *migration) planAPIs(apis []legacyAPI) error {
+	m.config = &config.Config{
+		Version: config.Version3Alpha,
+		Repo:    m.repo,
+		Layout:  goLayout,
+	}
+
+	groups := make(map[string]struct{})
+	for _, api := range apis {
+		split := strings.SplitN(api.group, ".", 2)
+		if len(split) != 2 {
+			return fmt.Errorf("API group %q in %s has no domain", api.group, api.dir)
+		}
+		if m.config.Domain == "" {
+			m.config.Domain = split[1]
+		} else if m.config.Domain != split[1] {
+			return fmt.Errorf("API groups have different domains %q and %q, which is not supported",
+				m.config.Domain, split[1])
+		}
+		groups[split[0]] = struct{}{}
+		for _, kind := range api.kinds {
+			m.config.Resources = append(m.config.Resources, config.GVK{
+				Group:   split[0],
+				Version: api.version,
+				Kind:    kind,
+			})
+		}
+	}
+	m.config.MultiGroup = len(groups) > 1
+
+	for _, api := range apis {
+		dest := path.Join("api", api.version)
+		if m.config.MultiGroup {
+			dest = path.Join("apis", strings.SplitN(api.group, ".", 2)[0], api.version)
+		}
+		if err := m.moveGoFiles(api.dir, dest, ""); err != nil {
+			return err
+		}
+		m.importPaths[path.Join(m.repo, api.dir)] = path.Join(m.repo, dest)
+	}
+	return nil
+}
+
+// planControllers merges each controller package in pkg/controller/<name>
+// into the controllers package.
+func (m *migration) planControllers() error {
+	names, err := readDirs(filepath.Join(m.root, legacyControllersDir))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		dir := path.Join(legacyControllersDir, name)
+		if err := m.moveGoFiles(dir, controllersPkg, controllersPkg); err != nil {
+			return err
+		}
+		m.importPaths[path.Join(m.repo, dir)] = path.Join(m.repo, controllersPkg)
+	}
+	if len(names) != 0 {
+		m.followUps = append(m.followUps, "Replace the legacy Add and add functions in "+controllersPkg+
+			"/ with a SetupWithManager method on each reconciler")
+	}
+	if len(names) > 1 {
+		m.followUps = append(m.followUps, "Resolve duplicate declarations from the controller packages merged into "+
+			controllersPkg+"/")
+	}
+	return nil
+}
+
+// planConfig moves manifests in deploy/ into a kustomize config/ structure.
+func (m *migration) planConfig() error {
+	resources := make(map[string][]string)
+	// add moves from to config/<dir>/<resource>, and adds resource to dir's kustomization.
+	add := func(from, dir, resource string) {
+		m.moves = append(m.moves, move{from: from, to: path.Join("config", dir, resource)})
+		resources[dir] = append(resources[dir], resource)
+	}
+
+	crdFiles, err := yamlFiles(filepath.Join(m.root, legacyCRDsDir))
+	if err != nil {
+		return err
+	}
+	for _, file := range crdFiles {
+		from := path.Join(legacyCRDsDir, file)
+		switch base := strings.TrimSuffix(file, filepath.Ext(file)); {
+		case strings.HasSuffix(base, "_crd"):
+			add(from, "crd", path.Join("bases", strings.TrimSuffix(base, "_crd")+".yaml"))
+		case strings.HasSuffix(base, "_cr"):
+			add(from, "samples", strings.TrimSuffix(base, "_cr")+".yaml")
+		}
+	}
+	deployFiles, err := yamlFiles(filepath.Join(m.root, legacyDeployDir))
+	if err != nil {
+		return err
+	}
+	for _, file := range deployFiles {
+		from := path.Join(legacyDeployDir, file)
+		if from == legacyOperatorFile {
+			add(from, "manager", "manager.yaml")
+		} else {
+			add(from, "rbac", file)
+		}
+	}
+
+	var defaultResources []string
+	for _, dir := range []string{"crd", "rbac", "manager", "samples"} {
+		if len(resources[dir]) == 0 {
+			continue
+		}
+		m.files[path.Join("config", dir, "kustomization.yaml")] = makeKustomization(resources[dir])
+		if dir != "samples" {
+			defaultResources = append(defaultResources, path.Join("..", dir))
+		}
+	}
+	if len(defaultResources) != 0 {
+		m.files[path.Join("config", "default", "kustomization.yaml")] = makeKustomization(defaultResources)
+	}
+	return nil
+}
+
+// planFollowUps records changes to files that cannot be migrated automatically.
+func (m *migration) planFollowUps() error {
+	if m.exists(legacyMainFile) {
+		m.followUps = append(m.followUps, "Replace "+legacyMainFile+" with a main.go that adds each API's "+
+			"AddToScheme to the manager's scheme and sets up each reconciler, ex. from a project created by "+
+			"'operator-sdk init'")
+	}
+	if m.exists(legacyDockerfile) {
+		m.followUps = append(m.followUps, "Replace "+legacyDockerfile+" with a Dockerfile that builds the "+
+			"manager binary, and add a Makefile, ex. from a project created by 'operator-sdk init'")
+	}
+	b, err := ioutil.ReadFile(filepath.Join(m.root, "go.mod"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if bytes.Contains(b, []byte("github.com/operator-framework/operator-sdk ")) {
+		m.followUps = append(m.followUps, "Remove code using github.com/operator-framework/operator-sdk packages "+
+			"and its go.mod requirement and replace directives")
+	}
+	if m.exists(legacyCatalogDir) {
+		m.followUps = append(m.followUps, "Regenerate the OLM manifests in "+legacyCatalogDir+" with "+
+			"'operator-sdk generate kustomize manifests' and 'operator-sdk generate bundle'")
+	}
+	if len(m.config.Resources) != 0 {
+		m.followUps = append(m.followUps, "Add +kubebuilder:object:root=true markers to API root types "+
+			"so CRDs in config/crd can be regenerated by 'make manifests'")
+	}
+	for _, dir := range []string{legacyAPIsDir, legacyControllersDir} {
+		files, err := goFiles(filepath.Join(m.root, dir))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			m.followUps = append(m.followUps, fmt.Sprintf("Remove legacy registration file %s once main.go "+
+				"is updated", path.Join(dir, file)))
+		}
+	}
+	return nil
+}
+
+// moveGoFiles moves all Go files in project directory from to directory to,
+// renaming their package to pkg if set.
+func (m *migration) moveGoFiles(from, to, pkg string) error {
+	files, err := goFiles(filepath.Join(m.root, from))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		m.moves = append(m.moves, move{from: path.Join(from, file), to: path.Join(to, file), pkg: pkg})
+	}
+	return nil
+}
+
+// checkTargets returns an error if any file m moves or creates already exists,
+// or if two are destined for the same path.
+func (m *migration) checkTargets() error {
+	targets := make(map[string]struct{})
+	for _, p := range m.targets() {
+		if _, ok := targets[p]; ok {
+			return fmt.Errorf("more than one file would be written to %s", p)
+		}
+		targets[p] = struct{}{}
+		if m.exists(p) {
+			return fmt.Errorf("%s already exists", p)
+		}
+	}
+	return nil
+}
+
+func (m *migration) targets() (targets []string) {
+	for _, mv := range m.moves {
+		targets = append(targets, mv.to)
+	}
+	for _, p := range sortedKeys(m.files) {
+		targets = append(targets, p)
+	}
+	return targets
+}
+
+// print writes a summary of m's changes and follow-ups to w.
+func (m *migration) print(w io.Writer) {
+	fmt.Fprintln(w, "Files to move:")
+	for _, mv := range m.moves {
+		fmt.Fprintf(w, "  %s -> %s\n", mv.from, mv.to)
+	}
+	fmt.Fprintln(w, "Files to create:")
+	for _, p := range sortedKeys(m.files) {
+		fmt.Fprintf(w, "  %s\n", p)
+	}
+	fmt.Fprintln(w, "Import paths to update in all Go files:")
+	for _, old := range sortedKeys(m.importPaths) {
+		fmt.Fprintf(w, "  %s -> %s\n", old, m.importPaths[old])
+	}
+	if len(m.followUps) != 0 {
+		fmt.Fprintln(w, "Manual follow-ups:")
+		for _, f := range m.followUps {
+			fmt.Fprintf(w, "  - %s\n", f)
+		}
+	}
+}
+
+// apply makes m's changes on disk.
+func (m *migration) apply() error {
+	moved := make(map[string]struct{})
+	for _, mv := range m.moves {
+		b, err := ioutil.ReadFile(filepath.Join(m.root, mv.from))
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(mv.from, ".go") {
+			b = m.rewriteImports(b)
+		}
+		if mv.pkg != "" {
+			b = packageRe.ReplaceAll(b, []byte("package "+mv.pkg))
+		}
+		if err := m.writeFile(mv.to, b); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(m.root, mv.from)); err != nil {
+			return err
+		}
+		moved[mv.to] = struct{}{}
+	}
+
+	// Update imports in all Go files that were not moved.
+	err := filepath.Walk(m.root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if name := info.Name(); name == "vendor" || name == "testbin" || (p != m.root && strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(m.root, p)
+		if err != nil {
+			return err
+		}
+		if _, ok := moved[filepath.ToSlash(rel)]; ok || filepath.Ext(p) != ".go" {
+			return nil
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if nb := m.rewriteImports(b); !bytes.Equal(b, nb) {
+			return ioutil.WriteFile(p, nb, info.Mode())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range sortedKeys(m.files) {
+		if err := m.writeFile(p, m.files[p]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteImports replaces quoted legacy import paths in b with their new paths.
+func (m *migration) rewriteImports(b []byte) []byte {
+	for oldPath, newPath := range m.importPaths {
+		b = bytes.ReplaceAll(b, []byte(`"`+oldPath+`"`), []byte(`"`+newPath+`"`))
+	}
+	return b
+}
+
+func (m *migration) writeFile(p string, b []byte) error {
+	fp := filepath.Join(m.root, p)
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fp, b, 0644)
+}
+
+func (m *migration) exists(p string) bool {
+	_, err := os.Stat(filepath.Join(m.root, p))
+	return err == nil
+}
+
+func makeKustomization(resources []string) []byte {
+	sb := &strings.Builder{}
+	sb.WriteString("resources:\n")
+	for _, r := range resources {
+		sb.WriteString("- " + r + "\n")
+	}
+	return []byte(sb.String())
+}
+
+// readDirs returns the names of all directories in dir, or none if dir
+// does not exist.
+func readDirs(dir string) (names []string, err error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+// goFiles returns the names of all Go files in dir.
+func goFiles(dir string) ([]string, error) {
+	return filesWithExt(dir, ".go")
+}
+
+// yamlFiles returns the names of all YAML files in dir.
+func yamlFiles(dir string) ([]string, error) {
+	return filesWithExt(dir, ".yaml", ".yml")
+}
+
+func filesWithExt(dir string, exts ...string) (names []string, err error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, info := range infos {
+		for _, ext := range exts {
+			if !info.IsDir() && filepath.Ext(info.Name()) == ext {
+				names = append(names, info.Name())
+			}
+		}
+	}
+	return names, nil
+}
+
+// sortedKeys returns the sorted keys of m, which must be a map with string keys.
+func sortedKeys(m interface{}) (keys []string) {
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
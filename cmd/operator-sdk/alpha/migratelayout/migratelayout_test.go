@@ -0,0 +1,147 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migratelayout
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRepo = "github.com/example-inc/memcached-operator"
+
+var legacyProject = map[string]string{
+	"go.mod":                         "module " + testRepo + "\n\nrequire github.com/operator-framework/operator-sdk v0.19.0\n",
+	"cmd/manager/main.go":            "package main\n\nimport (\n\t\"" + testRepo + "/pkg/apis\"\n\t\"" + testRepo + "/pkg/controller\"\n)\n",
+	"build/Dockerfile":               "FROM scratch\n",
+	"pkg/apis/apis.go":               "package apis\n",
+	"pkg/apis/cache/group.go":        "package cache\n",
+	"pkg/apis/cache/v1alpha1/doc.go": "// Package v1alpha1 contains API Schema definitions\n// +groupName=cache.example.com\npackage v1alpha1\n",
+	"pkg/apis/cache/v1alpha1/memcached_types.go": "package v1alpha1\n\nfunc init() {\n" +
+		"\tSchemeBuilder.Register(&Memcached{}, &MemcachedList{})\n}\n",
+	"pkg/controller/controller.go": "package controller\n",
+	"pkg/controller/memcached/memcached_controller.go": "package memcached\n\nimport (\n" +
+		"\tcachev1alpha1 \"" + testRepo + "/pkg/apis/cache/v1alpha1\"\n)\n",
+	"deploy/operator.yaml": "kind: Deployment\n",
+	"deploy/role.yaml":     "kind: Role\n",
+	"deploy/crds/cache.example.com_memcacheds_crd.yaml":        "kind: CustomResourceDefinition\n",
+	"deploy/crds/cache.example.com_v1alpha1_memcached_cr.yaml": "kind: Memcached\n",
+}
+
+func writeLegacyProject(t *testing.T) string {
+	root, err := ioutil.TempDir("", "migrate-layout")
+	require.NoError(t, err)
+	for p, content := range legacyProject {
+		fp := filepath.Join(root, p)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0755))
+		require.NoError(t, ioutil.WriteFile(fp, []byte(content), 0644))
+	}
+	return root
+}
+
+func TestPlanMigration(t *testing.T) {
+	root := writeLegacyProject(t)
+	defer os.RemoveAll(root)
+
+	m, err := planMigration(root, testRepo)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", m.config.Domain)
+	assert.False(t, m.config.MultiGroup)
+	require.Len(t, m.config.Resources, 1)
+	assert.Equal(t, "cache", m.config.Resources[0].Group)
+	assert.Equal(t, "v1alpha1", m.config.Resources[0].Version)
+	assert.Equal(t, "Memcached", m.config.Resources[0].Kind)
+
+	assert.ElementsMatch(t, []move{
+		{from: "pkg/apis/cache/v1alpha1/doc.go", to: "api/v1alpha1/doc.go"},
+		{from: "pkg/apis/cache/v1alpha1/memcached_types.go", to: "api/v1alpha1/memcached_types.go"},
+		{from: "pkg/controller/memcached/memcached_controller.go", to: "controllers/memcached_controller.go", pkg: "controllers"},
+		{from: "deploy/crds/cache.example.com_memcacheds_crd.yaml", to: "config/crd/bases/cache.example.com_memcacheds.yaml"},
+		{from: "deploy/crds/cache.example.com_v1alpha1_memcached_cr.yaml", to: "config/samples/cache.example.com_v1alpha1_memcached.yaml"},
+		{from: "deploy/operator.yaml", to: "config/manager/manager.yaml"},
+		{from: "deploy/role.yaml", to: "config/rbac/role.yaml"},
+	}, m.moves)
+	assert.Equal(t, "resources:\n- ../crd\n- ../rbac\n- ../manager\n",
+		string(m.files["config/default/kustomization.yaml"]))
+	assert.Equal(t, "resources:\n- bases/cache.example.com_memcacheds.yaml\n",
+		string(m.files["config/crd/kustomization.yaml"]))
+	assert.Contains(t, m.files, "PROJECT")
+	// main.go, Dockerfile, operator-sdk dependency, markers, controller setup, and 2 registration files.
+	assert.Len(t, m.followUps, 7)
+
+	out := &bytes.Buffer{}
+	m.print(out)
+	assert.Contains(t, out.String(), "pkg/apis/cache/v1alpha1 -> "+testRepo+"/api/v1alpha1")
+}
+
+func TestApplyMigration(t *testing.T) {
+	root := writeLegacyProject(t)
+	defer os.RemoveAll(root)
+
+	m, err := planMigration(root, testRepo)
+	require.NoError(t, err)
+	require.NoError(t, m.apply())
+
+	b, err := ioutil.ReadFile(filepath.Join(root, "controllers", "memcached_controller.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package controllers\n\nimport (\n\tcachev1alpha1 \""+testRepo+"/api/v1alpha1\"\n)\n", string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(root, "cmd", "manager", "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, legacyProject["cmd/manager/main.go"], string(b))
+
+	for _, p := range []string{"PROJECT", "api/v1alpha1/memcached_types.go", "config/manager/manager.yaml"} {
+		assert.FileExists(t, filepath.Join(root, p))
+	}
+	_, err = os.Stat(filepath.Join(root, "pkg", "controller", "memcached", "memcached_controller.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	// A migrated project cannot be migrated again.
+	_, err = planMigration(root, testRepo)
+	assert.Error(t, err)
+}
+
+func TestPlanMigrationMultiGroup(t *testing.T) {
+	root := writeLegacyProject(t)
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "pkg", "apis", "web", "v1")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "doc.go"),
+		[]byte("// +groupName=web.example.com\npackage v1\n"), 0644))
+
+	m, err := planMigration(root, testRepo)
+	require.NoError(t, err)
+	assert.True(t, m.config.MultiGroup)
+	assert.Contains(t, m.moves, move{from: "pkg/apis/web/v1/doc.go", to: "apis/web/v1/doc.go"})
+	assert.Equal(t, testRepo+"/apis/cache/v1alpha1", m.importPaths[testRepo+"/pkg/apis/cache/v1alpha1"])
+}
+
+func TestPlanMigrationDifferentDomains(t *testing.T) {
+	root := writeLegacyProject(t)
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "pkg", "apis", "web", "v1")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "doc.go"),
+		[]byte("// +groupName=web.example.org\npackage v1\n"), 0644))
+
+	_, err := planMigration(root, testRepo)
+	assert.Error(t, err)
+}
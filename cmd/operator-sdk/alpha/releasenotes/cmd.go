@@ -0,0 +1,94 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenotes
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const longHelp = `
+Running 'alpha release-notes' compares two on-disk bundles and writes a markdown changelog
+of the differences between them, grouped into categories:
+
+  - Version: the ClusterServiceVersion's version
+  - CustomResourceDefinitions: added and removed CRDs and CRD versions, and changed schemas
+  - Permissions: added and removed cluster- and namespace-scoped install permissions
+  - Images: added, removed, and changed operator deployment container images
+
+Each of '--from' and '--to' is either a bundle directory containing a 'manifests' directory,
+ex. 'bundle', or a manifests directory itself. Use '--categories' to include only a subset
+of categories in the changelog.
+`
+
+const examples = `
+  # Write release notes for the changes between two bundles to stdout:
+  $ operator-sdk alpha release-notes --from v0.1.0/bundle --to bundle
+
+  # Write only permission and image changes to a file:
+  $ operator-sdk alpha release-notes --from v0.1.0/bundle --to bundle \
+      --categories permissions,images \
+      --output-file release-notes.md
+`
+
+type releaseNotesCmd struct {
+	from       string
+	to         string
+	categories []string
+	title      string
+	outputFile string
+}
+
+// NewCmd returns the 'release-notes' command.
+func NewCmd() *cobra.Command {
+	c := &releaseNotesCmd{}
+	cmd := &cobra.Command{
+		Use:     "release-notes",
+		Short:   "Generates markdown release notes from the differences between two bundles",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+
+			if err := c.validate(); err != nil {
+				return fmt.Errorf("invalid command options: %v", err)
+			}
+
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating release notes: %v", err)
+			}
+
+			return nil
+		},
+	}
+
+	c.addFlagsTo(cmd.Flags())
+
+	return cmd
+}
+
+func (c *releaseNotesCmd) addFlagsTo(fs *pflag.FlagSet) {
+	fs.StringVar(&c.from, "from", "", "Bundle or manifests directory of the previous release (required)")
+	fs.StringVar(&c.to, "to", "", "Bundle or manifests directory of the new release (required)")
+	fs.StringSliceVar(&c.categories, "categories", nil, "Categories of changes to include. "+
+		"One or more of: [version, customresourcedefinitions, permissions, images]. Defaults to all categories")
+	fs.StringVar(&c.title, "title", "", "Title of the release notes. Defaults to the new bundle's CSV name")
+	fs.StringVar(&c.outputFile, "output-file", "", "File to write the release notes to. Defaults to stdout")
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenotes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+
+	"github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+func (c releaseNotesCmd) validate() error {
+	if c.from == "" {
+		return errors.New("--from must be set")
+	}
+	if c.to == "" {
+		return errors.New("--to must be set")
+	}
+	if _, err := registry.ParseChangeCategories(c.categories); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c releaseNotesCmd) run() error {
+	from, err := loadBundle(c.from)
+	if err != nil {
+		return fmt.Errorf("error loading bundle %s: %v", c.from, err)
+	}
+	to, err := loadBundle(c.to)
+	if err != nil {
+		return fmt.Errorf("error loading bundle %s: %v", c.to, err)
+	}
+
+	categories, err := registry.ParseChangeCategories(c.categories)
+	if err != nil {
+		return err
+	}
+	if len(categories) == 0 {
+		categories = registry.ChangeCategories
+	}
+
+	title := c.title
+	if title == "" && to.CSV != nil {
+		title = to.CSV.GetName()
+	}
+	b := renderMarkdown(title, registry.DiffBundles(from, to), categories)
+
+	if c.outputFile == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	if dir := filepath.Dir(c.outputFile); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(c.outputFile, b, 0644)
+}
+
+// loadBundle reads a bundle from dir, which is either a bundle directory
+// containing a manifests directory or a manifests directory itself.
+func loadBundle(dir string) (*apimanifests.Bundle, error) {
+	manifestsDir := filepath.Join(dir, registrybundle.ManifestsDir)
+	if info, err := os.Stat(manifestsDir); err != nil || !info.IsDir() {
+		manifestsDir = dir
+	}
+	bundle, err := apimanifests.GetBundleFromDir(manifestsDir)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.CSV == nil {
+		return nil, fmt.Errorf("no ClusterServiceVersion found in %s", manifestsDir)
+	}
+	return bundle, nil
+}
+
+// renderMarkdown writes the changes in diff for each of categories as a
+// markdown section of a changelog titled title. Categories with no changes
+// are omitted.
+func renderMarkdown(title string, diff registry.BundleDiff, categories []registry.ChangeCategory) []byte {
+	buf := &bytes.Buffer{}
+	if title != "" {
+		fmt.Fprintf(buf, "# %s\n\n", title)
+	}
+	empty := true
+	for _, category := range categories {
+		changes := diff.InCategory(category)
+		if len(changes) == 0 {
+			continue
+		}
+		empty = false
+		fmt.Fprintf(buf, "## %s\n\n", category)
+		for _, change := range changes {
+			fmt.Fprintf(buf, "- %s %s\n", change.Kind, escapeMarkdown(change.Description))
+		}
+		buf.WriteString("\n")
+	}
+	if empty {
+		buf.WriteString("No changes.\n")
+	}
+	return buf.Bytes()
+}
+
+var markdownEscaper = strings.NewReplacer("*", `\*`, "_", `\_`, "`", "\\`")
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
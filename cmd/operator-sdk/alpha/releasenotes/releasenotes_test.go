@@ -0,0 +1,88 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenotes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	diff := registry.BundleDiff{Changes: []registry.Change{
+		{Category: registry.CategoryImages, Kind: registry.ChangeModified,
+			Description: "container memcached-operator/manager image from quay.io/example/op:v0.1.0 to quay.io/example/op:v0.2.0"},
+		{Category: registry.CategoryCRDs, Kind: registry.ChangeRemoved, Description: "CRD redis.cache.example.com"},
+		{Category: registry.CategoryCRDs, Kind: registry.ChangeAdded, Description: "CRD memcacheds.cache.example.com version v1beta1"},
+		{Category: registry.CategoryPermissions, Kind: registry.ChangeAdded, Description: "cluster-scoped permission default: * */secrets"},
+	}}
+
+	cases := []struct {
+		name       string
+		title      string
+		diff       registry.BundleDiff
+		categories []registry.ChangeCategory
+		expected   string
+	}{
+		{
+			name:       "all categories",
+			title:      "memcached-operator.v0.2.0",
+			diff:       diff,
+			categories: registry.ChangeCategories,
+			expected: "# memcached-operator.v0.2.0\n\n" +
+				"## CustomResourceDefinitions\n\n" +
+				"- Added CRD memcacheds.cache.example.com version v1beta1\n" +
+				"- Removed CRD redis.cache.example.com\n\n" +
+				"## Permissions\n\n" +
+				"- Added cluster-scoped permission default: \\* \\*/secrets\n\n" +
+				"## Images\n\n" +
+				"- Changed container memcached-operator/manager image from quay.io/example/op:v0.1.0 to quay.io/example/op:v0.2.0\n\n",
+		},
+		{
+			name:       "selected categories without title",
+			diff:       diff,
+			categories: []registry.ChangeCategory{registry.CategoryImages, registry.CategoryCRDs},
+			expected: "## Images\n\n" +
+				"- Changed container memcached-operator/manager image from quay.io/example/op:v0.1.0 to quay.io/example/op:v0.2.0\n\n" +
+				"## CustomResourceDefinitions\n\n" +
+				"- Added CRD memcacheds.cache.example.com version v1beta1\n" +
+				"- Removed CRD redis.cache.example.com\n\n",
+		},
+		{
+			name:       "no changes in selected categories",
+			title:      "memcached-operator.v0.2.0",
+			diff:       diff,
+			categories: []registry.ChangeCategory{registry.CategoryVersion},
+			expected:   "# memcached-operator.v0.2.0\n\nNo changes.\n",
+		},
+		{
+			name:       "no changes",
+			categories: registry.ChangeCategories,
+			expected:   "No changes.\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, string(renderMarkdown(c.title, c.diff, c.categories)))
+		})
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	assert.Equal(t, "my\\_field \\*.example.com \\`code\\`", escapeMarkdown("my_field *.example.com `code`"))
+}
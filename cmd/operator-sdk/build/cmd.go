@@ -15,25 +15,40 @@
 package build
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/operator-framework/operator-sdk/internal/scaffold"
 	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+	"github.com/operator-framework/operator-sdk/internal/util/signutil"
 
 	"github.com/google/shlex"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+// sourceDateEpochEnv is the environment variable conventionally used to set
+// build timestamps.
+// See https://reproducible-builds.org/specs/source-date-epoch/
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
 var (
 	imageBuildArgs string
 	imageBuilder   string
+	timestamp      string
+	push           bool
+	sign           bool
+	signKey        string
 
 	// todo: remove when the legacy layout is no longer supported
 	// Deprecated
@@ -56,6 +71,32 @@ For example:
 
 	$ operator-sdk build quay.io/example/operator:v0.0.1
 	$ docker push quay.io/example/operator:v0.0.1
+
+To set image timestamps, set '--timestamp' or the SOURCE_DATE_EPOCH environment variable
+to a Unix timestamp in seconds, ex. that of the latest commit:
+
+	$ export SOURCE_DATE_EPOCH=$(git log -1 --pretty=%ct)
+	$ operator-sdk build quay.io/example/operator:v0.0.1 --image-builder podman
+
+The timestamp sets the image's creation timestamp and the timestamps of files in its layers,
+so builds of the same content with the same timestamp produce the same image digest. It is
+passed to podman and buildah with '--timestamp'. It is passed to docker as the SOURCE_DATE_EPOCH
+build arg with '--output type=image,rewrite-timestamp=true', which requires BuildKit v0.13 or
+later; the build fails if 'docker buildx inspect' does not report such a builder, ex. if
+DOCKER_BUILDKIT=0 selects the legacy builder. Builds with different timestamps produce
+different layers, so keep the timestamp fixed across builds to reuse cached layers.
+
+Set '--push' to push the image to its registry after it is built, and '--sign' to also sign the
+pushed image with cosign (https://github.com/sigstore/cosign), which must be installed. The
+image is signed by the digest it was pushed with, not by its tag, so the signature covers the
+pushed image even if the tag is later moved. The signature is pushed to the image's repository,
+and its reference is logged. By default images
+are signed keylessly: cosign obtains an OIDC identity token, from the environment or by opening
+a browser to log in, and signs with a short-lived certificate bound to that identity. Set
+'--sign-key' to sign with a cosign private key file or KMS key URI instead:
+
+	$ operator-sdk build quay.io/example/operator:v0.0.1 --push --sign
+	$ operator-sdk build quay.io/example/operator:v0.0.1 --push --sign --sign-key cosign.key
 `,
 		RunE: buildFunc,
 	}
@@ -63,6 +104,13 @@ For example:
 		"Extra image build arguments as one string such as \"--build-arg https_proxy=$https_proxy\"")
 	buildCmd.Flags().StringVar(&imageBuilder, "image-builder", "docker",
 		"Tool to build OCI images. One of: [docker, podman, buildah]")
+	buildCmd.Flags().StringVar(&timestamp, "timestamp", "",
+		"Unix timestamp in seconds passed to the image builder to set image timestamps. "+
+			"Defaults to the value of $SOURCE_DATE_EPOCH if set. See the command help for how each builder uses it")
+	buildCmd.Flags().BoolVar(&push, "push", false, "Push the image to its registry after it is built")
+	buildCmd.Flags().BoolVar(&sign, "sign", false, "Sign the pushed image with cosign. Requires --push")
+	buildCmd.Flags().StringVar(&signKey, "sign-key", "",
+		"Path to a cosign private key, or KMS key URI, to sign the image with. Defaults to keyless signing")
 
 	// todo: remove when the legacy layout is no longer supported
 	if !kbutil.HasProjectFile() {
@@ -72,7 +120,7 @@ For example:
 	return buildCmd
 }
 
-func createBuildCommand(imageBuilder, context, dockerFile, image string, imageBuildArgs ...string) (*exec.Cmd, error) {
+func createBuildCommand(imageBuilder, context, dockerFile, image, timestamp string, imageBuildArgs ...string) (*exec.Cmd, error) {
 	var args []string
 	switch imageBuilder {
 	case "docker", "podman":
@@ -83,6 +131,19 @@ func createBuildCommand(imageBuilder, context, dockerFile, image string, imageBu
 		return nil, fmt.Errorf("%s is not supported image builder", imageBuilder)
 	}
 
+	if timestamp != "" {
+		switch imageBuilder {
+		case "docker":
+			// BuildKit sets the image's creation timestamp from this build arg,
+			// and rewrites the timestamps of files in layers with the exporter's
+			// rewrite-timestamp option.
+			args = append(args, "--build-arg", fmt.Sprintf("%s=%s", sourceDateEpochEnv, timestamp),
+				"--output", "type=image,rewrite-timestamp=true")
+		case "podman", "buildah":
+			args = append(args, "--timestamp", timestamp)
+		}
+	}
+
 	for _, bargs := range imageBuildArgs {
 		if bargs != "" {
 			splitArgs, err := shlex.Split(bargs)
@@ -103,6 +164,13 @@ func buildFunc(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("command %s requires exactly one argument", cmd.CommandPath())
 	}
 
+	if sign && !push {
+		return fmt.Errorf("--sign requires --push, since signatures are stored in the image's registry")
+	}
+	if signKey != "" && !sign {
+		return fmt.Errorf("--sign-key requires --sign")
+	}
+
 	image := args[0]
 	projutil.MustInProjectRoot()
 
@@ -110,17 +178,130 @@ func buildFunc(cmd *cobra.Command, args []string) error {
 		if err := doImageBuild("Dockerfile", image); err != nil {
 			log.Fatalf("Failed to build image %s: %v", image, err)
 		}
-		return nil
+	} else {
+		// todo: remove when the legacy layout is no longer supported
+		// note that the above if will no longer be required as well.
+		if err := doLegacyBuild(image); err != nil {
+			log.Fatalf("Failed to build image %s: %v", image, err)
+		}
 	}
 
-	// todo: remove when the legacy layout is no longer supported
-	// note that the above if will no longer be required as well.
-	if err := doLegacyBuild(image); err != nil {
-		log.Fatalf("Failed to build image %s: %v", image, err)
+	var digest string
+	if push {
+		var err error
+		if digest, err = doImagePush(image); err != nil {
+			log.Fatalf("Failed to push image %s: %v", image, err)
+		}
+	}
+	if sign {
+		if err := doImageSign(image, digest); err != nil {
+			log.Fatalf("Failed to sign image %s: %v", image, err)
+		}
 	}
 	return nil
 }
 
+// createPushCommand returns a command that pushes image. If digestFile is set,
+// podman and buildah write the digest of the pushed image to it; docker
+// records the digest in the image's RepoDigests instead.
+func createPushCommand(imageBuilder, image, digestFile string) (*exec.Cmd, error) {
+	switch imageBuilder {
+	case "docker":
+		return exec.Command(imageBuilder, "push", image), nil
+	case "podman", "buildah":
+		args := []string{"push"}
+		if digestFile != "" {
+			args = append(args, "--digestfile", digestFile)
+		}
+		return exec.Command(imageBuilder, append(args, image)...), nil
+	}
+	return nil, fmt.Errorf("%s is not supported image builder", imageBuilder)
+}
+
+// doImagePush pushes image to its registry with the image builder, and returns
+// the digest of the pushed image.
+func doImagePush(image string) (string, error) {
+	log.Infof("Pushing OCI image %s", image)
+	digestFile := ""
+	if imageBuilder != "docker" {
+		f, err := ioutil.TempFile("", "operator-sdk-digest-")
+		if err != nil {
+			return "", err
+		}
+		if err := f.Close(); err != nil {
+			return "", err
+		}
+		defer os.Remove(f.Name())
+		digestFile = f.Name()
+	}
+	pushCmd, err := createPushCommand(imageBuilder, image, digestFile)
+	if err != nil {
+		return "", err
+	}
+	if err := projutil.ExecCmd(pushCmd); err != nil {
+		return "", err
+	}
+
+	if digestFile != "" {
+		b, err := ioutil.ReadFile(digestFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading digest of pushed image: %v", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	out, err := createRepoDigestsCommand(image).Output()
+	if err != nil {
+		return "", fmt.Errorf("error inspecting digest of pushed image: %v", err)
+	}
+	return getRepoDigest(image, out)
+}
+
+// createRepoDigestsCommand returns a command that prints the RepoDigests of a
+// docker image as a JSON list.
+func createRepoDigestsCommand(image string) *exec.Cmd {
+	return exec.Command("docker", "image", "inspect", "--format", "{{json .RepoDigests}}", image)
+}
+
+// getRepoDigest returns the digest in the JSON list of RepoDigests, each of the
+// form "<repository>@<digest>", whose repository is image's repository.
+func getRepoDigest(image string, repoDigests []byte) (string, error) {
+	var refs []string
+	if err := json.Unmarshal(repoDigests, &refs); err != nil {
+		return "", fmt.Errorf("error parsing RepoDigests of image %s: %v", image, err)
+	}
+	repo := familiarRepository(signutil.Repository(image))
+	for _, ref := range refs {
+		i := strings.LastIndex(ref, "@")
+		if i >= 0 && familiarRepository(ref[:i]) == repo {
+			return ref[i+1:], nil
+		}
+	}
+	return "", fmt.Errorf("no digest of image %s found in RepoDigests %v", image, refs)
+}
+
+// familiarRepository returns repo as docker displays it, without the default
+// registry and namespace, ex. "busybox" for "docker.io/library/busybox".
+func familiarRepository(repo string) string {
+	repo = strings.TrimPrefix(repo, "docker.io/")
+	return strings.TrimPrefix(repo, "library/")
+}
+
+// doImageSign signs the image pushed with digest and logs the reference of its
+// signature.
+func doImageSign(image, digest string) error {
+	ref, err := signutil.DigestRef(image, digest)
+	if err != nil {
+		return err
+	}
+	log.Infof("Signing OCI image %s", ref)
+	sigRef, err := signutil.Sign(ref, signutil.Options{Key: signKey})
+	if err != nil {
+		return err
+	}
+	log.Infof("Signed image %s, signature stored at %s", ref, sigRef)
+	return nil
+}
+
 // todo: remove when the legacy layout is no longer supported
 // Deprecated: Used just for the legacy layout
 // --
@@ -144,8 +325,9 @@ func doLegacyBuild(image string) error {
 			args = append(args, splitArgs...)
 		}
 
+		binPath := filepath.Join(absProjectPath, projutil.BinaryOutputPath(projectName, "linux", ""))
 		opts := projutil.GoCmdOptions{
-			BinName:     filepath.Join(absProjectPath, scaffold.BuildBinDir, projectName),
+			BinName:     binPath,
 			PackagePath: path.Join(projutil.GetGoPkg(), filepath.ToSlash(scaffold.ManagerDir)),
 			Args:        args,
 			Env:         goBuildEnv,
@@ -153,14 +335,115 @@ func doLegacyBuild(image string) error {
 		if err := projutil.GoBuild(opts); err != nil {
 			log.Fatalf("Failed to build operator binary: %v", err)
 		}
+		// Legacy build/Dockerfiles copy the binary from build/_output/bin/<projectName>.
+		if err := copyBinary(binPath, filepath.Join(absProjectPath, scaffold.BuildBinDir, projectName)); err != nil {
+			log.Fatalf("Failed to copy operator binary: %v", err)
+		}
 	}
 	return doImageBuild("build/Dockerfile", image)
 }
 
+// copyBinary copies the executable at src to dst, replacing dst if it exists.
+func copyBinary(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// OpenFile does not change the mode of an existing dst.
+	return os.Chmod(dst, 0755)
+}
+
+// getBuildTimestamp returns the --timestamp flag value, or that of
+// $SOURCE_DATE_EPOCH if the flag is unset, after checking it is a
+// non-negative Unix timestamp. An empty string means no timestamp is set.
+func getBuildTimestamp() (string, error) {
+	ts, source := timestamp, "--timestamp"
+	if ts == "" {
+		ts, source = os.Getenv(sourceDateEpochEnv), "$"+sourceDateEpochEnv
+	}
+	if ts == "" {
+		return "", nil
+	}
+	if sec, err := strconv.ParseInt(ts, 10, 64); err != nil || sec < 0 {
+		return "", fmt.Errorf("%s value %q must be a non-negative Unix timestamp in seconds", source, ts)
+	}
+	return ts, nil
+}
+
+// minRewriteTimestampBuildKit is the first BuildKit minor version, of v0, whose
+// image exporter supports rewrite-timestamp.
+const minRewriteTimestampBuildKit = 13
+
+// buildKitVersionRe matches the BuildKit version in 'docker buildx inspect'
+// output, ex. "BuildKit version: v0.13.2", or "Buildkit: v0.11.6" from older
+// buildx versions.
+var buildKitVersionRe = regexp.MustCompile(`(?im)^buildkit(?: version)?:\s*v?(\d+)\.(\d+)`)
+
+// dockerBuildxInspect returns the output of 'docker buildx inspect', which
+// describes the builder that 'docker build' uses.
+var dockerBuildxInspect = func() ([]byte, error) {
+	return exec.Command("docker", "buildx", "inspect").Output()
+}
+
+// checkDockerTimestamps returns an error if docker cannot set image timestamps,
+// since it would otherwise silently build an image with the current time.
+func checkDockerTimestamps() error {
+	if os.Getenv("DOCKER_BUILDKIT") == "0" {
+		return fmt.Errorf("docker cannot set image timestamps with the legacy builder selected by " +
+			"DOCKER_BUILDKIT=0; unset it, use --image-builder podman or buildah, or unset --timestamp and $" +
+			sourceDateEpochEnv)
+	}
+	out, err := dockerBuildxInspect()
+	if err != nil {
+		return fmt.Errorf("error inspecting the docker builder to check it can set image timestamps: %v", err)
+	}
+	return checkBuildKitVersion(out)
+}
+
+// checkBuildKitVersion returns an error unless inspectOutput, the output of
+// 'docker buildx inspect', reports BuildKit v0.13 or later. Versions other than
+// v0 are rejected, since older buildx versions report the docker engine version,
+// ex. 20.10.17, for builders of the docker driver.
+func checkBuildKitVersion(inspectOutput []byte) error {
+	m := buildKitVersionRe.FindSubmatch(inspectOutput)
+	if m == nil {
+		return fmt.Errorf("could not determine the BuildKit version of the docker builder; " +
+			"BuildKit v0.13 or later is required to set image timestamps")
+	}
+	major, _ := strconv.Atoi(string(m[1]))
+	minor, _ := strconv.Atoi(string(m[2]))
+	if major != 0 || minor < minRewriteTimestampBuildKit {
+		return fmt.Errorf("the docker builder uses BuildKit v%d.%d, which cannot set image timestamps; "+
+			"BuildKit v0.%d or later is required", major, minor, minRewriteTimestampBuildKit)
+	}
+	return nil
+}
+
 // doImageBuild will execute the build command for the given Dockerfile path and image
 func doImageBuild(dockerFilePath, image string) error {
 	log.Infof("Building OCI image %s", image)
-	buildCmd, err := createBuildCommand(imageBuilder, ".", dockerFilePath, image, imageBuildArgs)
+	ts, err := getBuildTimestamp()
+	if err != nil {
+		return err
+	}
+	if imageBuilder == "docker" && ts != "" {
+		if err := checkDockerTimestamps(); err != nil {
+			return err
+		}
+	}
+	buildCmd, err := createBuildCommand(imageBuilder, ".", dockerFilePath, image, ts, imageBuildArgs)
 	if err != nil {
 		return err
 	}
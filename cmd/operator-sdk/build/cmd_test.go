@@ -0,0 +1,350 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCreateBuildCommand(t *testing.T) {
+	cases := []struct {
+		imageBuilder string
+		timestamp    string
+		buildArgs    string
+		expectedArgs []string
+	}{
+		{"docker", "", "", []string{"docker", "build", "-f", "Dockerfile", "-t", "img", "."}},
+		{"docker", "1600000000", "", []string{"docker", "build", "-f", "Dockerfile", "-t", "img",
+			"--build-arg", "SOURCE_DATE_EPOCH=1600000000", "--output", "type=image,rewrite-timestamp=true", "."}},
+		{"docker", "1600000000", "--build-arg https_proxy=proxy --no-cache", []string{"docker", "build",
+			"-f", "Dockerfile", "-t", "img", "--build-arg", "SOURCE_DATE_EPOCH=1600000000",
+			"--output", "type=image,rewrite-timestamp=true", "--build-arg", "https_proxy=proxy", "--no-cache", "."}},
+		{"podman", "", "", []string{"podman", "build", "-f", "Dockerfile", "-t", "img", "."}},
+		{"podman", "1600000000", "", []string{"podman", "build", "-f", "Dockerfile", "-t", "img",
+			"--timestamp", "1600000000", "."}},
+		{"podman", "1600000000", "--layers", []string{"podman", "build", "-f", "Dockerfile", "-t", "img",
+			"--timestamp", "1600000000", "--layers", "."}},
+		{"buildah", "", "", []string{"buildah", "bud", "--format=docker", "-f", "Dockerfile", "-t", "img", "."}},
+		{"buildah", "1600000000", "", []string{"buildah", "bud", "--format=docker", "-f", "Dockerfile", "-t", "img",
+			"--timestamp", "1600000000", "."}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.imageBuilder+"/"+c.timestamp+"/"+c.buildArgs, func(t *testing.T) {
+			cmd, err := createBuildCommand(c.imageBuilder, ".", "Dockerfile", "img", c.timestamp, c.buildArgs)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd.Args, c.expectedArgs) {
+				t.Errorf("Wanted args %v, got: %v", c.expectedArgs, cmd.Args)
+			}
+		})
+	}
+
+	if _, err := createBuildCommand("kaniko", ".", "Dockerfile", "img", ""); err == nil {
+		t.Error("Wanted error for an unsupported image builder, got none")
+	}
+}
+
+func TestGetBuildTimestamp(t *testing.T) {
+	defer func() { timestamp = "" }()
+	if err := os.Setenv(sourceDateEpochEnv, "1500000000"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(sourceDateEpochEnv)
+
+	cases := []struct {
+		flag      string
+		expected  string
+		wantError bool
+	}{
+		{"", "1500000000", false},
+		{"1600000000", "1600000000", false},
+		{"yesterday", "", true},
+		{"-1", "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.flag, func(t *testing.T) {
+			timestamp = c.flag
+			ts, err := getBuildTimestamp()
+			if c.wantError && err == nil {
+				t.Fatal("Wanted error, got none")
+			} else if !c.wantError && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ts != c.expected {
+				t.Errorf("Wanted timestamp %q, got: %q", c.expected, ts)
+			}
+		})
+	}
+}
+
+func TestCheckBuildKitVersion(t *testing.T) {
+	cases := []struct {
+		name    string
+		output  string
+		wantErr bool
+	}{
+		{"v0.13", "Name: default\nDriver: docker\n\nNodes:\nName: default\nBuildKit version: v0.13.2\n", false},
+		{"v0.16", "Name: builder\nDriver: docker-container\nBuildkit: v0.16.0\n", false},
+		{"v0.12", "Name: default\nDriver: docker\nBuildkit: v0.12.5\n", true},
+		{"engine version", "Name: default\nDriver: docker\nBuildkit: 20.10.17\n", true},
+		{"no version", "Name: default\nDriver: docker\n", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkBuildKitVersion([]byte(c.output))
+			if c.wantErr && err == nil {
+				t.Error("Wanted error, got none")
+			} else if !c.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckDockerTimestamps(t *testing.T) {
+	defer func(f func() ([]byte, error)) { dockerBuildxInspect = f }(dockerBuildxInspect)
+	dockerBuildxInspect = func() ([]byte, error) { return []byte("BuildKit version: v0.13.2\n"), nil }
+	defer os.Setenv("DOCKER_BUILDKIT", os.Getenv("DOCKER_BUILDKIT"))
+
+	if err := os.Setenv("DOCKER_BUILDKIT", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkDockerTimestamps(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := os.Setenv("DOCKER_BUILDKIT", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkDockerTimestamps(); err == nil {
+		t.Error("Wanted error for the legacy docker builder, got none")
+	}
+}
+
+// TestBuildReproducible builds the same content twice with the same timestamp,
+// with each installed image builder, and checks both images have the same ID.
+func TestBuildReproducible(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping image builds in short mode")
+	}
+	inspectArgs := map[string][]string{
+		"docker":  {"image", "inspect", "--format", "{{.Id}}"},
+		"podman":  {"image", "inspect", "--format", "{{.Id}}"},
+		"buildah": {"inspect", "--type", "image", "--format", "{{.FromImageID}}"},
+	}
+	for _, builder := range []string{"docker", "podman", "buildah"} {
+		t.Run(builder, func(t *testing.T) {
+			if _, err := exec.LookPath(builder); err != nil {
+				t.Skipf("%s is not installed", builder)
+			}
+			if builder == "docker" {
+				if err := checkDockerTimestamps(); err != nil {
+					t.Skip(err)
+				}
+			}
+
+			dir, err := ioutil.TempDir("", "operator-sdk-build-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			dockerfile := filepath.Join(dir, "Dockerfile")
+			if err := ioutil.WriteFile(dockerfile, []byte("FROM scratch\nCOPY data /data\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []string
+			for i, image := range []string{"operator-sdk-build-test:a", "operator-sdk-build-test:b"} {
+				// Give the file a different modification time in each build.
+				data := filepath.Join(dir, "data")
+				if err := ioutil.WriteFile(data, []byte("data\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				mtime := time.Now().Add(time.Duration(i) * time.Hour)
+				if err := os.Chtimes(data, mtime, mtime); err != nil {
+					t.Fatal(err)
+				}
+
+				cmd, err := createBuildCommand(builder, dir, dockerfile, image, "1600000000", "--no-cache")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("Error building %s: %v\n%s", image, err, out)
+				}
+				defer func(image string) { _ = exec.Command(builder, "rmi", image).Run() }(image)
+
+				out, err := exec.Command(builder, append(inspectArgs[builder], image)...).Output()
+				if err != nil {
+					t.Fatalf("Error inspecting %s: %v", image, err)
+				}
+				ids = append(ids, strings.TrimSpace(string(out)))
+			}
+			if ids[0] != ids[1] {
+				t.Errorf("Wanted builds of the same content to have the same image ID, got %s and %s", ids[0], ids[1])
+			}
+		})
+	}
+}
+
+func TestCopyBinary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "operator-sdk-build-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, dst := filepath.Join(dir, "app-operator-linux-amd64"), filepath.Join(dir, "app-operator")
+	if err := ioutil.WriteFile(src, []byte("new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dst, []byte("old binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyBinary(src, dst); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "new" {
+		t.Errorf("Wanted copied binary %q, got: %q", "new", b)
+	}
+	if info, err := os.Stat(dst); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0755 {
+		t.Errorf("Wanted copied binary mode %v, got: %v", os.FileMode(0755), info.Mode().Perm())
+	}
+	if err := copyBinary(filepath.Join(dir, "missing"), dst); err == nil {
+		t.Error("Wanted error for a missing binary, got none")
+	}
+}
+
+func TestCreatePushCommand(t *testing.T) {
+	cases := []struct {
+		imageBuilder string
+		digestFile   string
+		expectedArgs []string
+	}{
+		{"docker", "", []string{"docker", "push", "img"}},
+		{"docker", "digest", []string{"docker", "push", "img"}},
+		{"podman", "", []string{"podman", "push", "img"}},
+		{"podman", "digest", []string{"podman", "push", "--digestfile", "digest", "img"}},
+		{"buildah", "", []string{"buildah", "push", "img"}},
+		{"buildah", "digest", []string{"buildah", "push", "--digestfile", "digest", "img"}},
+	}
+	for _, c := range cases {
+		t.Run(c.imageBuilder+" "+c.digestFile, func(t *testing.T) {
+			cmd, err := createPushCommand(c.imageBuilder, "img", c.digestFile)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd.Args, c.expectedArgs) {
+				t.Errorf("Wanted args %v, got: %v", c.expectedArgs, cmd.Args)
+			}
+		})
+	}
+	if _, err := createPushCommand("kaniko", "img", ""); err == nil {
+		t.Error("Wanted error for unsupported image builder, got none")
+	}
+}
+
+func TestCreateRepoDigestsCommand(t *testing.T) {
+	cmd := createRepoDigestsCommand("img")
+	expectedArgs := []string{"docker", "image", "inspect", "--format", "{{json .RepoDigests}}", "img"}
+	if !reflect.DeepEqual(cmd.Args, expectedArgs) {
+		t.Errorf("Wanted args %v, got: %v", expectedArgs, cmd.Args)
+	}
+}
+
+func TestGetRepoDigest(t *testing.T) {
+	const (
+		digest      = "sha256:c71cb4f7e8ececaffb34037c2637dc86820e4185100e18b4d02d613a9bd772af"
+		otherDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	)
+	cases := []struct {
+		name           string
+		image          string
+		repoDigests    string
+		expectedDigest string
+		wantErr        bool
+	}{
+		{"single", "quay.io/example/operator:v0.0.1",
+			`["quay.io/example/operator@` + digest + `"]`, digest, false},
+		{"other repository", "quay.io/example/operator:v0.0.1",
+			`["quay.io/other/operator@` + otherDigest + `","quay.io/example/operator@` + digest + `"]`, digest, false},
+		{"registry port", "localhost:5000/operator:v0.0.1",
+			`["localhost:5000/operator@` + digest + `"]`, digest, false},
+		{"docker hub", "docker.io/library/operator:v0.0.1",
+			`["operator@` + digest + `"]`, digest, false},
+		{"no matching repository", "quay.io/example/operator:v0.0.1",
+			`["quay.io/other/operator@` + otherDigest + `"]`, "", true},
+		{"none", "quay.io/example/operator:v0.0.1", `[]`, "", true},
+		{"invalid", "quay.io/example/operator:v0.0.1", `not json`, "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d, err := getRepoDigest(c.image, []byte(c.repoDigests))
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("Wanted error, got digest %s", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if d != c.expectedDigest {
+				t.Errorf("Wanted digest %s, got: %s", c.expectedDigest, d)
+			}
+		})
+	}
+}
+
+func TestBuildFuncSignFlags(t *testing.T) {
+	defer func(p, s bool, k string) { push, sign, signKey = p, s, k }(push, sign, signKey)
+
+	cases := []struct {
+		name        string
+		push, sign  bool
+		signKey     string
+		expectedErr string
+	}{
+		{"sign without push", false, true, "", "--sign requires --push, since signatures are stored in the image's registry"},
+		{"sign key without sign", true, false, "cosign.key", "--sign-key requires --sign"},
+		{"sign key without push", false, false, "cosign.key", "--sign-key requires --sign"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			push, sign, signKey = c.push, c.sign, c.signKey
+			err := buildFunc(&cobra.Command{Use: "build"}, []string{"img"})
+			if err == nil || err.Error() != c.expectedErr {
+				t.Errorf("Wanted error %q, got: %v", c.expectedErr, err)
+			}
+		})
+	}
+}
@@ -28,7 +28,7 @@ func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "bundle",
 		Short: "Manage operator bundle metadata",
-		Long: `Manage bundle builds, bundle metadata generation, and bundle validation.
+		Long: `Manage bundle builds, bundle metadata generation, bundle validation, bundle image rewriting, and bundle signing.
 An operator bundle is a portable operator packaging format understood by Kubernetes
 native software, like the Operator Lifecycle Manager.
 
@@ -41,6 +41,8 @@ https://sdk.operatorframework.io/docs/olm-integration
 	}
 
 	cmd.AddCommand(
+		newRewriteImagesCmd(),
+		newSignCmd(),
 		newValidateCmd(),
 	)
 	return cmd
@@ -26,8 +26,10 @@ var _ = Describe("Running a bundle command", func() {
 			Expect(cmd).NotTo(BeNil())
 
 			subcommands := cmd.Commands()
-			Expect(len(subcommands)).To(Equal(1))
-			Expect(subcommands[0].Use).To(Equal("validate"))
+			Expect(len(subcommands)).To(Equal(3))
+			Expect(subcommands[0].Use).To(Equal("rewrite-images <bundle-dir>"))
+			Expect(subcommands[1].Use).To(Equal("sign <image>"))
+			Expect(subcommands[2].Use).To(Equal("validate"))
 		})
 	})
 })
@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
 
 	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	"github.com/sirupsen/logrus"
@@ -28,18 +30,33 @@ import (
 const (
 	JSONAlpha1 = "json-alpha1"
 	Text       = "text"
+	Table      = "table"
 )
 
+// Table columns.
+const (
+	ColumnValidator = "validator"
+	ColumnSeverity  = "severity"
+	ColumnMessage   = "message"
+)
+
+// TableColumns are the columns the table format can print, in their default order.
+var TableColumns = []string{ColumnValidator, ColumnSeverity, ColumnMessage}
+
 // Result represents the final result
 type Result struct {
 	Passed  bool     `json:"passed"`
 	Outputs []output `json:"outputs"`
+
+	// Columns are the columns printed in the table format, defaulting to TableColumns.
+	Columns []string `json:"-"`
 }
 
 // output represents the logs which are used to return the final result in the JSON format
 type output struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	Validator string `json:"validator,omitempty"`
 }
 
 // NewResult return a new result object which starts with passed == true since has no errors
@@ -57,18 +74,25 @@ func (o *Result) AddInfo(msg string) {
 
 // AddError will add a log to the result with the Error Level
 func (o *Result) AddError(err error) {
+	o.AddValidatorError("", err)
+}
+
+// AddValidatorError will add a log from validator to the result with the Error Level
+func (o *Result) AddValidatorError(validator string, err error) {
 	verr := registrybundle.ValidationError{}
 	if errors.As(err, &verr) {
 		for _, valErr := range verr.Errors {
 			o.Outputs = append(o.Outputs, output{
-				Type:    logrus.ErrorLevel.String(),
-				Message: valErr.Error(),
+				Type:      logrus.ErrorLevel.String(),
+				Message:   valErr.Error(),
+				Validator: validator,
 			})
 		}
 	} else {
 		o.Outputs = append(o.Outputs, output{
-			Type:    logrus.ErrorLevel.String(),
-			Message: err.Error(),
+			Type:      logrus.ErrorLevel.String(),
+			Message:   err.Error(),
+			Validator: validator,
 		})
 	}
 	o.Passed = false
@@ -76,9 +100,15 @@ func (o *Result) AddError(err error) {
 
 // AddWarn will add a log to the result with the Warn Level
 func (o *Result) AddWarn(err error) {
+	o.AddValidatorWarn("", err)
+}
+
+// AddValidatorWarn will add a log from validator to the result with the Warn Level
+func (o *Result) AddValidatorWarn(validator string, err error) {
 	o.Outputs = append(o.Outputs, output{
-		Type:    logrus.WarnLevel.String(),
-		Message: err.Error(),
+		Type:      logrus.WarnLevel.String(),
+		Message:   err.Error(),
+		Validator: validator,
 	})
 }
 
@@ -114,6 +144,38 @@ func (o *Result) printJSON() error {
 	return nil
 }
 
+// printTable will print the output as a table with one row per output, containing o.Columns
+func (o *Result) printTable() error {
+	columns := o.Columns
+	if len(columns) == 0 {
+		columns = TableColumns
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = strings.ToUpper(col)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, obj := range o.Outputs {
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			switch col {
+			case ColumnValidator:
+				row[i] = obj.Validator
+			case ColumnSeverity:
+				row[i] = obj.Type
+			case ColumnMessage:
+				row[i] = obj.Message
+			default:
+				return fmt.Errorf("unknown table column %q", col)
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
 // prepare should be used when writing an Result to a non-log writer.
 // it will ensure that the passed boolean will properly set in the case of the setters were not properly used
 func (o *Result) prepare() error {
@@ -156,6 +218,10 @@ func (o *Result) getPrintFuncFormat(format string) func(Result) error {
 		return func(o Result) error {
 			return o.printJSON()
 		}
+	case Table:
+		return func(o Result) error {
+			return o.printTable()
+		}
 	}
 
 	// Address all to the Stdout when the type is not JSON
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo" //nolint:golint
@@ -227,6 +228,50 @@ var _ = Describe("Output Result", func() {
 		})
 	})
 
+	Describe("Test printTable()", func() {
+		captureTable := func() string {
+			r, w, _ := os.Pipe()
+			tmp := os.Stdout
+			defer func() {
+				os.Stdout = tmp
+			}()
+			os.Stdout = w
+			go func() {
+				Expect(result.printTable()).To(Succeed())
+				w.Close()
+			}()
+			stdout, _ := ioutil.ReadAll(r)
+			return string(stdout)
+		}
+
+		It("should print all columns by default", func() {
+			result.AddValidatorError("bundle-content", errors.New("example of an error"))
+			result.AddWarn(errors.New("example of a warn"))
+
+			lines := strings.Split(strings.TrimSpace(captureTable()), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(strings.Fields(lines[0])).To(Equal([]string{"VALIDATOR", "SEVERITY", "MESSAGE"}))
+			Expect(lines[1]).To(MatchRegexp(`^bundle-content\s+error\s+example of an error$`))
+			Expect(lines[2]).To(MatchRegexp(`^\s+warning\s+example of a warn$`))
+		})
+
+		It("should print only the selected columns in order", func() {
+			result.AddValidatorError("bundle-content", errors.New("example of an error"))
+			result.Columns = []string{ColumnMessage, ColumnSeverity}
+
+			lines := strings.Split(strings.TrimSpace(captureTable()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(strings.Fields(lines[0])).To(Equal([]string{"MESSAGE", "SEVERITY"}))
+			Expect(lines[1]).To(MatchRegexp(`^example of an error\s+error$`))
+		})
+
+		It("should fail when an unknown column is selected", func() {
+			result.Columns = []string{"name"}
+			result.AddInfo("Example of an info")
+			Expect(result.printTable()).NotTo(Succeed())
+		})
+	})
+
 	Describe("Test PrintWithFormat()", func() {
 		It("should print a JSON", func() {
 			By("passing the format`json-alpha1`")
@@ -0,0 +1,212 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	internalregistry "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+const (
+	rewriteImagesLongHelp = `The 'operator-sdk bundle rewrite-images' command rewrites image references in the
+ClusterServiceVersion of the bundle in <bundle-dir> that are prefixed by '--from-registry' to be
+prefixed by '--to-registry' instead, for example to install a bundle from a mirror registry.
+A prefix is a registry host optionally followed by repository path components, and only matches
+whole components: 'quay.io/example' matches 'quay.io/example/operator:v0.1.0' but not
+'quay.io/example-org/operator:v0.1.0'. References are also matched in their normalized form,
+so 'docker.io' matches 'busybox:1.32'.
+
+Rewritten references are those of deployment containers and init containers, 'RELATED_IMAGE_*'
+container environment variables, 'spec.relatedImages', and the 'containerImage' annotation.
+
+Set '--resolve-digests' to replace the tag of each rewritten reference with the digest of its image
+in the '--to-registry' registry, which must be reachable. Every rewritten reference is validated,
+and no files are written if any is invalid. Set '--dry-run' to print rewrites without writing them.
+`
+
+	rewriteImagesExamples = `  # Preview rewrites of images from quay.io/example to a mirror registry.
+  $ operator-sdk bundle rewrite-images ./bundle \
+      --from-registry quay.io/example \
+      --to-registry registry.example.com/mirror \
+      --dry-run
+  metadata.annotations.containerImage: quay.io/example/memcached-operator:v0.0.1 -> registry.example.com/mirror/memcached-operator:v0.0.1
+  spec.install.spec.deployments[0].spec.template.spec.containers[1].image: quay.io/example/memcached-operator:v0.0.1 -> registry.example.com/mirror/memcached-operator:v0.0.1
+
+  # Rewrite the images, pinning each to its digest in the mirror registry.
+  $ operator-sdk bundle rewrite-images ./bundle \
+      --from-registry quay.io/example \
+      --to-registry registry.example.com/mirror \
+      --resolve-digests
+`
+)
+
+type bundleRewriteImagesCmd struct {
+	fromRegistry   string
+	toRegistry     string
+	resolveDigests bool
+	dryRun         bool
+}
+
+// newRewriteImagesCmd returns a command that will rewrite image references in a bundle.
+func newRewriteImagesCmd() *cobra.Command {
+	c := bundleRewriteImagesCmd{}
+	cmd := &cobra.Command{
+		Use:     "rewrite-images <bundle-dir>",
+		Short:   "Rewrite the registry of image references in an operator bundle",
+		Long:    rewriteImagesLongHelp,
+		Example: rewriteImagesExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(args); err != nil {
+				return fmt.Errorf("invalid command args: %v", err)
+			}
+
+			rewrites, err := c.run(context.TODO(), args[0])
+			if err != nil {
+				log.Fatalf("Error rewriting bundle images: %v", err)
+			}
+			if c.dryRun {
+				for _, r := range rewrites {
+					fmt.Println(r)
+				}
+				return nil
+			}
+			log.Infof("Rewrote %d image reference(s) in bundle %s", len(rewrites), args[0])
+
+			return nil
+		},
+	}
+
+	c.addToFlagSet(cmd.Flags())
+
+	return cmd
+}
+
+// validate verifies the command args
+func (c bundleRewriteImagesCmd) validate(args []string) error {
+	if len(args) != 1 {
+		return errors.New("a bundle directory is a required argument")
+	}
+	if c.fromRegistry == "" || c.toRegistry == "" {
+		return errors.New("--from-registry and --to-registry must be set")
+	}
+	if c.fromRegistry == c.toRegistry {
+		return errors.New("--from-registry and --to-registry must differ")
+	}
+	return nil
+}
+
+// addToFlagSet adds the command's flags to fs.
+func (c *bundleRewriteImagesCmd) addToFlagSet(fs *pflag.FlagSet) {
+	fs.StringVar(&c.fromRegistry, "from-registry", "", "Registry prefix of image references to rewrite, "+
+		"ex. quay.io/example")
+	fs.StringVar(&c.toRegistry, "to-registry", "", "Registry prefix to rewrite image references to, "+
+		"ex. registry.example.com/mirror")
+	fs.BoolVar(&c.resolveDigests, "resolve-digests", false, "Replace the tag of each rewritten reference "+
+		"with its image's digest, read from the rewritten reference's registry")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "Print each rewrite without writing the bundle")
+}
+
+// run rewrites image references in the CSV of the bundle in bundleRoot,
+// and writes the CSV unless c.dryRun is set.
+func (c bundleRewriteImagesCmd) run(ctx context.Context, bundleRoot string) ([]internalregistry.ImageRewrite, error) {
+	manifestsDir := filepath.Join(bundleRoot, registrybundle.ManifestsDir)
+	bundle, err := apimanifests.GetBundleFromDir(manifestsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle: %v", err)
+	}
+	if bundle.CSV == nil {
+		return nil, fmt.Errorf("no ClusterServiceVersion found in %s", manifestsDir)
+	}
+
+	// Edit the CSV as unstructured data so fields not in the CSV type are preserved.
+	csvPath, csv, err := findCSVFile(manifestsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	rewriter := internalregistry.ImageRewriter{From: c.fromRegistry, To: c.toRegistry}
+	if c.resolveDigests {
+		resolver, err := containerdregistry.NewResolver("", false, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating image resolver: %v", err)
+		}
+		rewriter.ResolveDigest = internalregistry.NewDigestResolver(resolver)
+	}
+	rewrites, err := rewriter.RewriteCSV(ctx, csv)
+	if err != nil {
+		return nil, fmt.Errorf("error rewriting %s: %v", csvPath, err)
+	}
+	if c.dryRun || len(rewrites) == 0 {
+		return rewrites, nil
+	}
+
+	b, err := yaml.Marshal(csv)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(csvPath, b, info.Mode()); err != nil {
+		return nil, fmt.Errorf("error writing %s: %v", csvPath, err)
+	}
+	// Make sure the rewritten bundle can still be loaded.
+	if _, err := apimanifests.GetBundleFromDir(manifestsDir); err != nil {
+		return nil, fmt.Errorf("error reading rewritten bundle: %v", err)
+	}
+	return rewrites, nil
+}
+
+// findCSVFile returns the path to and contents of the CSV manifest in manifestsDir.
+func findCSVFile(manifestsDir string) (string, map[string]interface{}, error) {
+	infos, err := ioutil.ReadDir(manifestsDir)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		path := filepath.Join(manifestsDir, info.Name())
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		obj := map[string]interface{}{}
+		// Non-manifest files are not the CSV.
+		if err := yaml.Unmarshal(b, &obj); err != nil {
+			continue
+		}
+		if obj["kind"] == "ClusterServiceVersion" {
+			return path, obj, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no ClusterServiceVersion manifest found in %s", manifestsDir)
+}
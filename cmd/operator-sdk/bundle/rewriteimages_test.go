@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const rewriteImagesCSV = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+spec:
+  version: 0.0.1
+  install:
+    strategy: deployment
+    spec:
+      deployments:
+      - name: memcached-operator
+        spec:
+          selector:
+            matchLabels:
+              name: memcached-operator
+          template:
+            metadata:
+              labels:
+                name: memcached-operator
+            spec:
+              containers:
+              - name: manager
+                image: quay.io/example/memcached-operator:v0.0.1
+  relatedImages:
+  - name: memcached
+    image: quay.io/example/memcached:1.4.36
+`
+
+var _ = Describe("Running a bundle rewrite-images command", func() {
+	Describe("validate", func() {
+		var cmd bundleRewriteImagesCmd
+		BeforeEach(func() {
+			cmd = bundleRewriteImagesCmd{fromRegistry: "quay.io/example", toRegistry: "registry.example.com/mirror"}
+		})
+
+		It("succeeds with a bundle directory and registries", func() {
+			Expect(cmd.validate([]string{"bundle"})).To(Succeed())
+		})
+		It("fails with no args", func() {
+			Expect(cmd.validate([]string{})).To(MatchError("a bundle directory is a required argument"))
+		})
+		It("fails if a registry is not set", func() {
+			cmd.toRegistry = ""
+			Expect(cmd.validate([]string{"bundle"})).To(MatchError("--from-registry and --to-registry must be set"))
+		})
+		It("fails if the registries are the same", func() {
+			cmd.toRegistry = cmd.fromRegistry
+			Expect(cmd.validate([]string{"bundle"})).To(MatchError("--from-registry and --to-registry must differ"))
+		})
+	})
+
+	Describe("run", func() {
+		var (
+			cmd        bundleRewriteImagesCmd
+			bundleRoot string
+			csvPath    string
+		)
+		BeforeEach(func() {
+			var err error
+			bundleRoot, err = ioutil.TempDir("", "rewrite-images-")
+			Expect(err).NotTo(HaveOccurred())
+			manifestsDir := filepath.Join(bundleRoot, "manifests")
+			Expect(os.Mkdir(manifestsDir, 0755)).To(Succeed())
+			csvPath = filepath.Join(manifestsDir, "memcached-operator.clusterserviceversion.yaml")
+			Expect(ioutil.WriteFile(csvPath, []byte(rewriteImagesCSV), 0644)).To(Succeed())
+			cmd = bundleRewriteImagesCmd{fromRegistry: "quay.io/example", toRegistry: "registry.example.com/mirror"}
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(bundleRoot)).To(Succeed())
+		})
+
+		It("does not write the CSV with --dry-run", func() {
+			cmd.dryRun = true
+			rewrites, err := cmd.run(context.TODO(), bundleRoot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rewrites).To(HaveLen(2))
+			b, err := ioutil.ReadFile(csvPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(rewriteImagesCSV))
+		})
+		It("writes rewritten references to the CSV", func() {
+			rewrites, err := cmd.run(context.TODO(), bundleRoot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rewrites).To(HaveLen(2))
+			b, err := ioutil.ReadFile(csvPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("image: registry.example.com/mirror/memcached-operator:v0.0.1"))
+			Expect(string(b)).To(ContainSubstring("image: registry.example.com/mirror/memcached:1.4.36"))
+			Expect(string(b)).NotTo(ContainSubstring("quay.io/example"))
+		})
+		It("fails if the bundle has no CSV", func() {
+			Expect(os.Remove(csvPath)).To(Succeed())
+			_, err := cmd.run(context.TODO(), bundleRoot)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,96 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/util/signutil"
+)
+
+const (
+	signLongHelp = `The 'operator-sdk bundle sign' command signs an operator bundle image with cosign
+(https://github.com/sigstore/cosign), which must be installed, and pushes the signature to the
+image's repository. The reference of the signature is printed once the image is signed.
+
+By default the image is signed keylessly: cosign obtains an OIDC identity token, either from the
+environment or by opening a browser to log in, and signs with a short-lived certificate bound to
+that identity. Set '--key' to sign with a cosign private key file or KMS key URI instead.
+
+NOTE: the image must exist in a remote registry, not just locally.
+`
+
+	signExamples = `  # Build and push a bundle image using the docker CLI.
+  $ make bundle-build BUNDLE_IMG=quay.io/example/test-operator-bundle:v0.1.0
+  $ docker push quay.io/example/test-operator-bundle:v0.1.0
+
+  # Sign the image keylessly.
+  $ operator-sdk bundle sign quay.io/example/test-operator-bundle:v0.1.0
+
+  # Sign the image with a key pair created by 'cosign generate-key-pair'.
+  $ operator-sdk bundle sign quay.io/example/test-operator-bundle:v0.1.0 --key cosign.key
+`
+)
+
+type bundleSignCmd struct {
+	key string
+}
+
+// newSignCmd returns a command that will sign an operator bundle image.
+func newSignCmd() *cobra.Command {
+	c := bundleSignCmd{}
+	cmd := &cobra.Command{
+		Use:     "sign <image>",
+		Short:   "Sign an operator bundle image",
+		Long:    signLongHelp,
+		Example: signExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(args); err != nil {
+				return fmt.Errorf("invalid command args: %v", err)
+			}
+
+			sigRef, err := signutil.Sign(args[0], signutil.Options{Key: c.key})
+			if err != nil {
+				log.Fatalf("Error signing image %s: %v", args[0], err)
+			}
+			fmt.Println(sigRef)
+
+			return nil
+		},
+	}
+
+	c.addToFlagSet(cmd.Flags())
+
+	return cmd
+}
+
+// validate verifies the command args
+func (c bundleSignCmd) validate(args []string) error {
+	if len(args) != 1 {
+		return errors.New("an image tag is a required argument")
+	}
+	return nil
+}
+
+// addToFlagSet adds the command's flags to fs.
+func (c *bundleSignCmd) addToFlagSet(fs *pflag.FlagSet) {
+	fs.StringVar(&c.key, "key", "", "Path to a cosign private key, or KMS key URI, to sign the image with. "+
+		"Defaults to keyless signing")
+}
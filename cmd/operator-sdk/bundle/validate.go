@@ -15,11 +15,13 @@
 package bundle
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	apierrors "github.com/operator-framework/api/pkg/validation/errors"
@@ -47,6 +49,22 @@ More information about operator bundles and metadata:
 https://github.com/operator-framework/operator-registry/blob/master/docs/design/operator-bundle.md
 
 NOTE: if validating an image, the image must exist in a remote registry, not just locally.
+
+Set '--fix' to repair the following issues in a bundle directory before it is validated, printing each repair:
+- a missing media type annotation is set to 'registry+v1'
+- the channels annotation is trimmed, de-duplicated, and sorted
+- empty, duplicate, and whitespace-padded CSV keywords and categories are trimmed or removed
+
+Repairs that change how the bundle behaves on a cluster are only made if '--fix-destructive' is also set,
+and are otherwise printed as warnings:
+- a status subresource is added to CRD versions whose schema has a status property
+
+Other issues are not repaired and are reported as usual.
+
+Optional validators are only run if selected with '--select-optional':
+- k8s-deprecated-apis: checks that manifests use no APIs removed in the Kubernetes or OpenShift versions
+  set by the 'k8s-version' and 'ocp-version' optional values, each a comma-separated list of versions.
+  Results are reported per version, followed by a summary of the versions the bundle is clean for.
 `
 
 	examples = `The following command flow will generate test-operator bundle manifests and metadata,
@@ -69,15 +87,44 @@ To build and validate an image built with the above manifests and metadata:
 
   # Ensure the image with modified metadata and Dockerfile is valid.
   $ operator-sdk bundle validate quay.io/$NAMESPACE/test-operator:v0.1.0
+
+To print results as a compact table, ex. in CI logs, optionally selecting its columns:
+
+  $ operator-sdk bundle validate ./bundle --output table --columns severity,message
+
+To check that the operator's images, read from their registries, support every architecture and
+operating system declared by the CSV's 'operatorframework.io/arch.<arch>' and 'operatorframework.io/os.<os>' labels:
+
+  $ operator-sdk bundle validate ./bundle --verify-image-arch
+
+To repair simple issues in a bundle directory before validating it, then also add missing
+status subresources to its CRDs:
+
+  $ operator-sdk bundle validate ./bundle --fix
+  $ operator-sdk bundle validate ./bundle --fix --fix-destructive
+
+To check that the bundle uses no APIs removed in several Kubernetes and OpenShift versions at once:
+
+  $ operator-sdk bundle validate ./bundle --select-optional k8s-deprecated-apis \
+      --optional-values k8s-version=1.21,1.22 --optional-values ocp-version=4.8,4.9
 `
 )
 
 type bundleValidateCmd struct {
 	bundleCmd
 
-	outputFormat string
+	outputFormat    string
+	columns         []string
+	verifyImageArch bool
+	fix             bool
+	fixDestructive  bool
+	selectOptional  []string
+	optionalValues  []string
 }
 
+// optionalValidators are validators only run if selected with --select-optional.
+var optionalValidators = []string{internalregistry.DeprecatedAPIsValidator}
+
 // newValidateCmd returns a command that will validate an operator bundle.
 func newValidateCmd() *cobra.Command {
 	cmd := makeValidateCmd()
@@ -111,6 +158,7 @@ func makeValidateCmd() *cobra.Command {
 			if err != nil {
 				logger.Fatal(err)
 			}
+			result.Columns = c.columns
 			if err := result.PrintWithFormat(c.outputFormat); err != nil {
 				logger.Fatal(err)
 			}
@@ -131,14 +179,77 @@ func (c bundleValidateCmd) validate(args []string) error {
 	if len(args) != 1 {
 		return errors.New("an image tag or directory is a required argument")
 	}
-	if c.outputFormat != internal.JSONAlpha1 && c.outputFormat != internal.Text {
+	if c.outputFormat != internal.JSONAlpha1 && c.outputFormat != internal.Text && c.outputFormat != internal.Table {
 		return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
 
 	}
+	if len(c.columns) != 0 && c.outputFormat != internal.Table {
+		return fmt.Errorf("columns can only be set with output %q", internal.Table)
+	}
+	for _, col := range c.columns {
+		if !isTableColumn(col) {
+			return fmt.Errorf("invalid value for columns flag: %q must be one of %q", col, internal.TableColumns)
+		}
+	}
+	if c.fixDestructive && !c.fix {
+		return errors.New("--fix-destructive can only be set with --fix")
+	}
+	for _, name := range c.selectOptional {
+		if !isOptionalValidator(name) {
+			return fmt.Errorf("invalid value for select-optional flag: %q must be one of %q", name, optionalValidators)
+		}
+	}
+	targets, err := c.deprecatedAPIsTargets()
+	if err != nil {
+		return err
+	}
+	if c.isOptionalSelected(internalregistry.DeprecatedAPIsValidator) && len(targets) == 0 {
+		return fmt.Errorf("optional validator %s requires optional value %s or %s", internalregistry.DeprecatedAPIsValidator,
+			internalregistry.K8sVersionOptionalValue, internalregistry.OCPVersionOptionalValue)
+	}
 
 	return nil
 }
 
+// parseOptionalValues parses c.optionalValues, each of the form <key>=<value>, into a map.
+func (c bundleValidateCmd) parseOptionalValues() (map[string]string, error) {
+	values := make(map[string]string, len(c.optionalValues))
+	for _, kv := range c.optionalValues {
+		split := strings.SplitN(kv, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid value for optional-values flag: %q must have the format <key>=<value>", kv)
+		}
+		switch key := split[0]; key {
+		case internalregistry.K8sVersionOptionalValue, internalregistry.OCPVersionOptionalValue:
+			values[key] = split[1]
+		default:
+			return nil, fmt.Errorf("invalid value for optional-values flag: key %q must be one of %q", key,
+				[]string{internalregistry.K8sVersionOptionalValue, internalregistry.OCPVersionOptionalValue})
+		}
+	}
+	return values, nil
+}
+
+// deprecatedAPIsTargets returns the versions set by c's optional values to check for removed APIs.
+func (c bundleValidateCmd) deprecatedAPIsTargets() ([]internalregistry.DeprecatedAPIsTarget, error) {
+	values, err := c.parseOptionalValues()
+	if err != nil {
+		return nil, err
+	}
+	return internalregistry.ParseDeprecatedAPIsTargets(
+		values[internalregistry.K8sVersionOptionalValue], values[internalregistry.OCPVersionOptionalValue])
+}
+
+// isOptionalSelected returns true if optional validator name was selected.
+func (c bundleValidateCmd) isOptionalSelected(name string) bool {
+	for _, s := range c.selectOptional {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
 // TODO: add a "permissive" flag to toggle whether warnings also cause a non-zero
 // exit code to be returned (true by default).
 func (c *bundleValidateCmd) addToFlagSet(fs *pflag.FlagSet) {
@@ -147,12 +258,48 @@ func (c *bundleValidateCmd) addToFlagSet(fs *pflag.FlagSet) {
 			"One of: [docker, podman, none]")
 
 	fs.StringVarP(&c.outputFormat, "output", "o", internal.Text,
-		"Result format for results. One of: [text, json-alpha1]")
+		"Result format for results. One of: [text, json-alpha1, table]")
 	// It is hidden because it is an alpha option
 	// The idea is the next versions of Operator Registry will return a List of errors
 	if err := fs.MarkHidden("output"); err != nil {
 		panic(err)
 	}
+	fs.StringSliceVar(&c.columns, "columns", nil,
+		fmt.Sprintf("Columns printed by the table output format. Any of: %q", internal.TableColumns))
+	fs.BoolVar(&c.verifyImageArch, "verify-image-arch", false,
+		"Warn if the operator's images do not support each architecture and operating system declared "+
+			"by the CSV's arch and os labels. Queries each image's registry")
+	fs.BoolVar(&c.fix, "fix", false,
+		"Repair simple issues in a bundle directory before validating it. See the command help for the repairs made")
+	fs.BoolVar(&c.fixDestructive, "fix-destructive", false,
+		"Also make repairs that change how the bundle behaves on a cluster, "+
+			"ex. adding CRD status subresources. Requires --fix")
+	fs.StringSliceVar(&c.selectOptional, "select-optional", nil,
+		fmt.Sprintf("Optional validators to run. Any of: %q", optionalValidators))
+	fs.StringArrayVar(&c.optionalValues, "optional-values", nil,
+		"Values passed to optional validators, of the form <key>=<value>. May be set more than once. "+
+			"Keys: ["+internalregistry.K8sVersionOptionalValue+", "+internalregistry.OCPVersionOptionalValue+
+			"], each a comma-separated list of versions")
+}
+
+// isOptionalValidator returns true if name is an optional validator.
+func isOptionalValidator(name string) bool {
+	for _, v := range optionalValidators {
+		if name == v {
+			return true
+		}
+	}
+	return false
+}
+
+// isTableColumn returns true if col is a column the table output format can print.
+func isTableColumn(col string) bool {
+	for _, tc := range internal.TableColumns {
+		if col == tc {
+			return true
+		}
+	}
+	return false
 }
 
 func (c bundleValidateCmd) run(logger *log.Entry, bundle string) (res internal.Result, err error) {
@@ -173,6 +320,9 @@ func (c bundleValidateCmd) run(logger *log.Entry, bundle string) (res internal.R
 			return res, err
 		}
 	} else {
+		if c.fix {
+			return res, fmt.Errorf("--fix can only be set when validating a bundle directory, not image %s", bundle)
+		}
 		c.directory, err = ioutil.TempDir("", "bundle-")
 		if err != nil {
 			return res, err
@@ -190,6 +340,15 @@ func (c bundleValidateCmd) run(logger *log.Entry, bundle string) (res internal.R
 		}
 	}
 
+	// Names of the validators run below, printed in the table output format.
+	const (
+		formatValidator       = "bundle-format"
+		contentValidator      = "bundle-content"
+		dependenciesValidator = "bundle-dependencies"
+		propertiesValidator   = "bundle-properties"
+		imageArchValidator    = "image-arch"
+	)
+
 	// Create Result to be outputted
 	res = internal.NewResult()
 
@@ -199,9 +358,23 @@ func (c bundleValidateCmd) run(logger *log.Entry, bundle string) (res internal.R
 	})
 	val := registrybundle.NewImageValidator(reg, logger)
 
+	// Repair simple issues before validating, if requested.
+	if c.fix {
+		fixed, skipped, err := internalregistry.FixBundle(c.directory, c.fixDestructive)
+		if err != nil {
+			return res, fmt.Errorf("error fixing bundle: %v", err)
+		}
+		for _, f := range fixed {
+			res.AddInfo(fmt.Sprintf("Fixed %s", f))
+		}
+		for _, f := range skipped {
+			res.AddWarn(fmt.Errorf("skipped destructive fix %s: set --fix-destructive to apply it", f))
+		}
+	}
+
 	// Validate bundle format.
 	if err := val.ValidateBundleFormat(c.directory); err != nil {
-		res.AddError(fmt.Errorf("error validating format in %s: %v", c.directory, err))
+		res.AddValidatorError(formatValidator, fmt.Errorf("error validating format in %s: %v", c.directory, err))
 	}
 
 	// Validate bundle content.
@@ -210,12 +383,45 @@ func (c bundleValidateCmd) run(logger *log.Entry, bundle string) (res internal.R
 	manifestsDir := filepath.Join(c.directory, registrybundle.ManifestsDir)
 	results, err := validateBundleContent(logger, manifestsDir)
 	if err != nil {
-		res.AddError(fmt.Errorf("error validating content in %s: %v", manifestsDir, err))
+		res.AddValidatorError(contentValidator, fmt.Errorf("error validating content in %s: %v", manifestsDir, err))
 	}
 
 	// Check the Results will check the []apierrors.ManifestResult returned
 	// from the ValidateBundleContent to add the output(s) into the result
-	checkResults(results, &res)
+	checkResults(contentValidator, results, &res)
+
+	// Validate bundle dependencies, if any.
+	if depResult, err := validateBundleDependencies(c.directory); err != nil {
+		res.AddValidatorError(dependenciesValidator, fmt.Errorf("error validating dependencies in %s: %v", c.directory, err))
+	} else {
+		checkResults(dependenciesValidator, []apierrors.ManifestResult{depResult}, &res)
+	}
+
+	// Validate bundle properties in metadata and in the CSV, if any.
+	if propResult, err := validateBundleProperties(c.directory); err != nil {
+		res.AddValidatorError(propertiesValidator, fmt.Errorf("error validating properties in %s: %v", c.directory, err))
+	} else {
+		checkResults(propertiesValidator, []apierrors.ManifestResult{propResult}, &res)
+	}
+
+	// Validate that operator images support the CSV's declared platforms, if requested.
+	if c.verifyImageArch {
+		logger.Info("Reading operator image platforms from their registries")
+		if archResult, err := validateImageArch(context.TODO(), manifestsDir); err != nil {
+			res.AddValidatorError(imageArchValidator, fmt.Errorf("error validating image architectures: %v", err))
+		} else {
+			checkResults(imageArchValidator, []apierrors.ManifestResult{archResult}, &res)
+		}
+	}
+
+	// Validate that manifests use no APIs removed in each requested version, if selected.
+	if c.isOptionalSelected(internalregistry.DeprecatedAPIsValidator) {
+		targets, err := c.deprecatedAPIsTargets()
+		if err != nil {
+			return res, err
+		}
+		validateDeprecatedAPIs(manifestsDir, targets, &res)
+	}
 
 	return res, nil
 }
@@ -267,15 +473,96 @@ func validateBundleContent(logger *log.Entry, manifestsDir string) ([]apierrors.
 	return internalregistry.ValidateBundleContent(logger, bundle, mediaType), nil
 }
 
-// checkResults logs warnings and errors in results, and returns true if at
-// least one error was encountered.
-func checkResults(results []apierrors.ManifestResult, res *internal.Result) {
+// validateBundleDependencies validates the dependencies of the bundle in bundleRoot
+// against the package in its metadata, if the bundle has any dependencies.
+func validateBundleDependencies(bundleRoot string) (result apierrors.ManifestResult, err error) {
+	result.Name = internalregistry.DependenciesFile
+	if !isExist(filepath.Join(bundleRoot, registrybundle.MetadataDir, internalregistry.DependenciesFile)) {
+		return result, nil
+	}
+	metadata, _, err := internalregistry.FindBundleMetadata(bundleRoot)
+	if err != nil {
+		return result, err
+	}
+	errs, err := internalregistry.ValidateDependencies(bundleRoot, metadata[registrybundle.PackageLabel])
+	if err != nil {
+		return result, err
+	}
+	result.Add(errs...)
+	return result, nil
+}
+
+// validateBundleProperties validates the properties of the bundle in bundleRoot, which may be
+// in its metadata's properties file and in its CSV's properties annotation.
+func validateBundleProperties(bundleRoot string) (result apierrors.ManifestResult, err error) {
+	result.Name = internalregistry.PropertiesFile
+	errs, err := internalregistry.ValidateProperties(bundleRoot)
+	if err != nil {
+		return result, err
+	}
+	result.Add(errs...)
+
+	// Errors reading the bundle are reported by content validation.
+	bundle, err := apimanifests.GetBundleFromDir(filepath.Join(bundleRoot, registrybundle.ManifestsDir))
+	if err == nil && bundle.CSV != nil {
+		result.Add(internalregistry.ValidatePropertiesAnnotation(bundle.CSV.GetAnnotations())...)
+	}
+	return result, nil
+}
+
+// validateImageArch validates that the images in the CSV in manifestsDir support
+// the platforms its arch and os labels declare, reading images from their registries.
+func validateImageArch(ctx context.Context, manifestsDir string) (result apierrors.ManifestResult, err error) {
+	// Errors reading the bundle are reported by content validation.
+	bundle, err := apimanifests.GetBundleFromDir(manifestsDir)
+	if err != nil || bundle.CSV == nil {
+		return result, nil
+	}
+	result.Name = bundle.CSV.GetName()
+	resolver, err := containerdregistry.NewResolver("", false, nil)
+	if err != nil {
+		return result, fmt.Errorf("error creating image resolver: %v", err)
+	}
+	result.Add(internalregistry.ValidateImagePlatforms(ctx, bundle.CSV, internalregistry.NewPlatformsGetter(resolver))...)
+	return result, nil
+}
+
+// validateDeprecatedAPIs adds results of checking the manifests in manifestsDir for APIs removed
+// in each of targets to res, then summarizes which targets the bundle is clean for.
+func validateDeprecatedAPIs(manifestsDir string, targets []internalregistry.DeprecatedAPIsTarget, res *internal.Result) {
+	// Errors reading the bundle are reported by content validation.
+	bundle, err := apimanifests.GetBundleFromDir(manifestsDir)
+	if err != nil {
+		return
+	}
+	var clean, unclean []string
+	for _, t := range targets {
+		validator := fmt.Sprintf("%s (%s)", internalregistry.DeprecatedAPIsValidator, t)
+		result := apierrors.ManifestResult{Name: bundle.Name}
+		result.Add(internalregistry.ValidateDeprecatedAPIs(bundle.Objects, t)...)
+		checkResults(validator, []apierrors.ManifestResult{result}, res)
+		if result.HasError() {
+			unclean = append(unclean, t.String())
+		} else {
+			clean = append(clean, t.String())
+		}
+	}
+	if len(clean) != 0 {
+		res.AddInfo(fmt.Sprintf("Bundle uses no removed APIs in: %s", strings.Join(clean, ", ")))
+	}
+	if len(unclean) != 0 {
+		res.AddInfo(fmt.Sprintf("Bundle uses removed APIs in: %s", strings.Join(unclean, ", ")))
+	}
+}
+
+// checkResults adds warnings and errors in results found by validator to res.
+func checkResults(validator string, results []apierrors.ManifestResult, res *internal.Result) {
 	for _, r := range results {
 		for _, w := range r.Warnings {
-			res.AddWarn(w)
+			res.AddValidatorWarn(validator, w)
 		}
 		for _, e := range r.Errors {
-			res.AddError(e)
+			res.AddValidatorError(validator, e)
 		}
 	}
 }
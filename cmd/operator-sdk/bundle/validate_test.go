@@ -15,9 +15,15 @@
 package bundle
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
 	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/bundle/internal"
+	internalregistry "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
 var _ = Describe("Running a bundle validate command", func() {
@@ -72,5 +78,125 @@ var _ = Describe("Running a bundle validate command", func() {
 			err = cmd.validate([]string{"quay.io/person/example"})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("succeeds if the arg is table with valid columns", func() {
+			cmd.outputFormat = "table"
+			err := cmd.validate([]string{"quay.io/person/example"})
+			Expect(err).NotTo(HaveOccurred())
+
+			cmd.columns = []string{"severity", "message"}
+			err = cmd.validate([]string{"quay.io/person/example"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails if columns are invalid or set without the table format", func() {
+			cmd.outputFormat = "table"
+			cmd.columns = []string{"severity", "name"}
+			err := cmd.validate([]string{"quay.io/person/example"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`"name" must be one of`))
+
+			cmd.outputFormat = "text"
+			cmd.columns = []string{"severity"}
+			err = cmd.validate([]string{"quay.io/person/example"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`columns can only be set with output "table"`))
+		})
+
+		It("fails if fix-destructive is set without fix", func() {
+			cmd.outputFormat = "text"
+			cmd.fixDestructive = true
+			err := cmd.validate([]string{"./bundle"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("--fix-destructive can only be set with --fix"))
+
+			cmd.fix = true
+			Expect(cmd.validate([]string{"./bundle"})).To(Succeed())
+		})
+
+		It("validates optional validators and their values", func() {
+			cmd.outputFormat = "text"
+			cmd.selectOptional = []string{"operatorhub"}
+			err := cmd.validate([]string{"./bundle"})
+			Expect(err).To(MatchError(ContainSubstring(`"operatorhub" must be one of`)))
+
+			cmd.selectOptional = []string{"k8s-deprecated-apis"}
+			err = cmd.validate([]string{"./bundle"})
+			Expect(err).To(MatchError("optional validator k8s-deprecated-apis requires optional value k8s-version or ocp-version"))
+
+			cmd.optionalValues = []string{"k8s-version"}
+			err = cmd.validate([]string{"./bundle"})
+			Expect(err).To(MatchError(ContainSubstring("must have the format <key>=<value>")))
+
+			cmd.optionalValues = []string{"kube-version=1.22"}
+			err = cmd.validate([]string{"./bundle"})
+			Expect(err).To(MatchError(ContainSubstring(`key "kube-version" must be one of`)))
+
+			cmd.optionalValues = []string{"k8s-version=1.21,latest"}
+			err = cmd.validate([]string{"./bundle"})
+			Expect(err).To(MatchError(ContainSubstring(`k8s-version "latest"`)))
+
+			cmd.optionalValues = []string{"k8s-version=1.21,1.22", "ocp-version=4.8,4.9"}
+			Expect(cmd.validate([]string{"./bundle"})).To(Succeed())
+		})
+	})
+
+	Describe("validateDeprecatedAPIs", func() {
+		var manifestsDir string
+
+		BeforeEach(func() {
+			var err error
+			manifestsDir, err = ioutil.TempDir("", "manifests-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(manifestsDir, "csv.yaml"), []byte(deprecatedAPIsCSV), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(manifestsDir, "crd.yaml"), []byte(deprecatedAPIsCRD), 0644)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(manifestsDir)).To(Succeed())
+		})
+
+		It("reports results per version and summarizes clean versions", func() {
+			targets, err := internalregistry.ParseDeprecatedAPIsTargets("1.21,1.22", "4.8,4.9")
+			Expect(err).NotTo(HaveOccurred())
+			res := internal.NewResult()
+			validateDeprecatedAPIs(manifestsDir, targets, &res)
+			Expect(res.Passed).To(BeFalse())
+
+			var validators, infos []string
+			for _, o := range res.Outputs {
+				if o.Type == "error" {
+					Expect(o.Message).To(ContainSubstring("uses apiextensions.k8s.io/v1beta1, which was removed in Kubernetes 1.22"))
+					validators = append(validators, o.Validator)
+				} else {
+					infos = append(infos, o.Message)
+				}
+			}
+			Expect(validators).To(Equal([]string{"k8s-deprecated-apis (k8s 1.22)", "k8s-deprecated-apis (ocp 4.9)"}))
+			Expect(infos).To(Equal([]string{
+				"Bundle uses no removed APIs in: k8s 1.21, ocp 4.8",
+				"Bundle uses removed APIs in: k8s 1.22, ocp 4.9",
+			}))
+		})
 	})
 })
+
+const deprecatedAPIsCSV = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+spec:
+  version: 0.0.1
+`
+
+const deprecatedAPIsCRD = `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  version: v1alpha1
+`
@@ -45,6 +45,10 @@ ex. '<project-root>/packagemanifests'.`,
 	}
 
 	c.PackageManifestsCmd.AddToFlagSet(cmd.Flags())
+	cmd.Flags().DurationVar(&c.CleanupTimeout, "cleanup-timeout", 0,
+		"Time to wait for each resource to be deleted before removing its finalizers to force its deletion. "+
+			"Only resources created by operator-sdk, and the CSV installed by its Subscription, are forced. "+
+			"Finalizers are never removed if unset")
 
 	return cmd
 }
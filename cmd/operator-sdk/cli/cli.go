@@ -15,6 +15,7 @@
 package cli
 
 import (
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/alpha"
 	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/build"
 	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/bundle"
 	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/cleanup"
@@ -40,6 +41,7 @@ var commands = []*cobra.Command{
 	// The "new" cmd provides a way to scaffold Helm/Ansible projects
 	// from the new CLI.
 	new.NewCmd(),
+	alpha.NewCmd(),
 	scorecard.NewCmd(),
 	build.NewCmd(),
 	bundle.NewCmd(),
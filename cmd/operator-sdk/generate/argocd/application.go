@@ -0,0 +1,186 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultNamespace  = "argocd"
+	defaultProject    = "default"
+	defaultRevision   = "HEAD"
+	defaultPath       = "config/default"
+	defaultDestServer = "https://kubernetes.default.svc"
+
+	applicationAPIVersion = "argoproj.io/v1alpha1"
+	applicationKind       = "Application"
+)
+
+// syncOptions are Argo CD sync options suited to applying CRDs, which can
+// exceed the annotation size limit imposed by client-side apply.
+var syncOptions = []string{
+	"Replace=true",
+	"ServerSideApply=true",
+}
+
+// The following types are a minimal subset of Argo CD's Application API,
+// defined here to avoid depending on Argo CD's module.
+
+type application struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Metadata   applicationMetadata `json:"metadata"`
+	Spec       applicationSpec     `json:"spec"`
+}
+
+type applicationMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type applicationSpec struct {
+	Project     string                 `json:"project"`
+	Source      applicationSource      `json:"source"`
+	Destination applicationDestination `json:"destination"`
+	SyncPolicy  syncPolicy             `json:"syncPolicy"`
+}
+
+type applicationSource struct {
+	RepoURL        string `json:"repoURL"`
+	Path           string `json:"path"`
+	TargetRevision string `json:"targetRevision"`
+}
+
+type applicationDestination struct {
+	Server    string `json:"server"`
+	Namespace string `json:"namespace"`
+}
+
+type syncPolicy struct {
+	SyncOptions []string `json:"syncOptions"`
+}
+
+// setDefaults sets command defaults.
+func (c *applicationCmd) setDefaults(cfg *config.Config) {
+	if c.name == "" {
+		if c.name = cfg.ProjectName; c.name == "" {
+			c.name = filepath.Base(cfg.Repo)
+		}
+	}
+}
+
+// validate validates c for Application generation.
+func (c applicationCmd) validate() error {
+	if c.repo == "" {
+		return errors.New("--repo must be set")
+	}
+	if !isValidRepoURL(c.repo) {
+		return fmt.Errorf("--repo %q is not a valid repository URL", c.repo)
+	}
+	if c.path == "" {
+		return errors.New("--path must be set")
+	}
+	if path.IsAbs(c.path) || strings.HasPrefix(path.Clean(c.path), "..") {
+		return fmt.Errorf("--path %q must be relative to the repository root", c.path)
+	}
+	if c.destNamespace == "" {
+		return errors.New("--dest-namespace must be set")
+	}
+	for flag, value := range map[string]string{
+		"--name":           c.name,
+		"--namespace":      c.namespace,
+		"--dest-namespace": c.destNamespace,
+	} {
+		if errs := validation.IsDNS1123Label(value); len(errs) != 0 {
+			return fmt.Errorf("%s %q is invalid: %s", flag, value, strings.Join(errs, ", "))
+		}
+	}
+	if c.destServer == "" {
+		return errors.New("--dest-server must be set")
+	}
+	return nil
+}
+
+// scpRepoRe matches scp-style git repository addresses, ex. "git@github.com:org/repo.git",
+// which url.Parse rejects.
+var scpRepoRe = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:.+$`)
+
+// isValidRepoURL returns true if repo is either an scp-style git address or a URL with a host.
+func isValidRepoURL(repo string) bool {
+	if scpRepoRe.MatchString(repo) {
+		return true
+	}
+	u, err := url.Parse(repo)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// run generates an Application and writes it to c.outputFile or stdout.
+func (c applicationCmd) run() error {
+	b, err := yaml.Marshal(c.newApplication())
+	if err != nil {
+		return err
+	}
+
+	if c.outputFile == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	if dir := filepath.Dir(c.outputFile); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(c.outputFile, b, 0644)
+}
+
+// newApplication returns an Application configured by c.
+func (c applicationCmd) newApplication() application {
+	return application{
+		APIVersion: applicationAPIVersion,
+		Kind:       applicationKind,
+		Metadata: applicationMetadata{
+			Name:      c.name,
+			Namespace: c.namespace,
+		},
+		Spec: applicationSpec{
+			Project: c.project,
+			Source: applicationSource{
+				RepoURL:        c.repo,
+				Path:           path.Clean(filepath.ToSlash(c.path)),
+				TargetRevision: c.revision,
+			},
+			Destination: applicationDestination{
+				Server:    c.destServer,
+				Namespace: c.destNamespace,
+			},
+			SyncPolicy: syncPolicy{
+				SyncOptions: syncOptions,
+			},
+		},
+	}
+}
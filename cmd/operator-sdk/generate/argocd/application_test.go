@@ -0,0 +1,134 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+const expectedApplication = `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: memcached-operator
+  namespace: argocd
+spec:
+  destination:
+    namespace: memcached-operator-system
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: bundle/manifests
+    repoURL: git@github.com:example/memcached-operator.git
+    targetRevision: HEAD
+  syncPolicy:
+    syncOptions:
+    - Replace=true
+    - ServerSideApply=true
+`
+
+func newTestApplicationCmd() applicationCmd {
+	return applicationCmd{
+		name:          "memcached-operator",
+		namespace:     defaultNamespace,
+		project:       defaultProject,
+		repo:          "https://github.com/example/memcached-operator.git",
+		revision:      defaultRevision,
+		path:          defaultPath,
+		destServer:    defaultDestServer,
+		destNamespace: "memcached-operator-system",
+	}
+}
+
+var _ = Describe("Generating an Argo CD Application", func() {
+	Describe("setDefaults", func() {
+		DescribeTable("sets the Application name",
+			func(name string, cfg config.Config, expected string) {
+				c := applicationCmd{name: name}
+				c.setDefaults(&cfg)
+				Expect(c.name).To(Equal(expected))
+			},
+			Entry("from --name", "my-app", config.Config{ProjectName: "memcached-operator"}, "my-app"),
+			Entry("from the project name", "", config.Config{ProjectName: "memcached-operator"}, "memcached-operator"),
+			Entry("from the repo", "", config.Config{Repo: "github.com/example/memcached-operator"}, "memcached-operator"),
+		)
+	})
+
+	Describe("validate", func() {
+		DescribeTable("returns no error for valid options",
+			func(modify func(*applicationCmd)) {
+				c := newTestApplicationCmd()
+				modify(&c)
+				Expect(c.validate()).To(Succeed())
+			},
+			Entry("with defaults", func(*applicationCmd) {}),
+			Entry("with an scp-style repo", func(c *applicationCmd) { c.repo = "git@github.com:example/memcached-operator.git" }),
+			Entry("with an ssh repo URL", func(c *applicationCmd) { c.repo = "ssh://git@github.com/example/memcached-operator.git" }),
+			Entry("with a nested path", func(c *applicationCmd) { c.path = "./bundle/manifests" }),
+		)
+		DescribeTable("returns an error for invalid options",
+			func(modify func(*applicationCmd), errMsg string) {
+				c := newTestApplicationCmd()
+				modify(&c)
+				Expect(c.validate()).To(MatchError(ContainSubstring(errMsg)))
+			},
+			Entry("with no repo", func(c *applicationCmd) { c.repo = "" }, "--repo must be set"),
+			Entry("with a repo with no host", func(c *applicationCmd) { c.repo = "example/memcached-operator" },
+				"is not a valid repository URL"),
+			Entry("with an unparseable repo", func(c *applicationCmd) { c.repo = "https://github.com/%zz" },
+				"is not a valid repository URL"),
+			Entry("with no path", func(c *applicationCmd) { c.path = "" }, "--path must be set"),
+			Entry("with an absolute path", func(c *applicationCmd) { c.path = "/config/default" },
+				"must be relative to the repository root"),
+			Entry("with a path outside the repo", func(c *applicationCmd) { c.path = "../config/default" },
+				"must be relative to the repository root"),
+			Entry("with no dest namespace", func(c *applicationCmd) { c.destNamespace = "" }, "--dest-namespace must be set"),
+			Entry("with an invalid name", func(c *applicationCmd) { c.name = "Memcached_Operator" }, "--name"),
+			Entry("with an invalid namespace", func(c *applicationCmd) { c.namespace = "argo.cd" }, "--namespace"),
+			Entry("with no dest server", func(c *applicationCmd) { c.destServer = "" }, "--dest-server must be set"),
+		)
+	})
+
+	Describe("run", func() {
+		var tmp string
+
+		BeforeEach(func() {
+			var err error
+			tmp, err = ioutil.TempDir("", "argocd-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmp)).To(Succeed())
+		})
+
+		It("writes the Application manifest to --output-file", func() {
+			c := newTestApplicationCmd()
+			c.repo = "git@github.com:example/memcached-operator.git"
+			c.path = "bundle/manifests/"
+			c.outputFile = filepath.Join(tmp, "deploy", "argocd-application.yaml")
+			Expect(c.run()).To(Succeed())
+
+			b, err := ioutil.ReadFile(c.outputFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(expectedApplication))
+		})
+	})
+})
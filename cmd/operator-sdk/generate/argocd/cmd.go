@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
+)
+
+const longHelp = `
+Running 'generate argocd-application' emits an Argo CD Application manifest that deploys
+the operator from a git repository. By default the Application points at the project's
+'config/default' kustomize directory; set '--path' to deploy bundle manifests or another
+directory instead.
+
+The Application is configured with sync options suited to CustomResourceDefinitions,
+which may be too large for client-side apply: 'Replace=true' and 'ServerSideApply=true'.
+`
+
+const examples = `
+  # Write an Application to stdout:
+  $ operator-sdk generate argocd-application \
+      --repo https://github.com/example/memcached-operator.git \
+      --dest-namespace memcached-operator-system
+
+  # Write an Application deploying bundle manifests to a file:
+  $ operator-sdk generate argocd-application \
+      --repo https://github.com/example/memcached-operator.git \
+      --path bundle/manifests \
+      --dest-namespace memcached-operator-system \
+      --output-file argocd-application.yaml
+`
+
+type applicationCmd struct {
+	name          string
+	namespace     string
+	project       string
+	repo          string
+	revision      string
+	path          string
+	destServer    string
+	destNamespace string
+	outputFile    string
+}
+
+// NewCmd returns the 'argocd-application' command configured for the new project layout.
+func NewCmd() *cobra.Command {
+	c := &applicationCmd{}
+	cmd := &cobra.Command{
+		Use:     "argocd-application",
+		Short:   "Generates an Argo CD Application for the operator",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+
+			cfg, err := kbutil.ReadConfig()
+			if err != nil {
+				return fmt.Errorf("error reading configuration: %v", err)
+			}
+			c.setDefaults(cfg)
+
+			if err = c.validate(); err != nil {
+				return fmt.Errorf("invalid command options: %v", err)
+			}
+
+			if err = c.run(); err != nil {
+				log.Fatalf("Error generating Argo CD Application: %v", err)
+			}
+
+			return nil
+		},
+	}
+
+	c.addFlagsTo(cmd.Flags())
+
+	return cmd
+}
+
+func (c *applicationCmd) addFlagsTo(fs *pflag.FlagSet) {
+	fs.StringVar(&c.name, "name", "", "Name of the Application. Defaults to the project name")
+	fs.StringVar(&c.namespace, "namespace", defaultNamespace, "Namespace Argo CD is installed in")
+	fs.StringVar(&c.project, "project", defaultProject, "Argo CD project the Application belongs to")
+	fs.StringVar(&c.repo, "repo", "", "URL of the git repository containing the operator's manifests (required)")
+	fs.StringVar(&c.revision, "revision", defaultRevision, "Git revision of --repo to deploy")
+	fs.StringVar(&c.path, "path", defaultPath, "Path in --repo to the operator's manifests, "+
+		"ex. 'config/default' or 'bundle/manifests'")
+	fs.StringVar(&c.destServer, "dest-server", defaultDestServer, "URL of the cluster to deploy the operator to")
+	fs.StringVar(&c.destNamespace, "dest-namespace", "", "Namespace to deploy the operator to (required)")
+	fs.StringVar(&c.outputFile, "output-file", "", "File to write the Application to. Defaults to stdout")
+}
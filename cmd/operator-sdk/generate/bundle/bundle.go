@@ -15,6 +15,7 @@
 package bundle
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -23,7 +24,9 @@ import (
 	"sort"
 	"strings"
 
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
 	yaml "gopkg.in/yaml.v3"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 
@@ -34,6 +37,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
 	"github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
 )
@@ -50,6 +54,48 @@ for the first time or upgrading an existing one.
 If '--output-dir' is set and you wish to build bundle images from that directory,
 either manually update your bundle.Dockerfile or set '--overwrite'.
 
+Paths in the bundle directory matching patterns in its '.bundleignore' file, which has '.dockerignore'
+syntax with patterns relative to the bundle directory, are excluded from the bundle image. Editor and
+OS files matching '**/.DS_Store' and '**/*.swp' are always excluded. These patterns are written to
+a generated block of the project's '.dockerignore', since the bundle image's build context is the
+project directory.
+
+If the output directory already contains CustomResourceDefinitions from a previous bundle,
+a warning is logged for each schema change that can break existing custom resources:
+removed fields, newly required fields, narrowed types, and removed enum values.
+
+Set '--assess-capabilities' to log a capability level suggested by inspecting your manifests
+for signals of each level, or '--set-capabilities' to also write it to the CSV's 'capabilities'
+annotation. The assessment is conservative; see the operator capabilities documentation for its criteria.
+
+Set '--enable-cleanup' to set the CSV's 'spec.cleanup.enabled' to true, so OLM v0.17.0+ deletes the custom
+resources of the CSV's owned CRDs when the operator is uninstalled. The CSV must own at least one CRD, and
+a warning is logged for each owned CRD the operator cannot update, since it may need to remove finalizers
+from the resources being deleted. Cleanup stays enabled when a bundle that enables it is regenerated.
+
+Set '--include-object' to include a ConfigMap or Secret from your manifests in the bundle's manifests,
+ex. one mounted by your operator's Deployment. Each referenced object must exist in the input manifests
+under its rendered name, and a warning is logged for each Secret with literal data, since bundle images
+are not encrypted.
+
+Set '--install-mode-variants' to generate a namespaced variant supporting OwnNamespace and SingleNamespace installs,
+and a cluster-wide variant supporting AllNamespaces installs, with RBAC scoped accordingly. Each variant is its own
+package named '<operator-name>-namespaced' or '<operator-name>-cluster', written to the 'namespaced' or 'cluster'
+subdirectory of the output directory with a bundle.namespaced.Dockerfile or bundle.cluster.Dockerfile, and validated
+separately. Since the variants' upgrade graphs are separate, always regenerate both together.
+
+Set '--verbose-diff' to print a unified diff of each bundle file, including the bundle.Dockerfile, that the
+command added, removed, or modified, ex. to review regenerated changes. It is off by default.
+
+A warning is logged if the project directory's name does not match the PROJECT file's 'projectName',
+ignoring case, since names generated from either will not agree. Set '--skip-project-name-check'
+to suppress it.
+
+If your manifests are rendered by other tooling, set '--input-dir' to a directory of pre-rendered
+manifests containing a ClusterServiceVersion, CustomResourceDefinitions, and any other bundle objects.
+These manifests are packaged as-is into the bundle's manifests directory, along with bundle metadata
+and a bundle.Dockerfile, without reading kustomize bases or other project manifests.
+
 More information on bundles:
 https://github.com/operator-framework/operator-registry/#manifest-format
 `
@@ -89,6 +135,18 @@ https://github.com/operator-framework/operator-registry/#manifest-format
   └── metadata
       └── annotations.yaml
 
+  # If your operator requires other operators, pass their packages or APIs to write
+  # them to bundle/metadata/dependencies.yaml:
+  $ kustomize build config/manifests | operator-sdk generate bundle --overwrite --version 0.0.1 \
+      --dependency="olm.package:etcd:>=0.9.0 <0.10.0" \
+      --dependency=olm.gvk:monitoring.coreos.com/v1/Prometheus
+
+  # Bundle properties, ex. the latest OpenShift version your operator supports, are written to
+  # bundle/metadata/properties.yaml, or to the CSV's olm.properties annotation:
+  $ kustomize build config/manifests | operator-sdk generate bundle --overwrite --version 0.0.1 \
+      --property='olm.maxOpenShiftVersion:"4.8"' \
+      --properties-placement=annotation
+
   # Then it validates your bundle files and builds your bundle image:
   $ operator-sdk bundle validate ./bundle
   $ docker build -f bundle.Dockerfile -t $BUNDLE_IMG .
@@ -96,6 +154,11 @@ https://github.com/operator-framework/operator-registry/#manifest-format
   Step 1/9 : FROM scratch
   ...
 
+  # If your pipeline renders its own manifests, package them into a bundle directly:
+  $ ls rendered
+  cache.my.domain_memcacheds.yaml  memcached-operator.clusterserviceversion.yaml
+  $ operator-sdk generate bundle --input-dir rendered --output-dir bundle
+
   # You can then push your bundle image:
   $ make docker-push IMG=$BUNDLE_IMG
 `
@@ -104,6 +167,12 @@ https://github.com/operator-framework/operator-registry/#manifest-format
 // defaultRootDir is the default root directory in which to generate bundle files.
 const defaultRootDir = "bundle"
 
+// Valid --properties-placement values.
+const (
+	propertiesPlacementFile       = "file"
+	propertiesPlacementAnnotation = "annotation"
+)
+
 // setDefaults sets defaults useful to all modes of this subcommand.
 func (c *bundleCmd) setDefaults(cfg *config.Config) {
 	if c.operatorName == "" {
@@ -116,12 +185,35 @@ func (c *bundleCmd) setDefaults(cfg *config.Config) {
 	}
 }
 
+// checkProjectName logs a warning if the project directory's name does not match the PROJECT file's projectName,
+// since generated names derived from either will not agree.
+func (c bundleCmd) checkProjectName() {
+	if err := projutil.CheckProjectNameConsistency(); err != nil {
+		log.Warnf("%v. Set --skip-project-name-check to suppress this warning", err)
+	}
+}
+
 // validateManifests validates c for bundle manifests generation.
 func (c bundleCmd) validateManifests(*config.Config) (err error) {
+	if c.isRenderedInput() {
+		return c.validateRenderedManifests()
+	}
+
 	if c.version != "" {
 		if err := genutil.ValidateVersion(c.version); err != nil {
 			return err
 		}
+		if err := gencsv.ValidateNameTemplate(c.csvNameTemplate, c.operatorName, c.version); err != nil {
+			return fmt.Errorf("invalid --csv-name-template: %v", err)
+		}
+	}
+
+	if _, err := c.parseProperties(); err != nil {
+		return err
+	}
+
+	if _, err := c.parseIncludeObjects(); err != nil {
+		return err
 	}
 
 	if c.kustomizeDir == "" {
@@ -141,6 +233,12 @@ func (c bundleCmd) validateManifests(*config.Config) (err error) {
 		if c.outputDir != "" {
 			return errors.New("--output-dir cannot be set if writing to stdout")
 		}
+		if c.verboseDiff {
+			return errors.New("--verbose-diff cannot be set if writing to stdout")
+		}
+		if c.installModeVariants {
+			return errors.New("--install-mode-variants cannot be set if writing to stdout")
+		}
 	}
 
 	return nil
@@ -148,6 +246,12 @@ func (c bundleCmd) validateManifests(*config.Config) (err error) {
 
 // runManifests generates bundle manifests.
 func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
+	if c.isRenderedInput() {
+		return c.runRenderedManifests()
+	}
+	if c.installModeVariants {
+		return c.runVariantManifests(cfg)
+	}
 
 	if !c.quiet && !c.stdout {
 		if c.version == "" {
@@ -168,7 +272,11 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 
 	col := &collector.Manifests{}
 	if genutil.IsPipeReader() {
-		if err := col.UpdateFromReader(os.Stdin); err != nil {
+		b, err := genutil.ReadStdin(c.stdinTimeout)
+		if err != nil {
+			return err
+		}
+		if err := col.UpdateFromReader(bytes.NewReader(b)); err != nil {
 			return err
 		}
 	}
@@ -178,11 +286,25 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 		}
 	}
 
+	props, err := c.parseProperties()
+	if err != nil {
+		return err
+	}
+	if c.propertiesPlacement != propertiesPlacementAnnotation {
+		props = nil
+	}
+
 	csvGen := gencsv.Generator{
-		OperatorName: c.operatorName,
-		OperatorType: projutil.PluginKeyToOperatorType(cfg.Layout),
-		Version:      c.version,
-		Collector:    col,
+		OperatorName:       c.operatorName,
+		OperatorType:       projutil.PluginKeyToOperatorType(cfg.Layout),
+		Version:            c.version,
+		CSVNameTemplate:    c.csvNameTemplate,
+		Collector:          col,
+		AssessCapabilities: c.assessCapabilities,
+		SetCapabilities:    c.setCapabilities,
+		EnableCleanup:      c.enableCleanup,
+		Properties:         props,
+		InstallModeVariant: c.variant,
 	}
 
 	stdout := genutil.NewMultiManifestWriter(os.Stdout)
@@ -208,12 +330,26 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 	for _, crd := range col.V1beta1CustomResourceDefinitions {
 		objs = append(objs, crd)
 	}
+	refs, err := c.parseIncludeObjects()
+	if err != nil {
+		return err
+	}
+	included, err := getIncludedObjects(col, refs)
+	if err != nil {
+		return err
+	}
+	for _, obj := range included {
+		objs = append(objs, obj)
+	}
 	if c.stdout {
 		if err := genutil.WriteObjects(stdout, objs...); err != nil {
 			return err
 		}
 	} else {
 		dir := filepath.Join(c.outputDir, bundle.ManifestsDir)
+		if err := warnCRDSchemaBreakingChanges(dir, col); err != nil {
+			return err
+		}
 		if err := genutil.WriteObjectsToFiles(dir, objs...); err != nil {
 			return err
 		}
@@ -231,6 +367,40 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 	return nil
 }
 
+// warnCRDSchemaBreakingChanges logs a warning for each breaking change between the
+// schemas of CRDs in manifestsDir, written for a previous bundle, and those in col.
+func warnCRDSchemaBreakingChanges(manifestsDir string, col *collector.Manifests) error {
+	if !isExist(manifestsDir) {
+		return nil
+	}
+	v1crds, v1beta1crds, err := k8sutil.GetCustomResourceDefinitions(manifestsDir)
+	if err != nil {
+		return fmt.Errorf("error reading existing bundle CustomResourceDefinitions: %v", err)
+	}
+	from, to := &apimanifests.Bundle{}, &apimanifests.Bundle{}
+	for i := range v1crds {
+		from.V1CRDs = append(from.V1CRDs, &v1crds[i])
+	}
+	for i := range v1beta1crds {
+		from.V1beta1CRDs = append(from.V1beta1CRDs, &v1beta1crds[i])
+	}
+	for i := range col.V1CustomResourceDefinitions {
+		to.V1CRDs = append(to.V1CRDs, &col.V1CustomResourceDefinitions[i])
+	}
+	for i := range col.V1beta1CustomResourceDefinitions {
+		to.V1beta1CRDs = append(to.V1beta1CRDs, &col.V1beta1CustomResourceDefinitions[i])
+	}
+
+	changes, err := registry.DiffCRDSchemas(from, to)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		log.Warnf("Breaking CustomResourceDefinition schema change: %s", change)
+	}
+	return nil
+}
+
 // writeScorecardConfig writes cfg to dir at the hard-coded config path 'config.yaml'.
 func writeScorecardConfig(dir string, cfg v1alpha3.Configuration) error {
 	if cfg.Metadata.Name == "" {
@@ -257,13 +427,66 @@ func (c bundleCmd) validateMetadata(*config.Config) (err error) {
 		return fmt.Errorf("--default-channel must be set if setting multiple channels")
 	}
 
+	if _, err := c.parseDependencies(); err != nil {
+		return err
+	}
+
+	props, err := c.parseProperties()
+	if err != nil {
+		return err
+	}
+	if len(props) != 0 && c.propertiesPlacement == propertiesPlacementAnnotation && !c.manifests {
+		return fmt.Errorf("--properties-placement=%s requires generating bundle manifests", propertiesPlacementAnnotation)
+	}
+
 	return nil
 }
 
+// parseDependencies parses each --dependency value.
+func (c bundleCmd) parseDependencies() (deps []registry.Dependency, err error) {
+	for _, d := range c.dependencies {
+		dep, err := registry.ParseDependency(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --dependency: %v", err)
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// parseProperties parses each --property value.
+func (c bundleCmd) parseProperties() (props []registry.Property, err error) {
+	switch c.propertiesPlacement {
+	case propertiesPlacementFile, propertiesPlacementAnnotation:
+	default:
+		return nil, fmt.Errorf("--properties-placement must be one of %q or %q",
+			propertiesPlacementFile, propertiesPlacementAnnotation)
+	}
+	for _, p := range c.properties {
+		prop, err := registry.ParseProperty(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --property: %v", err)
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
 // runMetadata generates a bundle.Dockerfile and bundle metadata.
 func (c bundleCmd) runMetadata(cfg *config.Config) error {
+	if c.installModeVariants {
+		return c.runVariantMetadata(cfg)
+	}
 
 	directory := c.inputDir
+	if c.isRenderedInput() {
+		// Pre-rendered manifests are packaged into the output directory.
+		outputDir := c.outputDir
+		if outputDir == "" {
+			outputDir = defaultRootDir
+		}
+		return c.generateMetadata(cfg, filepath.Join(outputDir, bundle.ManifestsDir), "")
+	}
 	if directory == "" {
 		// There may be no existing bundle at the default path, so assume manifests
 		// only exist in the output directory.
@@ -288,22 +511,51 @@ func (c bundleCmd) runMetadata(cfg *config.Config) error {
 func (c bundleCmd) generateMetadata(cfg *config.Config, manifestsDir, outputDir string) error {
 
 	metadataExists := isMetatdataExist(outputDir, manifestsDir)
-	err := bundle.GenerateFunc(manifestsDir, outputDir, c.operatorName, c.channels, c.defaultChannel, c.overwrite)
+	err := bundle.GenerateFunc(manifestsDir, outputDir, c.packageName(), c.channels, c.defaultChannel, c.overwrite)
 	if err != nil {
 		return fmt.Errorf("error generating bundle metadata: %v", err)
 	}
 
-	// Add SDK annotations/labels if metadata did not exist before or when overwrite is true.
-	if c.overwrite || !metadataExists {
-		bundleRoot := outputDir
-		if bundleRoot == "" {
-			bundleRoot = filepath.Dir(manifestsDir)
+	bundleRoot := outputDir
+	if bundleRoot == "" {
+		bundleRoot = filepath.Dir(manifestsDir)
+	}
+
+	// Exclude ignored bundle files from the bundle image's build context, the project directory.
+	if isExist(bundle.DockerFile) {
+		if err := registry.WriteBundleDockerignore(".", bundleRoot); err != nil {
+			return fmt.Errorf("error writing bundle excludes to %s: %v", registry.DockerignoreFile, err)
 		}
+	}
 
+	// Add SDK annotations/labels if metadata did not exist before or when overwrite is true.
+	if c.overwrite || !metadataExists {
 		if err = updateMetadata(cfg, bundleRoot); err != nil {
 			return err
 		}
 	}
+
+	// Write dependencies if any were passed. Otherwise an existing dependencies file is left as-is.
+	deps, err := c.parseDependencies()
+	if err != nil {
+		return err
+	}
+	if len(deps) != 0 {
+		if err := registry.WriteDependencies(bundleRoot, deps); err != nil {
+			return fmt.Errorf("error writing bundle dependencies: %v", err)
+		}
+	}
+
+	// Write properties to the properties file if any were passed and they aren't placed in the CSV.
+	props, err := c.parseProperties()
+	if err != nil {
+		return err
+	}
+	if len(props) != 0 && c.propertiesPlacement == propertiesPlacementFile {
+		if err := registry.WriteProperties(bundleRoot, props); err != nil {
+			return fmt.Errorf("error writing bundle properties: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,62 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generating bundle properties", func() {
+	var c bundleCmd
+
+	BeforeEach(func() {
+		c = bundleCmd{
+			defaultChannel:      "alpha",
+			propertiesPlacement: propertiesPlacementFile,
+			properties:          []string{`olm.maxOpenShiftVersion:"4.8"`},
+		}
+	})
+
+	It("parses properties for either placement", func() {
+		props, err := c.parseProperties()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(props).To(HaveLen(1))
+
+		c.propertiesPlacement = propertiesPlacementAnnotation
+		_, err = c.parseProperties()
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("returns an error for an invalid placement or property", func() {
+		c.propertiesPlacement = "csv"
+		_, err := c.parseProperties()
+		Expect(err).To(MatchError(ContainSubstring("--properties-placement must be one of")))
+
+		c.propertiesPlacement = propertiesPlacementFile
+		c.properties = []string{"olm.maxOpenShiftVersion:4.8"}
+		_, err = c.parseProperties()
+		Expect(err).To(MatchError(ContainSubstring("invalid --property")))
+	})
+	It("requires generating manifests to place properties in the CSV", func() {
+		c.metadata = true
+		Expect(c.validateMetadata(nil)).To(Succeed())
+
+		c.propertiesPlacement = propertiesPlacementAnnotation
+		Expect(c.validateMetadata(nil)).To(MatchError(ContainSubstring("requires generating bundle manifests")))
+
+		c.manifests = true
+		Expect(c.validateMetadata(nil)).To(Succeed())
+	})
+})
@@ -16,12 +16,17 @@ package bundle
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	genutil "github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/internal"
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+	"github.com/operator-framework/operator-sdk/internal/registry"
 	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
 )
 
@@ -37,15 +42,35 @@ type bundleCmd struct {
 	inputDir     string
 	outputDir    string
 	kustomizeDir string
+	stdinTimeout time.Duration
 	deployDir    string
 	crdsDir      string
 	stdout       bool
 	quiet        bool
+	verboseDiff  bool
+
+	skipProjectNameCheck bool
+
+	// Manifests options.
+	csvNameTemplate    string
+	assessCapabilities bool
+	setCapabilities    bool
+	enableCleanup      bool
+	includeObjects     []string
+
+	// Install mode variant options.
+	installModeVariants bool
+	variant             gencsv.InstallModeVariant
 
 	// Metadata options.
 	channels       string
 	defaultChannel string
 	overwrite      bool
+	dependencies   []string
+
+	// Properties options.
+	properties          []string
+	propertiesPlacement string
 }
 
 // NewCmd returns the 'bundle' command configured for the new project layout.
@@ -73,6 +98,9 @@ func NewCmd() *cobra.Command {
 				return fmt.Errorf("error reading configuration: %v", err)
 			}
 			c.setDefaults(cfg)
+			if !c.skipProjectNameCheck {
+				c.checkProjectName()
+			}
 
 			// Validate command args before running so a preceding mode doesn't run
 			// before a following validation fails.
@@ -87,6 +115,14 @@ func NewCmd() *cobra.Command {
 				}
 			}
 
+			// Read the bundle as it was before generation to diff it against afterwards.
+			var before bundleFiles
+			if c.verboseDiff {
+				if before, err = readBundleFiles(c.diffPaths()...); err != nil {
+					return fmt.Errorf("error reading existing bundle: %v", err)
+				}
+			}
+
 			// Run command logic.
 			if c.manifests {
 				if err = c.runManifests(cfg); err != nil {
@@ -99,14 +135,30 @@ func NewCmd() *cobra.Command {
 				}
 			}
 
+			if c.verboseDiff {
+				after, err := readBundleFiles(c.diffPaths()...)
+				if err != nil {
+					log.Fatalf("Error reading generated bundle: %v", err)
+				}
+				if err := writeBundleDiff(os.Stdout, before, after); err != nil {
+					log.Fatalf("Error printing bundle diff: %v", err)
+				}
+			}
+
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&c.kustomizeDir, "kustomize-dir", filepath.Join("config", "manifests"),
 		"Directory containing kustomize bases and a kustomization.yaml for operator-framework manifests")
+	cmd.Flags().DurationVar(&c.stdinTimeout, "kustomize-build-timeout", genutil.DefaultStdinTimeout,
+		"Time to wait for manifests piped to stdin, ex. by 'kustomize build', before failing. "+
+			"Set to 0 to wait indefinitely")
 	cmd.Flags().BoolVar(&c.stdout, "stdout", false, "Write bundle manifest to stdout")
 
+	cmd.Flags().BoolVar(&c.skipProjectNameCheck, "skip-project-name-check", false, "Do not warn if the project "+
+		"directory's name does not match the PROJECT file's 'projectName'")
+
 	c.addFlagsTo(cmd.Flags())
 
 	return cmd
@@ -120,13 +172,41 @@ func (c *bundleCmd) addFlagsTo(fs *pflag.FlagSet) {
 	fs.StringVarP(&c.version, "version", "v", "", "Semantic version of the operator in the generated bundle. "+
 		"Only set if creating a new bundle or upgrading your operator")
 	fs.StringVar(&c.inputDir, "input-dir", "", "Directory to read an existing bundle from. "+
-		"This directory is the parent of your bundle 'manifests' directory, and different from --deploy-dir")
+		"This directory is the parent of your bundle 'manifests' directory, and different from --deploy-dir. "+
+		"If this directory has no 'manifests' directory, it is read as pre-rendered manifests to package as-is")
 	fs.StringVar(&c.outputDir, "output-dir", "", "Directory to write the bundle to")
+	fs.StringVar(&c.csvNameTemplate, "csv-name-template", "{{.Package}}.v{{.Version}}",
+		"Template of the ClusterServiceVersion's name, with {{.Package}} and {{.Version}} "+
+			"replaced by the operator's name and version. \"replaces\" and \"skips\" are set using the same template")
+	fs.BoolVar(&c.assessCapabilities, "assess-capabilities", false, "Log a capability level suggested by "+
+		"inspecting manifests for signals of each level, and which signals were found")
+	fs.BoolVar(&c.setCapabilities, "set-capabilities", false, "Set the ClusterServiceVersion's 'capabilities' "+
+		"annotation to the suggested capability level. Implies --assess-capabilities")
+	fs.BoolVar(&c.enableCleanup, "enable-cleanup", false, "Set the ClusterServiceVersion's 'spec.cleanup.enabled' "+
+		"to true, so OLM deletes the operator's custom resources on uninstall. Requires OLM v0.17.0+. "+
+		"Cleanup stays enabled when regenerating a bundle that enables it")
+	fs.StringArrayVar(&c.includeObjects, "include-object", nil, "A ConfigMap or Secret, as 'ConfigMap/<name>' "+
+		"or 'Secret/<name>', to include in the bundle's manifests from the input manifests. "+
+		"May be set more than once")
+	fs.BoolVar(&c.installModeVariants, "install-mode-variants", false, "Generate a namespaced and a cluster "+
+		"bundle variant, each its own package, in the 'namespaced' and 'cluster' subdirectories of --output-dir, "+
+		"with install modes and RBAC scoped to namespaced or cluster-wide installs")
 	fs.StringVar(&c.deployDir, "deploy-dir", "", "Root directory for operator manifests such as "+
 		"Deployments and RBAC, ex. 'deploy'. This directory is different from that passed to --input-dir")
 	fs.StringVar(&c.crdsDir, "crds-dir", "", "Root directory for CustomResoureDefinition manifests")
 	fs.StringVar(&c.channels, "channels", "alpha", "A comma-separated list of channels the bundle belongs to")
 	fs.StringVar(&c.defaultChannel, "default-channel", "", "The default channel for the bundle")
 	fs.BoolVar(&c.overwrite, "overwrite", true, "Overwrite the bundle's metadata and Dockerfile if they exist")
+	fs.StringArrayVar(&c.dependencies, "dependency", nil, "A dependency written to the bundle's "+
+		"metadata/dependencies.yaml, either 'olm.package:<package name>:<version range>' or "+
+		"'olm.gvk:<group>/<version>/<kind>'. May be set more than once")
+	fs.StringArrayVar(&c.properties, "property", nil, "A bundle property, as '<type>:<JSON value>', "+
+		"ex. 'olm.maxOpenShiftVersion:\"4.8\"'. May be set more than once")
+	fs.StringVar(&c.propertiesPlacement, "properties-placement", propertiesPlacementFile,
+		fmt.Sprintf("Where to write --property values: %q writes them to the bundle's metadata/%s, and %q "+
+			"adds them to the ClusterServiceVersion's %s annotation", propertiesPlacementFile, registry.PropertiesFile,
+			propertiesPlacementAnnotation, registry.PropertiesAnnotation))
 	fs.BoolVarP(&c.quiet, "quiet", "q", false, "Run in quiet mode")
+	fs.BoolVar(&c.verboseDiff, "verbose-diff", false, "After generating the bundle, print a unified diff "+
+		"of each bundle file the command added, removed, or modified")
 }
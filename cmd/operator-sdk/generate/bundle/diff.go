@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+	"github.com/operator-framework/operator-sdk/internal/util/diffutil"
+)
+
+// bundleFiles maps the paths of bundle files to their contents.
+type bundleFiles map[string]string
+
+// diffPaths returns the paths of files and directories that 'generate bundle' may write to.
+func (c bundleCmd) diffPaths() []string {
+	outputDir := c.outputDir
+	if outputDir == "" {
+		outputDir = defaultRootDir
+	}
+	paths := []string{outputDir, bundle.DockerFile}
+	if c.installModeVariants {
+		for _, v := range gencsv.InstallModeVariants {
+			paths = append(paths, variantDockerfileName(v))
+		}
+	}
+	if c.inputDir != "" && filepath.Clean(c.inputDir) != filepath.Clean(outputDir) {
+		paths = append(paths, c.inputDir)
+	}
+	return paths
+}
+
+// readBundleFiles reads the regular files in or at each path. Paths that do not exist are skipped.
+func readBundleFiles(paths ...string) (bundleFiles, error) {
+	files := bundleFiles{}
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return nil
+				}
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(path)] = string(b)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// writeBundleDiff writes a unified diff of each file added, removed, or modified between before and after to w.
+func writeBundleDiff(w io.Writer, before, after bundleFiles) error {
+	paths := make([]string, 0, len(after))
+	for path := range after {
+		paths = append(paths, path)
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		from, hadFile := before[path]
+		to, hasFile := after[path]
+		fromName, toName := "a/"+path, "b/"+path
+		if !hadFile {
+			fromName = "/dev/null"
+		}
+		if !hasFile {
+			toName = "/dev/null"
+		}
+		if diff := diffutil.UnifiedDiff(fromName, toName, from, to); diff != "" {
+			if _, err := fmt.Fprint(w, diff); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Printing a verbose bundle diff", func() {
+	It("reads bundle files, skipping missing paths", func() {
+		dir, err := ioutil.TempDir("", "bundle-diff-")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		manifest := filepath.Join(dir, "manifests", "memcached-operator.clusterserviceversion.yaml")
+		Expect(os.MkdirAll(filepath.Dir(manifest), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(manifest, []byte("kind: ClusterServiceVersion\n"), 0644)).To(Succeed())
+
+		files, err := readBundleFiles(dir, filepath.Join(dir, "bundle.Dockerfile"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(Equal(bundleFiles{filepath.ToSlash(manifest): "kind: ClusterServiceVersion\n"}))
+	})
+	It("writes a diff of each added, removed, and modified file", func() {
+		before := bundleFiles{
+			"bundle/manifests/csv.yaml":        "spec:\n  version: 0.0.1\n",
+			"bundle/manifests/old-crd.yaml":    "kind: CustomResourceDefinition\n",
+			"bundle/metadata/annotations.yaml": "annotations: {}\n",
+		}
+		after := bundleFiles{
+			"bundle/manifests/csv.yaml":        "spec:\n  version: 0.0.2\n",
+			"bundle/manifests/new-crd.yaml":    "kind: CustomResourceDefinition\n",
+			"bundle/metadata/annotations.yaml": "annotations: {}\n",
+		}
+		var buf bytes.Buffer
+		Expect(writeBundleDiff(&buf, before, after)).To(Succeed())
+		Expect(buf.String()).To(Equal(`--- a/bundle/manifests/csv.yaml
++++ b/bundle/manifests/csv.yaml
+@@ -1,2 +1,2 @@
+ spec:
+-  version: 0.0.1
++  version: 0.0.2
+--- /dev/null
++++ b/bundle/manifests/new-crd.yaml
+@@ -0,0 +1,1 @@
++kind: CustomResourceDefinition
+--- a/bundle/manifests/old-crd.yaml
++++ /dev/null
+@@ -1,1 +0,0 @@
+-kind: CustomResourceDefinition
+`))
+	})
+	It("cannot be set when writing manifests to stdout", func() {
+		c := bundleCmd{
+			kustomizeDir:        "config/manifests",
+			deployDir:           "config",
+			crdsDir:             "config/crd",
+			propertiesPlacement: propertiesPlacementFile,
+			stdout:              true,
+			verboseDiff:         true,
+		}
+		Expect(c.validateManifests(nil)).To(MatchError("--verbose-diff cannot be set if writing to stdout"))
+	})
+})
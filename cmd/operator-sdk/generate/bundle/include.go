@@ -0,0 +1,88 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// objectRef references a ConfigMap or Secret to include in the bundle.
+type objectRef struct {
+	kind, name string
+}
+
+func (r objectRef) String() string {
+	return r.kind + "/" + r.name
+}
+
+// parseIncludeObjects parses each --include-object value, of the form '<kind>/<name>'.
+func (c bundleCmd) parseIncludeObjects() (refs []objectRef, err error) {
+	for _, o := range c.includeObjects {
+		split := strings.SplitN(o, "/", 2)
+		if len(split) != 2 || split[1] == "" {
+			return nil, fmt.Errorf("invalid --include-object %q: must be of the form '<kind>/<name>'", o)
+		}
+		ref := objectRef{kind: split[0], name: split[1]}
+		if ref.kind != "ConfigMap" && ref.kind != "Secret" {
+			return nil, fmt.Errorf("invalid --include-object %q: kind must be ConfigMap or Secret", o)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// getIncludedObjects returns the object in col referenced by each of refs, or an error
+// if any does not exist. A warning is logged for each Secret with literal data,
+// since bundle manifests are stored unencrypted in bundle images.
+func getIncludedObjects(col *collector.Manifests, refs []objectRef) (objs []*unstructured.Unstructured, err error) {
+	for _, ref := range refs {
+		obj, ok := findObject(col.Others, ref)
+		if !ok {
+			return nil, fmt.Errorf("--include-object %s not found in manifests: "+
+				"names must match those in rendered manifests, ex. with a kustomize namePrefix", ref)
+		}
+		if ref.kind == "Secret" && hasLiteralData(obj) {
+			log.Warnf("Secret %q included in the bundle has literal data, which will be readable by anyone "+
+				"with access to the bundle image", ref.name)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// findObject returns the core object in objs matching ref.
+func findObject(objs []unstructured.Unstructured, ref objectRef) (*unstructured.Unstructured, bool) {
+	for i, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group == corev1.GroupName && gvk.Kind == ref.kind && obj.GetName() == ref.name {
+			return &objs[i], true
+		}
+	}
+	return nil, false
+}
+
+// hasLiteralData returns true if secret has a non-empty 'data' or 'stringData' field.
+func hasLiteralData(secret *unstructured.Unstructured) bool {
+	data, _, _ := unstructured.NestedMap(secret.Object, "data")
+	stringData, _, _ := unstructured.NestedMap(secret.Object, "stringData")
+	return len(data) != 0 || len(stringData) != 0
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+
+	genutil "github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+const includeManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: memcached-operator-manager-config
+data:
+  controller_manager_config.yaml: |
+    leaderElection:
+      leaderElect: true
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: memcached-operator-credentials
+stringData:
+  password: hunter2
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: memcached-operator-empty
+type: Opaque
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: memcached-operator-manager-config
+`
+
+var _ = Describe("Including bundle objects", func() {
+	var (
+		c   bundleCmd
+		col *collector.Manifests
+		buf *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		c = bundleCmd{propertiesPlacement: propertiesPlacementFile}
+		col = &collector.Manifests{}
+		Expect(col.UpdateFromReader(strings.NewReader(includeManifests))).To(Succeed())
+		buf = &bytes.Buffer{}
+		log.SetOutput(buf)
+	})
+	AfterEach(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	It("parses ConfigMap and Secret references", func() {
+		c.includeObjects = []string{"ConfigMap/memcached-operator-manager-config", "Secret/memcached-operator-credentials"}
+		refs, err := c.parseIncludeObjects()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(refs).To(Equal([]objectRef{
+			{kind: "ConfigMap", name: "memcached-operator-manager-config"},
+			{kind: "Secret", name: "memcached-operator-credentials"},
+		}))
+	})
+	It("returns an error for a malformed reference or unsupported kind", func() {
+		c.includeObjects = []string{"memcached-operator-manager-config"}
+		_, err := c.parseIncludeObjects()
+		Expect(err).To(MatchError(ContainSubstring("must be of the form '<kind>/<name>'")))
+
+		c.includeObjects = []string{"Service/memcached-operator-manager-config"}
+		_, err = c.parseIncludeObjects()
+		Expect(err).To(MatchError(ContainSubstring("kind must be ConfigMap or Secret")))
+	})
+	It("includes referenced objects by kind and name", func() {
+		objs, err := getIncludedObjects(col, []objectRef{
+			{kind: "ConfigMap", name: "memcached-operator-manager-config"},
+			{kind: "Secret", name: "memcached-operator-empty"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(2))
+		Expect(objs[0].GetKind()).To(Equal("ConfigMap"))
+		Expect(objs[0].GetName()).To(Equal("memcached-operator-manager-config"))
+		Expect(objs[1].GetKind()).To(Equal("Secret"))
+		Expect(buf.String()).To(BeEmpty())
+	})
+	It("writes included objects to bundle manifests", func() {
+		objs, err := getIncludedObjects(col, []objectRef{{kind: "ConfigMap", name: "memcached-operator-manager-config"}})
+		Expect(err).NotTo(HaveOccurred())
+		dir, err := ioutil.TempDir("", "include-object-")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		Expect(genutil.WriteObjectsToFiles(dir, objs[0])).To(Succeed())
+		b, err := ioutil.ReadFile(filepath.Join(dir, "memcached-operator-manager-config_v1_configmap.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(HavePrefix("apiVersion: v1\n"))
+		Expect(string(b)).To(ContainSubstring("leaderElect: true"))
+	})
+	It("warns about Secrets with literal data", func() {
+		objs, err := getIncludedObjects(col, []objectRef{{kind: "Secret", name: "memcached-operator-credentials"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(buf.String()).To(ContainSubstring(`Secret \"memcached-operator-credentials\" included in the bundle has literal data`))
+	})
+	It("returns an error for a reference that does not exist", func() {
+		_, err := getIncludedObjects(col, []objectRef{{kind: "Secret", name: "memcached-operator-manager-config"}})
+		Expect(err).To(MatchError(ContainSubstring("--include-object Secret/memcached-operator-manager-config not found")))
+	})
+	It("cannot be set when packaging pre-rendered manifests", func() {
+		c.inputDir = "rendered"
+		c.includeObjects = []string{"ConfigMap/memcached-operator-manager-config"}
+		Expect(c.validateRenderedManifests()).To(MatchError(ContainSubstring("--include-object cannot be set")))
+	})
+})
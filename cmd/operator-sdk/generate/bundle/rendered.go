@@ -0,0 +1,217 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	genutil "github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// renderedManifest is a single manifest read from a directory of pre-rendered manifests.
+type renderedManifest struct {
+	obj unstructured.Unstructured
+	raw []byte
+}
+
+// isRenderedInput returns true if --input-dir is a directory of pre-rendered manifests
+// to package into a bundle, rather than an existing bundle containing a manifests directory.
+// With --install-mode-variants, --input-dir is always the parent of existing variant bundles.
+func (c bundleCmd) isRenderedInput() bool {
+	return !c.installModeVariants && c.inputDir != "" && isExist(c.inputDir) &&
+		genutil.IsNotExist(filepath.Join(c.inputDir, bundle.ManifestsDir))
+}
+
+// validateRenderedManifests validates c for packaging pre-rendered manifests.
+func (c bundleCmd) validateRenderedManifests() error {
+	if c.version != "" {
+		return errors.New("--version cannot be set when packaging pre-rendered manifests from --input-dir; " +
+			"set the version in the rendered ClusterServiceVersion")
+	}
+	if c.stdout && c.outputDir != "" {
+		return errors.New("--output-dir cannot be set if writing to stdout")
+	}
+	if c.enableCleanup {
+		return errors.New("--enable-cleanup cannot be set when packaging pre-rendered manifests from --input-dir; " +
+			"set spec.cleanup.enabled in the rendered ClusterServiceVersion")
+	}
+	if len(c.includeObjects) != 0 {
+		return errors.New("--include-object cannot be set when packaging pre-rendered manifests from --input-dir; " +
+			"pre-rendered manifests are packaged as-is")
+	}
+	if len(c.properties) != 0 && c.propertiesPlacement == propertiesPlacementAnnotation {
+		return fmt.Errorf("--properties-placement=%s cannot be set when packaging pre-rendered manifests from --input-dir",
+			propertiesPlacementAnnotation)
+	}
+	return nil
+}
+
+// runRenderedManifests packages the pre-rendered manifests in c.inputDir, as-is,
+// into the bundle's manifests directory.
+func (c bundleCmd) runRenderedManifests() error {
+	if !c.quiet && !c.stdout {
+		fmt.Println("Packaging bundle manifests from", c.inputDir)
+	}
+
+	manifests, err := readRenderedManifests(c.inputDir)
+	if err != nil {
+		return err
+	}
+
+	if c.stdout {
+		return writeRenderedManifests(genutil.NewMultiManifestWriter(os.Stdout), manifests)
+	}
+
+	outputDir := c.outputDir
+	if outputDir == "" {
+		outputDir = defaultRootDir
+	}
+	if err := writeRenderedManifestsToFiles(filepath.Join(outputDir, bundle.ManifestsDir), manifests); err != nil {
+		return err
+	}
+
+	if !c.quiet {
+		fmt.Println("Bundle manifests packaged successfully in", outputDir)
+	}
+	return nil
+}
+
+// readRenderedManifests reads all manifests in dir, and returns an error if exactly one
+// ClusterServiceVersion is not found.
+func readRenderedManifests(dir string) (manifests []renderedManifest, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		scanner := k8sutil.NewYAMLScanner(bytes.NewBuffer(b))
+		for scanner.Scan() {
+			raw := bytes.TrimSpace(scanner.Bytes())
+			if typeMeta, err := k8sutil.GetTypeMetaFromBytes(raw); err != nil || typeMeta.Kind == "" {
+				log.Debugf("No TypeMeta in %s, skipping manifest", path)
+				continue
+			}
+			m := renderedManifest{raw: raw}
+			if err := yaml.Unmarshal(raw, &m.obj.Object); err != nil {
+				return fmt.Errorf("error reading manifest in %s: %v", path, err)
+			}
+			manifests = append(manifests, m)
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading pre-rendered manifests: %v", err)
+	}
+
+	csvCount := 0
+	for _, m := range manifests {
+		if isCSV(m.obj) {
+			csvCount++
+		}
+	}
+	switch csvCount {
+	case 0:
+		return nil, fmt.Errorf("no ClusterServiceVersion found in pre-rendered manifests in %s", dir)
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d ClusterServiceVersions found in pre-rendered manifests in %s, expected 1", csvCount, dir)
+	}
+	return manifests, nil
+}
+
+// writeRenderedManifests writes each manifest to w.
+func writeRenderedManifests(w io.Writer, manifests []renderedManifest) error {
+	for _, m := range manifests {
+		if _, err := w.Write(append(m.raw, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRenderedManifestsToFiles creates dir then writes each manifest to its own file in dir.
+func writeRenderedManifestsToFiles(dir string, manifests []renderedManifest) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	seenFiles := make(map[string]struct{})
+	for _, m := range manifests {
+		fileName := makeRenderedManifestFileName(m.obj)
+		if _, hasFile := seenFiles[fileName]; hasFile {
+			return fmt.Errorf("duplicate file cannot be written: %s", fileName)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, fileName), append(m.raw, '\n'), 0666); err != nil {
+			return err
+		}
+		seenFiles[fileName] = struct{}{}
+	}
+	return nil
+}
+
+// makeRenderedManifestFileName returns a bundle file name for obj, using the same names
+// as 'generate bundle' for ClusterServiceVersions and CustomResourceDefinitions.
+func makeRenderedManifestFileName(obj unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	switch {
+	case isCSV(obj):
+		return fmt.Sprintf("%s.clusterserviceversion.yaml", strings.ToLower(csvPackageName(obj.GetName())))
+	case gvk.Group == apiextv1.GroupName && gvk.Kind == "CustomResourceDefinition":
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		plural, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "plural")
+		if group != "" && plural != "" {
+			return fmt.Sprintf("%s_%s.yaml", group, plural)
+		}
+	}
+	parts := []string{obj.GetName()}
+	if gvk.Group != "" {
+		parts = append(parts, gvk.Group)
+	}
+	parts = append(parts, gvk.Version, gvk.Kind)
+	return strings.ToLower(strings.Join(parts, "_")) + ".yaml"
+}
+
+// csvPackageName returns the package part of a CSV name of the form "<package>.v<version>".
+func csvPackageName(name string) string {
+	if i := strings.LastIndex(name, ".v"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// isCSV returns true if obj is a ClusterServiceVersion.
+func isCSV(obj unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == operatorsv1alpha1.GroupName && gvk.Kind == operatorsv1alpha1.ClusterServiceVersionKind
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	renderedCSV = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+spec:
+  version: 0.0.1`
+	renderedCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds`
+	renderedService = `apiVersion: v1
+kind: Service
+metadata:
+  name: memcached-operator-metrics`
+	renderedServiceMonitor = `apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: memcached-operator-metrics`
+)
+
+var _ = Describe("Packaging pre-rendered manifests", func() {
+	var inputDir, outputDir string
+
+	BeforeEach(func() {
+		var err error
+		inputDir, err = ioutil.TempDir("", "rendered")
+		Expect(err).NotTo(HaveOccurred())
+		outputDir, err = ioutil.TempDir("", "bundle")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(inputDir)).To(Succeed())
+		Expect(os.RemoveAll(outputDir)).To(Succeed())
+	})
+
+	writeInput := func(name, contents string) {
+		Expect(ioutil.WriteFile(filepath.Join(inputDir, name), []byte(contents), 0644)).To(Succeed())
+	}
+
+	It("reads an input directory without a manifests directory as pre-rendered manifests", func() {
+		c := bundleCmd{inputDir: inputDir}
+		Expect(c.isRenderedInput()).To(BeTrue())
+		Expect(os.Mkdir(filepath.Join(inputDir, "manifests"), 0755)).To(Succeed())
+		Expect(c.isRenderedInput()).To(BeFalse())
+		Expect(bundleCmd{}.isRenderedInput()).To(BeFalse())
+	})
+
+	It("packages each manifest as-is into its own file", func() {
+		writeInput("all.yaml", renderedCSV+"\n---\n"+renderedCRD)
+		writeInput("metrics.yaml", renderedService+"\n---\n"+renderedServiceMonitor)
+		writeInput("README.md", "# Rendered manifests")
+
+		c := bundleCmd{inputDir: inputDir, outputDir: outputDir, quiet: true}
+		Expect(c.runRenderedManifests()).To(Succeed())
+
+		manifestsDir := filepath.Join(outputDir, "manifests")
+		for fileName, contents := range map[string]string{
+			"memcached-operator.clusterserviceversion.yaml":                           renderedCSV,
+			"cache.example.com_memcacheds.yaml":                                       renderedCRD,
+			"memcached-operator-metrics_v1_service.yaml":                              renderedService,
+			"memcached-operator-metrics_monitoring.coreos.com_v1_servicemonitor.yaml": renderedServiceMonitor,
+		} {
+			b, err := ioutil.ReadFile(filepath.Join(manifestsDir, fileName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(contents + "\n"))
+		}
+		infos, err := ioutil.ReadDir(manifestsDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infos).To(HaveLen(4))
+	})
+
+	It("returns an error if no ClusterServiceVersion is found", func() {
+		writeInput("crd.yaml", renderedCRD)
+		_, err := readRenderedManifests(inputDir)
+		Expect(err).To(MatchError(ContainSubstring("no ClusterServiceVersion found")))
+	})
+
+	It("returns an error if more than one ClusterServiceVersion is found", func() {
+		writeInput("csv.yaml", renderedCSV)
+		writeInput("csv-copy.yaml", renderedCSV)
+		_, err := readRenderedManifests(inputDir)
+		Expect(err).To(MatchError(ContainSubstring("2 ClusterServiceVersions found")))
+	})
+
+	It("does not allow setting a version", func() {
+		c := bundleCmd{inputDir: inputDir, version: "0.0.2"}
+		Expect(c.validateManifests(nil)).To(MatchError(ContainSubstring("--version cannot be set")))
+	})
+})
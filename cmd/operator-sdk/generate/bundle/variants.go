@@ -0,0 +1,168 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+	"github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// packageName returns the name of the bundle's package, the operator's name
+// suffixed by the install mode variant being generated, if any.
+func (c bundleCmd) packageName() string {
+	if c.variant != "" {
+		return c.variant.PackageName(c.operatorName)
+	}
+	return c.operatorName
+}
+
+// forVariant returns a copy of c that generates the bundle of variant v in the
+// v subdirectory of the output directory, from an existing bundle in the v
+// subdirectory of the input directory.
+func (c bundleCmd) forVariant(v gencsv.InstallModeVariant) bundleCmd {
+	outputDir := c.outputDir
+	if outputDir == "" {
+		outputDir = defaultRootDir
+	}
+	inputDir := c.inputDir
+	if inputDir == "" {
+		inputDir = outputDir
+	}
+	c.installModeVariants = false
+	c.variant = v
+	c.inputDir = filepath.Join(inputDir, string(v))
+	c.outputDir = filepath.Join(outputDir, string(v))
+	return c
+}
+
+// variantDockerfileName returns the name of v's bundle Dockerfile, ex. bundle.namespaced.Dockerfile.
+func variantDockerfileName(v gencsv.InstallModeVariant) string {
+	return strings.TrimSuffix(bundle.DockerFile, ".Dockerfile") + "." + string(v) + ".Dockerfile"
+}
+
+// runVariantManifests generates and validates the bundle manifests of each install mode variant.
+func (c bundleCmd) runVariantManifests(cfg *config.Config) error {
+	for _, v := range gencsv.InstallModeVariants {
+		vc := c.forVariant(v)
+		if err := vc.runManifests(cfg); err != nil {
+			return fmt.Errorf("error generating %s bundle variant: %v", v, err)
+		}
+		if err := validateVariantManifests(v, filepath.Join(vc.outputDir, bundle.ManifestsDir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateVariantManifests validates the bundle manifests of variant v in manifestsDir,
+// logging warnings and returning an error containing all validation errors.
+func validateVariantManifests(v gencsv.InstallModeVariant, manifestsDir string) error {
+	mediaType, err := bundle.GetMediaType(manifestsDir)
+	if err != nil {
+		return fmt.Errorf("error reading %s bundle variant: %v", v, err)
+	}
+	b, err := apimanifests.GetBundleFromDir(manifestsDir)
+	if err != nil {
+		return fmt.Errorf("error reading %s bundle variant: %v", v, err)
+	}
+
+	var errs []string
+	for _, result := range registry.ValidateBundleContent(log.WithField("variant", v), b, mediaType) {
+		for _, w := range result.Warnings {
+			log.Warnf("%s bundle variant: %s", v, w)
+		}
+		for _, e := range result.Errors {
+			errs = append(errs, e.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid %s bundle variant: %s", v, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// runVariantMetadata generates the bundle metadata and Dockerfile of each install mode variant.
+// The operator-registry generator always writes bundle.Dockerfile, so each variant's Dockerfile
+// is swapped in for generation and moved to variantDockerfileName after, and the project's
+// bundle.Dockerfile, if any, is restored.
+func (c bundleCmd) runVariantMetadata(cfg *config.Config) (err error) {
+	info, err := os.Stat(bundle.DockerFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var original []byte
+	if info != nil {
+		if original, err = ioutil.ReadFile(bundle.DockerFile); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		var restoreErr error
+		if info != nil {
+			restoreErr = ioutil.WriteFile(bundle.DockerFile, original, info.Mode())
+		} else if rmErr := os.Remove(bundle.DockerFile); !os.IsNotExist(rmErr) {
+			restoreErr = rmErr
+		}
+		if err == nil {
+			err = restoreErr
+		}
+	}()
+
+	for _, v := range gencsv.InstallModeVariants {
+		vc := c.forVariant(v)
+		dockerfile := variantDockerfileName(v)
+		if err := swapDockerfile(dockerfile); err != nil {
+			return err
+		}
+
+		// Manifests generated by this command are written to the output directory.
+		manifestsDir := filepath.Join(vc.inputDir, bundle.ManifestsDir)
+		outputDir := vc.outputDir
+		if c.manifests || filepath.Clean(vc.inputDir) == filepath.Clean(outputDir) {
+			manifestsDir, outputDir = filepath.Join(vc.outputDir, bundle.ManifestsDir), ""
+		}
+		if err := vc.generateMetadata(cfg, manifestsDir, outputDir); err != nil {
+			return fmt.Errorf("error generating %s bundle variant: %v", v, err)
+		}
+
+		if err := os.Rename(bundle.DockerFile, dockerfile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// swapDockerfile moves dockerfile to bundle.Dockerfile, or removes bundle.Dockerfile
+// if dockerfile does not exist, so it is generated from scratch.
+func swapDockerfile(dockerfile string) error {
+	err := os.Rename(dockerfile, bundle.DockerFile)
+	if os.IsNotExist(err) {
+		if err = os.Remove(bundle.DockerFile); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	return err
+}
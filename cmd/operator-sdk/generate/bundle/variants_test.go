@@ -0,0 +1,93 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+)
+
+var _ = Describe("Generating install mode variants", func() {
+	It("generates each variant in a subdirectory of the output directory", func() {
+		c := bundleCmd{operatorName: "memcached-operator", installModeVariants: true}
+		vc := c.forVariant(gencsv.InstallModeVariantNamespaced)
+		Expect(vc.installModeVariants).To(BeFalse())
+		Expect(vc.inputDir).To(Equal(filepath.Join("bundle", "namespaced")))
+		Expect(vc.outputDir).To(Equal(filepath.Join("bundle", "namespaced")))
+		Expect(vc.packageName()).To(Equal("memcached-operator-namespaced"))
+		Expect(c.packageName()).To(Equal("memcached-operator"))
+
+		c.inputDir, c.outputDir = "old", "new"
+		vc = c.forVariant(gencsv.InstallModeVariantCluster)
+		Expect(vc.inputDir).To(Equal(filepath.Join("old", "cluster")))
+		Expect(vc.outputDir).To(Equal(filepath.Join("new", "cluster")))
+	})
+	It("names each variant's Dockerfile distinctly", func() {
+		Expect(variantDockerfileName(gencsv.InstallModeVariantNamespaced)).To(Equal("bundle.namespaced.Dockerfile"))
+		Expect(variantDockerfileName(gencsv.InstallModeVariantCluster)).To(Equal("bundle.cluster.Dockerfile"))
+	})
+	It("cannot write variants to stdout", func() {
+		c := bundleCmd{kustomizeDir: "config/manifests", propertiesPlacement: propertiesPlacementFile,
+			deployDir: "deploy", crdsDir: "crds", stdout: true, installModeVariants: true}
+		Expect(c.validateManifests(nil)).To(MatchError(ContainSubstring("--install-mode-variants cannot be set")))
+	})
+
+	Describe("swapDockerfile", func() {
+		var wd, dir string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			dir, err = ioutil.TempDir("", "bundle-variants-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(dir)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("moves an existing variant Dockerfile to bundle.Dockerfile", func() {
+			Expect(ioutil.WriteFile(bundle.DockerFile, []byte("project"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile("bundle.cluster.Dockerfile", []byte("cluster"), 0644)).To(Succeed())
+			Expect(swapDockerfile("bundle.cluster.Dockerfile")).To(Succeed())
+			Expect(ioutil.ReadFile(bundle.DockerFile)).To(BeEquivalentTo("cluster"))
+			_, err := os.Stat("bundle.cluster.Dockerfile")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+		It("removes bundle.Dockerfile for a new variant", func() {
+			Expect(ioutil.WriteFile(bundle.DockerFile, []byte("project"), 0644)).To(Succeed())
+			Expect(swapDockerfile("bundle.cluster.Dockerfile")).To(Succeed())
+			_, err := os.Stat(bundle.DockerFile)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+			Expect(swapDockerfile("bundle.cluster.Dockerfile")).To(Succeed())
+		})
+		It("reads an input directory of variants as existing bundles", func() {
+			Expect(os.MkdirAll(filepath.Join("bundle", "cluster", bundle.ManifestsDir), 0755)).To(Succeed())
+			c := bundleCmd{inputDir: "bundle"}
+			Expect(c.isRenderedInput()).To(BeTrue())
+			c.installModeVariants = true
+			Expect(c.isRenderedInput()).To(BeFalse())
+		})
+	})
+})
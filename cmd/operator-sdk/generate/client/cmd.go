@@ -0,0 +1,116 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	genclient "github.com/operator-framework/operator-sdk/internal/generate/client"
+	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `
+Running 'generate client' generates a typed clientset, listers, and informers for
+the project's API types, so that other Go programs can use the operator's custom
+resources without a controller-runtime client. It runs client-gen, lister-gen,
+and informer-gen from k8s.io/code-generator, which must be installed:
+
+  $ go get k8s.io/code-generator/cmd/{client-gen,lister-gen,informer-gen}@` + genclient.CodeGeneratorVersion + `
+
+A client is generated for every API group version that has a resource in the
+PROJECT file, from the api/<version> directory, or apis/<group>/<version> for
+multigroup projects. code-generator only generates clients for types marked with
+'+genclient', and cluster-scoped types must also be marked with
+'+genclient:nonNamespaced', so add these markers above each resource's type:
+
+  // +genclient
+  // +kubebuilder:object:root=true
+  // +kubebuilder:subresource:status
+
+  // Memcached is the Schema for the memcacheds API
+  type Memcached struct {
+
+Clients are written to the '--output-dir' directory, replacing any previously
+generated clients:
+- <output-dir>/clientset/versioned: the typed clientset
+- <output-dir>/listers: listers for each type
+- <output-dir>/informers: shared informers for each type
+`
+
+const examples = `
+  # Generate clients in pkg/client:
+  $ operator-sdk generate client
+
+  # Generate clients in client, with a different license header:
+  $ operator-sdk generate client --output-dir client --header-file hack/license.go.txt
+`
+
+type clientCmd struct {
+	outputDir  string
+	headerFile string
+}
+
+// NewCmd returns the 'client' command configured for the new project layout.
+func NewCmd() *cobra.Command {
+	c := &clientCmd{}
+	cmd := &cobra.Command{
+		Use:     "client",
+		Short:   "Generates a typed Go clientset, listers, and informers for the project's APIs",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+
+			operatorType, err := projutil.GetOperatorTypeErr()
+			if err != nil {
+				return fmt.Errorf("error detecting project type: %v", err)
+			}
+			if operatorType != projutil.OperatorTypeGo {
+				return fmt.Errorf("clients can only be generated for Go projects, not %s projects", operatorType)
+			}
+			cfg, err := kbutil.ReadConfig()
+			if err != nil {
+				return fmt.Errorf("error reading configuration: %v", err)
+			}
+
+			g := genclient.NewGenerator(cfg)
+			g.OutputDir = c.outputDir
+			g.HeaderFile = c.headerFile
+			if err := g.Generate(); err != nil {
+				log.Fatalf("Error generating clients: %v", err)
+			}
+
+			log.Infof("Generated clients in %s", c.outputDir)
+			return nil
+		},
+	}
+
+	c.addFlagsTo(cmd.Flags())
+
+	return cmd
+}
+
+func (c *clientCmd) addFlagsTo(fs *pflag.FlagSet) {
+	fs.StringVar(&c.outputDir, "output-dir", "pkg/client", "Project-relative directory to write clients to")
+	fs.StringVar(&c.headerFile, "header-file", "hack/boilerplate.go.txt",
+		"File containing the header prepended to every generated file")
+}
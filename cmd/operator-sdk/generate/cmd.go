@@ -17,7 +17,9 @@ package generate
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/argocd"
 	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/bundle"
+	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/client"
 	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/kustomize"
 	"github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/packagemanifests"
 )
@@ -35,6 +37,8 @@ code or manifests.`,
 		kustomize.NewCmd(),
 		bundle.NewCmd(),
 		packagemanifests.NewCmd(),
+		argocd.NewCmd(),
+		client.NewCmd(),
 	)
 	return cmd
 }
@@ -19,12 +19,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
 )
 
@@ -52,6 +57,58 @@ func IsPipeReader() bool {
 	return info.Mode()&os.ModeNamedPipe != 0
 }
 
+// DefaultStdinTimeout is the default time to wait for manifests piped to stdin,
+// ex. by 'kustomize build', to be read.
+const DefaultStdinTimeout = 5 * time.Minute
+
+// stdin is read by ReadStdin. Tests may replace it.
+var stdin io.Reader = os.Stdin
+
+// ReadStdin reads all of stdin, returning an error if reading does not complete
+// within timeout. A non-positive timeout waits indefinitely. Empty stdin, ex.
+// an empty pipe in CI, means no manifests were piped, so nil is returned.
+// Manifests are commonly piped from 'kustomize build', which may hang while
+// fetching remote bases; the returned errors point users to that command.
+func ReadStdin(timeout time.Duration) ([]byte, error) {
+	return readWithTimeout(stdin, timeout)
+}
+
+// readWithTimeout reads all of r, returning an error if reading does not complete
+// within timeout. nil is returned if r contains only whitespace.
+func readWithTimeout(r io.Reader, timeout time.Duration) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		b, err := ioutil.ReadAll(r)
+		done <- result{b, err}
+	}()
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, fmt.Errorf("error reading manifests from stdin: %v", res.err)
+		}
+		if len(bytes.TrimSpace(res.data)) == 0 {
+			return nil, nil
+		}
+		return res.data, nil
+	case <-timer:
+		return nil, fmt.Errorf("timed out after %s waiting for manifests on stdin; "+
+			"the command piped to stdin, ex. 'kustomize build', may be hanging on a remote base "+
+			"or waiting for input. Run it separately to see its output", timeout)
+	}
+}
+
 // WriteObjects writes each object in objs to w.
 func WriteObjects(w io.Writer, objs ...interface{}) error {
 	for _, obj := range objs {
@@ -76,6 +133,8 @@ func WriteObjectsToFiles(dir string, objs ...interface{}) error {
 			fileName = makeCRDFileName(t.Spec.Group, t.Spec.Names.Plural)
 		case apiextv1beta1.CustomResourceDefinition:
 			fileName = makeCRDFileName(t.Spec.Group, t.Spec.Names.Plural)
+		case *unstructured.Unstructured:
+			fileName = makeObjectFileName(t.GroupVersionKind(), t.GetName())
 		default:
 			return fmt.Errorf("unknown object type: %T", t)
 		}
@@ -95,6 +154,13 @@ func makeCRDFileName(group, resource string) string {
 	return fmt.Sprintf("%s_%s.yaml", group, resource)
 }
 
+func makeObjectFileName(gvk schema.GroupVersionKind, name string) string {
+	if gvk.Group == "" {
+		return fmt.Sprintf("%s_%s_%s.yaml", name, gvk.Version, strings.ToLower(gvk.Kind))
+	}
+	return fmt.Sprintf("%s_%s_%s_%s.yaml", name, gvk.Group, gvk.Version, strings.ToLower(gvk.Kind))
+}
+
 // WriteObjectsToFilesLegacy creates dir then writes each object in objs to a
 // file in legacy format in dir.
 func WriteObjectsToFilesLegacy(dir string, objs ...interface{}) error {
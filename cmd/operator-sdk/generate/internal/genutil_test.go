@@ -0,0 +1,71 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genutil
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("pipe broken")
+}
+
+func TestReadStdin(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+
+	blocked, w := io.Pipe()
+	defer w.Close()
+
+	cases := []struct {
+		name     string
+		r        io.Reader
+		timeout  time.Duration
+		expected string
+		errMsg   string
+	}{
+		{"data", strings.NewReader("kind: Deployment\n"), time.Second, "kind: Deployment\n", ""},
+		{"data with no timeout", strings.NewReader("kind: Deployment\n"), 0, "kind: Deployment\n", ""},
+		{"empty", strings.NewReader(""), time.Second, "", ""},
+		{"whitespace", strings.NewReader("\n  \n"), time.Second, "", ""},
+		{"read error", errReader{}, time.Second, "", "error reading manifests from stdin: pipe broken"},
+		{"timeout", blocked, 10 * time.Millisecond, "", "timed out after 10ms waiting for manifests on stdin"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdin = c.r
+			b, err := ReadStdin(c.timeout)
+			if c.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), c.errMsg) {
+					t.Fatalf("Wanted error containing %q, got: %v", c.errMsg, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(b) != c.expected {
+				t.Errorf("Wanted data %q, got: %q", c.expected, b)
+			}
+			if c.expected == "" && b != nil {
+				t.Errorf("Wanted nil data, got: %q", b)
+			}
+		})
+	}
+}
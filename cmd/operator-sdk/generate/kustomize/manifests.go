@@ -16,6 +16,7 @@ package kustomize
 
 import (
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
@@ -24,7 +25,11 @@ import (
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 
 	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+	gencrd "github.com/operator-framework/operator-sdk/internal/generate/crd"
+	genwebhook "github.com/operator-framework/operator-sdk/internal/generate/webhook"
+	"github.com/operator-framework/operator-sdk/internal/plugins/helm/v1/chartutil"
 	"github.com/operator-framework/operator-sdk/internal/scaffold/kustomize"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
@@ -34,6 +39,15 @@ Running 'generate kustomize manifests' will (re)generate kustomize bases and a k
 'config/manifests', which are used to build operator-framework manifests by other operator-sdk commands.
 This command will interactively ask for UI metadata, an important component of manifest bases,
 by default unless a base already exists or you set '--interactive=false'.
+
+For Helm operators, UI metadata of a new base is instead populated from the Chart.yaml of the chart
+in '--helm-chart-dir', which defaults to the only chart in 'helm-charts'. Set '--interactive' to also
+be prompted for UI metadata, which overrides values from Chart.yaml.
+
+For Go operators, webhook configurations in 'config/webhook/manifests.yaml' are also regenerated
+from '+kubebuilder:webhook' markers, so the webhook definitions of generated bundles match the code.
+A warning is also logged for each '+kubebuilder:default' marker in '--apis-dir' whose default is
+missing from the field's schema in the CRDs in 'config/crd/bases'; controller-gen drops some defaults.
 `
 
 const examples = `
@@ -64,6 +78,7 @@ type manifestsCmd struct {
 	inputDir     string
 	outputDir    string
 	apisDir      string
+	helmChartDir string
 	quiet        bool
 
 	// Interactive options.
@@ -119,6 +134,8 @@ func (c *manifestsCmd) addFlagsTo(fs *pflag.FlagSet) {
 	fs.StringVar(&c.inputDir, "input-dir", "", "Directory containing existing kustomize files")
 	fs.StringVar(&c.outputDir, "output-dir", "", "Directory to write kustomize files")
 	fs.StringVar(&c.apisDir, "apis-dir", "", "Root directory for API type defintions")
+	fs.StringVar(&c.helmChartDir, "helm-chart-dir", "", "Helm chart directory whose Chart.yaml metadata "+
+		"populates a new base (Helm operators only)")
 	fs.BoolVarP(&c.quiet, "quiet", "q", false, "Run in quiet mode")
 	fs.BoolVar(&c.interactive, "interactive", false, "When set or no kustomize base exists, an interactive "+
 		"command prompt will be presented to accept non-inferrable metadata")
@@ -146,6 +163,60 @@ func (c *manifestsCmd) setDefaults(cfg *config.Config) {
 			c.apisDir = "api"
 		}
 	}
+	if c.helmChartDir == "" && projutil.PluginKeyToOperatorType(cfg.Layout) == projutil.OperatorTypeHelm {
+		c.helmChartDir = defaultHelmChartDir()
+	}
+}
+
+// defaultHelmChartDir returns the chart directory in chartutil.HelmChartsDir
+// if exactly one exists, or an empty string otherwise.
+func defaultHelmChartDir() string {
+	infos, err := ioutil.ReadDir(chartutil.HelmChartsDir)
+	if err != nil {
+		log.Debugf("Not reading chart metadata: %v", err)
+		return ""
+	}
+	var chartDirs []string
+	for _, info := range infos {
+		if info.IsDir() {
+			chartDirs = append(chartDirs, filepath.Join(chartutil.HelmChartsDir, info.Name()))
+		}
+	}
+	if len(chartDirs) != 1 {
+		log.Debugf("Not reading chart metadata: found %d charts in %s, set --helm-chart-dir to choose one",
+			len(chartDirs), chartutil.HelmChartsDir)
+		return ""
+	}
+	return chartDirs[0]
+}
+
+// crdBasesDir contains the CRDs generated by controller-gen in Go projects.
+var crdBasesDir = filepath.Join("config", "crd", "bases")
+
+// warnMissingCRDDefaults logs a warning for each default set on an API type
+// field in apisDir that is missing from the CRDs in crdsDir.
+func warnMissingCRDDefaults(apisDir, crdsDir string) {
+	v1crds, v1beta1crds, err := k8sutil.GetCustomResourceDefinitions(crdsDir)
+	if err != nil {
+		log.Debugf("Not checking CRD defaults: %v", err)
+		return
+	}
+	for _, crd := range v1beta1crds {
+		v1crd, err := k8sutil.Convertv1beta1Tov1CustomResourceDefinition(&crd)
+		if err != nil {
+			log.Debugf("Not checking CRD %s defaults: %v", crd.GetName(), err)
+			continue
+		}
+		v1crds = append(v1crds, *v1crd)
+	}
+	missing, err := gencrd.CheckDefaults(apisDir, v1crds)
+	if err != nil {
+		log.Warnf("Error checking CRD defaults: %v", err)
+		return
+	}
+	for _, m := range missing {
+		log.Warn(m)
+	}
 }
 
 // kustomization.yaml file contents for manifests. this should always be written to
@@ -166,6 +237,7 @@ func (c manifestsCmd) run(cfg *config.Config) error {
 	csvGen := gencsv.Generator{
 		OperatorName: c.operatorName,
 		OperatorType: projutil.PluginKeyToOperatorType(cfg.Layout),
+		ChartDir:     c.helmChartDir,
 	}
 	opts := []gencsv.Option{
 		gencsv.WithBase(c.inputDir, c.apisDir, c.interactiveLevel),
@@ -175,6 +247,14 @@ func (c manifestsCmd) run(cfg *config.Config) error {
 		return fmt.Errorf("error generating kustomize bases: %v", err)
 	}
 
+	// Regenerate webhook configurations from markers, which are read into CSVs.
+	if csvGen.OperatorType == projutil.OperatorTypeGo {
+		if err := (genwebhook.Generator{}).Generate(); err != nil {
+			return err
+		}
+		warnMissingCRDDefaults(c.apisDir, crdBasesDir)
+	}
+
 	// Write a kustomization.yaml to outputDir if one does not exist.
 	if err := kustomize.WriteIfNotExist(c.outputDir, manifestsKustomization); err != nil {
 		return fmt.Errorf("error writing kustomization.yaml: %v", err)
@@ -17,11 +17,13 @@ package packagemanifests
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	genutil "github.com/operator-framework/operator-sdk/cmd/operator-sdk/generate/internal"
 	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
 )
 
@@ -34,12 +36,16 @@ type packagemanifestsCmd struct {
 	inputDir     string
 	outputDir    string
 	kustomizeDir string
+	stdinTimeout time.Duration
 	deployDir    string
 	crdsDir      string
 	updateCRDs   bool
 	stdout       bool
 	quiet        bool
 
+	// ClusterServiceVersion options.
+	csvNameTemplate string
+
 	// Package manifest options.
 	channelName      string
 	isDefaultChannel bool
@@ -88,8 +94,15 @@ func (c *packagemanifestsCmd) addFlagsTo(fs *pflag.FlagSet) {
 	fs.StringVar(&c.inputDir, "input-dir", "", "Directory to read existing package manifests from. "+
 		"This directory is the parent of individual versioned package directories, and different from --deploy-dir")
 	fs.StringVar(&c.outputDir, "output-dir", "", "Directory in which to write package manifests")
+	fs.StringVar(&c.csvNameTemplate, "csv-name-template", "{{.Package}}.v{{.Version}}",
+		"Template of the ClusterServiceVersion's name, with {{.Package}} and {{.Version}} "+
+			"replaced by the operator's name and version. \"replaces\", \"skips\", and the package's "+
+			"current CSV are set using the same template")
 	fs.StringVar(&c.kustomizeDir, "kustomize-dir", filepath.Join("config", "manifests"),
 		"Directory containing kustomize bases and a kustomization.yaml for operator-framework manifests")
+	fs.DurationVar(&c.stdinTimeout, "kustomize-build-timeout", genutil.DefaultStdinTimeout,
+		"Time to wait for manifests piped to stdin, ex. by 'kustomize build', before failing. "+
+			"Set to 0 to wait indefinitely")
 	fs.StringVar(&c.deployDir, "deploy-dir", "", "Root directory for operator manifests such as "+
 		"Deployments and RBAC, ex. 'deploy'. This directory is different from that passed to --input-dir")
 	fs.StringVar(&c.crdsDir, "crds-dir", "", "Root directory for CustomResoureDefinition manifests")
@@ -15,6 +15,7 @@
 package packagemanifests
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -104,6 +105,9 @@ func (c packagemanifestsCmd) validate() error {
 	} else {
 		return errors.New("--version must be set")
 	}
+	if err := gencsv.ValidateNameTemplate(c.csvNameTemplate, c.operatorName, c.version); err != nil {
+		return fmt.Errorf("invalid --csv-name-template: %v", err)
+	}
 
 	if c.fromVersion != "" {
 		if err := genutil.ValidateVersion(c.fromVersion); err != nil {
@@ -153,7 +157,11 @@ func (c packagemanifestsCmd) run(cfg *config.Config) error {
 
 	col := &collector.Manifests{}
 	if genutil.IsPipeReader() {
-		if err := col.UpdateFromReader(os.Stdin); err != nil {
+		b, err := genutil.ReadStdin(c.stdinTimeout)
+		if err != nil {
+			return err
+		}
+		if err := col.UpdateFromReader(bytes.NewReader(b)); err != nil {
 			return err
 		}
 	}
@@ -164,11 +172,12 @@ func (c packagemanifestsCmd) run(cfg *config.Config) error {
 	}
 
 	csvGen := gencsv.Generator{
-		OperatorName: c.operatorName,
-		OperatorType: projutil.PluginKeyToOperatorType(cfg.Layout),
-		Version:      c.version,
-		FromVersion:  c.fromVersion,
-		Collector:    col,
+		OperatorName:    c.operatorName,
+		OperatorType:    projutil.PluginKeyToOperatorType(cfg.Layout),
+		Version:         c.version,
+		FromVersion:     c.fromVersion,
+		CSVNameTemplate: c.csvNameTemplate,
+		Collector:       col,
 	}
 
 	stdout := genutil.NewMultiManifestWriter(os.Stdout)
@@ -220,6 +229,7 @@ func (c packagemanifestsCmd) generatePackageManifest() error {
 		Version:          c.version,
 		ChannelName:      c.channelName,
 		IsDefaultChannel: c.isDefaultChannel,
+		CSVNameTemplate:  c.csvNameTemplate,
 	}
 	opts := []genpkg.Option{
 		genpkg.WithBase(c.inputDir),
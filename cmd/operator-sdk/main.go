@@ -36,20 +36,23 @@ func main() {
 		return
 	}
 
-	// Use the legacy CLI if inside of a Go/Helm/Ansible legacy project
-	operatorType := projutil.GetOperatorType()
+	// Use the legacy CLI if inside of a Go/Helm/Ansible legacy project.
+	// The type is detected from the project's files, not overridden by
+	// projutil.ProjectTypeEnv, so that it cannot route "init" to the legacy CLI.
+	// Without a PROJECT file, detection can only fail to find legacy files.
+	operatorType, _ := projutil.DetectOperatorType()
 	switch operatorType {
 	case projutil.OperatorTypeGo, projutil.OperatorTypeHelm, projutil.OperatorTypeAnsible:
 		// Deprecation warning for Go projects
 		// TODO/Discuss: UX wise, is displaying this notice on every command that runs
 		// in the legacy Go projects too loud.
 		if operatorType == projutil.OperatorTypeGo {
-			depMsg := "Operator SDK has a new CLI and project layout that is aligned with Kubebuilder.\n" +
-				"See `operator-sdk init -h` and the following doc on how to scaffold a new project:\n" +
-				"https://sdk.operatorframework.io/docs/golang/quickstart/\n" +
-				"To migrate existing projects to the new layout see:\n" +
-				"https://sdk.operatorframework.io/docs/golang/project_migration_guide/\n"
-			projutil.PrintDeprecationWarning(depMsg)
+			projutil.PrintDeprecation(projutil.Deprecation{
+				Feature: "The legacy Go project layout and CLI",
+				Replacement: "the new CLI and project layout that are aligned with Kubebuilder " +
+					"(see `operator-sdk init -h` and https://sdk.operatorframework.io/docs/golang/quickstart/)",
+				DocsURL: "https://sdk.operatorframework.io/docs/golang/project_migration_guide/",
+			})
 		}
 		if err := cli.RunLegacy(); err != nil {
 			log.Fatal(err)
@@ -16,6 +16,7 @@ package olm
 
 import (
 	"github.com/operator-framework/operator-sdk/internal/olm"
+	olmresourceclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -37,6 +38,7 @@ func newInstallCmd() *cobra.Command {
 	cmd.Flags().StringVar(&mgr.Version, "version", olm.DefaultVersion, "version of OLM resources to install")
 	cmd.Flags().StringVar(&mgr.OLMNamespace, "olm-namespace", olm.DefaultOLMNamespace,
 		"namespace where OLM is to be installed.")
+	cmd.Flags().StringVar(&mgr.DryRun, "dry-run", olmresourceclient.DryRunNone, olmresourceclient.DryRunUsage)
 	mgr.AddToFlagSet(cmd.Flags())
 	return cmd
 }
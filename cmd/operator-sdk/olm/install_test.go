@@ -18,6 +18,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/operator-framework/operator-sdk/internal/olm"
+	olmresourceclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 )
 
 var _ = Describe("Running an olm install command", func() {
@@ -37,6 +38,11 @@ var _ = Describe("Running an olm install command", func() {
 			Expect(flag).NotTo(BeNil())
 			Expect(flag.DefValue).To(Equal(olm.DefaultOLMNamespace))
 			Expect(flag.Usage).NotTo(BeNil())
+
+			flag = cmd.Flags().Lookup("dry-run")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(olmresourceclient.DryRunNone))
+			Expect(flag.Usage).NotTo(BeNil())
 		})
 	})
 })
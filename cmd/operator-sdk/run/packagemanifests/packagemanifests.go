@@ -20,6 +20,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 	olmoperator "github.com/operator-framework/operator-sdk/internal/olm/operator"
 )
 
@@ -55,6 +56,10 @@ must be set to a valid package manifests root directory, ex. '<project-root>/pac
 	}
 
 	c.PackageManifestsCmd.AddToFlagSet(cmd.Flags())
+	cmd.Flags().StringVar(&c.DryRun, "dry-run", olmclient.DryRunNone, olmclient.DryRunUsage)
+	cmd.Flags().StringVar(&c.OutputManifestsDir, "output-manifests", "", olmclient.OutputManifestsUsage)
+	cmd.Flags().BoolVar(&c.ManifestsOnly, "manifests-only", false, olmclient.ManifestsOnlyUsage)
+	cmd.Flags().StringArrayVar(&c.WaitForConditions, "wait-for-condition", nil, olmclient.WaitForConditionUsage)
 
 	return cmd
 }
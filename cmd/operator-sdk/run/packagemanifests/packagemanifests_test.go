@@ -17,6 +17,8 @@ package packagemanifests
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 )
 
 var _ = Describe("Running a run packagemanifests command", func() {
@@ -30,6 +32,10 @@ var _ = Describe("Running a run packagemanifests command", func() {
 			aliases := cmd.Aliases
 			Expect(len(aliases)).To(Equal(1))
 			Expect(aliases[0]).To(Equal("pm"))
+
+			flag := cmd.Flags().Lookup("dry-run")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(olmclient.DryRunNone))
 		})
 	})
 	Describe("validate", func() {
@@ -20,13 +20,17 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
 	scorecardannotations "github.com/operator-framework/operator-sdk/internal/annotations/scorecard"
@@ -47,6 +51,22 @@ type scorecardCmd struct {
 	list           bool
 	skipCleanup    bool
 	waitTime       time.Duration
+	useStorage     bool
+	storageClass   string
+	storageSize    string
+	storageImage   string
+	keepArtifacts  bool
+	nodeSelector   map[string]string
+	tolerations    []string
+
+	createNamespace bool
+	keepNamespace   bool
+
+	resultsWebhookURL         string
+	resultsWebhookAuth        string
+	resultsWebhookFailOnError bool
+
+	parsedTolerations []v1.Toleration
 }
 
 func NewCmd() *cobra.Command {
@@ -83,6 +103,36 @@ If the argument holds an image tag, it must be present remotely.`,
 		"Disable resource cleanup after tests are run")
 	scorecardCmd.Flags().DurationVarP(&c.waitTime, "wait-time", "w", time.Duration(30*time.Second),
 		"seconds to wait for tests to complete. Example: 35s")
+	scorecardCmd.Flags().BoolVar(&c.useStorage, "use-storage", false,
+		"Mount a PersistentVolumeClaim into test pods and read test results from it instead of from pod logs")
+	scorecardCmd.Flags().StringVar(&c.storageClass, "storage-class", "",
+		"Storage class of the results PersistentVolumeClaim. Defaults to the cluster's default storage class")
+	scorecardCmd.Flags().StringVar(&c.storageSize, "storage-size", scorecard.DefaultStorageSize,
+		"Size of the results PersistentVolumeClaim")
+	scorecardCmd.Flags().StringVar(&c.storageImage, "storage-image", scorecard.DefaultStorageImage,
+		"Image of the pod results are read through, which must provide 'sh' and 'cat'. "+
+			"Set to a mirrored image, pinned by digest, in disconnected clusters")
+	scorecardCmd.Flags().BoolVar(&c.keepArtifacts, "keep-artifacts", false,
+		"Do not delete the results PersistentVolumeClaim after tests are run")
+	scorecardCmd.Flags().StringToStringVar(&c.nodeSelector, "test-node-selector", nil,
+		"Node selector label, as key=value, added to all test pods. May be set more than once. "+
+			"A test's configured node selector overrides a label with the same key")
+	scorecardCmd.Flags().StringArrayVar(&c.tolerations, "test-toleration", nil,
+		"Toleration, as <key>[=<value>][:<effect>], added to all test pods. A toleration without a value "+
+			"uses the Exists operator, and one without an effect tolerates all effects. May be set more than once")
+	scorecardCmd.Flags().BoolVar(&c.createNamespace, "create-namespace", false,
+		"Run tests in a new namespace with a unique name, which is deleted after tests are run, so concurrent "+
+			"runs do not interfere. The --service-account, and service accounts named by tests, are created in it")
+	scorecardCmd.Flags().BoolVar(&c.keepNamespace, "keep-namespace", false,
+		"Do not delete the namespace created by --create-namespace after tests are run")
+	scorecardCmd.Flags().StringVar(&c.resultsWebhookURL, "results-webhook-url", "",
+		"HTTP(S) endpoint that test results are POSTed to as JSON, with the bundle they were run against, "+
+			"after tests are run")
+	scorecardCmd.Flags().StringVar(&c.resultsWebhookAuth, "results-webhook-auth", "",
+		"Value of the Authorization header sent to --results-webhook-url, ex. \"Bearer <token>\". "+
+			"Defaults to the value of $"+scorecard.ResultsWebhookAuthEnv)
+	scorecardCmd.Flags().BoolVar(&c.resultsWebhookFailOnError, "results-webhook-fail-on-error", false,
+		"Fail the run if test results cannot be posted to --results-webhook-url")
 
 	return scorecardCmd
 }
@@ -110,6 +160,9 @@ func (c *scorecardCmd) printOutput(output v1alpha3.TestList) error {
 }
 
 func (c *scorecardCmd) run() (err error) {
+	// The bundle argument identifies the tested bundle in posted results.
+	bundleRef := c.bundle
+
 	// Extract bundle image contents if bundle is inferred to be an image.
 	if _, err = os.Stat(c.bundle); err != nil && errors.Is(err, os.ErrNotExist) {
 		if c.bundle, err = extractBundleImage(c.bundle); err != nil {
@@ -158,12 +211,30 @@ func (c *scorecardCmd) run() (err error) {
 			Namespace:      scorecard.GetKubeNamespace(c.kubeconfig, c.namespace),
 			BundlePath:     c.bundle,
 			BundleMetadata: metadata,
+			UseStorage:     c.useStorage,
+			StorageClass:   c.storageClass,
+			StorageSize:    c.storageSize,
+			StorageImage:   c.storageImage,
+			KeepArtifacts:  c.keepArtifacts,
+			NodeSelector:   c.nodeSelector,
+			Tolerations:    c.parsedTolerations,
+
+			CreateNamespace: c.createNamespace,
+			KeepNamespace:   c.keepNamespace,
+		}
+		if c.createNamespace {
+			runner.Namespace = ""
 		}
 
 		// Only get the client if running tests.
 		if runner.Client, err = scorecard.GetKubeClient(c.kubeconfig); err != nil {
 			return fmt.Errorf("error getting kubernetes client: %w", err)
 		}
+		if c.useStorage {
+			if runner.RESTConfig, err = scorecard.GetKubeConfig(c.kubeconfig); err != nil {
+				return fmt.Errorf("error getting kubernetes config: %w", err)
+			}
+		}
 
 		o.TestRunner = &runner
 
@@ -171,21 +242,55 @@ func (c *scorecardCmd) run() (err error) {
 		defer cancel()
 
 		scorecardTests, err = o.Run(ctx)
+		if ns := runner.CreatedNamespace(); ns != "" && (c.keepNamespace || c.skipCleanup) {
+			log.Infof("Tests were run in namespace %s, which was kept", ns)
+		}
 		if err != nil {
 			return fmt.Errorf("error running tests %w", err)
 		}
+		if pvcName := runner.ResultsClaimName(); pvcName != "" && (c.keepArtifacts || c.skipCleanup) {
+			log.Infof("Test results were kept in PersistentVolumeClaim %s/%s", runner.Namespace, pvcName)
+		}
 	}
 
 	if err := c.printOutput(scorecardTests); err != nil {
 		log.Fatal(err)
 	}
 
+	if c.resultsWebhookURL != "" && !c.list {
+		payload := scorecard.ResultsPayload{
+			Bundle:  bundleRef,
+			Package: metadata[bundle.PackageLabel],
+			Results: scorecardTests,
+		}
+		if err := c.postResults(payload); err != nil {
+			if c.resultsWebhookFailOnError {
+				return err
+			}
+			log.Warn(err)
+		}
+	}
+
 	if hasFailingTest(scorecardTests) {
 		os.Exit(1)
 	}
 	return nil
 }
 
+// postResults posts payload to the results webhook.
+func (c *scorecardCmd) postResults(payload scorecard.ResultsPayload) error {
+	auth := c.resultsWebhookAuth
+	if auth == "" {
+		auth = os.Getenv(scorecard.ResultsWebhookAuthEnv)
+	}
+	webhook := scorecard.ResultsWebhook{
+		URL:           c.resultsWebhookURL,
+		Authorization: auth,
+		Client:        &http.Client{Timeout: 30 * time.Second},
+	}
+	return webhook.Post(context.TODO(), payload)
+}
+
 func hasFailingTest(list v1alpha3.TestList) bool {
 	for _, t := range list.Items {
 		for _, r := range t.Status.Results {
@@ -201,6 +306,33 @@ func (c *scorecardCmd) validate(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf("a bundle image or directory argument is required")
 	}
+	if !c.useStorage && (c.storageClass != "" || c.keepArtifacts) {
+		return fmt.Errorf("--storage-class and --keep-artifacts require --use-storage")
+	}
+	if c.createNamespace && c.namespace != "" {
+		return fmt.Errorf("--namespace cannot be set with --create-namespace")
+	}
+	if c.keepNamespace && !c.createNamespace {
+		return fmt.Errorf("--keep-namespace requires --create-namespace")
+	}
+	if err := scorecard.ValidateNodeSelector(c.nodeSelector); err != nil {
+		return fmt.Errorf("invalid --test-node-selector: %v", err)
+	}
+	var err error
+	if c.parsedTolerations, err = scorecard.ParseTolerations(c.tolerations); err != nil {
+		return fmt.Errorf("invalid --test-toleration: %v", err)
+	}
+	if c.resultsWebhookURL != "" {
+		u, err := url.Parse(c.resultsWebhookURL)
+		if err != nil {
+			return fmt.Errorf("invalid --results-webhook-url: %v", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --results-webhook-url %q: must be an http or https URL", c.resultsWebhookURL)
+		}
+	} else if c.resultsWebhookAuth != "" || c.resultsWebhookFailOnError {
+		return fmt.Errorf("--results-webhook-auth and --results-webhook-fail-on-error require --results-webhook-url")
+	}
 	return nil
 }
 
@@ -65,6 +65,48 @@ var _ = Describe("Running the scorecard command", func() {
 			Expect(flag).NotTo(BeNil())
 			Expect(flag.Shorthand).To(Equal("w"))
 			Expect(flag.DefValue).To(Equal("30s"))
+
+			flag = cmd.Flags().Lookup("use-storage")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+
+			flag = cmd.Flags().Lookup("storage-class")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("storage-size")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("1Gi"))
+
+			flag = cmd.Flags().Lookup("keep-artifacts")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+
+			flag = cmd.Flags().Lookup("test-node-selector")
+			Expect(flag).NotTo(BeNil())
+
+			flag = cmd.Flags().Lookup("test-toleration")
+			Expect(flag).NotTo(BeNil())
+
+			flag = cmd.Flags().Lookup("create-namespace")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+
+			flag = cmd.Flags().Lookup("keep-namespace")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+
+			flag = cmd.Flags().Lookup("results-webhook-url")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("results-webhook-auth")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("results-webhook-fail-on-error")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
 		})
 	})
 
@@ -86,5 +128,60 @@ var _ = Describe("Running the scorecard command", func() {
 			err := cmd.validate([]string{input})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("fails if storage options are set without --use-storage", func() {
+			cmd.storageClass = "standard"
+			err := cmd.validate([]string{"cherry"})
+			Expect(err).To(HaveOccurred())
+
+			cmd.useStorage = true
+			err = cmd.validate([]string{"cherry"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("parses valid test pod scheduling options", func() {
+			cmd.nodeSelector = map[string]string{"kubernetes.io/arch": "arm64"}
+			cmd.tolerations = []string{"nvidia.com/gpu:NoSchedule"}
+			err := cmd.validate([]string{"cherry"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.parsedTolerations).To(HaveLen(1))
+		})
+
+		It("fails if test pod scheduling options are invalid", func() {
+			cmd.nodeSelector = map[string]string{"kubernetes.io/arch": "not a label"}
+			err := cmd.validate([]string{"cherry"})
+			Expect(err).To(MatchError(ContainSubstring("invalid --test-node-selector")))
+
+			cmd.nodeSelector = nil
+			cmd.tolerations = []string{"nvidia.com/gpu:NoRun"}
+			err = cmd.validate([]string{"cherry"})
+			Expect(err).To(MatchError(ContainSubstring("invalid --test-toleration")))
+		})
+
+		It("validates results webhook options", func() {
+			cmd.resultsWebhookURL = "https://quality.example.com/api/scorecard"
+			cmd.resultsWebhookAuth = "Bearer token"
+			cmd.resultsWebhookFailOnError = true
+			err := cmd.validate([]string{"cherry"})
+			Expect(err).NotTo(HaveOccurred())
+
+			cmd.resultsWebhookURL = "quality.example.com"
+			err = cmd.validate([]string{"cherry"})
+			Expect(err).To(MatchError(ContainSubstring("invalid --results-webhook-url")))
+
+			cmd.resultsWebhookURL = ""
+			err = cmd.validate([]string{"cherry"})
+			Expect(err).To(MatchError(ContainSubstring("require --results-webhook-url")))
+		})
+		It("validates namespace creation options", func() {
+			cmd.createNamespace, cmd.keepNamespace = true, true
+			Expect(cmd.validate([]string{"cherry"})).To(Succeed())
+
+			cmd.namespace = "tests"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(ContainSubstring("cannot be set with --create-namespace")))
+
+			cmd.namespace, cmd.createNamespace = "", false
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(ContainSubstring("--keep-namespace requires --create-namespace")))
+		})
 	})
 })
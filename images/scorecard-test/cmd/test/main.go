@@ -17,6 +17,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 
@@ -77,6 +78,15 @@ func main() {
 	if err != nil {
 		log.Fatal("failed to generate json", err)
 	}
+
+	// Write results to storage if the scorecard runner configured it,
+	// otherwise to the pod log.
+	if resultsFile := os.Getenv(scorecard.ResultsFileEnv); resultsFile != "" {
+		if err := ioutil.WriteFile(resultsFile, prettyJSON, 0644); err != nil {
+			log.Fatal("failed to write results file", err)
+		}
+		return
+	}
 	fmt.Printf("%s\n", string(prettyJSON))
 
 }
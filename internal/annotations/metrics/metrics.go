@@ -32,7 +32,7 @@ const (
 const (
 	mediaTypeBundleAnnotation = "operators.operatorframework.io.metrics.mediatype.v1"
 	builderBundleAnnotation   = "operators.operatorframework.io.metrics.builder"
-	layoutBundleAnnotation    = "operators.operatorframework.io.metrics.project_layout"
+	LayoutBundleAnnotation    = "operators.operatorframework.io.metrics.project_layout"
 )
 
 // Object annotation keys.
@@ -47,7 +47,7 @@ func MakeBundleMetadataLabels(cfg *config.Config) map[string]string {
 	return map[string]string{
 		mediaTypeBundleAnnotation: mediaTypeV1,
 		builderBundleAnnotation:   getSDKBuilder(sdkversion.Version),
-		layoutBundleAnnotation:    getSDKProjectLayout(cfg),
+		LayoutBundleAnnotation:    getSDKProjectLayout(cfg),
 	}
 }
 
@@ -0,0 +1,346 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fieldcache builds controller-runtime caches that list and watch custom resources of some kinds with a
+// field selector, so Ansible and Helm operators never cache custom resources their --watch-field-selector excludes.
+//
+// The caches of controller-runtime v0.6 cannot be given list options, so objects of the selected kinds are kept by
+// informers of this package, and every other kind by the wrapped cache.
+package fieldcache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// defaultResync is the resync period of informers when cache.Options.Resync is unset, as in controller-runtime.
+const defaultResync = 10 * time.Hour
+
+// NewCacheFunc returns a function that creates caches with newCache, except that objects of each kind in selectors
+// are listed and watched only if they match that kind's field selector. Objects of selected kinds are watched in
+// each of namespaces; no namespaces, or an empty one, means all namespaces.
+func NewCacheFunc(newCache cache.NewCacheFunc, namespaces []string,
+	selectors map[schema.GroupVersionKind]string) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		inner, err := newCache(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		dc, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return newFieldCache(inner, dc, opts, namespaces, selectors)
+	}
+}
+
+// fieldCache is a cache.Cache that serves objects of selected kinds from field-selected informers.
+type fieldCache struct {
+	cache.Cache
+	scheme   *runtime.Scheme
+	selected map[schema.GroupVersionKind]*selectedKind
+}
+
+// selectedKind holds the field-selected informers of a kind, keyed by namespace.
+type selectedKind struct {
+	resource  schema.GroupResource
+	informers map[string]toolscache.SharedIndexInformer
+}
+
+func newFieldCache(inner cache.Cache, dc dynamic.Interface, opts cache.Options, namespaces []string,
+	selectors map[schema.GroupVersionKind]string) (*fieldCache, error) {
+	resync := defaultResync
+	if opts.Resync != nil {
+		resync = *opts.Resync
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{opts.Namespace}
+	}
+
+	c := &fieldCache{
+		Cache:    inner,
+		scheme:   opts.Scheme,
+		selected: make(map[schema.GroupVersionKind]*selectedKind, len(selectors)),
+	}
+	for gvk, s := range selectors {
+		sel, err := fields.ParseSelector(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field selector %q for %s: %v", s, gvk, err)
+		}
+		mapping, err := opts.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, err
+		}
+		kindNamespaces := namespaces
+		if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			kindNamespaces = []string{metav1.NamespaceAll}
+		}
+
+		tweak := func(o *metav1.ListOptions) { o.FieldSelector = sel.String() }
+		sk := &selectedKind{
+			resource:  mapping.Resource.GroupResource(),
+			informers: make(map[string]toolscache.SharedIndexInformer, len(kindNamespaces)),
+		}
+		for _, ns := range kindNamespaces {
+			sk.informers[ns] = dynamicinformer.NewFilteredDynamicInformer(dc, mapping.Resource, ns, resync,
+				toolscache.Indexers{toolscache.NamespaceIndex: toolscache.MetaNamespaceIndexFunc}, tweak).Informer()
+		}
+		c.selected[gvk] = sk
+	}
+	return c, nil
+}
+
+// Get reads objects of selected kinds from their informer, and any other object from the wrapped cache.
+// An object that does not match its kind's field selector is not found.
+func (c *fieldCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	sk, ok := c.selected[gvk]
+	if !ok {
+		return c.Cache.Get(ctx, key, obj)
+	}
+
+	inf, ok := sk.informers[key.Namespace]
+	if !ok {
+		inf, ok = sk.informers[metav1.NamespaceAll]
+	}
+	if !ok {
+		return apierrors.NewNotFound(sk.resource, key.Name)
+	}
+	storeKey := key.Name
+	if key.Namespace != "" {
+		storeKey = key.Namespace + "/" + key.Name
+	}
+	item, exists, err := inf.GetIndexer().GetByKey(storeKey)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apierrors.NewNotFound(sk.resource, key.Name)
+	}
+	return copyInto(item.(*unstructured.Unstructured), obj)
+}
+
+// List lists objects of selected kinds from their informers, and any other objects from the wrapped cache.
+// Label selectors and field selectors on metadata.name and metadata.namespace are applied to objects of selected
+// kinds.
+func (c *fieldCache) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	gvk, err := apiutil.GVKForObject(list, c.scheme)
+	if err != nil {
+		return err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	sk, ok := c.selected[gvk]
+	if !ok {
+		return c.Cache.List(ctx, list, opts...)
+	}
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	var items []runtime.Object
+	for _, ns := range sk.namespaces() {
+		if listOpts.Namespace != "" && ns != metav1.NamespaceAll && ns != listOpts.Namespace {
+			continue
+		}
+		indexer := sk.informers[ns].GetIndexer()
+		var objs []interface{}
+		if listOpts.Namespace != "" {
+			if objs, err = indexer.ByIndex(toolscache.NamespaceIndex, listOpts.Namespace); err != nil {
+				return err
+			}
+		} else {
+			objs = indexer.List()
+		}
+		for _, o := range objs {
+			u := o.(*unstructured.Unstructured)
+			if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(u.GetLabels())) {
+				continue
+			}
+			if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Matches(objectFields(u)) {
+				continue
+			}
+			item, err := c.convert(u, gvk)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+	}
+	return meta.SetList(list, items)
+}
+
+// GetInformer returns the informer of obj's kind.
+func (c *fieldCache) GetInformer(ctx context.Context, obj runtime.Object) (cache.Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	if sk, ok := c.selected[gvk]; ok {
+		return sk.informer(), nil
+	}
+	return c.Cache.GetInformer(ctx, obj)
+}
+
+// GetInformerForKind returns the informer of gvk.
+func (c *fieldCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	if sk, ok := c.selected[gvk]; ok {
+		return sk.informer(), nil
+	}
+	return c.Cache.GetInformerForKind(ctx, gvk)
+}
+
+// Start runs the informers of selected kinds and the wrapped cache until stop is closed. It blocks.
+func (c *fieldCache) Start(stop <-chan struct{}) error {
+	for _, sk := range c.selected {
+		for _, inf := range sk.informers {
+			go inf.Run(stop)
+		}
+	}
+	return c.Cache.Start(stop)
+}
+
+// WaitForCacheSync waits for the informers of selected kinds and the wrapped cache to sync.
+func (c *fieldCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	var synced []toolscache.InformerSynced
+	for _, sk := range c.selected {
+		for _, inf := range sk.informers {
+			synced = append(synced, inf.HasSynced)
+		}
+	}
+	if !toolscache.WaitForCacheSync(stop, synced...) {
+		return false
+	}
+	return c.Cache.WaitForCacheSync(stop)
+}
+
+// IndexField adds a field index to objects of obj's kind. Objects of selected kinds cannot be indexed.
+func (c *fieldCache) IndexField(ctx context.Context, obj runtime.Object, field string,
+	extractValue client.IndexerFunc) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.selected[gvk]; ok {
+		return fmt.Errorf("cannot index field %q of %s, which is watched with a field selector", field, gvk)
+	}
+	return c.Cache.IndexField(ctx, obj, field, extractValue)
+}
+
+// convert returns u as an object of the list's item type: unstructured, or typed if gvk is in the scheme.
+func (c *fieldCache) convert(u *unstructured.Unstructured, gvk schema.GroupVersionKind) (runtime.Object, error) {
+	if c.scheme == nil || !c.scheme.Recognizes(gvk) {
+		return u.DeepCopy(), nil
+	}
+	obj, err := c.scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	return obj, copyInto(u, obj)
+}
+
+// namespaces returns the namespaces of the kind's informers in order.
+func (sk *selectedKind) namespaces() []string {
+	nss := make([]string, 0, len(sk.informers))
+	for ns := range sk.informers {
+		nss = append(nss, ns)
+	}
+	sort.Strings(nss)
+	return nss
+}
+
+// informer returns the kind's informer, or one spanning all of its namespaces' informers.
+func (sk *selectedKind) informer() cache.Informer {
+	if len(sk.informers) == 1 {
+		for _, inf := range sk.informers {
+			return inf
+		}
+	}
+	infs := make(multiInformer, 0, len(sk.informers))
+	for _, ns := range sk.namespaces() {
+		infs = append(infs, sk.informers[ns])
+	}
+	return infs
+}
+
+// multiInformer is a cache.Informer over the informers of several namespaces.
+type multiInformer []toolscache.SharedIndexInformer
+
+func (m multiInformer) AddEventHandler(h toolscache.ResourceEventHandler) {
+	for _, inf := range m {
+		inf.AddEventHandler(h)
+	}
+}
+
+func (m multiInformer) AddEventHandlerWithResyncPeriod(h toolscache.ResourceEventHandler, resync time.Duration) {
+	for _, inf := range m {
+		inf.AddEventHandlerWithResyncPeriod(h, resync)
+	}
+}
+
+func (m multiInformer) AddIndexers(indexers toolscache.Indexers) error {
+	for _, inf := range m {
+		if err := inf.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m multiInformer) HasSynced() bool {
+	for _, inf := range m {
+		if !inf.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// objectFields returns the fields of u that custom resources can be selected by.
+func objectFields(u *unstructured.Unstructured) fields.Set {
+	return fields.Set{
+		"metadata.name":      u.GetName(),
+		"metadata.namespace": u.GetNamespace(),
+	}
+}
+
+// copyInto deep copies u into obj, which may be unstructured or typed.
+func copyInto(u *unstructured.Unstructured, obj runtime.Object) error {
+	if out, ok := obj.(*unstructured.Unstructured); ok {
+		u.DeepCopyInto(out)
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), obj)
+}
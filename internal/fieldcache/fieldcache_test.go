@@ -0,0 +1,213 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fieldcache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	gvk = schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Memcached"}
+	gvr = schema.GroupVersionResource{Group: "example.com", Version: "v1alpha1", Resource: "memcacheds"}
+)
+
+func newCR(namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetLabels(map[string]string{"app": name})
+	return u
+}
+
+// fakeAPIServer serves lists of crs, applying field selectors as the API server does, and records the field
+// selectors of list and watch requests.
+type fakeAPIServer struct {
+	sync.Mutex
+	crs       []*unstructured.Unstructured
+	selectors []string
+}
+
+func (s *fakeAPIServer) client() *fakedynamic.FakeDynamicClient {
+	dc := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	dc.PrependReactor("list", gvr.Resource, func(action clienttesting.Action) (bool, runtime.Object, error) {
+		sel := action.(clienttesting.ListAction).GetListRestrictions().Fields
+		s.record(sel)
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		for _, cr := range s.crs {
+			if cr.GetNamespace() == action.GetNamespace() && sel.Matches(objectFields(cr)) {
+				list.Items = append(list.Items, *cr.DeepCopy())
+			}
+		}
+		return true, list, nil
+	})
+	dc.PrependWatchReactor(gvr.Resource, func(action clienttesting.Action) (bool, watch.Interface, error) {
+		s.record(action.(clienttesting.WatchAction).GetWatchRestrictions().Fields)
+		return true, watch.NewFake(), nil
+	})
+	return dc
+}
+
+func (s *fakeAPIServer) record(sel fields.Selector) {
+	s.Lock()
+	defer s.Unlock()
+	s.selectors = append(s.selectors, sel.String())
+}
+
+// countingHandler counts the objects it is notified of.
+type countingHandler struct {
+	sync.Mutex
+	names []string
+}
+
+func (h *countingHandler) OnAdd(obj interface{}) {
+	h.Lock()
+	defer h.Unlock()
+	h.names = append(h.names, obj.(*unstructured.Unstructured).GetName())
+}
+func (h *countingHandler) OnUpdate(_, _ interface{}) {}
+func (h *countingHandler) OnDelete(_ interface{})    {}
+
+func (h *countingHandler) count() int {
+	h.Lock()
+	defer h.Unlock()
+	return len(h.names)
+}
+
+func TestFieldCache(t *testing.T) {
+	server := &fakeAPIServer{crs: []*unstructured.Unstructured{
+		newCR("ns1", "selected"),
+		newCR("ns1", "other"),
+		newCR("ns2", "selected"),
+		newCR("ns3", "selected"),
+	}}
+	scheme := runtime.NewScheme()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+
+	c, err := newFieldCache(&informertest.FakeInformers{Scheme: scheme}, server.client(),
+		cache.Options{Scheme: scheme, Mapper: mapper}, []string{"ns1", "ns2"},
+		map[schema.GroupVersionKind]string{gvk: "metadata.name=selected"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		if err := c.Start(stop); err != nil {
+			t.Error(err)
+		}
+	}()
+	if !c.WaitForCacheSync(stop) {
+		t.Fatal("cache did not sync")
+	}
+
+	server.Lock()
+	for _, sel := range server.selectors {
+		if sel != "metadata.name=selected" {
+			t.Errorf("got request with field selector %q, want %q", sel, "metadata.name=selected")
+		}
+	}
+	server.Unlock()
+
+	ctx := context.TODO()
+	get := func(namespace, name string) error {
+		return c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, newCR("", ""))
+	}
+	if err := get("ns1", "selected"); err != nil {
+		t.Errorf("getting selected CR: %v", err)
+	}
+	if err := get("ns1", "other"); !apierrors.IsNotFound(err) {
+		t.Errorf("getting CR excluded by the field selector: got %v, want not found", err)
+	}
+	if err := get("ns3", "selected"); !apierrors.IsNotFound(err) {
+		t.Errorf("getting CR of an unwatched namespace: got %v, want not found", err)
+	}
+
+	list := func(opts ...client.ListOption) int {
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, l, opts...); err != nil {
+			t.Fatal(err)
+		}
+		return len(l.Items)
+	}
+	if n := list(); n != 2 {
+		t.Errorf("listing all CRs: got %d, want 2", n)
+	}
+	if n := list(client.InNamespace("ns2")); n != 1 {
+		t.Errorf("listing CRs in ns2: got %d, want 1", n)
+	}
+	if n := list(client.MatchingLabels{"app": "other"}); n != 0 {
+		t.Errorf("listing CRs labelled app=other: got %d, want 0", n)
+	}
+
+	// A controller watching the kind is only notified of selected CRs.
+	inf, err := c.GetInformer(ctx, newCR("", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &countingHandler{}
+	inf.AddEventHandler(h)
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) { return h.count() >= 2, nil })
+	if err != nil {
+		t.Fatalf("got %d notifications, want 2", h.count())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := h.count(); n != 2 {
+		t.Errorf("got %d notifications (%v), want 2", n, h.names)
+	}
+
+	// Kinds without a field selector are served by the wrapped cache.
+	cm := &unstructured.Unstructured{}
+	cm.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "other"}, cm); err != nil {
+		t.Errorf("getting ConfigMap from the wrapped cache: %v", err)
+	}
+	if _, ok := c.selected[cm.GroupVersionKind()]; ok {
+		t.Error("ConfigMap is served by a field-selected informer")
+	}
+	if err := c.IndexField(ctx, newCR("", ""), "spec.size", func(runtime.Object) []string { return nil }); err == nil {
+		t.Error("indexing a field-selected kind did not fail")
+	}
+}
+
+func TestNewFieldCacheInvalidSelector(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+	_, err := newFieldCache(&informertest.FakeInformers{}, (&fakeAPIServer{}).client(),
+		cache.Options{Mapper: mapper}, nil, map[schema.GroupVersionKind]string{gvk: "metadata.name"})
+	if err == nil {
+		t.Error("got no error for an invalid field selector")
+	}
+}
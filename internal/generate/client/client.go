@@ -0,0 +1,322 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const (
+	// The code-generator executables invoked to generate clients.
+	// See https://github.com/kubernetes/code-generator
+	clientGenBin   = "client-gen"
+	listerGenBin   = "lister-gen"
+	informerGenBin = "informer-gen"
+
+	// CodeGeneratorVersion is the k8s.io/code-generator version matching the
+	// client-go version projects are scaffolded with.
+	CodeGeneratorVersion = "v0.18.4"
+
+	// genclientMarker is the marker code-generator requires on each type
+	// it generates a client for.
+	genclientMarker = "+genclient"
+
+	clientsetName     = "versioned"
+	clientsetDir      = "clientset"
+	listersDir        = "listers"
+	informersDir      = "informers"
+	defaultOutputDir  = "pkg/client"
+	defaultHeaderFile = "hack/boilerplate.go.txt"
+)
+
+var (
+	// User-facing errors.
+	errNoRepo      = errors.New("repo must be set in the PROJECT file")
+	errNoResources = errors.New("the PROJECT file has no resources; create an API first")
+)
+
+// Generator generates a typed clientset, listers, and informers for a Go
+// project's API types with k8s.io/code-generator.
+type Generator struct {
+	// Repo is the project's Go module path.
+	Repo string
+	// MultiGroup is true if the project's API types are in apis/<group>/<version>
+	// rather than api/<version>.
+	MultiGroup bool
+	// Resources are the project's resources. A client is generated for every
+	// API group version that has a resource.
+	Resources []config.GVK
+	// OutputDir is the project-relative directory clients are written to.
+	// Defaults to "pkg/client".
+	OutputDir string
+	// HeaderFile is the boilerplate header prepended to every generated file.
+	// Defaults to "hack/boilerplate.go.txt".
+	HeaderFile string
+}
+
+// NewGenerator returns a Generator for the project configured by cfg.
+func NewGenerator(cfg *config.Config) Generator {
+	return Generator{
+		Repo:       cfg.Repo,
+		MultiGroup: cfg.MultiGroup,
+		Resources:  cfg.Resources,
+	}
+}
+
+// Generate writes a clientset to <OutputDir>/clientset/versioned, listers to
+// <OutputDir>/listers, and informers to <OutputDir>/informers, replacing any
+// previously generated clients.
+func (g Generator) Generate() error {
+	g.setDefaults()
+	if g.Repo == "" {
+		return errNoRepo
+	}
+	if len(g.Resources) == 0 {
+		return errNoResources
+	}
+	if filepath.IsAbs(g.OutputDir) || strings.HasPrefix(filepath.Clean(g.OutputDir), "..") {
+		return fmt.Errorf("output directory %s must be in the project", g.OutputDir)
+	}
+	for _, bin := range []string{clientGenBin, listerGenBin, informerGenBin} {
+		if _, err := exec.LookPath(bin); err != nil {
+			return fmt.Errorf("%s must be installed to generate clients, ex. with "+
+				"'go get k8s.io/code-generator/cmd/%s@%s': %v", bin, bin, CodeGeneratorVersion, err)
+		}
+	}
+	if err := g.checkMarkers(); err != nil {
+		return err
+	}
+
+	// code-generator writes to <output-base>/<output-package>, which for
+	// projects outside of $GOPATH is not the project, so generate into
+	// a temporary directory then move the result into the project.
+	// The directory is created in the project so it can be renamed.
+	outputBase, err := ioutil.TempDir(".", ".operator-sdk-client-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(outputBase)
+
+	for _, cmd := range g.commands(outputBase) {
+		if err := projutil.ExecCmd(cmd); err != nil {
+			return fmt.Errorf("error running %s: %v", filepath.Base(cmd.Path), err)
+		}
+	}
+
+	generatedDir := filepath.Join(outputBase, filepath.FromSlash(g.outputPackage()))
+	for _, dir := range []string{clientsetDir, listersDir, informersDir} {
+		dst := filepath.Join(g.OutputDir, dir)
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(generatedDir, dir), dst); err != nil {
+			return fmt.Errorf("error moving generated %s: %v", dir, err)
+		}
+	}
+	return nil
+}
+
+func (g *Generator) setDefaults() {
+	if g.OutputDir == "" {
+		g.OutputDir = defaultOutputDir
+	}
+	if g.HeaderFile == "" {
+		g.HeaderFile = defaultHeaderFile
+	}
+}
+
+// outputPackage returns the import path of OutputDir.
+func (g Generator) outputPackage() string {
+	return path.Join(g.Repo, filepath.ToSlash(g.OutputDir))
+}
+
+// groupVersionDirs returns the sorted, project-relative directories of every
+// API group version with a resource.
+func (g Generator) groupVersionDirs() []string {
+	dirSet := map[string]struct{}{}
+	for _, r := range g.Resources {
+		dirSet[g.apiDir(r)] = struct{}{}
+	}
+	dirs := make([]string, 0, len(dirSet))
+	for dir := range dirSet {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// apiDir returns the project-relative directory of r's API types, which
+// follows the layout scaffolded by 'create api'.
+func (g Generator) apiDir(r config.GVK) string {
+	if g.MultiGroup {
+		return path.Join("apis", r.Group, r.Version)
+	}
+	return path.Join("api", r.Version)
+}
+
+// inputPackages returns the import paths of every API group version with a resource.
+func (g Generator) inputPackages() []string {
+	dirs := g.groupVersionDirs()
+	pkgs := make([]string, len(dirs))
+	for i, dir := range dirs {
+		pkgs[i] = path.Join(g.Repo, dir)
+	}
+	return pkgs
+}
+
+// commands returns the client-gen, lister-gen, and informer-gen commands,
+// in that order, that generate clients into outputBase.
+func (g Generator) commands(outputBase string) []*exec.Cmd {
+	outPkg := g.outputPackage()
+	inputs := strings.Join(g.inputPackages(), ",")
+	common := []string{"--go-header-file", g.HeaderFile, "--output-base", outputBase}
+
+	clientGen := append([]string{
+		"--clientset-name", clientsetName,
+		"--input-base", "",
+		"--input", inputs,
+		"--output-package", path.Join(outPkg, clientsetDir),
+	}, common...)
+	listerGen := append([]string{
+		"--input-dirs", inputs,
+		"--output-package", path.Join(outPkg, listersDir),
+	}, common...)
+	informerGen := append([]string{
+		"--input-dirs", inputs,
+		"--versioned-clientset-package", path.Join(outPkg, clientsetDir, clientsetName),
+		"--listers-package", path.Join(outPkg, listersDir),
+		"--output-package", path.Join(outPkg, informersDir),
+	}, common...)
+
+	return []*exec.Cmd{
+		exec.Command(clientGenBin, clientGen...),
+		exec.Command(listerGenBin, listerGen...),
+		exec.Command(informerGenBin, informerGen...),
+	}
+}
+
+// checkMarkers returns an error listing every resource whose type is not
+// declared with a +genclient marker, since code-generator silently skips those.
+func (g Generator) checkMarkers() error {
+	markedByDir := map[string]map[string]bool{}
+	var missing []string
+	for _, r := range g.Resources {
+		dir := g.apiDir(r)
+		marked, ok := markedByDir[dir]
+		if !ok {
+			var err error
+			if marked, err = getTypeMarkers(filepath.FromSlash(dir)); err != nil {
+				return fmt.Errorf("error parsing API types in %s: %v", dir, err)
+			}
+			markedByDir[dir] = marked
+		}
+		hasMarker, found := marked[r.Kind]
+		switch {
+		case !found:
+			missing = append(missing, fmt.Sprintf("%s: type %s not found", dir, r.Kind))
+		case !hasMarker:
+			missing = append(missing, fmt.Sprintf("%s: type %s has no %s marker", dir, r.Kind, genclientMarker))
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("cannot generate clients for all resources, add a %s marker "+
+			"(and %s:nonNamespaced for cluster-scoped types) above each type:\n%s",
+			genclientMarker, genclientMarker, strings.Join(missing, "\n"))
+	}
+	return nil
+}
+
+// getTypeMarkers parses the Go files in dir and returns whether each
+// declared type has a +genclient marker.
+func getTypeMarkers(dir string) (map[string]bool, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	marked := map[string]bool{}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			// Comment groups keyed by the line they end on.
+			groupsByEndLine := map[int]*ast.CommentGroup{}
+			for _, cg := range f.Comments {
+				groupsByEndLine[fset.Position(cg.End()).Line] = cg
+			}
+			// markerComments returns the comment group documenting a node at pos,
+			// and, like code-generator, the group separated from it by one blank
+			// line, where kubebuilder scaffolds markers.
+			markerComments := func(doc *ast.CommentGroup, pos token.Pos) []*ast.CommentGroup {
+				if doc != nil {
+					pos = doc.Pos()
+				}
+				return []*ast.CommentGroup{doc, groupsByEndLine[fset.Position(pos).Line-2]}
+			}
+
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					// Markers may be on the declaration, or on the spec in a grouped declaration.
+					groups := markerComments(ts.Doc, ts.Pos())
+					if !gen.Lparen.IsValid() {
+						groups = markerComments(gen.Doc, gen.Pos())
+					}
+					marked[ts.Name.Name] = hasGenclientMarker(groups...)
+				}
+			}
+		}
+	}
+	return marked, nil
+}
+
+// hasGenclientMarker returns true if any of groups has a +genclient line. Markers
+// with arguments, like +genclient:nonNamespaced, only modify a +genclient marker.
+func hasGenclientMarker(groups ...*ast.CommentGroup) bool {
+	for _, cg := range groups {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if strings.TrimSpace(strings.TrimPrefix(c.Text, "//")) == genclientMarker {
+				return true
+			}
+		}
+	}
+	return false
+}
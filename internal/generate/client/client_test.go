@@ -0,0 +1,191 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+func TestGenerator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Client Generator Suite")
+}
+
+const typesWithMarkers = `package v1alpha1
+
+// +genclient
+// +kubebuilder:object:root=true
+
+// Memcached is the Schema for the memcacheds API
+type Memcached struct{}
+
+// +kubebuilder:object:root=true
+
+// MemcachedList contains a list of Memcached
+type MemcachedList struct{}
+
+type (
+	// +genclient
+	// +genclient:nonNamespaced
+	Cluster struct{}
+
+	// +genclient:nonNamespaced
+	Node struct{}
+)
+`
+
+var _ = Describe("Generator", func() {
+	var (
+		multiGroup = Generator{
+			Repo:       "github.com/example/memcached-operator",
+			MultiGroup: true,
+			Resources: []config.GVK{
+				{Group: "ship", Version: "v1beta1", Kind: "Frigate"},
+				{Group: "cache", Version: "v1alpha1", Kind: "Memcached"},
+				{Group: "cache", Version: "v1alpha1", Kind: "Redis"},
+			},
+		}
+		singleGroup = Generator{
+			Repo: "github.com/example/memcached-operator",
+			Resources: []config.GVK{
+				{Group: "cache", Version: "v1alpha1", Kind: "Memcached"},
+				{Group: "cache", Version: "v1", Kind: "Memcached"},
+			},
+		}
+	)
+
+	Describe("inputPackages", func() {
+		It("returns api/<version> packages for single group projects", func() {
+			Expect(singleGroup.inputPackages()).To(Equal([]string{
+				"github.com/example/memcached-operator/api/v1",
+				"github.com/example/memcached-operator/api/v1alpha1",
+			}))
+		})
+		It("returns apis/<group>/<version> packages for multigroup projects", func() {
+			Expect(multiGroup.inputPackages()).To(Equal([]string{
+				"github.com/example/memcached-operator/apis/cache/v1alpha1",
+				"github.com/example/memcached-operator/apis/ship/v1beta1",
+			}))
+		})
+	})
+
+	Describe("commands", func() {
+		It("generates clients into the output package", func() {
+			g := multiGroup
+			g.setDefaults()
+			cmds := g.commands("/tmp/out")
+			Expect(cmds).To(HaveLen(3))
+
+			inputs := "github.com/example/memcached-operator/apis/cache/v1alpha1," +
+				"github.com/example/memcached-operator/apis/ship/v1beta1"
+			common := []string{"--go-header-file", "hack/boilerplate.go.txt", "--output-base", "/tmp/out"}
+			Expect(cmds[0].Args).To(Equal(append([]string{clientGenBin,
+				"--clientset-name", "versioned",
+				"--input-base", "",
+				"--input", inputs,
+				"--output-package", "github.com/example/memcached-operator/pkg/client/clientset",
+			}, common...)))
+			Expect(cmds[1].Args).To(Equal(append([]string{listerGenBin,
+				"--input-dirs", inputs,
+				"--output-package", "github.com/example/memcached-operator/pkg/client/listers",
+			}, common...)))
+			Expect(cmds[2].Args).To(Equal(append([]string{informerGenBin,
+				"--input-dirs", inputs,
+				"--versioned-clientset-package", "github.com/example/memcached-operator/pkg/client/clientset/versioned",
+				"--listers-package", "github.com/example/memcached-operator/pkg/client/listers",
+				"--output-package", "github.com/example/memcached-operator/pkg/client/informers",
+			}, common...)))
+		})
+	})
+
+	Describe("checkMarkers", func() {
+		var wd, dir string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			dir, err = ioutil.TempDir("", "generate-client-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(dir)).To(Succeed())
+			apiDir := filepath.Join("api", "v1alpha1")
+			Expect(os.MkdirAll(apiDir, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(apiDir, "memcached_types.go"), []byte(typesWithMarkers), 0644)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("finds +genclient markers on type declarations", func() {
+			marked, err := getTypeMarkers(filepath.Join("api", "v1alpha1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(marked).To(Equal(map[string]bool{
+				"Memcached":     true,
+				"MemcachedList": false,
+				"Cluster":       true,
+				"Node":          false,
+			}))
+		})
+		It("succeeds if all resources have markers", func() {
+			g := Generator{Resources: []config.GVK{
+				{Group: "cache", Version: "v1alpha1", Kind: "Memcached"},
+				{Group: "cache", Version: "v1alpha1", Kind: "Cluster"},
+			}}
+			Expect(g.checkMarkers()).To(Succeed())
+		})
+		It("lists resources without markers or types", func() {
+			g := Generator{Resources: []config.GVK{
+				{Group: "cache", Version: "v1alpha1", Kind: "Node"},
+				{Group: "cache", Version: "v1alpha1", Kind: "Redis"},
+			}}
+			err := g.checkMarkers()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("api/v1alpha1: type Node has no +genclient marker"))
+			Expect(err.Error()).To(ContainSubstring("api/v1alpha1: type Redis not found"))
+		})
+		It("fails if a group version directory does not exist", func() {
+			g := Generator{Resources: []config.GVK{{Group: "cache", Version: "v1", Kind: "Memcached"}}}
+			Expect(g.checkMarkers()).NotTo(Succeed())
+		})
+	})
+
+	Describe("Generate", func() {
+		It("fails without a repo", func() {
+			g := singleGroup
+			g.Repo = ""
+			Expect(g.Generate()).To(MatchError(errNoRepo))
+		})
+		It("fails without resources", func() {
+			g := singleGroup
+			g.Resources = nil
+			Expect(g.Generate()).To(MatchError(errNoResources))
+		})
+		It("fails if the output directory is outside of the project", func() {
+			for _, outputDir := range []string{"/tmp/client", "../client"} {
+				g := singleGroup
+				g.OutputDir = outputDir
+				Expect(g.Generate()).To(MatchError(ContainSubstring("must be in the project")))
+			}
+		})
+	})
+})
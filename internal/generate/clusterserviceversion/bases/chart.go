@@ -0,0 +1,103 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// readChartMetadata returns the validated metadata in the Chart.yaml of the
+// Helm chart directory chartDir.
+func readChartMetadata(chartDir string) (*chart.Metadata, error) {
+	chartFile := filepath.Join(chartDir, chartutil.ChartfileName)
+	if _, err := os.Stat(chartFile); err != nil {
+		return nil, fmt.Errorf("chart metadata %s not found: %v", chartFile, err)
+	}
+	md, err := chartutil.LoadChartfile(chartFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading chart metadata %s: %v", chartFile, err)
+	}
+	if err := md.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid chart metadata %s: %v", chartFile, err)
+	}
+	return md, nil
+}
+
+// applyChartMetadata sets each unset UI metadata field in b from Helm chart
+// metadata md. Fields that are already set take precedence over md.
+func (b *ClusterServiceVersion) applyChartMetadata(md *chart.Metadata) {
+	if b.DisplayName == "" {
+		b.DisplayName = k8sutil.GetDisplayName(md.Name)
+	}
+	if b.Description == "" {
+		b.Description = md.Description
+	}
+	if len(b.Keywords) == 0 {
+		b.Keywords = md.Keywords
+	}
+	if len(b.Links) == 0 {
+		if md.Home != "" {
+			b.Links = append(b.Links, v1alpha1.AppLink{Name: b.DisplayName, URL: md.Home})
+		}
+		for _, source := range md.Sources {
+			b.Links = append(b.Links, v1alpha1.AppLink{Name: "Source Code", URL: source})
+		}
+	}
+	if len(b.Maintainers) == 0 {
+		for _, m := range md.Maintainers {
+			if m != nil && m.Name != "" {
+				b.Maintainers = append(b.Maintainers, v1alpha1.Maintainer{Name: m.Name, Email: m.Email})
+			}
+		}
+	}
+	// Charts have no notion of a provider, so use the first maintainer.
+	if b.Provider == (v1alpha1.AppLink{}) && len(md.Maintainers) != 0 && md.Maintainers[0] != nil {
+		b.Provider = v1alpha1.AppLink{Name: md.Maintainers[0].Name, URL: md.Maintainers[0].URL}
+	}
+	if len(b.Icon) == 0 && md.Icon != "" {
+		if icon, ok := parseDataURIIcon(md.Icon); ok {
+			b.Icon = []v1alpha1.Icon{icon}
+		} else {
+			log.Warnf("Chart %s icon %q is not a base64-encoded data URI, skipping", md.Name, md.Icon)
+		}
+	}
+}
+
+// parseDataURIIcon parses a "data:<mediatype>;base64,<data>" URI into a CSV icon.
+// CSV icons must be embedded, so icons referenced by URL cannot be used.
+func parseDataURIIcon(uri string) (v1alpha1.Icon, bool) {
+	const prefix, encoding = "data:", ";base64"
+	if !strings.HasPrefix(uri, prefix) {
+		return v1alpha1.Icon{}, false
+	}
+	split := strings.SplitN(strings.TrimPrefix(uri, prefix), ",", 2)
+	if len(split) != 2 || !strings.HasSuffix(split[0], encoding) || split[1] == "" {
+		return v1alpha1.Icon{}, false
+	}
+	return v1alpha1.Icon{
+		MediaType: strings.TrimSuffix(split[0], encoding),
+		Data:      split[1],
+	}, true
+}
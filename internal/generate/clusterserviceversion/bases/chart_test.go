@@ -0,0 +1,92 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bases
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const testChartYAML = `apiVersion: v2
+name: nginx
+version: 0.1.0
+description: A Helm chart for nginx.
+keywords: [nginx, web]
+home: https://nginx.example.com
+icon: data:image/png;base64,iVBORw0KGgo=
+maintainers:
+- name: Example Corp
+  email: corp@example.com
+  url: https://example.com
+`
+
+var _ = Describe("Chart metadata", func() {
+	var chartDir string
+
+	BeforeEach(func() {
+		var err error
+		chartDir, err = ioutil.TempDir("", "chart-metadata")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(chartDir)).To(Succeed())
+	})
+
+	It("populates a new base from Chart.yaml", func() {
+		Expect(ioutil.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte(testChartYAML), 0644)).To(Succeed())
+		b := ClusterServiceVersion{OperatorName: "nginx-operator", ChartDir: chartDir}
+		csv, err := b.GetBase()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(csv.Spec.DisplayName).To(Equal("Nginx"))
+		Expect(csv.Spec.Description).To(Equal("A Helm chart for nginx."))
+		Expect(csv.Spec.Keywords).To(Equal([]string{"nginx", "web"}))
+		Expect(csv.Spec.Links).To(Equal([]v1alpha1.AppLink{{Name: "Nginx", URL: "https://nginx.example.com"}}))
+		Expect(csv.Spec.Maintainers).To(Equal([]v1alpha1.Maintainer{{Name: "Example Corp", Email: "corp@example.com"}}))
+		Expect(csv.Spec.Provider).To(Equal(v1alpha1.AppLink{Name: "Example Corp", URL: "https://example.com"}))
+		Expect(csv.Spec.Icon).To(Equal([]v1alpha1.Icon{{MediaType: "image/png", Data: "iVBORw0KGgo="}}))
+	})
+
+	It("does not override explicitly set fields", func() {
+		b := ClusterServiceVersion{DisplayName: "NGINX Operator", Keywords: []string{"proxy"}}
+		b.applyChartMetadata(&chart.Metadata{Name: "nginx", Description: "A Helm chart for nginx.", Keywords: []string{"web"}})
+		Expect(b.DisplayName).To(Equal("NGINX Operator"))
+		Expect(b.Keywords).To(Equal([]string{"proxy"}))
+		Expect(b.Description).To(Equal("A Helm chart for nginx."))
+	})
+
+	It("skips icons that are not data URIs", func() {
+		b := ClusterServiceVersion{}
+		b.applyChartMetadata(&chart.Metadata{Name: "nginx", Icon: "https://nginx.example.com/icon.png"})
+		Expect(b.Icon).To(BeEmpty())
+	})
+
+	It("returns an error if Chart.yaml does not exist", func() {
+		_, err := ClusterServiceVersion{OperatorName: "nginx-operator", ChartDir: chartDir}.GetBase()
+		Expect(err).To(MatchError(ContainSubstring("chart metadata")))
+	})
+
+	It("returns an error if Chart.yaml is invalid", func() {
+		Expect(ioutil.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("description: foo\n"), 0644)).To(Succeed())
+		_, err := ClusterServiceVersion{OperatorName: "nginx-operator", ChartDir: chartDir}.GetBase()
+		Expect(err).To(MatchError(ContainSubstring("invalid chart metadata")))
+	})
+})
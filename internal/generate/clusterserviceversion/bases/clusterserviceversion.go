@@ -46,6 +46,9 @@ type ClusterServiceVersion struct {
 	GVKs []schema.GroupVersionKind
 	// Interactive turns on an interactive prompt.
 	Interactive bool
+	// ChartDir is a Helm chart directory whose Chart.yaml metadata populates
+	// unset UI metadata fields of a default base.
+	ChartDir string
 
 	// Fields for input to the base.
 	DisplayName  string
@@ -67,6 +70,13 @@ func (b ClusterServiceVersion) GetBase() (base *v1alpha1.ClusterServiceVersion,
 			return nil, fmt.Errorf("error reading existing ClusterServiceVersion base %s: %v", b.BasePath, err)
 		}
 	} else {
+		if b.ChartDir != "" {
+			md, err := readChartMetadata(b.ChartDir)
+			if err != nil {
+				return nil, err
+			}
+			b.applyChartMetadata(md)
+		}
 		b.setDefaults()
 		base = b.makeNewBase()
 	}
@@ -0,0 +1,211 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// Operator capability levels, in increasing order of maturity, that are valid
+// values of a CSV's "capabilities" annotation.
+const (
+	CapabilityBasicInstall     = "Basic Install"
+	CapabilitySeamlessUpgrades = "Seamless Upgrades"
+	CapabilityFullLifecycle    = "Full Lifecycle"
+	CapabilityDeepInsights     = "Deep Insights"
+	CapabilityAutoPilot        = "Auto Pilot"
+)
+
+// capabilitiesAnnotation is the CSV annotation key of an operator's capability level.
+const capabilitiesAnnotation = "capabilities"
+
+var serviceMonitorGK = schema.GroupKind{Group: "monitoring.coreos.com", Kind: "ServiceMonitor"}
+
+// CapabilityCriterion is a signal that an operator has the capabilities of Level.
+type CapabilityCriterion struct {
+	// Level is the capability level this criterion is required for.
+	Level string
+	// Description describes the signal inspected.
+	Description string
+	// Met is true if the signal was found.
+	Met bool
+}
+
+// CapabilityAssessment is the result of AssessCapabilities.
+type CapabilityAssessment struct {
+	// Level is the suggested capability level.
+	Level string
+	// Criteria are all criteria inspected, ordered by level.
+	Criteria []CapabilityCriterion
+}
+
+// AssessCapabilities suggests a capability level for csv by inspecting csv and
+// the manifests in c for signals of each level. Levels are cumulative, so the
+// suggested level is the highest level whose criteria, and those of every lower
+// level, are all met. Basic Install is always the lowest level suggested.
+//
+// The assessment is conservative: a signal only counts if it can be found in the
+// manifests, and Auto Pilot is never suggested since it cannot be inferred from them.
+func AssessCapabilities(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion) CapabilityAssessment {
+	if c == nil {
+		c = &collector.Manifests{}
+	}
+	strategy := csv.Spec.InstallStrategy.StrategySpec
+
+	criteria := []CapabilityCriterion{
+		{
+			Level:       CapabilityBasicInstall,
+			Description: "the install strategy deploys the operator",
+			Met:         len(strategy.DeploymentSpecs) != 0,
+		},
+		{
+			Level:       CapabilityBasicInstall,
+			Description: "every owned API reports status conditions in 'status.conditions'",
+			Met:         hasStatusConditions(c),
+		},
+		{
+			Level:       CapabilitySeamlessUpgrades,
+			Description: "the CSV upgrades a previous version by setting 'spec.replaces'",
+			Met:         csv.Spec.Replaces != "",
+		},
+		{
+			Level:       CapabilityFullLifecycle,
+			Description: "an owned API handles backup or restore",
+			Met:         hasBackupOrRestoreAPI(csv),
+		},
+		{
+			Level:       CapabilityDeepInsights,
+			Description: "a ServiceMonitor exposes metrics",
+			Met:         hasServiceMonitor(c),
+		},
+		{
+			Level:       CapabilityAutoPilot,
+			Description: "auto-scaling, auto-tuning, and abnormality detection cannot be inferred from manifests",
+			Met:         false,
+		},
+	}
+
+	assessment := CapabilityAssessment{Level: CapabilityBasicInstall, Criteria: criteria}
+	unmet := map[string]bool{}
+	for _, criterion := range criteria {
+		if !criterion.Met {
+			unmet[criterion.Level] = true
+		}
+	}
+	// Basic Install is skipped since it is the floor.
+	levels := []string{CapabilityBasicInstall, CapabilitySeamlessUpgrades, CapabilityFullLifecycle,
+		CapabilityDeepInsights, CapabilityAutoPilot}
+	for i := 1; i < len(levels) && !unmet[levels[i-1]] && !unmet[levels[i]]; i++ {
+		assessment.Level = levels[i]
+	}
+	return assessment
+}
+
+// hasStatusConditions returns true if every CRD in c has a storage version schema
+// with a 'status.conditions' field. CRDs without schemas do not report conditions.
+func hasStatusConditions(c *collector.Manifests) bool {
+	if len(c.V1CustomResourceDefinitions) == 0 && len(c.V1beta1CustomResourceDefinitions) == 0 {
+		return false
+	}
+	for _, crd := range c.V1CustomResourceDefinitions {
+		if !v1CRDHasStatusConditions(crd) {
+			return false
+		}
+	}
+	for _, crd := range c.V1beta1CustomResourceDefinitions {
+		if !v1beta1CRDHasStatusConditions(crd) {
+			return false
+		}
+	}
+	return true
+}
+
+func v1CRDHasStatusConditions(crd apiextv1.CustomResourceDefinition) bool {
+	for _, ver := range crd.Spec.Versions {
+		if ver.Storage && ver.Schema != nil && ver.Schema.OpenAPIV3Schema != nil {
+			_, hasConditions := ver.Schema.OpenAPIV3Schema.Properties["status"].Properties["conditions"]
+			return hasConditions
+		}
+	}
+	return false
+}
+
+func v1beta1CRDHasStatusConditions(crd apiextv1beta1.CustomResourceDefinition) bool {
+	// A top-level validation applies to all versions.
+	validation := crd.Spec.Validation
+	for _, ver := range crd.Spec.Versions {
+		if ver.Storage && ver.Schema != nil {
+			validation = ver.Schema
+		}
+	}
+	if validation == nil || validation.OpenAPIV3Schema == nil {
+		return false
+	}
+	_, hasConditions := validation.OpenAPIV3Schema.Properties["status"].Properties["conditions"]
+	return hasConditions
+}
+
+// hasBackupOrRestoreAPI returns true if csv owns an API whose kind names a backup or restore.
+func hasBackupOrRestoreAPI(csv *operatorsv1alpha1.ClusterServiceVersion) bool {
+	for _, desc := range csv.Spec.CustomResourceDefinitions.Owned {
+		kind := strings.ToLower(desc.Kind)
+		if strings.Contains(kind, "backup") || strings.Contains(kind, "restore") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasServiceMonitor returns true if c contains a ServiceMonitor.
+func hasServiceMonitor(c *collector.Manifests) bool {
+	for _, other := range c.Others {
+		if other.GroupVersionKind().GroupKind() == serviceMonitorGK {
+			return true
+		}
+	}
+	return false
+}
+
+// assessCapabilities logs the capability level assessment of csv, and sets the
+// suggested level in csv's annotations if set is true.
+func (g Generator) assessCapabilities(csv *operatorsv1alpha1.ClusterServiceVersion, set bool) {
+	assessment := AssessCapabilities(g.Collector, csv)
+	current := csv.GetAnnotations()[capabilitiesAnnotation]
+	log.Infof("Suggested capability level: %q (current: %q)", assessment.Level, current)
+	for _, criterion := range assessment.Criteria {
+		mark := " "
+		if criterion.Met {
+			mark = "x"
+		}
+		log.Infof("  [%s] %s: %s", mark, criterion.Level, criterion.Description)
+	}
+
+	if set && assessment.Level != current {
+		annotations := csv.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[capabilitiesAnnotation] = assessment.Level
+		csv.SetAnnotations(annotations)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+var _ = Describe("Assessing capabilities", func() {
+	var (
+		c   *collector.Manifests
+		csv *v1alpha1.ClusterServiceVersion
+	)
+
+	newV1CRD := func(status apiextv1.JSONSchemaProps) apiextv1.CustomResourceDefinition {
+		crd := apiextv1.CustomResourceDefinition{}
+		crd.Spec.Versions = []apiextv1.CustomResourceDefinitionVersion{
+			{Name: "v1alpha1", Served: true},
+			{Name: "v1", Served: true, Storage: true, Schema: &apiextv1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+					Type:       "object",
+					Properties: map[string]apiextv1.JSONSchemaProps{"status": status},
+				},
+			}},
+		}
+		return crd
+	}
+	conditionsStatus := apiextv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{"conditions": {Type: "array"}},
+	}
+	serviceMonitor := func() unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetAPIVersion("monitoring.coreos.com/v1")
+		u.SetKind("ServiceMonitor")
+		u.SetName("metrics")
+		return u
+	}
+
+	BeforeEach(func() {
+		c = &collector.Manifests{
+			V1CustomResourceDefinitions: []apiextv1.CustomResourceDefinition{newV1CRD(conditionsStatus)},
+		}
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{
+			{Name: "memcached-operator", Spec: appsv1.DeploymentSpec{}},
+		}
+	})
+
+	It("suggests Basic Install if no higher level signals are found", func() {
+		a := AssessCapabilities(c, csv)
+		Expect(a.Level).To(Equal(CapabilityBasicInstall))
+		Expect(a.Criteria).To(HaveLen(6))
+		Expect(a.Criteria[0].Met).To(BeTrue())
+		Expect(a.Criteria[1].Met).To(BeTrue())
+		Expect(a.Criteria[2].Met).To(BeFalse())
+	})
+	It("suggests Seamless Upgrades if the CSV replaces a previous version", func() {
+		csv.Spec.Replaces = "memcached-operator.v0.0.1"
+		Expect(AssessCapabilities(c, csv).Level).To(Equal(CapabilitySeamlessUpgrades))
+	})
+	It("suggests Full Lifecycle if an owned API handles backups", func() {
+		csv.Spec.Replaces = "memcached-operator.v0.0.1"
+		csv.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{{Kind: "MemcachedBackup"}}
+		Expect(AssessCapabilities(c, csv).Level).To(Equal(CapabilityFullLifecycle))
+	})
+	It("suggests Deep Insights but never Auto Pilot if all other signals are found", func() {
+		csv.Spec.Replaces = "memcached-operator.v0.0.1"
+		csv.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{{Kind: "MemcachedRestore"}}
+		c.Others = []unstructured.Unstructured{serviceMonitor()}
+		a := AssessCapabilities(c, csv)
+		Expect(a.Level).To(Equal(CapabilityDeepInsights))
+		Expect(a.Criteria[5].Level).To(Equal(CapabilityAutoPilot))
+		Expect(a.Criteria[5].Met).To(BeFalse())
+	})
+	It("does not skip a level whose signals are missing", func() {
+		c.Others = []unstructured.Unstructured{serviceMonitor()}
+		csv.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{{Kind: "MemcachedBackup"}}
+		Expect(AssessCapabilities(c, csv).Level).To(Equal(CapabilityBasicInstall))
+	})
+	It("suggests Basic Install if an owned API does not report status conditions", func() {
+		csv.Spec.Replaces = "memcached-operator.v0.0.1"
+		c.V1CustomResourceDefinitions = append(c.V1CustomResourceDefinitions, newV1CRD(apiextv1.JSONSchemaProps{Type: "object"}))
+		a := AssessCapabilities(c, csv)
+		Expect(a.Level).To(Equal(CapabilityBasicInstall))
+		Expect(a.Criteria[1].Met).To(BeFalse())
+	})
+	It("inspects top-level v1beta1 CRD validation for status conditions", func() {
+		csv.Spec.Replaces = "memcached-operator.v0.0.1"
+		crd := apiextv1beta1.CustomResourceDefinition{}
+		crd.Spec.Validation = &apiextv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextv1beta1.JSONSchemaProps{
+					"status": {Properties: map[string]apiextv1beta1.JSONSchemaProps{"conditions": {Type: "array"}}},
+				},
+			},
+		}
+		c.V1CustomResourceDefinitions = nil
+		c.V1beta1CustomResourceDefinitions = []apiextv1beta1.CustomResourceDefinition{crd}
+		Expect(AssessCapabilities(c, csv).Level).To(Equal(CapabilitySeamlessUpgrades))
+	})
+	It("does not find status conditions without CRDs", func() {
+		csv.Spec.Replaces = "memcached-operator.v0.0.1"
+		Expect(AssessCapabilities(nil, csv).Level).To(Equal(CapabilityBasicInstall))
+	})
+
+	Describe("Generator.assessCapabilities", func() {
+		It("sets the suggested level if set is true", func() {
+			csv.Spec.Replaces = "memcached-operator.v0.0.1"
+			csv.SetAnnotations(map[string]string{"capabilities": CapabilityAutoPilot})
+			Generator{Collector: c}.assessCapabilities(csv, true)
+			Expect(csv.GetAnnotations()).To(HaveKeyWithValue("capabilities", CapabilitySeamlessUpgrades))
+		})
+		It("does not change the CSV if set is false", func() {
+			Generator{Collector: c}.assessCapabilities(csv, false)
+			Expect(csv.GetAnnotations()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,100 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// noOwnedCRDsCleanupError is returned when cleanup is enabled for a CSV that owns no CRDs.
+var noOwnedCRDsCleanupError = errors.New("cleanup is enabled but the ClusterServiceVersion owns no " +
+	"CustomResourceDefinitions, so OLM has no custom resources to clean up")
+
+// isCleanupEnabled returns true if the CSV manifest at path sets spec.cleanup.enabled to true.
+// The CSV type does not have a cleanup field, so the manifest is read as unstructured data.
+func isCleanupEnabled(path string) (bool, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	u := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &u); err != nil {
+		return false, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	enabled, _, err := unstructured.NestedBool(u, "spec", "cleanup", "enabled")
+	if err != nil {
+		return false, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return enabled, nil
+}
+
+// validateCleanup returns an error if csv owns no CRDs, since OLM cleans up a CSV by deleting
+// the custom resources of its owned CRDs. A warning is logged for each owned CRD the operator
+// cannot update or patch, since it may need to remove finalizers from custom resources being deleted.
+func validateCleanup(csv *operatorsv1alpha1.ClusterServiceVersion) error {
+	owned := csv.Spec.CustomResourceDefinitions.Owned
+	if len(owned) == 0 {
+		return noOwnedCRDsCleanupError
+	}
+
+	strategy := csv.Spec.InstallStrategy.StrategySpec
+	var rules []rbacv1.PolicyRule
+	for _, perms := range [][]operatorsv1alpha1.StrategyDeploymentPermissions{strategy.Permissions, strategy.ClusterPermissions} {
+		for _, perm := range perms {
+			rules = append(rules, perm.Rules...)
+		}
+	}
+	for _, crd := range owned {
+		split := strings.SplitN(crd.Name, ".", 2)
+		if len(split) != 2 {
+			continue
+		}
+		if !canUpdate(rules, split[1], split[0]) {
+			log.Warnf("Cleanup is enabled but the operator cannot update or patch %s, so it may not be able to "+
+				"remove finalizers from custom resources OLM deletes on uninstall", crd.Name)
+		}
+	}
+	return nil
+}
+
+// canUpdate returns true if rules allow updating or patching resource in group.
+func canUpdate(rules []rbacv1.PolicyRule, group, resource string) bool {
+	for _, rule := range rules {
+		if containsRuleValue(rule.APIGroups, group) && containsRuleValue(rule.Resources, resource) &&
+			(containsRuleValue(rule.Verbs, "update") || containsRuleValue(rule.Verbs, "patch")) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRuleValue returns true if values contains value or the RBAC wildcard.
+func containsRuleValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == rbacv1.ResourceAll {
+			return true
+		}
+	}
+	return false
+}
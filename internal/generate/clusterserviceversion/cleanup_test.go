@@ -0,0 +1,61 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+var _ = Describe("Validating cleanup", func() {
+	var csv *v1alpha1.ClusterServiceVersion
+
+	BeforeEach(func() {
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{{Name: "memcacheds.cache.example.com"}}
+	})
+
+	It("returns an error if the CSV owns no CRDs", func() {
+		csv.Spec.CustomResourceDefinitions.Owned = nil
+		Expect(validateCleanup(csv)).To(MatchError(noOwnedCRDsCleanupError))
+	})
+	It("succeeds if the CSV owns CRDs", func() {
+		Expect(validateCleanup(csv)).To(Succeed())
+	})
+
+	Describe("canUpdate", func() {
+		rule := func(groups, resources, verbs []string) rbacv1.PolicyRule {
+			return rbacv1.PolicyRule{APIGroups: groups, Resources: resources, Verbs: verbs}
+		}
+
+		It("returns true if a rule allows updating or patching the resource", func() {
+			Expect(canUpdate([]rbacv1.PolicyRule{
+				rule([]string{"cache.example.com"}, []string{"memcacheds"}, []string{"get", "update"}),
+			}, "cache.example.com", "memcacheds")).To(BeTrue())
+			Expect(canUpdate([]rbacv1.PolicyRule{
+				rule([]string{"*"}, []string{"*"}, []string{"patch"}),
+			}, "cache.example.com", "memcacheds")).To(BeTrue())
+		})
+		It("returns false if no rule allows updating or patching the resource", func() {
+			Expect(canUpdate([]rbacv1.PolicyRule{
+				rule([]string{"cache.example.com"}, []string{"memcacheds"}, []string{"get", "list", "delete"}),
+				rule([]string{"cache.example.com"}, []string{"memcacheds/status"}, []string{"update"}),
+				rule([]string{"apps"}, []string{"memcacheds"}, []string{"update"}),
+			}, "cache.example.com", "memcacheds")).To(BeFalse())
+		})
+	})
+})
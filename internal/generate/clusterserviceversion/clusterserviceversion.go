@@ -31,6 +31,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion/bases"
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
 	genutil "github.com/operator-framework/operator-sdk/internal/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
 
@@ -57,8 +58,31 @@ type Generator struct {
 	Version string
 	// FromVersion is the version of a previous CSV to upgrade from.
 	FromVersion string
+	// CSVNameTemplate is a text/template of the CSV's name, with .Package and
+	// .Version fields. The same template names the CSV in "replaces", and
+	// CSVs in "skips" named in the default format.
+	// Defaults to "{{.Package}}.v{{.Version}}".
+	CSVNameTemplate string
+	// ChartDir is the Helm chart directory whose metadata populates a new base
+	// for Helm operators.
+	ChartDir string
 	// Collector holds all manifests relevant to the Generator.
 	Collector *collector.Manifests
+	// AssessCapabilities logs a suggested capability level for the CSV,
+	// assessed from Collector. See AssessCapabilities.
+	AssessCapabilities bool
+	// SetCapabilities sets the CSV's "capabilities" annotation to the
+	// suggested capability level. Implies AssessCapabilities.
+	SetCapabilities bool
+	// Properties are added to the CSV's "olm.properties" annotation.
+	Properties []registry.Property
+	// EnableCleanup sets the CSV's spec.cleanup.enabled to true, so OLM deletes
+	// the CSV's owned custom resources on uninstall. Cleanup stays enabled in an
+	// updated bundled CSV that already enables it.
+	EnableCleanup bool
+	// InstallModeVariant, if set, scopes the CSV's install modes and RBAC to the
+	// variant, and names the CSV with the variant's package name.
+	InstallModeVariant InstallModeVariant
 
 	// Project configuration.
 	config *config.Config
@@ -70,6 +94,9 @@ type Generator struct {
 	// CSV. Used to bring over data from an existing CSV that is not captured
 	// in a base. Not set if a non-file or base writer is returned by getWriter.
 	bundledPath string
+	// Path of a kustomize-style base CSV, which may not exist. Used to bring over
+	// data the CSV type does not capture. Only set by WithBase.
+	basePath string
 }
 
 // Type of Generator.getBase.
@@ -81,6 +108,7 @@ type Option func(*Generator) error
 // WithBase sets a Generator's base CSV to a kustomize-style base.
 func WithBase(inputDir, apisDir string, ilvl projutil.InteractiveLevel) Option {
 	return func(g *Generator) error {
+		g.basePath = filepath.Join(inputDir, "bases", makeCSVFileName(g.OperatorName))
 		g.getBase = g.makeKustomizeBaseGetter(inputDir, apisDir, ilvl)
 		return nil
 	}
@@ -164,11 +192,30 @@ func (g *Generator) Generate(cfg *config.Config, opts ...Option) (err error) {
 	// Add sdk labels to csv
 	g.setSDKAnnotations(csv)
 
+	// Cleanup is written separately since the CSV type has no cleanup field.
+	enableCleanup := g.EnableCleanup
+	if !enableCleanup && genutil.IsExist(g.bundledPath) {
+		if enableCleanup, err = isCleanupEnabled(g.bundledPath); err != nil {
+			return fmt.Errorf("error reading existing ClusterServiceVersion: %v", err)
+		}
+	}
+	if enableCleanup {
+		if err := validateCleanup(csv); err != nil {
+			return err
+		}
+	}
+
+	// Skips are also written separately since the CSV type has no skips field.
+	skips, err := g.getSkips()
+	if err != nil {
+		return err
+	}
+
 	w, err := g.getWriter()
 	if err != nil {
 		return err
 	}
-	return genutil.WriteObject(w, csv)
+	return writeCSV(w, csv, enableCleanup, skips)
 }
 
 // setSDKAnnotations adds SDK metric labels to the base if they do not exist.
@@ -200,9 +247,23 @@ func (g *Generator) generate() (*operatorsv1alpha1.ClusterServiceVersion, error)
 	}
 
 	if g.Collector != nil {
-		if err := ApplyTo(g.Collector, base); err != nil {
+		if err := applyTo(g.Collector, base, g.InstallModeVariant); err != nil {
+			return nil, err
+		}
+		if g.AssessCapabilities || g.SetCapabilities {
+			g.assessCapabilities(base, g.SetCapabilities)
+		}
+	}
+
+	if len(g.Properties) != 0 {
+		annotations := base.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if err := registry.AddPropertiesAnnotation(annotations, g.Properties); err != nil {
 			return nil, err
 		}
+		base.SetAnnotations(annotations)
 	}
 
 	return base, nil
@@ -220,12 +281,21 @@ func (g Generator) makeKustomizeBaseGetter(inputDir, apisDir string, ilvl projut
 		basePath = ""
 	}
 
-	return g.makeBaseGetter(basePath, apisDir, requiresInteraction(basePath, ilvl))
+	// Chart metadata supplants interactive prompts for a new Helm operator base,
+	// unless prompts are explicitly requested.
+	interactive := requiresInteraction(basePath, ilvl)
+	var chartDir string
+	if basePath == "" && g.OperatorType == projutil.OperatorTypeHelm && g.ChartDir != "" {
+		chartDir = g.ChartDir
+		interactive = ilvl == projutil.InteractiveOnAll
+	}
+
+	return g.makeBaseGetter(basePath, apisDir, chartDir, interactive)
 }
 
 // makeBaseGetter returns a function that gets a base from inputDir.
-// apisDir is used by getBaseFunc to populate base fields.
-func (g Generator) makeBaseGetter(basePath, apisDir string, interactive bool) getBaseFunc {
+// apisDir and chartDir are used by getBaseFunc to populate base fields.
+func (g Generator) makeBaseGetter(basePath, apisDir, chartDir string, interactive bool) getBaseFunc {
 	gvks := make([]schema.GroupVersionKind, len(g.config.Resources))
 	for i, gvk := range g.config.Resources {
 		gvks[i].Group = fmt.Sprintf("%s.%s", gvk.Group, g.config.Domain)
@@ -241,6 +311,7 @@ func (g Generator) makeBaseGetter(basePath, apisDir string, interactive bool) ge
 			APIsDir:      apisDir,
 			GVKs:         gvks,
 			Interactive:  interactive,
+			ChartDir:     chartDir,
 		}
 		return b.GetBase()
 	}
@@ -258,8 +329,6 @@ func requiresInteraction(basePath string, ilvl projutil.InteractiveLevel) bool {
 func (g Generator) updateVersions(csv *operatorsv1alpha1.ClusterServiceVersion) (err error) {
 
 	oldVer, newVer := csv.Spec.Version.String(), g.Version
-	newName := genutil.MakeCSVName(g.OperatorName, newVer)
-	oldName := csv.GetName()
 
 	// A bundled CSV may not have a base containing the previous version to use,
 	// so use the current bundled CSV for version information.
@@ -269,7 +338,6 @@ func (g Generator) updateVersions(csv *operatorsv1alpha1.ClusterServiceVersion)
 			return fmt.Errorf("error reading existing ClusterServiceVersion: %v", err)
 		}
 		oldVer = existing.Spec.Version.String()
-		oldName = existing.GetName()
 	}
 
 	// If the new version is empty, either because a CSV is only being updated or
@@ -278,13 +346,37 @@ func (g Generator) updateVersions(csv *operatorsv1alpha1.ClusterServiceVersion)
 		return nil
 	}
 
+	newName, err := genutil.MakeCSVNameFromTemplate(g.CSVNameTemplate, g.packageName(), newVer)
+	if err != nil {
+		return err
+	}
+
 	// Set replaces by default.
 	// TODO: consider all possible CSV versioning schemes supported  by OLM.
 	if oldVer != "0.0.0" && newVer != oldVer {
-		csv.Spec.Replaces = oldName
+		csv.Spec.Replaces, err = genutil.MakeCSVNameFromTemplate(g.CSVNameTemplate, g.packageName(), oldVer)
+		if err != nil {
+			return err
+		}
 	}
 
 	csv.SetName(newName)
 	csv.Spec.Version.Version, err = semver.Parse(newVer)
 	return err
 }
+
+// packageName returns the name of the CSV's package, which differs from the
+// operator's name for an install mode variant.
+func (g Generator) packageName() string {
+	if g.InstallModeVariant != "" {
+		return g.InstallModeVariant.PackageName(g.OperatorName)
+	}
+	return g.OperatorName
+}
+
+// ValidateNameTemplate returns an error if tmpl, a CSVNameTemplate, does not
+// render a valid CSV name for operatorName at version.
+func ValidateNameTemplate(tmpl, operatorName, version string) error {
+	_, err := genutil.MakeCSVNameFromTemplate(tmpl, operatorName, version)
+	return err
+}
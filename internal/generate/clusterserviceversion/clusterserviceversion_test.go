@@ -32,6 +32,8 @@ import (
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/yaml"
@@ -39,6 +41,7 @@ import (
 	metricsannotations "github.com/operator-framework/operator-sdk/internal/annotations/metrics"
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
 	genutil "github.com/operator-framework/operator-sdk/internal/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/registry"
 	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
@@ -200,6 +203,51 @@ var _ = Describe("Generating a ClusterServiceVersion", func() {
 				Expect(outputFile).To(BeAnExistingFile())
 				Expect(readFileHelper(outputFile)).To(MatchYAML(newCSVStr))
 			})
+			It("should enable cleanup in a bundle file and keep it enabled on regeneration", func() {
+				g = Generator{
+					OperatorName:  operatorName,
+					OperatorType:  operatorType,
+					Version:       version,
+					Collector:     col,
+					EnableCleanup: true,
+				}
+				opts := []Option{
+					WithBase(csvBasesDir, goAPIsDir, projutil.InteractiveHardOff),
+					WithBundleWriter(tmp),
+				}
+				Expect(g.Generate(cfg, opts...)).ToNot(HaveOccurred())
+				outputFile := filepath.Join(tmp, bundle.ManifestsDir, makeCSVFileName(operatorName))
+				Expect(isCleanupEnabled(outputFile)).To(BeTrue())
+
+				g.EnableCleanup = false
+				Expect(g.Generate(cfg, opts...)).ToNot(HaveOccurred())
+				Expect(isCleanupEnabled(outputFile)).To(BeTrue())
+				Expect(readFileHelper(outputFile)).To(ContainSubstring("cleanup:\n    enabled: true\n"))
+			})
+			It("should write skips renamed by a CSV name template and keep them on regeneration", func() {
+				basesDir := filepath.Join(tmp, "config")
+				writeBaseWithSkipsHelper(basesDir, operatorName, "memcached-operator.v0.0.0", "other-operator.v0.0.0", "memcached-operator.vlatest")
+				g = Generator{
+					OperatorName:    operatorName,
+					OperatorType:    operatorType,
+					Version:         version,
+					CSVNameTemplate: "{{.Package}}-custom.v{{.Version}}",
+					Collector:       col,
+				}
+				opts := []Option{
+					WithBase(basesDir, goAPIsDir, projutil.InteractiveHardOff),
+					WithBundleWriter(tmp),
+				}
+				Expect(g.Generate(cfg, opts...)).ToNot(HaveOccurred())
+				outputFile := filepath.Join(tmp, bundle.ManifestsDir, makeCSVFileName(operatorName))
+				expectedSkips := []string{"memcached-operator-custom.v0.0.0", "other-operator.v0.0.0", "memcached-operator.vlatest"}
+				Expect(readSkips(outputFile)).To(Equal(expectedSkips))
+
+				// Skips are read from the bundled CSV if the base has none.
+				writeBaseWithSkipsHelper(basesDir, operatorName)
+				Expect(g.Generate(cfg, opts...)).ToNot(HaveOccurred())
+				Expect(readSkips(outputFile)).To(Equal(expectedSkips))
+			})
 		})
 
 		Context("with incorrect Options", func() {
@@ -270,6 +318,23 @@ var _ = Describe("Generating a ClusterServiceVersion", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(csv).To(Equal(newCSV))
 			})
+			It("should add properties to the olm.properties annotation", func() {
+				g = Generator{
+					OperatorName: operatorName,
+					OperatorType: operatorType,
+					Version:      version,
+					Collector:    col,
+					Properties: []registry.Property{
+						{Type: registry.MaxOpenShiftVersionProperty, Value: json.RawMessage(`"4.8"`)},
+					},
+					config:  cfg,
+					getBase: makeBaseGetter(baseCSVUIMeta),
+				}
+				csv, err := g.generate()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(csv.GetAnnotations()).To(HaveKeyWithValue(registry.PropertiesAnnotation,
+					`[{"type":"olm.maxOpenShiftVersion","value":"4.8"}]`))
+			})
 		})
 
 		Context("to update an existing ClusterServiceVersion", func() {
@@ -293,6 +358,66 @@ var _ = Describe("Generating a ClusterServiceVersion", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(csv).To(Equal(updatedCSV))
 			})
+			It("should set the manager's resource requirements on the embedded deployment", func() {
+				g = Generator{
+					OperatorName: operatorName,
+					OperatorType: operatorType,
+					Version:      version,
+					Collector:    &collector.Manifests{},
+					config:       cfg,
+					getBase:      makeBaseGetter(newCSV),
+				}
+				Expect(g.Collector.UpdateFromDirs(goConfigDir, goCRDsDir)).ToNot(HaveOccurred())
+				Expect(len(g.Collector.Deployments)).To(BeNumerically(">=", 1))
+				resources := corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("64Mi"),
+					},
+				}
+				g.Collector.Deployments[0].Spec.Template.Spec.Containers[0].Resources = resources
+
+				csv, err := g.generate()
+				Expect(err).ToNot(HaveOccurred())
+				depSpecs := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+				Expect(depSpecs).To(HaveLen(1))
+				Expect(depSpecs[0].Spec.Template.Spec.Containers[0].Resources).To(Equal(resources))
+			})
+			It("should keep extra manager pod labels on the embedded deployment", func() {
+				g = Generator{
+					OperatorName: operatorName,
+					OperatorType: operatorType,
+					Version:      version,
+					Collector:    &collector.Manifests{},
+					config:       cfg,
+					getBase:      makeBaseGetter(newCSV),
+				}
+				Expect(g.Collector.UpdateFromDirs(goConfigDir, goCRDsDir)).ToNot(HaveOccurred())
+				Expect(len(g.Collector.Deployments)).To(BeNumerically(">=", 1))
+				template := &g.Collector.Deployments[0].Spec.Template
+				selectorLabels := map[string]string{}
+				for k, v := range template.GetLabels() {
+					selectorLabels[k] = v
+				}
+				template.Labels["team"] = "storage"
+				template.Labels["cost-center"] = "1234"
+
+				csv, err := g.generate()
+				Expect(err).ToNot(HaveOccurred())
+				depSpecs := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+				Expect(depSpecs).To(HaveLen(1))
+				labels := depSpecs[0].Spec.Template.GetLabels()
+				Expect(labels).To(HaveKeyWithValue("team", "storage"))
+				Expect(labels).To(HaveKeyWithValue("cost-center", "1234"))
+				for k, v := range selectorLabels {
+					Expect(labels).To(HaveKeyWithValue(k, v))
+				}
+				Expect(depSpecs[0].Spec.Selector.MatchLabels).To(Equal(selectorLabels))
+			})
 		})
 
 		Context("to upgrade an existing ClusterServiceVersion", func() {
@@ -312,6 +437,35 @@ var _ = Describe("Generating a ClusterServiceVersion", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(csv).To(Equal(upgradeCSV(newCSV, g.OperatorName, g.Version)))
 			})
+			It("should return an upgraded object named by a CSV name template", func() {
+				g = Generator{
+					OperatorName:    operatorName,
+					OperatorType:    operatorType,
+					Version:         "0.0.2",
+					CSVNameTemplate: "{{.Package}}-custom.v{{.Version}}",
+					Collector:       col,
+					config:          cfg,
+					getBase:         makeBaseGetter(newCSV),
+					bundledPath:     filepath.Join(csvNewLayoutBundleDir, "memcached-operator.clusterserviceversion.yaml"),
+				}
+				csv, err := g.generate()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(csv.GetName()).To(Equal("memcached-operator-custom.v0.0.2"))
+				Expect(csv.Spec.Replaces).To(Equal("memcached-operator-custom.v0.0.1"))
+			})
+			It("should return an error if a CSV name template renders an invalid name", func() {
+				g = Generator{
+					OperatorName:    operatorName,
+					OperatorType:    operatorType,
+					Version:         "0.0.2",
+					CSVNameTemplate: "{{.Package}}_{{.Version}}",
+					Collector:       col,
+					config:          cfg,
+					getBase:         makeBaseGetter(newCSV),
+				}
+				_, err := g.generate()
+				Expect(err).To(MatchError(ContainSubstring("is not a valid resource name")))
+			})
 		})
 
 		Context("generate ClusterServiceVersion", func() {
@@ -413,6 +567,22 @@ func initTestCSVsHelper() {
 	ExpectWithOffset(1, err).ToNot(HaveOccurred())
 }
 
+// writeBaseWithSkipsHelper writes operatorName's test base CSV with skips to <dir>/bases.
+func writeBaseWithSkipsHelper(dir, operatorName string, skips ...string) {
+	fileName := makeCSVFileName(operatorName)
+	b, err := ioutil.ReadFile(filepath.Join(csvBasesDir, fileName))
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	u := map[string]interface{}{}
+	ExpectWithOffset(1, yaml.Unmarshal(b, &u)).To(Succeed())
+	if len(skips) != 0 {
+		ExpectWithOffset(1, unstructured.SetNestedStringSlice(u, skips, "spec", "skips")).To(Succeed())
+	}
+	b, err = yaml.Marshal(u)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	ExpectWithOffset(1, os.MkdirAll(filepath.Join(dir, "bases"), 0755)).To(Succeed())
+	ExpectWithOffset(1, ioutil.WriteFile(filepath.Join(dir, "bases", fileName), b, 0644)).To(Succeed())
+}
+
 func readFileHelper(path string) string {
 	b, err := ioutil.ReadFile(path)
 	ExpectWithOffset(1, err).ToNot(HaveOccurred())
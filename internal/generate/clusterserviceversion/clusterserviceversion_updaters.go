@@ -37,10 +37,20 @@ import (
 // ApplyTo applies relevant manifests in c to csv, sorts the applied updates,
 // and validates the result.
 func ApplyTo(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion) error {
+	return applyTo(c, csv, "")
+}
+
+// applyTo is like ApplyTo, but also scopes csv to variant if set, before validating it.
+func applyTo(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion, variant InstallModeVariant) error {
 	// Apply manifests to the CSV object.
 	if err := apply(c, csv); err != nil {
 		return fmt.Errorf("error updating ClusterServiceVersion: %v", err)
 	}
+	if variant != "" {
+		if err := applyInstallModeVariant(c, csv, variant); err != nil {
+			return fmt.Errorf("error updating ClusterServiceVersion: %v", err)
+		}
+	}
 
 	// Set fields required by namespaced operators. This is a no-op for cluster-
 	// scoped operators.
@@ -80,7 +90,9 @@ func getCSVInstallStrategy(csv *operatorsv1alpha1.ClusterServiceVersion) operato
 	return csv.Spec.InstallStrategy
 }
 
-// applyRoles updates strategy's permissions with the Roles in the collector.
+// applyRoles updates strategy's permissions with the Roles in the collector,
+// and the ClusterRoles that are bound by a RoleBinding, since a RoleBinding
+// only grants a ClusterRole's rules in the binding's namespace.
 func applyRoles(c *collector.Manifests, strategy *operatorsv1alpha1.StrategyDetailsDeployment) {
 	perms := []operatorsv1alpha1.StrategyDeploymentPermissions{}
 	for _, role := range c.Roles {
@@ -89,22 +101,55 @@ func applyRoles(c *collector.Manifests, strategy *operatorsv1alpha1.StrategyDeta
 			Rules:              role.Rules,
 		})
 	}
+	for _, role := range c.ClusterRoles {
+		if isBoundByRoleBinding(c, role.GetName()) {
+			perms = append(perms, operatorsv1alpha1.StrategyDeploymentPermissions{
+				ServiceAccountName: role.GetName(),
+				Rules:              role.Rules,
+			})
+		}
+	}
 	strategy.Permissions = perms
 }
 
 // applyClusterRoles updates strategy's cluserPermissions with the ClusterRoles
-// in the collector.
+// in the collector that are bound by a ClusterRoleBinding, or are not bound
+// by a RoleBinding.
 func applyClusterRoles(c *collector.Manifests, strategy *operatorsv1alpha1.StrategyDetailsDeployment) {
 	perms := []operatorsv1alpha1.StrategyDeploymentPermissions{}
 	for _, role := range c.ClusterRoles {
-		perms = append(perms, operatorsv1alpha1.StrategyDeploymentPermissions{
-			ServiceAccountName: role.GetName(),
-			Rules:              role.Rules,
-		})
+		if isBoundByClusterRoleBinding(c, role.GetName()) || !isBoundByRoleBinding(c, role.GetName()) {
+			perms = append(perms, operatorsv1alpha1.StrategyDeploymentPermissions{
+				ServiceAccountName: role.GetName(),
+				Rules:              role.Rules,
+			})
+		}
 	}
 	strategy.ClusterPermissions = perms
 }
 
+// isBoundByRoleBinding returns true if a RoleBinding in the collector
+// references the ClusterRole named name.
+func isBoundByRoleBinding(c *collector.Manifests, name string) bool {
+	for _, binding := range c.RoleBindings {
+		if binding.RoleRef.Kind == "ClusterRole" && binding.RoleRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isBoundByClusterRoleBinding returns true if a ClusterRoleBinding in the
+// collector references the ClusterRole named name.
+func isBoundByClusterRoleBinding(c *collector.Manifests, name string) bool {
+	for _, binding := range c.ClusterRoleBindings {
+		if binding.RoleRef.Kind == "ClusterRole" && binding.RoleRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // applyDeployments updates strategy's deployments with the Deployments
 // in the collector.
 func applyDeployments(c *collector.Manifests, strategy *operatorsv1alpha1.StrategyDetailsDeployment) {
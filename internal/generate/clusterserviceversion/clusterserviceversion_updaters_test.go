@@ -0,0 +1,131 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+var _ = Describe("Applying RBAC to a ClusterServiceVersion", func() {
+	var (
+		c        *collector.Manifests
+		strategy *v1alpha1.StrategyDetailsDeployment
+
+		roleRules        = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}}
+		clusterRoleRules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list"}}}
+	)
+
+	newClusterRole := func(name string) rbacv1.ClusterRole {
+		return rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}, Rules: clusterRoleRules}
+	}
+	newRoleRef := func(kind, name string) rbacv1.RoleRef {
+		return rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: kind, Name: name}
+	}
+	perm := func(name string, rules []rbacv1.PolicyRule) v1alpha1.StrategyDeploymentPermissions {
+		return v1alpha1.StrategyDeploymentPermissions{ServiceAccountName: name, Rules: rules}
+	}
+	apply := func() {
+		applyRoles(c, strategy)
+		applyClusterRoles(c, strategy)
+	}
+
+	BeforeEach(func() {
+		c = &collector.Manifests{
+			Roles: []rbacv1.Role{{ObjectMeta: metav1.ObjectMeta{Name: "role"}, Rules: roleRules}},
+			RoleBindings: []rbacv1.RoleBinding{
+				{ObjectMeta: metav1.ObjectMeta{Name: "role"}, RoleRef: newRoleRef("Role", "role")},
+			},
+		}
+		strategy = &v1alpha1.StrategyDetailsDeployment{}
+	})
+
+	It("adds Roles to permissions and unbound ClusterRoles to clusterPermissions", func() {
+		c.ClusterRoles = []rbacv1.ClusterRole{newClusterRole("cluster-role")}
+		apply()
+		Expect(strategy.Permissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("role", roleRules)}))
+		Expect(strategy.ClusterPermissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("cluster-role", clusterRoleRules)}))
+	})
+	It("adds ClusterRoles bound by a ClusterRoleBinding to clusterPermissions", func() {
+		c.ClusterRoles = []rbacv1.ClusterRole{newClusterRole("cluster-role")}
+		c.ClusterRoleBindings = []rbacv1.ClusterRoleBinding{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cluster-role"}, RoleRef: newRoleRef("ClusterRole", "cluster-role")},
+		}
+		apply()
+		Expect(strategy.Permissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("role", roleRules)}))
+		Expect(strategy.ClusterPermissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("cluster-role", clusterRoleRules)}))
+	})
+	It("adds ClusterRoles bound by a RoleBinding to permissions", func() {
+		c.ClusterRoles = []rbacv1.ClusterRole{newClusterRole("namespaced-cluster-role"), newClusterRole("cluster-role")}
+		c.RoleBindings = append(c.RoleBindings, rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "namespaced-cluster-role"},
+			RoleRef:    newRoleRef("ClusterRole", "namespaced-cluster-role"),
+		})
+		apply()
+		Expect(strategy.Permissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{
+			perm("role", roleRules),
+			perm("namespaced-cluster-role", clusterRoleRules),
+		}))
+		Expect(strategy.ClusterPermissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("cluster-role", clusterRoleRules)}))
+	})
+	It("adds ClusterRoles bound by both binding types to both permissions and clusterPermissions", func() {
+		c.ClusterRoles = []rbacv1.ClusterRole{newClusterRole("cluster-role")}
+		c.RoleBindings = append(c.RoleBindings, rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-role"},
+			RoleRef:    newRoleRef("ClusterRole", "cluster-role"),
+		})
+		c.ClusterRoleBindings = []rbacv1.ClusterRoleBinding{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cluster-role"}, RoleRef: newRoleRef("ClusterRole", "cluster-role")},
+		}
+		apply()
+		Expect(strategy.Permissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{
+			perm("role", roleRules),
+			perm("cluster-role", clusterRoleRules),
+		}))
+		Expect(strategy.ClusterPermissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("cluster-role", clusterRoleRules)}))
+	})
+	It("does not treat a RoleBinding to a Role as binding a ClusterRole of the same name", func() {
+		c.ClusterRoles = []rbacv1.ClusterRole{newClusterRole("role")}
+		apply()
+		Expect(strategy.Permissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("role", roleRules)}))
+		Expect(strategy.ClusterPermissions).To(Equal([]v1alpha1.StrategyDeploymentPermissions{perm("role", clusterRoleRules)}))
+	})
+})
+
+var _ = Describe("Applying Deployments to a ClusterServiceVersion", func() {
+	It("embeds each Deployment's spec, including init containers, replicas, and termination grace period", func() {
+		replicas, gracePeriod := int32(3), int64(60)
+		dep := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "controller-manager"}}
+		dep.Spec.Replicas = &replicas
+		dep.Spec.Template.Spec.TerminationGracePeriodSeconds = &gracePeriod
+		dep.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "migrate", Image: "quay.io/example/migrate:v0.1.0"}}
+		dep.Spec.Template.Spec.Containers = []corev1.Container{{Name: "manager", Image: "quay.io/example/operator:v0.1.0"}}
+		c := &collector.Manifests{Deployments: []appsv1.Deployment{dep}}
+		strategy := &v1alpha1.StrategyDetailsDeployment{}
+		applyDeployments(c, strategy)
+		Expect(strategy.DeploymentSpecs).To(Equal([]v1alpha1.StrategyDeploymentSpec{{Name: "controller-manager", Spec: dep.Spec}}))
+		Expect(strategy.DeploymentSpecs[0].Spec.Template.Spec.InitContainers).To(HaveLen(1))
+		Expect(*strategy.DeploymentSpecs[0].Spec.Replicas).To(Equal(replicas))
+		Expect(*strategy.DeploymentSpecs[0].Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(gracePeriod))
+	})
+})
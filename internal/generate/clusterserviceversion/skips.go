@@ -0,0 +1,116 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/blang/semver"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	genutil "github.com/operator-framework/operator-sdk/internal/generate/internal"
+)
+
+// getSkips returns the CSV names to write in the generated CSV's spec.skips, read from
+// its base or, if the base skips none, the existing bundled CSV. Names in the default
+// "<package>.v<version>" format are renamed with g.CSVNameTemplate so skips stay
+// consistent with the CSV's name and replaces.
+func (g Generator) getSkips() ([]string, error) {
+	var skips []string
+	for _, path := range []string{g.basePath, g.bundledPath} {
+		if path == "" || genutil.IsNotExist(path) {
+			continue
+		}
+		var err error
+		if skips, err = readSkips(path); err != nil {
+			return nil, fmt.Errorf("error reading ClusterServiceVersion skips: %v", err)
+		}
+		if len(skips) != 0 {
+			break
+		}
+	}
+
+	renamed := make([]string, len(skips))
+	for i, skip := range skips {
+		name, err := g.renameSkip(skip)
+		if err != nil {
+			return nil, err
+		}
+		renamed[i] = name
+	}
+	return renamed, nil
+}
+
+// renameSkip renames skip with g.CSVNameTemplate if it is named in the default
+// "<package>.v<version>" format. Otherwise skip is returned unchanged.
+func (g Generator) renameSkip(skip string) (string, error) {
+	prefix := g.packageName() + ".v"
+	if !strings.HasPrefix(skip, prefix) {
+		return skip, nil
+	}
+	version := strings.TrimPrefix(skip, prefix)
+	if _, err := semver.Parse(version); err != nil {
+		return skip, nil
+	}
+	return genutil.MakeCSVNameFromTemplate(g.CSVNameTemplate, g.packageName(), version)
+}
+
+// readSkips returns the CSV names in spec.skips of the CSV manifest at path.
+// The CSV type does not have a skips field, so the manifest is read as unstructured data.
+func readSkips(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	u := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &u); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	skips, _, err := unstructured.NestedStringSlice(u, "spec", "skips")
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return skips, nil
+}
+
+// writeCSV writes csv to w. If enableCleanup is true or skips is not empty, csv is
+// written as unstructured data with spec.cleanup.enabled and spec.skips set,
+// since the CSV type has neither field.
+func writeCSV(w io.Writer, csv *operatorsv1alpha1.ClusterServiceVersion, enableCleanup bool, skips []string) error {
+	if !enableCleanup && len(skips) == 0 {
+		return genutil.WriteObject(w, csv)
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(csv)
+	if err != nil {
+		return err
+	}
+	if enableCleanup {
+		if err := unstructured.SetNestedField(u, true, "spec", "cleanup", "enabled"); err != nil {
+			return err
+		}
+	}
+	if len(skips) != 0 {
+		if err := unstructured.SetNestedStringSlice(u, skips, "spec", "skips"); err != nil {
+			return err
+		}
+	}
+	return genutil.WriteObject(w, &unstructured.Unstructured{Object: u})
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"fmt"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// InstallModeVariant is a variant of an operator's bundle whose install modes and
+// RBAC are scoped to either namespaced or cluster-wide installs. Each variant is
+// packaged separately so both can be shipped from one project.
+type InstallModeVariant string
+
+const (
+	// InstallModeVariantNamespaced supports OwnNamespace and SingleNamespace installs.
+	// The operator's ClusterRole rules are granted as namespaced permissions, except
+	// for rules that only apply to cluster-scoped resources.
+	InstallModeVariantNamespaced InstallModeVariant = "namespaced"
+	// InstallModeVariantCluster supports AllNamespaces installs. The operator's Role
+	// rules are granted as cluster-wide permissions.
+	InstallModeVariantCluster InstallModeVariant = "cluster"
+)
+
+// InstallModeVariants are all install mode variants, in the order they are generated.
+var InstallModeVariants = []InstallModeVariant{InstallModeVariantNamespaced, InstallModeVariantCluster}
+
+// PackageName returns the name of v's package for operatorName, ex. app-operator-namespaced.
+func (v InstallModeVariant) PackageName(operatorName string) string {
+	return operatorName + "-" + string(v)
+}
+
+// installModes returns the install modes of v's CSV.
+func (v InstallModeVariant) installModes() ([]operatorsv1alpha1.InstallMode, error) {
+	var supported map[operatorsv1alpha1.InstallModeType]bool
+	switch v {
+	case InstallModeVariantNamespaced:
+		supported = map[operatorsv1alpha1.InstallModeType]bool{
+			operatorsv1alpha1.InstallModeTypeOwnNamespace:    true,
+			operatorsv1alpha1.InstallModeTypeSingleNamespace: true,
+		}
+	case InstallModeVariantCluster:
+		supported = map[operatorsv1alpha1.InstallModeType]bool{
+			operatorsv1alpha1.InstallModeTypeAllNamespaces: true,
+		}
+	default:
+		return nil, fmt.Errorf("unknown install mode variant %q", v)
+	}
+	var modes []operatorsv1alpha1.InstallMode
+	for _, t := range []operatorsv1alpha1.InstallModeType{
+		operatorsv1alpha1.InstallModeTypeOwnNamespace,
+		operatorsv1alpha1.InstallModeTypeSingleNamespace,
+		operatorsv1alpha1.InstallModeTypeMultiNamespace,
+		operatorsv1alpha1.InstallModeTypeAllNamespaces,
+	} {
+		modes = append(modes, operatorsv1alpha1.InstallMode{Type: t, Supported: supported[t]})
+	}
+	return modes, nil
+}
+
+// clusterScopedResources are well-known cluster-scoped resources by API group,
+// whose rules cannot be granted by a namespaced Role.
+var clusterScopedResources = map[string][]string{
+	"":                             {"namespaces", "nodes", "persistentvolumes", "componentstatuses"},
+	"admissionregistration.k8s.io": {"mutatingwebhookconfigurations", "validatingwebhookconfigurations"},
+	"apiextensions.k8s.io":         {"customresourcedefinitions"},
+	"apiregistration.k8s.io":       {"apiservices"},
+	"authentication.k8s.io":        {"tokenreviews"},
+	"authorization.k8s.io":         {"selfsubjectaccessreviews", "selfsubjectrulesreviews", "subjectaccessreviews"},
+	"certificates.k8s.io":          {"certificatesigningrequests"},
+	"rbac.authorization.k8s.io":    {"clusterroles", "clusterrolebindings"},
+	"scheduling.k8s.io":            {"priorityclasses"},
+	"storage.k8s.io":               {"csidrivers", "csinodes", "storageclasses", "volumeattachments"},
+}
+
+// applyInstallModeVariant sets csv's install modes for v and moves its RBAC between
+// permissions and clusterPermissions to match v's scope. Rules for resources of
+// cluster-scoped CRDs in c are kept cluster-wide in a namespaced variant.
+func applyInstallModeVariant(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion,
+	v InstallModeVariant) error {
+	modes, err := v.installModes()
+	if err != nil {
+		return err
+	}
+	csv.Spec.InstallModes = modes
+
+	strategy := &csv.Spec.InstallStrategy.StrategySpec
+	switch v {
+	case InstallModeVariantNamespaced:
+		isClusterScoped := makeClusterScopedMatcher(c)
+		var clusterPerms []operatorsv1alpha1.StrategyDeploymentPermissions
+		for _, perm := range strategy.ClusterPermissions {
+			namespaced, cluster := splitRulesByScope(perm.Rules, isClusterScoped)
+			strategy.Permissions = mergePermissions(strategy.Permissions, perm.ServiceAccountName, namespaced)
+			if len(cluster) != 0 {
+				log.Warnf("%s variant: service account %q keeps %d cluster-scoped rule(s) in clusterPermissions",
+					v, perm.ServiceAccountName, len(cluster))
+				clusterPerms = mergePermissions(clusterPerms, perm.ServiceAccountName, cluster)
+			}
+		}
+		strategy.ClusterPermissions = clusterPerms
+	case InstallModeVariantCluster:
+		for _, perm := range strategy.Permissions {
+			strategy.ClusterPermissions = mergePermissions(strategy.ClusterPermissions, perm.ServiceAccountName, perm.Rules)
+		}
+		strategy.Permissions = nil
+	}
+	return nil
+}
+
+// mergePermissions appends rules to the permissions of serviceAccountName in perms,
+// adding an entry for serviceAccountName if perms has none.
+func mergePermissions(perms []operatorsv1alpha1.StrategyDeploymentPermissions, serviceAccountName string,
+	rules []rbacv1.PolicyRule) []operatorsv1alpha1.StrategyDeploymentPermissions {
+	if len(rules) == 0 {
+		return perms
+	}
+	for i := range perms {
+		if perms[i].ServiceAccountName == serviceAccountName {
+			perms[i].Rules = append(perms[i].Rules, rules...)
+			return perms
+		}
+	}
+	return append(perms, operatorsv1alpha1.StrategyDeploymentPermissions{
+		ServiceAccountName: serviceAccountName,
+		Rules:              rules,
+	})
+}
+
+// makeClusterScopedMatcher returns a function that returns true if resource in group
+// is cluster-scoped, either because it is well-known or defined by a cluster-scoped CRD in c.
+func makeClusterScopedMatcher(c *collector.Manifests) func(group, resource string) bool {
+	scoped := make(map[string]bool)
+	for group, resources := range clusterScopedResources {
+		for _, resource := range resources {
+			scoped[group+"/"+resource] = true
+		}
+	}
+	if c != nil {
+		for _, crd := range c.V1CustomResourceDefinitions {
+			if crd.Spec.Scope == apiextv1.ClusterScoped {
+				scoped[crd.Spec.Group+"/"+crd.Spec.Names.Plural] = true
+			}
+		}
+		for _, crd := range c.V1beta1CustomResourceDefinitions {
+			if crd.Spec.Scope == apiextv1beta1.ClusterScoped {
+				scoped[crd.Spec.Group+"/"+crd.Spec.Names.Plural] = true
+			}
+		}
+	}
+	return func(group, resource string) bool {
+		// Subresources, ex. nodes/status, have the scope of their resource.
+		resource = strings.SplitN(resource, "/", 2)[0]
+		return scoped[group+"/"+resource]
+	}
+}
+
+// splitRulesByScope splits rules into those that can be granted in a namespace and
+// those that only apply cluster-wide: non-resource URL rules, and the resources of
+// each rule that isClusterScoped reports as cluster-scoped in any of its API groups.
+func splitRulesByScope(rules []rbacv1.PolicyRule,
+	isClusterScoped func(group, resource string) bool) (namespaced, cluster []rbacv1.PolicyRule) {
+	for _, rule := range rules {
+		if len(rule.NonResourceURLs) != 0 {
+			cluster = append(cluster, rule)
+			continue
+		}
+		var nsResources, clusterResources []string
+		for _, resource := range rule.Resources {
+			isCluster := false
+			for _, group := range rule.APIGroups {
+				if isClusterScoped(group, resource) {
+					isCluster = true
+					break
+				}
+			}
+			if isCluster {
+				clusterResources = append(clusterResources, resource)
+			} else {
+				nsResources = append(nsResources, resource)
+			}
+		}
+		switch {
+		case len(clusterResources) == 0:
+			namespaced = append(namespaced, rule)
+		case len(nsResources) == 0:
+			cluster = append(cluster, rule)
+		default:
+			nsRule, clusterRule := *rule.DeepCopy(), *rule.DeepCopy()
+			nsRule.Resources, clusterRule.Resources = nsResources, clusterResources
+			namespaced = append(namespaced, nsRule)
+			cluster = append(cluster, clusterRule)
+		}
+	}
+	return namespaced, cluster
+}
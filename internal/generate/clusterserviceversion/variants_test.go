@@ -0,0 +1,118 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+var _ = Describe("Install mode variants", func() {
+	var (
+		c   *collector.Manifests
+		csv *operatorsv1alpha1.ClusterServiceVersion
+	)
+
+	podRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}}
+	leaseRule := rbacv1.PolicyRule{
+		APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"*"},
+	}
+	metricsRule := rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}}
+
+	BeforeEach(func() {
+		c = &collector.Manifests{
+			V1CustomResourceDefinitions: []apiextv1.CustomResourceDefinition{{
+				Spec: apiextv1.CustomResourceDefinitionSpec{
+					Group: "cache.example.com",
+					Names: apiextv1.CustomResourceDefinitionNames{Plural: "memcachedclusters"},
+					Scope: apiextv1.ClusterScoped,
+				},
+			}},
+		}
+		csv = &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.Spec.InstallStrategy.StrategySpec = operatorsv1alpha1.StrategyDetailsDeployment{
+			Permissions: []operatorsv1alpha1.StrategyDeploymentPermissions{
+				{ServiceAccountName: "memcached-operator", Rules: []rbacv1.PolicyRule{leaseRule}},
+			},
+			ClusterPermissions: []operatorsv1alpha1.StrategyDeploymentPermissions{
+				{ServiceAccountName: "memcached-operator", Rules: []rbacv1.PolicyRule{
+					podRule,
+					{
+						APIGroups: []string{"", "cache.example.com"},
+						Resources: []string{"configmaps", "nodes/status", "memcachedclusters"},
+						Verbs:     []string{"get"},
+					},
+				}},
+				{ServiceAccountName: "memcached-proxy", Rules: []rbacv1.PolicyRule{metricsRule}},
+			},
+		}
+	})
+
+	It("names each variant's package after the operator", func() {
+		Expect(InstallModeVariantNamespaced.PackageName("memcached-operator")).To(Equal("memcached-operator-namespaced"))
+		Expect(InstallModeVariantCluster.PackageName("memcached-operator")).To(Equal("memcached-operator-cluster"))
+	})
+
+	It("scopes a namespaced variant to namespaced install modes and permissions", func() {
+		Expect(applyInstallModeVariant(c, csv, InstallModeVariantNamespaced)).To(Succeed())
+		Expect(csv.Spec.InstallModes).To(Equal([]operatorsv1alpha1.InstallMode{
+			{Type: operatorsv1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+			{Type: operatorsv1alpha1.InstallModeTypeSingleNamespace, Supported: true},
+			{Type: operatorsv1alpha1.InstallModeTypeMultiNamespace, Supported: false},
+			{Type: operatorsv1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+		}))
+		strategy := csv.Spec.InstallStrategy.StrategySpec
+		Expect(strategy.Permissions).To(Equal([]operatorsv1alpha1.StrategyDeploymentPermissions{
+			{ServiceAccountName: "memcached-operator", Rules: []rbacv1.PolicyRule{
+				leaseRule,
+				podRule,
+				{APIGroups: []string{"", "cache.example.com"}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			}},
+		}))
+		Expect(strategy.ClusterPermissions).To(Equal([]operatorsv1alpha1.StrategyDeploymentPermissions{
+			{ServiceAccountName: "memcached-operator", Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{"", "cache.example.com"},
+					Resources: []string{"nodes/status", "memcachedclusters"},
+					Verbs:     []string{"get"},
+				},
+			}},
+			{ServiceAccountName: "memcached-proxy", Rules: []rbacv1.PolicyRule{metricsRule}},
+		}))
+	})
+
+	It("scopes a cluster variant to AllNamespaces installs and cluster permissions", func() {
+		Expect(applyInstallModeVariant(c, csv, InstallModeVariantCluster)).To(Succeed())
+		Expect(csv.Spec.InstallModes).To(ContainElement(
+			operatorsv1alpha1.InstallMode{Type: operatorsv1alpha1.InstallModeTypeAllNamespaces, Supported: true}))
+		Expect(csv.Spec.InstallModes).To(ContainElement(
+			operatorsv1alpha1.InstallMode{Type: operatorsv1alpha1.InstallModeTypeOwnNamespace, Supported: false}))
+		strategy := csv.Spec.InstallStrategy.StrategySpec
+		Expect(strategy.Permissions).To(BeEmpty())
+		Expect(strategy.ClusterPermissions).To(HaveLen(2))
+		Expect(strategy.ClusterPermissions[0].ServiceAccountName).To(Equal("memcached-operator"))
+		Expect(strategy.ClusterPermissions[0].Rules).To(HaveLen(3))
+		Expect(strategy.ClusterPermissions[0].Rules[2]).To(Equal(leaseRule))
+	})
+
+	It("returns an error for an unknown variant", func() {
+		Expect(applyInstallModeVariant(c, csv, "multi")).To(MatchError(`unknown install mode variant "multi"`))
+	})
+})
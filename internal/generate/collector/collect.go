@@ -40,6 +40,8 @@ import (
 type Manifests struct {
 	Roles                            []rbacv1.Role
 	ClusterRoles                     []rbacv1.ClusterRole
+	RoleBindings                     []rbacv1.RoleBinding
+	ClusterRoleBindings              []rbacv1.ClusterRoleBinding
 	Deployments                      []appsv1.Deployment
 	V1CustomResourceDefinitions      []apiextv1.CustomResourceDefinition
 	V1beta1CustomResourceDefinitions []apiextv1beta1.CustomResourceDefinition
@@ -54,6 +56,8 @@ type Manifests struct {
 var (
 	roleGK                 = rbacv1.SchemeGroupVersion.WithKind("Role").GroupKind()
 	clusterRoleGK          = rbacv1.SchemeGroupVersion.WithKind("ClusterRole").GroupKind()
+	roleBindingGK          = rbacv1.SchemeGroupVersion.WithKind("RoleBinding").GroupKind()
+	clusterRoleBindingGK   = rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding").GroupKind()
 	deploymentGK           = appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind()
 	v1crdGK                = apiextv1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind()
 	v1beta1crdGK           = apiextv1beta1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind()
@@ -62,7 +66,7 @@ var (
 	v1alpha3ScorecardCfgGK = scorecardv1alpha3.SchemeGroupVersion.WithKind("Configuration").GroupKind()
 )
 
-// UpdateFromDirs adds Roles, ClusterRoles, Deployments, and Custom Resource examples
+// UpdateFromDirs adds Roles, ClusterRoles, their bindings, Deployments, and Custom Resource examples
 // found in deployDir, and CustomResourceDefinitions found in crdsDir,
 // to their respective fields in a Manifests, then filters and deduplicates them.
 // All other objects are added to Manifests.Others.
@@ -92,6 +96,10 @@ func (c *Manifests) UpdateFromDirs(deployDir, crdsDir string) error {
 				err = c.addRoles(manifest)
 			case clusterRoleGK:
 				err = c.addClusterRoles(manifest)
+			case roleBindingGK:
+				err = c.addRoleBindings(manifest)
+			case clusterRoleBindingGK:
+				err = c.addClusterRoleBindings(manifest)
 			case deploymentGK:
 				err = c.addDeployments(manifest)
 			case v1crdGK, v1beta1crdGK:
@@ -134,7 +142,7 @@ func (c *Manifests) UpdateFromDirs(deployDir, crdsDir string) error {
 	return nil
 }
 
-// UpdateFromReader adds Roles, ClusterRoles, Deployments, CustomResourceDefinitions,
+// UpdateFromReader adds Roles, ClusterRoles, their bindings, Deployments, CustomResourceDefinitions,
 // and Custom Resources found in r to their respective fields in a Manifests, then
 // filters and deduplicates them. All other objects are added to Manifests.Others.
 func (c *Manifests) UpdateFromReader(r io.Reader) error {
@@ -154,6 +162,10 @@ func (c *Manifests) UpdateFromReader(r io.Reader) error {
 			err = c.addRoles(manifest)
 		case clusterRoleGK:
 			err = c.addClusterRoles(manifest)
+		case roleBindingGK:
+			err = c.addRoleBindings(manifest)
+		case clusterRoleBindingGK:
+			err = c.addClusterRoleBindings(manifest)
 		case deploymentGK:
 			err = c.addDeployments(manifest)
 		case v1crdGK, v1beta1crdGK:
@@ -212,6 +224,32 @@ func (c *Manifests) addClusterRoles(rawManifests ...[]byte) error {
 	return nil
 }
 
+// addRoleBindings assumes all manifest data in rawManifests are RoleBindings
+// and adds them to the collector.
+func (c *Manifests) addRoleBindings(rawManifests ...[]byte) error {
+	for _, rawManifest := range rawManifests {
+		binding := rbacv1.RoleBinding{}
+		if err := yaml.Unmarshal(rawManifest, &binding); err != nil {
+			return err
+		}
+		c.RoleBindings = append(c.RoleBindings, binding)
+	}
+	return nil
+}
+
+// addClusterRoleBindings assumes all manifest data in rawManifests are
+// ClusterRoleBindings and adds them to the collector.
+func (c *Manifests) addClusterRoleBindings(rawManifests ...[]byte) error {
+	for _, rawManifest := range rawManifests {
+		binding := rbacv1.ClusterRoleBinding{}
+		if err := yaml.Unmarshal(rawManifest, &binding); err != nil {
+			return err
+		}
+		c.ClusterRoleBindings = append(c.ClusterRoleBindings, binding)
+	}
+	return nil
+}
+
 // addDeployments assumes all manifest data in rawManifests are Deployments
 // and adds them to the collector.
 func (c *Manifests) addDeployments(rawManifests ...[]byte) error {
@@ -45,6 +45,7 @@ func TestUpdateFromReader(t *testing.T) {
 
 	assert.Nil(t, err, "failed to read manifests")
 	assert.Equal(t, len(c.Roles), 1, "failed to read Role(s)")
+	assert.Equal(t, len(c.RoleBindings), 1, "failed to read RoleBinding(s)")
 	assert.Equal(t, len(c.Deployments), 1, "failed to read Deployment(s)")
 	// 2 CR/CRDs:
 	// - memcached.cache.examples.com
@@ -80,6 +80,30 @@ func (c *Manifests) deduplicate() error {
 	}
 	c.ClusterRoles = clusterRoles
 
+	roleBindings := []rbacv1.RoleBinding{}
+	for _, binding := range c.RoleBindings {
+		hasHash, err := addToHashes(&binding, hashes)
+		if err != nil {
+			return err
+		}
+		if !hasHash {
+			roleBindings = append(roleBindings, binding)
+		}
+	}
+	c.RoleBindings = roleBindings
+
+	clusterRoleBindings := []rbacv1.ClusterRoleBinding{}
+	for _, binding := range c.ClusterRoleBindings {
+		hasHash, err := addToHashes(&binding, hashes)
+		if err != nil {
+			return err
+		}
+		if !hasHash {
+			clusterRoleBindings = append(clusterRoleBindings, binding)
+		}
+	}
+	c.ClusterRoleBindings = clusterRoleBindings
+
 	deps := []appsv1.Deployment{}
 	for _, dep := range c.Deployments {
 		hasHash, err := addToHashes(&dep, hashes)
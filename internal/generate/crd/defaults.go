@@ -0,0 +1,391 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+
+	"github.com/operator-framework/operator-sdk/internal/util/fileutil"
+)
+
+const (
+	defaultMarkerName     = "kubebuilder:default"
+	groupNameMarkerName   = "groupName"
+	versionNameMarkerName = "versionName"
+
+	// Field path segments of list items and map values.
+	itemsPathSegment  = "[*]"
+	valuesPathSegment = "*"
+)
+
+// MissingDefault is a default set on an API type field with a
+// +kubebuilder:default marker that is not, or is not correctly, in the
+// field's CRD schema.
+type MissingDefault struct {
+	// CRD is the CRD's name.
+	CRD string
+	// Version is the CRD version whose schema is missing the default.
+	Version string
+	// Path is the field's path in the CRD's schema, ex. spec.ports[*].protocol.
+	Path string
+	// Default is the marker's raw value.
+	Default string
+	// Reason describes how the default is missing, ex. "is missing from the CRD schema".
+	Reason string
+}
+
+func (d MissingDefault) String() string {
+	return fmt.Sprintf("CRD %s version %s: field %s default %s %s", d.CRD, d.Version, d.Path, d.Default, d.Reason)
+}
+
+// CheckDefaults returns the defaults set by +kubebuilder:default markers on
+// fields of API types in apisDir that are missing from their CRDs' schemas in
+// crds. controller-gen silently drops or mangles some defaults, ex. "{}" is
+// emitted as null and "[]" as a string, so CRDs should be checked after generation.
+// A CRD version is matched to the type named by its kind in the package with
+// that version and group, as set by the package's +groupName marker.
+func CheckDefaults(apisDir string, crds []apiextv1.CustomResourceDefinition) ([]MissingDefault, error) {
+	pkgs, err := loader.LoadRoots(fmt.Sprintf("%s/...", fileutil.DotPath(filepath.Clean(apisDir))))
+	if err != nil {
+		return nil, fmt.Errorf("error loading API packages in %s: %v", apisDir, err)
+	}
+	w, err := newDefaultsWalker(pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []MissingDefault
+	for _, crd := range crds {
+		for _, ver := range crd.Spec.Versions {
+			root := w.findRoot(crd.Spec.Group, ver.Name, crd.Spec.Names.Kind)
+			if root == nil {
+				continue
+			}
+			var schema *apiextv1.JSONSchemaProps
+			if ver.Schema != nil {
+				schema = ver.Schema.OpenAPIV3Schema
+			}
+			for _, d := range w.defaultsFor(root) {
+				reason := checkSchemaDefault(schema, d.path)
+				if reason == "" {
+					continue
+				}
+				missing = append(missing, MissingDefault{
+					CRD:     crd.GetName(),
+					Version: ver.Name,
+					Path:    formatFieldPath(d.path),
+					Default: d.value,
+					Reason:  reason,
+				})
+			}
+		}
+	}
+	return missing, nil
+}
+
+// fieldDefault is a field's raw +kubebuilder:default value at some path.
+type fieldDefault struct {
+	path  []string
+	value string
+}
+
+// apiType is a named type declared in an API package.
+type apiType struct {
+	pkg  *apiPackage
+	file *ast.File
+	spec *ast.TypeSpec
+}
+
+// apiPackage holds syntactic information about an API package.
+type apiPackage struct {
+	path    string
+	name    string
+	group   string
+	version string
+	types   map[string]apiType
+	markers map[ast.Node]markers.MarkerValues
+}
+
+// defaultsWalker collects field defaults from API types by walking their
+// syntax, so API packages and their dependencies do not need to type-check.
+type defaultsWalker struct {
+	pkgs map[string]*apiPackage
+}
+
+func newDefaultsWalker(pkgs []*loader.Package) (*defaultsWalker, error) {
+	reg := &markers.Registry{}
+	for _, def := range []*markers.Definition{
+		markers.Must(markers.MakeDefinition(defaultMarkerName, markers.DescribesField, markers.RawArguments(nil))),
+		markers.Must(markers.MakeDefinition(groupNameMarkerName, markers.DescribesPackage, "")),
+		markers.Must(markers.MakeDefinition(versionNameMarkerName, markers.DescribesPackage, "")),
+	} {
+		if err := reg.Register(def); err != nil {
+			return nil, err
+		}
+	}
+	col := &markers.Collector{Registry: reg}
+
+	w := &defaultsWalker{pkgs: make(map[string]*apiPackage, len(pkgs))}
+	for _, pkg := range pkgs {
+		nodeMarkers, err := col.MarkersInPackage(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing markers in package %s: %v", pkg.PkgPath, err)
+		}
+		pkgMarkers, err := markers.PackageMarkers(col, pkg)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing package markers in package %s: %v", pkg.PkgPath, err)
+		}
+		p := &apiPackage{
+			path:    pkg.PkgPath,
+			name:    pkg.Name,
+			version: pkg.Name,
+			types:   map[string]apiType{},
+			markers: nodeMarkers,
+		}
+		if v, ok := pkgMarkers.Get(groupNameMarkerName).(string); ok {
+			p.group = v
+		}
+		if v, ok := pkgMarkers.Get(versionNameMarkerName).(string); ok && v != "" {
+			p.version = v
+		}
+		loader.EachType(pkg, func(file *ast.File, _ *ast.GenDecl, spec *ast.TypeSpec) {
+			p.types[spec.Name.Name] = apiType{pkg: p, file: file, spec: spec}
+		})
+		w.pkgs[p.path] = p
+	}
+	return w, nil
+}
+
+// findRoot returns the type of kind in the API package for group and version.
+func (w *defaultsWalker) findRoot(group, version, kind string) *apiType {
+	for _, p := range w.pkgs {
+		if p.group != group || p.version != version {
+			continue
+		}
+		if t, ok := p.types[kind]; ok {
+			return &t
+		}
+	}
+	return nil
+}
+
+// defaultsFor returns all field defaults in t and the types it references,
+// sorted by path.
+func (w *defaultsWalker) defaultsFor(t *apiType) []fieldDefault {
+	var defaults []fieldDefault
+	w.walkExpr(t.pkg, t.file, t.spec.Type, nil, map[string]bool{}, &defaults)
+	sort.Slice(defaults, func(i, j int) bool {
+		return formatFieldPath(defaults[i].path) < formatFieldPath(defaults[j].path)
+	})
+	return defaults
+}
+
+// walkExpr collects defaults in the type expression expr, declared in file
+// of pkg, at path. seen contains the named types being walked on this path.
+func (w *defaultsWalker) walkExpr(pkg *apiPackage, file *ast.File, expr ast.Expr, path []string,
+	seen map[string]bool, defaults *[]fieldDefault) {
+
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		w.walkExpr(pkg, file, e.X, path, seen, defaults)
+	case *ast.ArrayType:
+		w.walkExpr(pkg, file, e.Elt, appendPath(path, itemsPathSegment), seen, defaults)
+	case *ast.MapType:
+		w.walkExpr(pkg, file, e.Value, appendPath(path, valuesPathSegment), seen, defaults)
+	case *ast.Ident:
+		if t, ok := pkg.types[e.Name]; ok {
+			w.walkNamed(t, path, seen, defaults)
+		}
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return
+		}
+		if other := w.importedPackage(file, x.Name); other != nil {
+			if t, ok := other.types[e.Sel.Name]; ok {
+				w.walkNamed(t, path, seen, defaults)
+			}
+		}
+	case *ast.StructType:
+		for _, field := range e.Fields.List {
+			w.walkField(pkg, file, field, path, seen, defaults)
+		}
+	}
+}
+
+// walkNamed collects defaults in the named type t at path.
+func (w *defaultsWalker) walkNamed(t apiType, path []string, seen map[string]bool, defaults *[]fieldDefault) {
+	key := t.pkg.path + "." + t.spec.Name.Name
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+	defer delete(seen, key)
+	w.walkExpr(t.pkg, t.file, t.spec.Type, path, seen, defaults)
+}
+
+// walkField collects the default of field and those in its type, using the
+// field's JSON name as in encoding/json.
+func (w *defaultsWalker) walkField(pkg *apiPackage, file *ast.File, field *ast.Field, path []string,
+	seen map[string]bool, defaults *[]fieldDefault) {
+
+	tagParts := strings.Split(loader.ParseAstTag(field.Tag).Get("json"), ",")
+	name := tagParts[0]
+	if name == "-" && len(tagParts) == 1 {
+		return
+	}
+	inline := len(field.Names) == 0 && name == ""
+	for _, opt := range tagParts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+	if inline {
+		w.walkExpr(pkg, file, field.Type, path, seen, defaults)
+		return
+	}
+
+	names := []string{name}
+	if name == "" {
+		names = names[:0]
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+	}
+	for _, n := range names {
+		fieldPath := appendPath(path, n)
+		if raw, ok := pkg.markers[field].Get(defaultMarkerName).(markers.RawArguments); ok {
+			*defaults = append(*defaults, fieldDefault{path: fieldPath, value: string(raw)})
+		}
+		w.walkExpr(pkg, file, field.Type, fieldPath, seen, defaults)
+	}
+}
+
+// importedPackage returns the API package imported in file as name, if any.
+func (w *defaultsWalker) importedPackage(file *ast.File, name string) *apiPackage {
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		p, ok := w.pkgs[path]
+		if !ok {
+			continue
+		}
+		if (imp.Name != nil && imp.Name.Name == name) || (imp.Name == nil && p.name == name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// checkSchemaDefault returns why the schema at path in root does not have a
+// default of its type, or an empty string if it does or the schema does not
+// describe path, ex. because an ancestor preserves unknown fields.
+func checkSchemaDefault(root *apiextv1.JSONSchemaProps, path []string) string {
+	if root == nil {
+		return ""
+	}
+	s := root
+	for _, seg := range path {
+		var next *apiextv1.JSONSchemaProps
+		switch seg {
+		case itemsPathSegment:
+			if s.Items != nil {
+				next = s.Items.Schema
+			}
+		case valuesPathSegment:
+			if s.AdditionalProperties != nil {
+				next = s.AdditionalProperties.Schema
+			}
+		default:
+			if p, ok := s.Properties[seg]; ok {
+				next = &p
+			}
+		}
+		if next == nil {
+			if isSchemaless(s) {
+				return ""
+			}
+			return "is missing from the CRD schema, which has no such field"
+		}
+		s = next
+	}
+
+	if s.Default == nil {
+		return "is missing from the CRD schema"
+	}
+	var value interface{}
+	if err := json.Unmarshal(s.Default.Raw, &value); err != nil || value == nil {
+		return "is null in the CRD schema"
+	}
+	if t := jsonSchemaType(value); s.Type != "" && t != s.Type && !(t == "number" && s.Type == "integer") {
+		return fmt.Sprintf("has type %s in the CRD schema, which expects type %s", t, s.Type)
+	}
+	return ""
+}
+
+// jsonSchemaType returns the JSON schema type of a decoded JSON value.
+func jsonSchemaType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		return "number"
+	}
+}
+
+// isSchemaless returns true if s does not describe its contents.
+func isSchemaless(s *apiextv1.JSONSchemaProps) bool {
+	if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields {
+		return true
+	}
+	return s.Type == "" && len(s.Properties) == 0 && s.Items == nil && s.AdditionalProperties == nil &&
+		len(s.AllOf) == 0
+}
+
+func appendPath(path []string, seg string) []string {
+	out := make([]string, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, seg)
+}
+
+// formatFieldPath returns path joined by ".", with list items as "[*]".
+func formatFieldPath(path []string) string {
+	sb := strings.Builder{}
+	for i, seg := range path {
+		if i > 0 && seg != itemsPathSegment {
+			sb.WriteString(".")
+		}
+		sb.WriteString(seg)
+	}
+	return sb.String()
+}
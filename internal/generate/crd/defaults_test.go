@@ -0,0 +1,175 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const defaultsGroupVersionInfo = `// +groupName=cache.example.com
+package v1alpha1
+`
+
+const defaultsTypes = `package v1alpha1
+
+type MemcachedSpec struct {
+	// +kubebuilder:default=3
+	Size int32 ` + "`json:\"size,omitempty\"`" + `
+	// +kubebuilder:default={mode: "lru"}
+	Eviction *Eviction ` + "`json:\"eviction,omitempty\"`" + `
+	// +kubebuilder:default={{name: "memcached", port: 11211}}
+	Ports []Port ` + "`json:\"ports,omitempty\"`" + `
+	Labels map[string]Label ` + "`json:\"labels,omitempty\"`" + `
+	Extra Extra ` + "`json:\"extra,omitempty\"`" + `
+	Cache Cache ` + "`json:\"cache,omitempty\"`" + `
+	Common ` + "`json:\",inline\"`" + `
+	// +kubebuilder:default=ignored
+	Ignored string ` + "`json:\"-\"`" + `
+}
+
+type Common struct {
+	// +kubebuilder:default=info
+	LogLevel string ` + "`json:\"logLevel,omitempty\"`" + `
+}
+
+type Eviction struct {
+	Mode string ` + "`json:\"mode,omitempty\"`" + `
+}
+
+type Port struct {
+	Name string ` + "`json:\"name\"`" + `
+	// +kubebuilder:default=TCP
+	Protocol string ` + "`json:\"protocol,omitempty\"`" + `
+}
+
+type Label struct {
+	// +kubebuilder:default=false
+	Required bool ` + "`json:\"required,omitempty\"`" + `
+}
+
+type Extra struct {
+	// +kubebuilder:default={}
+	Options map[string]string ` + "`json:\"options,omitempty\"`" + `
+}
+
+type Cache struct {
+	// +kubebuilder:default={}
+	Options map[string]string ` + "`json:\"options,omitempty\"`" + `
+	// +kubebuilder:default=[]
+	Servers []string ` + "`json:\"servers,omitempty\"`" + `
+}
+
+type Memcached struct {
+	Spec MemcachedSpec ` + "`json:\"spec,omitempty\"`" + `
+}
+`
+
+func TestCheckDefaults(t *testing.T) {
+	// Write API types to a package in this module so they can be loaded.
+	tmp, err := ioutil.TempDir(".", "defaults-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	apisDir := filepath.Join(tmp, "api")
+	pkgDir := filepath.Join(apisDir, "v1alpha1")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "groupversion_info.go"), []byte(defaultsGroupVersionInfo), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "memcached_types.go"), []byte(defaultsTypes), 0644))
+
+	def := func(raw string) *apiextv1.JSON { return &apiextv1.JSON{Raw: []byte(raw)} }
+	trueVal := true
+	spec := apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"size":     {Type: "integer", Default: def("3")},
+			"logLevel": {Type: "string", Default: def(`"info"`)},
+			// The object default was dropped.
+			"eviction": {Type: "object", Properties: map[string]apiextv1.JSONSchemaProps{"mode": {Type: "string"}}},
+			"ports": {
+				Type:    "array",
+				Default: def(`[{"name":"memcached","port":11211}]`),
+				Items: &apiextv1.JSONSchemaPropsOrArray{Schema: &apiextv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextv1.JSONSchemaProps{
+						"name": {Type: "string"},
+						// The list item field default was dropped.
+						"protocol": {Type: "string"},
+					},
+				}},
+			},
+			"labels": {
+				Type: "object",
+				AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{Schema: &apiextv1.JSONSchemaProps{
+					Type: "object",
+					// The map value field is missing.
+					Properties: map[string]apiextv1.JSONSchemaProps{},
+				}},
+			},
+			// controller-gen emits "{}" as null and "[]" as a string.
+			"cache": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"options": {Type: "object", Default: &apiextv1.JSON{}},
+					"servers": {Type: "array", Default: def(`"[]"`)},
+				},
+			},
+			// Defaults under schemaless fields cannot be checked.
+			"extra": {Type: "object", XPreserveUnknownFields: &trueVal},
+		},
+	}
+	crd := apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "memcacheds.cache.example.com"},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Group: "cache.example.com",
+			Names: apiextv1.CustomResourceDefinitionNames{Kind: "Memcached"},
+			Versions: []apiextv1.CustomResourceDefinitionVersion{
+				{
+					Name: "v1alpha1",
+					Schema: &apiextv1.CustomResourceValidation{OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+						Type:       "object",
+						Properties: map[string]apiextv1.JSONSchemaProps{"spec": spec},
+					}},
+				},
+				// No API types exist for this version.
+				{Name: "v1beta1"},
+			},
+		},
+	}
+
+	missing, err := CheckDefaults(apisDir, []apiextv1.CustomResourceDefinition{crd})
+	require.NoError(t, err)
+	name := crd.GetName()
+	assert.Equal(t, []MissingDefault{
+		{CRD: name, Version: "v1alpha1", Path: "spec.cache.options", Default: "{}",
+			Reason: "is null in the CRD schema"},
+		{CRD: name, Version: "v1alpha1", Path: "spec.cache.servers", Default: "[]",
+			Reason: "has type string in the CRD schema, which expects type array"},
+		{CRD: name, Version: "v1alpha1", Path: "spec.eviction", Default: `{mode: "lru"}`,
+			Reason: "is missing from the CRD schema"},
+		{CRD: name, Version: "v1alpha1", Path: "spec.labels.*.required", Default: "false",
+			Reason: "is missing from the CRD schema, which has no such field"},
+		{CRD: name, Version: "v1alpha1", Path: "spec.ports[*].protocol", Default: "TCP",
+			Reason: "is missing from the CRD schema"},
+	}, missing)
+	assert.Equal(t, "CRD memcacheds.cache.example.com version v1alpha1: field spec.ports[*].protocol "+
+		"default TCP is missing from the CRD schema", missing[4].String())
+}
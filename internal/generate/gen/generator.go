@@ -24,6 +24,7 @@ import (
 	crdgen "sigs.k8s.io/controller-tools/pkg/crd"
 	"sigs.k8s.io/controller-tools/pkg/genall"
 	"sigs.k8s.io/controller-tools/pkg/markers"
+	webhookgen "sigs.k8s.io/controller-tools/pkg/webhook"
 )
 
 // Generator can generate artifacts using data contained in the Generator.
@@ -118,7 +119,8 @@ func NewCachedRunner() Runner {
 	return &cachedRunner{
 		optionsRegistry: &markers.Registry{},
 		allGenerators: map[string]genall.Generator{
-			"crd": crdgen.Generator{},
+			"crd":     crdgen.Generator{},
+			"webhook": webhookgen.Generator{},
 		},
 		allOutputRules: map[string]genall.OutputRule{
 			"dir": genall.OutputToDirectory(""),
@@ -20,7 +20,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/template"
 
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/yaml"
 
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
@@ -39,6 +42,34 @@ func MakeCSVName(name, version string) string {
 	return fmt.Sprintf("%s.v%s", name, version)
 }
 
+// DefaultCSVNameTemplate is the template of names returned by MakeCSVName.
+const DefaultCSVNameTemplate = "{{.Package}}.v{{.Version}}"
+
+// MakeCSVNameFromTemplate returns a ClusterServiceVersion's name rendered from
+// tmpl, a text/template with .Package and .Version set to name and version.
+// If tmpl is empty, DefaultCSVNameTemplate is used. The rendered name must be
+// a valid resource name.
+func MakeCSVNameFromTemplate(tmpl, name, version string) (string, error) {
+	if tmpl == "" || tmpl == DefaultCSVNameTemplate {
+		return MakeCSVName(name, version), nil
+	}
+	t, err := template.New("csv-name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("error parsing CSV name template %q: %v", tmpl, err)
+	}
+	sb := &strings.Builder{}
+	data := struct{ Package, Version string }{Package: name, Version: version}
+	if err := t.Execute(sb, data); err != nil {
+		return "", fmt.Errorf("error executing CSV name template %q: %v", tmpl, err)
+	}
+	csvName := sb.String()
+	if errs := validation.IsDNS1123Subdomain(csvName); len(errs) != 0 {
+		return "", fmt.Errorf("CSV name %q rendered from template %q is not a valid resource name: %s",
+			csvName, tmpl, strings.Join(errs, ", "))
+	}
+	return csvName, nil
+}
+
 // File wraps os.File. Use this type when generating files that may already
 // exist on disk and should be overwritten.
 type File struct {
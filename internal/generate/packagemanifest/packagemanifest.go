@@ -57,6 +57,9 @@ type Generator struct {
 	// generated PackageManifest. If true, ChannelName will be the PackageManifest's default channel.
 	// Setting this field is only necessary when more than one channel exists.
 	IsDefaultChannel bool
+	// CSVNameTemplate is a text/template of the current CSV's name in ChannelName,
+	// with .Package and .Version fields. Defaults to "{{.Package}}.v{{.Version}}".
+	CSVNameTemplate string
 
 	// Func that returns a base PackageManifest.
 	getBase getBaseFunc
@@ -136,7 +139,10 @@ func (g *Generator) generate() (*apimanifests.PackageManifest, error) {
 		return nil, fmt.Errorf("error getting PackageManifest base: %v", err)
 	}
 
-	csvName := genutil.MakeCSVName(g.OperatorName, g.Version)
+	csvName, err := genutil.MakeCSVNameFromTemplate(g.CSVNameTemplate, g.OperatorName, g.Version)
+	if err != nil {
+		return nil, err
+	}
 	if g.ChannelName != "" {
 		setChannels(base, g.ChannelName, csvName)
 		sortChannelsByName(base)
@@ -0,0 +1,108 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/gen"
+	"github.com/operator-framework/operator-sdk/internal/util/fileutil"
+)
+
+// ManifestsFile is the name of the file webhook configurations are written to,
+// matching controller-gen's webhook generator.
+const ManifestsFile = "manifests.yaml"
+
+// DefaultOutputDir is the default directory of a project's webhook configurations.
+var DefaultOutputDir = filepath.Join("config", "webhook")
+
+// Generator configures ValidatingWebhookConfiguration and MutatingWebhookConfiguration
+// manifest generation from +kubebuilder:webhook markers in Go code.
+type Generator struct {
+	// PackagesDir is the root of the Go packages to parse for markers.
+	// Defaults to the current directory.
+	PackagesDir string
+	// OutputDir is the directory ManifestsFile is written to.
+	// Defaults to DefaultOutputDir.
+	OutputDir string
+}
+
+// Generate generates webhook configuration manifests and writes them to g.OutputDir.
+// Nothing is written if no packages contain webhook markers.
+func (g Generator) Generate() error {
+	if g.PackagesDir == "" {
+		g.PackagesDir = "."
+	}
+	if g.OutputDir == "" {
+		g.OutputDir = DefaultOutputDir
+	}
+
+	b, err := g.generate()
+	if err != nil {
+		return fmt.Errorf("error generating webhook manifests: %w", err)
+	}
+	if b == nil {
+		log.Debugf("No webhook markers found in %s", g.PackagesDir)
+		return nil
+	}
+	if err = os.MkdirAll(g.OutputDir, fileutil.DefaultDirFileMode); err != nil {
+		return fmt.Errorf("error mkdir %s: %w", g.OutputDir, err)
+	}
+	path := filepath.Join(g.OutputDir, ManifestsFile)
+	if err := ioutil.WriteFile(path, b, fileutil.DefaultFileMode); err != nil {
+		return fmt.Errorf("error writing webhook manifests: %w", err)
+	}
+	return nil
+}
+
+// generate runs controller-gen's webhook generator, returning the generated
+// manifests or nil if there are none.
+func (g Generator) generate() ([]byte, error) {
+	// Generate files in the generator's cache, which persists between runs,
+	// so remove any manifests generated by a previous run.
+	defName := "output:webhook:cache"
+	cacheOutputDir := filepath.Clean(g.OutputDir)
+	cache := gen.GetCache()
+	cachePath := filepath.Join(cacheOutputDir, ManifestsFile)
+	if err := cache.Remove(cachePath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error removing cached webhook manifests %s: %w", cachePath, err)
+	}
+
+	rawOpts := []string{
+		"webhook",
+		fmt.Sprintf("paths=%s/...", fileutil.DotPath(g.PackagesDir)),
+		fmt.Sprintf("%s:dir=%s", defName, cacheOutputDir),
+	}
+	runner := gen.NewCachedRunner()
+	runner.AddOutputRule(defName, gen.OutputToCachedDirectory{})
+	if err := runner.Run(rawOpts); err != nil {
+		return nil, fmt.Errorf("error running webhook generator: %w", err)
+	}
+
+	b, err := afero.ReadFile(cache, cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading cached webhook manifests %s: %w", cachePath, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookSource = `package v1alpha1
+
+// +kubebuilder:webhook:path=/validate-cache-example-com-v1alpha1-memcached,mutating=false,failurePolicy=fail,groups=cache.example.com,resources=memcacheds,verbs=create;update,versions=v1alpha1,name=vmemcached.kb.io
+`
+
+func TestGenerate(t *testing.T) {
+	// Packages must be in this module to be loaded.
+	pkgDir, err := ioutil.TempDir(".", "webhooks-")
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, os.RemoveAll(pkgDir))
+	}()
+	outputDir := filepath.Join(pkgDir, "config")
+	g := Generator{PackagesDir: pkgDir, OutputDir: outputDir}
+	sourcePath := filepath.Join(pkgDir, "memcached_webhook.go")
+	manifestsPath := filepath.Join(outputDir, ManifestsFile)
+
+	// No markers, no manifests.
+	require.NoError(t, ioutil.WriteFile(sourcePath, []byte("package v1alpha1\n"), 0644))
+	require.NoError(t, g.Generate())
+	assert.NoFileExists(t, manifestsPath)
+
+	require.NoError(t, ioutil.WriteFile(sourcePath, []byte(webhookSource), 0644))
+	require.NoError(t, g.Generate())
+	b, err := ioutil.ReadFile(manifestsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "kind: ValidatingWebhookConfiguration")
+	assert.Contains(t, string(b), "path: /validate-cache-example-com-v1alpha1-memcached")
+	assert.Contains(t, string(b), "- CREATE\n    - UPDATE\n")
+
+	// Changing a marker updates the generated configuration.
+	changed := strings.Replace(webhookSource, "mutating=false", "mutating=true", 1)
+	changed = strings.Replace(changed, "verbs=create;update", "verbs=create", 1)
+	require.NoError(t, ioutil.WriteFile(sourcePath, []byte(changed), 0644))
+	require.NoError(t, g.Generate())
+	b, err = ioutil.ReadFile(manifestsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "kind: MutatingWebhookConfiguration")
+	assert.NotContains(t, string(b), "kind: ValidatingWebhookConfiguration")
+	assert.NotContains(t, string(b), "- UPDATE")
+}
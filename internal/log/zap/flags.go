@@ -116,6 +116,28 @@ type levelValue struct {
 
 func (v *levelValue) Set(l string) error {
 	v.set = true
+	level, err := ParseLevel(l)
+	if err != nil {
+		return err
+	}
+
+	v.level = level
+	lvl := int(level)
+	// If log level is greater than debug, set glog/klog level to that level.
+	if lvl < -3 {
+		fs := flag.NewFlagSet("", flag.ContinueOnError)
+		klog.InitFlags(fs)
+		err := fs.Set("v", fmt.Sprintf("%v", -1*lvl))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseLevel parses a log level in the format accepted by --zap-level: one of
+// 'debug', 'info', 'error', or any integer value > 0.
+func ParseLevel(l string) (zapcore.Level, error) {
 	lower := strings.ToLower(l)
 	var lvl int
 	var err error
@@ -128,21 +150,10 @@ func (v *levelValue) Set(l string) error {
 		lvl = 2
 	default:
 		if lvl, err = parseIntLogLevel(lower); err != nil {
-			return err
-		}
-	}
-
-	v.level = zapcore.Level(int8(lvl))
-	// If log level is greater than debug, set glog/klog level to that level.
-	if lvl < -3 {
-		fs := flag.NewFlagSet("", flag.ContinueOnError)
-		klog.InitFlags(fs)
-		err := fs.Set("v", fmt.Sprintf("%v", -1*lvl))
-		if err != nil {
-			return err
+			return 0, err
 		}
 	}
-	return nil
+	return zapcore.Level(int8(lvl)), nil
 }
 
 func (v levelValue) String() string {
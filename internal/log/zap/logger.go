@@ -35,6 +35,18 @@ func LoggerTo(destWriter io.Writer) logr.Logger {
 	return createLogger(conf, destWriter)
 }
 
+// LoggerWithLevel returns a logger configured by the zap flags, except with
+// its level set to level.
+func LoggerWithLevel(level zapcore.Level) logr.Logger {
+	conf := getConfig()
+	conf.level = zap.NewAtomicLevelAt(level)
+	// Sampling cannot be used at levels lower than debug; see getConfig.
+	if level < -1 {
+		conf.sample = false
+	}
+	return createLogger(conf, os.Stderr)
+}
+
 func createLogger(conf config, destWriter io.Writer) logr.Logger {
 	syncer := zapcore.AddSync(destWriter)
 
@@ -56,12 +56,15 @@ func ClientForConfig(cfg *rest.Config) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get OLM resource client: %v", err)
 	}
-	c := &Client{
+	return newClient(cl), nil
+}
+
+func newClient(cl *olmresourceclient.Client) *Client {
+	return &Client{
 		Client:          cl,
 		HTTPClient:      *http.DefaultClient,
 		BaseDownloadURL: "https://github.com/operator-framework/operator-lifecycle-manager/releases",
 	}
-	return c, nil
 }
 
 func (c Client) InstallVersion(ctx context.Context, namespace, version string) (*olmresourceclient.Status, error) {
@@ -72,19 +75,26 @@ func (c Client) InstallVersion(ctx context.Context, namespace, version string) (
 	}
 	objs := toObjects(resources...)
 
-	status := c.GetObjectsStatus(ctx, objs...)
-	installed, err := status.HasInstalledResources()
-	if installed {
-		return nil, errors.New(
-			"detected existing OLM resources: OLM must be completely uninstalled before installation")
-	} else if err != nil {
-		return nil, errors.New("detected errored OLM resources, see resource statuses for more details")
+	// Client-side dry runs do not contact the cluster.
+	if !c.DryRun.IsClient() {
+		status := c.GetObjectsStatus(ctx, objs...)
+		installed, err := status.HasInstalledResources()
+		if installed {
+			return nil, errors.New(
+				"detected existing OLM resources: OLM must be completely uninstalled before installation")
+		} else if err != nil {
+			return nil, errors.New("detected errored OLM resources, see resource statuses for more details")
+		}
 	}
 
 	log.Print("Creating CRDs and resources")
 	if err := c.DoCreate(ctx, objs...); err != nil {
 		return nil, fmt.Errorf("failed to create CRDs and resources: %v", err)
 	}
+	// Nothing was persisted, so there is nothing to wait for.
+	if c.DryRun != nil {
+		return nil, nil
+	}
 
 	log.Print("Waiting for deployment/olm-operator rollout to complete")
 	olmOperatorKey := types.NamespacedName{Namespace: namespace, Name: olmOperatorName}
@@ -126,7 +136,7 @@ func (c Client) InstallVersion(ctx context.Context, namespace, version string) (
 		return nil, fmt.Errorf("deployment/%s failed to rollout: %v", packageServerKey.Name, err)
 	}
 
-	status = c.GetObjectsStatus(ctx, objs...)
+	status := c.GetObjectsStatus(ctx, objs...)
 	return &status, nil
 }
 
@@ -32,6 +32,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -54,6 +55,11 @@ func init() {
 
 type Client struct {
 	KubeClient client.Client
+	// DryRun, if set, makes DoCreate preview objects instead of persisting them.
+	DryRun *DryRun
+	// Manifests, if set, makes DoCreate write the manifest of each object
+	// it creates, or would create in a dry run.
+	Manifests *ManifestsWriter
 }
 
 func ClientForConfig(cfg *rest.Config) (*Client, error) {
@@ -78,6 +84,29 @@ func ClientForConfig(cfg *rest.Config) (*Client, error) {
 
 func (c Client) DoCreate(ctx context.Context, objs ...runtime.Object) error {
 	for _, obj := range objs {
+		if err := c.doCreate(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Client) doCreate(ctx context.Context, obj runtime.Object) error {
+	// Render the manifest before creation, since the cluster's response
+	// overwrites obj.
+	var u *unstructured.Unstructured
+	if c.Manifests != nil {
+		var err error
+		if u, err = manifest(obj); err != nil {
+			return err
+		}
+	}
+
+	if c.DryRun != nil {
+		if err := c.DryRun.doCreate(ctx, c.KubeClient, obj); err != nil {
+			return err
+		}
+	} else {
 		a, err := meta.Accessor(obj)
 		if err != nil {
 			return err
@@ -92,6 +121,10 @@ func (c Client) DoCreate(ctx context.Context, objs ...runtime.Object) error {
 			log.Infof("    %s %q already exists", kind, getName(a.GetNamespace(), a.GetName()))
 		}
 	}
+
+	if c.Manifests != nil {
+		return c.Manifests.write(u)
+	}
 	return nil
 }
 
@@ -129,6 +162,51 @@ func (c Client) DoDelete(ctx context.Context, objs ...runtime.Object) error {
 	return nil
 }
 
+// DoRemoveFinalizers removes all finalizers from each live object in objs
+// that is being deleted and whose labels contain every key/value pair in
+// ownerLabels, so its pending deletion can complete. Objects that do not exist,
+// are not being deleted, or are not labeled with ownerLabels are left untouched.
+// A nil ownerLabels matches every object.
+func (c Client) DoRemoveFinalizers(ctx context.Context, ownerLabels map[string]string, objs ...runtime.Object) error {
+	for _, obj := range objs {
+		key, err := client.ObjectKeyFromObject(obj)
+		if err != nil {
+			return err
+		}
+		live := obj.DeepCopyObject()
+		if err := c.KubeClient.Get(ctx, key, live); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		a, err := meta.Accessor(live)
+		if err != nil {
+			return err
+		}
+		if a.GetDeletionTimestamp() == nil || !hasLabels(a.GetLabels(), ownerLabels) || len(a.GetFinalizers()) == 0 {
+			continue
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		finalizers := a.GetFinalizers()
+		a.SetFinalizers(nil)
+		if err := c.KubeClient.Update(ctx, live); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error removing finalizers from %s %q: %v", kind, key, err)
+		}
+		log.Infof("  Force-removed finalizers %q from %s %q", finalizers, kind, getName(a.GetNamespace(), a.GetName()))
+	}
+	return nil
+}
+
+func hasLabels(labels, want map[string]string) bool {
+	for k, v := range want {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
 func getName(namespace, name string) string {
 	if namespace != "" {
 		name = fmt.Sprintf("%s/%s", namespace, name)
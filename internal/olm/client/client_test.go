@@ -0,0 +1,99 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Client", func() {
+	Describe("DoRemoveFinalizers", func() {
+		var (
+			ctx         context.Context
+			c           Client
+			logs        *bytes.Buffer
+			logOut      io.Writer
+			ownerLabels = map[string]string{"operator-framework.io/owner": "operator-sdk"}
+			finalizers  = []string{"example.com/cleanup"}
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			logs, logOut = &bytes.Buffer{}, log.StandardLogger().Out
+			log.SetOutput(logs)
+			c = Client{KubeClient: fake.NewFakeClientWithScheme(scheme.Scheme,
+				newFinalizedConfigMap("owned-deleting", ownerLabels, true),
+				newFinalizedConfigMap("owned-live", ownerLabels, false),
+				newFinalizedConfigMap("unowned-deleting", nil, true),
+			)}
+		})
+		AfterEach(func() {
+			log.SetOutput(logOut)
+		})
+
+		getFinalizers := func(name string) []string {
+			cm := &corev1.ConfigMap{}
+			ExpectWithOffset(1, c.KubeClient.Get(ctx, types.NamespacedName{Namespace: "memcached", Name: name}, cm)).To(Succeed())
+			return cm.GetFinalizers()
+		}
+
+		It("removes and logs finalizers of labeled objects being deleted", func() {
+			Expect(c.DoRemoveFinalizers(ctx, ownerLabels, newConfigMap("memcached", "owned-deleting"))).To(Succeed())
+			Expect(getFinalizers("owned-deleting")).To(BeEmpty())
+			Expect(logs.String()).To(ContainSubstring(`Force-removed finalizers [\"example.com/cleanup\"] from ConfigMap \"memcached/owned-deleting\"`))
+		})
+		It("leaves objects that are not being deleted or not labeled untouched", func() {
+			Expect(c.DoRemoveFinalizers(ctx, ownerLabels,
+				newConfigMap("memcached", "owned-live"),
+				newConfigMap("memcached", "unowned-deleting"),
+				newConfigMap("memcached", "missing"),
+			)).To(Succeed())
+			Expect(getFinalizers("owned-live")).To(Equal(finalizers))
+			Expect(getFinalizers("unowned-deleting")).To(Equal(finalizers))
+			Expect(logs.String()).NotTo(ContainSubstring("Force-removed"))
+		})
+		It("removes finalizers of any object being deleted with nil owner labels", func() {
+			Expect(c.DoRemoveFinalizers(ctx, nil,
+				newConfigMap("memcached", "owned-live"),
+				newConfigMap("memcached", "unowned-deleting"),
+			)).To(Succeed())
+			Expect(getFinalizers("owned-live")).To(Equal(finalizers))
+			Expect(getFinalizers("unowned-deleting")).To(BeEmpty())
+		})
+	})
+})
+
+// newFinalizedConfigMap returns a ConfigMap with labels and a finalizer, marked for deletion if deleting is true.
+func newFinalizedConfigMap(name string, labels map[string]string, deleting bool) *corev1.ConfigMap {
+	cm := newConfigMap("memcached", name)
+	cm.SetLabels(labels)
+	cm.SetFinalizers([]string{"example.com/cleanup"})
+	if deleting {
+		now := metav1.Now()
+		cm.SetDeletionTimestamp(&now)
+	}
+	return cm
+}
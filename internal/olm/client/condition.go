@@ -0,0 +1,141 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ConditionWaitFormat is the format of a condition parsed by ParseConditionWait.
+const ConditionWaitFormat = "<apiVersion>/<kind>/<name>:<conditionType>=<status>"
+
+// WaitForConditionUsage is the usage of a --wait-for-condition flag.
+const WaitForConditionUsage = "After the operator's CSV succeeds, wait within --timeout for an object in the operator " +
+	"namespace, or a cluster-scoped object, to have a status condition, ex. an operator-specific readiness condition. " +
+	"Format: " + ConditionWaitFormat + ". May be set more than once"
+
+// ConditionWait is a condition in an object's status.conditions to wait for.
+type ConditionWait struct {
+	GVK    schema.GroupVersionKind
+	Name   string
+	Type   string
+	Status string
+}
+
+func (c ConditionWait) String() string {
+	return fmt.Sprintf("%s %q condition %s=%s", c.GVK.Kind, c.Name, c.Type, c.Status)
+}
+
+// ParseConditionWait parses s, of the form ConditionWaitFormat,
+// ex. "cache.example.com/v1alpha1/MemcachedHealth/cluster:Ready=True".
+func ParseConditionWait(s string) (c ConditionWait, err error) {
+	split := strings.SplitN(s, ":", 2)
+	if len(split) != 2 {
+		return c, fmt.Errorf("condition %q must be of the form %s", s, ConditionWaitFormat)
+	}
+	ref, cond := strings.Split(split[0], "/"), strings.SplitN(split[1], "=", 2)
+	// The apiVersion of a core object like v1 has no group.
+	if (len(ref) != 3 && len(ref) != 4) || len(cond) != 2 {
+		return c, fmt.Errorf("condition %q must be of the form %s", s, ConditionWaitFormat)
+	}
+	n := len(ref)
+	gv, err := schema.ParseGroupVersion(strings.Join(ref[:n-2], "/"))
+	if err != nil {
+		return c, fmt.Errorf("condition %q has an invalid apiVersion: %v", s, err)
+	}
+	c = ConditionWait{GVK: gv.WithKind(ref[n-2]), Name: ref[n-1], Type: cond[0], Status: cond[1]}
+	if c.GVK.Version == "" || c.GVK.Kind == "" || c.Name == "" || c.Type == "" || c.Status == "" {
+		return ConditionWait{}, fmt.Errorf("condition %q must be of the form %s", s, ConditionWaitFormat)
+	}
+	if errs := validation.IsDNS1123Subdomain(c.Name); len(errs) != 0 {
+		return ConditionWait{}, fmt.Errorf("condition %q has an invalid name: %s", s, strings.Join(errs, ", "))
+	}
+	return c, nil
+}
+
+// DoConditionWait polls the object referenced by cond in namespace, which is ignored for cluster-scoped
+// objects, until it has cond's condition or ctx is done. If ctx is done first, the returned error
+// describes the last observed condition.
+func (c Client) DoConditionWait(ctx context.Context, namespace string, cond ConditionWait) error {
+	key := types.NamespacedName{Namespace: namespace, Name: cond.Name}
+	var last string
+
+	hasCondition := func() (bool, error) {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(cond.GVK)
+		if err := c.KubeClient.Get(ctx, key, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				last = "object not found"
+				return false, nil
+			}
+			return false, err
+		}
+		status, observed, found, err := getCondition(obj, cond.Type)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			observed = fmt.Sprintf("no %s condition", cond.Type)
+		}
+		if observed != last {
+			last = observed
+			log.Printf("  Found %s %q: %s", cond.GVK.Kind, key, last)
+		}
+		return found && status == cond.Status, nil
+	}
+
+	err := wait.PollImmediateUntil(time.Second, hasCondition, ctx.Done())
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for %s, last observed: %s", cond, last)
+	}
+	return err
+}
+
+// getCondition returns the status of obj's conditionType condition in status.conditions, a description
+// of it as "<type>=<status>" followed by its reason and message if set, and whether it was found.
+func getCondition(obj *unstructured.Unstructured, conditionType string) (status, observed string, found bool, err error) {
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return "", "", false, fmt.Errorf("error reading status.conditions of %s %q: %v", obj.GetKind(), obj.GetName(), err)
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		status = fmt.Sprint(condition["status"])
+		observed = conditionType + "=" + status
+		if reason, ok := condition["reason"].(string); ok && reason != "" {
+			observed += fmt.Sprintf(" (reason: %s)", reason)
+		}
+		if message, ok := condition["message"].(string); ok && message != "" {
+			observed += ": " + message
+		}
+		return status, observed, true, nil
+	}
+	return "", "", false, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Condition waits", func() {
+	Describe("ParseConditionWait", func() {
+		It("parses conditions of grouped and core objects", func() {
+			c, err := ParseConditionWait("cache.example.com/v1alpha1/MemcachedHealth/cluster:Ready=True")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal(ConditionWait{
+				GVK:    schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "MemcachedHealth"},
+				Name:   "cluster",
+				Type:   "Ready",
+				Status: "True",
+			}))
+			Expect(c.String()).To(Equal(`MemcachedHealth "cluster" condition Ready=True`))
+
+			c, err = ParseConditionWait("v1/Node/worker-0:MemoryPressure=False")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.GVK).To(Equal(schema.GroupVersionKind{Version: "v1", Kind: "Node"}))
+		})
+		It("returns an error for a malformed condition", func() {
+			for _, s := range []string{
+				"cache.example.com/v1alpha1/MemcachedHealth/cluster",
+				"MemcachedHealth/cluster:Ready=True",
+				"cache.example.com/v1alpha1/MemcachedHealth/cluster:Ready",
+				"cache.example.com/v1alpha1/MemcachedHealth/:Ready=True",
+				"cache.example.com/v1alpha1/MemcachedHealth/cluster:=True",
+			} {
+				_, err := ParseConditionWait(s)
+				Expect(err).To(MatchError(ContainSubstring("must be of the form " + ConditionWaitFormat)))
+			}
+			_, err := ParseConditionWait("cache.example.com/v1alpha1/MemcachedHealth/Cluster:Ready=True")
+			Expect(err).To(MatchError(ContainSubstring("has an invalid name")))
+		})
+	})
+
+	Describe("DoConditionWait", func() {
+		var (
+			dep  *appsv1.Deployment
+			cond ConditionWait
+		)
+
+		BeforeEach(func() {
+			dep = &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "memcached", Name: "memcached-operator"},
+			}
+			var err error
+			cond, err = ParseConditionWait("apps/v1/Deployment/memcached-operator:Available=True")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns once the object has the condition", func() {
+			dep.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+			c := Client{KubeClient: fake.NewFakeClientWithScheme(scheme.Scheme, dep)}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			Expect(c.DoConditionWait(ctx, "memcached", cond)).To(Succeed())
+		})
+		It("reports the last observed condition on timeout", func() {
+			dep.Status.Conditions = []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentAvailable,
+				Status:  corev1.ConditionFalse,
+				Reason:  "MinimumReplicasUnavailable",
+				Message: "Deployment does not have minimum availability.",
+			}}
+			c := Client{KubeClient: fake.NewFakeClientWithScheme(scheme.Scheme, dep)}
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			Expect(c.DoConditionWait(ctx, "memcached", cond)).To(MatchError(
+				`timed out waiting for Deployment "memcached-operator" condition Available=True, last observed: ` +
+					`Available=False (reason: MinimumReplicasUnavailable): Deployment does not have minimum availability.`))
+		})
+		It("reports a missing condition or object on timeout", func() {
+			c := Client{KubeClient: fake.NewFakeClientWithScheme(scheme.Scheme, dep)}
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			Expect(c.DoConditionWait(ctx, "memcached", cond)).To(MatchError(ContainSubstring("last observed: no Available condition")))
+
+			ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			Expect(c.DoConditionWait(ctx, "other", cond)).To(MatchError(ContainSubstring("last observed: object not found")))
+		})
+	})
+})
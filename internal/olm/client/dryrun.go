@@ -0,0 +1,156 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// Dry run strategies, set with --dry-run.
+const (
+	// DryRunNone persists all created resources.
+	DryRunNone = "none"
+	// DryRunClient prints resources that would be created without contacting
+	// the cluster.
+	DryRunClient = "client"
+	// DryRunServer submits resources to the cluster for admission without
+	// persisting them.
+	DryRunServer = "server"
+)
+
+// DryRunUsage is the usage of a --dry-run flag.
+const DryRunUsage = "Preview resources that would be created without persisting them. One of: [none, client, server]. " +
+	"\"client\" prints resources without contacting the cluster, and \"server\" submits resources " +
+	"to the cluster for validation and admission"
+
+// DryRun configures a Client to create objects without persisting them.
+type DryRun struct {
+	// Strategy is DryRunClient or DryRunServer.
+	Strategy string
+	// Out is where DryRunClient objects are printed. Defaults to stdout.
+	Out io.Writer
+
+	// Namespaces and custom resource kinds created earlier in this dry run,
+	// which do not exist for objects that require them.
+	namespaces map[string]bool
+	groupKinds map[schema.GroupKind]bool
+}
+
+// NewDryRun returns a DryRun for strategy, or nil if strategy is DryRunNone or empty.
+func NewDryRun(strategy string) (*DryRun, error) {
+	switch strategy {
+	case "", DryRunNone:
+		return nil, nil
+	case DryRunClient, DryRunServer:
+		return &DryRun{
+			Strategy:   strategy,
+			Out:        os.Stdout,
+			namespaces: map[string]bool{},
+			groupKinds: map[schema.GroupKind]bool{},
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid dry run strategy %q: must be one of [%s, %s, %s]",
+		strategy, DryRunNone, DryRunClient, DryRunServer)
+}
+
+// IsClient returns true if d renders objects client-side only.
+func (d *DryRun) IsClient() bool {
+	return d != nil && d.Strategy == DryRunClient
+}
+
+// doCreate creates obj according to d's strategy.
+func (d *DryRun) doCreate(ctx context.Context, cl client.Client, obj runtime.Object) error {
+	a, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	name := getName(a.GetNamespace(), a.GetName())
+
+	if d.Strategy == DryRunClient {
+		b, err := k8sutil.GetObjectBytes(obj, yaml.Marshal)
+		if err != nil {
+			return err
+		}
+		log.Infof("  Would create %s %q (client dry run)", kind, name)
+		_, err = fmt.Fprintf(d.Out, "---\n%s", b)
+		return err
+	}
+
+	if err := cl.Create(ctx, obj, client.DryRunAll); err != nil {
+		switch {
+		case apierrors.IsAlreadyExists(err):
+			log.Infof("    %s %q already exists", kind, name)
+			return nil
+		case d.requiresCreated(obj, err):
+			// The object depends on a namespace or CRD that was only validated
+			// by this dry run, so the server cannot admit it.
+			log.Infof("  Would create %s %q (server dry run, not validated: depends on resources created by this dry run)",
+				kind, name)
+			return nil
+		}
+		return err
+	}
+	log.Infof("  Would create %s %q (server dry run)", kind, name)
+	return d.record(obj)
+}
+
+// requiresCreated returns true if err from creating obj was caused by obj's
+// namespace or custom resource kind having been created only by this dry run.
+func (d *DryRun) requiresCreated(obj runtime.Object, err error) bool {
+	if meta.IsNoMatchError(err) {
+		return d.groupKinds[obj.GetObjectKind().GroupVersionKind().GroupKind()]
+	}
+	if apierrors.IsNotFound(err) {
+		a, aerr := meta.Accessor(obj)
+		return aerr == nil && d.namespaces[a.GetNamespace()]
+	}
+	return false
+}
+
+// record records obj if other objects may depend on it.
+func (d *DryRun) record(obj runtime.Object) error {
+	switch obj.GetObjectKind().GroupVersionKind().Kind {
+	case "Namespace":
+		a, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		d.namespaces[a.GetName()] = true
+	case "CustomResourceDefinition":
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		group, _, _ := unstructured.NestedString(u, "spec", "group")
+		kind, _, _ := unstructured.NestedString(u, "spec", "names", "kind")
+		d.groupKinds[schema.GroupKind{Group: group, Kind: kind}] = true
+	}
+	return nil
+}
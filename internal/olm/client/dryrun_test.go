@@ -0,0 +1,182 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("DryRun", func() {
+	Describe("NewDryRun", func() {
+		It("returns nil when not dry running", func() {
+			for _, s := range []string{"", DryRunNone} {
+				d, err := NewDryRun(s)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(d).To(BeNil())
+				Expect(d.IsClient()).To(BeFalse())
+			}
+		})
+		It("returns a dry run for client and server strategies", func() {
+			d, err := NewDryRun(DryRunClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.IsClient()).To(BeTrue())
+			d, err = NewDryRun(DryRunServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.IsClient()).To(BeFalse())
+		})
+		It("returns an error for an invalid strategy", func() {
+			_, err := NewDryRun("all")
+			Expect(err).To(MatchError(ContainSubstring(`invalid dry run strategy "all"`)))
+		})
+	})
+
+	Describe("DoCreate", func() {
+		var (
+			ctx context.Context
+			c   Client
+			kc  *dryRunClient
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			kc = &dryRunClient{errs: map[string]error{}}
+			c = Client{KubeClient: kc}
+		})
+
+		It("prints objects without contacting the cluster in a client dry run", func() {
+			var err error
+			c.KubeClient = nil
+			c.DryRun, err = NewDryRun(DryRunClient)
+			Expect(err).NotTo(HaveOccurred())
+			out := &bytes.Buffer{}
+			c.DryRun.Out = out
+
+			Expect(c.DoCreate(ctx, newNamespace("memcached"), newConfigMap("memcached", "config"))).To(Succeed())
+			Expect(out.String()).To(Equal(`---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: memcached
+spec: {}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: memcached
+`))
+		})
+		It("submits objects with DryRunAll in a server dry run", func() {
+			var err error
+			c.DryRun, err = NewDryRun(DryRunServer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.DoCreate(ctx, newNamespace("memcached"), newConfigMap("memcached", "config"))).To(Succeed())
+			Expect(kc.created).To(Equal([]string{"memcached", "config"}))
+			Expect(kc.dryRun).To(ConsistOf([]string{metav1.DryRunAll}, []string{metav1.DryRunAll}))
+		})
+		It("tolerates objects depending on resources created by the server dry run", func() {
+			var err error
+			c.DryRun, err = NewDryRun(DryRunServer)
+			Expect(err).NotTo(HaveOccurred())
+			kc.errs["config"] = apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "memcached")
+			kc.errs["example"] = &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "cache.example.com", Kind: "Memcached"}}
+
+			Expect(c.DoCreate(ctx,
+				newNamespace("memcached"),
+				newCRD("cache.example.com", "Memcached"),
+				newConfigMap("memcached", "config"),
+				newCR("memcached", "example"),
+			)).To(Succeed())
+		})
+		It("returns errors for objects not depending on resources created by the server dry run", func() {
+			var err error
+			c.DryRun, err = NewDryRun(DryRunServer)
+			Expect(err).NotTo(HaveOccurred())
+			kc.errs["config"] = apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "memcached")
+			Expect(c.DoCreate(ctx, newConfigMap("memcached", "config"))).To(MatchError(ContainSubstring("not found")))
+
+			kc.errs["example"] = &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "cache.example.com", Kind: "Memcached"}}
+			Expect(c.DoCreate(ctx, newCR("memcached", "example"))).To(MatchError(ContainSubstring("no matches for kind")))
+		})
+	})
+})
+
+// dryRunClient records created objects' names and dry run options, and
+// returns errs by object name.
+type dryRunClient struct {
+	client.Client
+	errs    map[string]error
+	created []string
+	dryRun  [][]string
+}
+
+func (c *dryRunClient) Create(_ context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	a, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if err := c.errs[a.GetName()]; err != nil {
+		return err
+	}
+	c.created = append(c.created, a.GetName())
+	c.dryRun = append(c.dryRun, (&client.CreateOptions{}).ApplyOptions(opts).DryRun)
+	return nil
+}
+
+func newNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+}
+
+func newConfigMap(namespace, name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+}
+
+func newCRD(group, kind string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apiextensions.k8s.io/v1")
+	u.SetKind("CustomResourceDefinition")
+	u.SetName("memcacheds." + group)
+	Expect(unstructured.SetNestedField(u.Object, group, "spec", "group")).To(Succeed())
+	Expect(unstructured.SetNestedField(u.Object, kind, "spec", "names", "kind")).To(Succeed())
+	return u
+}
+
+func newCR(namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("cache.example.com/v1alpha1")
+	u.SetKind("Memcached")
+	u.SetName(name)
+	u.SetNamespace(namespace)
+	return u
+}
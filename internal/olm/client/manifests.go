@@ -0,0 +1,112 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// OutputManifestsUsage is the usage of an --output-manifests flag.
+const OutputManifestsUsage = "Directory to write the manifests of all created resources to, one file per resource, " +
+	"for applying with kubectl or committing to a GitOps repository. The directory must not exist or be empty"
+
+// ManifestsOnlyUsage is the usage of a --manifests-only flag.
+const ManifestsOnlyUsage = "Write manifests to the --output-manifests directory without creating any resources " +
+	"or contacting the cluster"
+
+// ephemeralMetadataFields are metadata fields set by the cluster, which must
+// not be present in a manifest that is applied elsewhere.
+var ephemeralMetadataFields = []string{
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+	"managedFields",
+	"selfLink",
+}
+
+// ManifestsWriter writes the manifests of objects created by a Client to a
+// directory. Files are prefixed by creation order, so applying the directory
+// with "kubectl apply -f" creates namespaces and custom resource definitions
+// before the objects that depend on them.
+type ManifestsWriter struct {
+	// Dir is the directory manifests are written to. Dir is created if it
+	// does not exist.
+	Dir string
+
+	// count is the number of manifests written so far.
+	count int
+}
+
+// CheckManifestsDir returns an error if dir exists and is not an empty
+// directory, so manifests from different runs are never mixed.
+func CheckManifestsDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("output manifests path %s is not a directory", dir)
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(infos) != 0 {
+		return fmt.Errorf("output manifests directory %s is not empty", dir)
+	}
+	return nil
+}
+
+// manifest returns the manifest of obj without status or cluster-set metadata.
+func manifest(obj runtime.Object) (*unstructured.Unstructured, error) {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: m}
+	unstructured.RemoveNestedField(u.Object, "status")
+	for _, f := range ephemeralMetadataFields {
+		unstructured.RemoveNestedField(u.Object, "metadata", f)
+	}
+	return u, nil
+}
+
+// write writes u to a new file in w.Dir.
+func (w *ManifestsWriter) write(u *unstructured.Unstructured) error {
+	b, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return fmt.Errorf("error marshaling %s %q: %v", u.GetKind(), u.GetName(), err)
+	}
+	if err := os.MkdirAll(w.Dir, 0755); err != nil {
+		return err
+	}
+	w.count++
+	name := fmt.Sprintf("%03d-%s-%s.yaml", w.count, strings.ToLower(u.GetKind()), u.GetName())
+	return ioutil.WriteFile(filepath.Join(w.Dir, name), b, 0644)
+}
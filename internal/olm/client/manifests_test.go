@@ -0,0 +1,132 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ManifestsWriter", func() {
+	var (
+		ctx context.Context
+		dir string
+		c   Client
+		kc  *dryRunClient
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		dir, err = ioutil.TempDir("", "manifests-")
+		Expect(err).NotTo(HaveOccurred())
+		kc = &dryRunClient{errs: map[string]error{}}
+		c = Client{KubeClient: kc, Manifests: &ManifestsWriter{Dir: filepath.Join(dir, "out")}}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	readManifests := func() map[string]string {
+		infos, err := ioutil.ReadDir(c.Manifests.Dir)
+		Expect(err).NotTo(HaveOccurred())
+		files := map[string]string{}
+		for _, info := range infos {
+			b, err := ioutil.ReadFile(filepath.Join(c.Manifests.Dir, info.Name()))
+			Expect(err).NotTo(HaveOccurred())
+			files[info.Name()] = string(b)
+		}
+		return files
+	}
+
+	It("writes created objects in creation order", func() {
+		Expect(c.DoCreate(ctx, newNamespace("memcached"), newConfigMap("memcached", "config"))).To(Succeed())
+		Expect(kc.created).To(Equal([]string{"memcached", "config"}))
+		Expect(readManifests()).To(Equal(map[string]string{
+			"001-namespace-memcached.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: memcached
+spec: {}
+`,
+			"002-configmap-config.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: memcached
+`,
+		}))
+	})
+	It("removes status and cluster-set metadata", func() {
+		ns := newNamespace("memcached")
+		ns.SetUID("1234")
+		ns.SetResourceVersion("5")
+		ns.SetCreationTimestamp(metav1.Now())
+		ns.SetLabels(map[string]string{"app": "memcached"})
+		ns.Status.Phase = "Active"
+		Expect(c.DoCreate(ctx, ns)).To(Succeed())
+		Expect(readManifests()).To(Equal(map[string]string{
+			"001-namespace-memcached.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    app: memcached
+  name: memcached
+spec: {}
+`,
+		}))
+	})
+	It("writes objects without contacting the cluster in a client dry run", func() {
+		var err error
+		c.KubeClient = nil
+		c.DryRun, err = NewDryRun(DryRunClient)
+		Expect(err).NotTo(HaveOccurred())
+		c.DryRun.Out = ioutil.Discard
+
+		Expect(c.DoCreate(ctx, newCRD("cache.example.com", "Memcached"), newCR("memcached", "example"))).To(Succeed())
+		Expect(readManifests()).To(HaveLen(2))
+		Expect(readManifests()).To(HaveKey("001-customresourcedefinition-memcacheds.cache.example.com.yaml"))
+		Expect(readManifests()).To(HaveKey("002-memcached-example.yaml"))
+	})
+	It("does not write objects that fail to be created", func() {
+		kc.errs["config"] = os.ErrPermission
+		Expect(c.DoCreate(ctx, newConfigMap("memcached", "config"))).To(MatchError(os.ErrPermission))
+		_, err := os.Stat(c.Manifests.Dir)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	Describe("CheckManifestsDir", func() {
+		It("accepts a missing or empty directory", func() {
+			Expect(CheckManifestsDir(filepath.Join(dir, "missing"))).To(Succeed())
+			Expect(CheckManifestsDir(dir)).To(Succeed())
+		})
+		It("rejects a non-empty directory", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "old.yaml"), nil, 0644)).To(Succeed())
+			Expect(CheckManifestsDir(dir)).To(MatchError(ContainSubstring("is not empty")))
+		})
+		It("rejects a file", func() {
+			f := filepath.Join(dir, "file")
+			Expect(ioutil.WriteFile(f, nil, 0644)).To(Succeed())
+			Expect(CheckManifestsDir(f)).To(MatchError(ContainSubstring("is not a directory")))
+		})
+	})
+})
@@ -23,6 +23,8 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	olmresourceclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 )
 
 const (
@@ -37,11 +39,22 @@ type Manager struct {
 	Version      string
 	Timeout      time.Duration
 	OLMNamespace string
-	once         sync.Once
+	// DryRun is a dry run strategy for Install, ex. olmresourceclient.DryRunServer.
+	DryRun string
+	once   sync.Once
 }
 
 func (m *Manager) initialize() (err error) {
 	m.once.Do(func() {
+		dryRun, derr := olmresourceclient.NewDryRun(m.DryRun)
+		if derr != nil {
+			err = derr
+			return
+		}
+		if m.Client == nil && dryRun.IsClient() {
+			// Client-side dry runs only render resources, so no cluster is needed.
+			m.Client = newClient(&olmresourceclient.Client{})
+		}
 		if m.Client == nil {
 			cfg, cerr := config.GetConfig()
 			if cerr != nil {
@@ -56,6 +69,7 @@ func (m *Manager) initialize() (err error) {
 			}
 			m.Client = client
 		}
+		m.Client.DryRun = dryRun
 		if m.Timeout <= 0 {
 			m.Timeout = DefaultTimeout
 		}
@@ -78,6 +92,10 @@ func (m *Manager) Install() error {
 	if err != nil {
 		return err
 	}
+	if m.Client.DryRun != nil {
+		log.Infof("Dry run of OLM version %q installation complete, no resources were persisted", m.Version)
+		return nil
+	}
 
 	log.Infof("Successfully installed OLM version %q", m.Version)
 	fmt.Print("\n")
@@ -148,6 +148,10 @@ func (rr *RegistryResources) CreatePackageManifestsRegistry(ctx context.Context,
 	if err := rr.Client.DoCreate(ctx, objs...); err != nil {
 		return fmt.Errorf("error creating operator %q registry-server objects: %w", pkgName, err)
 	}
+	// Dry run objects are not persisted, so will never roll out.
+	if rr.Client.DryRun != nil {
+		return nil
+	}
 
 	// Wait for registry Deployment rollout.
 	depKey := types.NamespacedName{
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"path"
 	"strings"
 	"text/template"
@@ -27,10 +28,12 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/flags"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 
+	"github.com/docker/distribution/reference"
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 )
@@ -44,8 +47,14 @@ const (
 	// ReplacesBundleAddMode - bundle add mode for replaces
 	ReplacesBundleAddMode BundleAddModeType = "replaces"
 )
+
+// DefaultIndexBaseImage is the image registry pods run by default. It is pinned so the opm
+// commands the pod runs, "opm registry add" with --mode and "opm registry serve", behave the
+// same across runs; a replacement base must provide a compatible opm binary at /bin/opm,
+// from an operator-registry release no older than v1.6.0, which added --mode.
+const DefaultIndexBaseImage = "quay.io/operator-framework/upstream-opm-builder:v1.14.3"
+
 const (
-	defaultIndexImage        = "quay.io/operator-framework/upstream-opm-builder:latest"
 	defaultContainerName     = "registry-grpc"
 	defaultContainerPortName = "grpc"
 	defaultGRPCPort          = 50051
@@ -61,7 +70,9 @@ type RegistryPod struct {
 	BundleImage string
 
 	// Index image contains a database of pointers to operator manifest content that is queriable via an API.
-	// new version of an operator bundle when published can be added to an index image
+	// new version of an operator bundle when published can be added to an index image.
+	// It is the base image the registry pod runs, DefaultIndexBaseImage unless overridden,
+	// ex. by an approved mirror in a disconnected cluster
 	IndexImage string
 
 	// DBPath refers to the registry DB;
@@ -77,34 +88,40 @@ type RegistryPod struct {
 	// GRPCPort is the container grpc port which is defaulted to 50051
 	GRPCPort int32
 
+	// HostAliases are added to the pod's /etc/hosts, so the pod can resolve hosts
+	// that are not reachable by cluster DNS, ex. a registry in an air-gapped lab
+	HostAliases []corev1.HostAlias
+
 	// pod represents a kubernetes *corev1.pod that will be created on a cluster using an index image
 	pod *corev1.Pod
 }
 
-// NewRegistryPod initializes the RegistryPod struct and sets defaults for empty fields
-func NewRegistryPod(kubeclient kubernetes.Interface, dbPath, bundleImage, namespace string) (*RegistryPod, error) {
+// NewRegistryPod initializes the RegistryPod struct and sets defaults for empty fields.
+// The pod runs indexBaseImage, or DefaultIndexBaseImage if empty.
+func NewRegistryPod(kubeclient kubernetes.Interface, dbPath, bundleImage, indexBaseImage, namespace string,
+	hostAliases ...corev1.HostAlias) (*RegistryPod, error) {
 	rp := &RegistryPod{}
 
 	if rp.GRPCPort == 0 {
 		rp.GRPCPort = defaultGRPCPort
 	}
 
-	if len(strings.TrimSpace(rp.IndexImage)) < 1 {
-		rp.IndexImage = defaultIndexImage
+	rp.IndexImage = strings.TrimSpace(indexBaseImage)
+	if len(rp.IndexImage) < 1 {
+		rp.IndexImage = DefaultIndexBaseImage
 	}
 
+	// A base image has no database of its own for bundles to replace entries in,
+	// so the graph is always built in semver mode.
 	if len(strings.TrimSpace(rp.BundleAddMode)) < 1 {
-		if rp.IndexImage == defaultIndexImage {
-			rp.BundleAddMode = SemverBundleAddMode
-		} else {
-			rp.BundleAddMode = ReplacesBundleAddMode
-		}
+		rp.BundleAddMode = SemverBundleAddMode
 	}
 
 	rp.Kubeclient = kubeclient
 	rp.DBPath = dbPath
 	rp.BundleImage = bundleImage
 	rp.Namespace = namespace
+	rp.HostAliases = hostAliases
 
 	// validate the RegistryPod struct and ensure required fields are set
 	if err := rp.validate(); err != nil {
@@ -208,6 +225,10 @@ func (rp *RegistryPod) validate() error {
 		return errors.New("pod namespace cannot be empty")
 	}
 
+	if err := ValidateIndexBaseImage(rp.IndexImage); err != nil {
+		return err
+	}
+
 	if len(strings.TrimSpace(rp.BundleAddMode)) < 1 {
 		return errors.New("bundle add mode cannot be empty")
 	}
@@ -220,6 +241,45 @@ func (rp *RegistryPod) validate() error {
 	return nil
 }
 
+// ValidateIndexBaseImage returns an error if image is not a valid image reference.
+func ValidateIndexBaseImage(image string) error {
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return fmt.Errorf("invalid index base image %q: %v", image, err)
+	}
+	return nil
+}
+
+// ParseHostAliases parses values of the form "host=ip" into host aliases,
+// grouping hosts that resolve to the same IP into one alias in the order they are given.
+func ParseHostAliases(values []string) ([]corev1.HostAlias, error) {
+	var aliases []corev1.HostAlias
+	ipIndexes := map[string]int{}
+	for _, value := range values {
+		split := strings.SplitN(value, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("host alias %q must be of the form host=ip", value)
+		}
+		host, ip := strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) != 0 {
+			return nil, fmt.Errorf("host alias %q has an invalid host: %s", value, strings.Join(errs, ", "))
+		}
+		parsedIP := net.ParseIP(ip)
+		if parsedIP == nil {
+			return nil, fmt.Errorf("host alias %q has an invalid IP address %q", value, ip)
+		}
+		ip = parsedIP.String()
+
+		i, hasIP := ipIndexes[ip]
+		if !hasIP {
+			i = len(aliases)
+			ipIndexes[ip] = i
+			aliases = append(aliases, corev1.HostAlias{IP: ip})
+		}
+		aliases[i].Hostnames = append(aliases[i].Hostnames, host)
+	}
+	return aliases, nil
+}
+
 // getPodName will return a string constructed from the bundle Image name
 func getPodName(bundleImage string) string {
 	// todo(rashmigottipati): need to come up with human-readable references
@@ -243,6 +303,7 @@ func (rp *RegistryPod) podForBundleRegistry() (*corev1.Pod, error) {
 			Namespace: rp.Namespace,
 		},
 		Spec: corev1.PodSpec{
+			HostAliases: rp.HostAliases,
 			Containers: []corev1.Container{
 				{
 					Name:  defaultContainerName,
@@ -21,6 +21,8 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
@@ -49,7 +51,7 @@ var _ = Describe("RegistryPod", func() {
 			var err error
 
 			BeforeEach(func() {
-				rp, err = NewRegistryPod(newFakeClient(), "/database/index.db", "quay.io/example/example-operator-bundle:0.2.0", "default")
+				rp, err = NewRegistryPod(newFakeClient(), "/database/index.db", "quay.io/example/example-operator-bundle:0.2.0", "", "default")
 				Expect(err).To(BeNil())
 			})
 
@@ -92,6 +94,36 @@ var _ = Describe("RegistryPod", func() {
 				}
 			})
 
+			It("should add host aliases to the pod spec", func() {
+				aliases := []corev1.HostAlias{{IP: "10.0.0.1", Hostnames: []string{"registry.lab"}}}
+				rp, err := NewRegistryPod(newFakeClient(), "/database/index.db",
+					"quay.io/example/example-operator-bundle:0.2.0", "", "default", aliases...)
+				Expect(err).To(BeNil())
+				Expect(rp.pod.Spec.HostAliases).To(Equal(aliases))
+
+				Expect(rp.Create(context.Background())).To(Succeed())
+				pod, err := rp.Kubeclient.CoreV1().Pods("default").Get(context.Background(), expectedPodName, metav1.GetOptions{})
+				Expect(err).To(BeNil())
+				Expect(pod.Spec.HostAliases).To(Equal(aliases))
+			})
+
+			It("should run the pinned default index base image", func() {
+				Expect(rp.IndexImage).To(Equal(DefaultIndexBaseImage))
+				Expect(rp.pod.Spec.Containers[0].Image).To(Equal(DefaultIndexBaseImage))
+				Expect(rp.BundleAddMode).To(Equal(SemverBundleAddMode))
+			})
+
+			It("should run a custom index base image", func() {
+				base := "registry.lab:5000/mirror/upstream-opm-builder@sha256:" +
+					"4f2d7a3c1dbe6d0e3f7bd2637b7e8d3b4a4e1f9d2c6b8a7e5f3d1c9b7a5e3f1d"
+				rp, err := NewRegistryPod(newFakeClient(), "/database/index.db",
+					"quay.io/example/example-operator-bundle:0.2.0", base, "default")
+				Expect(err).To(BeNil())
+				Expect(rp.IndexImage).To(Equal(base))
+				Expect(rp.pod.Spec.Containers[0].Image).To(Equal(base))
+				Expect(rp.BundleAddMode).To(Equal(SemverBundleAddMode))
+			})
+
 			It("should create registry pod successfully", func() {
 				err := rp.Create(context.Background())
 
@@ -115,7 +147,17 @@ var _ = Describe("RegistryPod", func() {
 				expectedErr := "bundle image cannot be empty"
 
 				_, err := NewRegistryPod(newFakeClient(), "/database/index.db",
-					"", "default")
+					"", "", "default")
+
+				Expect(err).NotTo(BeNil())
+				Expect(err.Error()).Should(ContainSubstring(expectedErr))
+			})
+
+			It("should not create a registry pod with an invalid index base image", func() {
+				expectedErr := `invalid index base image "quay.io/Example/opm:latest"`
+
+				_, err := NewRegistryPod(newFakeClient(), "/database/index.db",
+					"quay.io/example/example-operator-bundle:0.2.0", "quay.io/Example/opm:latest", "default")
 
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).Should(ContainSubstring(expectedErr))
@@ -125,7 +167,7 @@ var _ = Describe("RegistryPod", func() {
 				expectedErr := "namespace cannot be empty"
 
 				_, err := NewRegistryPod(newFakeClient(), "/database/index.db",
-					"quay.io/example/example-operator-bundle:0.2.0", "")
+					"quay.io/example/example-operator-bundle:0.2.0", "", "")
 
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).Should(ContainSubstring(expectedErr))
@@ -135,7 +177,7 @@ var _ = Describe("RegistryPod", func() {
 				expectedErr := "registry database path cannot be empty"
 
 				_, err := NewRegistryPod(newFakeClient(), "",
-					"quay.io/example/example-operator-bundle:0.2.0", "default")
+					"quay.io/example/example-operator-bundle:0.2.0", "", "default")
 
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).Should(ContainSubstring(expectedErr))
@@ -145,7 +187,7 @@ var _ = Describe("RegistryPod", func() {
 				expectedErr := "bundle add mode cannot be empty"
 
 				rp, _ := NewRegistryPod(newFakeClient(), "/database/index.db",
-					"quay.io/example/example-operator-bundle:0.2.0", "default")
+					"quay.io/example/example-operator-bundle:0.2.0", "", "default")
 				rp.BundleAddMode = ""
 
 				err := rp.validate()
@@ -157,7 +199,7 @@ var _ = Describe("RegistryPod", func() {
 				expectedErr := "invalid bundle mode"
 
 				rp, _ := NewRegistryPod(newFakeClient(), "/database/index.db",
-					"quay.io/example/example-operator-bundle:0.2.0", "default")
+					"quay.io/example/example-operator-bundle:0.2.0", "", "default")
 				rp.BundleAddMode = "invalid"
 
 				err := rp.validate()
@@ -167,7 +209,7 @@ var _ = Describe("RegistryPod", func() {
 
 			It("checkPodStatus should return error when pod check is false and context is done", func() {
 				rp, _ := NewRegistryPod(newFakeClient(), "/database/index.db",
-					"quay.io/example/example-operator-bundle:0.2.0", "default")
+					"quay.io/example/example-operator-bundle:0.2.0", "", "default")
 
 				mockBadPodCheck := wait.ConditionFunc(func() (done bool, err error) {
 					return false, fmt.Errorf("error waiting for registry pod")
@@ -207,4 +249,35 @@ var _ = Describe("RegistryPod", func() {
 			// todo(rashmigottipati): add test to check VerifyPodRunning returning error
 		})
 	})
+
+	Describe("parsing host aliases", func() {
+		It("should group hosts by IP", func() {
+			aliases, err := ParseHostAliases([]string{"registry.lab=10.0.0.1", "mirror.lab=fd00::1", "quay.lab=10.0.0.1"})
+			Expect(err).To(BeNil())
+			Expect(aliases).To(Equal([]corev1.HostAlias{
+				{IP: "10.0.0.1", Hostnames: []string{"registry.lab", "quay.lab"}},
+				{IP: "fd00::1", Hostnames: []string{"mirror.lab"}},
+			}))
+		})
+
+		It("should return no aliases for no values", func() {
+			aliases, err := ParseHostAliases(nil)
+			Expect(err).To(BeNil())
+			Expect(aliases).To(BeEmpty())
+		})
+
+		It("should error on invalid values", func() {
+			for value, expectedErr := range map[string]string{
+				"registry.lab":           "must be of the form host=ip",
+				"Registry_Lab=10.0.0.1":  "invalid host",
+				"=10.0.0.1":              "invalid host",
+				"registry.lab=10.0.0":    "invalid IP address",
+				"registry.lab=localhost": "invalid IP address",
+			} {
+				_, err := ParseHostAliases([]string{value})
+				Expect(err).NotTo(BeNil(), value)
+				Expect(err.Error()).Should(ContainSubstring(expectedErr), value)
+			}
+		})
+	})
 })
@@ -22,12 +22,23 @@ import (
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	internalregistry "github.com/operator-framework/operator-sdk/internal/olm/operator/internal"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
 
 // General OperatorGroup for operators created with the SDK.
 const sdkOperatorGroupName = "operator-sdk-og"
 
+// makeSDKLabels returns a copy of the labels identifying OLM objects created
+// by the SDK, which are safe to force-delete during cleanup.
+func makeSDKLabels() map[string]string {
+	labels := make(map[string]string, len(internalregistry.SDKLabels))
+	for k, v := range internalregistry.SDKLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
 func getSubscriptionName(csvName string) string {
 	name := k8sutil.FormatOperatorNameDNS1123(csvName)
 	return fmt.Sprintf("%s-sub", name)
@@ -81,6 +92,7 @@ func newSubscription(csvName, namespace string,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      getSubscriptionName(csvName),
 			Namespace: namespace,
+			Labels:    makeSDKLabels(),
 		},
 	}
 	for _, opt := range opts {
@@ -116,6 +128,7 @@ func newCatalogSource(pkgName, namespace string,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      getCatalogSourceName(pkgName),
 			Namespace: namespace,
+			Labels:    makeSDKLabels(),
 		},
 		Spec: operatorsv1alpha1.CatalogSourceSpec{
 			DisplayName: pkgName,
@@ -153,6 +166,7 @@ func newSDKOperatorGroup(namespace string, opts ...func(*operatorsv1.OperatorGro
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      sdkOperatorGroupName,
 			Namespace: namespace,
+			Labels:    makeSDKLabels(),
 		},
 	}
 	for _, opt := range opts {
@@ -17,6 +17,7 @@ package olm
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -84,6 +85,19 @@ type OperatorCmd struct {
 	Timeout time.Duration
 	// ForceRegistry forces deletion of registry resources.
 	ForceRegistry bool
+	// DryRun is a dry run strategy for resources created by Run(),
+	// ex. internalolmclient.DryRunServer. Resources are persisted if empty.
+	DryRun string
+	// OutputManifestsDir, if set, is the directory the manifests of all
+	// resources created by Run() are written to.
+	OutputManifestsDir string
+	// ManifestsOnly writes manifests to OutputManifestsDir without creating
+	// resources or contacting the cluster.
+	ManifestsOnly bool
+	// WaitForConditions are conditions, parsed by internalolmclient.ParseConditionWait,
+	// that objects must have before an installed operator is reported as running,
+	// ex. a condition of an operator-specific health custom resource.
+	WaitForConditions []string
 
 	once sync.Once
 }
@@ -104,11 +118,32 @@ func (c *OperatorCmd) AddToFlagSet(fs *pflag.FlagSet) {
 }
 
 func (c *OperatorCmd) validate() error {
+	if _, err := internalolmclient.NewDryRun(c.DryRun); err != nil {
+		return err
+	}
+	if c.ManifestsOnly {
+		if c.OutputManifestsDir == "" {
+			return errors.New("an output manifests directory must be set to only write manifests")
+		}
+		if c.DryRun != "" && c.DryRun != internalolmclient.DryRunNone {
+			return errors.New("a dry run cannot be combined with only writing manifests")
+		}
+	}
+	if c.OutputManifestsDir != "" {
+		if err := internalolmclient.CheckManifestsDir(c.OutputManifestsDir); err != nil {
+			return err
+		}
+	}
 	if c.InstallMode != "" {
 		if _, _, err := parseInstallModeKV(c.InstallMode); err != nil {
 			return err
 		}
 	}
+	for _, cond := range c.WaitForConditions {
+		if _, err := internalolmclient.ParseConditionWait(cond); err != nil {
+			return fmt.Errorf("invalid --wait-for-condition: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -130,6 +165,7 @@ type operatorManager struct {
 	installMode      operatorsv1alpha1.InstallModeType //nolint:structcheck
 	targetNamespaces []string                          //nolint:structcheck
 	olmObjects       []runtime.Object
+	waitConditions   []internalolmclient.ConditionWait
 }
 
 func (c *OperatorCmd) newManager() (*operatorManager, error) {
@@ -151,12 +187,39 @@ func (c *OperatorCmd) newManager() (*operatorManager, error) {
 	if m.operatorNamespace = c.OperatorNamespace; m.operatorNamespace == "" {
 		m.operatorNamespace = ns
 	}
+	dryRun, err := internalolmclient.NewDryRun(c.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if c.ManifestsOnly {
+		// Manifests are rendered like a client-side dry run, but only written
+		// to OutputManifestsDir.
+		if dryRun, err = internalolmclient.NewDryRun(internalolmclient.DryRunClient); err != nil {
+			return nil, err
+		}
+		dryRun.Out = ioutil.Discard
+	}
+	if m.client == nil && dryRun.IsClient() {
+		// Client-side dry runs only render resources, so no cluster is needed.
+		m.client = &internalolmclient.Client{}
+	}
 	if m.client == nil {
 		m.client, err = internalolmclient.ClientForConfig(rc)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create SDK OLM client: %w", err)
 		}
 	}
+	m.client.DryRun = dryRun
+	for _, cond := range c.WaitForConditions {
+		wc, err := internalolmclient.ParseConditionWait(cond)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --wait-for-condition: %v", err)
+		}
+		m.waitConditions = append(m.waitConditions, wc)
+	}
+	if c.OutputManifestsDir != "" {
+		m.client.Manifests = &internalolmclient.ManifestsWriter{Dir: c.OutputManifestsDir}
+	}
 
 	return m, nil
 }
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -46,6 +47,10 @@ type PackageManifestsCmd struct {
 	// - Subscription
 	// - OperatorGroup
 	IncludePaths []string
+	// CleanupTimeout is the time to wait for each resource deleted by Cleanup()
+	// to be removed before its finalizers are removed, if it is SDK-created or
+	// the operator's CSV, to force its deletion. If zero, finalizers are never removed.
+	CleanupTimeout time.Duration
 }
 
 func (c *PackageManifestsCmd) AddToFlagSet(fs *pflag.FlagSet) {
@@ -73,6 +78,9 @@ func (c *PackageManifestsCmd) validate() error {
 	if c.OperatorVersion == "" {
 		return errors.New("operator version must be set")
 	}
+	if c.CleanupTimeout < 0 {
+		return errors.New("cleanup timeout must not be negative")
+	}
 
 	return c.OperatorCmd.validate()
 }
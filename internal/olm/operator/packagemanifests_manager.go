@@ -18,14 +18,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	internalregistry "github.com/operator-framework/operator-sdk/internal/olm/operator/internal"
 )
@@ -33,16 +36,18 @@ import (
 type packageManifestsManager struct {
 	*operatorManager
 
-	version       string
-	forceRegistry bool
-	pkg           *apimanifests.PackageManifest
-	bundles       []*apimanifests.Bundle
+	version        string
+	forceRegistry  bool
+	cleanupTimeout time.Duration
+	pkg            *apimanifests.PackageManifest
+	bundles        []*apimanifests.Bundle
 }
 
 func (c *PackageManifestsCmd) newManager() (m *packageManifestsManager, err error) {
 	m = &packageManifestsManager{
-		version:       c.OperatorVersion,
-		forceRegistry: c.ForceRegistry,
+		version:        c.OperatorVersion,
+		forceRegistry:  c.ForceRegistry,
+		cleanupTimeout: c.CleanupTimeout,
 	}
 	if m.operatorManager, err = c.OperatorCmd.newManager(); err != nil {
 		return nil, err
@@ -104,10 +109,15 @@ func (c *PackageManifestsCmd) newManager() (m *packageManifestsManager, err erro
 }
 
 func (m *packageManifestsManager) run(ctx context.Context) (err error) {
+	// Client-side dry runs do not contact the cluster.
+	isClientDryRun := m.client.DryRun.IsClient()
+
 	// Ensure OLM is installed.
-	olmVer, err := m.client.GetInstalledVersion(ctx, m.olmNamespace)
-	if err != nil {
-		return fmt.Errorf("error getting installed OLM version: %w", err)
+	var olmVer string
+	if !isClientDryRun {
+		if olmVer, err = m.client.GetInstalledVersion(ctx, m.olmNamespace); err != nil {
+			return fmt.Errorf("error getting installed OLM version: %w", err)
+		}
 	}
 
 	pkgName := m.pkg.PackageName
@@ -119,16 +129,18 @@ func (m *packageManifestsManager) run(ctx context.Context) (err error) {
 
 	// Only check CSV here, since other deployed operators/versions may be
 	// running with shared CRDs.
-	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(csv)
-	if err != nil {
-		return fmt.Errorf("error converting CSV to unstructured: %w", err)
-	}
-	u := unstructured.Unstructured{Object: obj}
-	status := m.status(ctx, &u)
-	if installed, err := status.HasInstalledResources(); installed {
-		return fmt.Errorf("an operator with name %q is already running\n%s", pkgName, status)
-	} else if err != nil {
-		return fmt.Errorf("an operator with name %q is present and has resource errors\n%s", pkgName, status)
+	if !isClientDryRun {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(csv)
+		if err != nil {
+			return fmt.Errorf("error converting CSV to unstructured: %w", err)
+		}
+		u := unstructured.Unstructured{Object: obj}
+		status := m.status(ctx, &u)
+		if installed, err := status.HasInstalledResources(); installed {
+			return fmt.Errorf("an operator with name %q is already running\n%s", pkgName, status)
+		} else if err != nil {
+			return fmt.Errorf("an operator with name %q is present and has resource errors\n%s", pkgName, status)
+		}
 	}
 
 	if err = m.registryUp(ctx, m.olmNamespace); err != nil {
@@ -171,6 +183,14 @@ func (m *packageManifestsManager) run(ctx context.Context) (err error) {
 	if err = m.client.DoCreate(ctx, objects...); err != nil {
 		return fmt.Errorf("error creating operator resources: %w", err)
 	}
+	if m.client.Manifests != nil {
+		log.Infof("Wrote manifests of %q installation to %s", csv.GetName(), m.client.Manifests.Dir)
+	}
+	// Nothing was persisted, so OLM will not install the CSV.
+	if m.client.DryRun != nil {
+		log.Infof("Dry run of %q installation complete, no resources were persisted", csv.GetName())
+		return nil
+	}
 
 	// BUG(estroz): if operatorNamespace is not contained in targetNamespaces,
 	// DoCSVWait will fail because the CSV is not deployed in operatorNamespace.
@@ -183,12 +203,18 @@ func (m *packageManifestsManager) run(ctx context.Context) (err error) {
 		return fmt.Errorf("error waiting for CSV to install: %w", err)
 	}
 
-	status = m.status(ctx, bundle.Objects...)
+	status := m.status(ctx, bundle.Objects...)
 	if installed, err := status.HasInstalledResources(); !installed {
 		return fmt.Errorf("operator %s did not install successfully\n%s", pkgName, status)
 	} else if err != nil {
 		return fmt.Errorf("operator %q has resource errors\n%s", pkgName, status)
 	}
+	for _, cond := range m.waitConditions {
+		log.Printf("Waiting for %s", cond)
+		if err = m.client.DoConditionWait(ctx, m.operatorNamespace, cond); err != nil {
+			return fmt.Errorf("error waiting for operator %q to be ready: %w", pkgName, err)
+		}
+	}
 	log.Infof("Successfully installed %q on OLM version %q", csv.GetName(), olmVer)
 	fmt.Print(status)
 
@@ -213,6 +239,14 @@ func (m *packageManifestsManager) cleanup(ctx context.Context) (err error) {
 		return fmt.Errorf("error removing registry resources: %w", err)
 	}
 
+	// The Subscription is deleted before the CSV, so find the CSV it installed first.
+	installedCSV := ""
+	if m.cleanupTimeout != 0 {
+		if installedCSV, err = m.getSDKInstalledCSV(ctx, getSubscriptionName(csv.GetName())); err != nil {
+			return fmt.Errorf("error getting operator Subscription: %w", err)
+		}
+	}
+
 	log.Info("Deleting resources")
 	if !m.hasCatalogSource() {
 		m.olmObjects = append(m.olmObjects, newCatalogSource(pkgName, m.operatorNamespace))
@@ -232,7 +266,7 @@ func (m *packageManifestsManager) cleanup(ctx context.Context) (err error) {
 		objc.SetNamespace(m.operatorNamespace)
 		toDelete = append(toDelete, objc)
 	}
-	if err = m.client.DoDelete(ctx, toDelete...); err != nil {
+	if err = m.deleteObjects(ctx, installedCSV, toDelete...); err != nil {
 		return fmt.Errorf("error deleting operator resources: %w", err)
 	}
 
@@ -247,6 +281,86 @@ func (m *packageManifestsManager) cleanup(ctx context.Context) (err error) {
 	return nil
 }
 
+// getSDKInstalledCSV returns the name of the CSV installed by Subscription
+// subName in the operator namespace, or an empty string if that Subscription
+// does not exist or was not created by the SDK.
+func (m *packageManifestsManager) getSDKInstalledCSV(ctx context.Context, subName string) (string, error) {
+	sub := &operatorsv1alpha1.Subscription{}
+	key := types.NamespacedName{Namespace: m.operatorNamespace, Name: subName}
+	if err := m.client.KubeClient.Get(ctx, key, sub); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for k, v := range internalregistry.SDKLabels {
+		if sub.GetLabels()[k] != v {
+			return "", nil
+		}
+	}
+	return sub.Status.InstalledCSV, nil
+}
+
+// deleteObjects deletes objs, waiting for each to be removed. If a cleanup
+// timeout is set and an object has not been removed within it, finalizers are
+// removed from that object to force its deletion if it is labeled as created
+// by the SDK or is the CSV installedCSV, installed by the SDK's Subscription.
+func (m *packageManifestsManager) deleteObjects(ctx context.Context, installedCSV string, objs ...runtime.Object) error {
+	if m.cleanupTimeout == 0 {
+		return m.client.DoDelete(ctx, objs...)
+	}
+	for _, obj := range objs {
+		if err := m.forceDeleteObject(ctx, installedCSV, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forceDeleteObject deletes obj, removing its finalizers if it has not been removed
+// within the cleanup timeout. The timeout applies to each wait for obj's removal.
+func (m *packageManifestsManager) forceDeleteObject(ctx context.Context, installedCSV string, obj runtime.Object) error {
+	err := m.deleteWithTimeout(ctx, obj)
+	if !isTimeout(err) {
+		return err
+	}
+
+	a, aerr := meta.Accessor(obj)
+	if aerr != nil {
+		return aerr
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	// OLM creates the CSV, so it does not have SDK labels. It was created for the
+	// operator only if the SDK's Subscription installed it.
+	ownerLabels := internalregistry.SDKLabels
+	if kind == operatorsv1alpha1.ClusterServiceVersionKind {
+		if a.GetName() != installedCSV {
+			log.Warnf("%s %q was not deleted within %s and was not installed by the operator's Subscription, "+
+				"leaving it stuck with its finalizers", kind, a.GetName(), m.cleanupTimeout)
+			return err
+		}
+		ownerLabels = nil
+	}
+	log.Infof("%s %q was not deleted within %s, removing its finalizers if it was created for the operator",
+		kind, a.GetName(), m.cleanupTimeout)
+	if err := m.client.DoRemoveFinalizers(ctx, ownerLabels, obj); err != nil {
+		return err
+	}
+	return m.deleteWithTimeout(ctx, obj)
+}
+
+// deleteWithTimeout deletes obj, waiting up to the cleanup timeout for it to be removed.
+func (m *packageManifestsManager) deleteWithTimeout(ctx context.Context, obj runtime.Object) error {
+	deleteCtx, cancel := context.WithTimeout(ctx, m.cleanupTimeout)
+	defer cancel()
+	return m.client.DoDelete(deleteCtx, obj)
+}
+
+// isTimeout returns true if err was returned because a wait timed out.
+func isTimeout(err error) bool {
+	return errors.Is(err, wait.ErrWaitTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
+
 func (m packageManifestsManager) registryUp(ctx context.Context, namespace string) error {
 	rr := internalregistry.RegistryResources{
 		Client:  m.client,
@@ -254,6 +368,12 @@ func (m packageManifestsManager) registryUp(ctx context.Context, namespace strin
 		Bundles: m.bundles,
 	}
 
+	// Client-side dry runs cannot check for an existing registry.
+	if m.client.DryRun.IsClient() {
+		log.Infof("Creating %s registry", m.pkg.PackageName)
+		return rr.CreatePackageManifestsRegistry(ctx, namespace)
+	}
+
 	if exists, err := rr.IsRegistryExist(ctx, namespace); err != nil {
 		return fmt.Errorf("error checking registry existence: %v", err)
 	} else if exists {
@@ -262,6 +382,10 @@ func (m packageManifestsManager) registryUp(ctx context.Context, namespace strin
 				log.Infof("%s registry data is current", m.pkg.PackageName)
 				return nil
 			}
+			if m.client.DryRun != nil {
+				log.Infof("A stale %s registry exists and would be replaced", m.pkg.PackageName)
+				return nil
+			}
 			log.Infof("A stale %s registry exists, deleting", m.pkg.PackageName)
 			if err = rr.DeletePackageManifestsRegistry(ctx, namespace); err != nil {
 				return fmt.Errorf("error deleting registered package: %w", err)
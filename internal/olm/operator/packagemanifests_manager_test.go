@@ -0,0 +1,204 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package olm
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	internalolmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	internalregistry "github.com/operator-framework/operator-sdk/internal/olm/operator/internal"
+)
+
+var _ = Describe("Package manifests manager", func() {
+	Describe("deleteObjects", func() {
+		var (
+			ctx context.Context
+			kc  client.Client
+			m   *packageManifestsManager
+		)
+
+		newManager := func(objs ...runtime.Object) {
+			kc = finalizingClient{fake.NewFakeClientWithScheme(internalolmclient.Scheme, objs...)}
+			m = &packageManifestsManager{
+				operatorManager: &operatorManager{
+					client:            &internalolmclient.Client{KubeClient: kc},
+					operatorNamespace: "memcached",
+				},
+				cleanupTimeout: 200 * time.Millisecond,
+			}
+		}
+		isDeleted := func(obj runtime.Object) bool {
+			key, err := client.ObjectKeyFromObject(obj)
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			return apierrors.IsNotFound(kc.Get(ctx, key, obj.DeepCopyObject()))
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("force-deletes each stuck SDK-created object and the CSV within its own timeout", func() {
+			sdkCM := newStuckConfigMap("registry", internalregistry.SDKLabels)
+			csv := newStuckCSV("memcached-operator.v0.0.1")
+			newManager(sdkCM, csv)
+			Expect(m.deleteObjects(ctx, csv.GetName(), sdkCM, csv)).To(Succeed())
+			Expect(isDeleted(sdkCM)).To(BeTrue())
+			Expect(isDeleted(csv)).To(BeTrue())
+		})
+		It("does not remove finalizers from a stuck unlabeled object", func() {
+			cm := newStuckConfigMap("user-config", nil)
+			newManager(cm)
+			Expect(m.deleteObjects(ctx, "", cm)).NotTo(Succeed())
+			Expect(isDeleted(cm)).To(BeFalse())
+			live := &corev1.ConfigMap{}
+			Expect(kc.Get(ctx, client.ObjectKey{Namespace: cm.GetNamespace(), Name: cm.GetName()}, live)).To(Succeed())
+			Expect(live.GetFinalizers()).To(Equal(cm.GetFinalizers()))
+		})
+		It("does not remove finalizers from a stuck CSV not installed by the SDK's Subscription", func() {
+			csv := newStuckCSV("memcached-operator.v0.0.1")
+			other := newStuckCSV("other-operator.v0.0.1")
+			newManager(csv, other)
+			Expect(m.deleteObjects(ctx, csv.GetName(), other)).NotTo(Succeed())
+			Expect(isDeleted(other)).To(BeFalse())
+			live := &operatorsv1alpha1.ClusterServiceVersion{}
+			Expect(kc.Get(ctx, client.ObjectKey{Namespace: other.GetNamespace(), Name: other.GetName()}, live)).To(Succeed())
+			Expect(live.GetFinalizers()).To(Equal(other.GetFinalizers()))
+
+			Expect(m.deleteObjects(ctx, "", csv)).NotTo(Succeed())
+			Expect(isDeleted(csv)).To(BeFalse())
+		})
+	})
+
+	Describe("getSDKInstalledCSV", func() {
+		var (
+			ctx context.Context
+			m   *packageManifestsManager
+		)
+
+		newManager := func(objs ...runtime.Object) {
+			kc := fake.NewFakeClientWithScheme(internalolmclient.Scheme, objs...)
+			m = &packageManifestsManager{
+				operatorManager: &operatorManager{
+					client:            &internalolmclient.Client{KubeClient: kc},
+					operatorNamespace: "memcached",
+				},
+			}
+		}
+		newInstalledSubscription := func(labels map[string]string) *operatorsv1alpha1.Subscription {
+			sub := newSubscription("memcached-operator.v0.0.1", "memcached")
+			sub.SetLabels(labels)
+			sub.Status.InstalledCSV = "memcached-operator.v0.0.1"
+			return sub
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("returns the CSV installed by the SDK's Subscription", func() {
+			sub := newInstalledSubscription(internalregistry.SDKLabels)
+			newManager(sub)
+			Expect(m.getSDKInstalledCSV(ctx, sub.GetName())).To(Equal("memcached-operator.v0.0.1"))
+		})
+		It("returns no CSV for a Subscription not created by the SDK", func() {
+			sub := newInstalledSubscription(nil)
+			newManager(sub)
+			Expect(m.getSDKInstalledCSV(ctx, sub.GetName())).To(BeEmpty())
+		})
+		It("returns no CSV if the Subscription does not exist", func() {
+			newManager()
+			Expect(m.getSDKInstalledCSV(ctx, "memcached-operator-v0-0-1-sub")).To(BeEmpty())
+		})
+	})
+})
+
+// finalizingClient marks objects with finalizers as being deleted instead of deleting them,
+// and deletes them once their finalizers are removed, like the API server.
+type finalizingClient struct {
+	client.Client
+}
+
+func (c finalizingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		return err
+	}
+	live := obj.DeepCopyObject()
+	if err := c.Client.Get(ctx, key, live); err != nil {
+		return err
+	}
+	a, err := meta.Accessor(live)
+	if err != nil {
+		return err
+	}
+	if len(a.GetFinalizers()) == 0 {
+		return c.Client.Delete(ctx, obj, opts...)
+	}
+	if a.GetDeletionTimestamp() == nil {
+		now := metav1.Now()
+		a.SetDeletionTimestamp(&now)
+		return c.Client.Update(ctx, live)
+	}
+	return nil
+}
+
+func (c finalizingClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	a, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if a.GetDeletionTimestamp() != nil && len(a.GetFinalizers()) == 0 {
+		return c.Client.Delete(ctx, obj)
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func newStuckConfigMap(name string, labels map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "memcached",
+			Labels:     labels,
+			Finalizers: []string{"example.com/cleanup"},
+		},
+	}
+}
+
+func newStuckCSV(name string) *operatorsv1alpha1.ClusterServiceVersion {
+	return &operatorsv1alpha1.ClusterServiceVersion{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: operatorsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       operatorsv1alpha1.ClusterServiceVersionKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "memcached",
+			Finalizers: []string{"example.com/cleanup"},
+		},
+	}
+}
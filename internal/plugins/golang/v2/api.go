@@ -15,8 +15,14 @@
 package v2
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
 	"github.com/spf13/pflag"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
 	"sigs.k8s.io/kubebuilder/pkg/plugin"
 
 	utilplugins "github.com/operator-framework/operator-sdk/internal/util/plugins"
@@ -26,12 +32,41 @@ type createAPIPlugin struct {
 	plugin.CreateAPI
 
 	config *config.Config
+	// Flags bound by the wrapped plugin, ex. --resource and --controller.
+	flagSet *pflag.FlagSet
+
+	// Import path of an existing API type to scaffold a controller for.
+	externalAPIPath string
+
+	// Exponential backoff on reconcile errors.
+	reconcileBackoff bool
+	backoffBaseDelay time.Duration
+	backoffMaxDelay  time.Duration
+
+	// Scaffold a test that reconciles a recorded fixture.
+	fixtureTest bool
 }
 
 var _ plugin.CreateAPI = &createAPIPlugin{}
 
 func (p *createAPIPlugin) UpdateContext(ctx *plugin.Context) { p.CreateAPI.UpdateContext(ctx) }
-func (p *createAPIPlugin) BindFlags(fs *pflag.FlagSet)       { p.CreateAPI.BindFlags(fs) }
+
+func (p *createAPIPlugin) BindFlags(fs *pflag.FlagSet) {
+	p.CreateAPI.BindFlags(fs)
+	fs.StringVar(&p.externalAPIPath, "external-api-path", "", "Go import path of an API type not defined "+
+		"in this project, ex. 'github.com/example/foo-operator/api/v1', to scaffold a controller for. "+
+		"Requires --resource=false")
+	fs.BoolVar(&p.reconcileBackoff, "reconcile-backoff", false, "scaffold a rate limiter into the controller "+
+		"that backs off exponentially when Reconcile returns an error")
+	fs.DurationVar(&p.backoffBaseDelay, "backoff-base-delay", defaultBackoffBaseDelay,
+		"delay before the first retry of a failed reconcile. Requires --reconcile-backoff")
+	fs.DurationVar(&p.backoffMaxDelay, "backoff-max-delay", defaultBackoffMaxDelay,
+		"maximum delay between retries of a failed reconcile. Requires --reconcile-backoff")
+	fs.BoolVar(&p.fixtureTest, "fixture-test", false, "scaffold a unit test that runs the controller's "+
+		"Reconcile once against the objects in a YAML fixture, using a fake client. The test imports "+
+		"github.com/operator-framework/operator-sdk/pkg/reconciletest")
+	p.flagSet = fs
+}
 
 func (p *createAPIPlugin) InjectConfig(c *config.Config) {
 	p.CreateAPI.InjectConfig(c)
@@ -39,10 +74,33 @@ func (p *createAPIPlugin) InjectConfig(c *config.Config) {
 }
 
 func (p *createAPIPlugin) Run() error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
 	if err := p.CreateAPI.Run(); err != nil {
 		return err
 	}
 
+	if p.externalAPIPath != "" {
+		if err := p.updateExternalAPIImport(); err != nil {
+			return err
+		}
+	}
+
+	if p.reconcileBackoff {
+		controllerPath := controllerFilePath(p.config, p.flagValue("group"), p.flagValue("kind"))
+		if err := addReconcileBackoff(controllerPath, p.backoffBaseDelay, p.backoffMaxDelay); err != nil {
+			return fmt.Errorf("error adding reconcile backoff: %v", err)
+		}
+	}
+
+	if p.fixtureTest {
+		if err := p.addFixtureTest(); err != nil {
+			return fmt.Errorf("error adding fixture test: %v", err)
+		}
+	}
+
 	// Emulate plugins phase 2 behavior by checking the config for this plugin's
 	// config object.
 	if !hasPluginConfig(p.config) {
@@ -52,6 +110,80 @@ func (p *createAPIPlugin) Run() error {
 	return p.run()
 }
 
+// validate checks that --external-api-path is only set when a controller, and
+// not a resource, is scaffolded, that --fixture-test is only set for a
+// scaffolded controller, and that backoff flags are only set with
+// --reconcile-backoff for a scaffolded controller.
+func (p *createAPIPlugin) validate() error {
+	if p.externalAPIPath != "" {
+		if !p.flagIs("resource", "false") {
+			return errors.New("--external-api-path can only be set with --resource=false")
+		}
+		if p.flagIs("controller", "false") {
+			return errors.New("--external-api-path cannot be set with --controller=false")
+		}
+	}
+
+	if p.fixtureTest && p.flagIs("controller", "false") {
+		return errors.New("--fixture-test cannot be set with --controller=false")
+	}
+
+	if !p.reconcileBackoff {
+		for _, name := range []string{"backoff-base-delay", "backoff-max-delay"} {
+			if p.flagSet.Changed(name) {
+				return fmt.Errorf("--%s can only be set with --reconcile-backoff", name)
+			}
+		}
+		return nil
+	}
+	if p.flagIs("controller", "false") {
+		return errors.New("--reconcile-backoff cannot be set with --controller=false")
+	}
+	if p.backoffBaseDelay <= 0 {
+		return errors.New("--backoff-base-delay must be greater than 0")
+	}
+	if p.backoffMaxDelay < p.backoffBaseDelay {
+		return errors.New("--backoff-max-delay must not be less than --backoff-base-delay")
+	}
+	return nil
+}
+
+// flagIs returns true if the flag name was set to value.
+func (p *createAPIPlugin) flagIs(name, value string) bool {
+	f := p.flagSet.Lookup(name)
+	return f != nil && f.Changed && f.Value.String() == value
+}
+
+// flagValue returns the value of the flag name bound by the wrapped plugin.
+func (p *createAPIPlugin) flagValue(name string) string {
+	return p.flagSet.Lookup(name).Value.String()
+}
+
+// updateExternalAPIImport points the scaffolded controller at the external
+// API type's package instead of the project's API package, and registers that
+// package's types with the manager's scheme.
+func (p *createAPIPlugin) updateExternalAPIImport() error {
+	group, version, kind := p.flagValue("group"), p.flagValue("version"), p.flagValue("kind")
+
+	controllerPath := controllerFilePath(p.config, group, kind)
+	alias, err := updateControllerImport(controllerPath, apiPackage(p.config, group, version), p.externalAPIPath)
+	if err != nil {
+		return fmt.Errorf("error importing external API %s: %v", p.externalAPIPath, err)
+	}
+	if err := updateMainScheme("main.go", alias, p.externalAPIPath); err != nil {
+		return fmt.Errorf("error registering external API %s: %v", p.externalAPIPath, err)
+	}
+	return nil
+}
+
+// addFixtureTest scaffolds a fixture test next to the scaffolded controller.
+func (p *createAPIPlugin) addFixtureTest() error {
+	opts := &resource.Options{Group: p.flagValue("group"), Version: p.flagValue("version"), Kind: p.flagValue("kind")}
+	res := opts.NewResource(p.config, p.flagValue("resource") == "true")
+	controllerDir := filepath.Dir(controllerFilePath(p.config, opts.Group, opts.Kind))
+	return addFixtureTest(controllerDir, res, p.externalAPIPath)
+}
+
 // SDK plugin-specific scaffolds.
 func (p *createAPIPlugin) run() error {
 	return utilplugins.WriteSamplesKustomization(p.config)
@@ -0,0 +1,137 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TODO: rewrite this as a kubebuilder file.Template and file.Inserter when plugins phase 2 is implemented.
+
+const (
+	// Defaults of the per-item exponential backoff of controller-runtime's
+	// default rate limiter.
+	defaultBackoffBaseDelay = 5 * time.Millisecond
+	defaultBackoffMaxDelay  = 1000 * time.Second
+
+	rateLimiterFileName = "ratelimiter.go"
+)
+
+const rateLimiterTemplate = `package controllers
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+// newReconcileRateLimiter returns a rate limiter for a controller's reconcile
+// requests. Each time Reconcile returns an error for a request, that request is
+// requeued after an exponentially increasing delay, starting at baseDelay and
+// capped at maxDelay; the delay is reset once it reconciles without error.
+//
+// Return an error from Reconcile on transient failures to back off, or
+// ctrl.Result{RequeueAfter: d} to requeue after a fixed delay d.
+func newReconcileRateLimiter(baseDelay, maxDelay time.Duration) ratelimiter.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		// Limit overall requeues to 10 qps with a burst of 100, like
+		// controller-runtime's default rate limiter.
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+`
+
+// controllerCompleteFragment is the end of the controller builder chain in
+// SetupWithManager scaffolded by kubebuilder's create api.
+const controllerCompleteFragment = "\t\tComplete(r)\n"
+
+// addReconcileBackoff configures the controller file at filePath, scaffolded
+// by kubebuilder's create api, to back off exponentially from baseDelay up to
+// maxDelay on reconcile errors, and writes the rate limiter helper it uses to
+// the controller's directory if it does not exist.
+func addReconcileBackoff(filePath string, baseDelay, maxDelay time.Duration) error {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	src, err := addControllerRateLimiter(string(b), baseDelay, maxDelay)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %v", filePath, err)
+	}
+	if err := ioutil.WriteFile(filePath, []byte(src), 0644); err != nil {
+		return err
+	}
+
+	helperPath := filepath.Join(filepath.Dir(filePath), rateLimiterFileName)
+	if _, err := os.Stat(helperPath); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return ioutil.WriteFile(helperPath, []byte(rateLimiterTemplate), 0644)
+}
+
+// addControllerRateLimiter returns the controller source src with a rate
+// limiter set in its controller options, and the imports the option needs.
+func addControllerRateLimiter(src string, baseDelay, maxDelay time.Duration) (string, error) {
+	if !strings.Contains(src, controllerCompleteFragment) {
+		return "", errors.New("controller builder not found")
+	}
+	opts := fmt.Sprintf("\t\t// Back off exponentially from %s up to %s when Reconcile returns an error.\n"+
+		"\t\tWithOptions(controller.Options{\n"+
+		"\t\t\tRateLimiter: newReconcileRateLimiter(%s, %s),\n"+
+		"\t\t}).\n", baseDelay, maxDelay, durationExpr(baseDelay), durationExpr(maxDelay))
+	src = strings.Replace(src, controllerCompleteFragment, opts+controllerCompleteFragment, 1)
+
+	for _, imp := range []struct{ after, add string }{
+		{"\t\"context\"\n", "\t\"time\"\n"},
+		{"\t\"sigs.k8s.io/controller-runtime/pkg/client\"\n", "\t\"sigs.k8s.io/controller-runtime/pkg/controller\"\n"},
+	} {
+		if !strings.Contains(src, imp.after) {
+			return "", errors.New("import block not found")
+		}
+		if !strings.Contains(src, imp.add) {
+			src = strings.Replace(src, imp.after, imp.after+imp.add, 1)
+		}
+	}
+	return src, nil
+}
+
+// durationExpr returns a Go expression for d using the largest time unit
+// that divides it, ex. "30 * time.Second".
+func durationExpr(d time.Duration) string {
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	} {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddControllerRateLimiter(t *testing.T) {
+	out, err := addControllerRateLimiter(testController, 10*time.Millisecond, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "memcached_controller.go", out, 0); err != nil {
+		t.Fatalf("Updated controller does not parse: %v\n%s", err, out)
+	}
+	for _, s := range []string{
+		"\t\"context\"\n\t\"time\"\n",
+		"\t\"sigs.k8s.io/controller-runtime/pkg/controller\"\n",
+		"\t\tWithOptions(controller.Options{\n" +
+			"\t\t\tRateLimiter: newReconcileRateLimiter(10 * time.Millisecond, 5 * time.Minute),\n" +
+			"\t\t}).\n\t\tComplete(r)\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Updated controller does not contain %q:\n%s", s, out)
+		}
+	}
+
+	if _, err := addControllerRateLimiter("package controllers\n", defaultBackoffBaseDelay, defaultBackoffMaxDelay); err == nil {
+		t.Error("Wanted error for controller without a controller builder, got none")
+	}
+}
+
+func TestRateLimiterTemplate(t *testing.T) {
+	if _, err := parser.ParseFile(token.NewFileSet(), rateLimiterFileName, rateLimiterTemplate, 0); err != nil {
+		t.Errorf("Rate limiter helper does not parse: %v", err)
+	}
+}
+
+func TestDurationExpr(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		defaultBackoffBaseDelay: "5 * time.Millisecond",
+		defaultBackoffMaxDelay:  "1000 * time.Second",
+		90 * time.Minute:        "90 * time.Minute",
+		2 * time.Hour:           "2 * time.Hour",
+		1500 * time.Microsecond: "1500 * time.Microsecond",
+		7:                       "7 * time.Nanosecond",
+	} {
+		if got := durationExpr(d); got != want {
+			t.Errorf("durationExpr(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"strings"
+)
+
+// TODO: rewrite this as a kubebuilder file.Inserter when plugins phase 2 is implemented.
+
+const (
+	// flagParseFragment is the flag parsing call in main scaffolded by
+	// kubebuilder's Init plugin.
+	flagParseFragment = "\tflag.Parse()\n"
+	// newManagerFromOptionsFragment is the manager constructor written by
+	// addWatchNamespaces.
+	newManagerFromOptionsFragment = "\tmgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)\n"
+)
+
+const cacheSyncTimeoutFlagFragment = `	var cacheSyncTimeout time.Duration
+	flag.DurationVar(&cacheSyncTimeout, "cache-sync-timeout", 0,
+		"The maximum time to wait for the manager's caches to sync at startup before failing. "+
+			"Zero waits until they sync.")
+`
+
+const cacheSyncTimeoutOptionsFragment = `	// Fail to start if the manager's caches do not sync within --cache-sync-timeout.
+	if cacheSyncTimeout > 0 {
+		options.NewCache = newCacheWithSyncTimeout(options.NewCache, cacheSyncTimeout)
+	}
+
+`
+
+const newCacheWithSyncTimeoutFragment = `
+// newCacheWithSyncTimeout returns a cache.NewCacheFunc that builds caches with
+// newCache, or cache.New if nil, that stop waiting to sync after timeout.
+func newCacheWithSyncTimeout(newCache cache.NewCacheFunc, timeout time.Duration) cache.NewCacheFunc {
+	if newCache == nil {
+		newCache = cache.New
+	}
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		c, err := newCache(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		return syncTimeoutCache{Cache: c, timeout: timeout}, nil
+	}
+}
+
+// syncTimeoutCache is a cache.Cache whose WaitForCacheSync fails after timeout.
+type syncTimeoutCache struct {
+	cache.Cache
+	timeout time.Duration
+}
+
+// WaitForCacheSync waits for the cache to sync until stop is closed or timeout
+// expires, and returns false if it did not sync.
+func (c syncTimeoutCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	syncStop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(syncStop)
+		select {
+		case <-stop:
+		case <-done:
+		case <-time.After(c.timeout):
+			setupLog.Error(nil, "timed out waiting for caches to sync", "timeout", c.timeout)
+		}
+	}()
+	return c.Cache.WaitForCacheSync(syncStop)
+}
+`
+
+// addCacheSyncTimeout returns mainStr, updated by addWatchNamespaces, with a
+// --cache-sync-timeout flag that bounds the manager's cache sync, and the
+// imports that flag needs.
+func addCacheSyncTimeout(mainStr string) (string, error) {
+	for _, s := range []string{flagParseFragment, newManagerFromOptionsFragment} {
+		if !strings.Contains(mainStr, s) {
+			return "", errors.New("manager constructor not found")
+		}
+	}
+	mainStr = strings.Replace(mainStr, flagParseFragment, cacheSyncTimeoutFlagFragment+flagParseFragment, 1)
+	mainStr = strings.Replace(mainStr, newManagerFromOptionsFragment,
+		cacheSyncTimeoutOptionsFragment+newManagerFromOptionsFragment, 1)
+	mainStr += newCacheWithSyncTimeoutFragment
+
+	for _, imp := range []struct{ after, add string }{
+		{"\t\"strings\"\n", "\t\"time\"\n"},
+		{"\tclientgoscheme \"k8s.io/client-go/kubernetes/scheme\"\n", "\t\"k8s.io/client-go/rest\"\n"},
+	} {
+		if !strings.Contains(mainStr, imp.after) {
+			return "", errors.New("import block not found")
+		}
+		if !strings.Contains(mainStr, imp.add) {
+			mainStr = strings.Replace(mainStr, imp.after, imp.after+imp.add, 1)
+		}
+	}
+	return mainStr, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestAddCacheSyncTimeout(t *testing.T) {
+	mainStr, err := addWatchNamespaces(testMain)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out, err := addCacheSyncTimeout(mainStr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", out, 0); err != nil {
+		t.Fatalf("Updated main.go does not parse: %v\n%s", err, out)
+	}
+	for _, s := range []string{
+		"\t\"strings\"\n\t\"time\"\n",
+		"\t\"k8s.io/client-go/rest\"\n",
+		"flag.DurationVar(&cacheSyncTimeout, \"cache-sync-timeout\", 0,",
+		"\t}\n\n\t// Fail to start if the manager's caches do not sync within --cache-sync-timeout.\n",
+		"options.NewCache = newCacheWithSyncTimeout(options.NewCache, cacheSyncTimeout)\n\t}\n\n" +
+			"\tmgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)\n",
+		"func (c syncTimeoutCache) WaitForCacheSync(stop <-chan struct{}) bool {",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Updated main.go does not contain %q:\n%s", s, out)
+		}
+	}
+
+	if _, err := addCacheSyncTimeout(testMain); err == nil {
+		t.Error("Wanted error for main.go without watch namespace options, got none")
+	}
+}
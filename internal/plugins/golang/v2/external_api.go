@@ -0,0 +1,114 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+// TODO: remove this file when kubebuilder's create api supports external API types.
+
+// apiPackage returns the import path of the package kubebuilder's create api
+// scaffolds group and version's API types into.
+func apiPackage(cfg *config.Config, group, version string) string {
+	if cfg.MultiGroup {
+		return path.Join(cfg.Repo, "apis", group, version)
+	}
+	return path.Join(cfg.Repo, "api", version)
+}
+
+// controllerFilePath returns the path of the controller kubebuilder's create api
+// scaffolds for group and kind.
+func controllerFilePath(cfg *config.Config, group, kind string) string {
+	fileName := strings.ToLower(kind) + "_controller.go"
+	if cfg.MultiGroup {
+		return filepath.Join("controllers", group, fileName)
+	}
+	return filepath.Join("controllers", fileName)
+}
+
+// updateControllerImport rewrites the import of apiPkg in the controller file
+// at filePath to externalPkg, so a controller scaffolded without a resource
+// reconciles an API type defined outside of the project. The import's alias
+// is returned.
+func updateControllerImport(filePath, apiPkg, externalPkg string) (string, error) {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	src, alias, err := replaceAPIImport(string(b), apiPkg, externalPkg)
+	if err != nil {
+		return "", fmt.Errorf("error updating %s: %v", filePath, err)
+	}
+	return alias, ioutil.WriteFile(filePath, []byte(src), 0644)
+}
+
+// replaceAPIImport returns src with its import of apiPkg replaced by
+// externalPkg, and the import's alias, which is kept.
+func replaceAPIImport(src, apiPkg, externalPkg string) (string, string, error) {
+	importRe := regexp.MustCompile(`(?m)^(\s*)(?:([A-Za-z_][A-Za-z0-9_]*)\s+)?"` + regexp.QuoteMeta(apiPkg) + `"`)
+	match := importRe.FindStringSubmatch(src)
+	if match == nil {
+		return "", "", fmt.Errorf("import of %q not found", apiPkg)
+	}
+	alias := match[2]
+	if alias == "" {
+		alias = path.Base(apiPkg)
+	}
+	src = importRe.ReplaceAllString(src, fmt.Sprintf("${1}%s %q", alias, externalPkg))
+	return src, alias, nil
+}
+
+// updateMainScheme registers the types in externalPkg, imported as alias, with
+// the manager's scheme in the main.go file at filePath.
+func updateMainScheme(filePath, alias, externalPkg string) error {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	src, err := addSchemeRegistration(string(b), alias, externalPkg)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %v", filePath, err)
+	}
+	return ioutil.WriteFile(filePath, []byte(src), 0644)
+}
+
+const (
+	importsMarker = "\t// +kubebuilder:scaffold:imports\n"
+	schemeMarker  = "\t// +kubebuilder:scaffold:scheme\n"
+)
+
+// addSchemeRegistration returns mainSrc with externalPkg imported as alias and
+// its AddToScheme function called on the manager's scheme, unless the package
+// is already imported.
+func addSchemeRegistration(mainSrc, alias, externalPkg string) (string, error) {
+	if strings.Contains(mainSrc, fmt.Sprintf("%q", externalPkg)) {
+		return mainSrc, nil
+	}
+	if !strings.Contains(mainSrc, importsMarker) || !strings.Contains(mainSrc, schemeMarker) {
+		return "", errors.New("scaffold markers not found")
+	}
+	mainSrc = strings.Replace(mainSrc, importsMarker, fmt.Sprintf("\t%s %q\n", alias, externalPkg)+importsMarker, 1)
+	mainSrc = strings.Replace(mainSrc, schemeMarker, fmt.Sprintf("\t_ = %s.AddToScheme(scheme)\n\n", alias)+schemeMarker, 1)
+	return mainSrc, nil
+}
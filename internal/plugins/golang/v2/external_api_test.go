@@ -0,0 +1,95 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const testController = `package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cachev1alpha1 "github.com/example/memcached-operator/api/v1alpha1"
+)
+
+func (r *MemcachedReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cachev1alpha1.Memcached{}).
+		Complete(r)
+}
+`
+
+const externalAPIPkg = "github.com/example/cache-operator/api/v1"
+
+func TestReplaceAPIImport(t *testing.T) {
+	out, alias, err := replaceAPIImport(testController, "github.com/example/memcached-operator/api/v1alpha1", externalAPIPkg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if alias != "cachev1alpha1" {
+		t.Errorf("Unexpected import alias %q", alias)
+	}
+	if !strings.Contains(out, "\tcachev1alpha1 \""+externalAPIPkg+"\"\n") {
+		t.Errorf("Updated controller does not import %q:\n%s", externalAPIPkg, out)
+	}
+	if strings.Contains(out, "memcached-operator/api/v1alpha1") {
+		t.Errorf("Updated controller still imports the project's API package:\n%s", out)
+	}
+
+	if _, _, err := replaceAPIImport(testController, "github.com/example/memcached-operator/api/v1", externalAPIPkg); err == nil {
+		t.Error("Wanted error for controller without the API package import, got none")
+	}
+}
+
+func TestAddSchemeRegistration(t *testing.T) {
+	out, err := addSchemeRegistration(testMain, "cachev1", externalAPIPkg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", out, 0); err != nil {
+		t.Fatalf("Updated main.go does not parse: %v\n%s", err, out)
+	}
+	for _, s := range []string{
+		"\tcachev1 \"" + externalAPIPkg + "\"\n\t// +kubebuilder:scaffold:imports\n",
+		"\t_ = cachev1.AddToScheme(scheme)\n\n\t// +kubebuilder:scaffold:scheme\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Updated main.go does not contain %q:\n%s", s, out)
+		}
+	}
+
+	// Registering the same package again is a no-op.
+	again, err := addSchemeRegistration(out, "cachev1", externalAPIPkg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again != out {
+		t.Errorf("Registering an imported package changed main.go:\n%s", again)
+	}
+
+	if _, err := addSchemeRegistration("package main\n", "cachev1", externalAPIPkg); err == nil {
+		t.Error("Wanted error for main.go without scaffold markers, got none")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
+)
+
+// TODO: rewrite this as a kubebuilder file.Template when plugins phase 2 is implemented.
+
+// fixtureTestTemplate is a unit test that runs a controller's reconciler once
+// against the objects in its fixture.
+const fixtureTestTemplate = `package controllers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-sdk/pkg/reconciletest"
+
+	{{ .ImportAlias }} "{{ .Package }}"
+)
+
+// Test{{ .Kind }}ReconcileFixture runs {{ .Kind }}Reconciler's Reconcile once with
+// a fake client against the objects in {{ .FixturePath }}.
+// To reproduce a reconcile against a cluster's state, add the objects the
+// reconciler reads to the fixture, ex. from "kubectl get -o yaml", then assert
+// on the result and the objects' states after reconcile.
+func Test{{ .Kind }}ReconcileFixture(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := {{ .ImportAlias }}.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := reconciletest.Load(scheme, filepath.Join({{ .FixturePathArgs }}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &{{ .Kind }}Reconciler{
+		Client: f.Client,
+		Log:    ctrl.Log.WithName("controllers").WithName("{{ .Kind }}"),
+		Scheme: scheme,
+	}
+	res, err := f.Reconcile(r, types.NamespacedName{Namespace: "default", Name: "{{ .Name }}"})
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	// TODO(user): assert on res.Result and the objects in res.Objects.
+	for _, obj := range res.Objects {
+		t.Logf("%s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+}
+`
+
+// fixtureTemplate is a fixture containing a sample of the reconciled kind.
+const fixtureTemplate = `apiVersion: {{ .APIVersion }}
+kind: {{ .Kind }}
+metadata:
+  name: {{ .Name }}
+  namespace: default
+`
+
+// fixtureTestData are the values used to render the fixture templates.
+type fixtureTestData struct {
+	*resource.Resource
+	APIVersion      string
+	Name            string
+	FixturePath     string
+	FixturePathArgs string
+}
+
+// addFixtureTest writes a fixture with a sample of res, and a test that
+// reconciles it with res's reconciler, to controllerDir. pkg, if set,
+// overrides the import path of res's API package.
+func addFixtureTest(controllerDir string, res *resource.Resource, pkg string) error {
+	if pkg != "" {
+		res.Package = pkg
+	}
+	lowerKind := strings.ToLower(res.Kind)
+	data := fixtureTestData{
+		Resource:        res,
+		APIVersion:      res.Domain + "/" + res.Version,
+		Name:            lowerKind + "-sample",
+		FixturePath:     "testdata/" + lowerKind + "_fixture.yaml",
+		FixturePathArgs: fmt.Sprintf("%q, %q", "testdata", lowerKind+"_fixture.yaml"),
+	}
+	// Core types, ex. ConfigMap, are in the legacy unnamed group.
+	if res.Group == "core" {
+		data.APIVersion = res.Version
+	}
+
+	testSrc, err := renderFixtureTemplate(fixtureTestTemplate, data)
+	if err != nil {
+		return err
+	}
+	if testSrc, err = format.Source(testSrc); err != nil {
+		return fmt.Errorf("error formatting fixture test: %v", err)
+	}
+	fixture, err := renderFixtureTemplate(fixtureTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	testPath := filepath.Join(controllerDir, lowerKind+"_fixture_test.go")
+	fixturePath := filepath.Join(controllerDir, filepath.FromSlash(data.FixturePath))
+	for _, path := range []string{testPath, fixturePath} {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(fixturePath), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fixturePath, fixture, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(testPath, testSrc, 0644)
+}
+
+func renderFixtureTemplate(text string, data fixtureTestData) ([]byte, error) {
+	t, err := template.New("fixture").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
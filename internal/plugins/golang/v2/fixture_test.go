@@ -0,0 +1,104 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
+)
+
+func TestAddFixtureTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixture-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{Repo: "github.com/example/memcached-operator", Domain: "example.com"}
+	opts := &resource.Options{Group: "cache", Version: "v1alpha1", Kind: "Memcached"}
+	if err := addFixtureTest(dir, opts.NewResource(cfg, true), ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fixture, err := ioutil.ReadFile(filepath.Join(dir, "testdata", "memcached_fixture.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFixture := "apiVersion: cache.example.com/v1alpha1\nkind: Memcached\nmetadata:\n" +
+		"  name: memcached-sample\n  namespace: default\n"
+	if string(fixture) != wantFixture {
+		t.Errorf("Wanted fixture:\n%s\ngot:\n%s", wantFixture, fixture)
+	}
+
+	testPath := filepath.Join(dir, "memcached_fixture_test.go")
+	src, err := ioutil.ReadFile(testPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), testPath, src, 0); err != nil {
+		t.Fatalf("Fixture test does not parse: %v\n%s", err, src)
+	}
+	for _, s := range []string{
+		"\tcachev1alpha1 \"github.com/example/memcached-operator/api/v1alpha1\"\n",
+		"func TestMemcachedReconcileFixture(t *testing.T) {\n",
+		"reconciletest.Load(scheme, filepath.Join(\"testdata\", \"memcached_fixture.yaml\"))",
+		"\tr := &MemcachedReconciler{\n",
+		"types.NamespacedName{Namespace: \"default\", Name: \"memcached-sample\"}",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("Fixture test does not contain %q:\n%s", s, src)
+		}
+	}
+
+	if err := addFixtureTest(dir, opts.NewResource(cfg, true), ""); err == nil {
+		t.Error("Wanted error for existing fixture test, got none")
+	}
+}
+
+func TestAddFixtureTestCoreType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixture-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{Repo: "github.com/example/config-operator", Domain: "example.com"}
+	opts := &resource.Options{Group: "core", Version: "v1", Kind: "ConfigMap"}
+	if err := addFixtureTest(dir, opts.NewResource(cfg, false), ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fixture, err := ioutil.ReadFile(filepath.Join(dir, "testdata", "configmap_fixture.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(fixture), "apiVersion: v1\nkind: ConfigMap\n") {
+		t.Errorf("Wanted core group fixture, got:\n%s", fixture)
+	}
+	src, err := ioutil.ReadFile(filepath.Join(dir, "configmap_fixture_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "\"k8s.io/api/core/v1\"") {
+		t.Errorf("Wanted core API import, got:\n%s", src)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/plugin"
 
@@ -28,13 +29,29 @@ import (
 type initPlugin struct {
 	plugin.Init
 
-	config *config.Config
+	config                *config.Config
+	managerResources      string
+	managerLabels         string
+	managerInitContainers string
+	managerReplicas       string
+	managerTermination    string
+	templateDir           string
 }
 
 var _ plugin.Init = &initPlugin{}
 
 func (p *initPlugin) UpdateContext(ctx *plugin.Context) { p.Init.UpdateContext(ctx) }
-func (p *initPlugin) BindFlags(fs *pflag.FlagSet)       { p.Init.BindFlags(fs) }
+
+func (p *initPlugin) BindFlags(fs *pflag.FlagSet) {
+	p.Init.BindFlags(fs)
+	fs.StringVar(&p.managerResources, "manager-resources", "", utilplugins.ManagerResourcesUsage)
+	fs.StringVar(&p.managerLabels, "manager-labels", "", utilplugins.ManagerLabelsUsage)
+	fs.StringVar(&p.managerInitContainers, "manager-init-containers", "", utilplugins.ManagerInitContainersUsage)
+	fs.StringVar(&p.managerReplicas, "manager-replicas", "", utilplugins.ManagerReplicasUsage)
+	fs.StringVar(&p.managerTermination, "manager-termination-grace-period", "",
+		utilplugins.ManagerTerminationGracePeriodUsage)
+	fs.StringVar(&p.templateDir, "template-dir", "", utilplugins.TemplateDirUsage)
+}
 
 func (p *initPlugin) InjectConfig(c *config.Config) {
 	p.Init.InjectConfig(c)
@@ -42,6 +59,50 @@ func (p *initPlugin) InjectConfig(c *config.Config) {
 }
 
 func (p *initPlugin) Run() error {
+	var managerResources corev1.ResourceRequirements
+	if p.managerResources != "" {
+		var err error
+		if managerResources, err = utilplugins.ParseManagerResources(p.managerResources); err != nil {
+			return fmt.Errorf("invalid --manager-resources: %v", err)
+		}
+	}
+	var managerLabels map[string]string
+	if p.managerLabels != "" {
+		var err error
+		if managerLabels, err = utilplugins.ParseManagerLabels(p.managerLabels); err != nil {
+			return fmt.Errorf("invalid --manager-labels: %v", err)
+		}
+	}
+	var managerInitContainers utilplugins.ManagerInitContainers
+	if p.managerInitContainers != "" {
+		var err error
+		if managerInitContainers, err = utilplugins.ReadManagerInitContainers(p.managerInitContainers); err != nil {
+			return fmt.Errorf("invalid --manager-init-containers: %v", err)
+		}
+	}
+	var managerReplicas int32
+	if p.managerReplicas != "" {
+		var err error
+		if managerReplicas, err = utilplugins.ParseManagerReplicas(p.managerReplicas); err != nil {
+			return fmt.Errorf("invalid --manager-replicas: %v", err)
+		}
+	}
+	var managerTermination int64
+	if p.managerTermination != "" {
+		var err error
+		if managerTermination, err = utilplugins.ParseManagerTerminationGracePeriod(p.managerTermination); err != nil {
+			return fmt.Errorf("invalid --manager-termination-grace-period: %v", err)
+		}
+	}
+
+	var templateOverlay utilplugins.TemplateOverlay
+	if p.templateDir != "" {
+		var err error
+		if templateOverlay, err = utilplugins.ReadTemplateDir(p.templateDir); err != nil {
+			return fmt.Errorf("invalid --template-dir: %v", err)
+		}
+	}
+
 	if err := p.Init.Run(); err != nil {
 		return err
 	}
@@ -51,11 +112,53 @@ func (p *initPlugin) Run() error {
 		return err
 	}
 
+	// Override the manager's default resource requests and limits.
+	if p.managerResources != "" {
+		if err := utilplugins.AddManagerResourcesPatch(managerResources); err != nil {
+			return fmt.Errorf("error adding manager resources patch: %v", err)
+		}
+	}
+
+	// Add extra labels to the manager Deployment and its pods.
+	if p.managerLabels != "" {
+		if err := utilplugins.AddManagerLabelsPatch(managerLabels); err != nil {
+			return fmt.Errorf("error adding manager labels patch: %v", err)
+		}
+	}
+
+	// Add init containers to the manager's pods.
+	if p.managerInitContainers != "" {
+		if err := utilplugins.AddManagerInitContainersPatch(managerInitContainers); err != nil {
+			return fmt.Errorf("error adding manager init containers patch: %v", err)
+		}
+	}
+
+	// Override the manager Deployment's replica count.
+	if p.managerReplicas != "" {
+		if err := utilplugins.AddManagerReplicasPatch(managerReplicas); err != nil {
+			return fmt.Errorf("error adding manager replicas patch: %v", err)
+		}
+	}
+
+	// Override the manager pods' termination grace period.
+	if p.managerTermination != "" {
+		if err := utilplugins.AddManagerTerminationGracePeriodPatch(managerTermination); err != nil {
+			return fmt.Errorf("error adding manager termination grace period patch: %v", err)
+		}
+	}
+
 	// Run the scorecard "phase 2" plugin.
 	if err := scorecard.RunInit(p.config); err != nil {
 		return err
 	}
 
+	// Render user-provided templates over the scaffolded files.
+	if p.templateDir != "" {
+		if err := templateOverlay.Apply(p.config); err != nil {
+			return fmt.Errorf("error applying --template-dir templates: %v", err)
+		}
+	}
+
 	// Update plugin config section with this plugin's configuration.
 	cfg := Config{}
 	if err := p.config.EncodePluginConfig(pluginConfigKey, cfg); err != nil {
@@ -67,5 +170,14 @@ func (p *initPlugin) Run() error {
 
 // SDK plugin-specific scaffolds.
 func (p *initPlugin) run() error {
-	return utilplugins.UpdateMakefile(p.config)
+	if err := utilplugins.UpdateMakefile(p.config); err != nil {
+		return err
+	}
+
+	// Configure the manager to watch the namespace(s) in WATCH_NAMESPACE, and
+	// add --cache-sync-timeout and the dedicated metrics server flags.
+	if err := updateMain("main.go"); err != nil {
+		return fmt.Errorf("error updating main.go: %v", err)
+	}
+	return nil
 }
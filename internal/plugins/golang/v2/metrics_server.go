@@ -0,0 +1,154 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"strings"
+)
+
+// TODO: rewrite this as a kubebuilder file.Inserter when plugins phase 2 is implemented.
+
+// managerErrorFragment is the error check after the manager constructor in main
+// scaffolded by kubebuilder's Init plugin.
+const managerErrorFragment = `		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+`
+
+const metricsServerFlagFragment = `	var metricsServer metricsServerOptions
+	flag.StringVar(&metricsServer.BindAddress, "metrics-server-addr", "",
+		"The address a dedicated metrics server binds to, ex. :8443. Disabled if empty. "+
+			"Set --metrics-addr=0 to serve metrics only from this server.")
+	flag.StringVar(&metricsServer.CertDir, "metrics-server-cert-dir", "",
+		"The directory containing the dedicated metrics server's TLS certificate and key, tls.crt and tls.key. "+
+			"Metrics are served over HTTP if empty.")
+	flag.StringVar(&metricsServer.ClientCAFile, "metrics-server-client-ca-file", "",
+		"A CA bundle the dedicated metrics server verifies client certificates with. "+
+			"If set, only clients with a certificate signed by this CA are served. Requires --metrics-server-cert-dir.")
+`
+
+const metricsServerAddFragment = `
+	// Serve metrics from a dedicated server if --metrics-server-addr is set.
+	if metricsServer.BindAddress != "" {
+		if err := mgr.Add(metricsServer); err != nil {
+			setupLog.Error(err, "unable to add metrics server")
+			os.Exit(1)
+		}
+	}
+`
+
+const metricsServerFragment = `
+// metricsServerOptions configures a dedicated metrics server run by the manager
+// alongside its metrics endpoint at --metrics-addr, ex. to serve metrics over TLS
+// with the server's own certificate.
+type metricsServerOptions struct {
+	// BindAddress is the address the server binds to.
+	BindAddress string
+	// CertDir is the directory containing the server's TLS certificate and key,
+	// tls.crt and tls.key. Metrics are served over HTTP if empty.
+	CertDir string
+	// ClientCAFile is a CA bundle client certificates must be signed by. Client
+	// certificates are not required if empty.
+	ClientCAFile string
+	// Gatherer gathers the metrics served, defaulting to controller-runtime's
+	// metrics.Registry. Set it to a separate prometheus.Registry to serve custom
+	// metrics apart from controller-runtime's metrics at --metrics-addr.
+	Gatherer prometheus.Gatherer
+}
+
+// Start serves metrics until stop is closed.
+func (o metricsServerOptions) Start(stop <-chan struct{}) error {
+	gatherer := o.Gatherer
+	if gatherer == nil {
+		gatherer = metrics.Registry
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	srv := &http.Server{Addr: o.BindAddress, Handler: mux}
+
+	if o.ClientCAFile != "" {
+		if o.CertDir == "" {
+			return fmt.Errorf("a metrics server client CA requires a server certificate")
+		}
+		b, err := ioutil.ReadFile(o.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return fmt.Errorf("no certificates found in %s", o.ClientCAFile)
+		}
+		srv.TLSConfig = &tls.Config{ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert}
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		if o.CertDir != "" {
+			err = srv.ListenAndServeTLS(filepath.Join(o.CertDir, "tls.crt"), filepath.Join(o.CertDir, "tls.key"))
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+	setupLog.Info("starting metrics server", "addr", o.BindAddress, "secure", o.CertDir != "")
+
+	select {
+	case <-stop:
+		return srv.Shutdown(context.Background())
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection returns false, so every replica serves its own metrics.
+func (o metricsServerOptions) NeedLeaderElection() bool {
+	return false
+}
+`
+
+// addMetricsServer returns mainStr, updated by addCacheSyncTimeout, with flags
+// configuring a dedicated metrics server that the manager runs if one is set,
+// and the imports that server needs. The manager's metrics endpoint is unchanged.
+func addMetricsServer(mainStr string) (string, error) {
+	for _, s := range []string{flagParseFragment, managerErrorFragment} {
+		if !strings.Contains(mainStr, s) {
+			return "", errors.New("manager constructor not found")
+		}
+	}
+	mainStr = strings.Replace(mainStr, flagParseFragment, metricsServerFlagFragment+flagParseFragment, 1)
+	mainStr = strings.Replace(mainStr, managerErrorFragment, managerErrorFragment+metricsServerAddFragment, 1)
+	mainStr += metricsServerFragment
+
+	for _, imp := range []struct{ after, add string }{
+		{"import (\n", "\t\"context\"\n\t\"crypto/tls\"\n\t\"crypto/x509\"\n"},
+		{"\t\"flag\"\n", "\t\"fmt\"\n\t\"io/ioutil\"\n\t\"net/http\"\n"},
+		{"\t\"os\"\n", "\t\"path/filepath\"\n"},
+		{"\t\"time\"\n\n", "\t\"github.com/prometheus/client_golang/prometheus\"\n" +
+			"\t\"github.com/prometheus/client_golang/prometheus/promhttp\"\n"},
+		{"\t\"sigs.k8s.io/controller-runtime/pkg/log/zap\"\n", "\t\"sigs.k8s.io/controller-runtime/pkg/metrics\"\n"},
+	} {
+		if !strings.Contains(mainStr, imp.after) {
+			return "", errors.New("import block not found")
+		}
+		if !strings.Contains(mainStr, imp.add) {
+			mainStr = strings.Replace(mainStr, imp.after, imp.after+imp.add, 1)
+		}
+	}
+	return mainStr, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestAddMetricsServer(t *testing.T) {
+	mainStr, err := addWatchNamespaces(testMain)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mainStr, err = addCacheSyncTimeout(mainStr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out, err := addMetricsServer(mainStr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", out, 0); err != nil {
+		t.Fatalf("Updated main.go does not parse: %v\n%s", err, out)
+	}
+	for _, s := range []string{
+		"import (\n\t\"context\"\n\t\"crypto/tls\"\n\t\"crypto/x509\"\n\t\"flag\"\n\t\"fmt\"\n\t\"io/ioutil\"\n\t\"net/http\"\n" +
+			"\t\"os\"\n\t\"path/filepath\"\n",
+		"\t\"time\"\n\n\t\"github.com/prometheus/client_golang/prometheus\"\n",
+		"\t\"sigs.k8s.io/controller-runtime/pkg/metrics\"\n",
+		"flag.StringVar(&metricsServer.BindAddress, \"metrics-server-addr\", \"\",",
+		"\t\tos.Exit(1)\n\t}\n\n\t// Serve metrics from a dedicated server if --metrics-server-addr is set.\n",
+		"MetricsBindAddress: metricsAddr,",
+		"func (o metricsServerOptions) Start(stop <-chan struct{}) error {",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Updated main.go does not contain %q:\n%s", s, out)
+		}
+	}
+
+	if _, err := addMetricsServer("package main\n"); err == nil {
+		t.Error("Wanted error for main.go without a manager constructor, got none")
+	}
+}
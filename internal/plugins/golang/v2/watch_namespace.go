@@ -0,0 +1,115 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// TODO: rewrite this as a kubebuilder file.Inserter when plugins phase 2 is implemented.
+
+// newManagerRe matches the manager constructor scaffolded by kubebuilder's Init plugin,
+// capturing the body of the manager's options literal.
+var newManagerRe = regexp.MustCompile(`(?s)\tmgr, err := ctrl\.NewManager\(ctrl\.GetConfigOrDie\(\), ctrl\.Options\{\n(.*?)\n\t\}\)\n`)
+
+const watchNamespaceFragment = `	options := ctrl.Options{
+%s
+	}
+
+	// Configure the manager's cache for the namespace(s) in WATCH_NAMESPACE.
+	// An empty or unset value watches all namespaces, and a comma-separated
+	// list of namespaces watches each of them.
+	namespaces := getWatchNamespaces()
+	switch len(namespaces) {
+	case 0:
+		setupLog.Info("watching all namespaces")
+	case 1:
+		setupLog.Info("watching single namespace", "namespace", namespaces[0])
+		options.Namespace = namespaces[0]
+	default:
+		setupLog.Info("watching multiple namespaces", "namespaces", namespaces)
+		options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
+`
+
+const getWatchNamespacesFragment = `
+// watchNamespaceEnvVar is the environment variable containing the
+// comma-separated namespace(s) the manager watches.
+const watchNamespaceEnvVar = "WATCH_NAMESPACE"
+
+// getWatchNamespaces returns the non-empty namespaces in watchNamespaceEnvVar,
+// or none if it is unset or empty, meaning all namespaces are watched.
+func getWatchNamespaces() []string {
+	var namespaces []string
+	for _, ns := range strings.Split(os.Getenv(watchNamespaceEnvVar), ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+`
+
+// updateMain configures the manager in the main.go file at filePath, scaffolded
+// by kubebuilder's Init plugin, to watch the namespace(s) in WATCH_NAMESPACE,
+// to optionally bound its cache sync with --cache-sync-timeout, and to optionally
+// run a dedicated metrics server set by --metrics-server-addr.
+func updateMain(filePath string) error {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	mainStr, err := addWatchNamespaces(string(b))
+	if err != nil {
+		return err
+	}
+	if mainStr, err = addCacheSyncTimeout(mainStr); err != nil {
+		return err
+	}
+	if mainStr, err = addMetricsServer(mainStr); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, []byte(mainStr), 0644)
+}
+
+// addWatchNamespaces returns mainStr with WATCH_NAMESPACE cache configuration
+// added to its manager's options, and the imports that configuration needs.
+func addWatchNamespaces(mainStr string) (string, error) {
+	match := newManagerRe.FindStringSubmatchIndex(mainStr)
+	if match == nil {
+		return "", errors.New("manager constructor not found")
+	}
+	opts := mainStr[match[2]:match[3]]
+	mainStr = mainStr[:match[0]] + strings.Replace(watchNamespaceFragment, "%s", opts, 1) + mainStr[match[1]:]
+	mainStr += getWatchNamespacesFragment
+
+	for _, imp := range []struct{ after, add string }{
+		{"\t\"os\"\n", "\t\"strings\"\n"},
+		{"\tctrl \"sigs.k8s.io/controller-runtime\"\n", "\t\"sigs.k8s.io/controller-runtime/pkg/cache\"\n"},
+	} {
+		if !strings.Contains(mainStr, imp.after) {
+			return "", errors.New("import block not found")
+		}
+		if !strings.Contains(mainStr, imp.add) {
+			mainStr = strings.Replace(mainStr, imp.after, imp.after+imp.add, 1)
+		}
+	}
+	return mainStr, nil
+}
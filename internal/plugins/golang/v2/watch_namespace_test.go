@@ -0,0 +1,98 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const testMain = `package main
+
+import (
+	"flag"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	// +kubebuilder:scaffold:imports
+)
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("setup")
+)
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	// +kubebuilder:scaffold:scheme
+}
+
+func main() {
+	var metricsAddr string
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: metricsAddr,
+		Port:               9443,
+		LeaderElectionID:   "abcd1234.example.com",
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	// +kubebuilder:scaffold:builder
+
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		os.Exit(1)
+	}
+}
+`
+
+func TestAddWatchNamespaces(t *testing.T) {
+	out, err := addWatchNamespaces(testMain)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", out, 0); err != nil {
+		t.Fatalf("Updated main.go does not parse: %v\n%s", err, out)
+	}
+	for _, s := range []string{
+		"\t\"strings\"\n",
+		"\t\"sigs.k8s.io/controller-runtime/pkg/cache\"\n",
+		"\t\tLeaderElectionID:   \"abcd1234.example.com\",\n\t}\n",
+		"options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)",
+		"mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)",
+		"func getWatchNamespaces() []string {",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Updated main.go does not contain %q:\n%s", s, out)
+		}
+	}
+
+	if _, err := addWatchNamespaces("package main\n"); err == nil {
+		t.Error("Wanted error for main.go without a manager constructor, got none")
+	}
+}
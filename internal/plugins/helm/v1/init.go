@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/plugin"
@@ -38,6 +39,19 @@ type initPlugin struct {
 	apiPlugin     createAPIPlugin
 	doAPIScaffold bool
 
+	managerResourcesFlag      string
+	managerResources          corev1.ResourceRequirements
+	managerLabelsFlag         string
+	managerLabels             map[string]string
+	managerInitContainersFlag string
+	managerInitContainers     utilplugins.ManagerInitContainers
+	managerReplicasFlag       string
+	managerReplicas           int32
+	managerTerminationFlag    string
+	managerTermination        int64
+	templateDirFlag           string
+	templateOverlay           utilplugins.TemplateOverlay
+
 	// For help text.
 	commandName string
 }
@@ -59,6 +73,13 @@ Writes the following files:
 - a Kustomization.yaml for customizating manifests
 - a Patch file for customizing image for manager manifests
 - a Patch file for enabling prometheus metrics
+- a Patch file for setting the manager's resource requests and limits, if --manager-resources is set
+- a Patch file for adding init containers to the manager's pods, if --manager-init-containers is set
+- a Patch file for setting the manager Deployment's replica count, if --manager-replicas is set
+- a Patch file for setting the manager pods' termination grace period, if --manager-termination-grace-period is set
+
+Files in the directory set by --template-dir are rendered as templates over the
+scaffolded file at the same path, or added if nothing is scaffolded there.
 `
 	ctx.Examples = fmt.Sprintf(`  $ %s init --plugins=%s \
       --domain=example.com \
@@ -127,6 +148,13 @@ func (p *initPlugin) BindFlags(fs *pflag.FlagSet) {
 	fs.SortFlags = false
 	fs.StringVar(&p.config.Domain, "domain", "my.domain", "domain for groups")
 	fs.StringVar(&p.config.ProjectName, "project-name", "", "name of this project, the default being directory name")
+	fs.StringVar(&p.managerResourcesFlag, "manager-resources", "", utilplugins.ManagerResourcesUsage)
+	fs.StringVar(&p.managerLabelsFlag, "manager-labels", "", utilplugins.ManagerLabelsUsage)
+	fs.StringVar(&p.managerInitContainersFlag, "manager-init-containers", "", utilplugins.ManagerInitContainersUsage)
+	fs.StringVar(&p.managerReplicasFlag, "manager-replicas", "", utilplugins.ManagerReplicasUsage)
+	fs.StringVar(&p.managerTerminationFlag, "manager-termination-grace-period", "",
+		utilplugins.ManagerTerminationGracePeriodUsage)
+	fs.StringVar(&p.templateDirFlag, "template-dir", "", utilplugins.TemplateDirUsage)
 	p.apiPlugin.BindFlags(fs)
 }
 
@@ -149,6 +177,13 @@ func (p *initPlugin) Run() error {
 		return err
 	}
 
+	// Render user-provided templates over the scaffolded files.
+	if p.templateDirFlag != "" {
+		if err := p.templateOverlay.Apply(p.config); err != nil {
+			return fmt.Errorf("error applying --template-dir templates: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -167,6 +202,43 @@ func (p *initPlugin) Validate() error {
 		return fmt.Errorf("project name (%s) is invalid: %v", p.config.ProjectName, err)
 	}
 
+	if p.managerResourcesFlag != "" {
+		var err error
+		if p.managerResources, err = utilplugins.ParseManagerResources(p.managerResourcesFlag); err != nil {
+			return fmt.Errorf("invalid --manager-resources: %v", err)
+		}
+	}
+	if p.managerLabelsFlag != "" {
+		var err error
+		if p.managerLabels, err = utilplugins.ParseManagerLabels(p.managerLabelsFlag); err != nil {
+			return fmt.Errorf("invalid --manager-labels: %v", err)
+		}
+	}
+	if p.managerInitContainersFlag != "" {
+		var err error
+		if p.managerInitContainers, err = utilplugins.ReadManagerInitContainers(p.managerInitContainersFlag); err != nil {
+			return fmt.Errorf("invalid --manager-init-containers: %v", err)
+		}
+	}
+	if p.managerReplicasFlag != "" {
+		var err error
+		if p.managerReplicas, err = utilplugins.ParseManagerReplicas(p.managerReplicasFlag); err != nil {
+			return fmt.Errorf("invalid --manager-replicas: %v", err)
+		}
+	}
+	if p.managerTerminationFlag != "" {
+		var err error
+		if p.managerTermination, err = utilplugins.ParseManagerTerminationGracePeriod(p.managerTerminationFlag); err != nil {
+			return fmt.Errorf("invalid --manager-termination-grace-period: %v", err)
+		}
+	}
+	if p.templateDirFlag != "" {
+		var err error
+		if p.templateOverlay, err = utilplugins.ReadTemplateDir(p.templateDirFlag); err != nil {
+			return fmt.Errorf("invalid --template-dir: %v", err)
+		}
+	}
+
 	defaultOpts := chartutil.CreateOptions{CRDVersion: "v1"}
 	if !p.apiPlugin.createOptions.GVK.Empty() || p.apiPlugin.createOptions != defaultOpts {
 		p.doAPIScaffold = true
@@ -198,6 +270,41 @@ func (p *initPlugin) PostScaffold() error {
 		return err
 	}
 
+	// Override the manager's default resource requests and limits.
+	if p.managerResourcesFlag != "" {
+		if err := utilplugins.AddManagerResourcesPatch(p.managerResources); err != nil {
+			return fmt.Errorf("error adding manager resources patch: %v", err)
+		}
+	}
+
+	// Add extra labels to the manager Deployment and its pods.
+	if p.managerLabelsFlag != "" {
+		if err := utilplugins.AddManagerLabelsPatch(p.managerLabels); err != nil {
+			return fmt.Errorf("error adding manager labels patch: %v", err)
+		}
+	}
+
+	// Add init containers to the manager's pods.
+	if p.managerInitContainersFlag != "" {
+		if err := utilplugins.AddManagerInitContainersPatch(p.managerInitContainers); err != nil {
+			return fmt.Errorf("error adding manager init containers patch: %v", err)
+		}
+	}
+
+	// Override the manager Deployment's replica count.
+	if p.managerReplicasFlag != "" {
+		if err := utilplugins.AddManagerReplicasPatch(p.managerReplicas); err != nil {
+			return fmt.Errorf("error adding manager replicas patch: %v", err)
+		}
+	}
+
+	// Override the manager pods' termination grace period.
+	if p.managerTerminationFlag != "" {
+		if err := utilplugins.AddManagerTerminationGracePeriodPatch(p.managerTermination); err != nil {
+			return fmt.Errorf("error adding manager termination grace period patch: %v", err)
+		}
+	}
+
 	if p.doAPIScaffold {
 		return p.apiPlugin.PostScaffold()
 	}
@@ -0,0 +1,163 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimiter builds the workqueue rate limiters of Ansible and Helm operator controllers.
+package ratelimiter
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crratelimiter "sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+// Defaults of the overall reconcile rate limit, which are those of workqueue.DefaultControllerRateLimiter.
+const (
+	DefaultQPS   = 10.0
+	DefaultBurst = 100
+)
+
+// Usage text of the --reconcile-qps and --reconcile-burst flags.
+const (
+	QPSUsage = "Maximum average number of reconciles per second for each controller, counting both " +
+		"custom resource events, ex. creations, and requeues, ex. retries of failed reconciles. " +
+		"Lower to throttle reconciles that load a backend"
+	BurstUsage = "Maximum number of reconciles for each controller that may run above --reconcile-qps " +
+		"in a burst"
+)
+
+// Limiter is a controller rate limiter that shares its token bucket with the event handlers
+// returned by EventHandler, so requests enqueued by watch events and requeued requests are
+// limited together.
+type Limiter struct {
+	crratelimiter.RateLimiter
+	bucket *bucketRateLimiter
+}
+
+// New returns a controller rate limiter that delays each requeue of a request by the larger of a
+// per-request exponential backoff, as the default controller rate limiter does, and an overall token
+// bucket limit of qps requests per second with bursts of up to burst requests.
+func New(qps float64, burst int) (*Limiter, error) {
+	if qps <= 0 {
+		return nil, fmt.Errorf("reconcile qps %v must be positive", qps)
+	}
+	if burst < 1 {
+		return nil, fmt.Errorf("reconcile burst %d must be positive", burst)
+	}
+	bucket := newBucketRateLimiter(qps, burst, time.Now)
+	return &Limiter{
+		RateLimiter: workqueue.NewMaxOfRateLimiter(
+			workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second),
+			bucket,
+		),
+		bucket: bucket,
+	}, nil
+}
+
+// EventHandler returns h with each request it adds to a controller's queue delayed until a token
+// of limiter's bucket is available. A controller's rate limiter only delays requeues, so without
+// this requests enqueued by watch events, such as a burst of new custom resources, are not limited.
+// If limiter was not returned by New, h is returned unchanged.
+func EventHandler(limiter crratelimiter.RateLimiter, h handler.EventHandler) handler.EventHandler {
+	l, ok := limiter.(*Limiter)
+	if !ok || l == nil {
+		return h
+	}
+	return &limitedEventHandler{handler: h, bucket: l.bucket}
+}
+
+// limitedEventHandler passes events to handler with a queue that delays added requests by bucket.
+type limitedEventHandler struct {
+	handler handler.EventHandler
+	bucket  crratelimiter.RateLimiter
+}
+
+var _ handler.EventHandler = &limitedEventHandler{}
+
+func (h *limitedEventHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Create(evt, &limitedQueue{RateLimitingInterface: q, bucket: h.bucket})
+}
+
+func (h *limitedEventHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Update(evt, &limitedQueue{RateLimitingInterface: q, bucket: h.bucket})
+}
+
+func (h *limitedEventHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Delete(evt, &limitedQueue{RateLimitingInterface: q, bucket: h.bucket})
+}
+
+func (h *limitedEventHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.handler.Generic(evt, &limitedQueue{RateLimitingInterface: q, bucket: h.bucket})
+}
+
+// limitedQueue adds requests to a queue once bucket allows them. Requeues are left to the queue's
+// own rate limiter, which takes tokens from the same bucket.
+type limitedQueue struct {
+	workqueue.RateLimitingInterface
+	bucket crratelimiter.RateLimiter
+}
+
+func (q *limitedQueue) Add(item interface{}) {
+	if d := q.bucket.When(item); d > 0 {
+		q.AddAfter(item, d)
+		return
+	}
+	q.RateLimitingInterface.Add(item)
+}
+
+// bucketRateLimiter delays requests to an average of qps per second with bursts of up to burst requests,
+// like workqueue.BucketRateLimiter does with a golang.org/x/time/rate limiter.
+type bucketRateLimiter struct {
+	qps   float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+var _ crratelimiter.RateLimiter = &bucketRateLimiter{}
+
+func newBucketRateLimiter(qps float64, burst int, now func() time.Time) *bucketRateLimiter {
+	return &bucketRateLimiter{qps: qps, burst: float64(burst), now: now, tokens: float64(burst), last: now()}
+}
+
+// When takes a token from the bucket, returning how long to wait for it if the bucket is empty.
+func (r *bucketRateLimiter) When(interface{}) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if elapsed := now.Sub(r.last); elapsed > 0 {
+		r.tokens = math.Min(r.burst, r.tokens+elapsed.Seconds()*r.qps)
+		r.last = now
+	}
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / r.qps * float64(time.Second))
+}
+
+// Forget is a no-op since the bucket is not tracked per request.
+func (r *bucketRateLimiter) Forget(interface{}) {}
+
+// NumRequeues is always 0 since the bucket is not tracked per request.
+func (r *bucketRateLimiter) NumRequeues(interface{}) int { return 0 }
@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimiter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func TestNew(t *testing.T) {
+	limiter, err := New(1, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The first requeue of each request is only delayed by the initial backoff until the burst is used up.
+	for _, item := range []string{"a", "b"} {
+		if d := limiter.When(item); d > time.Second/2 {
+			t.Errorf("Unexpected delay %v of %s within the burst", d, item)
+		}
+	}
+	if d := limiter.When("c"); d < time.Second/2 {
+		t.Errorf("Unexpected delay %v of c after the burst, wanted about a second", d)
+	}
+
+	for _, c := range []struct {
+		qps   float64
+		burst int
+	}{{0, 1}, {-1, 1}, {1, 0}} {
+		if _, err := New(c.qps, c.burst); err == nil {
+			t.Errorf("Wanted error for qps %v and burst %d, got none", c.qps, c.burst)
+		}
+	}
+}
+
+func TestBucketRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := newBucketRateLimiter(2, 2, func() time.Time { return now })
+
+	for _, want := range []time.Duration{0, 0, 500 * time.Millisecond, time.Second} {
+		if d := r.When("a"); d != want {
+			t.Errorf("Unexpected delay %v, wanted %v", d, want)
+		}
+	}
+	// Tokens refill at qps, and waiting requests have already taken theirs.
+	now = now.Add(2 * time.Second)
+	if d := r.When("a"); d != 0 {
+		t.Errorf("Unexpected delay %v after refilling", d)
+	}
+	now = now.Add(time.Hour)
+	for _, want := range []time.Duration{0, 0, 500 * time.Millisecond} {
+		if d := r.When("a"); d != want {
+			t.Errorf("Unexpected delay %v after refilling to the burst, wanted %v", d, want)
+		}
+	}
+}
+
+// recordingQueue records the delay each request is added with.
+type recordingQueue struct {
+	workqueue.RateLimitingInterface
+	delays map[interface{}]time.Duration
+}
+
+func (q *recordingQueue) Add(item interface{}) {
+	q.delays[item] = 0
+}
+
+func (q *recordingQueue) AddAfter(item interface{}, d time.Duration) {
+	q.delays[item] = d
+}
+
+func TestEventHandler(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newBucketRateLimiter(2, 2, func() time.Time { return now })
+	limiter := &Limiter{RateLimiter: bucket, bucket: bucket}
+
+	h := EventHandler(limiter, handler.Funcs{
+		CreateFunc:  func(_ event.CreateEvent, q workqueue.RateLimitingInterface) { q.Add("create") },
+		UpdateFunc:  func(_ event.UpdateEvent, q workqueue.RateLimitingInterface) { q.Add("update") },
+		DeleteFunc:  func(_ event.DeleteEvent, q workqueue.RateLimitingInterface) { q.Add("delete") },
+		GenericFunc: func(_ event.GenericEvent, q workqueue.RateLimitingInterface) { q.Add("generic") },
+	})
+
+	q := &recordingQueue{delays: map[interface{}]time.Duration{}}
+	h.Create(event.CreateEvent{}, q)
+	h.Update(event.UpdateEvent{}, q)
+	h.Delete(event.DeleteEvent{}, q)
+	h.Generic(event.GenericEvent{}, q)
+	expected := map[interface{}]time.Duration{
+		"create":  0,
+		"update":  0,
+		"delete":  500 * time.Millisecond,
+		"generic": time.Second,
+	}
+	if !reflect.DeepEqual(q.delays, expected) {
+		t.Errorf("Unexpected delays %v, wanted %v", q.delays, expected)
+	}
+	// Requeues take tokens from the same bucket.
+	if d := limiter.When("requeue"); d != 1500*time.Millisecond {
+		t.Errorf("Unexpected requeue delay %v after the burst of events, wanted 1.5s", d)
+	}
+
+	other := handler.Funcs{}
+	if h := EventHandler(workqueue.DefaultControllerRateLimiter(), other); !reflect.DeepEqual(h, other) {
+		t.Errorf("Expected the handler to be unchanged for a rate limiter not returned by New, got %T", h)
+	}
+}
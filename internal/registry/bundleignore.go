@@ -0,0 +1,130 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/builder/dockerignore"
+)
+
+const (
+	// BundleIgnoreFile is the name of the file in a bundle's root directory listing
+	// paths, relative to that directory and in .dockerignore syntax, to exclude
+	// from the bundle image's build context.
+	BundleIgnoreFile = ".bundleignore"
+	// DockerignoreFile is the name of the file in a build context's root directory
+	// listing paths to exclude from the build context.
+	DockerignoreFile = ".dockerignore"
+
+	// Lines delimiting bundle excludes managed by WriteBundleDockerignore.
+	bundleIgnoreBlockStart = "# Start of bundle image excludes generated by operator-sdk from %s. DO NOT EDIT.\n"
+	bundleIgnoreBlockEnd   = "# End of bundle image excludes.\n"
+)
+
+// DefaultBundleIgnorePatterns are editor and OS files excluded from every bundle image.
+var DefaultBundleIgnorePatterns = []string{"**/.DS_Store", "**/*.swp"}
+
+// ReadBundleIgnore returns DefaultBundleIgnorePatterns followed by the patterns in
+// bundleRoot's BundleIgnoreFile, if it exists. Later patterns take precedence, so
+// a "!"-prefixed pattern in the file can re-include a default exclude.
+func ReadBundleIgnore(bundleRoot string) ([]string, error) {
+	patterns := append([]string{}, DefaultBundleIgnorePatterns...)
+	f, err := os.Open(filepath.Join(bundleRoot, BundleIgnoreFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return patterns, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	filePatterns, err := dockerignore.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", f.Name(), err)
+	}
+	return append(patterns, filePatterns...), nil
+}
+
+// WriteBundleDockerignore writes the patterns returned by ReadBundleIgnore for bundleRoot
+// to the DockerignoreFile in contextDir, the bundle image's build context, so they exclude
+// paths under bundleRoot. The patterns are written to a block of that file which is replaced
+// on each call, so other patterns in the file are kept.
+func WriteBundleDockerignore(contextDir, bundleRoot string) error {
+	patterns, err := ReadBundleIgnore(bundleRoot)
+	if err != nil {
+		return err
+	}
+	absContext, err := filepath.Abs(contextDir)
+	if err != nil {
+		return err
+	}
+	absRoot, err := filepath.Abs(bundleRoot)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(absContext, absRoot)
+	if err != nil {
+		return err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("bundle directory %s is not in build context %s", bundleRoot, contextDir)
+	}
+
+	ignoreFile := filepath.ToSlash(filepath.Join(rel, BundleIgnoreFile))
+	block := &strings.Builder{}
+	fmt.Fprintf(block, bundleIgnoreBlockStart, ignoreFile)
+	for _, p := range patterns {
+		negate := ""
+		if strings.HasPrefix(p, "!") {
+			negate, p = "!", p[1:]
+		}
+		fmt.Fprintf(block, "%s%s\n", negate, filepath.ToSlash(filepath.Join(rel, p)))
+	}
+	block.WriteString(bundleIgnoreBlockEnd)
+
+	path := filepath.Join(contextDir, DockerignoreFile)
+	b, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	return ioutil.WriteFile(path, replaceBundleIgnoreBlock(b, ignoreFile, block.String()), mode)
+}
+
+// replaceBundleIgnoreBlock returns content with its bundle excludes block for ignoreFile
+// replaced by block, or with block appended if content has no such block.
+func replaceBundleIgnoreBlock(content []byte, ignoreFile, block string) []byte {
+	start := []byte(fmt.Sprintf(bundleIgnoreBlockStart, ignoreFile))
+	if i := bytes.Index(content, start); i >= 0 {
+		if j := bytes.Index(content[i:], []byte(bundleIgnoreBlockEnd)); j >= 0 {
+			end := i + j + len(bundleIgnoreBlockEnd)
+			out := append([]byte{}, content[:i]...)
+			out = append(out, block...)
+			return append(out, content[end:]...)
+		}
+	}
+	if len(content) != 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	return append(content, block...)
+}
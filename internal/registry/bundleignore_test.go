@@ -0,0 +1,135 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/builder/dockerignore"
+	"github.com/docker/docker/pkg/archive"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundle ignore", func() {
+	var contextDir, bundleRoot string
+
+	writeFile := func(path, content string) {
+		path = filepath.Join(contextDir, path)
+		ExpectWithOffset(1, os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		ExpectWithOffset(1, ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+	readFile := func(path string) string {
+		b, err := ioutil.ReadFile(filepath.Join(contextDir, path))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return string(b)
+	}
+	// contextFiles returns the files in the build context sent by the docker CLI,
+	// which are the only files an image's COPY layers can contain.
+	contextFiles := func() (files []string) {
+		f, err := os.Open(filepath.Join(contextDir, DockerignoreFile))
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		excludes, err := dockerignore.ReadAll(f)
+		Expect(err).NotTo(HaveOccurred())
+		rc, err := archive.TarWithOptions(contextDir, &archive.TarOptions{ExcludePatterns: excludes})
+		Expect(err).NotTo(HaveOccurred())
+		defer rc.Close()
+		tr := tar.NewReader(rc)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return files
+			}
+			Expect(err).NotTo(HaveOccurred())
+			if hdr.Typeflag == tar.TypeReg {
+				files = append(files, hdr.Name)
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		contextDir, err = ioutil.TempDir("", "bundleignore-")
+		Expect(err).NotTo(HaveOccurred())
+		bundleRoot = filepath.Join(contextDir, "bundle")
+		writeFile("bundle/manifests/operator.clusterserviceversion.yaml", "kind: ClusterServiceVersion\n")
+		writeFile("bundle/manifests/.DS_Store", "")
+		writeFile("bundle/manifests/.operator.clusterserviceversion.yaml.swp", "")
+		writeFile("bundle/metadata/annotations.yaml", "annotations: {}\n")
+		writeFile("bundle/metadata/notes.txt", "")
+		writeFile("bundle/.DS_Store", "")
+		writeFile("notes.txt", "")
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(contextDir)).To(Succeed())
+	})
+
+	Describe("ReadBundleIgnore", func() {
+		It("returns the defaults without an ignore file", func() {
+			Expect(ReadBundleIgnore(bundleRoot)).To(Equal(DefaultBundleIgnorePatterns))
+		})
+		It("returns the defaults followed by patterns in the ignore file", func() {
+			writeFile("bundle/.bundleignore", "# Notes.\nmetadata/*.txt\n\n!manifests/.DS_Store\n")
+			Expect(ReadBundleIgnore(bundleRoot)).To(Equal(append(append([]string{}, DefaultBundleIgnorePatterns...),
+				"metadata/*.txt", "!manifests/.DS_Store")))
+		})
+	})
+
+	Describe("WriteBundleDockerignore", func() {
+		It("excludes default patterns from the build context", func() {
+			Expect(WriteBundleDockerignore(contextDir, bundleRoot)).To(Succeed())
+			Expect(contextFiles()).To(ConsistOf(
+				".dockerignore",
+				"notes.txt",
+				"bundle/manifests/operator.clusterserviceversion.yaml",
+				"bundle/metadata/annotations.yaml",
+				"bundle/metadata/notes.txt",
+			))
+		})
+		It("excludes patterns in the ignore file relative to the bundle directory", func() {
+			writeFile("bundle/.bundleignore", "*.txt\nmetadata/*.txt\n")
+			Expect(WriteBundleDockerignore(contextDir, bundleRoot)).To(Succeed())
+			Expect(contextFiles()).To(ConsistOf(
+				".dockerignore",
+				"notes.txt",
+				"bundle/.bundleignore",
+				"bundle/manifests/operator.clusterserviceversion.yaml",
+				"bundle/metadata/annotations.yaml",
+			))
+		})
+		It("replaces its own block and keeps other patterns", func() {
+			writeFile(".dockerignore", "bin/\ntestbin/")
+			Expect(WriteBundleDockerignore(contextDir, bundleRoot)).To(Succeed())
+			writeFile("bundle/.bundleignore", "metadata/notes.txt\n")
+			Expect(WriteBundleDockerignore(contextDir, bundleRoot)).To(Succeed())
+			Expect(readFile(".dockerignore")).To(Equal(`bin/
+testbin/
+# Start of bundle image excludes generated by operator-sdk from bundle/.bundleignore. DO NOT EDIT.
+bundle/**/.DS_Store
+bundle/**/*.swp
+bundle/metadata/notes.txt
+# End of bundle image excludes.
+`))
+		})
+		It("fails if the bundle is not in the build context", func() {
+			Expect(WriteBundleDockerignore(bundleRoot, contextDir)).To(MatchError(ContainSubstring("is not in build context")))
+		})
+	})
+})
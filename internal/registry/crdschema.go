@@ -0,0 +1,189 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// SchemaBreakingChange is a change to a CRD version's validation schema that can
+// invalidate custom resources stored with the previous schema.
+type SchemaBreakingChange struct {
+	// CRD is the name of the changed CRD.
+	CRD string
+	// Version is the name of the CRD version whose schema changed.
+	Version string
+	// Path is the JSON path of the changed field, ex. ".spec.size".
+	Path string
+	// Reason describes the change.
+	Reason string
+}
+
+func (c SchemaBreakingChange) String() string {
+	return fmt.Sprintf("CRD %s version %s: %s: %s", c.CRD, c.Version, c.Path, c.Reason)
+}
+
+// DiffCRDSchemas returns the breaking changes between the schemas of each
+// version of each CRD in both bundles from and to: removed fields, newly
+// required fields, narrowed types, and removed enum values. CRDs and versions
+// only in one bundle are not compared.
+func DiffCRDSchemas(from, to *apimanifests.Bundle) (changes []SchemaBreakingChange, err error) {
+	fromCRDs, toCRDs := collectCRDs(from), collectCRDs(to)
+	for _, name := range sortedKeys(fromCRDs) {
+		toInfo, ok := toCRDs[name]
+		if !ok {
+			continue
+		}
+		fromInfo := fromCRDs[name]
+		for _, v := range sortedKeys(fromInfo.versions) {
+			if _, ok := toInfo.versions[v]; !ok {
+				continue
+			}
+			fromSchema, err := toV1Schema(fromInfo.versions[v])
+			if err != nil {
+				return nil, fmt.Errorf("error reading CRD %s version %s schema: %v", name, v, err)
+			}
+			toSchema, err := toV1Schema(toInfo.versions[v])
+			if err != nil {
+				return nil, fmt.Errorf("error reading CRD %s version %s schema: %v", name, v, err)
+			}
+			// A version without a schema accepts any object, so a new schema
+			// can only narrow it, but there is nothing to compare field by field.
+			if fromSchema == nil || toSchema == nil {
+				continue
+			}
+			for _, c := range diffSchemas("", *fromSchema, *toSchema) {
+				c.CRD, c.Version = name, v
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// toV1Schema converts schema, a v1 or v1beta1 schema pointer, to a v1 schema.
+// The two schema types share the same serialized form.
+func toV1Schema(schema interface{}) (*apiextv1.JSONSchemaProps, error) {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	if string(b) == "null" {
+		return nil, nil
+	}
+	out := &apiextv1.JSONSchemaProps{}
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// diffSchemas returns the breaking changes from schema from to schema to,
+// both at path.
+func diffSchemas(path string, from, to apiextv1.JSONSchemaProps) (changes []SchemaBreakingChange) {
+	add := func(path, format string, args ...interface{}) {
+		if path == "" {
+			path = "."
+		}
+		changes = append(changes, SchemaBreakingChange{Path: path, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	if reason := narrowedType(from.Type, to.Type); reason != "" {
+		add(path, "%s", reason)
+	}
+
+	if len(to.Enum) != 0 {
+		if len(from.Enum) == 0 {
+			add(path, "enum %s added", enumString(to.Enum))
+		} else {
+			toVals := make(map[string]struct{}, len(to.Enum))
+			for _, e := range to.Enum {
+				toVals[string(e.Raw)] = struct{}{}
+			}
+			for _, e := range from.Enum {
+				if _, ok := toVals[string(e.Raw)]; !ok {
+					add(path, "enum value %s removed", e.Raw)
+				}
+			}
+		}
+	}
+
+	fromRequired := stringSet(from.Required)
+	for _, name := range to.Required {
+		if _, ok := fromRequired[name]; !ok {
+			add(path+"."+name, "field is now required")
+		}
+	}
+	for _, name := range sortedKeys(from.Properties) {
+		toProp, ok := to.Properties[name]
+		if !ok {
+			// An object without properties accepts any field, so only report
+			// removals from objects that still constrain their fields.
+			if len(to.Properties) == 0 && to.AdditionalProperties == nil {
+				continue
+			}
+			if _, required := fromRequired[name]; required {
+				add(path+"."+name, "required field removed")
+			} else {
+				add(path+"."+name, "field removed")
+			}
+			continue
+		}
+		changes = append(changes, diffSchemas(path+"."+name, from.Properties[name], toProp)...)
+	}
+
+	if from.Items != nil && from.Items.Schema != nil && to.Items != nil && to.Items.Schema != nil {
+		changes = append(changes, diffSchemas(path+"[*]", *from.Items.Schema, *to.Items.Schema)...)
+	}
+	if from.AdditionalProperties != nil && from.AdditionalProperties.Schema != nil &&
+		to.AdditionalProperties != nil && to.AdditionalProperties.Schema != nil {
+		changes = append(changes, diffSchemas(path+".*", *from.AdditionalProperties.Schema, *to.AdditionalProperties.Schema)...)
+	}
+	return changes
+}
+
+// narrowedType returns a reason if type to accepts fewer values than type from.
+func narrowedType(from, to string) string {
+	switch {
+	case from == to, to == "", from == "integer" && to == "number":
+		return ""
+	case from == "":
+		return fmt.Sprintf("type narrowed to %s", to)
+	case from == "number" && to == "integer":
+		return "type narrowed from number to integer"
+	}
+	return fmt.Sprintf("type changed from %s to %s", from, to)
+}
+
+func enumString(enum []apiextv1.JSON) string {
+	vals := make([]string, len(enum))
+	for i, e := range enum {
+		vals[i] = string(e.Raw)
+	}
+	return "[" + strings.Join(vals, ", ") + "]"
+}
+
+func stringSet(strs []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(strs))
+	for _, s := range strs {
+		set[s] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("DiffCRDSchemas", func() {
+	const crdName = "memcacheds.cache.example.com"
+
+	var fromSpec, toSpec apiextv1.JSONSchemaProps
+
+	newSpec := func() apiextv1.JSONSchemaProps {
+		return apiextv1.JSONSchemaProps{
+			Type:     "object",
+			Required: []string{"size"},
+			Properties: map[string]apiextv1.JSONSchemaProps{
+				"size":  {Type: "number"},
+				"image": {Type: "string"},
+				"mode": {Type: "string", Enum: []apiextv1.JSON{
+					{Raw: []byte(`"fast"`)}, {Raw: []byte(`"safe"`)},
+				}},
+				"nodes": {Type: "array", Items: &apiextv1.JSONSchemaPropsOrArray{
+					Schema: &apiextv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextv1.JSONSchemaProps{
+						"name": {Type: "string"},
+					}},
+				}},
+			},
+		}
+	}
+	bundleFor := func(spec apiextv1.JSONSchemaProps) *apimanifests.Bundle {
+		return &apimanifests.Bundle{V1CRDs: []*apiextv1.CustomResourceDefinition{
+			newDiffTestCRDWithSchema(crdName, "v1alpha1", spec),
+		}}
+	}
+	diff := func() []SchemaBreakingChange {
+		changes, err := DiffCRDSchemas(bundleFor(fromSpec), bundleFor(toSpec))
+		Expect(err).NotTo(HaveOccurred())
+		return changes
+	}
+	change := func(path, reason string) SchemaBreakingChange {
+		return SchemaBreakingChange{CRD: crdName, Version: "v1alpha1", Path: path, Reason: reason}
+	}
+
+	BeforeEach(func() {
+		fromSpec, toSpec = newSpec(), newSpec()
+	})
+
+	It("returns no changes for identical schemas", func() {
+		Expect(diff()).To(BeEmpty())
+	})
+	It("returns no changes for added optional fields, widened types, or added enum values", func() {
+		toSpec.Properties["replicas"] = apiextv1.JSONSchemaProps{Type: "integer"}
+		mode := toSpec.Properties["mode"]
+		mode.Enum = append(mode.Enum, apiextv1.JSON{Raw: []byte(`"slow"`)})
+		toSpec.Properties["mode"] = mode
+		fromSpec.Properties["size"] = apiextv1.JSONSchemaProps{Type: "integer"}
+		Expect(diff()).To(BeEmpty())
+	})
+	It("detects removed required and optional fields", func() {
+		delete(toSpec.Properties, "size")
+		delete(toSpec.Properties, "image")
+		toSpec.Required = nil
+		Expect(diff()).To(Equal([]SchemaBreakingChange{
+			change(".spec.image", "field removed"),
+			change(".spec.size", "required field removed"),
+		}))
+	})
+	It("detects newly required fields", func() {
+		toSpec.Required = append(toSpec.Required, "image")
+		Expect(diff()).To(Equal([]SchemaBreakingChange{
+			change(".spec.image", "field is now required"),
+		}))
+	})
+	It("detects narrowed and changed types, including in array items", func() {
+		toSpec.Properties["size"] = apiextv1.JSONSchemaProps{Type: "integer"}
+		toSpec.Properties["nodes"].Items.Schema.Properties["name"] = apiextv1.JSONSchemaProps{Type: "boolean"}
+		Expect(diff()).To(Equal([]SchemaBreakingChange{
+			change(".spec.nodes[*].name", "type changed from string to boolean"),
+			change(".spec.size", "type narrowed from number to integer"),
+		}))
+	})
+	It("detects removed and added enums", func() {
+		mode := toSpec.Properties["mode"]
+		mode.Enum = mode.Enum[:1]
+		toSpec.Properties["mode"] = mode
+		image := toSpec.Properties["image"]
+		image.Enum = []apiextv1.JSON{{Raw: []byte(`"memcached"`)}}
+		toSpec.Properties["image"] = image
+		Expect(diff()).To(Equal([]SchemaBreakingChange{
+			change(".spec.image", `enum ["memcached"] added`),
+			change(".spec.mode", `enum value "safe" removed`),
+		}))
+	})
+	It("compares v1beta1 schemas", func() {
+		newV1beta1 := func(spec apiextv1beta1.JSONSchemaProps) *apimanifests.Bundle {
+			return &apimanifests.Bundle{V1beta1CRDs: []*apiextv1beta1.CustomResourceDefinition{{
+				ObjectMeta: metav1.ObjectMeta{Name: crdName},
+				Spec: apiextv1beta1.CustomResourceDefinitionSpec{
+					Version: "v1alpha1",
+					Validation: &apiextv1beta1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
+							Type:       "object",
+							Properties: map[string]apiextv1beta1.JSONSchemaProps{"spec": spec},
+						},
+					},
+				},
+			}}}
+		}
+		from := newV1beta1(apiextv1beta1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiextv1beta1.JSONSchemaProps{"size": {Type: "integer"}, "image": {Type: "string"}},
+		})
+		to := newV1beta1(apiextv1beta1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiextv1beta1.JSONSchemaProps{"size": {Type: "string"}},
+		})
+		changes, err := DiffCRDSchemas(from, to)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(Equal([]SchemaBreakingChange{
+			change(".spec.image", "field removed"),
+			change(".spec.size", "type changed from integer to string"),
+		}))
+	})
+	It("formats changes with the CRD, version, and JSON path", func() {
+		Expect(change(".spec.size", "field removed").String()).To(Equal(
+			"CRD memcacheds.cache.example.com version v1alpha1: .spec.size: field removed"))
+	})
+})
+
+func newDiffTestCRDWithSchema(name, version string, spec apiextv1.JSONSchemaProps) *apiextv1.CustomResourceDefinition {
+	return &apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Versions: []apiextv1.CustomResourceDefinitionVersion{{
+				Name: version,
+				Schema: &apiextv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+						Type:       "object",
+						Properties: map[string]apiextv1.JSONSchemaProps{"spec": spec},
+					},
+				},
+			}},
+		},
+	}
+}
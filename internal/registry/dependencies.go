@@ -0,0 +1,187 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// DependenciesFile is the name of the bundle metadata file listing the
+// packages and APIs a bundle's operator depends on.
+const DependenciesFile = "dependencies.yaml"
+
+// Dependency is a bundle dependency on an operator package in a version range,
+// or on an API provided by another operator.
+type Dependency struct {
+	// Type is one of registry.PackageType or registry.GVKType.
+	Type string `json:"type"`
+	// Value is a registry.PackageDependency or registry.GVKDependency for Type.
+	Value interface{} `json:"value"`
+}
+
+// dependencies is the content of a DependenciesFile.
+type dependencies struct {
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+var kindRe = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// ParseDependency parses a dependency in one of the formats:
+//
+//	olm.package:<package name>:<version range>, ex. olm.package:etcd:>=0.9.0 <0.10.0
+//	olm.gvk:<group>/<version>/<kind>, ex. olm.gvk:etcd.database.coreos.com/v1beta2/EtcdCluster
+//
+// Version ranges use the semver range syntax, and a single version matches
+// only that version.
+func ParseDependency(s string) (Dependency, error) {
+	split := strings.SplitN(s, ":", 2)
+	if len(split) != 2 {
+		return Dependency{}, fmt.Errorf("dependency %q must have the format <type>:<value>", s)
+	}
+	typ, value := split[0], split[1]
+	switch typ {
+	case registry.PackageType:
+		fields := strings.SplitN(value, ":", 2)
+		if len(fields) != 2 {
+			return Dependency{}, fmt.Errorf("%s dependency %q must have the format %s:<package name>:<version range>", typ, s, typ)
+		}
+		dep := registry.PackageDependency{PackageName: fields[0], Version: strings.TrimSpace(fields[1])}
+		if err := validatePackageDependency(dep); err != nil {
+			return Dependency{}, fmt.Errorf("invalid %s dependency %q: %v", typ, s, err)
+		}
+		return Dependency{Type: typ, Value: dep}, nil
+	case registry.GVKType:
+		fields := strings.Split(value, "/")
+		if len(fields) != 3 {
+			return Dependency{}, fmt.Errorf("%s dependency %q must have the format %s:<group>/<version>/<kind>", typ, s, typ)
+		}
+		dep := registry.GVKDependency{Group: fields[0], Version: fields[1], Kind: fields[2]}
+		if err := validateGVKDependency(dep); err != nil {
+			return Dependency{}, fmt.Errorf("invalid %s dependency %q: %v", typ, s, err)
+		}
+		return Dependency{Type: typ, Value: dep}, nil
+	}
+	return Dependency{}, fmt.Errorf("dependency %q type must be one of %q or %q", s, registry.PackageType, registry.GVKType)
+}
+
+func validatePackageDependency(dep registry.PackageDependency) error {
+	if dep.PackageName == "" {
+		return errors.New("package name must be set")
+	}
+	if dep.Version == "" {
+		return errors.New("version must be set")
+	}
+	if _, err := semver.ParseRange(dep.Version); err != nil {
+		return fmt.Errorf("version %q is not a valid semver version or range: %v", dep.Version, err)
+	}
+	return nil
+}
+
+func validateGVKDependency(dep registry.GVKDependency) error {
+	if errs := validation.IsDNS1123Subdomain(dep.Group); len(errs) != 0 {
+		return fmt.Errorf("group %q is invalid: %s", dep.Group, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1035Label(dep.Version); len(errs) != 0 {
+		return fmt.Errorf("version %q is invalid: %s", dep.Version, strings.Join(errs, ", "))
+	}
+	if !kindRe.MatchString(dep.Kind) {
+		return fmt.Errorf("kind %q must be alphanumeric and start with an uppercase letter", dep.Kind)
+	}
+	return nil
+}
+
+// ValidateDependencies validates each entry in the DependenciesFile in bundleRoot's
+// metadata directory, if that file exists. Package dependencies must have a valid
+// semver version or range and must not be on packageName, the bundle's own
+// package, and GVK dependencies must be well-formed. An error is returned for
+// each invalid entry, identified by its index in the file.
+func ValidateDependencies(bundleRoot, packageName string) ([]apierrors.Error, error) {
+	path := filepath.Join(bundleRoot, registrybundle.MetadataDir, DependenciesFile)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return validateDependencies(b, packageName)
+}
+
+func validateDependencies(b []byte, packageName string) (errs []apierrors.Error, err error) {
+	deps := struct {
+		Dependencies []struct {
+			Type  string          `json:"type"`
+			Value json.RawMessage `json:"value"`
+		} `json:"dependencies"`
+	}{}
+	if err := yaml.Unmarshal(b, &deps); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", DependenciesFile, err)
+	}
+
+	for i, d := range deps.Dependencies {
+		field := fmt.Sprintf("dependencies[%d]", i)
+		switch d.Type {
+		case registry.PackageType:
+			dep := registry.PackageDependency{}
+			if err := json.Unmarshal(d.Value, &dep); err != nil {
+				errs = append(errs, apierrors.ErrInvalidParse(fmt.Sprintf("%s.value is invalid: %v", field, err), d.Type))
+			} else if err := validatePackageDependency(dep); err != nil {
+				errs = append(errs, apierrors.ErrInvalidBundle(fmt.Sprintf("%s is invalid: %v", field, err), dep))
+			} else if dep.PackageName == packageName {
+				errs = append(errs, apierrors.ErrInvalidBundle(
+					fmt.Sprintf("%s is a dependency on the bundle's own package %q", field, packageName), dep))
+			}
+		case registry.GVKType:
+			dep := registry.GVKDependency{}
+			if err := json.Unmarshal(d.Value, &dep); err != nil {
+				errs = append(errs, apierrors.ErrInvalidParse(fmt.Sprintf("%s.value is invalid: %v", field, err), d.Type))
+			} else if err := validateGVKDependency(dep); err != nil {
+				errs = append(errs, apierrors.ErrInvalidBundle(fmt.Sprintf("%s is invalid: %v", field, err), dep))
+			}
+		default:
+			errs = append(errs, apierrors.ErrInvalidBundle(fmt.Sprintf("%s.type %q must be one of %q or %q",
+				field, d.Type, registry.PackageType, registry.GVKType), d.Type))
+		}
+	}
+	return errs, nil
+}
+
+// WriteDependencies writes deps to the DependenciesFile in bundleRoot's
+// metadata directory, overwriting any existing file.
+func WriteDependencies(bundleRoot string, deps []Dependency) error {
+	b, err := yaml.Marshal(dependencies{Dependencies: deps})
+	if err != nil {
+		return err
+	}
+	metadataDir := filepath.Join(bundleRoot, registrybundle.MetadataDir)
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(metadataDir, DependenciesFile), b, 0666)
+}
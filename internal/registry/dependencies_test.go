@@ -0,0 +1,167 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+)
+
+var _ = Describe("Dependencies", func() {
+	Describe("ParseDependency", func() {
+		It("parses a package dependency with a version range", func() {
+			dep, err := ParseDependency("olm.package:etcd:>=0.9.0 <0.10.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dep).To(Equal(Dependency{
+				Type:  registry.PackageType,
+				Value: registry.PackageDependency{PackageName: "etcd", Version: ">=0.9.0 <0.10.0"},
+			}))
+		})
+		It("parses a package dependency with a single version", func() {
+			dep, err := ParseDependency("olm.package:etcd:0.9.2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dep.Value).To(Equal(registry.PackageDependency{PackageName: "etcd", Version: "0.9.2"}))
+		})
+		It("parses a GVK dependency", func() {
+			dep, err := ParseDependency("olm.gvk:etcd.database.coreos.com/v1beta2/EtcdCluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dep).To(Equal(Dependency{
+				Type:  registry.GVKType,
+				Value: registry.GVKDependency{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"},
+			}))
+		})
+		It("returns an error for invalid dependencies", func() {
+			for _, s := range []string{
+				"etcd",
+				"olm.operator:etcd:0.9.0",
+				"olm.package:etcd",
+				"olm.package::0.9.0",
+				"olm.package:etcd:",
+				"olm.package:etcd:latest",
+				"olm.gvk:etcd.database.coreos.com/EtcdCluster",
+				"olm.gvk:/v1beta2/EtcdCluster",
+				"olm.gvk:Etcd_Database/v1beta2/EtcdCluster",
+				"olm.gvk:etcd.database.coreos.com/V1/EtcdCluster",
+				"olm.gvk:etcd.database.coreos.com/v1beta2/etcdCluster",
+			} {
+				_, err := ParseDependency(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+	})
+
+	Describe("WriteDependencies", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "dependencies")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes package and GVK dependencies to the bundle's metadata", func() {
+			deps := []Dependency{
+				{Type: registry.PackageType, Value: registry.PackageDependency{PackageName: "etcd", Version: ">=0.9.0"}},
+				{Type: registry.GVKType, Value: registry.GVKDependency{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"}},
+			}
+			Expect(WriteDependencies(dir, deps)).To(Succeed())
+			b, err := ioutil.ReadFile(filepath.Join(dir, "metadata", DependenciesFile))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(MatchYAML(`dependencies:
+- type: olm.package
+  value:
+    packageName: etcd
+    version: '>=0.9.0'
+- type: olm.gvk
+  value:
+    group: etcd.database.coreos.com
+    kind: EtcdCluster
+    version: v1beta2
+`))
+		})
+	})
+
+	Describe("ValidateDependencies", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "dependencies")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("returns no errors if the bundle has no dependencies", func() {
+			errs, err := ValidateDependencies(dir, "memcached-operator")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(BeEmpty())
+		})
+		It("returns no errors for valid dependencies", func() {
+			deps := []Dependency{
+				{Type: registry.PackageType, Value: registry.PackageDependency{PackageName: "etcd", Version: ">=0.9.0 <0.10.0"}},
+				{Type: registry.GVKType, Value: registry.GVKDependency{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"}},
+			}
+			Expect(WriteDependencies(dir, deps)).To(Succeed())
+			errs, err := ValidateDependencies(dir, "memcached-operator")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(BeEmpty())
+		})
+		It("returns an error for each invalid dependency by index", func() {
+			errs, err := validateDependencies([]byte(`dependencies:
+- type: olm.package
+  value:
+    packageName: etcd
+    version: latest
+- type: olm.gvk
+  value:
+    group: etcd.database.coreos.com
+    version: v1beta2
+    kind: etcdCluster
+- type: olm.package
+  value:
+    packageName: memcached-operator
+    version: '>=0.1.0'
+- type: olm.operator
+  value:
+    name: etcd
+- type: olm.gvk
+  value:
+    group: etcd.database.coreos.com
+    version: v1beta2
+    kind: EtcdCluster
+`), "memcached-operator")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(HaveLen(4))
+			Expect(errs[0].Detail).To(ContainSubstring(`dependencies[0] is invalid: version "latest"`))
+			Expect(errs[1].Detail).To(ContainSubstring(`dependencies[1] is invalid: kind "etcdCluster"`))
+			Expect(errs[2].Detail).To(ContainSubstring(`dependencies[2] is a dependency on the bundle's own package "memcached-operator"`))
+			Expect(errs[3].Detail).To(ContainSubstring(`dependencies[3].type "olm.operator"`))
+		})
+		It("returns an error for an unparseable file", func() {
+			_, err := validateDependencies([]byte("dependencies: {"), "memcached-operator")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,173 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DeprecatedAPIsValidator is the name of the optional validator that checks
+	// a bundle's manifests for APIs removed in particular cluster versions.
+	DeprecatedAPIsValidator = "k8s-deprecated-apis"
+	// K8sVersionOptionalValue is the optional value key whose value is a comma-separated
+	// list of Kubernetes versions, ex. "1.21,1.22", to check for removed APIs.
+	K8sVersionOptionalValue = "k8s-version"
+	// OCPVersionOptionalValue is the optional value key whose value is a comma-separated
+	// list of OpenShift versions, ex. "4.8,4.9", to check for removed APIs.
+	OCPVersionOptionalValue = "ocp-version"
+)
+
+// removedAPI is a group version whose kinds were removed in a Kubernetes minor version.
+type removedAPI struct {
+	groupVersion string
+	kinds        []string
+	// removedIn is the Kubernetes 1.x minor version the kinds were removed in.
+	removedIn uint64
+	// replacement is the group version, or feature, that should be used instead.
+	replacement string
+}
+
+// removedAPIs lists APIs an operator bundle might contain that have been removed from Kubernetes.
+// See https://kubernetes.io/docs/reference/using-api/deprecation-guide/.
+var removedAPIs = []removedAPI{
+	{"extensions/v1beta1", []string{"DaemonSet", "Deployment", "ReplicaSet"}, 16, "apps/v1"},
+	{"extensions/v1beta1", []string{"NetworkPolicy"}, 16, "networking.k8s.io/v1"},
+	{"extensions/v1beta1", []string{"PodSecurityPolicy"}, 16, "policy/v1beta1"},
+	{"apps/v1beta1", []string{"Deployment", "StatefulSet"}, 16, "apps/v1"},
+	{"apps/v1beta2", []string{"DaemonSet", "Deployment", "ReplicaSet", "StatefulSet"}, 16, "apps/v1"},
+	{"admissionregistration.k8s.io/v1beta1",
+		[]string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}, 22, "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", []string{"CustomResourceDefinition"}, 22, "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", []string{"APIService"}, 22, "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", []string{"CertificateSigningRequest"}, 22, "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", []string{"Lease"}, 22, "coordination.k8s.io/v1"},
+	{"extensions/v1beta1", []string{"Ingress"}, 22, "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", []string{"Ingress", "IngressClass"}, 22, "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1",
+		[]string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, 22, "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", []string{"PriorityClass"}, 22, "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, 22, "storage.k8s.io/v1"},
+	{"batch/v1beta1", []string{"CronJob"}, 25, "batch/v1"},
+	{"discovery.k8s.io/v1beta1", []string{"EndpointSlice"}, 25, "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", []string{"Event"}, 25, "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", []string{"HorizontalPodAutoscaler"}, 25, "autoscaling/v2"},
+	{"policy/v1beta1", []string{"PodDisruptionBudget"}, 25, "policy/v1"},
+	{"policy/v1beta1", []string{"PodSecurityPolicy"}, 25, "Pod Security Admission"},
+	{"node.k8s.io/v1beta1", []string{"RuntimeClass"}, 25, "node.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1",
+		[]string{"FlowSchema", "PriorityLevelConfiguration"}, 26, "flowcontrol.apiserver.k8s.io/v1"},
+	{"autoscaling/v2beta2", []string{"HorizontalPodAutoscaler"}, 26, "autoscaling/v2"},
+	{"storage.k8s.io/v1beta1", []string{"CSIStorageCapacity"}, 27, "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2",
+		[]string{"FlowSchema", "PriorityLevelConfiguration"}, 29, "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3",
+		[]string{"FlowSchema", "PriorityLevelConfiguration"}, 32, "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// DeprecatedAPIsTarget is a cluster version to check a bundle's manifests against for removed APIs.
+type DeprecatedAPIsTarget struct {
+	// Name is the version as it was given, ex. "k8s 1.22" or "ocp 4.9".
+	Name string
+	// KubeMinor is the Kubernetes 1.x minor version clusters of this version run.
+	KubeMinor uint64
+}
+
+func (t DeprecatedAPIsTarget) String() string {
+	return t.Name
+}
+
+// ParseDeprecatedAPIsTargets parses comma-separated lists of Kubernetes and OpenShift
+// versions, ex. "1.21,1.22" and "4.8,4.9", into targets in the order they were given.
+func ParseDeprecatedAPIsTargets(k8sVersions, ocpVersions string) (targets []DeprecatedAPIsTarget, err error) {
+	for _, v := range splitVersions(k8sVersions) {
+		sv, err := semver.ParseTolerant(v)
+		if err != nil || sv.Major != 1 {
+			return nil, fmt.Errorf("%s %q must be a Kubernetes version of the form 1.<minor>", K8sVersionOptionalValue, v)
+		}
+		targets = append(targets, DeprecatedAPIsTarget{
+			Name:      fmt.Sprintf("k8s %d.%d", sv.Major, sv.Minor),
+			KubeMinor: sv.Minor,
+		})
+	}
+	for _, v := range splitVersions(ocpVersions) {
+		sv, err := semver.ParseTolerant(v)
+		if err != nil || sv.Major != 4 || sv.Minor == 0 {
+			return nil, fmt.Errorf("%s %q must be an OpenShift version of the form 4.<minor>", OCPVersionOptionalValue, v)
+		}
+		targets = append(targets, DeprecatedAPIsTarget{
+			Name:      fmt.Sprintf("ocp %d.%d", sv.Major, sv.Minor),
+			KubeMinor: ocpToKubeMinor(sv.Minor),
+		})
+	}
+	return targets, nil
+}
+
+// splitVersions splits a comma-separated list of versions, dropping empty elements.
+func splitVersions(s string) (versions []string) {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// ocpToKubeMinor returns the Kubernetes minor version OpenShift 4.<ocpMinor> is based on.
+// OpenShift 4.3 skipped Kubernetes 1.15, rebasing from 1.14 onto 1.16.
+func ocpToKubeMinor(ocpMinor uint64) uint64 {
+	if ocpMinor < 3 {
+		return ocpMinor + 12
+	}
+	return ocpMinor + 13
+}
+
+// ValidateDeprecatedAPIs returns an error for each object in objs whose API
+// was removed in or before target's Kubernetes version.
+func ValidateDeprecatedAPIs(objs []*unstructured.Unstructured, target DeprecatedAPIsTarget) (errs []apierrors.Error) {
+	for _, obj := range objs {
+		api, isRemoved := findRemovedAPI(obj.GetAPIVersion(), obj.GetKind())
+		if !isRemoved || api.removedIn > target.KubeMinor {
+			continue
+		}
+		errs = append(errs, apierrors.ErrFailedValidation(
+			fmt.Sprintf("%s %q uses %s, which was removed in Kubernetes 1.%d: migrate to %s",
+				obj.GetKind(), obj.GetName(), api.groupVersion, api.removedIn, api.replacement),
+			obj.GetName()))
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Detail < errs[j].Detail })
+	return errs
+}
+
+// findRemovedAPI returns the removedAPI kind of groupVersion belongs to, if any.
+func findRemovedAPI(groupVersion, kind string) (removedAPI, bool) {
+	for _, api := range removedAPIs {
+		if api.groupVersion != groupVersion {
+			continue
+		}
+		for _, k := range api.kinds {
+			if k == kind {
+				return api, true
+			}
+		}
+	}
+	return removedAPI{}, false
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Deprecated APIs", func() {
+	Describe("ParseDeprecatedAPIsTargets", func() {
+		It("parses Kubernetes and OpenShift version lists", func() {
+			targets, err := ParseDeprecatedAPIsTargets("1.21, v1.22.3,", "4.2,4.3,4.9")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(targets).To(Equal([]DeprecatedAPIsTarget{
+				{Name: "k8s 1.21", KubeMinor: 21},
+				{Name: "k8s 1.22", KubeMinor: 22},
+				{Name: "ocp 4.2", KubeMinor: 14},
+				{Name: "ocp 4.3", KubeMinor: 16},
+				{Name: "ocp 4.9", KubeMinor: 22},
+			}))
+		})
+		It("returns no targets for empty lists", func() {
+			targets, err := ParseDeprecatedAPIsTargets("", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(targets).To(BeEmpty())
+		})
+		DescribeTable("returns an error for an invalid version",
+			func(k8sVersions, ocpVersions, errMsg string) {
+				_, err := ParseDeprecatedAPIsTargets(k8sVersions, ocpVersions)
+				Expect(err).To(MatchError(ContainSubstring(errMsg)))
+			},
+			Entry("with a non-version", "1.21,latest", "", `k8s-version "latest"`),
+			Entry("with a non-1.x Kubernetes version", "2.0", "", `k8s-version "2.0"`),
+			Entry("with a non-4.x OpenShift version", "", "3.11", `ocp-version "3.11"`),
+			Entry("with OpenShift 4.0", "", "4.0", `ocp-version "4.0"`),
+		)
+	})
+
+	Describe("ValidateDeprecatedAPIs", func() {
+		var objs []*unstructured.Unstructured
+
+		BeforeEach(func() {
+			objs = []*unstructured.Unstructured{
+				newDeprecatedAPIsObject("operators.coreos.com/v1alpha1", "ClusterServiceVersion", "memcached-operator.v0.0.1"),
+				newDeprecatedAPIsObject("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "memcacheds.cache.example.com"),
+				newDeprecatedAPIsObject("policy/v1beta1", "PodDisruptionBudget", "memcached-operator"),
+				newDeprecatedAPIsObject("policy/v1beta1", "PodSecurityPolicy", "memcached-operator"),
+			}
+		})
+
+		DescribeTable("returns an error for each API removed in the target version",
+			func(kubeMinor uint64, expected []string) {
+				errs := ValidateDeprecatedAPIs(objs, DeprecatedAPIsTarget{Name: "test", KubeMinor: kubeMinor})
+				details := make([]string, len(errs))
+				for i, err := range errs {
+					details[i] = err.Detail
+				}
+				Expect(details).To(Equal(expected))
+			},
+			Entry("before any removal", uint64(21), []string{}),
+			Entry("after the CRD removal", uint64(22), []string{
+				`CustomResourceDefinition "memcacheds.cache.example.com" uses apiextensions.k8s.io/v1beta1, ` +
+					`which was removed in Kubernetes 1.22: migrate to apiextensions.k8s.io/v1`,
+			}),
+			Entry("after the PodDisruptionBudget removal", uint64(25), []string{
+				`CustomResourceDefinition "memcacheds.cache.example.com" uses apiextensions.k8s.io/v1beta1, ` +
+					`which was removed in Kubernetes 1.22: migrate to apiextensions.k8s.io/v1`,
+				`PodDisruptionBudget "memcached-operator" uses policy/v1beta1, ` +
+					`which was removed in Kubernetes 1.25: migrate to policy/v1`,
+				`PodSecurityPolicy "memcached-operator" uses policy/v1beta1, ` +
+					`which was removed in Kubernetes 1.25: migrate to Pod Security Admission`,
+			}),
+		)
+	})
+})
+
+func newDeprecatedAPIsObject(apiVersion, kind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	return obj
+}
@@ -0,0 +1,314 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+// ChangeCategory groups related bundle changes.
+type ChangeCategory string
+
+const (
+	CategoryVersion     ChangeCategory = "Version"
+	CategoryCRDs        ChangeCategory = "CustomResourceDefinitions"
+	CategoryPermissions ChangeCategory = "Permissions"
+	CategoryImages      ChangeCategory = "Images"
+)
+
+// ChangeCategories is the ordered set of all change categories.
+var ChangeCategories = []ChangeCategory{
+	CategoryVersion,
+	CategoryCRDs,
+	CategoryPermissions,
+	CategoryImages,
+}
+
+// ChangeKind describes how an item changed between two bundles.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "Added"
+	ChangeRemoved  ChangeKind = "Removed"
+	ChangeModified ChangeKind = "Changed"
+)
+
+// Change is a single difference between two bundles.
+type Change struct {
+	Category    ChangeCategory
+	Kind        ChangeKind
+	Description string
+}
+
+// BundleDiff is the set of changes from one bundle to another.
+type BundleDiff struct {
+	Changes []Change
+}
+
+// InCategory returns all changes in category, sorted by kind then description.
+func (d BundleDiff) InCategory(category ChangeCategory) (changes []Change) {
+	for _, c := range d.Changes {
+		if c.Category == category {
+			changes = append(changes, c)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Description < changes[j].Description
+	})
+	return changes
+}
+
+func (d *BundleDiff) add(category ChangeCategory, kind ChangeKind, format string, args ...interface{}) {
+	d.Changes = append(d.Changes, Change{
+		Category:    category,
+		Kind:        kind,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+// DiffBundles returns the changes between bundles from and to, covering CSV
+// version, CRDs and their schemas, install permissions, and deployment images.
+func DiffBundles(from, to *apimanifests.Bundle) (diff BundleDiff) {
+	diffVersion(&diff, from.CSV, to.CSV)
+	diffCRDs(&diff, collectCRDs(from), collectCRDs(to))
+	diffRules(&diff, "cluster-scoped", collectRules(from.CSV, true), collectRules(to.CSV, true))
+	diffRules(&diff, "namespace-scoped", collectRules(from.CSV, false), collectRules(to.CSV, false))
+	diffImages(&diff, collectImages(from.CSV), collectImages(to.CSV))
+	return diff
+}
+
+func diffVersion(diff *BundleDiff, from, to *operatorsv1alpha1.ClusterServiceVersion) {
+	if from == nil || to == nil {
+		return
+	}
+	fromVer, toVer := from.Spec.Version.String(), to.Spec.Version.String()
+	if fromVer != toVer {
+		diff.add(CategoryVersion, ChangeModified, "version from %s to %s", fromVer, toVer)
+	}
+}
+
+// crdInfo holds the fields of a CRD of either API version needed to diff it.
+type crdInfo struct {
+	versions map[string]interface{}
+}
+
+// collectCRDs returns all CRDs in bundle keyed by name, with each served
+// version mapped to its validation schema.
+func collectCRDs(bundle *apimanifests.Bundle) map[string]crdInfo {
+	crds := make(map[string]crdInfo)
+	for _, crd := range bundle.V1CRDs {
+		info := crdInfo{versions: make(map[string]interface{})}
+		for _, v := range crd.Spec.Versions {
+			var schema *apiextv1.JSONSchemaProps
+			if v.Schema != nil {
+				schema = v.Schema.OpenAPIV3Schema
+			}
+			info.versions[v.Name] = schema
+		}
+		crds[crd.GetName()] = info
+	}
+	for _, crd := range bundle.V1beta1CRDs {
+		info := crdInfo{versions: make(map[string]interface{})}
+		var topSchema *apiextv1beta1.JSONSchemaProps
+		if crd.Spec.Validation != nil {
+			topSchema = crd.Spec.Validation.OpenAPIV3Schema
+		}
+		if len(crd.Spec.Versions) == 0 && crd.Spec.Version != "" {
+			info.versions[crd.Spec.Version] = topSchema
+		}
+		for _, v := range crd.Spec.Versions {
+			schema := topSchema
+			if v.Schema != nil {
+				schema = v.Schema.OpenAPIV3Schema
+			}
+			info.versions[v.Name] = schema
+		}
+		crds[crd.GetName()] = info
+	}
+	return crds
+}
+
+func diffCRDs(diff *BundleDiff, from, to map[string]crdInfo) {
+	for _, name := range sortedKeys(to) {
+		if _, ok := from[name]; !ok {
+			diff.add(CategoryCRDs, ChangeAdded, "CRD %s", name)
+		}
+	}
+	for _, name := range sortedKeys(from) {
+		toInfo, ok := to[name]
+		if !ok {
+			diff.add(CategoryCRDs, ChangeRemoved, "CRD %s", name)
+			continue
+		}
+		fromInfo := from[name]
+		for _, v := range sortedKeys(toInfo.versions) {
+			if _, ok := fromInfo.versions[v]; !ok {
+				diff.add(CategoryCRDs, ChangeAdded, "CRD %s version %s", name, v)
+			}
+		}
+		for _, v := range sortedKeys(fromInfo.versions) {
+			toSchema, ok := toInfo.versions[v]
+			if !ok {
+				diff.add(CategoryCRDs, ChangeRemoved, "CRD %s version %s", name, v)
+				continue
+			}
+			if !schemasEqual(fromInfo.versions[v], toSchema) {
+				diff.add(CategoryCRDs, ChangeModified, "CRD %s version %s schema", name, v)
+			}
+		}
+	}
+}
+
+// schemasEqual returns true if schemas from and to, each a v1 or v1beta1 schema
+// pointer, are the same once converted to v1, so a CRD moved from v1beta1 to v1
+// without changing its schema is not reported as changed. Schemas that cannot
+// be converted are compared as-is.
+func schemasEqual(from, to interface{}) bool {
+	fromV1, fromErr := toV1Schema(from)
+	toV1, toErr := toV1Schema(to)
+	if fromErr != nil || toErr != nil {
+		return reflect.DeepEqual(from, to)
+	}
+	return reflect.DeepEqual(fromV1, toV1)
+}
+
+// collectRules returns a set of "<service account>: <verb> <group>/<resource>"
+// strings, one per verb and resource granted by the CSV's install strategy.
+func collectRules(csv *operatorsv1alpha1.ClusterServiceVersion, cluster bool) map[string]struct{} {
+	rules := make(map[string]struct{})
+	if csv == nil {
+		return rules
+	}
+	perms := csv.Spec.InstallStrategy.StrategySpec.Permissions
+	if cluster {
+		perms = csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions
+	}
+	for _, perm := range perms {
+		for _, rule := range perm.Rules {
+			for _, r := range expandRule(rule) {
+				rules[fmt.Sprintf("%s: %s", perm.ServiceAccountName, r)] = struct{}{}
+			}
+		}
+	}
+	return rules
+}
+
+func expandRule(rule rbacv1.PolicyRule) (out []string) {
+	for _, verb := range rule.Verbs {
+		for _, url := range rule.NonResourceURLs {
+			out = append(out, fmt.Sprintf("%s %s", verb, url))
+		}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				gr := resource
+				if group != "" {
+					gr = fmt.Sprintf("%s/%s", group, resource)
+				}
+				if len(rule.ResourceNames) == 0 {
+					out = append(out, fmt.Sprintf("%s %s", verb, gr))
+				}
+				for _, name := range rule.ResourceNames {
+					out = append(out, fmt.Sprintf("%s %s/%s", verb, gr, name))
+				}
+			}
+		}
+	}
+	return out
+}
+
+func diffRules(diff *BundleDiff, scope string, from, to map[string]struct{}) {
+	for _, r := range sortedKeys(to) {
+		if _, ok := from[r]; !ok {
+			diff.add(CategoryPermissions, ChangeAdded, "%s permission %s", scope, r)
+		}
+	}
+	for _, r := range sortedKeys(from) {
+		if _, ok := to[r]; !ok {
+			diff.add(CategoryPermissions, ChangeRemoved, "%s permission %s", scope, r)
+		}
+	}
+}
+
+// collectImages returns a map of "<deployment>/<container>" to image for
+// every container and init container in the CSV's install strategy.
+func collectImages(csv *operatorsv1alpha1.ClusterServiceVersion) map[string]string {
+	images := make(map[string]string)
+	if csv == nil {
+		return images
+	}
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		podSpec := dep.Spec.Template.Spec
+		for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+			images[fmt.Sprintf("%s/%s", dep.Name, c.Name)] = c.Image
+		}
+	}
+	return images
+}
+
+func diffImages(diff *BundleDiff, from, to map[string]string) {
+	for _, name := range sortedKeys(to) {
+		fromImage, ok := from[name]
+		if !ok {
+			diff.add(CategoryImages, ChangeAdded, "container %s image %s", name, to[name])
+		} else if fromImage != to[name] {
+			diff.add(CategoryImages, ChangeModified, "container %s image from %s to %s", name, fromImage, to[name])
+		}
+	}
+	for _, name := range sortedKeys(from) {
+		if _, ok := to[name]; !ok {
+			diff.add(CategoryImages, ChangeRemoved, "container %s image %s", name, from[name])
+		}
+	}
+}
+
+// sortedKeys returns the sorted keys of m, which must be a map with string keys.
+func sortedKeys(m interface{}) (keys []string) {
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ParseChangeCategories parses a list of category names, case-insensitively,
+// into change categories.
+func ParseChangeCategories(names []string) (categories []ChangeCategory, err error) {
+	for _, name := range names {
+		found := false
+		for _, c := range ChangeCategories {
+			if strings.EqualFold(name, string(c)) {
+				categories = append(categories, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown change category %q", name)
+		}
+	}
+	return categories, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"github.com/blang/semver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/lib/version"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("DiffBundles", func() {
+	var from, to *apimanifests.Bundle
+
+	BeforeEach(func() {
+		from = newDiffTestBundle("0.1.0", "quay.io/example/memcached-operator:v0.1.0",
+			[]string{"get", "list"}, newDiffTestCRD("memcacheds.cache.example.com", "v1alpha1"))
+		to = newDiffTestBundle("0.1.0", "quay.io/example/memcached-operator:v0.1.0",
+			[]string{"get", "list"}, newDiffTestCRD("memcacheds.cache.example.com", "v1alpha1"))
+	})
+
+	It("returns no changes for identical bundles", func() {
+		Expect(DiffBundles(from, to).Changes).To(BeEmpty())
+	})
+	It("detects a version bump", func() {
+		to = newDiffTestBundle("0.2.0", "quay.io/example/memcached-operator:v0.1.0",
+			[]string{"get", "list"}, newDiffTestCRD("memcacheds.cache.example.com", "v1alpha1"))
+		Expect(DiffBundles(from, to).InCategory(CategoryVersion)).To(Equal([]Change{
+			{Category: CategoryVersion, Kind: ChangeModified, Description: "version from 0.1.0 to 0.2.0"},
+		}))
+	})
+	It("detects added and removed CRDs and versions", func() {
+		to.V1CRDs = []*apiextv1.CustomResourceDefinition{
+			newDiffTestCRD("memcacheds.cache.example.com", "v1alpha1", "v1beta1"),
+			newDiffTestCRD("redis.cache.example.com", "v1"),
+		}
+		Expect(DiffBundles(from, to).InCategory(CategoryCRDs)).To(Equal([]Change{
+			{Category: CategoryCRDs, Kind: ChangeAdded, Description: "CRD memcacheds.cache.example.com version v1beta1"},
+			{Category: CategoryCRDs, Kind: ChangeAdded, Description: "CRD redis.cache.example.com"},
+		}))
+		Expect(DiffBundles(to, from).InCategory(CategoryCRDs)).To(Equal([]Change{
+			{Category: CategoryCRDs, Kind: ChangeRemoved, Description: "CRD memcacheds.cache.example.com version v1beta1"},
+			{Category: CategoryCRDs, Kind: ChangeRemoved, Description: "CRD redis.cache.example.com"},
+		}))
+	})
+	It("detects schema changes", func() {
+		props := to.V1CRDs[0].Spec.Versions[0].Schema.OpenAPIV3Schema.Properties
+		props["size"] = apiextv1.JSONSchemaProps{Type: "integer"}
+		Expect(DiffBundles(from, to).InCategory(CategoryCRDs)).To(Equal([]Change{
+			{Category: CategoryCRDs, Kind: ChangeModified, Description: "CRD memcacheds.cache.example.com version v1alpha1 schema"},
+		}))
+	})
+	It("returns no changes for a CRD moved from v1beta1 to v1 with the same schema", func() {
+		from.V1CRDs = nil
+		from.V1beta1CRDs = []*apiextv1beta1.CustomResourceDefinition{
+			newDiffTestV1beta1CRD("memcacheds.cache.example.com", "v1alpha1"),
+		}
+		Expect(DiffBundles(from, to).InCategory(CategoryCRDs)).To(BeEmpty())
+		Expect(DiffBundles(to, from).InCategory(CategoryCRDs)).To(BeEmpty())
+	})
+	It("detects schema changes in a CRD moved from v1beta1 to v1", func() {
+		from.V1CRDs = nil
+		from.V1beta1CRDs = []*apiextv1beta1.CustomResourceDefinition{
+			newDiffTestV1beta1CRD("memcacheds.cache.example.com", "v1alpha1"),
+		}
+		props := to.V1CRDs[0].Spec.Versions[0].Schema.OpenAPIV3Schema.Properties
+		props["size"] = apiextv1.JSONSchemaProps{Type: "integer"}
+		Expect(DiffBundles(from, to).InCategory(CategoryCRDs)).To(Equal([]Change{
+			{Category: CategoryCRDs, Kind: ChangeModified, Description: "CRD memcacheds.cache.example.com version v1alpha1 schema"},
+		}))
+	})
+	It("detects new and removed permissions", func() {
+		to = newDiffTestBundle("0.1.0", "quay.io/example/memcached-operator:v0.1.0",
+			[]string{"get", "watch"}, newDiffTestCRD("memcacheds.cache.example.com", "v1alpha1"))
+		Expect(DiffBundles(from, to).InCategory(CategoryPermissions)).To(Equal([]Change{
+			{Category: CategoryPermissions, Kind: ChangeAdded, Description: "cluster-scoped permission default: watch apps/deployments"},
+			{Category: CategoryPermissions, Kind: ChangeRemoved, Description: "cluster-scoped permission default: list apps/deployments"},
+		}))
+	})
+	It("detects image bumps", func() {
+		to = newDiffTestBundle("0.1.0", "quay.io/example/memcached-operator:v0.2.0",
+			[]string{"get", "list"}, newDiffTestCRD("memcacheds.cache.example.com", "v1alpha1"))
+		Expect(DiffBundles(from, to).InCategory(CategoryImages)).To(Equal([]Change{
+			{Category: CategoryImages, Kind: ChangeModified, Description: "container memcached-operator/manager image from " +
+				"quay.io/example/memcached-operator:v0.1.0 to quay.io/example/memcached-operator:v0.2.0"},
+		}))
+	})
+})
+
+var _ = Describe("ParseChangeCategories", func() {
+	It("parses category names case-insensitively", func() {
+		categories, err := ParseChangeCategories([]string{"images", "Permissions"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(categories).To(Equal([]ChangeCategory{CategoryImages, CategoryPermissions}))
+	})
+	It("returns an error for an unknown category", func() {
+		_, err := ParseChangeCategories([]string{"foo"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func newDiffTestBundle(ver, image string, verbs []string, crds ...*apiextv1.CustomResourceDefinition) *apimanifests.Bundle {
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	csv.Spec.Version = version.OperatorVersion{Version: semver.MustParse(ver)}
+	csv.Spec.InstallStrategy.StrategySpec = operatorsv1alpha1.StrategyDetailsDeployment{
+		ClusterPermissions: []operatorsv1alpha1.StrategyDeploymentPermissions{
+			{
+				ServiceAccountName: "default",
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: verbs},
+				},
+			},
+		},
+		DeploymentSpecs: []operatorsv1alpha1.StrategyDeploymentSpec{
+			{Name: "memcached-operator"},
+		},
+	}
+	depSpec := &csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec
+	depSpec.Template.Spec.Containers = []corev1.Container{{Name: "manager", Image: image}}
+	return &apimanifests.Bundle{CSV: csv, V1CRDs: crds}
+}
+
+func newDiffTestCRD(name string, versions ...string) *apiextv1.CustomResourceDefinition {
+	crd := &apiextv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, v := range versions {
+		crd.Spec.Versions = append(crd.Spec.Versions, apiextv1.CustomResourceDefinitionVersion{
+			Name: v,
+			Schema: &apiextv1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+					Type:       "object",
+					Properties: map[string]apiextv1.JSONSchemaProps{},
+				},
+			},
+		})
+	}
+	return crd
+}
+
+// newDiffTestV1beta1CRD returns a v1beta1 CRD with the same schema as
+// newDiffTestCRD, set once for all versions.
+func newDiffTestV1beta1CRD(name string, versions ...string) *apiextv1beta1.CustomResourceDefinition {
+	crd := &apiextv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	crd.Spec.Validation = &apiextv1beta1.CustomResourceValidation{
+		OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiextv1beta1.JSONSchemaProps{},
+		},
+	}
+	for _, v := range versions {
+		crd.Spec.Versions = append(crd.Spec.Versions, apiextv1beta1.CustomResourceDefinitionVersion{Name: v})
+	}
+	return crd
+}
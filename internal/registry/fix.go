@@ -0,0 +1,330 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// CSV annotation containing a comma-separated list of the operator's categories.
+const categoriesAnnotation = "categories"
+
+// BundleFix is a repair made to a bundle file by FixBundle.
+type BundleFix struct {
+	// File is the path of the fixed file.
+	File string
+	// Path is the path of the fixed field in File.
+	Path string
+	// Description describes the repair.
+	Description string
+	// Destructive is true if the repair changes how the bundle's manifests
+	// behave on a cluster. Destructive repairs are only made if requested.
+	Destructive bool
+}
+
+func (f BundleFix) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.File, f.Path, f.Description)
+}
+
+// fixer repairs obj, an unstructured manifest, and returns each repair it found.
+// Destructive repairs are returned but only made to obj if destructive is true.
+type fixer func(obj map[string]interface{}, destructive bool) []BundleFix
+
+// FixBundle repairs simple issues in the metadata and manifests of the bundle in bundleRoot
+// and returns the repairs made, along with destructive repairs that were skipped because
+// destructive is false. Safe repairs are:
+//
+// - setting a missing media type annotation to "registry+v1"
+// - trimming, de-duplicating, and sorting the channels annotation
+// - trimming and removing empty and duplicate CSV keywords and categories
+//
+// Destructive repairs are:
+//
+// - adding a status subresource to CRD versions whose schema has a status property
+//
+// Files that cannot be read as a single manifest are skipped, since validation reports them.
+func FixBundle(bundleRoot string, destructive bool) (fixed, skipped []BundleFix, err error) {
+	return fixBundle(afero.NewOsFs(), bundleRoot, destructive)
+}
+
+func fixBundle(fs afero.Fs, bundleRoot string, destructive bool) (fixed, skipped []BundleFix, err error) {
+	var fixes []BundleFix
+
+	// Missing metadata is reported by format validation.
+	if _, annotationsPath, err := findBundleMetadata(fs, bundleRoot); err == nil {
+		f, err := fixFile(fs, annotationsPath, destructive, func(obj map[string]interface{}) []fixer {
+			return []fixer{fixMediaType, fixChannels}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		fixes = append(fixes, f...)
+	}
+
+	manifestsDir := filepath.Join(bundleRoot, registrybundle.ManifestsDir)
+	infos, err := afero.ReadDir(fs, manifestsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	for _, info := range infos {
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		f, err := fixFile(fs, filepath.Join(manifestsDir, info.Name()), destructive,
+			func(obj map[string]interface{}) []fixer {
+				switch obj["kind"] {
+				case "ClusterServiceVersion":
+					return []fixer{fixKeywords, fixCategories}
+				case "CustomResourceDefinition":
+					return []fixer{fixStatusSubresource}
+				}
+				return nil
+			})
+		if err != nil {
+			return nil, nil, err
+		}
+		fixes = append(fixes, f...)
+	}
+
+	for _, f := range fixes {
+		if f.Destructive && !destructive {
+			skipped = append(skipped, f)
+		} else {
+			fixed = append(fixed, f)
+		}
+	}
+	return fixed, skipped, nil
+}
+
+// fixFile applies the fixers returned by fixersFor for the manifest in path, and writes the manifest
+// if any repair was made.
+func fixFile(fs afero.Fs, path string, destructive bool,
+	fixersFor func(map[string]interface{}) []fixer) (fixes []BundleFix, err error) {
+
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	// Only single-document files are fixed, since they are rewritten with one document.
+	if bytes.Contains(bytes.TrimPrefix(b, []byte("---")), []byte("\n---")) {
+		return nil, nil
+	}
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &obj); err != nil {
+		return nil, nil
+	}
+
+	changed := false
+	for _, fix := range fixersFor(obj) {
+		for _, f := range fix(obj, destructive) {
+			f.File = path
+			fixes = append(fixes, f)
+			changed = changed || !f.Destructive || destructive
+		}
+	}
+	if !changed {
+		return fixes, nil
+	}
+
+	if b, err = yaml.Marshal(obj); err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(fs, path, b, info.Mode()); err != nil {
+		return nil, fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return fixes, nil
+}
+
+// fixMediaType sets a missing media type annotation to the registry+v1 type.
+func fixMediaType(obj map[string]interface{}, _ bool) []BundleFix {
+	annotations, ok := getNestedMap(obj, "annotations")
+	if !ok {
+		return nil
+	}
+	if mediaType, _ := annotations[registrybundle.MediatypeLabel].(string); mediaType != "" {
+		return nil
+	}
+	annotations[registrybundle.MediatypeLabel] = registrybundle.RegistryV1Type
+	return []BundleFix{{
+		Path:        "annotations." + registrybundle.MediatypeLabel,
+		Description: fmt.Sprintf("set missing media type to %q", registrybundle.RegistryV1Type),
+	}}
+}
+
+// fixChannels trims, de-duplicates, and sorts the channels annotation.
+func fixChannels(obj map[string]interface{}, _ bool) []BundleFix {
+	annotations, ok := getNestedMap(obj, "annotations")
+	if !ok {
+		return nil
+	}
+	value, ok := annotations[registrybundle.ChannelsLabel].(string)
+	if !ok {
+		return nil
+	}
+	channels := normalizeList(strings.Split(value, ","))
+	sort.Strings(channels)
+	newValue := strings.Join(channels, ",")
+	// No channels is reported by format validation.
+	if newValue == value || newValue == "" {
+		return nil
+	}
+	annotations[registrybundle.ChannelsLabel] = newValue
+	return []BundleFix{{
+		Path:        "annotations." + registrybundle.ChannelsLabel,
+		Description: fmt.Sprintf("normalized channels %q to %q", value, newValue),
+	}}
+}
+
+// fixKeywords trims and removes empty and duplicate CSV keywords.
+func fixKeywords(obj map[string]interface{}, _ bool) []BundleFix {
+	spec, ok := getNestedMap(obj, "spec")
+	if !ok {
+		return nil
+	}
+	items, ok := spec["keywords"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var keywords []string
+	for _, item := range items {
+		keyword, ok := item.(string)
+		if !ok {
+			return nil
+		}
+		keywords = append(keywords, keyword)
+	}
+	newKeywords := normalizeList(keywords)
+	if len(newKeywords) == len(keywords) && strings.Join(newKeywords, "\n") == strings.Join(keywords, "\n") {
+		return nil
+	}
+	if len(newKeywords) == 0 {
+		delete(spec, "keywords")
+		return []BundleFix{{Path: "spec.keywords", Description: fmt.Sprintf("removed empty keywords %q", keywords)}}
+	}
+	newItems := make([]interface{}, len(newKeywords))
+	for i, keyword := range newKeywords {
+		newItems[i] = keyword
+	}
+	spec["keywords"] = newItems
+	return []BundleFix{{
+		Path:        "spec.keywords",
+		Description: fmt.Sprintf("normalized keywords %q to %q", keywords, newKeywords),
+	}}
+}
+
+// fixCategories trims and removes empty and duplicate categories from the CSV's categories annotation.
+func fixCategories(obj map[string]interface{}, _ bool) []BundleFix {
+	annotations, ok := getNestedMap(obj, "metadata", "annotations")
+	if !ok {
+		return nil
+	}
+	value, ok := annotations[categoriesAnnotation].(string)
+	if !ok {
+		return nil
+	}
+	newValue := strings.Join(normalizeList(strings.Split(value, ",")), ",")
+	if newValue == value {
+		return nil
+	}
+	path := "metadata.annotations." + categoriesAnnotation
+	if newValue == "" {
+		delete(annotations, categoriesAnnotation)
+		return []BundleFix{{Path: path, Description: fmt.Sprintf("removed empty categories %q", value)}}
+	}
+	annotations[categoriesAnnotation] = newValue
+	return []BundleFix{{
+		Path:        path,
+		Description: fmt.Sprintf("normalized categories %q to %q", value, newValue),
+	}}
+}
+
+// fixStatusSubresource adds a status subresource to each version of a CRD whose schema
+// has a status property but that has no status subresource. This is destructive since
+// the main resource endpoint then ignores status changes.
+func fixStatusSubresource(obj map[string]interface{}, destructive bool) (fixes []BundleFix) {
+	spec, ok := getNestedMap(obj, "spec")
+	if !ok {
+		return nil
+	}
+	hasStatusProperty := func(validation map[string]interface{}) bool {
+		_, ok := getNestedMap(validation, "openAPIV3Schema", "properties", "status")
+		return ok
+	}
+	addStatus := func(parent map[string]interface{}, path string) {
+		fixes = append(fixes, BundleFix{
+			Path:        path + ".subresources.status",
+			Description: "added status subresource for the status property in the schema",
+			Destructive: true,
+		})
+		if !destructive {
+			return
+		}
+		subresources, ok := parent["subresources"].(map[string]interface{})
+		if !ok {
+			subresources = map[string]interface{}{}
+			parent["subresources"] = subresources
+		}
+		subresources["status"] = map[string]interface{}{}
+	}
+
+	switch obj["apiVersion"] {
+	case "apiextensions.k8s.io/v1":
+		for i, version := range getNestedSlice(obj, "spec", "versions") {
+			schema, _ := version["schema"].(map[string]interface{})
+			if _, hasStatus := getNestedMap(version, "subresources", "status"); !hasStatus && hasStatusProperty(schema) {
+				addStatus(version, fmt.Sprintf("spec.versions[%d]", i))
+			}
+		}
+	case "apiextensions.k8s.io/v1beta1":
+		// Subresources are set either for all versions in spec or per version, so only fix the former.
+		for _, version := range getNestedSlice(obj, "spec", "versions") {
+			if _, ok := version["subresources"]; ok {
+				return nil
+			}
+		}
+		validation, _ := spec["validation"].(map[string]interface{})
+		if _, hasStatus := getNestedMap(spec, "subresources", "status"); !hasStatus && hasStatusProperty(validation) {
+			addStatus(spec, "spec")
+		}
+	}
+	return fixes
+}
+
+// normalizeList returns items trimmed, excluding empty and duplicate items.
+func normalizeList(items []string) (normalized []string) {
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if _, isDup := seen[item]; item == "" || isDup {
+			continue
+		}
+		seen[item] = struct{}{}
+		normalized = append(normalized, item)
+	}
+	return normalized
+}
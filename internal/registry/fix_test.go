@@ -0,0 +1,185 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("FixBundle", func() {
+	const (
+		annotationsPath = "/bundle/metadata/annotations.yaml"
+		csvPath         = "/bundle/manifests/memcached-operator.clusterserviceversion.yaml"
+		crdV1Path       = "/bundle/manifests/cache.example.com_memcacheds.yaml"
+		crdV1beta1Path  = "/bundle/manifests/cache.example.com_memcachedbackups.yaml"
+	)
+
+	var fs afero.Fs
+
+	write := func(path, content string) {
+		ExpectWithOffset(1, afero.WriteFile(fs, path, []byte(content), 0644)).To(Succeed())
+	}
+	read := func(path string) map[string]interface{} {
+		b, err := afero.ReadFile(fs, path)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		obj := map[string]interface{}{}
+		ExpectWithOffset(1, yaml.Unmarshal(b, &obj)).To(Succeed())
+		return obj
+	}
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+		write(annotationsPath, `annotations:
+  operators.operatorframework.io.bundle.channels.v1: "beta, alpha,,beta"
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+  operators.operatorframework.io.bundle.package.v1: memcached-operator
+`)
+		write(csvPath, `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+  annotations:
+    categories: "Database, ,Database"
+spec:
+  keywords:
+  - " memcached"
+  - ""
+  - memcached
+  - cache
+`)
+		write(crdV1Path, `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  versions:
+  - name: v1alpha1
+    schema:
+      openAPIV3Schema:
+        properties:
+          status:
+            type: object
+  - name: v1alpha2
+    schema:
+      openAPIV3Schema:
+        properties:
+          spec:
+            type: object
+`)
+		write(crdV1beta1Path, `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: memcachedbackups.cache.example.com
+spec:
+  validation:
+    openAPIV3Schema:
+      properties:
+        status:
+          type: object
+`)
+	})
+
+	It("makes safe repairs and skips destructive ones", func() {
+		fixed, skipped, err := fixBundle(fs, "/bundle", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fixed).To(ConsistOf(
+			BundleFix{File: annotationsPath, Path: "annotations.operators.operatorframework.io.bundle.mediatype.v1",
+				Description: `set missing media type to "registry+v1"`},
+			BundleFix{File: annotationsPath, Path: "annotations.operators.operatorframework.io.bundle.channels.v1",
+				Description: `normalized channels "beta, alpha,,beta" to "alpha,beta"`},
+			BundleFix{File: csvPath, Path: "spec.keywords",
+				Description: `normalized keywords [" memcached" "" "memcached" "cache"] to ["memcached" "cache"]`},
+			BundleFix{File: csvPath, Path: "metadata.annotations.categories",
+				Description: `normalized categories "Database, ,Database" to "Database"`},
+		))
+		Expect(skipped).To(ConsistOf(
+			BundleFix{File: crdV1Path, Path: "spec.versions[0].subresources.status",
+				Description: "added status subresource for the status property in the schema", Destructive: true},
+			BundleFix{File: crdV1beta1Path, Path: "spec.subresources.status",
+				Description: "added status subresource for the status property in the schema", Destructive: true},
+		))
+
+		annotations := read(annotationsPath)["annotations"].(map[string]interface{})
+		Expect(annotations).To(HaveKeyWithValue("operators.operatorframework.io.bundle.mediatype.v1", "registry+v1"))
+		Expect(annotations).To(HaveKeyWithValue("operators.operatorframework.io.bundle.channels.v1", "alpha,beta"))
+		csv := read(csvPath)
+		Expect(csv["spec"]).To(HaveKeyWithValue("keywords", []interface{}{"memcached", "cache"}))
+		Expect(csv["metadata"]).To(HaveKeyWithValue("annotations", map[string]interface{}{"categories": "Database"}))
+		Expect(read(crdV1beta1Path)["spec"]).NotTo(HaveKey("subresources"))
+
+		// Repairs are idempotent.
+		fixed, _, err = fixBundle(fs, "/bundle", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fixed).To(BeEmpty())
+	})
+
+	It("makes destructive repairs if requested", func() {
+		fixed, skipped, err := fixBundle(fs, "/bundle", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fixed).To(HaveLen(6))
+		Expect(skipped).To(BeEmpty())
+
+		versions := read(crdV1Path)["spec"].(map[string]interface{})["versions"].([]interface{})
+		Expect(versions[0]).To(HaveKeyWithValue("subresources", map[string]interface{}{"status": map[string]interface{}{}}))
+		Expect(versions[1]).NotTo(HaveKey("subresources"))
+		Expect(read(crdV1beta1Path)["spec"]).To(HaveKeyWithValue("subresources",
+			map[string]interface{}{"status": map[string]interface{}{}}))
+	})
+
+	It("removes an empty categories annotation and leaves multi-document files alone", func() {
+		write(csvPath, `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+  annotations:
+    categories: " , "
+spec:
+  keywords:
+  - ""
+`)
+		multiDoc := "---\n" + `apiVersion: v1
+kind: Service
+metadata:
+  name: a
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+spec:
+  validation:
+    openAPIV3Schema:
+      properties:
+        status: {}
+`
+		write("/bundle/manifests/multi.yaml", multiDoc)
+
+		fixed, skipped, err := fixBundle(fs, "/bundle", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fixed).To(ContainElement(BundleFix{File: csvPath, Path: "metadata.annotations.categories",
+			Description: `removed empty categories " , "`}))
+		Expect(fixed).To(ContainElement(BundleFix{File: csvPath, Path: "spec.keywords",
+			Description: `removed empty keywords [""]`}))
+		Expect(skipped).To(BeEmpty())
+		csv := read(csvPath)
+		Expect(csv["metadata"]).To(HaveKeyWithValue("annotations", BeEmpty()))
+		Expect(csv["spec"]).NotTo(HaveKey("keywords"))
+		b, err := afero.ReadFile(fs, "/bundle/manifests/multi.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(multiDoc))
+	})
+})
@@ -0,0 +1,196 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+)
+
+const (
+	// ArchLabelPrefix prefixes CSV labels declaring a supported architecture,
+	// ex. operatorframework.io/arch.arm64: supported.
+	ArchLabelPrefix = "operatorframework.io/arch."
+	// OSLabelPrefix prefixes CSV labels declaring a supported operating system,
+	// ex. operatorframework.io/os.linux: supported.
+	OSLabelPrefix = "operatorframework.io/os."
+
+	// Value of a supported arch or os label.
+	platformLabelSupported = "supported"
+	// OLM assumes these when a CSV declares no arch or os labels.
+	defaultArch = "amd64"
+	defaultOS   = "linux"
+)
+
+// Platform is an os/architecture pair an image can run on.
+type Platform struct {
+	OS           string
+	Architecture string
+}
+
+func (p Platform) String() string {
+	return p.OS + "/" + p.Architecture
+}
+
+// PlatformsGetter returns the platforms image supports.
+type PlatformsGetter func(ctx context.Context, image string) ([]Platform, error)
+
+// NewPlatformsGetter returns a PlatformsGetter that reads an image's manifest,
+// or manifest list, from its registry using resolver.
+func NewPlatformsGetter(resolver remotes.Resolver) PlatformsGetter {
+	return func(ctx context.Context, image string) ([]Platform, error) {
+		name, desc, err := resolver.Resolve(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving image %s: %v", image, err)
+		}
+		fetcher, err := resolver.Fetcher(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("error creating fetcher for image %s: %v", image, err)
+		}
+		return getPlatforms(ctx, fetcher, desc)
+	}
+}
+
+// getPlatforms returns the platforms of the manifest or manifest list desc describes.
+func getPlatforms(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor) ([]Platform, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, images.MediaTypeDockerSchema2ManifestList:
+		index := ocispec.Index{}
+		if err := fetchJSON(ctx, fetcher, desc, &index); err != nil {
+			return nil, err
+		}
+		var platforms []Platform
+		for _, m := range index.Manifests {
+			// Non-image entries, ex. attestations, have an "unknown" platform.
+			if m.Platform == nil || m.Platform.Architecture == "unknown" {
+				continue
+			}
+			platforms = append(platforms, Platform{OS: m.Platform.OS, Architecture: m.Platform.Architecture})
+		}
+		return platforms, nil
+	case ocispec.MediaTypeImageManifest, images.MediaTypeDockerSchema2Manifest:
+		manifest := ocispec.Manifest{}
+		if err := fetchJSON(ctx, fetcher, desc, &manifest); err != nil {
+			return nil, err
+		}
+		config := ocispec.Image{}
+		if err := fetchJSON(ctx, fetcher, manifest.Config, &config); err != nil {
+			return nil, err
+		}
+		return []Platform{{OS: config.OS, Architecture: config.Architecture}}, nil
+	}
+	return nil, fmt.Errorf("unsupported manifest media type %q", desc.MediaType)
+}
+
+// fetchJSON fetches the blob desc describes and unmarshals it into v.
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor, v interface{}) error {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("error fetching %s: %v", desc.Digest, err)
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", desc.Digest, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("error decoding %s: %v", desc.Digest, err)
+	}
+	return nil
+}
+
+// GetDeclaredPlatforms returns every os/arch combination declared by csv's
+// arch and os labels, using OLM's linux and amd64 defaults for missing labels.
+func GetDeclaredPlatforms(csv *v1alpha1.ClusterServiceVersion) []Platform {
+	var archs, oses []string
+	for key, value := range csv.GetLabels() {
+		if value != platformLabelSupported {
+			continue
+		}
+		if strings.HasPrefix(key, ArchLabelPrefix) {
+			archs = append(archs, strings.TrimPrefix(key, ArchLabelPrefix))
+		} else if strings.HasPrefix(key, OSLabelPrefix) {
+			oses = append(oses, strings.TrimPrefix(key, OSLabelPrefix))
+		}
+	}
+	if len(archs) == 0 {
+		archs = []string{defaultArch}
+	}
+	if len(oses) == 0 {
+		oses = []string{defaultOS}
+	}
+	sort.Strings(archs)
+	sort.Strings(oses)
+
+	var platforms []Platform
+	for _, osName := range oses {
+		for _, arch := range archs {
+			platforms = append(platforms, Platform{OS: osName, Architecture: arch})
+		}
+	}
+	return platforms
+}
+
+// ValidateImagePlatforms returns a warning for each platform declared by csv's
+// arch and os labels that an image in csv's deployments does not support.
+// Images' supported platforms are queried with getPlatforms.
+func ValidateImagePlatforms(ctx context.Context, csv *v1alpha1.ClusterServiceVersion, getPlatforms PlatformsGetter) (errs []apierrors.Error) {
+	declared := GetDeclaredPlatforms(csv)
+	for _, image := range getDeploymentImages(csv) {
+		platforms, err := getPlatforms(ctx, image)
+		if err != nil {
+			errs = append(errs, apierrors.WarnInvalidCSV(
+				fmt.Sprintf("error getting platforms of image %s: %v", image, err), csv.GetName()))
+			continue
+		}
+		supported := make(map[Platform]bool, len(platforms))
+		for _, p := range platforms {
+			supported[p] = true
+		}
+		for _, p := range declared {
+			if !supported[p] {
+				errs = append(errs, apierrors.WarnInvalidCSV(
+					fmt.Sprintf("image %s does not support declared platform %s", image, p), csv.GetName()))
+			}
+		}
+	}
+	return errs
+}
+
+// getDeploymentImages returns the sorted, unique set of container images in csv's deployments.
+func getDeploymentImages(csv *v1alpha1.ClusterServiceVersion) (imgs []string) {
+	seen := map[string]bool{}
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		podSpec := dep.Spec.Template.Spec
+		for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+			if c.Image != "" && !seen[c.Image] {
+				seen[c.Image] = true
+				imgs = append(imgs, c.Image)
+			}
+		}
+	}
+	sort.Strings(imgs)
+	return imgs
+}
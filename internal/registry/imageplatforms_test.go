@@ -0,0 +1,207 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Image platforms", func() {
+	Describe("GetDeclaredPlatforms", func() {
+		It("defaults to linux/amd64", func() {
+			csv := newPlatformsCSV(nil)
+			Expect(GetDeclaredPlatforms(csv)).To(Equal([]Platform{{OS: "linux", Architecture: "amd64"}}))
+		})
+		It("returns each declared os and arch combination", func() {
+			csv := newPlatformsCSV(map[string]string{
+				ArchLabelPrefix + "arm64":   "supported",
+				ArchLabelPrefix + "amd64":   "supported",
+				ArchLabelPrefix + "s390x":   "unsupported",
+				OSLabelPrefix + "linux":     "supported",
+				"operatorframework.io/tier": "supported",
+			})
+			Expect(GetDeclaredPlatforms(csv)).To(Equal([]Platform{
+				{OS: "linux", Architecture: "amd64"},
+				{OS: "linux", Architecture: "arm64"},
+			}))
+		})
+	})
+
+	Describe("ValidateImagePlatforms", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("returns no warnings when images support all declared platforms", func() {
+			csv := newPlatformsCSV(map[string]string{ArchLabelPrefix + "arm64": "supported", ArchLabelPrefix + "amd64": "supported"},
+				"quay.io/example/operator:v0.1.0")
+			getter := staticPlatformsGetter{"quay.io/example/operator:v0.1.0": {
+				{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}, {OS: "linux", Architecture: "ppc64le"},
+			}}
+			Expect(ValidateImagePlatforms(ctx, csv, getter.get)).To(BeEmpty())
+		})
+		It("returns a warning for each unsupported declared platform", func() {
+			csv := newPlatformsCSV(map[string]string{
+				ArchLabelPrefix + "arm64": "supported", ArchLabelPrefix + "ppc64le": "supported", ArchLabelPrefix + "amd64": "supported",
+			}, "quay.io/example/operator:v0.1.0", "quay.io/example/proxy:v0.1.0")
+			getter := staticPlatformsGetter{
+				"quay.io/example/operator:v0.1.0": {{OS: "linux", Architecture: "amd64"}},
+				"quay.io/example/proxy:v0.1.0":    {{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "ppc64le"}},
+			}
+			errs := ValidateImagePlatforms(ctx, csv, getter.get)
+			Expect(errs).To(HaveLen(3))
+			Expect(errs[0].Error()).To(ContainSubstring("image quay.io/example/operator:v0.1.0 does not support declared platform linux/arm64"))
+			Expect(errs[1].Error()).To(ContainSubstring("image quay.io/example/operator:v0.1.0 does not support declared platform linux/ppc64le"))
+			Expect(errs[2].Error()).To(ContainSubstring("image quay.io/example/proxy:v0.1.0 does not support declared platform linux/arm64"))
+		})
+		It("returns a warning when an image cannot be inspected", func() {
+			csv := newPlatformsCSV(nil, "quay.io/example/operator:v0.1.0")
+			errs := ValidateImagePlatforms(ctx, csv, staticPlatformsGetter{}.get)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring("error getting platforms of image quay.io/example/operator:v0.1.0"))
+		})
+	})
+
+	Describe("NewPlatformsGetter", func() {
+		var (
+			ctx      context.Context
+			resolver *memoryResolver
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			resolver = &memoryResolver{refs: map[string]ocispec.Descriptor{}, blobs: map[digest.Digest][]byte{}}
+		})
+
+		It("returns platforms in a manifest list", func() {
+			resolver.addRef("quay.io/example/operator:v0.1.0", images.MediaTypeDockerSchema2ManifestList, ocispec.Index{
+				Manifests: []ocispec.Descriptor{
+					{Platform: &ocispec.Platform{OS: "linux", Architecture: "amd64"}},
+					{Platform: &ocispec.Platform{OS: "linux", Architecture: "arm64"}},
+					{Platform: &ocispec.Platform{OS: "unknown", Architecture: "unknown"}},
+				},
+			})
+			platforms, err := NewPlatformsGetter(resolver)(ctx, "quay.io/example/operator:v0.1.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(platforms).To(Equal([]Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}}))
+		})
+		It("returns the platform in a single manifest's config", func() {
+			config := resolver.addBlob(ocispec.MediaTypeImageConfig, ocispec.Image{OS: "linux", Architecture: "s390x"})
+			resolver.addRef("quay.io/example/operator:v0.1.0", ocispec.MediaTypeImageManifest, ocispec.Manifest{Config: config})
+			platforms, err := NewPlatformsGetter(resolver)(ctx, "quay.io/example/operator:v0.1.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(platforms).To(Equal([]Platform{{OS: "linux", Architecture: "s390x"}}))
+		})
+		It("returns an error for an unresolvable image", func() {
+			_, err := NewPlatformsGetter(resolver)(ctx, "quay.io/example/operator:v0.1.0")
+			Expect(err).To(MatchError(ContainSubstring("error resolving image quay.io/example/operator:v0.1.0")))
+		})
+		It("returns an error for an unsupported media type", func() {
+			resolver.addRef("quay.io/example/operator:v0.1.0", images.MediaTypeDockerSchema1Manifest, struct{}{})
+			_, err := NewPlatformsGetter(resolver)(ctx, "quay.io/example/operator:v0.1.0")
+			Expect(err).To(MatchError(ContainSubstring("unsupported manifest media type")))
+		})
+	})
+})
+
+func newPlatformsCSV(labels map[string]string, imgs ...string) *v1alpha1.ClusterServiceVersion {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.SetName("memcached-operator.v0.0.1")
+	csv.SetLabels(labels)
+	for i, img := range imgs {
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = append(csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs,
+			v1alpha1.StrategyDeploymentSpec{
+				Name: fmt.Sprintf("deployment-%d", i),
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "manager", Image: img}},
+					}},
+				},
+			})
+	}
+	return csv
+}
+
+type staticPlatformsGetter map[string][]Platform
+
+func (g staticPlatformsGetter) get(_ context.Context, image string) ([]Platform, error) {
+	platforms, ok := g[image]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return platforms, nil
+}
+
+// memoryResolver is a remotes.Resolver serving refs and blobs from memory.
+type memoryResolver struct {
+	refs  map[string]ocispec.Descriptor
+	blobs map[digest.Digest][]byte
+}
+
+var _ remotes.Resolver = &memoryResolver{}
+
+func (r *memoryResolver) addBlob(mediaType string, v interface{}) ocispec.Descriptor {
+	b, err := json.Marshal(v)
+	Expect(err).NotTo(HaveOccurred())
+	dgst := digest.FromBytes(b)
+	r.blobs[dgst] = b
+	return ocispec.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(b))}
+}
+
+func (r *memoryResolver) addRef(ref, mediaType string, v interface{}) {
+	r.refs[ref] = r.addBlob(mediaType, v)
+}
+
+func (r *memoryResolver) Resolve(_ context.Context, ref string) (string, ocispec.Descriptor, error) {
+	desc, ok := r.refs[ref]
+	if !ok {
+		return "", ocispec.Descriptor{}, errors.New("not found")
+	}
+	return ref, desc, nil
+}
+
+func (r *memoryResolver) Fetcher(context.Context, string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(_ context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		b, ok := r.blobs[desc.Digest]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}), nil
+}
+
+func (r *memoryResolver) Pusher(context.Context, string) (remotes.Pusher, error) {
+	return nil, errors.New("not implemented")
+}
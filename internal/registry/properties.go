@@ -0,0 +1,188 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"github.com/operator-framework/operator-registry/pkg/registry"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PropertiesFile is the name of the bundle metadata file listing the
+	// bundle's properties.
+	PropertiesFile = "properties.yaml"
+	// PropertiesAnnotation is the CSV annotation key whose value is a JSON
+	// list of the bundle's properties.
+	PropertiesAnnotation = "olm.properties"
+	// MaxOpenShiftVersionProperty is the type of a property whose value is the
+	// latest OpenShift minor version, ex. "4.8", the bundle can be installed on.
+	MaxOpenShiftVersionProperty = "olm.maxOpenShiftVersion"
+)
+
+// Property is a bundle property of a type with an arbitrary JSON value.
+type Property struct {
+	// Type is the property type, ex. olm.maxOpenShiftVersion.
+	Type string `json:"type"`
+	// Value is the property's JSON value.
+	Value json.RawMessage `json:"value"`
+}
+
+// properties is the content of a PropertiesFile.
+type properties struct {
+	Properties []Property `json:"properties"`
+}
+
+// ParseProperty parses a property of the format <type>:<JSON value>,
+// ex. olm.maxOpenShiftVersion:"4.8" or example.com/tier:{"level":2}.
+func ParseProperty(s string) (Property, error) {
+	split := strings.SplitN(s, ":", 2)
+	if len(split) != 2 {
+		return Property{}, fmt.Errorf("property %q must have the format <type>:<JSON value>", s)
+	}
+	p := Property{Type: split[0], Value: json.RawMessage(strings.TrimSpace(split[1]))}
+	if err := validateProperty(p); err != nil {
+		return Property{}, fmt.Errorf("invalid property %q: %v", s, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, p.Value); err != nil {
+		return Property{}, err
+	}
+	p.Value = buf.Bytes()
+	return p, nil
+}
+
+func validateProperty(p Property) error {
+	if p.Type == "" || strings.ContainsAny(p.Type, " \t\n") {
+		return fmt.Errorf("type %q must be non-empty and contain no whitespace", p.Type)
+	}
+	switch p.Type {
+	case registry.PackageType, registry.GVKType:
+		return fmt.Errorf("type %q is derived from the bundle's manifests and cannot be set", p.Type)
+	}
+	if !json.Valid(p.Value) {
+		return fmt.Errorf("value %s is not valid JSON", p.Value)
+	}
+	if p.Type == MaxOpenShiftVersionProperty {
+		var version string
+		if err := json.Unmarshal(p.Value, &version); err != nil {
+			return fmt.Errorf("%s value must be a JSON string", p.Type)
+		}
+		if _, err := semver.ParseTolerant(version); err != nil {
+			return fmt.Errorf("%s value %q is not a valid version: %v", p.Type, version, err)
+		}
+	}
+	return nil
+}
+
+// ValidateProperties validates each entry in the PropertiesFile in bundleRoot's
+// metadata directory, if that file exists. An error is returned for each
+// invalid entry, identified by its index in the file.
+func ValidateProperties(bundleRoot string) ([]apierrors.Error, error) {
+	path := filepath.Join(bundleRoot, registrybundle.MetadataDir, PropertiesFile)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	props := properties{}
+	if err := yaml.Unmarshal(b, &props); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", PropertiesFile, err)
+	}
+	return validateProperties("properties", props.Properties), nil
+}
+
+// ValidatePropertiesAnnotation validates each entry in the PropertiesAnnotation
+// value of a CSV's annotations, if that annotation is set.
+func ValidatePropertiesAnnotation(annotations map[string]string) []apierrors.Error {
+	value, hasAnnotation := annotations[PropertiesAnnotation]
+	if !hasAnnotation {
+		return nil
+	}
+	var props []Property
+	if err := json.Unmarshal([]byte(value), &props); err != nil {
+		return []apierrors.Error{apierrors.ErrInvalidParse(
+			fmt.Sprintf("annotation %s is not a JSON list of properties: %v", PropertiesAnnotation, err), value)}
+	}
+	return validateProperties(PropertiesAnnotation, props)
+}
+
+func validateProperties(field string, props []Property) (errs []apierrors.Error) {
+	for i, p := range props {
+		if err := validateProperty(p); err != nil {
+			errs = append(errs, apierrors.ErrInvalidBundle(fmt.Sprintf("%s[%d] is invalid: %v", field, i, err), p.Type))
+		}
+	}
+	return errs
+}
+
+// WriteProperties writes props to the PropertiesFile in bundleRoot's
+// metadata directory, overwriting any existing file.
+func WriteProperties(bundleRoot string, props []Property) error {
+	b, err := yaml.Marshal(properties{Properties: props})
+	if err != nil {
+		return err
+	}
+	metadataDir := filepath.Join(bundleRoot, registrybundle.MetadataDir)
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(metadataDir, PropertiesFile), b, 0666)
+}
+
+// AddPropertiesAnnotation adds props to the PropertiesAnnotation in annotations,
+// keeping existing properties that are not identical to one in props.
+func AddPropertiesAnnotation(annotations map[string]string, props []Property) error {
+	var existing []Property
+	if value, hasAnnotation := annotations[PropertiesAnnotation]; hasAnnotation {
+		if err := json.Unmarshal([]byte(value), &existing); err != nil {
+			return fmt.Errorf("error parsing existing %s annotation: %v", PropertiesAnnotation, err)
+		}
+	}
+	merged := existing
+	for _, p := range props {
+		if !containsProperty(merged, p) {
+			merged = append(merged, p)
+		}
+	}
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	annotations[PropertiesAnnotation] = string(b)
+	return nil
+}
+
+func containsProperty(props []Property, p Property) bool {
+	for _, q := range props {
+		if q.Type == p.Type && bytes.Equal(q.Value, p.Value) {
+			return true
+		}
+	}
+	return false
+}
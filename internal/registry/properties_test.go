@@ -0,0 +1,145 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Properties", func() {
+	Describe("ParseProperty", func() {
+		It("parses a max OpenShift version property", func() {
+			p, err := ParseProperty(`olm.maxOpenShiftVersion:"4.8"`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p).To(Equal(Property{Type: MaxOpenShiftVersionProperty, Value: json.RawMessage(`"4.8"`)}))
+		})
+		It("parses and compacts an arbitrary property", func() {
+			p, err := ParseProperty(`example.com/tier: { "level": 2 }`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p).To(Equal(Property{Type: "example.com/tier", Value: json.RawMessage(`{"level":2}`)}))
+		})
+		It("returns an error for invalid properties", func() {
+			for _, s := range []string{
+				"olm.maxOpenShiftVersion",
+				`:"4.8"`,
+				`example tier:2`,
+				`example.com/tier:{level: 2}`,
+				`olm.maxOpenShiftVersion:4.8`,
+				`olm.maxOpenShiftVersion:"latest"`,
+				`olm.package:{"packageName":"etcd","version":"0.9.0"}`,
+				`olm.gvk:{"group":"etcd.database.coreos.com","version":"v1beta2","kind":"EtcdCluster"}`,
+			} {
+				_, err := ParseProperty(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+	})
+
+	Describe("WriteProperties and ValidateProperties", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "properties")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes properties to the bundle's metadata", func() {
+			props := []Property{
+				{Type: MaxOpenShiftVersionProperty, Value: json.RawMessage(`"4.8"`)},
+				{Type: "example.com/tier", Value: json.RawMessage(`{"level":2}`)},
+			}
+			Expect(WriteProperties(dir, props)).To(Succeed())
+			b, err := ioutil.ReadFile(filepath.Join(dir, "metadata", PropertiesFile))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(MatchYAML(`properties:
+- type: olm.maxOpenShiftVersion
+  value: "4.8"
+- type: example.com/tier
+  value:
+    level: 2
+`))
+			errs, err := ValidateProperties(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(BeEmpty())
+		})
+		It("returns no errors if the bundle has no properties", func() {
+			errs, err := ValidateProperties(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(BeEmpty())
+		})
+		It("returns an error for each invalid property by index", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "metadata"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "metadata", PropertiesFile), []byte(`properties:
+- type: olm.maxOpenShiftVersion
+  value: 4.8
+- type: example.com/tier
+  value: 2
+- type: olm.package
+  value:
+    packageName: etcd
+`), 0644)).To(Succeed())
+			errs, err := ValidateProperties(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(HaveLen(2))
+			Expect(errs[0].Detail).To(ContainSubstring("properties[0] is invalid: olm.maxOpenShiftVersion value must be a JSON string"))
+			Expect(errs[1].Detail).To(ContainSubstring(`properties[2] is invalid: type "olm.package"`))
+		})
+	})
+
+	Describe("ValidatePropertiesAnnotation", func() {
+		It("returns no errors for valid or missing annotations", func() {
+			Expect(ValidatePropertiesAnnotation(nil)).To(BeEmpty())
+			Expect(ValidatePropertiesAnnotation(map[string]string{
+				PropertiesAnnotation: `[{"type":"olm.maxOpenShiftVersion","value":"4.8"}]`,
+			})).To(BeEmpty())
+		})
+		It("returns errors for invalid annotations", func() {
+			errs := ValidatePropertiesAnnotation(map[string]string{PropertiesAnnotation: `{"type":"olm.maxOpenShiftVersion"}`})
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Detail).To(ContainSubstring("is not a JSON list of properties"))
+
+			errs = ValidatePropertiesAnnotation(map[string]string{PropertiesAnnotation: `[{"type":"olm.maxOpenShiftVersion","value":"4.x"}]`})
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Detail).To(ContainSubstring("olm.properties[0] is invalid"))
+		})
+	})
+
+	Describe("AddPropertiesAnnotation", func() {
+		It("adds properties to existing properties without duplicating them", func() {
+			annotations := map[string]string{PropertiesAnnotation: `[{"type":"example.com/tier","value":2}]`}
+			props := []Property{
+				{Type: "example.com/tier", Value: json.RawMessage(`2`)},
+				{Type: MaxOpenShiftVersionProperty, Value: json.RawMessage(`"4.8"`)},
+			}
+			Expect(AddPropertiesAnnotation(annotations, props)).To(Succeed())
+			Expect(annotations[PropertiesAnnotation]).To(MatchJSON(
+				`[{"type":"example.com/tier","value":2},{"type":"olm.maxOpenShiftVersion","value":"4.8"}]`))
+		})
+		It("returns an error for an unparseable existing annotation", func() {
+			annotations := map[string]string{PropertiesAnnotation: `{`}
+			Expect(AddPropertiesAnnotation(annotations, nil)).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,202 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/containerd/remotes"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+)
+
+const (
+	// CSV annotation containing the operator's image.
+	containerImageAnnotation = "containerImage"
+	// Prefix of container env var names whose values are related image references.
+	relatedImageEnvPrefix = "RELATED_IMAGE_"
+)
+
+// ImageRewrite is an image reference rewritten by an ImageRewriter.
+type ImageRewrite struct {
+	// Path is the path of the reference's field in the CSV.
+	Path string
+	// From is the original reference.
+	From string
+	// To is the rewritten reference.
+	To string
+}
+
+func (r ImageRewrite) String() string {
+	return fmt.Sprintf("%s: %s -> %s", r.Path, r.From, r.To)
+}
+
+// DigestResolver returns the digest of the image ref refers to.
+type DigestResolver func(ctx context.Context, ref string) (digest.Digest, error)
+
+// NewDigestResolver returns a DigestResolver that resolves references
+// in their registries using resolver.
+func NewDigestResolver(resolver remotes.Resolver) DigestResolver {
+	return func(ctx context.Context, ref string) (digest.Digest, error) {
+		_, desc, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		return desc.Digest, nil
+	}
+}
+
+// ImageRewriter rewrites image references prefixed by one registry, or registry
+// and repository path, to be prefixed by another.
+type ImageRewriter struct {
+	// From is the registry prefix of references to rewrite, ex. quay.io/example.
+	From string
+	// To is the registry prefix that replaces From, ex. registry.example.com/mirror.
+	To string
+	// ResolveDigest, if set, is used to replace the tag of rewritten references
+	// with their image's digest. References that already have a digest are not resolved.
+	ResolveDigest DigestResolver
+}
+
+// RewriteCSV rewrites image references in csv, an unstructured ClusterServiceVersion,
+// and returns each rewrite made. Rewritten references are in csv's deployments'
+// containers, RELATED_IMAGE_* container env vars, relatedImages, and containerImage
+// annotation. An error is returned if a rewritten reference is invalid.
+func (r ImageRewriter) RewriteCSV(ctx context.Context, csv map[string]interface{}) (rewrites []ImageRewrite, err error) {
+	rewriteField := func(obj map[string]interface{}, key, path string) error {
+		ref, ok := obj[key].(string)
+		if !ok || ref == "" {
+			return nil
+		}
+		newRef, rewritten, err := r.rewrite(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if rewritten {
+			obj[key] = newRef
+			rewrites = append(rewrites, ImageRewrite{Path: path, From: ref, To: newRef})
+		}
+		return nil
+	}
+
+	if annotations, ok := getNestedMap(csv, "metadata", "annotations"); ok {
+		path := "metadata.annotations." + containerImageAnnotation
+		if err := rewriteField(annotations, containerImageAnnotation, path); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, dep := range getNestedSlice(csv, "spec", "install", "spec", "deployments") {
+		podSpec, ok := getNestedMap(dep, "spec", "template", "spec")
+		if !ok {
+			continue
+		}
+		for _, field := range []string{"initContainers", "containers"} {
+			for j, container := range getNestedSlice(podSpec, field) {
+				path := fmt.Sprintf("spec.install.spec.deployments[%d].spec.template.spec.%s[%d]", i, field, j)
+				if err := rewriteField(container, "image", path+".image"); err != nil {
+					return nil, err
+				}
+				for k, env := range getNestedSlice(container, "env") {
+					if name, _ := env["name"].(string); !strings.HasPrefix(name, relatedImageEnvPrefix) {
+						continue
+					}
+					if err := rewriteField(env, "value", fmt.Sprintf("%s.env[%d].value", path, k)); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	for i, related := range getNestedSlice(csv, "spec", "relatedImages") {
+		if err := rewriteField(related, "image", fmt.Sprintf("spec.relatedImages[%d].image", i)); err != nil {
+			return nil, err
+		}
+	}
+
+	return rewrites, nil
+}
+
+// rewrite returns ref with its From prefix replaced by To, resolving its digest
+// if configured, and true if ref has the From prefix.
+func (r ImageRewriter) rewrite(ctx context.Context, ref string) (string, bool, error) {
+	from := strings.TrimSuffix(r.From, "/") + "/"
+	// Match both the reference as written and its normalized form,
+	// so "docker.io" matches references like "memcached:1.4".
+	normalized := ref
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		normalized = named.String()
+	}
+	var rest string
+	switch {
+	case strings.HasPrefix(ref, from):
+		rest = strings.TrimPrefix(ref, from)
+	case strings.HasPrefix(normalized, from):
+		rest = strings.TrimPrefix(normalized, from)
+	default:
+		return ref, false, nil
+	}
+
+	newRef := strings.TrimSuffix(r.To, "/") + "/" + rest
+	named, err := reference.ParseNormalizedNamed(newRef)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid rewritten image reference %q: %v", newRef, err)
+	}
+	if _, hasDigest := named.(reference.Digested); r.ResolveDigest == nil || hasDigest {
+		return newRef, true, nil
+	}
+
+	dgst, err := r.ResolveDigest(ctx, named.String())
+	if err != nil {
+		return "", false, fmt.Errorf("error resolving digest of image %s: %v", newRef, err)
+	}
+	canonical, err := reference.WithDigest(reference.TrimNamed(named), dgst)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid digest %q of image %s: %v", dgst, newRef, err)
+	}
+	return reference.FamiliarString(canonical), true, nil
+}
+
+// getNestedMap returns the map at path in obj, if any.
+func getNestedMap(obj map[string]interface{}, path ...string) (map[string]interface{}, bool) {
+	for _, key := range path {
+		var ok bool
+		if obj, ok = obj[key].(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return obj, true
+}
+
+// getNestedSlice returns the maps in the slice at path in obj, or
+// placeholder empty maps for non-map elements to preserve indices.
+func getNestedSlice(obj map[string]interface{}, path ...string) (elems []map[string]interface{}) {
+	parent, ok := getNestedMap(obj, path[:len(path)-1]...)
+	if !ok {
+		return nil
+	}
+	items, _ := parent[path[len(path)-1]].([]interface{})
+	for _, item := range items {
+		elem, _ := item.(map[string]interface{})
+		if elem == nil {
+			elem = map[string]interface{}{}
+		}
+		elems = append(elems, elem)
+	}
+	return elems
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	digest "github.com/opencontainers/go-digest"
+	"sigs.k8s.io/yaml"
+)
+
+const rewriteCSV = `
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+  annotations:
+    containerImage: quay.io/example/memcached-operator:v0.0.1
+spec:
+  install:
+    strategy: deployment
+    spec:
+      deployments:
+      - name: memcached-operator
+        spec:
+          template:
+            spec:
+              initContainers:
+              - name: init
+                image: docker.io/library/busybox:1.32
+              containers:
+              - name: manager
+                image: quay.io/example/memcached-operator:v0.0.1
+                env:
+                - name: RELATED_IMAGE_MEMCACHED
+                  value: quay.io/example/memcached:1.4.36
+                - name: WATCH_NAMESPACE
+                  value: quay.io/example/not-an-image
+              - name: proxy
+                image: gcr.io/kubebuilder/kube-rbac-proxy:v0.5.0
+  relatedImages:
+  - name: memcached
+    image: quay.io/example/memcached:1.4.36
+  - name: busybox
+    image: busybox:1.32
+`
+
+var _ = Describe("ImageRewriter", func() {
+	var (
+		ctx context.Context
+		csv map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		csv = map[string]interface{}{}
+		Expect(yaml.Unmarshal([]byte(rewriteCSV), &csv)).To(Succeed())
+	})
+
+	It("rewrites references with the from prefix", func() {
+		r := ImageRewriter{From: "quay.io/example", To: "registry.example.com/mirror/"}
+		rewrites, err := r.RewriteCSV(ctx, csv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewrites).To(Equal([]ImageRewrite{
+			{
+				Path: "metadata.annotations.containerImage",
+				From: "quay.io/example/memcached-operator:v0.0.1",
+				To:   "registry.example.com/mirror/memcached-operator:v0.0.1",
+			},
+			{
+				Path: "spec.install.spec.deployments[0].spec.template.spec.containers[0].image",
+				From: "quay.io/example/memcached-operator:v0.0.1",
+				To:   "registry.example.com/mirror/memcached-operator:v0.0.1",
+			},
+			{
+				Path: "spec.install.spec.deployments[0].spec.template.spec.containers[0].env[0].value",
+				From: "quay.io/example/memcached:1.4.36",
+				To:   "registry.example.com/mirror/memcached:1.4.36",
+			},
+			{
+				Path: "spec.relatedImages[0].image",
+				From: "quay.io/example/memcached:1.4.36",
+				To:   "registry.example.com/mirror/memcached:1.4.36",
+			},
+		}))
+		related := getNestedSlice(csv, "spec", "relatedImages")
+		Expect(related[0]["image"]).To(Equal("registry.example.com/mirror/memcached:1.4.36"))
+		Expect(related[1]["image"]).To(Equal("busybox:1.32"))
+	})
+	It("matches normalized references", func() {
+		r := ImageRewriter{From: "docker.io", To: "mirror.example.com"}
+		rewrites, err := r.RewriteCSV(ctx, csv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewrites).To(HaveLen(2))
+		Expect(rewrites[0].To).To(Equal("mirror.example.com/library/busybox:1.32"))
+		Expect(rewrites[1].Path).To(Equal("spec.relatedImages[1].image"))
+		Expect(rewrites[1].To).To(Equal("mirror.example.com/library/busybox:1.32"))
+	})
+	It("does not match a registry prefix mid-component", func() {
+		r := ImageRewriter{From: "quay.io/ex", To: "mirror.example.com"}
+		rewrites, err := r.RewriteCSV(ctx, csv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewrites).To(BeEmpty())
+	})
+	It("resolves digests of rewritten references", func() {
+		dgst := digest.FromString("image")
+		var resolved []string
+		r := ImageRewriter{
+			From: "quay.io/example", To: "mirror.example.com",
+			ResolveDigest: func(_ context.Context, ref string) (digest.Digest, error) {
+				resolved = append(resolved, ref)
+				return dgst, nil
+			},
+		}
+		rewrites, err := r.RewriteCSV(ctx, csv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewrites).To(HaveLen(4))
+		Expect(rewrites[0].To).To(Equal("mirror.example.com/memcached-operator@" + dgst.String()))
+		Expect(rewrites[3].To).To(Equal("mirror.example.com/memcached@" + dgst.String()))
+		Expect(resolved).To(ContainElement("mirror.example.com/memcached:1.4.36"))
+	})
+	It("does not resolve references that have a digest", func() {
+		dgst := digest.FromString("image")
+		annotations, _ := getNestedMap(csv, "metadata", "annotations")
+		annotations["containerImage"] = "quay.io/example/memcached-operator@" + dgst.String()
+		r := ImageRewriter{
+			From: "quay.io/example", To: "mirror.example.com",
+			ResolveDigest: func(context.Context, string) (digest.Digest, error) {
+				return "", errors.New("not found")
+			},
+		}
+		csv = map[string]interface{}{"metadata": map[string]interface{}{"annotations": annotations}}
+		rewrites, err := r.RewriteCSV(ctx, csv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewrites).To(Equal([]ImageRewrite{{
+			Path: "metadata.annotations.containerImage",
+			From: "quay.io/example/memcached-operator@" + dgst.String(),
+			To:   "mirror.example.com/memcached-operator@" + dgst.String(),
+		}}))
+	})
+	It("returns an error if a digest cannot be resolved", func() {
+		r := ImageRewriter{
+			From: "quay.io/example", To: "mirror.example.com",
+			ResolveDigest: func(context.Context, string) (digest.Digest, error) {
+				return "", errors.New("not found")
+			},
+		}
+		_, err := r.RewriteCSV(ctx, csv)
+		Expect(err).To(MatchError(ContainSubstring("metadata.annotations.containerImage: error resolving digest")))
+	})
+	It("returns an error if a rewritten reference is invalid", func() {
+		r := ImageRewriter{From: "quay.io/example", To: "Mirror.Example.com/UPPER"}
+		_, err := r.RewriteCSV(ctx, csv)
+		Expect(err).To(MatchError(ContainSubstring("invalid rewritten image reference")))
+	})
+})
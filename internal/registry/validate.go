@@ -17,9 +17,14 @@ package registry
 import (
 	"fmt"
 	"io/ioutil"
+	"net/mail"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
 
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	apivalidation "github.com/operator-framework/api/pkg/validation"
 	apierrors "github.com/operator-framework/api/pkg/validation/errors"
 	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
@@ -27,6 +32,7 @@ import (
 	"gopkg.in/yaml.v2"
 	k8svalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -78,6 +84,10 @@ func ValidateBundleContent(logger *log.Entry, bundle *apimanifests.Bundle,
 	// All bundles must have a CSV currently.
 	if bundle.CSV != nil {
 		results = append(results, apivalidation.ClusterServiceVersionValidator.Validate(bundle.CSV)...)
+		errs.Add(validateOwnedCRDs(bundle)...)
+		errs.Add(validateContacts(bundle.CSV)...)
+		errs.Add(validateListing(bundle.CSV)...)
+		errs.Add(validateInstallStrategy(bundle.CSV)...)
 	} else {
 		errs.Add(apierrors.ErrInvalidBundle("no ClusterServiceVersion in bundle", bundle.Name))
 	}
@@ -100,6 +110,255 @@ func ValidateBundleContent(logger *log.Entry, bundle *apimanifests.Bundle,
 	return results
 }
 
+// crdManifest is the group, kind, and versions defined by a CRD manifest.
+type crdManifest struct {
+	group    string
+	kind     string
+	versions []string
+}
+
+// gvks returns the GVKs defined by m, using kind if m does not define one.
+func (m crdManifest) gvks(kind string) (gvks []schema.GroupVersionKind) {
+	if m.kind != "" {
+		kind = m.kind
+	}
+	for _, v := range m.versions {
+		gvks = append(gvks, schema.GroupVersionKind{Group: m.group, Version: v, Kind: kind})
+	}
+	return gvks
+}
+
+func (m crdManifest) hasVersion(version string) bool {
+	for _, v := range m.versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOwnedCRDs cross-checks the CSV's owned CRDs against the CRD
+// manifests in bundle. An error is returned for each owned CRD with no
+// corresponding manifest, for each owned CRD whose declared version or kind
+// is not defined by its manifest, and for each CRD manifest that is not
+// declared as owned by the CSV. Version and kind errors report the declared
+// GVK and the GVKs the manifest defines.
+func validateOwnedCRDs(bundle *apimanifests.Bundle) (errs []apierrors.Error) {
+	manifests := make(map[string]crdManifest)
+	for _, crd := range bundle.V1beta1CRDs {
+		m := crdManifest{group: crd.Spec.Group, kind: crd.Spec.Names.Kind}
+		if crd.Spec.Version != "" {
+			m.versions = append(m.versions, crd.Spec.Version)
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Name != crd.Spec.Version {
+				m.versions = append(m.versions, v.Name)
+			}
+		}
+		manifests[crd.GetName()] = m
+	}
+	for _, crd := range bundle.V1CRDs {
+		m := crdManifest{group: crd.Spec.Group, kind: crd.Spec.Names.Kind}
+		for _, v := range crd.Spec.Versions {
+			m.versions = append(m.versions, v.Name)
+		}
+		manifests[crd.GetName()] = m
+	}
+
+	owned := make(map[string]struct{})
+	for _, desc := range bundle.CSV.Spec.CustomResourceDefinitions.Owned {
+		owned[desc.Name] = struct{}{}
+		m, hasManifest := manifests[desc.Name]
+		if !hasManifest {
+			errs = append(errs, apierrors.ErrInvalidBundle(
+				fmt.Sprintf("owned CRD %q has no corresponding manifest in the bundle", desc.Name), desc.Name))
+			continue
+		}
+		// CRD names are "<plural>.<group>", so the name declares the group.
+		if m.group == "" {
+			if i := strings.Index(desc.Name, "."); i >= 0 {
+				m.group = desc.Name[i+1:]
+			}
+		}
+		declared := schema.GroupVersionKind{Group: m.group, Version: desc.Version, Kind: desc.Kind}
+		switch {
+		case desc.Version != "" && !m.hasVersion(desc.Version):
+			errs = append(errs, apierrors.ErrInvalidBundle(
+				fmt.Sprintf("owned CRD %q version %q is not defined in its manifest: declared %q, expected one of %q",
+					desc.Name, desc.Version, declared, m.gvks(desc.Kind)), desc.Name))
+		case desc.Kind != "" && m.kind != "" && desc.Kind != m.kind:
+			expected := declared
+			expected.Kind = m.kind
+			errs = append(errs, apierrors.ErrInvalidBundle(
+				fmt.Sprintf("owned CRD %q kind %q does not match its manifest: declared %q, expected %q",
+					desc.Name, desc.Kind, declared, expected), desc.Name))
+		}
+	}
+
+	// Sort manifest names so errors are returned in a stable order.
+	names := make([]string, 0, len(manifests))
+	for name := range manifests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, isOwned := owned[name]; !isOwned {
+			errs = append(errs, apierrors.ErrInvalidBundle(
+				fmt.Sprintf("CRD %q is present in the bundle but not declared as owned by the CSV", name), name))
+		}
+	}
+	return errs
+}
+
+// validateInstallStrategy checks that csv's install strategy is the deployment strategy,
+// the only one OLM supports, and that its spec has at least one deployment, each with
+// at least one container. The spec is not checked if the strategy is unsupported.
+func validateInstallStrategy(csv *operatorsv1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	csvName := csv.GetName()
+	install := csv.Spec.InstallStrategy
+	if install.StrategyName != operatorsv1alpha1.InstallStrategyNameDeployment {
+		return []apierrors.Error{apierrors.ErrInvalidCSV(fmt.Sprintf("spec.install.strategy %q is not supported, must be %q",
+			install.StrategyName, operatorsv1alpha1.InstallStrategyNameDeployment), csvName)}
+	}
+	if len(install.StrategySpec.DeploymentSpecs) == 0 {
+		return []apierrors.Error{apierrors.ErrInvalidCSV("spec.install.spec.deployments must contain at least one deployment", csvName)}
+	}
+	for i, dep := range install.StrategySpec.DeploymentSpecs {
+		if len(dep.Spec.Template.Spec.Containers) == 0 {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf(
+				"spec.install.spec.deployments[%d] (%q) must have at least one container", i, dep.Name), csvName))
+		}
+	}
+	return errs
+}
+
+// Placeholder contact values, such as those scaffolded in new CSV bases,
+// that must be replaced before a CSV is published in a catalog.
+var (
+	placeholderNames = map[string]struct{}{
+		"maintainer name": {},
+		"provider name":   {},
+		"your-name":       {},
+		"your name":       {},
+	}
+	placeholderEmails = map[string]struct{}{
+		"your@email.com":    {},
+		"email@example.com": {},
+	}
+	// Domains reserved for documentation by RFC 2606, and scaffolded defaults.
+	placeholderDomains = map[string]struct{}{
+		"example.com": {},
+		"example.org": {},
+		"example.net": {},
+		"your.domain": {},
+	}
+)
+
+// validateContacts checks that csv's maintainers and provider are set with a
+// name, that maintainer emails and the provider URL are well-formed, and that
+// none are placeholder values. Missing and malformed values are errors, and
+// placeholder values are warnings. An error or warning is returned for each
+// offending field.
+func validateContacts(csv *operatorsv1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	csvName := csv.GetName()
+	if len(csv.Spec.Maintainers) == 0 {
+		errs = append(errs, apierrors.ErrInvalidCSV("spec.maintainers must contain at least one maintainer", csvName))
+	}
+	for i, m := range csv.Spec.Maintainers {
+		fieldPath := fmt.Sprintf("spec.maintainers[%d]", i)
+		errs = append(errs, validateContactName(csvName, fieldPath+".name", m.Name)...)
+		switch {
+		case m.Email == "":
+			errs = append(errs, apierrors.ErrInvalidCSV(fieldPath+".email must be set", csvName))
+		case !isEmail(m.Email):
+			errs = append(errs, apierrors.ErrInvalidCSV(
+				fmt.Sprintf("%s.email %q is not a valid email address", fieldPath, m.Email), csvName))
+		case isPlaceholderEmail(m.Email):
+			errs = append(errs, apierrors.WarnInvalidCSV(
+				fmt.Sprintf("%s.email %q is a placeholder value", fieldPath, m.Email), csvName))
+		}
+	}
+
+	provider := csv.Spec.Provider
+	errs = append(errs, validateContactName(csvName, "spec.provider.name", provider.Name)...)
+	if provider.URL != "" {
+		if u, err := url.Parse(provider.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(
+				fmt.Sprintf("spec.provider.url %q is not an absolute URL", provider.URL), csvName))
+		} else if _, isPlaceholder := placeholderDomains[strings.ToLower(u.Hostname())]; isPlaceholder {
+			errs = append(errs, apierrors.WarnInvalidCSV(
+				fmt.Sprintf("spec.provider.url %q is a placeholder value", provider.URL), csvName))
+		}
+	}
+	return errs
+}
+
+// validateListing checks that each of csv's links has a name and an absolute URL, and that each
+// of its keywords is non-empty and has no surrounding whitespace, since OperatorHub listings show
+// malformed entries as-is. Links to placeholder domains, ex. a scaffolded "https://<operator name>.domain",
+// are warnings. An error or warning is returned for each offending entry.
+func validateListing(csv *operatorsv1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	csvName := csv.GetName()
+	for i, link := range csv.Spec.Links {
+		fieldPath := fmt.Sprintf("spec.links[%d]", i)
+		if strings.TrimSpace(link.Name) == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(fieldPath+".name must be set", csvName))
+		}
+		if link.URL == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(fieldPath+".url must be set", csvName))
+		} else if u, err := url.Parse(link.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(
+				fmt.Sprintf("%s.url %q is not an absolute URL", fieldPath, link.URL), csvName))
+		} else if isPlaceholderHost(u.Hostname()) {
+			errs = append(errs, apierrors.WarnInvalidCSV(
+				fmt.Sprintf("%s.url %q is a placeholder value", fieldPath, link.URL), csvName))
+		}
+	}
+	for i, keyword := range csv.Spec.Keywords {
+		fieldPath := fmt.Sprintf("spec.keywords[%d]", i)
+		if strings.TrimSpace(keyword) == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(fieldPath+" must not be empty", csvName))
+		} else if strings.TrimSpace(keyword) != keyword {
+			errs = append(errs, apierrors.ErrInvalidCSV(
+				fmt.Sprintf("%s %q has leading or trailing whitespace", fieldPath, keyword), csvName))
+		}
+	}
+	return errs
+}
+
+// isPlaceholderHost returns true if host is a placeholder domain or in the scaffolded ".domain" top-level domain.
+func isPlaceholderHost(host string) bool {
+	host = strings.ToLower(host)
+	_, isPlaceholder := placeholderDomains[host]
+	return isPlaceholder || strings.HasSuffix(host, ".domain")
+}
+
+func validateContactName(csvName, fieldPath, name string) []apierrors.Error {
+	if strings.TrimSpace(name) == "" {
+		return []apierrors.Error{apierrors.ErrInvalidCSV(fieldPath+" must be set", csvName)}
+	}
+	if _, isPlaceholder := placeholderNames[strings.ToLower(strings.TrimSpace(name))]; isPlaceholder {
+		return []apierrors.Error{apierrors.WarnInvalidCSV(
+			fmt.Sprintf("%s %q is a placeholder value", fieldPath, name), csvName)}
+	}
+	return nil
+}
+
+// isEmail returns true if email is a bare email address, without a display name.
+func isEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+func isPlaceholderEmail(email string) bool {
+	email = strings.ToLower(email)
+	if _, isPlaceholder := placeholderEmails[email]; isPlaceholder {
+		return true
+	}
+	_, isPlaceholder := placeholderDomains[email[strings.LastIndex(email, "@")+1:]]
+	return isPlaceholder
+}
+
 // validateObject validates an arbitrary metav1.Object's metadata.
 func validateObject(obj metav1.Object) error {
 	f := func(string, bool) []string { return nil }
@@ -0,0 +1,240 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("validateOwnedCRDs", func() {
+	var bundle *apimanifests.Bundle
+
+	BeforeEach(func() {
+		bundle = &apimanifests.Bundle{
+			CSV: &operatorsv1alpha1.ClusterServiceVersion{},
+			V1CRDs: []*apiextv1.CustomResourceDefinition{
+				newDiffTestCRD("memcacheds.cache.example.com", "v1alpha1"),
+			},
+		}
+		bundle.CSV.Spec.CustomResourceDefinitions.Owned = []operatorsv1alpha1.CRDDescription{
+			{Name: "memcacheds.cache.example.com", Version: "v1alpha1", Kind: "Memcached"},
+		}
+	})
+
+	It("returns no errors when owned CRDs and manifests match", func() {
+		Expect(validateOwnedCRDs(bundle)).To(BeEmpty())
+	})
+	It("matches v1beta1 CRD manifests", func() {
+		bundle.V1CRDs = nil
+		crd := &apiextv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "memcacheds.cache.example.com"}}
+		crd.Spec.Version = "v1alpha1"
+		bundle.V1beta1CRDs = []*apiextv1beta1.CustomResourceDefinition{crd}
+		Expect(validateOwnedCRDs(bundle)).To(BeEmpty())
+	})
+	It("returns an error for an owned CRD with no manifest", func() {
+		bundle.CSV.Spec.CustomResourceDefinitions.Owned = append(bundle.CSV.Spec.CustomResourceDefinitions.Owned,
+			operatorsv1alpha1.CRDDescription{Name: "redis.cache.example.com", Version: "v1", Kind: "Redis"})
+		errs := validateOwnedCRDs(bundle)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring(`owned CRD "redis.cache.example.com" has no corresponding manifest`))
+	})
+	It("returns an error for an owned CRD version missing from its manifest", func() {
+		bundle.CSV.Spec.CustomResourceDefinitions.Owned[0].Version = "v1beta1"
+		errs := validateOwnedCRDs(bundle)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring(`version "v1beta1" is not defined in its manifest`))
+	})
+	It("reports the declared and expected GVKs for an owned CRD version missing from its manifest", func() {
+		crd := bundle.V1CRDs[0]
+		crd.Spec.Group, crd.Spec.Names.Kind = "cache.example.com", "Memcached"
+		crd.Spec.Versions = append(crd.Spec.Versions, apiextv1.CustomResourceDefinitionVersion{Name: "v1beta1"})
+		bundle.CSV.Spec.CustomResourceDefinitions.Owned[0].Version = "v1alpah1"
+		errs := validateOwnedCRDs(bundle)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring(`declared "cache.example.com/v1alpah1, Kind=Memcached", ` +
+			`expected one of ["cache.example.com/v1alpha1, Kind=Memcached" "cache.example.com/v1beta1, Kind=Memcached"]`))
+	})
+	It("returns an error with the declared and expected GVKs for an owned CRD kind not matching its manifest", func() {
+		crd := bundle.V1CRDs[0]
+		crd.Spec.Group, crd.Spec.Names.Kind = "cache.example.com", "Memcached"
+		bundle.CSV.Spec.CustomResourceDefinitions.Owned[0].Kind = "MemCached"
+		errs := validateOwnedCRDs(bundle)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring(`owned CRD "memcacheds.cache.example.com" kind "MemCached" does not match its manifest: ` +
+			`declared "cache.example.com/v1alpha1, Kind=MemCached", expected "cache.example.com/v1alpha1, Kind=Memcached"`))
+	})
+	It("matches the kind of v1beta1 CRD manifests", func() {
+		bundle.V1CRDs = nil
+		crd := &apiextv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "memcacheds.cache.example.com"}}
+		crd.Spec.Version, crd.Spec.Names.Kind = "v1alpha1", "Memcache"
+		bundle.V1beta1CRDs = []*apiextv1beta1.CustomResourceDefinition{crd}
+		errs := validateOwnedCRDs(bundle)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring(`expected "cache.example.com/v1alpha1, Kind=Memcache"`))
+	})
+	It("returns an error for a CRD manifest not declared as owned", func() {
+		bundle.V1CRDs = append(bundle.V1CRDs, newDiffTestCRD("redis.cache.example.com", "v1"))
+		errs := validateOwnedCRDs(bundle)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring(`CRD "redis.cache.example.com" is present in the bundle but not declared as owned`))
+	})
+})
+
+var _ = Describe("validateContacts", func() {
+	var csv *operatorsv1alpha1.ClusterServiceVersion
+
+	BeforeEach(func() {
+		csv = &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.Spec.Maintainers = []operatorsv1alpha1.Maintainer{{Name: "Memcached Team", Email: "memcached@redhat.com"}}
+		csv.Spec.Provider = operatorsv1alpha1.AppLink{Name: "Red Hat", URL: "https://www.redhat.com"}
+	})
+
+	It("returns no errors for complete contacts", func() {
+		Expect(validateContacts(csv)).To(BeEmpty())
+	})
+	It("returns no errors for a provider without a URL", func() {
+		csv.Spec.Provider.URL = ""
+		Expect(validateContacts(csv)).To(BeEmpty())
+	})
+	It("returns an error for missing maintainers and provider", func() {
+		csv.Spec.Maintainers = nil
+		csv.Spec.Provider = operatorsv1alpha1.AppLink{}
+		errs := validateContacts(csv)
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].Error()).To(ContainSubstring("spec.maintainers must contain at least one maintainer"))
+		Expect(errs[1].Error()).To(ContainSubstring("spec.provider.name must be set"))
+	})
+	It("returns an error for each malformed field", func() {
+		csv.Spec.Maintainers = append(csv.Spec.Maintainers,
+			operatorsv1alpha1.Maintainer{Name: "", Email: "Some Corp <corp@redhat.com>"})
+		csv.Spec.Provider.URL = "www.redhat.com"
+		errs := validateContacts(csv)
+		Expect(errs).To(HaveLen(3))
+		Expect(errs[0].Error()).To(ContainSubstring("spec.maintainers[1].name must be set"))
+		Expect(errs[1].Error()).To(ContainSubstring(`spec.maintainers[1].email "Some Corp <corp@redhat.com>" is not a valid email address`))
+		Expect(errs[2].Error()).To(ContainSubstring(`spec.provider.url "www.redhat.com" is not an absolute URL`))
+		for _, err := range errs {
+			Expect(err.Level).To(BeEquivalentTo(apierrors.LevelError))
+		}
+	})
+	It("returns a warning for each scaffolded placeholder value", func() {
+		csv.Spec.Maintainers = []operatorsv1alpha1.Maintainer{
+			{Name: "Maintainer Name", Email: "your@email.com"},
+			{Name: "your-name", Email: "corp@example.com"},
+		}
+		csv.Spec.Provider = operatorsv1alpha1.AppLink{Name: "Provider Name", URL: "https://your.domain"}
+		errs := validateContacts(csv)
+		Expect(errs).To(HaveLen(6))
+		for _, err := range errs {
+			Expect(err.Level).To(BeEquivalentTo(apierrors.LevelWarn))
+			Expect(err.Error()).To(ContainSubstring("is a placeholder value"))
+		}
+	})
+})
+
+var _ = Describe("validateListing", func() {
+	var csv *operatorsv1alpha1.ClusterServiceVersion
+
+	BeforeEach(func() {
+		csv = &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.Spec.Links = []operatorsv1alpha1.AppLink{{Name: "Memcached Operator", URL: "https://github.com/example/memcached-operator"}}
+		csv.Spec.Keywords = []string{"memcached", "cache"}
+	})
+
+	It("returns no errors for well-formed links and keywords", func() {
+		Expect(validateListing(csv)).To(BeEmpty())
+	})
+	It("returns no errors without links or keywords", func() {
+		csv.Spec.Links, csv.Spec.Keywords = nil, nil
+		Expect(validateListing(csv)).To(BeEmpty())
+	})
+	It("returns an error for each malformed link", func() {
+		csv.Spec.Links = append(csv.Spec.Links,
+			operatorsv1alpha1.AppLink{Name: " ", URL: "github.com/example/memcached-operator"},
+			operatorsv1alpha1.AppLink{Name: "Docs"},
+		)
+		errs := validateListing(csv)
+		Expect(errs).To(HaveLen(3))
+		Expect(errs[0].Error()).To(ContainSubstring("spec.links[1].name must be set"))
+		Expect(errs[1].Error()).To(ContainSubstring(`spec.links[1].url "github.com/example/memcached-operator" is not an absolute URL`))
+		Expect(errs[2].Error()).To(ContainSubstring("spec.links[2].url must be set"))
+		for _, err := range errs {
+			Expect(err.Level).To(BeEquivalentTo(apierrors.LevelError))
+		}
+	})
+	It("returns an error for each empty or untrimmed keyword", func() {
+		csv.Spec.Keywords = []string{"memcached", "", " cache", "\t"}
+		errs := validateListing(csv)
+		Expect(errs).To(HaveLen(3))
+		Expect(errs[0].Error()).To(ContainSubstring("spec.keywords[1] must not be empty"))
+		Expect(errs[1].Error()).To(ContainSubstring(`spec.keywords[2] " cache" has leading or trailing whitespace`))
+		Expect(errs[2].Error()).To(ContainSubstring("spec.keywords[3] must not be empty"))
+	})
+	It("returns a warning for a scaffolded placeholder link", func() {
+		csv.Spec.Links = []operatorsv1alpha1.AppLink{
+			{Name: "Memcached Operator", URL: "https://memcached-operator.domain"},
+			{Name: "Docs", URL: "https://docs.example.com/memcached"},
+		}
+		errs := validateListing(csv)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Level).To(BeEquivalentTo(apierrors.LevelWarn))
+		Expect(errs[0].Error()).To(ContainSubstring(`spec.links[0].url "https://memcached-operator.domain" is a placeholder value`))
+	})
+})
+
+var _ = Describe("validateInstallStrategy", func() {
+	var csv *operatorsv1alpha1.ClusterServiceVersion
+
+	BeforeEach(func() {
+		csv = &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.Spec.InstallStrategy.StrategyName = operatorsv1alpha1.InstallStrategyNameDeployment
+		dep := operatorsv1alpha1.StrategyDeploymentSpec{Name: "memcached-operator"}
+		dep.Spec.Template.Spec.Containers = []corev1.Container{{Name: "manager", Image: "quay.io/example/memcached-operator:v0.0.1"}}
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []operatorsv1alpha1.StrategyDeploymentSpec{dep}
+	})
+
+	It("returns no errors for a deployment strategy with containers", func() {
+		Expect(validateInstallStrategy(csv)).To(BeEmpty())
+	})
+	It("returns an error with the found strategy if it is not the deployment strategy", func() {
+		for _, strategy := range []string{"image", "Deployment", ""} {
+			csv.Spec.InstallStrategy.StrategyName = strategy
+			errs := validateInstallStrategy(csv)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring(`spec.install.strategy %q is not supported, must be "deployment"`, strategy))
+		}
+	})
+	It("returns an error if there are no deployments", func() {
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = nil
+		errs := validateInstallStrategy(csv)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring("spec.install.spec.deployments must contain at least one deployment"))
+	})
+	It("returns an error for each deployment without containers", func() {
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = append(csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs,
+			operatorsv1alpha1.StrategyDeploymentSpec{Name: "proxy"})
+		errs := validateInstallStrategy(csv)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring(`spec.install.spec.deployments[1] ("proxy") must have at least one container`))
+	})
+})
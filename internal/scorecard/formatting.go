@@ -23,10 +23,16 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
 )
 
-// getTestResult fetches the test pod log and converts it into
-// Test format
+// getTestResult fetches the test pod log, or the test pod's results file
+// if results storage is used, and converts it into Test format
 func (r PodTestRunner) getTestStatus(ctx context.Context, p *v1.Pod) (output *v1alpha3.TestStatus) {
-	logBytes, err := getPodLog(ctx, r.Client, p)
+	var logBytes []byte
+	var err error
+	if r.pvcName != "" {
+		logBytes, err = r.getStoredResults(p)
+	} else {
+		logBytes, err = getPodLog(ctx, r.Client, p)
+	}
 	if err != nil {
 		return convertErrorToStatus(err, string(logBytes))
 	}
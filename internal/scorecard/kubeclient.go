@@ -19,6 +19,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	cruntime "sigs.k8s.io/controller-runtime/pkg/client/config"
 )
@@ -31,11 +32,7 @@ import (
 //   the command line
 func GetKubeClient(kubeconfig string) (client kubernetes.Interface, err error) {
 
-	if kubeconfig != "" {
-		os.Setenv(k8sutil.KubeConfigEnvVar, kubeconfig)
-	}
-
-	config, err := cruntime.GetConfig()
+	config, err := GetKubeConfig(kubeconfig)
 	if err != nil {
 		return client, err
 	}
@@ -49,6 +46,17 @@ func GetKubeClient(kubeconfig string) (client kubernetes.Interface, err error) {
 	return clientset, err
 }
 
+// GetKubeConfig will get a kubernetes REST config from the same sources
+// as GetKubeClient.
+func GetKubeConfig(kubeconfig string) (*rest.Config, error) {
+
+	if kubeconfig != "" {
+		os.Setenv(k8sutil.KubeConfigEnvVar, kubeconfig)
+	}
+
+	return cruntime.GetConfig()
+}
+
 // GetKubeNamespace returns the kubernetes namespace to use
 // for scorecard pod creation
 // the order of how the namespace is determined is as follows:
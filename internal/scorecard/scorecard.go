@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
@@ -49,8 +50,40 @@ type PodTestRunner struct {
 	BundlePath     string
 	BundleMetadata registryutil.Labels
 	Client         kubernetes.Interface
-
-	configMapName string
+	// RESTConfig is used to read results from storage. Required if UseStorage is true.
+	RESTConfig *rest.Config
+
+	// UseStorage, if true, mounts a PersistentVolumeClaim shared by all test pods
+	// in a run, which tests write their results to instead of their pod logs.
+	UseStorage bool
+	// StorageClass is the results PersistentVolumeClaim's storage class.
+	// If empty, the cluster's default storage class is used.
+	StorageClass string
+	// StorageSize is the results PersistentVolumeClaim's size. Defaults to DefaultStorageSize.
+	StorageSize string
+	// StorageImage is the image of the pod results are read through. Defaults to DefaultStorageImage.
+	StorageImage string
+	// KeepArtifacts, if true, does not delete the results PersistentVolumeClaim during cleanup.
+	KeepArtifacts bool
+
+	// NodeSelector is added to the node selector of all pods created for a run.
+	NodeSelector map[string]string
+	// Tolerations are added to the tolerations of all pods created for a run.
+	Tolerations []v1.Toleration
+
+	// CreateNamespace, if true, creates a namespace with a unique name for a run,
+	// which overrides Namespace, so runs do not interfere with each other.
+	// ServiceAccount, and any test's named service account, is created in it.
+	CreateNamespace bool
+	// KeepNamespace, if true, does not delete the namespace created for a run
+	// during cleanup.
+	KeepNamespace bool
+
+	createdNamespace string
+	configMapName    string
+	pvcName          string
+	readerPodName    string
+	rbac             *testRBAC
 }
 
 type FakeTestRunner struct {
@@ -159,17 +192,48 @@ func (r FakeTestRunner) Initialize(ctx context.Context) error {
 	}
 }
 
-// Initialize sets up the bundle configmap for tests
-func (r *PodTestRunner) Initialize(ctx context.Context) error {
+// Initialize sets up the namespace, if CreateNamespace is set, and the bundle
+// configmap for tests. The created namespace is deleted if a later step fails.
+func (r *PodTestRunner) Initialize(ctx context.Context) (err error) {
 	bundleData, err := r.getBundleData()
 	if err != nil {
 		return fmt.Errorf("error getting bundle data %w", err)
 	}
 
+	if r.CreateNamespace {
+		if err := r.initializeNamespace(ctx); err != nil {
+			return fmt.Errorf("error initializing namespace %w", err)
+		}
+		defer func() {
+			if err != nil && r.createdNamespace != "" {
+				clctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+				defer cancel()
+				if nsErr := r.deleteNamespace(clctx); nsErr != nil {
+					err = fmt.Errorf("%v (cleanup failed: %v)", err, nsErr)
+				}
+			}
+		}()
+	}
+
 	r.configMapName, err = r.CreateConfigMap(ctx, bundleData)
 	if err != nil {
 		return fmt.Errorf("error creating ConfigMap %w", err)
 	}
+	r.rbac = &testRBAC{}
+
+	if r.UseStorage {
+		if err = r.initializeStorage(ctx); err != nil {
+			err = fmt.Errorf("error initializing results storage %w", err)
+			// Run() does not call Cleanup() if Initialize() fails, so delete the
+			// ConfigMap and any storage resources created so far.
+			clctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+			defer cancel()
+			if clErr := r.cleanupStorage(clctx); clErr != nil {
+				err = fmt.Errorf("%v (cleanup failed: %v)", err, clErr)
+			}
+			return err
+		}
+	}
 	return nil
 
 }
@@ -183,23 +247,76 @@ func (r FakeTestRunner) Cleanup(ctx context.Context) error {
 	}
 }
 
-// Cleanup deletes pods and configmap resources from this test run
+// Cleanup deletes pods, configmap, service accounts and ClusterRoleBindings
+// created for tests, and unless KeepArtifacts is set, results storage resources
+// from this test run. Unless KeepNamespace is set, the namespace created for
+// this run is then deleted, even if deleting other resources failed.
 func (r PodTestRunner) Cleanup(ctx context.Context) (err error) {
+	err = r.cleanupResources(ctx)
+	if r.createdNamespace != "" && !r.KeepNamespace {
+		if nsErr := r.deleteNamespace(ctx); err == nil {
+			err = nsErr
+		}
+	}
+	return err
+}
+
+// cleanupResources deletes the resources created for this test run.
+func (r PodTestRunner) cleanupResources(ctx context.Context) (err error) {
 	err = r.deletePods(ctx, r.configMapName)
 	if err != nil {
 		return err
 	}
+	err = r.deleteRBAC(ctx)
+	if err != nil {
+		return err
+	}
 	err = r.deleteConfigMap(ctx, r.configMapName)
 	if err != nil {
 		return err
 	}
+	if r.pvcName != "" && !r.KeepArtifacts {
+		err = r.deletePVC(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanupStorage deletes the ConfigMap, results reader pod, and results
+// PersistentVolumeClaim created by a run whose storage failed to initialize.
+// The claim has no results to keep, so it is deleted even if KeepArtifacts is set.
+func (r PodTestRunner) cleanupStorage(ctx context.Context) error {
+	if err := r.deletePods(ctx, r.configMapName); err != nil {
+		return err
+	}
+	if err := r.deleteConfigMap(ctx, r.configMapName); err != nil {
+		return err
+	}
+	if r.pvcName != "" {
+		return r.deletePVC(ctx)
+	}
 	return nil
 }
 
 // RunTest executes a single test
 func (r PodTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfiguration) (*v1alpha3.TestStatus, error) {
+	if err := validateTestScheduling(test); err != nil {
+		return nil, err
+	}
+	serviceAccount, err := r.setupServiceAccount(ctx, test)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a Pod to run the test
 	podDef := getPodDefinition(r.configMapName, test, r)
+	podDef.Spec.ServiceAccountName = serviceAccount
+	r.setPodScheduling(&podDef.Spec, test)
+	if r.pvcName != "" {
+		addResultsStorage(podDef, r.pvcName, r.configMapName)
+	}
 	pod, err := r.Client.CoreV1().Pods(r.Namespace).Create(ctx, podDef, metav1.CreateOptions{})
 	if err != nil {
 		return nil, err
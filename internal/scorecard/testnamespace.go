@@ -0,0 +1,98 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// defaultServiceAccount is the service account created in every namespace.
+const defaultServiceAccount = "default"
+
+// CreatedNamespace returns the name of the namespace created for this run, or
+// an empty string if CreateNamespace is not set.
+func (r PodTestRunner) CreatedNamespace() string {
+	return r.createdNamespace
+}
+
+// initializeNamespace creates a namespace with a unique name for this run, sets
+// it as the runner's namespace, and ensures the runner's service account exists
+// in it.
+func (r *PodTestRunner) initializeNamespace(ctx context.Context) error {
+	ns := getNamespaceDefinition()
+	ns, err := r.Client.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error creating namespace %w", err)
+	}
+	r.createdNamespace = ns.GetName()
+	r.Namespace = r.createdNamespace
+
+	if r.ServiceAccount == "" || r.ServiceAccount == defaultServiceAccount {
+		return r.waitForDefaultServiceAccount(ctx)
+	}
+	sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: r.ServiceAccount, Namespace: r.Namespace}}
+	if _, err := r.Client.CoreV1().ServiceAccounts(r.Namespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating service account %s %w", r.ServiceAccount, err)
+	}
+	return nil
+}
+
+// getNamespaceDefinition returns a Namespace definition with a unique name
+// that a test run's resources are created in
+func getNamespaceDefinition() *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("scorecard-%s", rand.String(8)),
+			Labels: map[string]string{
+				"app": "scorecard-test",
+			},
+		},
+	}
+}
+
+// waitForDefaultServiceAccount waits for the default service account, which
+// is created asynchronously, to exist in the created namespace, since pods in a
+// namespace cannot be created before their service account.
+func (r PodTestRunner) waitForDefaultServiceAccount(ctx context.Context) error {
+	saCheck := wait.ConditionFunc(func() (done bool, err error) {
+		_, err = r.Client.CoreV1().ServiceAccounts(r.Namespace).Get(ctx, defaultServiceAccount, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err := wait.PollImmediateUntil(500*time.Millisecond, saCheck, ctx.Done()); err != nil {
+		return fmt.Errorf("error waiting for service account %s %w", defaultServiceAccount, err)
+	}
+	return nil
+}
+
+// deleteNamespace deletes the namespace created for this run, and with it all
+// resources created in it.
+func (r PodTestRunner) deleteNamespace(ctx context.Context) error {
+	err := r.Client.CoreV1().Namespaces().Delete(ctx, r.createdNamespace, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting namespace %s %w", r.createdNamespace, err)
+	}
+	return nil
+}
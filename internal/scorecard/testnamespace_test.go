@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Test namespaces", func() {
+	var (
+		ctx    context.Context
+		client *fake.Clientset
+		r      PodTestRunner
+	)
+	BeforeEach(func() {
+		ctx = context.TODO()
+		client = fake.NewSimpleClientset()
+		// Create the default service account in new namespaces like the service
+		// account controller does.
+		client.PrependReactor("create", "namespaces", func(a k8stesting.Action) (bool, runtime.Object, error) {
+			ns := a.(k8stesting.CreateAction).GetObject().(*v1.Namespace)
+			sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: ns.GetName()}}
+			return false, nil, client.Tracker().Add(sa)
+		})
+
+		bundlePath := filepath.Join("testdata", "bundle")
+		metadata, _, err := registry.FindBundleMetadata(bundlePath)
+		Expect(err).NotTo(HaveOccurred())
+		r = PodTestRunner{
+			Namespace:       "ignored",
+			ServiceAccount:  "default",
+			BundlePath:      bundlePath,
+			BundleMetadata:  metadata,
+			Client:          client,
+			CreateNamespace: true,
+		}
+	})
+
+	It("runs tests in a unique namespace created for the run", func() {
+		Expect(r.Initialize(ctx)).To(Succeed())
+		ns := r.CreatedNamespace()
+		Expect(ns).To(HavePrefix("scorecard-"))
+		Expect(r.Namespace).To(Equal(ns))
+		_, err := client.CoreV1().ConfigMaps(ns).Get(ctx, r.configMapName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		other := r
+		other.Namespace = "ignored"
+		Expect(other.Initialize(ctx)).To(Succeed())
+		Expect(other.CreatedNamespace()).NotTo(Equal(ns))
+	})
+	It("creates a non-default runner service account", func() {
+		r.ServiceAccount = "tester"
+		Expect(r.Initialize(ctx)).To(Succeed())
+		_, err := client.CoreV1().ServiceAccounts(r.Namespace).Get(ctx, "tester", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("creates service accounts named by tests", func() {
+		Expect(r.Initialize(ctx)).To(Succeed())
+		test := v1alpha3.TestConfiguration{ServiceAccount: &v1alpha3.ServiceAccountConfiguration{Name: "tester"}}
+		name, err := r.setupServiceAccount(ctx, test)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("tester"))
+		_, err = client.CoreV1().ServiceAccounts(r.Namespace).Get(ctx, "tester", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("deletes the namespace during cleanup", func() {
+		Expect(r.Initialize(ctx)).To(Succeed())
+		Expect(r.Cleanup(ctx)).To(Succeed())
+		_, err := client.CoreV1().Namespaces().Get(ctx, r.CreatedNamespace(), metav1.GetOptions{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+	It("deletes the namespace even if deleting other resources fails", func() {
+		Expect(r.Initialize(ctx)).To(Succeed())
+		client.PrependReactor("delete-collection", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("forbidden")
+		})
+		Expect(r.Cleanup(ctx)).To(MatchError(ContainSubstring("forbidden")))
+		_, err := client.CoreV1().Namespaces().Get(ctx, r.CreatedNamespace(), metav1.GetOptions{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+	It("keeps the namespace if KeepNamespace is set", func() {
+		r.KeepNamespace = true
+		Expect(r.Initialize(ctx)).To(Succeed())
+		Expect(r.Cleanup(ctx)).To(Succeed())
+		_, err := client.CoreV1().Namespaces().Get(ctx, r.CreatedNamespace(), metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("deletes the namespace if initialization fails after creating it", func() {
+		client.PrependReactor("create", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("quota exceeded")
+		})
+		Expect(r.Initialize(ctx)).To(MatchError(ContainSubstring("quota exceeded")))
+		nss, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nss.Items).To(BeEmpty())
+	})
+})
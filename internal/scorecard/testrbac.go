@@ -0,0 +1,171 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+// testRBAC records the service accounts and ClusterRoleBindings created for
+// tests in a run, so they can be deleted during cleanup.
+type testRBAC struct {
+	mu                  sync.Mutex
+	serviceAccounts     []string
+	clusterRoleBindings []string
+}
+
+// setupServiceAccount returns the name of the service account test's pod runs
+// as. If test configures a service account, that service account is verified
+// to exist or is created, and bound to the test's ClusterRole if one is set.
+// A named service account is created if it does not exist in a namespace
+// created for this run.
+func (r PodTestRunner) setupServiceAccount(ctx context.Context, test v1alpha3.TestConfiguration) (string, error) {
+	cfg := test.ServiceAccount
+	if cfg == nil {
+		return r.ServiceAccount, nil
+	}
+	if cfg.Name == "" && cfg.ClusterRole == "" {
+		return "", errors.New("a test's serviceAccount must set a name, a clusterRole, or both")
+	}
+
+	// Verify the ClusterRole exists before creating anything, since binding
+	// a missing ClusterRole succeeds but grants nothing.
+	if cfg.ClusterRole != "" {
+		if _, err := r.Client.RbacV1().ClusterRoles().Get(ctx, cfg.ClusterRole, metav1.GetOptions{}); err != nil {
+			return "", fmt.Errorf("error getting ClusterRole %s %w", cfg.ClusterRole, err)
+		}
+	}
+
+	name := cfg.Name
+	if name == "" {
+		sa := getServiceAccountDefinition(r.configMapName, r.Namespace)
+		sa, err := r.Client.CoreV1().ServiceAccounts(r.Namespace).Create(ctx, sa, metav1.CreateOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error creating service account %w", err)
+		}
+		name = sa.GetName()
+		r.rbac.addServiceAccount(name)
+	} else if _, err := r.Client.CoreV1().ServiceAccounts(r.Namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		// A namespace created for this run has no service accounts to reuse.
+		if r.createdNamespace == "" || !apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("error getting service account %s %w", name, err)
+		}
+		sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.Namespace}}
+		if _, err := r.Client.CoreV1().ServiceAccounts(r.Namespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil &&
+			!apierrors.IsAlreadyExists(err) {
+			return "", fmt.Errorf("error creating service account %s %w", name, err)
+		}
+	}
+
+	if cfg.ClusterRole != "" {
+		crb := getClusterRoleBindingDefinition(r.configMapName, r.Namespace, name, cfg.ClusterRole)
+		crb, err := r.Client.RbacV1().ClusterRoleBindings().Create(ctx, crb, metav1.CreateOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error creating ClusterRoleBinding %w", err)
+		}
+		r.rbac.addClusterRoleBinding(crb.GetName())
+	}
+	return name, nil
+}
+
+// getServiceAccountDefinition returns a ServiceAccount definition that a
+// test pod runs as
+func getServiceAccountDefinition(configMapName, namespace string) *v1.ServiceAccount {
+	return &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("scorecard-test-%s", rand.String(4)),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":     "scorecard-test",
+				"testrun": configMapName,
+			},
+		},
+	}
+}
+
+// getClusterRoleBindingDefinition returns a ClusterRoleBinding definition that
+// binds clusterRole to the service account saName in namespace
+func getClusterRoleBindingDefinition(configMapName, namespace, saName, clusterRole string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("scorecard-test-%s", rand.String(8)),
+			Labels: map[string]string{
+				"app":     "scorecard-test",
+				"testrun": configMapName,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      saName,
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+func (t *testRBAC) addServiceAccount(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.serviceAccounts = append(t.serviceAccounts, name)
+}
+
+func (t *testRBAC) addClusterRoleBinding(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clusterRoleBindings = append(t.clusterRoleBindings, name)
+}
+
+// deleteRBAC deletes the ClusterRoleBindings and service accounts created for
+// tests in this run, and is called as part of the test run cleanup
+func (r PodTestRunner) deleteRBAC(ctx context.Context) error {
+	if r.rbac == nil {
+		return nil
+	}
+	r.rbac.mu.Lock()
+	defer r.rbac.mu.Unlock()
+
+	for _, name := range r.rbac.clusterRoleBindings {
+		err := r.Client.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("error deleting ClusterRoleBinding %s %w", name, err)
+		}
+	}
+	r.rbac.clusterRoleBindings = nil
+	for _, name := range r.rbac.serviceAccounts {
+		err := r.Client.CoreV1().ServiceAccounts(r.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("error deleting service account %s %w", name, err)
+		}
+	}
+	r.rbac.serviceAccounts = nil
+	return nil
+}
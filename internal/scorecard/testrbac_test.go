@@ -0,0 +1,125 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Test service accounts", func() {
+	const (
+		namespace     = "test-ns"
+		configMapName = "scorecard-test-abcd"
+		clusterRole   = "cross-namespace-tester"
+	)
+
+	var (
+		ctx context.Context
+		r   PodTestRunner
+	)
+	BeforeEach(func() {
+		ctx = context.TODO()
+		sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "tester", Namespace: namespace}}
+		cr := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: clusterRole}}
+		r = PodTestRunner{
+			Namespace:      namespace,
+			ServiceAccount: "default",
+			Client:         fake.NewSimpleClientset(sa, cr),
+			configMapName:  configMapName,
+			rbac:           &testRBAC{},
+		}
+	})
+
+	Describe("setupServiceAccount", func() {
+		It("uses the runner's service account if the test does not configure one", func() {
+			name, err := r.setupServiceAccount(ctx, v1alpha3.TestConfiguration{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("default"))
+		})
+		It("returns an error if neither a name nor a ClusterRole is set", func() {
+			test := v1alpha3.TestConfiguration{ServiceAccount: &v1alpha3.ServiceAccountConfiguration{}}
+			_, err := r.setupServiceAccount(ctx, test)
+			Expect(err).To(HaveOccurred())
+		})
+		It("uses an existing service account", func() {
+			test := v1alpha3.TestConfiguration{ServiceAccount: &v1alpha3.ServiceAccountConfiguration{Name: "tester"}}
+			name, err := r.setupServiceAccount(ctx, test)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("tester"))
+			Expect(r.rbac.serviceAccounts).To(BeEmpty())
+			Expect(r.rbac.clusterRoleBindings).To(BeEmpty())
+		})
+		It("returns an error if the service account does not exist", func() {
+			test := v1alpha3.TestConfiguration{ServiceAccount: &v1alpha3.ServiceAccountConfiguration{Name: "missing"}}
+			_, err := r.setupServiceAccount(ctx, test)
+			Expect(err).To(MatchError(ContainSubstring(`"missing" not found`)))
+		})
+		It("returns an error if the ClusterRole does not exist", func() {
+			test := v1alpha3.TestConfiguration{ServiceAccount: &v1alpha3.ServiceAccountConfiguration{ClusterRole: "missing"}}
+			_, err := r.setupServiceAccount(ctx, test)
+			Expect(err).To(MatchError(ContainSubstring(`"missing" not found`)))
+			Expect(r.rbac.serviceAccounts).To(BeEmpty())
+		})
+		It("creates a service account bound to the ClusterRole, and deletes both during cleanup", func() {
+			test := v1alpha3.TestConfiguration{ServiceAccount: &v1alpha3.ServiceAccountConfiguration{ClusterRole: clusterRole}}
+			name, err := r.setupServiceAccount(ctx, test)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.rbac.serviceAccounts).To(Equal([]string{name}))
+			Expect(r.rbac.clusterRoleBindings).To(HaveLen(1))
+
+			sa, err := r.Client.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sa.GetLabels()).To(HaveKeyWithValue("testrun", configMapName))
+			crbName := r.rbac.clusterRoleBindings[0]
+			crb, err := r.Client.RbacV1().ClusterRoleBindings().Get(ctx, crbName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.RoleRef.Name).To(Equal(clusterRole))
+			Expect(crb.Subjects).To(Equal([]rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace},
+			}))
+
+			Expect(r.deleteRBAC(ctx)).To(Succeed())
+			_, err = r.Client.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			_, err = r.Client.RbacV1().ClusterRoleBindings().Get(ctx, crbName, metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+		It("binds the ClusterRole to an existing service account without deleting it during cleanup", func() {
+			test := v1alpha3.TestConfiguration{ServiceAccount: &v1alpha3.ServiceAccountConfiguration{
+				Name:        "tester",
+				ClusterRole: clusterRole,
+			}}
+			name, err := r.setupServiceAccount(ctx, test)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("tester"))
+			Expect(r.rbac.serviceAccounts).To(BeEmpty())
+			Expect(r.rbac.clusterRoleBindings).To(HaveLen(1))
+
+			Expect(r.deleteRBAC(ctx)).To(Succeed())
+			_, err = r.Client.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
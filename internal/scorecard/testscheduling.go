@@ -0,0 +1,139 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+// ParseTolerations parses values of the form "<key>[=<value>][:<effect>]" into tolerations.
+// A toleration with a value uses the "Equal" operator, and one without uses "Exists".
+// A toleration without an effect tolerates all effects.
+func ParseTolerations(values []string) (tolerations []v1.Toleration, err error) {
+	for _, value := range values {
+		t := v1.Toleration{}
+		keyValue := value
+		if i := strings.Index(value, ":"); i >= 0 {
+			keyValue, t.Effect = value[:i], v1.TaintEffect(value[i+1:])
+		}
+		if i := strings.Index(keyValue, "="); i >= 0 {
+			t.Key, t.Value, t.Operator = keyValue[:i], keyValue[i+1:], v1.TolerationOpEqual
+		} else {
+			t.Key, t.Operator = keyValue, v1.TolerationOpExists
+		}
+		if t.Key == "" {
+			return nil, fmt.Errorf("toleration %q must have a key", value)
+		}
+		if err := validateToleration(t); err != nil {
+			return nil, fmt.Errorf("invalid toleration %q: %v", value, err)
+		}
+		tolerations = append(tolerations, t)
+	}
+	return tolerations, nil
+}
+
+// ValidateNodeSelector returns an error if any key or value in nodeSelector is not a valid label.
+func ValidateNodeSelector(nodeSelector map[string]string) error {
+	for key, value := range nodeSelector {
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return fmt.Errorf("invalid node selector key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return fmt.Errorf("invalid node selector value %q for key %q: %s", value, key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// validateToleration returns an error if t would be rejected by the API server.
+func validateToleration(t v1.Toleration) error {
+	if t.Key != "" {
+		if errs := validation.IsQualifiedName(t.Key); len(errs) != 0 {
+			return fmt.Errorf("invalid key %q: %s", t.Key, strings.Join(errs, ", "))
+		}
+	}
+	switch t.Operator {
+	case v1.TolerationOpEqual, "":
+		if t.Key == "" {
+			return fmt.Errorf("operator must be %q if key is empty", v1.TolerationOpExists)
+		}
+		if errs := validation.IsValidLabelValue(t.Value); len(errs) != 0 {
+			return fmt.Errorf("invalid value %q: %s", t.Value, strings.Join(errs, ", "))
+		}
+	case v1.TolerationOpExists:
+		if t.Value != "" {
+			return fmt.Errorf("value must be empty if operator is %q", v1.TolerationOpExists)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q: must be one of [%q, %q]", t.Operator, v1.TolerationOpEqual, v1.TolerationOpExists)
+	}
+	switch t.Effect {
+	case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+	default:
+		return fmt.Errorf("unknown effect %q: must be one of [%q, %q, %q]", t.Effect,
+			v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute)
+	}
+	if t.TolerationSeconds != nil && t.Effect != v1.TaintEffectNoExecute {
+		return fmt.Errorf("tolerationSeconds can only be set if effect is %q", v1.TaintEffectNoExecute)
+	}
+	return nil
+}
+
+// validateTestScheduling returns an error if test's node selector or tolerations are invalid.
+func validateTestScheduling(test v1alpha3.TestConfiguration) error {
+	if err := ValidateNodeSelector(test.NodeSelector); err != nil {
+		return fmt.Errorf("test %s: %v", test.Image, err)
+	}
+	for i, t := range test.Tolerations {
+		if err := validateToleration(t); err != nil {
+			return fmt.Errorf("test %s: invalid tolerations[%d]: %v", test.Image, i, err)
+		}
+	}
+	return nil
+}
+
+// addRunScheduling adds the node selector and tolerations set for all pods in a run to spec.
+func (r PodTestRunner) addRunScheduling(spec *v1.PodSpec) {
+	if len(r.NodeSelector) != 0 && spec.NodeSelector == nil {
+		spec.NodeSelector = make(map[string]string, len(r.NodeSelector))
+	}
+	for key, value := range r.NodeSelector {
+		spec.NodeSelector[key] = value
+	}
+	spec.Tolerations = append(spec.Tolerations, r.Tolerations...)
+}
+
+// setPodScheduling sets spec's node selector, tolerations, and affinity from those set
+// for all pods in a run and those configured for test. test's node selector entries
+// override the run's entries with the same key.
+func (r PodTestRunner) setPodScheduling(spec *v1.PodSpec, test v1alpha3.TestConfiguration) {
+	r.addRunScheduling(spec)
+	if len(test.NodeSelector) != 0 && spec.NodeSelector == nil {
+		spec.NodeSelector = make(map[string]string, len(test.NodeSelector))
+	}
+	for key, value := range test.NodeSelector {
+		spec.NodeSelector[key] = value
+	}
+	spec.Tolerations = append(spec.Tolerations, test.Tolerations...)
+	if test.Affinity != nil {
+		spec.Affinity = test.Affinity.DeepCopy()
+	}
+}
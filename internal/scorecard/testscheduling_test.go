@@ -0,0 +1,165 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Test pod scheduling", func() {
+	const (
+		namespace     = "test-ns"
+		configMapName = "scorecard-test-abcd"
+	)
+
+	var (
+		gpuToleration = v1.Toleration{
+			Key:      "nvidia.com/gpu",
+			Operator: v1.TolerationOpExists,
+			Effect:   v1.TaintEffectNoSchedule,
+		}
+		armToleration = v1.Toleration{
+			Key:      "arch",
+			Operator: v1.TolerationOpEqual,
+			Value:    "arm64",
+		}
+		nodeAffinity = &v1.Affinity{
+			NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{{
+						MatchExpressions: []v1.NodeSelectorRequirement{{
+							Key:      "kubernetes.io/os",
+							Operator: v1.NodeSelectorOpIn,
+							Values:   []string{"linux"},
+						}},
+					}},
+				},
+			},
+		}
+	)
+
+	Describe("ParseTolerations", func() {
+		It("parses tolerations with and without values and effects", func() {
+			tolerations, err := ParseTolerations([]string{"nvidia.com/gpu:NoSchedule", "arch=arm64", "dedicated=test:NoExecute"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tolerations).To(Equal([]v1.Toleration{
+				gpuToleration,
+				armToleration,
+				{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "test", Effect: v1.TaintEffectNoExecute},
+			}))
+		})
+		It("returns an error for invalid tolerations", func() {
+			for _, value := range []string{":NoSchedule", "=arm64", "arch=arm 64", "arch:NoRun", "bad key:NoSchedule"} {
+				_, err := ParseTolerations([]string{value})
+				Expect(err).To(HaveOccurred(), value)
+			}
+		})
+	})
+
+	Describe("ValidateNodeSelector", func() {
+		It("accepts valid labels", func() {
+			Expect(ValidateNodeSelector(map[string]string{"kubernetes.io/arch": "arm64", "gpu": ""})).To(Succeed())
+		})
+		It("returns an error for invalid labels", func() {
+			Expect(ValidateNodeSelector(map[string]string{"kubernetes.io/arch": "arm 64"})).NotTo(Succeed())
+			Expect(ValidateNodeSelector(map[string]string{"-arch": "arm64"})).NotTo(Succeed())
+		})
+	})
+
+	Describe("validateTestScheduling", func() {
+		It("returns an error for invalid configured tolerations", func() {
+			test := v1alpha3.TestConfiguration{
+				Image:       "test-image",
+				Tolerations: []v1.Toleration{{Operator: v1.TolerationOpEqual, Value: "arm64"}},
+			}
+			Expect(validateTestScheduling(test)).To(MatchError(ContainSubstring("invalid tolerations[0]")))
+		})
+		It("accepts a configured toleration of all taints", func() {
+			test := v1alpha3.TestConfiguration{Tolerations: []v1.Toleration{{Operator: v1.TolerationOpExists}}}
+			Expect(validateTestScheduling(test)).To(Succeed())
+		})
+	})
+
+	Describe("RunTest", func() {
+		var r PodTestRunner
+		BeforeEach(func() {
+			r = PodTestRunner{
+				Namespace:      namespace,
+				ServiceAccount: "default",
+				Client:         fake.NewSimpleClientset(),
+				NodeSelector:   map[string]string{"kubernetes.io/arch": "arm64", "pool": "tests"},
+				Tolerations:    []v1.Toleration{armToleration},
+				configMapName:  configMapName,
+				rbac:           &testRBAC{},
+			}
+		})
+
+		It("creates test pods with the run's and the test's scheduling options", func() {
+			test := v1alpha3.TestConfiguration{
+				Image:        "test-image",
+				NodeSelector: map[string]string{"pool": "gpu"},
+				Tolerations:  []v1.Toleration{gpuToleration},
+				Affinity:     nodeAffinity,
+			}
+			// Cancel so RunTest returns after the pod is created.
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+			_, _ = r.RunTest(ctx, test)
+
+			pods, err := r.Client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(HaveLen(1))
+			spec := pods.Items[0].Spec
+			Expect(spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/arch": "arm64", "pool": "gpu"}))
+			Expect(spec.Tolerations).To(Equal([]v1.Toleration{armToleration, gpuToleration}))
+			Expect(spec.Affinity).To(Equal(nodeAffinity))
+			// The run's node selector is not modified by a test's.
+			Expect(r.NodeSelector).To(HaveKeyWithValue("pool", "tests"))
+		})
+
+		It("does not create a pod for invalid scheduling options", func() {
+			test := v1alpha3.TestConfiguration{Image: "test-image", NodeSelector: map[string]string{"pool": "not valid"}}
+			_, err := r.RunTest(context.TODO(), test)
+			Expect(err).To(MatchError(ContainSubstring("invalid node selector value")))
+
+			pods, err := r.Client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(BeEmpty())
+		})
+	})
+
+	Describe("addResultsStorage", func() {
+		It("keeps a test's configured affinity", func() {
+			r := PodTestRunner{Namespace: namespace}
+			test := v1alpha3.TestConfiguration{Image: "test-image", Affinity: nodeAffinity}
+			pod := getPodDefinition(configMapName, test, r)
+			r.setPodScheduling(&pod.Spec, test)
+			addResultsStorage(pod, configMapName, configMapName)
+
+			Expect(pod.Spec.Affinity.NodeAffinity).To(Equal(nodeAffinity.NodeAffinity))
+			Expect(pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+			// The configured affinity is not modified.
+			Expect(nodeAffinity.PodAffinity).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,263 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// PodResultsDir is the directory a test pod writes its results to when
+	// results storage is enabled.
+	PodResultsDir = "/test-output"
+	// ResultsFileEnv is the environment variable set in each test pod to the path
+	// of the file its results must be written to when results storage is enabled.
+	ResultsFileEnv = "SCORECARD_RESULTS_FILE"
+	// ResultsFileName is the name of a test pod's results file in PodResultsDir.
+	ResultsFileName = "results.json"
+
+	// DefaultStorageSize is the default size of the results PersistentVolumeClaim.
+	DefaultStorageSize = "1Gi"
+	// DefaultStorageImage is the default image of the results reader pod, pinned by
+	// digest so the same image is run on every cluster. It must provide 'sh' and 'cat'.
+	DefaultStorageImage = "docker.io/library/busybox@sha256:c71cb4f7e8ececaffb34037c2637dc86820e4185100e18b4d02d613a9bd772af"
+
+	resultsVolumeName   = "scorecard-results"
+	readerContainerName = "scorecard-results-reader"
+	readerAppLabel      = "scorecard-results-reader"
+)
+
+// ResultsClaimName returns the name of the PersistentVolumeClaim holding test
+// results, or an empty string if results storage is not used.
+func (r PodTestRunner) ResultsClaimName() string {
+	return r.pvcName
+}
+
+// initializeStorage creates a PersistentVolumeClaim shared by all test pods in
+// this run, and a reader pod that mounts it so results can be read from it.
+func (r *PodTestRunner) initializeStorage(ctx context.Context) error {
+	size := r.StorageSize
+	if size == "" {
+		size = DefaultStorageSize
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return fmt.Errorf("error parsing storage size %q: %w", size, err)
+	}
+
+	pvc := getPVCDefinition(r.configMapName, r.Namespace, r.StorageClass, quantity)
+	if pvc, err = r.Client.CoreV1().PersistentVolumeClaims(r.Namespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating PersistentVolumeClaim %w", err)
+	}
+	r.pvcName = pvc.GetName()
+
+	image := r.StorageImage
+	if image == "" {
+		image = DefaultStorageImage
+	}
+	reader := getReaderPodDefinition(r.configMapName, r.pvcName, r.Namespace, r.ServiceAccount, image)
+	r.addRunScheduling(&reader.Spec)
+	if reader, err = r.Client.CoreV1().Pods(r.Namespace).Create(ctx, reader, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating results reader pod %w", err)
+	}
+	r.readerPodName = reader.GetName()
+
+	return r.waitForReaderPod(ctx)
+}
+
+// getPVCDefinition returns a PersistentVolumeClaim definition that
+// test pods write their results to
+func getPVCDefinition(configMapName, namespace, storageClass string, size resource.Quantity) *v1.PersistentVolumeClaim {
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":     "scorecard-test",
+				"testrun": configMapName,
+			},
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+	// An unset storage class selects the cluster's default storage class.
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	return pvc
+}
+
+// getReaderPodDefinition returns a Pod definition that mounts the results
+// PersistentVolumeClaim and idles so results files can be read from it.
+func getReaderPodDefinition(configMapName, pvcName, namespace, serviceAccount, image string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-results", configMapName),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":     readerAppLabel,
+				"testrun": configMapName,
+			},
+		},
+		Spec: v1.PodSpec{
+			ServiceAccountName: serviceAccount,
+			RestartPolicy:      v1.RestartPolicyNever,
+			Containers: []v1.Container{
+				{
+					Name:            readerContainerName,
+					Image:           image,
+					ImagePullPolicy: v1.PullIfNotPresent,
+					// Run until the pod is deleted during cleanup.
+					Command: []string{"sh", "-c", "trap 'exit 0' TERM; while true; do sleep 1; done"},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							MountPath: PodResultsDir,
+							Name:      resultsVolumeName,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			Volumes: []v1.Volume{getResultsVolume(pvcName)},
+		},
+	}
+}
+
+func getResultsVolume(pvcName string) v1.Volume {
+	return v1.Volume{
+		Name: resultsVolumeName,
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+			},
+		},
+	}
+}
+
+// addResultsStorage mounts the results PersistentVolumeClaim into a test pod
+// at PodResultsDir, using a sub-directory named after the pod. The pod is
+// scheduled onto the reader pod's node, so a ReadWriteOnce claim can be
+// shared by all pods in the run.
+func addResultsStorage(pod *v1.Pod, pvcName, configMapName string) {
+	spec := &pod.Spec
+	spec.Volumes = append(spec.Volumes, getResultsVolume(pvcName))
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		c.VolumeMounts = append(c.VolumeMounts, v1.VolumeMount{
+			MountPath: PodResultsDir,
+			Name:      resultsVolumeName,
+			SubPath:   pod.GetName(),
+		})
+		c.Env = append(c.Env, v1.EnvVar{
+			Name:  ResultsFileEnv,
+			Value: path.Join(PodResultsDir, ResultsFileName),
+		})
+	}
+	// Merge with any affinity configured for the test.
+	if spec.Affinity == nil {
+		spec.Affinity = &v1.Affinity{}
+	}
+	if spec.Affinity.PodAffinity == nil {
+		spec.Affinity.PodAffinity = &v1.PodAffinity{}
+	}
+	podAffinity := spec.Affinity.PodAffinity
+	podAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(podAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+		v1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":     readerAppLabel,
+					"testrun": configMapName,
+				},
+			},
+			TopologyKey: "kubernetes.io/hostname",
+		})
+}
+
+// waitForReaderPod waits for the results reader pod to be running.
+func (r PodTestRunner) waitForReaderPod(ctx context.Context) error {
+	podCheck := wait.ConditionFunc(func() (done bool, err error) {
+		pod, err := r.Client.CoreV1().Pods(r.Namespace).Get(ctx, r.readerPodName, metav1.GetOptions{})
+		if err != nil {
+			return true, fmt.Errorf("error getting pod %s %w", r.readerPodName, err)
+		}
+		switch pod.Status.Phase {
+		case v1.PodRunning:
+			return true, nil
+		case v1.PodSucceeded, v1.PodFailed:
+			return true, fmt.Errorf("results reader pod %s exited with phase %s", r.readerPodName, pod.Status.Phase)
+		}
+		return false, nil
+	})
+
+	return wait.PollImmediateUntil(1*time.Second, podCheck, ctx.Done())
+}
+
+// getStoredResults reads a test pod's results file from the results
+// PersistentVolumeClaim via the reader pod.
+func (r PodTestRunner) getStoredResults(p *v1.Pod) ([]byte, error) {
+	resultsFile := path.Join(PodResultsDir, p.GetName(), ResultsFileName)
+	if r.RESTConfig == nil {
+		return nil, fmt.Errorf("a REST config is required to read results file %s", resultsFile)
+	}
+
+	req := r.Client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(r.Namespace).
+		Name(r.readerPodName).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: readerContainerName,
+			Command:   []string{"cat", resultsFile},
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(r.RESTConfig, "POST", req.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := exec.Stream(remotecommand.StreamOptions{Stdout: stdout, Stderr: stderr}); err != nil {
+		return nil, fmt.Errorf("error reading results file %s: %v: %s", resultsFile, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// deletePVC deletes the results PersistentVolumeClaim and is called
+// as part of the test run cleanup
+func (r PodTestRunner) deletePVC(ctx context.Context) error {
+	err := r.Client.CoreV1().PersistentVolumeClaims(r.Namespace).Delete(ctx, r.pvcName, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("error deleting PersistentVolumeClaim %s %w", r.pvcName, err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Test results storage", func() {
+	const (
+		namespace     = "test-ns"
+		configMapName = "scorecard-test-abcd"
+	)
+
+	Describe("getPVCDefinition", func() {
+		It("sets the storage class if one is provided", func() {
+			pvc := getPVCDefinition(configMapName, namespace, "standard", resource.MustParse("2Gi"))
+			Expect(pvc.GetName()).To(Equal(configMapName))
+			Expect(pvc.GetLabels()).To(HaveKeyWithValue("testrun", configMapName))
+			Expect(pvc.Spec.StorageClassName).NotTo(BeNil())
+			Expect(*pvc.Spec.StorageClassName).To(Equal("standard"))
+			Expect(pvc.Spec.Resources.Requests[v1.ResourceStorage]).To(Equal(resource.MustParse("2Gi")))
+		})
+		It("uses the default storage class if none is provided", func() {
+			pvc := getPVCDefinition(configMapName, namespace, "", resource.MustParse(DefaultStorageSize))
+			Expect(pvc.Spec.StorageClassName).To(BeNil())
+		})
+	})
+
+	Describe("getReaderPodDefinition", func() {
+		It("runs the given image with the claim mounted", func() {
+			pod := getReaderPodDefinition(configMapName, configMapName, namespace, "default", DefaultStorageImage)
+			Expect(pod.Spec.Containers).To(HaveLen(1))
+			Expect(pod.Spec.Containers[0].Image).To(Equal(DefaultStorageImage))
+			Expect(pod.Spec.Volumes).To(ContainElement(getResultsVolume(configMapName)))
+		})
+	})
+
+	Describe("addResultsStorage", func() {
+		It("mounts a per-pod sub-directory of the claim and sets the results file env", func() {
+			r := PodTestRunner{Namespace: namespace}
+			pod := getPodDefinition(configMapName, v1alpha3.TestConfiguration{Image: "test-image"}, r)
+			addResultsStorage(pod, configMapName, configMapName)
+
+			Expect(pod.Spec.Volumes).To(ContainElement(getResultsVolume(configMapName)))
+			container := pod.Spec.Containers[0]
+			Expect(container.VolumeMounts).To(ContainElement(v1.VolumeMount{
+				MountPath: PodResultsDir,
+				Name:      resultsVolumeName,
+				SubPath:   pod.GetName(),
+			}))
+			Expect(container.Env).To(ContainElement(v1.EnvVar{
+				Name:  ResultsFileEnv,
+				Value: PodResultsDir + "/" + ResultsFileName,
+			}))
+			Expect(pod.Spec.Affinity).NotTo(BeNil())
+			Expect(pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+		})
+	})
+
+	Describe("Cleanup", func() {
+		var (
+			ctx context.Context
+			r   PodTestRunner
+		)
+		BeforeEach(func() {
+			ctx = context.TODO()
+			cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: namespace}}
+			pvc := getPVCDefinition(configMapName, namespace, "", resource.MustParse(DefaultStorageSize))
+			r = PodTestRunner{
+				Namespace:     namespace,
+				Client:        fake.NewSimpleClientset(cm, pvc),
+				configMapName: configMapName,
+				pvcName:       configMapName,
+			}
+		})
+		It("deletes the results claim", func() {
+			Expect(r.Cleanup(ctx)).To(Succeed())
+			_, err := r.Client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+		It("keeps the results claim if KeepArtifacts is set", func() {
+			r.KeepArtifacts = true
+			Expect(r.Cleanup(ctx)).To(Succeed())
+			_, err := r.Client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Initialize", func() {
+		var (
+			ctx    context.Context
+			client *fake.Clientset
+			r      PodTestRunner
+		)
+		BeforeEach(func() {
+			ctx = context.TODO()
+			client = fake.NewSimpleClientset()
+			bundlePath := filepath.Join("testdata", "bundle")
+			metadata, _, err := registry.FindBundleMetadata(bundlePath)
+			Expect(err).NotTo(HaveOccurred())
+			r = PodTestRunner{
+				Namespace:      namespace,
+				Client:         client,
+				BundlePath:     bundlePath,
+				BundleMetadata: metadata,
+				UseStorage:     true,
+			}
+		})
+		It("deletes the ConfigMap, reader pod, and results claim if storage fails to initialize", func() {
+			client.PrependReactor("create", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("pods are forbidden")
+			})
+			Expect(r.Initialize(ctx)).To(MatchError(ContainSubstring("pods are forbidden")))
+
+			cms, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cms.Items).To(BeEmpty())
+			pvcs, err := client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pvcs.Items).To(BeEmpty())
+
+			deletedPods := false
+			for _, a := range client.Actions() {
+				if a.Matches("delete-collection", "pods") {
+					deletedPods = true
+				}
+			}
+			Expect(deletedPods).To(BeTrue())
+		})
+		It("reports cleanup errors along with the initialization error", func() {
+			client.PrependReactor("create", "persistentvolumeclaims", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("quota exceeded")
+			})
+			client.PrependReactor("delete", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("connection refused")
+			})
+			err := r.Initialize(ctx)
+			Expect(err).To(MatchError(ContainSubstring("quota exceeded")))
+			Expect(err).To(MatchError(ContainSubstring("cleanup failed: ")))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+const (
+	// ResultsWebhookAuthEnv is the environment variable holding the value of
+	// the Authorization header sent to a results webhook, if not set by flag.
+	ResultsWebhookAuthEnv = "SCORECARD_RESULTS_WEBHOOK_AUTH"
+
+	// Defaults for ResultsWebhook retries.
+	DefaultWebhookAttempts      = 3
+	DefaultWebhookRetryInterval = 2 * time.Second
+)
+
+// ResultsPayload is the JSON body a ResultsWebhook posts.
+type ResultsPayload struct {
+	// Bundle is the bundle image or directory that was tested.
+	Bundle string `json:"bundle"`
+	// Package is the bundle's package name, if its metadata sets one.
+	Package string `json:"package,omitempty"`
+	// Results are the test results.
+	Results v1alpha3.TestList `json:"results"`
+}
+
+// ResultsWebhook posts scorecard results to an HTTP endpoint.
+type ResultsWebhook struct {
+	// URL is the endpoint results are posted to.
+	URL string
+	// Authorization is the value of the Authorization header, if not empty.
+	Authorization string
+	// Client sends requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Attempts is the maximum number of times a post is sent. Defaults to
+	// DefaultWebhookAttempts.
+	Attempts int
+	// RetryInterval is the delay before the first retry, doubled before each
+	// subsequent retry. Defaults to DefaultWebhookRetryInterval.
+	RetryInterval time.Duration
+}
+
+// Post sends payload to w.URL, retrying on connection errors and on 429 and
+// 5xx responses until w.Attempts posts were sent or ctx is done.
+func (w ResultsWebhook) Post(ctx context.Context, payload ResultsPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling results: %v", err)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	attempts := w.Attempts
+	if attempts <= 0 {
+		attempts = DefaultWebhookAttempts
+	}
+	interval := w.RetryInterval
+	if interval <= 0 {
+		interval = DefaultWebhookRetryInterval
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, client, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= attempts {
+			return fmt.Errorf("error posting results to %s: %w", w.URL, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error posting results to %s: %w", w.URL, err)
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
+// post sends body to w.URL once, and returns whether a failure is transient.
+func (w ResultsWebhook) post(ctx context.Context, client *http.Client, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if w.Authorization != "" {
+		req.Header.Set("Authorization", w.Authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected response status %q", resp.Status)
+	default:
+		return false, fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+}
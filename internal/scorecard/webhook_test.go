@@ -0,0 +1,105 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/operator-framework/operator-sdk/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Posting results to a webhook", func() {
+	var (
+		payload ResultsPayload
+		calls   int32
+	)
+
+	BeforeEach(func() {
+		calls = 0
+		payload = ResultsPayload{
+			Bundle:  "quay.io/example/memcached-operator-bundle:v0.0.1",
+			Package: "memcached-operator",
+			Results: v1alpha3.NewTestList(),
+		}
+		test := v1alpha3.NewTest()
+		test.Spec.Image = "quay.io/operator-framework/scorecard-test:dev"
+		test.Status.Results = []v1alpha3.TestResult{{Name: "basic-check-spec", State: v1alpha3.PassState}}
+		payload.Results.Items = append(payload.Results.Items, test)
+	})
+
+	newServer := func(statuses ...int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&calls, 1)
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+			var got ResultsPayload
+			Expect(json.NewDecoder(r.Body).Decode(&got)).To(Succeed())
+			Expect(got.Bundle).To(Equal(payload.Bundle))
+			Expect(got.Package).To(Equal(payload.Package))
+			Expect(got.Results.Items).To(HaveLen(1))
+			Expect(got.Results.Items[0].Status.Results[0].Name).To(Equal("basic-check-spec"))
+			rw.WriteHeader(statuses[int(n-1)%len(statuses)])
+		}))
+	}
+
+	newWebhook := func(url string) ResultsWebhook {
+		return ResultsWebhook{
+			URL:           url,
+			Authorization: "Bearer token",
+			Attempts:      3,
+			RetryInterval: time.Millisecond,
+		}
+	}
+
+	It("posts results with the bundle identifier", func() {
+		srv := newServer(http.StatusOK)
+		defer srv.Close()
+		Expect(newWebhook(srv.URL).Post(context.TODO(), payload)).To(Succeed())
+		Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(1))
+	})
+
+	It("retries transient failures", func() {
+		srv := newServer(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusAccepted)
+		defer srv.Close()
+		Expect(newWebhook(srv.URL).Post(context.TODO(), payload)).To(Succeed())
+		Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(3))
+	})
+
+	It("fails after all attempts fail", func() {
+		srv := newServer(http.StatusBadGateway)
+		defer srv.Close()
+		err := newWebhook(srv.URL).Post(context.TODO(), payload)
+		Expect(err).To(MatchError(ContainSubstring("502 Bad Gateway")))
+		Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(3))
+	})
+
+	It("does not retry client errors", func() {
+		srv := newServer(http.StatusUnauthorized)
+		defer srv.Close()
+		err := newWebhook(srv.URL).Post(context.TODO(), payload)
+		Expect(err).To(MatchError(ContainSubstring("401 Unauthorized")))
+		Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(1))
+	})
+})
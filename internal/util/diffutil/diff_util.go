@@ -16,6 +16,7 @@ package diffutil
 
 import (
 	"bytes"
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -58,3 +59,101 @@ func prefixLines(s, prefix string) string {
 	}
 	return buf.String()
 }
+
+// unifiedContext is the number of unchanged lines UnifiedDiff prints around each change.
+const unifiedContext = 3
+
+// lineOp is a line of a line diff, with kind ' ', '-', or '+' if it is unchanged, deleted, or inserted.
+type lineOp struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns a unified diff, with three lines of context, of a named fromName and b named toName,
+// or an empty string if they are equal.
+func UnifiedDiff(fromName, toName, a, b string) string {
+	ops := lineOps(a, b)
+
+	var buf bytes.Buffer
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		// Extend the hunk over changes separated by at most twice the context of unchanged lines.
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*unifiedContext {
+				break
+			}
+		}
+		start, stop := i-unifiedContext, end+unifiedContext
+		if start < 0 {
+			start = 0
+		}
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		aStart, aCount := countLines(ops[:start], '-'), countLines(ops[start:stop], '-')
+		bStart, bCount := countLines(ops[:start], '+'), countLines(ops[start:stop], '+')
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, op := range ops[start:stop] {
+			buf.WriteByte(op.kind)
+			buf.WriteString(op.text)
+			if !strings.HasSuffix(op.text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return buf.String()
+}
+
+// lineOps returns the line diff of a and b.
+func lineOps(a, b string) (ops []lineOp) {
+	dmp := diffmatchpatch.New()
+	wSrc, wDst, warray := dmp.DiffLinesToRunes(a, b)
+	diffs := dmp.DiffMainRunes(wSrc, wDst, false)
+	diffs = dmp.DiffCharsToLines(diffs, warray)
+	for _, diff := range diffs {
+		kind := byte(' ')
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			kind = '+'
+		case diffmatchpatch.DiffDelete:
+			kind = '-'
+		}
+		for _, line := range strings.SplitAfter(diff.Text, "\n") {
+			if line != "" {
+				ops = append(ops, lineOp{kind: kind, text: line})
+			}
+		}
+	}
+	return ops
+}
+
+// countLines returns the number of lines in ops in the file whose changed lines have kind.
+func countLines(ops []lineOp, kind byte) (n int) {
+	for _, op := range ops {
+		if op.kind == ' ' || op.kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// hunkRange formats the range of count lines after line number start of a hunk header.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diffutil
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	b := "1\n2\ntwo\n3\n4\n5\n6\n7\n8\n9\n10\n11\n13\n14\n15"
+	want := `--- a/f.yaml
++++ b/f.yaml
+@@ -1,5 +1,6 @@
+ 1
+ 2
++two
+ 3
+ 4
+ 5
+@@ -9,7 +10,6 @@
+ 9
+ 10
+ 11
+-12
+ 13
+ 14
+-15
++15
+\ No newline at end of file
+`
+	if diff := UnifiedDiff("a/f.yaml", "b/f.yaml", a, b); diff != want {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+
+	if diff := UnifiedDiff("/dev/null", "b/f.yaml", "", "1\n"); diff != "--- /dev/null\n+++ b/f.yaml\n@@ -0,0 +1,1 @@\n+1\n" {
+		t.Errorf("Unexpected diff of a new file:\n%s", diff)
+	}
+	if diff := UnifiedDiff("a/f.yaml", "b/f.yaml", a, a); diff != "" {
+		t.Errorf("Wanted no diff of equal contents, got:\n%s", diff)
+	}
+}
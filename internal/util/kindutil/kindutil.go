@@ -0,0 +1,111 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kindutil creates and deletes local kind clusters.
+package kindutil
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const (
+	// kindBin is the kind executable invoked to manage clusters.
+	// See https://kind.sigs.k8s.io
+	kindBin = "kind"
+	// nodeImageRepo is the repository of kind's node images, tagged by Kubernetes version.
+	nodeImageRepo = "kindest/node"
+
+	// DefaultClusterName is the name of clusters created when no name is given.
+	DefaultClusterName = "operator-sdk"
+	// DefaultWait is how long kind waits for a created cluster's control plane to be ready.
+	DefaultWait = "5m"
+)
+
+// CreateOptions configures how a cluster is created.
+type CreateOptions struct {
+	// KubernetesVersion is the Kubernetes version of the cluster's nodes, ex. "v1.19.1".
+	// If empty, kind's default node image is used.
+	KubernetesVersion string
+	// Wait is how long to wait for the cluster's control plane to be ready, ex. "5m".
+	// If empty, DefaultWait is used.
+	Wait string
+}
+
+// CreateCluster creates a kind cluster named name, and sets the current context
+// of the user's kubeconfig to the cluster's context.
+func CreateCluster(name string, opts CreateOptions) error {
+	if err := checkKind(); err != nil {
+		return err
+	}
+	return projutil.ExecCmd(createClusterCommand(name, opts))
+}
+
+// DeleteCluster deletes the kind cluster named name and removes its context
+// from the user's kubeconfig.
+func DeleteCluster(name string) error {
+	if err := checkKind(); err != nil {
+		return err
+	}
+	return projutil.ExecCmd(exec.Command(kindBin, "delete", "cluster", "--name", name))
+}
+
+// KubeContext returns the name of the kubeconfig context kind configures
+// for the cluster named name.
+func KubeContext(name string) string {
+	return "kind-" + name
+}
+
+// RESTConfig returns a REST config for the cluster named name, read from
+// its context in the user's kubeconfig.
+func RESTConfig(name string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: KubeContext(name)}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
+
+func checkKind() error {
+	if _, err := exec.LookPath(kindBin); err != nil {
+		return fmt.Errorf("%s must be installed to manage clusters: %v", kindBin, err)
+	}
+	return nil
+}
+
+// createClusterCommand returns a command that creates a cluster named name as configured by opts.
+func createClusterCommand(name string, opts CreateOptions) *exec.Cmd {
+	wait := opts.Wait
+	if wait == "" {
+		wait = DefaultWait
+	}
+	args := []string{"create", "cluster", "--name", name, "--wait", wait}
+	if opts.KubernetesVersion != "" {
+		args = append(args, "--image", nodeImage(opts.KubernetesVersion))
+	}
+	return exec.Command(kindBin, args...)
+}
+
+// nodeImage returns the kind node image for Kubernetes version, which may
+// omit its leading "v".
+func nodeImage(version string) string {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return fmt.Sprintf("%s:%s", nodeImageRepo, version)
+}
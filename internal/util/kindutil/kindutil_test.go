@@ -0,0 +1,50 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kindutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateClusterCommand(t *testing.T) {
+	cases := []struct {
+		name         string
+		opts         CreateOptions
+		expectedArgs []string
+	}{
+		{"defaults", CreateOptions{},
+			[]string{"kind", "create", "cluster", "--name", "test", "--wait", DefaultWait}},
+		{"version", CreateOptions{KubernetesVersion: "v1.19.1", Wait: "1m"},
+			[]string{"kind", "create", "cluster", "--name", "test", "--wait", "1m", "--image", "kindest/node:v1.19.1"}},
+		{"version without v", CreateOptions{KubernetesVersion: "1.18.8"},
+			[]string{"kind", "create", "cluster", "--name", "test", "--wait", DefaultWait, "--image", "kindest/node:v1.18.8"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := createClusterCommand("test", c.opts)
+			if !reflect.DeepEqual(cmd.Args, c.expectedArgs) {
+				t.Errorf("Wanted args %v, got: %v", c.expectedArgs, cmd.Args)
+			}
+		})
+	}
+}
+
+func TestKubeContext(t *testing.T) {
+	if ctx := KubeContext("test"); ctx != "kind-test" {
+		t.Errorf("Wanted context kind-test, got: %s", ctx)
+	}
+}
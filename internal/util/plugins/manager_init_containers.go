@@ -0,0 +1,139 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// TODO: rewrite this when plugins phase 2 is implemented.
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// ManagerInitContainersPatchFile is the name of the kustomize patch that adds
+// init containers to the manager Deployment's pods.
+const ManagerInitContainersPatchFile = "manager_init_containers_patch.yaml"
+
+// ManagerInitContainersUsage is the usage text of init's --manager-init-containers flag.
+const ManagerInitContainersUsage = "path to a YAML file containing a list of init containers, ex. to run migrations, " +
+	"added to the manager Deployment's pods in addition to those in config/manager/manager.yaml"
+
+// managerContainerNames are the names of containers scaffolded in the manager
+// Deployment's pods, which init containers cannot share.
+var managerContainerNames = map[string]bool{"manager": true, "kube-rbac-proxy": true}
+
+// ManagerInitContainers is a list of init containers to add to the manager Deployment's pods.
+type ManagerInitContainers struct {
+	// Containers are the parsed init containers.
+	Containers []corev1.Container
+	// raw are the init containers as written, so the patch only sets fields set in the file.
+	raw []interface{}
+}
+
+// ReadManagerInitContainers reads a YAML list of init containers from path.
+// Each container must have a unique name that is not used by a manager container, and an image.
+func ReadManagerInitContainers(path string) (ics ManagerInitContainers, err error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ics, err
+	}
+	if err := yaml.UnmarshalStrict(b, &ics.Containers); err != nil {
+		return ics, fmt.Errorf("error parsing init containers: %v", err)
+	}
+	if err := yaml.Unmarshal(b, &ics.raw); err != nil {
+		return ics, fmt.Errorf("error parsing init containers: %v", err)
+	}
+	if len(ics.Containers) == 0 {
+		return ics, errors.New("no init containers set")
+	}
+	names := map[string]bool{}
+	for _, c := range ics.Containers {
+		if errs := validation.IsDNS1123Label(c.Name); len(errs) != 0 {
+			return ics, fmt.Errorf("init container name %q is invalid: %s", c.Name, strings.Join(errs, ", "))
+		}
+		if names[c.Name] {
+			return ics, fmt.Errorf("init container name %q is duplicated", c.Name)
+		}
+		if managerContainerNames[c.Name] {
+			return ics, fmt.Errorf("init container name %q is used by a manager container", c.Name)
+		}
+		if c.Image == "" {
+			return ics, fmt.Errorf("init container %q must have an image", c.Name)
+		}
+		names[c.Name] = true
+	}
+	return ics, nil
+}
+
+// managerInitContainersPatchHeader is the start of the manager init containers patch.
+const managerInitContainersPatchHeader = `# This patch adds init containers to the manager Deployment's pods.
+# Init containers are merged by name with those in config/manager/manager.yaml;
+# fields set here take precedence for init containers with the same name.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  template:
+    spec:
+      initContainers:
+`
+
+// AddManagerInitContainersPatch writes a patch adding init containers to the manager
+// Deployment's pods to config/default, and adds that patch to config/default/kustomization.yaml.
+func AddManagerInitContainersPatch(ics ManagerInitContainers) error {
+	dir := filepath.Join("config", "default")
+	kpath := filepath.Join(dir, "kustomization.yaml")
+	b, err := ioutil.ReadFile(kpath)
+	if err != nil {
+		return err
+	}
+	kustomization, err := addManagerPatchEntry(string(b), ManagerInitContainersPatchFile)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %v", kpath, err)
+	}
+
+	patch, err := makeManagerInitContainersPatch(ics)
+	if err != nil {
+		return err
+	}
+	patchPath := filepath.Join(dir, ManagerInitContainersPatchFile)
+	if err := ioutil.WriteFile(patchPath, []byte(patch), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(kpath, []byte(kustomization), 0644)
+}
+
+// makeManagerInitContainersPatch returns a strategic merge patch adding init containers
+// to the manager Deployment's pod template.
+func makeManagerInitContainersPatch(ics ManagerInitContainers) (string, error) {
+	b, err := yaml.Marshal(ics.raw)
+	if err != nil {
+		return "", err
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(managerInitContainersPatchHeader)
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n") {
+		sb.WriteString("      " + line)
+	}
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+func writeInitContainers(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "init-containers.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadManagerInitContainers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "init-containers-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ics, err := ReadManagerInitContainers(writeInitContainers(t, dir, `- name: migrate
+  image: quay.io/example/migrate:v0.1.0
+  args: ["--up"]
+- name: wait-for-db
+  image: busybox:1.32
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ics.Containers) != 2 || ics.Containers[0].Name != "migrate" || ics.Containers[1].Image != "busybox:1.32" {
+		t.Errorf("Unexpected init containers %+v", ics.Containers)
+	}
+
+	for _, content := range []string{
+		"[]",
+		"- name: migrate\n",
+		"- name: Migrate\n  image: busybox\n",
+		"- name: manager\n  image: busybox\n",
+		"- name: migrate\n  image: busybox\n- name: migrate\n  image: busybox\n",
+		"- name: migrate\n  image: busybox\n  imagee: typo\n",
+	} {
+		if _, err := ReadManagerInitContainers(writeInitContainers(t, dir, content)); err == nil {
+			t.Errorf("Wanted error for %q, got none", content)
+		}
+	}
+}
+
+func TestMakeManagerInitContainersPatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "init-containers-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ics, err := ReadManagerInitContainers(writeInitContainers(t, dir, `- name: migrate
+  image: quay.io/example/migrate:v0.1.0
+  args: ["--up"]
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := managerInitContainersPatchHeader + `      - args:
+        - --up
+        image: quay.io/example/migrate:v0.1.0
+        name: migrate
+`
+	patch, err := makeManagerInitContainersPatch(ics)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if patch != want {
+		t.Errorf("Unexpected patch:\n%s", patch)
+	}
+}
+
+func TestManagerInitContainersPatchMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "init-containers-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ics, err := ReadManagerInitContainers(writeInitContainers(t, dir, `- name: migrate
+  image: quay.io/example/migrate:v0.2.0
+- name: wait-for-db
+  image: busybox:1.32
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	patch, err := makeManagerInitContainersPatch(ics)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Apply the patch as kustomize would to a Deployment that already has an init container
+	// of the same name, which is merged rather than replaced.
+	dep := appsv1.Deployment{}
+	dep.Spec.Template.Spec.InitContainers = []corev1.Container{
+		{Name: "migrate", Image: "quay.io/example/migrate:v0.1.0", Args: []string{"--up"}},
+	}
+	depJSON, err := yaml.Marshal(dep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depJSON, err = yaml.YAMLToJSON(depJSON); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := strategicpatch.StrategicMergePatch(depJSON, patchJSON, appsv1.Deployment{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got := appsv1.Deployment{}
+	if err := yaml.Unmarshal(merged, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	initContainers := got.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 2 {
+		t.Fatalf("Unexpected init containers %+v", initContainers)
+	}
+	byName := map[string]corev1.Container{}
+	for _, c := range initContainers {
+		byName[c.Name] = c
+	}
+	if c := byName["migrate"]; c.Image != "quay.io/example/migrate:v0.2.0" || len(c.Args) != 1 {
+		t.Errorf("Unexpected merged init container %+v", c)
+	}
+	if c := byName["wait-for-db"]; c.Image != "busybox:1.32" {
+		t.Errorf("Unexpected added init container %+v", c)
+	}
+}
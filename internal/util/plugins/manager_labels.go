@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// TODO: rewrite this when plugins phase 2 is implemented.
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ManagerLabelsPatchFile is the name of the kustomize patch that adds labels
+// to the manager Deployment and its pods.
+const ManagerLabelsPatchFile = "manager_labels_patch.yaml"
+
+// ManagerLabelsUsage is the usage text of init's --manager-labels flag.
+const ManagerLabelsUsage = "comma-separated labels, ex. 'team=storage,cost-center=1234', added to the manager " +
+	"Deployment and its pods in addition to those in config/manager/manager.yaml"
+
+// managerSelectorLabel is the label init scaffolds on the manager Deployment and
+// its pods, and that the Deployment selects its pods by.
+const managerSelectorLabel = "control-plane"
+
+// ParseManagerLabels parses a comma-separated list of <key>=<value> pairs,
+// ex. "team=storage,cost-center=1234", into labels. Keys of labels managed
+// by the scaffolded manager Deployment cannot be set.
+func ParseManagerLabels(s string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("label %q must have the format <key>=<value>", pair)
+		}
+		key, value := kv[0], kv[1]
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return nil, fmt.Errorf("label %q key is invalid: %s", pair, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return nil, fmt.Errorf("label %q value is invalid: %s", pair, strings.Join(errs, ", "))
+		}
+		if key == managerSelectorLabel {
+			return nil, fmt.Errorf("label %q cannot be set, since the manager Deployment selects its pods by it", key)
+		}
+		labels[key] = value
+	}
+	if len(labels) == 0 {
+		return nil, errors.New("no labels set")
+	}
+	return labels, nil
+}
+
+// managerLabelsPatchHeader is the start of the manager labels patch.
+const managerLabelsPatchHeader = `# This patch adds labels to the manager Deployment and its pods,
+# in addition to those in config/manager/manager.yaml.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+`
+
+// AddManagerLabelsPatch writes a patch adding labels to the manager Deployment
+// and its pods to config/default, and adds that patch to config/default/kustomization.yaml.
+func AddManagerLabelsPatch(labels map[string]string) error {
+	dir := filepath.Join("config", "default")
+	kpath := filepath.Join(dir, "kustomization.yaml")
+	b, err := ioutil.ReadFile(kpath)
+	if err != nil {
+		return err
+	}
+	kustomization, err := addManagerPatchEntry(string(b), ManagerLabelsPatchFile)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %v", kpath, err)
+	}
+
+	patchPath := filepath.Join(dir, ManagerLabelsPatchFile)
+	if err := ioutil.WriteFile(patchPath, []byte(makeManagerLabelsPatch(labels)), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(kpath, []byte(kustomization), 0644)
+}
+
+// makeManagerLabelsPatch returns a strategic merge patch adding labels to the
+// manager Deployment and its pod template. Labels in the base manifest are kept.
+func makeManagerLabelsPatch(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	writeLabels := func(sb *strings.Builder, indent string) {
+		fmt.Fprintf(sb, "%slabels:\n", indent)
+		for _, key := range keys {
+			fmt.Fprintf(sb, "%s  %s: %q\n", indent, key, labels[key])
+		}
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(managerLabelsPatchHeader)
+	writeLabels(sb, "  ")
+	sb.WriteString("spec:\n  template:\n    metadata:\n")
+	writeLabels(sb, "      ")
+	return sb.String()
+}
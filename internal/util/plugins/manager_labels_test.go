@@ -0,0 +1,61 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"testing"
+)
+
+func TestParseManagerLabels(t *testing.T) {
+	labels, err := ParseManagerLabels("team=storage, cost-center=1234,example.com/tier=")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]string{"team": "storage", "cost-center": "1234", "example.com/tier": ""}
+	if len(labels) != len(want) {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+	for k, v := range want {
+		if got, ok := labels[k]; !ok || got != v {
+			t.Errorf("Unexpected label %s value %q", k, got)
+		}
+	}
+
+	for _, s := range []string{"", "team", "=storage", "team=bad value", "-team=storage", "control-plane=manager"} {
+		if _, err := ParseManagerLabels(s); err == nil {
+			t.Errorf("Wanted error for %q, got none", s)
+		}
+	}
+}
+
+func TestMakeManagerLabelsPatch(t *testing.T) {
+	labels, err := ParseManagerLabels("team=storage,cost-center=1234")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := managerLabelsPatchHeader + `  labels:
+    cost-center: "1234"
+    team: "storage"
+spec:
+  template:
+    metadata:
+      labels:
+        cost-center: "1234"
+        team: "storage"
+`
+	if patch := makeManagerLabelsPatch(labels); patch != want {
+		t.Errorf("Unexpected patch:\n%s", patch)
+	}
+}
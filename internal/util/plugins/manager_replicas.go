@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// TODO: rewrite this when plugins phase 2 is implemented.
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ManagerReplicasPatchFile is the name of the kustomize patch that sets
+// the manager Deployment's replica count.
+const ManagerReplicasPatchFile = "manager_replicas_patch.yaml"
+
+// ManagerReplicasUsage is the usage text of init's --manager-replicas flag.
+const ManagerReplicasUsage = "positive number of manager Deployment replicas, ex. 3 for high availability, that " +
+	"overrides the single replica in config/manager/manager.yaml. Multiple replicas require leader election"
+
+// leaderElectionFlag is the manager flag that enables leader election.
+const leaderElectionFlag = "--enable-leader-election"
+
+// ParseManagerReplicas parses a positive manager Deployment replica count.
+func ParseManagerReplicas(s string) (int32, error) {
+	replicas, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("replicas %q must be an integer", s)
+	}
+	if replicas < 1 {
+		return 0, fmt.Errorf("replicas %d must be positive", replicas)
+	}
+	return int32(replicas), nil
+}
+
+// managerReplicasPatchHeader is the start of the manager replicas patch.
+const managerReplicasPatchHeader = `# This patch sets the number of manager Deployment replicas, overriding
+# the replica count in config/manager/manager.yaml. Multiple replicas require
+# leader election, which the manager's --enable-leader-election flag enables.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+`
+
+// AddManagerReplicasPatch writes a patch setting the manager Deployment's replica count
+// to config/default, and adds that patch to config/default/kustomization.yaml.
+// A warning is logged if replicas is greater than one but the scaffolded manager
+// args do not enable leader election.
+func AddManagerReplicasPatch(replicas int32) error {
+	dir := filepath.Join("config", "default")
+	kpath := filepath.Join(dir, "kustomization.yaml")
+	b, err := ioutil.ReadFile(kpath)
+	if err != nil {
+		return err
+	}
+	kustomization, err := addManagerPatchEntry(string(b), ManagerReplicasPatchFile)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %v", kpath, err)
+	}
+
+	patchPath := filepath.Join(dir, ManagerReplicasPatchFile)
+	if err := ioutil.WriteFile(patchPath, []byte(makeManagerReplicasPatch(replicas)), 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(kpath, []byte(kustomization), 0644); err != nil {
+		return err
+	}
+
+	if replicas > 1 && !hasLeaderElection(
+		filepath.Join("config", "manager", "manager.yaml"),
+		filepath.Join(dir, "manager_auth_proxy_patch.yaml"),
+	) {
+		log.Warnf("The manager Deployment has %d replicas but leader election is not enabled, so every replica "+
+			"will reconcile the same objects. Add %q to the manager container's args", replicas, leaderElectionFlag)
+	}
+	return nil
+}
+
+// makeManagerReplicasPatch returns a strategic merge patch setting the
+// manager Deployment's replica count.
+func makeManagerReplicasPatch(replicas int32) string {
+	return fmt.Sprintf("%sspec:\n  replicas: %d\n", managerReplicasPatchHeader, replicas)
+}
+
+// hasLeaderElection returns true if any of the manager manifest files enables leader election.
+func hasLeaderElection(paths ...string) bool {
+	for _, path := range paths {
+		if b, err := ioutil.ReadFile(path); err == nil && strings.Contains(string(b), leaderElectionFlag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseManagerReplicas(t *testing.T) {
+	replicas, err := ParseManagerReplicas(" 3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if replicas != 3 {
+		t.Errorf("Unexpected replicas %d", replicas)
+	}
+
+	for _, s := range []string{"", "0", "-1", "two", "1.5", "4294967296"} {
+		if _, err := ParseManagerReplicas(s); err == nil {
+			t.Errorf("Wanted error for %q, got none", s)
+		}
+	}
+}
+
+func TestMakeManagerReplicasPatch(t *testing.T) {
+	want := managerReplicasPatchHeader + `spec:
+  replicas: 3
+`
+	if patch := makeManagerReplicasPatch(3); patch != want {
+		t.Errorf("Unexpected patch:\n%s", patch)
+	}
+}
+
+func TestHasLeaderElection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manager-replicas-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	enabled, disabled := filepath.Join(dir, "enabled.yaml"), filepath.Join(dir, "disabled.yaml")
+	if err := ioutil.WriteFile(enabled, []byte("args:\n- --enable-leader-election\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(disabled, []byte("args:\n- --metrics-addr=:8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !hasLeaderElection(disabled, enabled) {
+		t.Error("Wanted leader election to be enabled")
+	}
+	if hasLeaderElection(disabled, filepath.Join(dir, "missing.yaml")) {
+		t.Error("Wanted leader election to be disabled")
+	}
+}
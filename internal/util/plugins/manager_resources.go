@@ -0,0 +1,157 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// TODO: rewrite this when plugins phase 2 is implemented.
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ManagerResourcesPatchFile is the name of the kustomize patch that sets
+// the manager container's resource requests and limits.
+const ManagerResourcesPatchFile = "manager_resources_patch.yaml"
+
+// ManagerResourcesUsage is the usage text of init's --manager-resources flag.
+const ManagerResourcesUsage = "comma-separated resource requests and limits of the manager container, " +
+	"ex. 'limits.cpu=200m,limits.memory=128Mi,requests.memory=64Mi', that override those in config/manager/manager.yaml"
+
+// ParseManagerResources parses a comma-separated list of <type>.<resource>=<quantity>
+// pairs, ex. "limits.cpu=200m,requests.memory=64Mi", where type is one of
+// "limits" or "requests", into resource requirements.
+func ParseManagerResources(s string) (reqs corev1.ResourceRequirements, err error) {
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		key := strings.SplitN(kv[0], ".", 2)
+		if len(kv) != 2 || len(key) != 2 || key[1] == "" {
+			return reqs, fmt.Errorf("resource %q must have the format <type>.<resource>=<quantity>", pair)
+		}
+		quantity, err := resource.ParseQuantity(kv[1])
+		if err != nil {
+			return reqs, fmt.Errorf("error parsing resource %q quantity: %v", pair, err)
+		}
+		var list *corev1.ResourceList
+		switch key[0] {
+		case "limits":
+			list = &reqs.Limits
+		case "requests":
+			list = &reqs.Requests
+		default:
+			return reqs, fmt.Errorf("resource %q type must be one of \"limits\" or \"requests\"", pair)
+		}
+		if *list == nil {
+			*list = corev1.ResourceList{}
+		}
+		(*list)[corev1.ResourceName(key[1])] = quantity
+	}
+	if len(reqs.Limits) == 0 && len(reqs.Requests) == 0 {
+		return reqs, errors.New("no resources set")
+	}
+	return reqs, nil
+}
+
+// managerResourcesPatchHeader is the start of the manager resources patch,
+// to which each resource list is appended.
+const managerResourcesPatchHeader = `# This patch sets resource requests and limits on the manager container,
+# overriding those in config/manager/manager.yaml.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        resources:
+`
+
+// managerPatchesFragment is the last patch listed in the default kustomization
+// scaffolded by init, after which manager patches added by init flags are listed.
+const managerPatchesFragment = "- manager_auth_proxy_patch.yaml\n"
+
+// AddManagerResourcesPatch writes a patch setting reqs on the manager container
+// to config/default, and adds that patch to config/default/kustomization.yaml.
+func AddManagerResourcesPatch(reqs corev1.ResourceRequirements) error {
+	dir := filepath.Join("config", "default")
+	kpath := filepath.Join(dir, "kustomization.yaml")
+	b, err := ioutil.ReadFile(kpath)
+	if err != nil {
+		return err
+	}
+	kustomization, err := addManagerPatchEntry(string(b), ManagerResourcesPatchFile)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %v", kpath, err)
+	}
+
+	patchPath := filepath.Join(dir, ManagerResourcesPatchFile)
+	if err := ioutil.WriteFile(patchPath, []byte(makeManagerResourcesPatch(reqs)), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(kpath, []byte(kustomization), 0644)
+}
+
+// makeManagerResourcesPatch returns a strategic merge patch setting reqs on the
+// manager container. Resources not in reqs keep their values in the base manifest.
+func makeManagerResourcesPatch(reqs corev1.ResourceRequirements) string {
+	sb := &strings.Builder{}
+	sb.WriteString(managerResourcesPatchHeader)
+	for _, l := range []struct {
+		name string
+		list corev1.ResourceList
+	}{
+		{"limits", reqs.Limits},
+		{"requests", reqs.Requests},
+	} {
+		if len(l.list) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(l.list))
+		for name := range l.list {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		fmt.Fprintf(sb, "          %s:\n", l.name)
+		for _, name := range names {
+			quantity := l.list[corev1.ResourceName(name)]
+			fmt.Fprintf(sb, "            %s: %s\n", name, quantity.String())
+		}
+	}
+	return sb.String()
+}
+
+// addManagerPatchEntry returns kustomization with the manager patch
+// patchFile listed in its patchesStrategicMerge.
+func addManagerPatchEntry(kustomization, patchFile string) (string, error) {
+	entry := fmt.Sprintf("- %s\n", patchFile)
+	if strings.Contains(kustomization, entry) {
+		return kustomization, nil
+	}
+	if !strings.Contains(kustomization, managerPatchesFragment) {
+		return "", errors.New("manager patches not found")
+	}
+	return strings.Replace(kustomization, managerPatchesFragment, managerPatchesFragment+entry, 1), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseManagerResources(t *testing.T) {
+	reqs, err := ParseManagerResources("limits.cpu=200m, limits.memory=128Mi,requests.memory=64Mi")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if q := reqs.Limits.Cpu(); q.String() != "200m" {
+		t.Errorf("Unexpected CPU limit %s", q)
+	}
+	if q := reqs.Limits.Memory(); q.String() != "128Mi" {
+		t.Errorf("Unexpected memory limit %s", q)
+	}
+	if q := reqs.Requests.Memory(); q.String() != "64Mi" {
+		t.Errorf("Unexpected memory request %s", q)
+	}
+	if _, ok := reqs.Requests["cpu"]; ok {
+		t.Error("Unexpected CPU request")
+	}
+
+	for _, s := range []string{"", "cpu=200m", "limits.cpu", "limits.=200m", "limit.cpu=200m", "limits.cpu=foo"} {
+		if _, err := ParseManagerResources(s); err == nil {
+			t.Errorf("Wanted error for %q, got none", s)
+		}
+	}
+}
+
+func TestMakeManagerResourcesPatch(t *testing.T) {
+	reqs, err := ParseManagerResources("requests.memory=64Mi,limits.memory=128Mi,limits.cpu=200m")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := managerResourcesPatchHeader + `          limits:
+            cpu: 200m
+            memory: 128Mi
+          requests:
+            memory: 64Mi
+`
+	if patch := makeManagerResourcesPatch(reqs); patch != want {
+		t.Errorf("Unexpected patch:\n%s", patch)
+	}
+}
+
+const testKustomization = `patchesStrategicMerge:
+  # Protect the /metrics endpoint by putting it behind auth.
+  # If you want your controller-manager to expose the /metrics
+  # endpoint w/o any authn/z, please comment the following line.
+- manager_auth_proxy_patch.yaml
+`
+
+func TestAddManagerPatchEntry(t *testing.T) {
+	out, err := addManagerPatchEntry(testKustomization, ManagerResourcesPatchFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(out, "- manager_auth_proxy_patch.yaml\n- manager_resources_patch.yaml\n") {
+		t.Errorf("Updated kustomization does not list the patch:\n%s", out)
+	}
+	if again, err := addManagerPatchEntry(out, ManagerResourcesPatchFile); err != nil || again != out {
+		t.Errorf("Adding a listed patch changed the kustomization: %v\n%s", err, again)
+	}
+
+	if _, err := addManagerPatchEntry("resources:\n- ../crd\n", ManagerResourcesPatchFile); err == nil {
+		t.Error("Wanted error for kustomization without manager patches, got none")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// TODO: rewrite this when plugins phase 2 is implemented.
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ManagerTerminationGracePeriodPatchFile is the name of the kustomize patch that sets
+// the manager pods' termination grace period.
+const ManagerTerminationGracePeriodPatchFile = "manager_termination_grace_period_patch.yaml"
+
+// ManagerTerminationGracePeriodUsage is the usage text of init's --manager-termination-grace-period flag.
+const ManagerTerminationGracePeriodUsage = "non-negative number of seconds, ex. 60, the manager's pods are given " +
+	"to shut down, ex. to finish cleanup, before they are killed, that overrides the 10 seconds in config/manager/manager.yaml"
+
+// ParseManagerTerminationGracePeriod parses a non-negative manager pod termination
+// grace period in seconds.
+func ParseManagerTerminationGracePeriod(s string) (int64, error) {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("termination grace period %q must be an integer number of seconds", s)
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("termination grace period %d must not be negative", seconds)
+	}
+	return seconds, nil
+}
+
+// managerTerminationGracePeriodPatchHeader is the start of the manager termination grace period patch.
+const managerTerminationGracePeriodPatchHeader = `# This patch sets the number of seconds the manager's pods are given to shut down
+# before they are killed, overriding the grace period in config/manager/manager.yaml.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+`
+
+// AddManagerTerminationGracePeriodPatch writes a patch setting the manager pods'
+// termination grace period to config/default, and adds that patch to
+// config/default/kustomization.yaml.
+func AddManagerTerminationGracePeriodPatch(seconds int64) error {
+	dir := filepath.Join("config", "default")
+	kpath := filepath.Join(dir, "kustomization.yaml")
+	b, err := ioutil.ReadFile(kpath)
+	if err != nil {
+		return err
+	}
+	kustomization, err := addManagerPatchEntry(string(b), ManagerTerminationGracePeriodPatchFile)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %v", kpath, err)
+	}
+
+	patchPath := filepath.Join(dir, ManagerTerminationGracePeriodPatchFile)
+	if err := ioutil.WriteFile(patchPath, []byte(makeManagerTerminationGracePeriodPatch(seconds)), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(kpath, []byte(kustomization), 0644)
+}
+
+// makeManagerTerminationGracePeriodPatch returns a strategic merge patch setting
+// the manager pods' termination grace period.
+func makeManagerTerminationGracePeriodPatch(seconds int64) string {
+	return fmt.Sprintf("%sspec:\n  template:\n    spec:\n      terminationGracePeriodSeconds: %d\n",
+		managerTerminationGracePeriodPatchHeader, seconds)
+}
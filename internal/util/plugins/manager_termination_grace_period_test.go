@@ -0,0 +1,48 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"testing"
+)
+
+func TestParseManagerTerminationGracePeriod(t *testing.T) {
+	for s, want := range map[string]int64{" 60": 60, "0": 0} {
+		seconds, err := ParseManagerTerminationGracePeriod(s)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", s, err)
+		}
+		if seconds != want {
+			t.Errorf("Unexpected seconds %d for %q", seconds, s)
+		}
+	}
+
+	for _, s := range []string{"", "-1", "30s", "1.5", "9223372036854775808"} {
+		if _, err := ParseManagerTerminationGracePeriod(s); err == nil {
+			t.Errorf("Wanted error for %q, got none", s)
+		}
+	}
+}
+
+func TestMakeManagerTerminationGracePeriodPatch(t *testing.T) {
+	want := managerTerminationGracePeriodPatchHeader + `spec:
+  template:
+    spec:
+      terminationGracePeriodSeconds: 60
+`
+	if patch := makeManagerTerminationGracePeriodPatch(60); patch != want {
+		t.Errorf("Unexpected patch:\n%s", patch)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// TODO: rewrite this when plugins phase 2 is implemented.
+package plugins
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"golang.org/x/tools/imports"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+// TemplateDirUsage is the usage text of init's --template-dir flag.
+const TemplateDirUsage = "directory of Go text/template files that replace the built-in scaffold of the file at " +
+	"the same path relative to the project root, or add that file if nothing is scaffolded there"
+
+// projectFile is the project configuration file, which is written by the CLI
+// after all plugins have run and so cannot be templated.
+const projectFile = "PROJECT"
+
+// TemplateData is the data a template in a template directory is executed with.
+type TemplateData struct {
+	// ProjectName is the name of the project.
+	ProjectName string
+	// Repo is the Go module path of the project, empty for non-Go projects.
+	Repo string
+	// Domain is the domain of the project's API groups.
+	Domain string
+	// Layout is the key of the plugin that scaffolded the project.
+	Layout string
+	// ProjectVersion is the version of the PROJECT file.
+	ProjectVersion string
+	// Path is the slash-separated path of the file being rendered relative to the project root.
+	Path string
+	// Original is the built-in scaffold of the file being rendered,
+	// or empty if nothing is scaffolded at Path.
+	Original string
+}
+
+// TemplateOverlay is a set of parsed templates keyed by the
+// slash-separated project path they render to.
+type TemplateOverlay struct {
+	templates map[string]*template.Template
+}
+
+// ReadTemplateDir parses every file in dir as a template rendering to the file
+// at the same path relative to the project root.
+func ReadTemplateDir(dir string) (TemplateOverlay, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return TemplateOverlay{}, err
+	}
+	if !info.IsDir() {
+		return TemplateOverlay{}, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	overlay := TemplateOverlay{templates: map[string]*template.Template{}}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == projectFile {
+			return fmt.Errorf("template %s: %s is written by the CLI and cannot be templated", rel, projectFile)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		t, err := template.New(rel).Funcs(file.DefaultFuncMap()).Parse(string(b))
+		if err != nil {
+			return fmt.Errorf("error parsing template %s: %v", rel, err)
+		}
+		overlay.templates[rel] = t
+		return nil
+	})
+	if err != nil {
+		return TemplateOverlay{}, err
+	}
+	if len(overlay.templates) == 0 {
+		return TemplateOverlay{}, fmt.Errorf("%s contains no templates", dir)
+	}
+	return overlay, nil
+}
+
+// Apply renders every template in the overlay with data from cfg and writes the result
+// over the file at its path in the working directory. All templates are rendered
+// before any file is written, so an error leaves the scaffold unchanged.
+func (o TemplateOverlay) Apply(cfg *config.Config) error {
+	paths := make([]string, 0, len(o.templates))
+	for path := range o.templates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	rendered := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		b, err := o.render(path, cfg)
+		if err != nil {
+			return err
+		}
+		rendered[path] = b
+	}
+
+	for _, path := range paths {
+		path, b := filepath.FromSlash(path), rendered[path]
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode()
+		}
+		if err := ioutil.WriteFile(path, b, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render executes the template for path, gofmt'ing the result if it is a Go file.
+func (o TemplateOverlay) render(path string, cfg *config.Config) ([]byte, error) {
+	original, err := ioutil.ReadFile(filepath.FromSlash(path))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	data := TemplateData{
+		ProjectName:    cfg.ProjectName,
+		Repo:           cfg.Repo,
+		Domain:         cfg.Domain,
+		Layout:         cfg.Layout,
+		ProjectVersion: cfg.Version,
+		Path:           path,
+		Original:       string(original),
+	}
+
+	out := &bytes.Buffer{}
+	if err := o.templates[path].Execute(out, data); err != nil {
+		return nil, fmt.Errorf("error executing template %s: %v", path, err)
+	}
+	b := out.Bytes()
+	if filepath.Ext(path) == ".go" {
+		opts := imports.Options{Comments: true, TabIndent: true, TabWidth: 8, FormatOnly: true}
+		if b, err = imports.Process(path, b, &opts); err != nil {
+			return nil, fmt.Errorf("error formatting rendered template %s: %v", path, err)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+func writeTemplates(t *testing.T, dir string, templates map[string]string) {
+	for path, body := range templates {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadTemplateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "template-dir-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := ReadTemplateDir(dir); err == nil {
+		t.Error("Wanted error for empty directory, got none")
+	}
+
+	writeTemplates(t, dir, map[string]string{
+		"Dockerfile":               "FROM {{ .ProjectName }}\n",
+		"config/manager/README.md": "# {{ title .ProjectName }}\n",
+	})
+	overlay, err := ReadTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, path := range []string{"Dockerfile", "config/manager/README.md"} {
+		if _, ok := overlay.templates[path]; !ok {
+			t.Errorf("Missing template %s", path)
+		}
+	}
+
+	writeTemplates(t, dir, map[string]string{"main.go": "package {{ .Repo"})
+	if _, err := ReadTemplateDir(dir); err == nil || !strings.Contains(err.Error(), "main.go") {
+		t.Errorf("Wanted parse error for main.go, got %v", err)
+	}
+
+	writeTemplates(t, dir, map[string]string{"main.go": "package main\n", projectFile: "version: 2\n"})
+	if _, err := ReadTemplateDir(dir); err == nil {
+		t.Error("Wanted error for PROJECT template, got none")
+	}
+
+	if _, err := ReadTemplateDir(filepath.Join(dir, "Dockerfile")); err == nil {
+		t.Error("Wanted error for file, got none")
+	}
+}
+
+func TestTemplateOverlayApply(t *testing.T) {
+	templateDir, err := ioutil.TempDir("", "template-dir-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(templateDir)
+	projectDir, err := ioutil.TempDir("", "template-project-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectDir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(projectDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := ioutil.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeTemplates(t, templateDir, map[string]string{
+		"main.go": "// Copyright Example Corp. {{ .Repo }}\n\n{{ .Original }}",
+		"docs/{{.ProjectName}}.md": "# {{ .ProjectName }} ({{ .Domain }}, {{ .Layout }}, v{{ .ProjectVersion }})\n" +
+			"{{ if not .Original }}{{ .Path }} is new{{ end }}\n",
+	})
+	overlay, err := ReadTemplateDir(templateDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cfg := &config.Config{
+		Version:     config.Version3Alpha,
+		Repo:        "github.com/example/memcached-operator",
+		Domain:      "example.com",
+		ProjectName: "memcached-operator",
+		Layout:      "go.kubebuilder.io/v2",
+	}
+	if err := overlay.Apply(cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b, err := ioutil.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "// Copyright Example Corp. github.com/example/memcached-operator\n\npackage main\n\nfunc main() {}\n"
+	if string(b) != want {
+		t.Errorf("Unexpected main.go:\n%s", b)
+	}
+	b, err = ioutil.ReadFile(filepath.Join("docs", "{{.ProjectName}}.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "# memcached-operator (example.com, go.kubebuilder.io/v2, v3-alpha)\ndocs/{{.ProjectName}}.md is new\n"
+	if string(b) != want {
+		t.Errorf("Unexpected docs:\n%s", b)
+	}
+
+	// Execution errors must not leave a partially applied overlay.
+	writeTemplates(t, templateDir, map[string]string{
+		"Dockerfile": "FROM {{ .Image }}\n",
+		"main.go":    "package main\n",
+	})
+	if overlay, err = ReadTemplateDir(templateDir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := overlay.Apply(cfg); err == nil || !strings.Contains(err.Error(), "Dockerfile") {
+		t.Errorf("Wanted execution error for Dockerfile, got %v", err)
+	}
+	if b, err = ioutil.ReadFile("main.go"); err != nil || !strings.HasPrefix(string(b), "// Copyright") {
+		t.Errorf("main.go was overwritten after a failed apply: %s", b)
+	}
+}
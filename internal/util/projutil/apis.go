@@ -0,0 +1,167 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package projutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/markbates/inflect"
+
+	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
+)
+
+// Directories, relative to the project root, scanned for the APIs of legacy projects without a PROJECT file.
+var legacyAPIsDirs = []string{filepath.Join("pkg", "apis"), "apis"}
+
+var (
+	// Matches the marker on an API package that sets its group name.
+	groupNameRe = regexp.MustCompile(`(?m)^// \+groupName=(\S+)`)
+	// Matches the registration of an API package's types, ex. SchemeBuilder.Register(&Memcached{}, &MemcachedList{}).
+	registerRe = regexp.MustCompile(`SchemeBuilder\.Register\(([^)]*)\)`)
+	kindRe     = regexp.MustCompile(`&(\w+)\{\}`)
+)
+
+// Resource is an API defined by a project.
+type Resource struct {
+	// Group is the API group, including the project's domain, ex. "cache.example.com".
+	Group string `json:"group"`
+	// Version is the API version, ex. "v1alpha1".
+	Version string `json:"version"`
+	// Kind is the API kind, ex. "Memcached".
+	Kind string `json:"kind"`
+	// Plural is the plural of the lowercased kind, ex. "memcacheds".
+	Plural string `json:"plural"`
+}
+
+func (r Resource) String() string {
+	return fmt.Sprintf("%s.%s/%s, Kind=%s", r.Plural, r.Group, r.Version, r.Kind)
+}
+
+// ListAPIs returns the APIs the project in the working directory defines, in the order of the resources
+// in its PROJECT file. Legacy projects without a PROJECT file are scanned for API packages in pkg/apis or
+// apis, laid out as <group>/<version>, whose types are marked as root objects; their APIs are sorted by
+// group, version, and kind.
+func ListAPIs() ([]Resource, error) {
+	if !kbutil.HasProjectFile() {
+		return listLegacyAPIs()
+	}
+	cfg, err := GetProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	rs := make([]Resource, 0, len(cfg.Resources))
+	for _, gvk := range cfg.Resources {
+		group := gvk.Group
+		if cfg.Domain != "" {
+			group = fmt.Sprintf("%s.%s", gvk.Group, cfg.Domain)
+		}
+		rs = append(rs, newResource(group, gvk.Version, gvk.Kind))
+	}
+	return rs, nil
+}
+
+func newResource(group, version, kind string) Resource {
+	return Resource{
+		Group:   group,
+		Version: version,
+		Kind:    kind,
+		Plural:  inflect.NewDefaultRuleset().Pluralize(strings.ToLower(kind)),
+	}
+}
+
+// listLegacyAPIs returns the APIs in the first of legacyAPIsDirs that exists.
+func listLegacyAPIs() (rs []Resource, err error) {
+	for _, dir := range legacyAPIsDirs {
+		groupInfos, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, groupInfo := range groupInfos {
+			if !groupInfo.IsDir() {
+				continue
+			}
+			versionInfos, err := ioutil.ReadDir(filepath.Join(dir, groupInfo.Name()))
+			if err != nil {
+				return nil, err
+			}
+			for _, versionInfo := range versionInfos {
+				if !versionInfo.IsDir() {
+					continue
+				}
+				versionDir := filepath.Join(dir, groupInfo.Name(), versionInfo.Name())
+				group, kinds, err := parseAPIPackage(versionDir)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing API package %s: %w", versionDir, err)
+				}
+				if group == "" {
+					group = groupInfo.Name()
+				}
+				for _, kind := range kinds {
+					rs = append(rs, newResource(group, versionInfo.Name(), kind))
+				}
+			}
+		}
+		break
+	}
+
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Group != rs[j].Group {
+			return rs[i].Group < rs[j].Group
+		}
+		if rs[i].Version != rs[j].Version {
+			return rs[i].Version < rs[j].Version
+		}
+		return rs[i].Kind < rs[j].Kind
+	})
+	return rs, nil
+}
+
+// parseAPIPackage returns the group name set by a marker in the Go package in dir, if any,
+// and the kinds of the types the package registers, excluding list types.
+func parseAPIPackage(dir string) (group string, kinds []string, err error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".go" || strings.HasSuffix(info.Name(), "_test.go") {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return "", nil, err
+		}
+		if match := groupNameRe.FindSubmatch(b); match != nil {
+			group = string(match[1])
+		}
+		for _, register := range registerRe.FindAllSubmatch(b, -1) {
+			for _, kind := range kindRe.FindAllSubmatch(register[1], -1) {
+				if k := string(kind[1]); !strings.HasSuffix(k, "List") {
+					kinds = append(kinds, k)
+				}
+			}
+		}
+	}
+	return group, kinds, nil
+}
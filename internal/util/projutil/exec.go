@@ -15,11 +15,14 @@
 package projutil
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -164,19 +167,134 @@ func (opts GoCmdOptions) setCmdFields(c *exec.Cmd) {
 //	- Invoke the go command with GO111MODULE=on environment variable set.
 //
 // GoModOn returns true if Go modules are on in one of the above two ways.
+// Use GoModOnWithReason to find out why modules are off.
 func GoModOn() (bool, error) {
+	on, _, err := GoModOnWithReason()
+	return on, err
+}
+
+// GoModOnWithReason is like GoModOn, but if modules are off also returns the
+// reason they are off: GO111MODULE=off is set, or GO111MODULE is auto or unset
+// and the working directory is in $GOPATH/src, where go toolchains older than
+// go1.13 turn modules off.
+func GoModOnWithReason() (on bool, reason string, err error) {
 	v, ok := os.LookupEnv(GoModEnv)
 	if !ok {
-		return true, nil
+		v = "auto"
 	}
 	switch v {
-	case "", "auto", "on":
-		return true, nil
+	case "on":
+		return true, "", nil
+	case "", "auto":
+		inGoPathSrc, err := WdInGoPathSrc()
+		if err != nil {
+			return false, "", err
+		}
+		if !inGoPathSrc {
+			return true, "", nil
+		}
+		goVersion, err := getGoVersion()
+		if err != nil {
+			return false, "", fmt.Errorf("error getting go version: %v", err)
+		}
+		if !goVersionEnablesAutoModules(goVersion) {
+			return false, fmt.Sprintf("%s=%s and the project is in $%s/src, where %s does not enable modules",
+				GoModEnv, v, GoPathEnv, goVersion), nil
+		}
+		return true, "", nil
 	case "off":
-		return false, nil
+		return false, fmt.Sprintf("%s=off is set", GoModEnv), nil
 	default:
-		return false, fmt.Errorf("unknown environment setting GO111MODULE=%s", v)
+		return false, "", fmt.Errorf("unknown environment setting GO111MODULE=%s", v)
+	}
+}
+
+// getGoVersion returns the version of the go toolchain in $PATH, ex. go1.13.4,
+// or "devel go1.16-a1b2c3d" for newer development builds.
+// It is a variable so tests can stub it.
+var getGoVersion = func() (string, error) {
+	out, err := exec.Command("go", "version").Output()
+	if err != nil {
+		return "", err
+	}
+	// Output has the form "go version go1.13.4 linux/amd64", or for development builds
+	// "go version devel go1.16-a1b2c3d <date> linux/amd64" or "go version devel +a1b2c3d <date> linux/amd64".
+	fields := strings.Fields(string(out))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("unexpected go version output %q", out)
+	}
+	if fields[2] == "devel" && len(fields) > 3 && strings.HasPrefix(fields[3], "go") {
+		return fields[2] + " " + fields[3], nil
+	}
+	return fields[2], nil
+}
+
+var (
+	goReleaseVersionRe = regexp.MustCompile(`^go(\d+)\.(\d+)(?:\.(\d+))?((?:alpha|beta|rc)\d+)?$`)
+	goDevelVersionRe   = regexp.MustCompile(`^devel go(\d+)\.(\d+)(?:\.(\d+))?(?:[-+].*)?$`)
+
+	goToolchainVersionMu sync.Mutex
+	goToolchainVersion   string
+)
+
+// GoToolchainVersion returns the semantic version of the go toolchain in $PATH, which can differ from
+// the go directive in go.mod. Releases like go1.15 are returned as "1.15.0", pre-releases like go1.16rc1
+// as "1.16.0-rc1", and development builds of go1.16 as "1.16.0-devel". The version is cached after
+// the first successful call in a process. An error is returned if "go" is not in $PATH, or if its version
+// cannot be parsed, ex. for older development builds that only report a commit.
+func GoToolchainVersion() (string, error) {
+	goToolchainVersionMu.Lock()
+	defer goToolchainVersionMu.Unlock()
+	if goToolchainVersion != "" {
+		return goToolchainVersion, nil
+	}
+
+	goVersion, err := getGoVersion()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", errors.New(`"go" is not in $PATH; install Go from https://golang.org/dl/ ` +
+				`and add its bin directory to $PATH`)
+		}
+		return "", fmt.Errorf("error getting go version: %v", err)
+	}
+	version, err := parseGoVersion(goVersion)
+	if err != nil {
+		return "", err
+	}
+	goToolchainVersion = version
+	return version, nil
+}
+
+// parseGoVersion converts goVersion, a version returned by getGoVersion, to a semantic version
+// as described by GoToolchainVersion.
+func parseGoVersion(goVersion string) (string, error) {
+	suffix := ""
+	m := goReleaseVersionRe.FindStringSubmatch(goVersion)
+	if m != nil {
+		if m[4] != "" {
+			suffix = "-" + m[4]
+		}
+	} else if m = goDevelVersionRe.FindStringSubmatch(goVersion); m != nil {
+		suffix = "-devel"
+	} else {
+		return "", fmt.Errorf("cannot parse go version %q", goVersion)
+	}
+	patch := m[3]
+	if patch == "" {
+		patch = "0"
+	}
+	return m[1] + "." + m[2] + "." + patch + suffix, nil
+}
+
+// goVersionEnablesAutoModules returns true if goVersion, a version output by
+// 'go version', enables modules in $GOPATH/src with GO111MODULE=auto. Only
+// releases older than go1.13 do not; development builds are assumed to.
+func goVersionEnablesAutoModules(goVersion string) bool {
+	var major, minor int
+	if _, err := fmt.Sscanf(goVersion, "go%d.%d", &major, &minor); err != nil {
+		return true
 	}
+	return major > 1 || minor >= 13
 }
 
 func WdInGoPathSrc() (bool, error) {
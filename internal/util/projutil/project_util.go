@@ -15,17 +15,37 @@
 package projutil
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/mattn/go-isatty"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/rogpeppe/go-internal/modfile"
+	"github.com/rogpeppe/go-internal/module"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/tools/go/ast/astutil"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/yaml"
 
+	"github.com/operator-framework/operator-sdk/internal/annotations/metrics"
+	"github.com/operator-framework/operator-sdk/internal/util/diffutil"
 	kbutil "github.com/operator-framework/operator-sdk/internal/util/kubebuilder"
 )
 
@@ -35,17 +55,23 @@ const (
 	GoModEnv   = "GO111MODULE"
 	SrcDir     = "src"
 
+	// ProjectTypeEnv is the environment variable that, when set, overrides the
+	// operator type GetOperatorType detects, ex. "go", "ansible", or "helm". It
+	// does not change which CLI runs, which DetectOperatorType chooses.
+	ProjectTypeEnv = "OPERATOR_SDK_PROJECT_TYPE"
+
 	fsep              = string(filepath.Separator)
 	mainFile          = "main.go"
 	managerMainFile   = "cmd" + fsep + "manager" + fsep + mainFile
 	buildDockerfile   = "build" + fsep + "Dockerfile"
+	buildBinDir       = "build" + fsep + "_output" + fsep + "bin"
 	rolesDir          = "roles"
 	requirementsFile  = "requirements.yml"
 	moleculeDir       = "molecule"
+	watchesFile       = "watches.yaml"
 	goModFile         = "go.mod"
+	projectFile       = "PROJECT"
 	defaultPermission = 0644
-
-	noticeColor = "\033[1;36m%s\033[0m"
 )
 
 // OperatorType - the type of operator
@@ -62,6 +88,7 @@ const (
 	OperatorTypeUnknown OperatorType = "unknown"
 )
 
+// ErrUnknownOperatorType is returned when the type of operator cannot be detected.
 type ErrUnknownOperatorType struct {
 	Type string
 }
@@ -73,6 +100,34 @@ func (e ErrUnknownOperatorType) Error() string {
 	return fmt.Sprintf(`unknown operator type "%v"`, e.Type)
 }
 
+// ErrWrongOperatorType is returned when the type of operator detected in the project root
+// is not the type a command supports.
+type ErrWrongOperatorType struct {
+	Expected OperatorType
+	Detected OperatorType
+}
+
+func (e ErrWrongOperatorType) Error() string {
+	if e.Detected == OperatorTypeUnknown {
+		return fmt.Sprintf("this command only supports %s projects but the project type could not be detected",
+			operatorTypeName(e.Expected))
+	}
+	return fmt.Sprintf("this command only supports %s projects but detected %s", operatorTypeName(e.Expected), e.Detected)
+}
+
+// operatorTypeName returns the name of t as written in prose, ex. "Go".
+func operatorTypeName(t OperatorType) string {
+	switch t {
+	case OperatorTypeGo:
+		return "Go"
+	case OperatorTypeAnsible:
+		return "Ansible"
+	case OperatorTypeHelm:
+		return "Helm"
+	}
+	return t
+}
+
 // MustInProjectRoot checks if the current dir is the project root, and exits
 // if not.
 func MustInProjectRoot() {
@@ -81,9 +136,35 @@ func MustInProjectRoot() {
 	}
 }
 
+// MustInProjectRootWithType checks if the current dir is the root of a project of the expected
+// operator type, and exits if not.
+func MustInProjectRootWithType(expected OperatorType) {
+	if err := CheckProjectRootWithType(expected); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CheckProjectRootWithType checks if the current dir is the project root like CheckProjectRoot,
+// and returns an ErrWrongOperatorType if the project's detected operator type is not expected.
+func CheckProjectRootWithType(expected OperatorType) error {
+	if err := CheckProjectRoot(); err != nil {
+		return err
+	}
+	detected, err := GetOperatorTypeErr()
+	if err != nil && !errors.As(err, &ErrUnknownOperatorType{}) {
+		return err
+	}
+	if detected != expected {
+		return ErrWrongOperatorType{Expected: expected, Detected: detected}
+	}
+	return nil
+}
+
 // CheckProjectRoot checks if the current dir is the project root, and returns
 // an error if not.
-// "build/Dockerfile" may not be present in all projects
+// "build/Dockerfile" may not be present in all projects, so legacy Ansible
+// projects are also recognized by the files IsOperatorAnsible checks for, and
+// legacy Helm projects by their "watches.yaml".
 // todo: scaffold Project file for Ansible and Helm with the type information
 func CheckProjectRoot() error {
 	if kbutil.HasProjectFile() {
@@ -94,16 +175,22 @@ func CheckProjectRoot() error {
 	// If the current directory has a "build/Dockerfile", then it is safe to say
 	// we are at the project root.
 	if _, err := os.Stat(buildDockerfile); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("must run command in project root dir: project structure requires %s",
-				buildDockerfile)
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("error while checking if current directory is the project root: %v", err)
+		}
+		if hasLegacyAnsibleFiles() {
+			return nil
 		}
-		return fmt.Errorf("error while checking if current directory is the project root: %v", err)
+		if info, err := os.Stat(watchesFile); err == nil && info.Mode().IsRegular() {
+			return nil
+		}
+		return fmt.Errorf("must run command in project root dir: project structure requires %s",
+			buildDockerfile)
 	}
 	return nil
 }
 
-// TODO: remove this (should use os.Getwd() or Config.ProjectName).
+// TODO: remove this (should use os.Getwd() or GetProjectName).
 func MustGetwd() string {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -120,38 +207,65 @@ func getHomeDir() (string, error) {
 	return homedir.Expand(hd)
 }
 
-// TODO(hasbro17): If this function is called in the subdir of
-// a module project it will fail to parse go.mod and return
-// the correct import path.
-// This needs to be fixed to return the pkg import path for any subdir
-// in order for `generate csv` to correctly form pkg imports
-// for API pkg paths that are not relative to the root dir.
-// This might not be fixable since there is no good way to
-// get the project root from inside the subdir of a module project.
-//
-// GetGoPkg returns the current directory's import path by parsing it from
-// wd if this project's repository path is rooted under $GOPATH/src, or
-// from go.mod the project uses Go modules to manage dependencies.
-// If the project has a go.mod then wd must be the project root.
+// ErrNoProjectRoot is returned by FindProjectRoot if neither the working
+// directory nor any of its parents contain a go.mod or PROJECT file.
+var ErrNoProjectRoot = errors.New("no go.mod or PROJECT file found")
+
+// FindProjectRoot returns the closest directory, starting from the working directory
+// and walking up its parents to the filesystem root, that contains a go.mod or
+// PROJECT file.
+func FindProjectRoot() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return findProjectRoot(wd)
+}
+
+func findProjectRoot(start string) (string, error) {
+	for dir := start; ; dir = filepath.Dir(dir) {
+		for _, name := range []string{goModFile, projectFile} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return dir, nil
+			} else if !os.IsNotExist(err) {
+				return "", err
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("%w in %s or any parent directory", ErrNoProjectRoot, start)
+		}
+	}
+}
+
+// GetGoPkg returns the current directory's import path, either from the module
+// path in the go.mod of the closest project root found by FindProjectRoot, or
+// by parsing it from wd if this project's repository path is rooted under $GOPATH/src.
+// The current directory may be a subdirectory of the project root, ex. an API
+// package's directory.
 //
 // Example: "github.com/example-inc/app-operator"
 func GetGoPkg() string {
+	pkg, err := GetGoPkgErr()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return pkg
+}
+
+// GetGoPkgErr returns the current directory's import path like GetGoPkg, but
+// returns an error instead of exiting if the path cannot be determined.
+func GetGoPkgErr() (string, error) {
 	// Default to reading from go.mod, as it should usually have the (correct)
 	// package path, and no further processing need be done on it if so.
-	if _, err := os.Stat(goModFile); err != nil && !os.IsNotExist(err) {
-		log.Fatalf("Failed to read go.mod: %v", err)
-	} else if err == nil {
-		b, err := ioutil.ReadFile(goModFile)
-		if err != nil {
-			log.Fatalf("Read go.mod: %v", err)
-		}
-		mf, err := modfile.Parse(goModFile, b, nil)
-		if err != nil {
-			log.Fatalf("Parse go.mod: %v", err)
-		}
-		if mf.Module != nil && mf.Module.Mod.Path != "" {
-			return mf.Module.Mod.Path
-		}
+	if pkg, ok, err := getGoModPkg(); err != nil {
+		return "", err
+	} else if ok {
+		return pkg, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working directory: %w", err)
 	}
 
 	// Then try parsing package path from $GOPATH (set env or default).
@@ -159,43 +273,603 @@ func GetGoPkg() string {
 	if !ok || goPath == "" {
 		hd, err := getHomeDir()
 		if err != nil {
-			log.Fatal(err)
+			return "", err
 		}
 		goPath = filepath.Join(hd, "go", "src")
 	} else {
-		// MustSetWdGopath is necessary here because the user has set GOPATH,
+		// SetWdGopath is necessary here because the user has set GOPATH,
 		// which could be a path list.
-		goPath = MustSetWdGopath(goPath)
+		if goPath, err = SetWdGopath(goPath); err != nil {
+			return "", err
+		}
 	}
-	if !strings.HasPrefix(MustGetwd(), goPath) {
-		log.Fatal("Could not determine project repository path: $GOPATH not set, wd in default $HOME/go/src," +
+	if !strings.HasPrefix(wd, goPath) {
+		return "", errors.New("could not determine project repository path: $GOPATH not set, wd in default $HOME/go/src," +
 			" or wd does not contain a go.mod")
 	}
-	return parseGoPkg(goPath)
+	return parseGoPkg(goPath, wd), nil
+}
+
+// getGoModPkg returns the current directory's import path from the module path in
+// the project's module file, and false if the project root has no go.mod or
+// the module path is not set.
+func getGoModPkg() (string, bool, error) {
+	root, err := FindProjectRoot()
+	if errors.Is(err, ErrNoProjectRoot) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("error finding project root: %w", err)
+	}
+
+	goMod, err := resolveGoModPath()
+	if err != nil {
+		return "", false, err
+	}
+	b, err := ioutil.ReadFile(goMod)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("error reading %s: %w", filepath.Base(goMod), err)
+	}
+	mf, err := modfile.Parse(goMod, b, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("error parsing %s: %w", filepath.Base(goMod), err)
+	}
+	if mf.Module == nil || mf.Module.Mod.Path == "" {
+		return "", false, nil
+	}
+	if err := ValidateGoPkg(mf.Module.Mod.Path); err != nil {
+		return "", false, fmt.Errorf("error in %s: %w", goMod, err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", false, fmt.Errorf("error getting working directory: %w", err)
+	}
+	rel, err := filepath.Rel(root, wd)
+	if err != nil {
+		return "", false, err
+	}
+	return path.Join(mf.Module.Mod.Path, filepath.ToSlash(rel)), true, nil
+}
+
+// AddGoModReplace adds a replace directive replacing all versions of the module oldPath with newPath at
+// newVersion to the project root's go.mod, updating any existing replace of oldPath rather than adding
+// another. newVersion must be empty if newPath is a local directory, ex. "../fork", and set otherwise.
+func AddGoModReplace(oldPath, newPath, newVersion string) error {
+	if modfile.IsDirectoryPath(newPath) && newVersion != "" {
+		return fmt.Errorf("replacement %s is a directory, version %s must be empty", newPath, newVersion)
+	}
+	if !modfile.IsDirectoryPath(newPath) && newVersion == "" {
+		return fmt.Errorf("replacement module %s must have a version", newPath)
+	}
+	return editGoMod(func(mf *modfile.File) error {
+		return mf.AddReplace(oldPath, "", newPath, newVersion)
+	})
+}
+
+// RemoveGoModReplace removes all replace directives of the module oldPath from the project root's go.mod.
+// go.mod is not modified if it has none.
+func RemoveGoModReplace(oldPath string) error {
+	return editGoMod(func(mf *modfile.File) error {
+		for _, r := range mf.Replace {
+			if r.Old.Path == oldPath {
+				if err := mf.DropReplace(r.Old.Path, r.Old.Version); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetGoModVersion returns the Go language version, ex. "1.13", set by the go directive of the project root's go.mod.
+func GetGoModVersion() (string, error) {
+	goMod, _, mf, err := parseGoMod()
+	if err != nil {
+		return "", err
+	}
+	if mf.Go == nil || mf.Go.Version == "" {
+		return "", fmt.Errorf("%s has no go directive", goMod)
+	}
+	return mf.Go.Version, nil
+}
+
+// RenameModulePath renames the module oldPath to newPath throughout the project rooted at the closest
+// project root found by FindProjectRoot: in go.mod's module directive, in imports of oldPath and its packages
+// in all .go files, and in references to oldPath in the PROJECT file and YAML files in config/.
+// vendor, testdata, and hidden directories are skipped, like the go command does. The number of files changed
+// is logged.
+func RenameModulePath(oldPath, newPath string) error {
+	if err := ValidateGoPkg(newPath); err != nil {
+		return fmt.Errorf("invalid module path %s: %w", newPath, err)
+	}
+	root, err := FindProjectRoot()
+	if err != nil {
+		return fmt.Errorf("error finding project root: %w", err)
+	}
+
+	var changed int
+	goModChanged, err := editGoModChanged(func(mf *modfile.File) error {
+		if mf.Module == nil {
+			return errors.New("go.mod has no module directive")
+		}
+		if mf.Module.Mod.Path != oldPath {
+			return fmt.Errorf("go.mod module path is %s, not %s", mf.Module.Mod.Path, oldPath)
+		}
+		return mf.AddModuleStmt(newPath)
+	})
+	if err != nil {
+		return err
+	}
+	if goModChanged {
+		changed++
+	}
+
+	// Match whole references, which may be followed by a package path or punctuation, ex. a period.
+	refRe := regexp.MustCompile(`(^|[^\w.~-])` + regexp.QuoteMeta(oldPath) + `(\.?(?:[^\w.~-]|$))`)
+	configDir := filepath.Join(root, "config")
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var fileChanged bool
+		switch ext := filepath.Ext(path); {
+		case ext == ".go":
+			fileChanged, err = renameGoImports(path, info.Mode(), oldPath, newPath)
+		case path == filepath.Join(root, projectFile),
+			(ext == ".yaml" || ext == ".yml") && strings.HasPrefix(path, configDir+string(filepath.Separator)):
+			fileChanged, err = rewriteFileRegexp(path, info.Mode(), refRe, "${1}"+newPath+"${2}")
+		}
+		if err != nil {
+			return fmt.Errorf("error renaming module path in %s: %w", path, err)
+		}
+		if fileChanged {
+			changed++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Renamed module %s to %s in %d file(s)", oldPath, newPath, changed)
+	return nil
+}
+
+// renameGoImports rewrites imports of the module oldPath and its packages in the Go file
+// at path to import newPath instead, and returns true if the file was changed.
+func renameGoImports(path string, mode os.FileMode, oldPath, newPath string) (bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return false, err
+	}
+	rewrote := false
+	for _, imp := range f.Imports {
+		impPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return false, err
+		}
+		if impPath == oldPath || strings.HasPrefix(impPath, oldPath+"/") {
+			newImpPath := newPath + strings.TrimPrefix(impPath, oldPath)
+			rewrote = astutil.RewriteImport(fset, f, impPath, newImpPath) || rewrote
+		}
+	}
+	if !rewrote {
+		return false, nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return false, err
+	}
+	return true, ioutil.WriteFile(path, buf.Bytes(), mode)
+}
+
+// rewriteFileRegexp replaces matches of re in the file at path with repl,
+// and returns true if the file was changed.
+func rewriteFileRegexp(path string, mode os.FileMode, re *regexp.Regexp, repl string) (bool, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	out := re.ReplaceAll(b, []byte(repl))
+	if bytes.Equal(out, b) {
+		return false, nil
+	}
+	return true, ioutil.WriteFile(path, out, mode)
+}
+
+// editGoMod parses the project root's go.mod, applies edit, and writes it back formatted.
+func editGoMod(edit func(*modfile.File) error) error {
+	_, err := editGoModChanged(edit)
+	return err
+}
+
+// editGoModChanged is like editGoMod, but also returns true if go.mod was changed.
+func editGoModChanged(edit func(*modfile.File) error) (bool, error) {
+	goMod, b, mf, err := parseGoMod()
+	if err != nil {
+		return false, err
+	}
+
+	if err := edit(mf); err != nil {
+		return false, err
+	}
+	mf.Cleanup()
+	out, err := mf.Format()
+	if err != nil {
+		return false, fmt.Errorf("error formatting %s: %w", filepath.Base(goMod), err)
+	}
+	if bytes.Equal(out, b) {
+		return false, nil
+	}
+	if err := ioutil.WriteFile(goMod, out, defaultPermission); err != nil {
+		return false, fmt.Errorf("error writing %s: %w", filepath.Base(goMod), err)
+	}
+	return true, nil
+}
+
+// parseGoMod returns the path, contents, and parsed contents of the project's module file.
+func parseGoMod() (string, []byte, *modfile.File, error) {
+	goMod, err := resolveGoModPath()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	b, err := ioutil.ReadFile(goMod)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("error reading %s: %w", filepath.Base(goMod), err)
+	}
+	mf, err := modfile.Parse(goMod, b, nil)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("error parsing %s: %w", filepath.Base(goMod), err)
+	}
+	return goMod, b, mf, nil
+}
+
+// resolveGoModPath returns the path of the project's module file: the file set by the -modfile flag in
+// GOFLAGS, if any, otherwise the go.mod in the project root found by FindProjectRoot. Like the go command,
+// a relative -modfile path is relative to the working directory.
+func resolveGoModPath() (string, error) {
+	modFile, hasModFile, err := goFlagValue("modfile")
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", GoFlagsEnv, err)
+	}
+	if hasModFile {
+		return filepath.Abs(modFile)
+	}
+	root, err := FindProjectRoot()
+	if err != nil {
+		return "", fmt.Errorf("error finding project root: %w", err)
+	}
+	return filepath.Join(root, goModFile), nil
+}
+
+// ValidateGoPkg returns an error naming the offending path element if pkg is not a valid Go module path,
+// ex. if its host contains uppercase letters. Scaffolded imports of an invalid module path do not compile.
+// Paths whose first element is not a host, ex. "app-operator", only need to be valid import paths.
+func ValidateGoPkg(pkg string) error {
+	elems := strings.Split(pkg, "/")
+	check := module.CheckImportPath
+	if strings.Contains(elems[0], ".") {
+		check = module.CheckPath
+	}
+	err := check(pkg)
+	if err == nil {
+		return nil
+	}
+
+	// Find the first element that makes the path invalid. Rules of module paths that only apply
+	// to their host are not checked for import paths.
+	elem := elems[len(elems)-1]
+	if check(elems[0]) != nil {
+		elem = elems[0]
+	}
+	for i := range elems {
+		if module.CheckImportPath(strings.Join(elems[:i+1], "/")) != nil {
+			elem = elems[i]
+			break
+		}
+	}
+	return fmt.Errorf("invalid module path element %q: %v", elem, err)
 }
 
-func parseGoPkg(gopath string) string {
+func parseGoPkg(gopath, wd string) string {
 	goSrc := filepath.Join(gopath, SrcDir)
-	wd := MustGetwd()
 	pathedPkg := strings.Replace(wd, goSrc, "", 1)
 	// Make sure package only contains the "/" separator and no others, and
 	// trim any leading/trailing "/".
 	return strings.Trim(filepath.ToSlash(pathedPkg), "/")
 }
 
+var (
+	projectConfigMu  sync.Mutex
+	projectConfig    *config.Config
+	projectConfigErr error
+	projectConfigSet bool
+)
+
+// GetProjectConfig returns the config in the PROJECT file in cwd, which is read
+// and parsed by the first call in a process and cached for all later calls,
+// so every caller sees the same config even if the file changes mid-run.
+// Callers must not modify the returned config.
+func GetProjectConfig() (*config.Config, error) {
+	projectConfigMu.Lock()
+	defer projectConfigMu.Unlock()
+	if !projectConfigSet {
+		projectConfig, projectConfigErr = kbutil.ReadConfig()
+		projectConfigSet = true
+	}
+	return projectConfig, projectConfigErr
+}
+
+// ResetProjectConfigCache clears the config cached by GetProjectConfig,
+// so the next call reads the PROJECT file again. It is intended for tests.
+func ResetProjectConfigCache() {
+	projectConfigMu.Lock()
+	defer projectConfigMu.Unlock()
+	projectConfig, projectConfigErr, projectConfigSet = nil, nil, false
+}
+
+// GetPluginConfig decodes the plugin config stored under key in the plugins of the PROJECT file in cwd
+// into out, which must be a pointer. out is not modified if the PROJECT file has no config under key.
+func GetPluginConfig(key string, out interface{}) error {
+	cfg, err := GetProjectConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+	if err := cfg.DecodePluginConfig(key, out); err != nil {
+		return fmt.Errorf("error decoding plugin config %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetPluginConfig encodes pluginCfg under key in the plugins of the PROJECT file in cwd, replacing any
+// existing config under key and preserving all other keys. The PROJECT file is only written if its config changes,
+// and the config cached by GetProjectConfig is cleared so later calls see the new config.
+func SetPluginConfig(key string, pluginCfg interface{}) error {
+	b, err := ioutil.ReadFile(projectFile)
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+	cfg := &config.Config{}
+	if err := cfg.Unmarshal(b); err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+	// Compare marshaled configs, since the file may be formatted differently than it would be written.
+	before, err := cfg.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := cfg.EncodePluginConfig(key, pluginCfg); err != nil {
+		return fmt.Errorf("error encoding plugin config %s: %w", key, err)
+	}
+	out, err := cfg.Marshal()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(out, before) {
+		return nil
+	}
+	info, err := os.Stat(projectFile)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(projectFile, out, info.Mode()); err != nil {
+		return fmt.Errorf("error writing config: %w", err)
+	}
+	ResetProjectConfigCache()
+	return nil
+}
+
+// GetProjectName returns the projectName in the PROJECT file in cwd. If there is no PROJECT file or it has
+// no projectName, as in legacy projects, the base name of cwd is returned, sanitized into a valid DNS-1123 label.
+// Resource names scaffolded from the project name should use GetProjectName so they agree with each other.
+func GetProjectName() (string, error) {
+	if _, err := os.Stat(projectFile); err == nil {
+		cfg, err := GetProjectConfig()
+		if err != nil {
+			return "", fmt.Errorf("error reading config: %w", err)
+		}
+		if cfg.ProjectName != "" {
+			return cfg.ProjectName, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("error reading config: %w", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting current directory: %v", err)
+	}
+	name := sanitizeProjectName(filepath.Base(wd))
+	if name == "" {
+		return "", fmt.Errorf("cannot derive a project name from directory %q", filepath.Base(wd))
+	}
+	return name, nil
+}
+
+// ErrProjectNameMismatch is returned by CheckProjectNameConsistency when the project directory's name
+// does not match the PROJECT file's projectName. It describes a likely misconfiguration,
+// so callers should usually print it as a warning rather than fail.
+type ErrProjectNameMismatch struct {
+	// DirName is the base name of the project directory.
+	DirName string
+	// ProjectName is the projectName in the PROJECT file.
+	ProjectName string
+}
+
+func (e ErrProjectNameMismatch) Error() string {
+	return fmt.Sprintf("project directory name %q does not match projectName %q in the PROJECT file; "+
+		"names derived from the project name, ex. of bundles and CSVs, will use %q",
+		e.DirName, e.ProjectName, e.ProjectName)
+}
+
+// CheckProjectNameConsistency returns an ErrProjectNameMismatch if the base name of cwd does not match
+// the projectName in the PROJECT file in cwd. Names match if they are equal ignoring case, or if the directory
+// name sanitized by GetProjectName equals projectName. nil is returned if there is no PROJECT file or it has
+// no projectName, since GetProjectName uses the directory name then.
+func CheckProjectNameConsistency() error {
+	if _, err := os.Stat(projectFile); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading config: %w", err)
+	}
+	cfg, err := GetProjectConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+	if cfg.ProjectName == "" {
+		return nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current directory: %v", err)
+	}
+	dirName := filepath.Base(wd)
+	if strings.EqualFold(dirName, cfg.ProjectName) || sanitizeProjectName(dirName) == cfg.ProjectName {
+		return nil
+	}
+	return ErrProjectNameMismatch{DirName: dirName, ProjectName: cfg.ProjectName}
+}
+
+// invalidProjectNameCharsRe matches runs of characters not allowed in a DNS-1123 label.
+var invalidProjectNameCharsRe = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// sanitizeProjectName lowercases name, replaces runs of characters not allowed in a DNS-1123 label with "-",
+// and trims the result to a valid label. An empty string is returned if name has no valid characters.
+func sanitizeProjectName(name string) string {
+	name = invalidProjectNameCharsRe.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > validation.DNS1123LabelMaxLength {
+		name = strings.TrimRight(name[:validation.DNS1123LabelMaxLength], "-")
+	}
+	return name
+}
+
 // GetOperatorType returns type of operator is in cwd.
 // This function should be called after verifying the user is in project root.
+// OperatorTypeUnknown is returned if the type cannot be detected; use
+// GetOperatorTypeErr to find out why.
 func GetOperatorType() OperatorType {
+	operatorType, err := GetOperatorTypeErr()
+	if err != nil {
+		if errors.As(err, &ErrUnknownOperatorType{}) {
+			return OperatorTypeUnknown
+		}
+		log.Fatal(err)
+	}
+	return operatorType
+}
+
+// GetOperatorTypeErr returns type of operator is in cwd, like GetOperatorType,
+// but returns an error instead of exiting if the PROJECT file cannot be read.
+// If the type cannot be detected, an error wrapping ErrUnknownOperatorType is
+// returned: for projects with a PROJECT file this means the layout is empty
+// or not a known plugin key, otherwise the cwd has none of the legacy layout's
+// Go or Ansible files.
+//
+// If ProjectTypeEnv is set, its value is parsed by OperatorTypeFromString and
+// returned without inspecting cwd, ex. for scripts run where detection is unreliable.
+// Otherwise the type is detected by DetectOperatorType.
+func GetOperatorTypeErr() (OperatorType, error) {
+	if s := os.Getenv(ProjectTypeEnv); s != "" {
+		operatorType, err := OperatorTypeFromString(s)
+		if err != nil {
+			return OperatorTypeUnknown, fmt.Errorf("invalid %s: %w", ProjectTypeEnv, err)
+		}
+		return operatorType, nil
+	}
+	return DetectOperatorType()
+}
+
+// DetectOperatorType returns type of operator is in cwd from its PROJECT file,
+// or from the legacy layout's files if it has none, ignoring ProjectTypeEnv.
+// Errors are those of GetOperatorTypeErr. Use it to choose the CLI to run, so
+// that the environment variable cannot route commands to the legacy CLI.
+func DetectOperatorType() (OperatorType, error) {
+	if kbutil.HasProjectFile() {
+		cfg, err := GetProjectConfig()
+		if err != nil {
+			return OperatorTypeUnknown, fmt.Errorf("error reading config: %w", err)
+		}
+		// Project version 2 configs predate the layout key and are only scaffolded for Go.
+		if cfg.IsV2() {
+			return OperatorTypeGo, nil
+		}
+		if cfg.Layout == "" {
+			return OperatorTypeUnknown, fmt.Errorf("PROJECT file has no layout: %w", ErrUnknownOperatorType{})
+		}
+		if operatorType := PluginKeyToOperatorType(cfg.Layout); operatorType != OperatorTypeUnknown {
+			return operatorType, nil
+		}
+		return OperatorTypeUnknown, ErrUnknownOperatorType{Type: cfg.Layout}
+	}
+
+	// todo: remove the following code when the legacy layout is no longer supported
+	// Every legacy project type has a build/Dockerfile, so only the Go and
+	// Ansible files are checked, in the same order as GetOperatorType.
 	switch {
-	// TODO: Differentiate between legacy and KB Go projects
-	case IsOperatorGo():
-		return OperatorTypeGo
-	case IsOperatorAnsible():
-		return OperatorTypeAnsible
-	case IsOperatorHelm():
-		return OperatorTypeHelm
+	case hasLegacyGoFiles():
+		return OperatorTypeGo, nil
+	case hasLegacyAnsibleFiles():
+		return OperatorTypeAnsible, nil
 	}
-	return OperatorTypeUnknown
+	return OperatorTypeUnknown, fmt.Errorf("no PROJECT file or legacy project files found: %w", ErrUnknownOperatorType{})
+}
+
+// GetOperatorTypeFromBundle returns the type of operator packaged in the bundle
+// at bundleRoot, ex. an unpacked bundle image or a directory with manifests/
+// and metadata/, using the project layout annotation in metadata/annotations.yaml.
+// OperatorTypeUnknown is returned with no error if the bundle has no annotations
+// file or project layout annotation, ex. if it was not built by the SDK. An error
+// wrapping ErrUnknownOperatorType is returned for an unknown layout.
+func GetOperatorTypeFromBundle(bundleRoot string) (OperatorType, error) {
+	annotationsPath := filepath.Join(bundleRoot, "metadata", "annotations.yaml")
+	b, err := ioutil.ReadFile(annotationsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return OperatorTypeUnknown, nil
+		}
+		return OperatorTypeUnknown, fmt.Errorf("error reading bundle annotations: %v", err)
+	}
+	metadata := struct {
+		Annotations map[string]string `json:"annotations"`
+	}{}
+	if err := yaml.Unmarshal(b, &metadata); err != nil {
+		return OperatorTypeUnknown, fmt.Errorf("error unmarshalling bundle annotations %s: %v", annotationsPath, err)
+	}
+
+	layout, hasLayout := metadata.Annotations[metrics.LayoutBundleAnnotation]
+	if !hasLayout {
+		return OperatorTypeUnknown, nil
+	}
+	if operatorType := PluginKeyToOperatorType(layout); operatorType != OperatorTypeUnknown {
+		return operatorType, nil
+	}
+	return OperatorTypeUnknown, ErrUnknownOperatorType{Type: layout}
+}
+
+// OperatorTypeFromString returns the operator type named by s, one of "go", "ansible", or "helm",
+// ignoring case and surrounding whitespace. ErrUnknownOperatorType is returned for any other value.
+func OperatorTypeFromString(s string) (OperatorType, error) {
+	switch operatorType := strings.ToLower(strings.TrimSpace(s)); operatorType {
+	case OperatorTypeGo, OperatorTypeAnsible, OperatorTypeHelm:
+		return operatorType, nil
+	}
+	return OperatorTypeUnknown, ErrUnknownOperatorType{Type: s}
 }
 
 // PluginKeyToOperatorType converts a plugin key string to an operator project
@@ -214,20 +888,109 @@ func PluginKeyToOperatorType(pluginKey string) OperatorType {
 	return OperatorTypeUnknown
 }
 
+// Layout - the scaffold layout of a project.
+type Layout string
+
+const (
+	// LayoutLegacy - pre-kubebuilder layout with a build/Dockerfile and, for Go, cmd/manager/main.go.
+	LayoutLegacy Layout = "legacy"
+	// LayoutKubebuilderV2 - kubebuilder layout with a version 2 PROJECT file.
+	LayoutKubebuilderV2 Layout = "kubebuilder-v2"
+	// LayoutKubebuilderV3 - kubebuilder layout with a version 3-alpha PROJECT file.
+	LayoutKubebuilderV3 Layout = "kubebuilder-v3"
+	// LayoutUnknown - unknown layout.
+	LayoutUnknown Layout = "unknown"
+)
+
+// DetectProjectLayout returns the layout of the project in cwd from its PROJECT
+// file's version, or LayoutLegacy if there is no PROJECT file and cwd contains
+// the legacy layout's cmd/manager/main.go or build/Dockerfile. A PROJECT file
+// without a version is assumed to be the oldest kubebuilder layout, version 2.
+func DetectProjectLayout() (Layout, error) {
+	if kbutil.HasProjectFile() {
+		cfg, err := GetProjectConfig()
+		if err != nil {
+			return LayoutUnknown, fmt.Errorf("error reading config: %w", err)
+		}
+		switch cfg.Version {
+		case "":
+			log.Warnf("PROJECT file has no version, assuming version %s", config.Version2)
+			return LayoutKubebuilderV2, nil
+		case config.Version2:
+			return LayoutKubebuilderV2, nil
+		case config.Version3Alpha:
+			return LayoutKubebuilderV3, nil
+		}
+		return LayoutUnknown, fmt.Errorf("unsupported PROJECT file version %q", cfg.Version)
+	}
+
+	// todo: remove the following code when the legacy layout is no longer supported
+	for _, legacyFile := range []string{managerMainFile, buildDockerfile} {
+		if _, err := os.Stat(legacyFile); err == nil {
+			return LayoutLegacy, nil
+		} else if !os.IsNotExist(err) {
+			return LayoutUnknown, err
+		}
+	}
+	return LayoutUnknown, errors.New("no PROJECT file or legacy project files found")
+}
+
 // IsOperatorGo returns true when the layout field in PROJECT file has the Go prefix key.
 // NOTE: For the legacy, returns true when the project contains the cmd/manager directory and main.go file.
+// It is true exactly when one of IsOperatorGoKubebuilder or IsOperatorGoLegacy is.
 func IsOperatorGo() bool {
+	isGo, err := IsOperatorGoErr()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return isGo
+}
+
+// IsOperatorGoErr is like IsOperatorGo, but returns an error instead of
+// exiting if the PROJECT file cannot be read.
+func IsOperatorGoErr() (bool, error) {
 	// If the project has the new layout we will check the type in the config file
 	if kbutil.HasProjectFile() {
-		cfg, err := kbutil.ReadConfig()
-		if err != nil {
-			log.Fatalf("Error reading config: %v", err)
-		}
-		return cfg.IsV2() || PluginKeyToOperatorType(cfg.Layout) == OperatorTypeGo
+		return isOperatorGoKubebuilder()
 	}
 
 	// todo: remove the following code when the legacy layout is no longer supported
 	// we can check it using the Project File
+	return IsOperatorGoLegacy(), nil
+}
+
+// IsOperatorGoKubebuilder returns true when the project has a PROJECT file with a Go layout,
+// or is a project version 2 config, which are only scaffolded for Go. Since legacy projects
+// have no PROJECT file, a project cannot be both this and IsOperatorGoLegacy.
+func IsOperatorGoKubebuilder() bool {
+	isGo, err := isOperatorGoKubebuilder()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return isGo
+}
+
+func isOperatorGoKubebuilder() (bool, error) {
+	if !kbutil.HasProjectFile() {
+		return false, nil
+	}
+	cfg, err := GetProjectConfig()
+	if err != nil {
+		return false, fmt.Errorf("error reading config: %w", err)
+	}
+	return cfg.IsV2() || PluginKeyToOperatorType(cfg.Layout) == OperatorTypeGo, nil
+}
+
+// IsOperatorGoLegacy returns true when the project has no PROJECT file and contains the legacy
+// cmd/manager/main.go file or a main.go file. A project cannot both be this and IsOperatorGoKubebuilder.
+// Unlike IsLegacyOperatorGo, projects with only a main.go are also legacy Go projects.
+func IsOperatorGoLegacy() bool {
+	return !kbutil.HasProjectFile() && hasLegacyGoFiles()
+}
+
+// hasLegacyGoFiles returns true when the project contains the cmd/manager/main.go
+// or main.go file.
+func hasLegacyGoFiles() bool {
 	_, err := os.Stat(managerMainFile)
 	if err == nil || os.IsExist(err) {
 		return true
@@ -237,18 +1000,44 @@ func IsOperatorGo() bool {
 	return err == nil || os.IsExist(err)
 }
 
+// IsLegacyOperatorGo returns true when the project has no PROJECT file and
+// contains the legacy cmd/manager/main.go file.
+func IsLegacyOperatorGo() bool {
+	if kbutil.HasProjectFile() {
+		return false
+	}
+	_, err := os.Stat(managerMainFile)
+	return err == nil || os.IsExist(err)
+}
+
 // IsOperatorAnsible returns true when the layout field in PROJECT file has the Ansible prefix key.
 // NOTE: For the legacy, returns true when the project  contains the roles and the molecule directory.
 func IsOperatorAnsible() bool {
+	isAnsible, err := IsOperatorAnsibleErr()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return isAnsible
+}
+
+// IsOperatorAnsibleErr is like IsOperatorAnsible, but returns an error instead
+// of exiting if the PROJECT file cannot be read.
+func IsOperatorAnsibleErr() (bool, error) {
 	// If the project is in the new layout, check the config file's plugin type.
 	if kbutil.HasProjectFile() {
-		cfg, err := kbutil.ReadConfig()
+		cfg, err := GetProjectConfig()
 		if err != nil {
-			log.Fatalf("Error reading config: %v", err)
+			return false, fmt.Errorf("error reading config: %w", err)
 		}
-		return PluginKeyToOperatorType(cfg.Layout) == OperatorTypeAnsible
+		return PluginKeyToOperatorType(cfg.Layout) == OperatorTypeAnsible, nil
 	}
 	// todo(camilamacedo86): remove when the legacy layout is no longer supported
+	return hasLegacyAnsibleFiles(), nil
+}
+
+// hasLegacyAnsibleFiles returns true when the project contains the roles or
+// molecule directory or the requirements.yml file.
+func hasLegacyAnsibleFiles() bool {
 	stat, err := os.Stat(rolesDir)
 	if (err == nil && stat.IsDir()) || os.IsExist(err) {
 		return true
@@ -263,24 +1052,52 @@ func IsOperatorAnsible() bool {
 
 // IsOperatorHelm returns true when the layout field in PROJECT file has the Helm prefix key.
 func IsOperatorHelm() bool {
+	isHelm, err := IsOperatorHelmErr()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return isHelm
+}
+
+// IsOperatorHelmErr is like IsOperatorHelm, but returns an error instead of
+// exiting if the PROJECT file cannot be read.
+func IsOperatorHelmErr() (bool, error) {
 	if !kbutil.HasProjectFile() {
-		return false
+		return false, nil
 	}
-	cfg, err := kbutil.ReadConfig()
+	cfg, err := GetProjectConfig()
 	if err != nil {
-		log.Fatalf("Error reading config: %v", err)
+		return false, fmt.Errorf("error reading config: %w", err)
 	}
-	return PluginKeyToOperatorType(cfg.Layout) == OperatorTypeHelm
+	return PluginKeyToOperatorType(cfg.Layout) == OperatorTypeHelm, nil
 }
 
+// ErrNotInGopath is returned by SetWdGopath if the working directory is not in
+// any element of the GOPATH path list.
+var ErrNotInGopath = errors.New("project not in $GOPATH")
+
 // MustSetWdGopath sets GOPATH to the first element of the path list in
 // currentGopath that prefixes the wd, then returns the set path.
 // If GOPATH cannot be set, MustSetWdGopath exits.
 func MustSetWdGopath(currentGopath string) string {
+	newGopath, err := SetWdGopath(currentGopath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return newGopath
+}
+
+// SetWdGopath is like MustSetWdGopath, but returns an error instead of exiting
+// if GOPATH cannot be set. ErrNotInGopath is returned if no element of
+// currentGopath prefixes the wd.
+func SetWdGopath(currentGopath string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working directory: %w", err)
+	}
 	var (
 		newGopath   string
 		cwdInGopath bool
-		wd          = MustGetwd()
 	)
 	for _, newGopath = range filepath.SplitList(currentGopath) {
 		if strings.HasPrefix(filepath.Dir(wd), newGopath) {
@@ -289,56 +1106,309 @@ func MustSetWdGopath(currentGopath string) string {
 		}
 	}
 	if !cwdInGopath {
-		log.Fatalf("Project not in $GOPATH")
+		return "", ErrNotInGopath
 	}
 	if err := os.Setenv(GoPathEnv, newGopath); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	return newGopath
+	return newGopath, nil
 }
 
-var flagRe = regexp.MustCompile("(.* )?-v(.* )?")
-
 // SetGoVerbose sets GOFLAGS="${GOFLAGS} -v" if GOFLAGS does not
 // already contain "-v" to make "go" command output verbose.
 func SetGoVerbose() error {
-	gf, ok := os.LookupEnv(GoFlagsEnv)
-	if !ok || len(gf) == 0 {
-		return os.Setenv(GoFlagsEnv, "-v")
+	return AppendGoFlag("-v")
+}
+
+// BinaryOutputPath returns the path, relative to the project root, of projectName's binary built for the
+// goos and goarch platform: build/_output/bin/<projectName>-<goos>-<goarch>, so binaries cross-compiled for
+// different platforms do not overwrite each other. An empty goos or goarch defaults to the GOOS or GOARCH
+// environment variable if set, otherwise to the platform operator-sdk is running on, like the go command.
+// 'operator-sdk build' writes legacy Go projects' binaries to this path for linux, then copies them to
+// build/_output/bin/<projectName>, the path legacy build/Dockerfiles copy.
+func BinaryOutputPath(projectName, goos, goarch string) string {
+	if goos == "" {
+		if goos = os.Getenv("GOOS"); goos == "" {
+			goos = runtime.GOOS
+		}
 	}
-	if !flagRe.MatchString(gf) {
-		return os.Setenv(GoFlagsEnv, gf+" -v")
+	if goarch == "" {
+		if goarch = os.Getenv("GOARCH"); goarch == "" {
+			goarch = runtime.GOARCH
+		}
 	}
-	return nil
+	return filepath.Join(buildBinDir, projectName+"-"+goos+"-"+goarch)
+}
+
+// UnsetGoVerbose removes every "-v" flag, ex. "-v" or "-v=true", from GOFLAGS to make "go" command output
+// non-verbose, ex. in scripts that inherit a GOFLAGS set by SetGoVerbose. Other flags, including those
+// beginning with "v" like "-vet=off", are left as-is.
+func UnsetGoVerbose() error {
+	return RemoveGoFlag("v")
+}
+
+// RemoveGoFlag removes every flag named name, ex. "v" or "mod", from GOFLAGS. GOFLAGS is unset
+// if no flags remain, and left as-is if it has no flag named name.
+func RemoveGoFlag(name string) error {
+	flags := strings.Fields(os.Getenv(GoFlagsEnv))
+	kept := make([]string, 0, len(flags))
+	for _, f := range flags {
+		fname, err := goFlagName(f)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", GoFlagsEnv, err)
+		}
+		if fname != name {
+			kept = append(kept, f)
+		}
+	}
+	switch {
+	case len(kept) == len(flags):
+		return nil
+	case len(kept) == 0:
+		return os.Unsetenv(GoFlagsEnv)
+	}
+	return os.Setenv(GoFlagsEnv, strings.Join(kept, " "))
+}
+
+// AppendGoFlag appends flag, ex. "-trimpath" or "-tags=e2e", to GOFLAGS if GOFLAGS does not
+// already contain a flag of the same name; user-set values are otherwise left as-is.
+// The exception is "-mod", since a "-mod=mod" and "-mod=vendor" in the same GOFLAGS
+// cause "go" invocation errors: an existing "-mod" value is replaced by flag's.
+func AppendGoFlag(flag string) error {
+	name, err := goFlagName(flag)
+	if err != nil {
+		return err
+	}
+	flags := strings.Fields(os.Getenv(GoFlagsEnv))
+	for i, f := range flags {
+		if fname, err := goFlagName(f); err != nil {
+			return fmt.Errorf("invalid %s: %v", GoFlagsEnv, err)
+		} else if fname == name {
+			if name != "mod" || f == flag {
+				return nil
+			}
+			flags[i] = flag
+			return os.Setenv(GoFlagsEnv, strings.Join(flags, " "))
+		}
+	}
+	return os.Setenv(GoFlagsEnv, strings.Join(append(flags, flag), " "))
+}
+
+// goFlagValue returns the value of the flag name, ex. "modfile", in GOFLAGS, and false if GOFLAGS
+// does not set it. An error is returned if GOFLAGS is malformed or the flag has no value.
+func goFlagValue(name string) (string, bool, error) {
+	for _, f := range strings.Fields(os.Getenv(GoFlagsEnv)) {
+		fname, err := goFlagName(f)
+		if err != nil {
+			return "", false, err
+		}
+		if fname == name {
+			i := strings.Index(f, "=")
+			if i == -1 {
+				return "", false, fmt.Errorf("flag %q must have a value", f)
+			}
+			return f[i+1:], true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// goFlagName returns the name of flag, i.e. the text between its leading dashes and the first "=".
+func goFlagName(flag string) (string, error) {
+	if strings.ContainsAny(flag, " \t\n") {
+		return "", fmt.Errorf("flag %q must not contain whitespace", flag)
+	}
+	name := strings.TrimLeft(flag, "-")
+	if i := strings.Index(name, "="); i != -1 {
+		name = name[:i]
+	}
+	if !strings.HasPrefix(flag, "-") || strings.HasPrefix(flag, "---") || name == "" {
+		return "", fmt.Errorf("flag %q must be of the form -name or -name=value", flag)
+	}
+	return name, nil
 }
 
 // CheckGoModules ensures that go modules are enabled.
 func CheckGoModules() error {
-	goModOn, err := GoModOn()
+	goModOn, reason, err := GoModOnWithReason()
 	if err != nil {
 		return err
 	}
 	if !goModOn {
-		return fmt.Errorf(`using go modules requires GO111MODULE="on", "auto", or unset.` +
-			` More info: https://sdk.operatorframework.io/docs/golang/quickstart/#a-note-on-dependency-management`)
+		return fmt.Errorf(`using go modules requires GO111MODULE="on", "auto", or unset, but modules are off: %s.`+
+			` More info: https://sdk.operatorframework.io/docs/golang/quickstart/#a-note-on-dependency-management`, reason)
 	}
 	return nil
 }
 
-// PrintDeprecationWarning prints a colored warning wrapping msg to the terminal.
+// WarnLevel is the severity of a warning printed by PrintWarning.
+type WarnLevel int
+
+const (
+	// WarnInfo is an informational notice.
+	WarnInfo WarnLevel = iota
+	// WarnDeprecation is a notice that a feature is deprecated.
+	WarnDeprecation
+	// WarnError is a notice of a problem that does not stop the command.
+	WarnError
+)
+
+// warnLevelFormats maps each WarnLevel to its prefix and ANSI color code.
+var warnLevelFormats = map[WarnLevel]struct{ prefix, color string }{
+	WarnInfo:        {"[Info] ", "1;34"},
+	WarnDeprecation: {"[Deprecation Notice] ", "1;36"},
+	WarnError:       {"[Error] ", "1;31"},
+}
+
+var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// PrintWarning prints a warning wrapping msg to stderr, prefixed and colored by level.
+// If stderr is not a terminal, ex. it is redirected to a file or pipe, or the NO_COLOR
+// environment variable is set, the warning is printed without color and any ANSI
+// escape codes in msg are stripped.
+func PrintWarning(level WarnLevel, msg string) {
+	printWarning(os.Stderr, level, msg, !isColorTerminal(os.Stderr))
+}
+
+// isColorTerminal returns true if f is a terminal and NO_COLOR is not set.
+func isColorTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func printWarning(w io.Writer, level WarnLevel, msg string, noColor bool) {
+	format, ok := warnLevelFormats[level]
+	if !ok {
+		format = warnLevelFormats[WarnInfo]
+	}
+	if noColor {
+		fmt.Fprint(w, format.prefix+ansiEscapeRe.ReplaceAllString(msg, "")+"\n")
+		return
+	}
+	fmt.Fprintf(w, "\033[%sm%s\033[0m", format.color, format.prefix+msg+"\n")
+}
+
+// PrintDeprecationWarning prints a warning wrapping msg to stderr, colored if it is a terminal.
+// New notices should use PrintDeprecation so they are formatted consistently.
 func PrintDeprecationWarning(msg string) {
-	fmt.Fprintf(os.Stderr, noticeColor, "[Deprecation Notice] "+msg+"\n")
+	PrintWarning(WarnDeprecation, msg)
+}
+
+// Deprecation describes a deprecated feature for PrintDeprecation.
+type Deprecation struct {
+	// Feature is the deprecated feature, which begins the notice's first sentence,
+	// ex. "The --foo flag". Required.
+	Feature string
+	// RemovedInVersion is the release the feature will be removed in, ex. "v2.0.0", if known.
+	RemovedInVersion string
+	// Replacement is what to use instead of the feature, ex. "the --bar flag", if anything.
+	Replacement string
+	// DocsURL links to documentation on migrating off of the feature, if any.
+	DocsURL string
+}
+
+// String returns the notice printed by PrintDeprecation, with one line for each of d's set fields.
+func (d Deprecation) String() string {
+	notice := d.Feature + " is deprecated"
+	if d.RemovedInVersion != "" {
+		notice += " and will be removed in " + d.RemovedInVersion
+	}
+	notice += "."
+	if d.Replacement != "" {
+		notice += "\nUse " + d.Replacement + " instead."
+	}
+	if d.DocsURL != "" {
+		notice += "\nSee " + d.DocsURL + " for migration instructions."
+	}
+	return notice
+}
+
+// PrintDeprecation prints a deprecation notice for d to stderr, like PrintDeprecationWarning.
+func PrintDeprecation(d Deprecation) {
+	PrintWarning(WarnDeprecation, d.String())
 }
 
 // RewriteFileContents adds newContent to the line after the last occurrence of target in filename's contents,
-// then writes the updated contents back to disk.
+// then writes the updated contents back to disk. newContent's line endings are converted to the file's
+// dominant line ending, so "\r\n" endings are preserved. filename is locked while it is rewritten, and
+// an error wrapping ErrFileLocked is returned if another rewrite does not release it in time.
 func RewriteFileContents(filename, target, newContent string) error {
-	text, err := ioutil.ReadFile(filename)
+	return rewriteFile(filename, target, newContent, appendContent)
+}
+
+// ErrContentAlreadyPresent is returned by RewriteFileContentsIdempotent if the
+// content to add is already present, in which case the file is not modified.
+var ErrContentAlreadyPresent = errors.New("content already present")
+
+// RewriteFileContentsIdempotent is like RewriteFileContents, but returns ErrContentAlreadyPresent without writing
+// filename if the lines after the last occurrence of target already match newContent's lines, ignoring leading
+// and trailing whitespace and blank lines. Callers re-running a scaffold can ignore ErrContentAlreadyPresent.
+func RewriteFileContentsIdempotent(filename, target, newContent string) error {
+	return rewriteFile(filename, target, newContent, appendContentIdempotent)
+}
+
+// RewriteFileContentsBefore adds newContent to the line before the first occurrence of target in filename's
+// contents, then writes the updated contents back to disk.
+func RewriteFileContentsBefore(filename, target, newContent string) error {
+	return rewriteFile(filename, target, newContent, prependContent)
+}
+
+// RewriteFileContentsDryRun is like RewriteFileContents, but returns a unified diff of the changes it would make,
+// with filename as the diff's header, instead of writing them to disk. An empty diff means filename would not change.
+func RewriteFileContentsDryRun(filename, target, newContent string) (diff string, err error) {
+	text, modifiedContent, err := insertFileContents(filename, target, newContent, appendContent)
 	if err != nil {
-		return fmt.Errorf("error in getting contents from the file, %v", err)
+		return "", err
+	}
+	return diffutil.UnifiedDiff(filename, filename, text, modifiedContent), nil
+}
+
+func rewriteFile(filename, target, newContent string, insert func(string, string, string) (string, error)) error {
+	return modifyFile(filename, func(fileContents string) (string, error) {
+		return insert(fileContents, target, newContent)
+	})
+}
+
+// Edit is an insertion made by RewriteFileContentsBatch.
+type Edit struct {
+	// Target is the string after the line of whose last occurrence NewContent is added.
+	Target string
+	// NewContent is the content to add.
+	NewContent string
+}
+
+// RewriteFileContentsBatch is like calling RewriteFileContents with each of edits, but reads and writes filename
+// once. Every Target is found in filename's original contents, not in content added by other edits, and edits
+// are applied from the bottom of the file upward so each one's insertion point stays valid. Content of edits with
+// the same target is added in the order of edits. If any edit cannot be applied, filename is not written
+// and the returned error names every missing target.
+func RewriteFileContentsBatch(filename string, edits []Edit) error {
+	return modifyFile(filename, func(fileContents string) (string, error) {
+		return appendContents(fileContents, edits)
+	})
+}
+
+// modifyFile replaces filename's contents with those returned by modify.
+func modifyFile(filename string, modify func(string) (string, error)) (err error) {
+	// Lock filename while it is read and written so concurrent rewrites, ex. by generators
+	// run in parallel, do not overwrite each other's changes.
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
 	}
+	defer func() {
+		if uerr := unlock(); uerr != nil && err == nil {
+			err = uerr
+		}
+	}()
 
-	modifiedContent, err := appendContent(string(text), target, newContent)
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("error in getting contents from the file, %v", err)
+	}
+	modifiedContent, err := modify(string(b))
 	if err != nil {
 		return err
 	}
@@ -350,6 +1420,64 @@ func RewriteFileContents(filename, target, newContent string) error {
 	return nil
 }
 
+// ErrFileLocked is returned when a file's lock is not released by another writer within fileLockTimeout.
+var ErrFileLocked = errors.New("file is locked")
+
+// fileLockTimeout is how long lockFile waits for another writer to release a file's lock.
+var fileLockTimeout = 30 * time.Second
+
+// fileLockRetryInterval is how often lockFile tries to acquire a held lock.
+const fileLockRetryInterval = 10 * time.Millisecond
+
+// lockFile acquires an advisory lock on filename by exclusively creating the lock file "<filename>.lock",
+// waiting up to fileLockTimeout for another writer to release it, and returns a function that releases it.
+// Only writers that use lockFile respect the lock.
+func lockFile(filename string) (unlock func() error, err error) {
+	lockPath := filename + ".lock"
+	deadline := time.Now().Add(fileLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, defaultPermission)
+		if err == nil {
+			// The lock is held by the file's existence, so its contents are only informational.
+			_, werr := fmt.Fprintf(f, "%d\n", os.Getpid())
+			if cerr := f.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				_ = os.Remove(lockPath)
+				return nil, fmt.Errorf("error writing lock file %s: %v", lockPath, werr)
+			}
+			return func() error {
+				if err := os.Remove(lockPath); err != nil {
+					return fmt.Errorf("error removing lock file %s: %v", lockPath, err)
+				}
+				return nil
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("error creating lock file %s: %v", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s was not released within %s; if no other operator-sdk command "+
+				"is writing %s, remove it", ErrFileLocked, lockPath, fileLockTimeout, filename)
+		}
+		time.Sleep(fileLockRetryInterval)
+	}
+}
+
+// insertFileContents returns filename's contents before and after inserting newContent relative to target with insert.
+func insertFileContents(filename, target, newContent string,
+	insert func(string, string, string) (string, error)) (text, modifiedContent string, err error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", "", fmt.Errorf("error in getting contents from the file, %v", err)
+	}
+	if modifiedContent, err = insert(string(b), target, newContent); err != nil {
+		return "", "", err
+	}
+	return string(b), modifiedContent, nil
+}
+
 func appendContent(fileContents, target, newContent string) (string, error) {
 	labelIndex := strings.LastIndex(fileContents, target)
 	if labelIndex == -1 {
@@ -362,6 +1490,119 @@ func appendContent(fileContents, target, newContent string) (string, error) {
 	}
 
 	index := labelIndex + separationIndex + 1
-	return fileContents[:index] + newContent + fileContents[index:], nil
+	return fileContents[:index] + withLineEnding(newContent, lineEnding(fileContents)) + fileContents[index:], nil
+
+}
+
+// appendContents adds each edit's NewContent to the line after the last occurrence of its Target
+// in fileContents, as described by RewriteFileContentsBatch.
+func appendContents(fileContents string, edits []Edit) (string, error) {
+	type insertion struct {
+		index   int
+		content string
+	}
+	insertions := make([]insertion, 0, len(edits))
+	var missing, unterminated []string
+	for _, edit := range edits {
+		labelIndex := strings.LastIndex(fileContents, edit.Target)
+		if labelIndex == -1 {
+			missing = append(missing, strconv.Quote(edit.Target))
+			continue
+		}
+		separationIndex := strings.Index(fileContents[labelIndex:], "\n")
+		if separationIndex == -1 {
+			unterminated = append(unterminated, strconv.Quote(edit.Target))
+			continue
+		}
+		insertions = append(insertions, insertion{labelIndex + separationIndex + 1, edit.NewContent})
+	}
+	var errs []string
+	if len(missing) != 0 {
+		errs = append(errs, "targets not found in file contents: "+strings.Join(missing, ", "))
+	}
+	if len(unterminated) != 0 {
+		errs = append(errs, "targets with no new line at the end of their line: "+strings.Join(unterminated, ", "))
+	}
+	if len(errs) != 0 {
+		return "", errors.New(strings.Join(errs, "; "))
+	}
+
+	// Content inserted at an index does not move content before it, so insert from the largest index down,
+	// concatenating content at the same index in edit order.
+	sort.SliceStable(insertions, func(i, j int) bool { return insertions[i].index > insertions[j].index })
+	ending := lineEnding(fileContents)
+	for i := 0; i < len(insertions); {
+		index, content := insertions[i].index, ""
+		for ; i < len(insertions) && insertions[i].index == index; i++ {
+			content += withLineEnding(insertions[i].content, ending)
+		}
+		fileContents = fileContents[:index] + content + fileContents[index:]
+	}
+	return fileContents, nil
+}
+
+// lineEnding returns the dominant line terminator of s, "\r\n" if most of its lines end with one
+// and "\n" otherwise.
+func lineEnding(s string) string {
+	crlf := strings.Count(s, "\r\n")
+	if lf := strings.Count(s, "\n") - crlf; crlf > lf {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// withLineEnding returns s with each of its line terminators replaced by ending.
+func withLineEnding(s, ending string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if ending == "\n" {
+		return s
+	}
+	return strings.ReplaceAll(s, "\n", ending)
+}
+
+func appendContentIdempotent(fileContents, target, newContent string) (string, error) {
+	labelIndex := strings.LastIndex(fileContents, target)
+	if labelIndex != -1 {
+		if separationIndex := strings.Index(fileContents[labelIndex:], "\n"); separationIndex != -1 {
+			following := contentLines(fileContents[labelIndex+separationIndex+1:])
+			added := contentLines(newContent)
+			if len(added) <= len(following) && equalLines(added, following[:len(added)]) {
+				return "", ErrContentAlreadyPresent
+			}
+		}
+	}
+	return appendContent(fileContents, target, newContent)
+}
+
+// contentLines returns the lines of s trimmed of surrounding whitespace, without blank lines.
+func contentLines(s string) (lines []string) {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func prependContent(fileContents, target, newContent string) (string, error) {
+	labelIndex := strings.Index(fileContents, target)
+	if labelIndex == -1 {
+		return "", fmt.Errorf("no string %s in file contents", target)
+	}
 
+	// Insert at the start of the target's line, or of the file if it is the first line.
+	index := strings.LastIndex(fileContents[:labelIndex], "\n") + 1
+	return fileContents[:index] + withLineEnding(newContent, lineEnding(fileContents)) + fileContents[index:], nil
 }
@@ -15,14 +15,45 @@
 package projutil
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
+// realGetGoVersion is getGoVersion before any spec stubs it.
+var realGetGoVersion = getGoVersion
+
 var _ = Describe("Testing projutil helpers", func() {
+	// Specs write different PROJECT files, so none may see another's cached config.
+	BeforeEach(ResetProjectConfigCache)
+
+	// A -modfile in the caller's GOFLAGS would change which module file helpers read,
+	// and a caller's project type override would change which type helpers detect.
+	var goflags, projectType string
+	BeforeEach(func() {
+		goflags = os.Getenv(GoFlagsEnv)
+		Expect(os.Unsetenv(GoFlagsEnv)).To(Succeed())
+		projectType = os.Getenv(ProjectTypeEnv)
+		Expect(os.Unsetenv(ProjectTypeEnv)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.Setenv(GoFlagsEnv, goflags)).To(Succeed())
+		Expect(os.Setenv(ProjectTypeEnv, projectType)).To(Succeed())
+	})
+
 	Describe("Testing RewriteFileContents", func() {
 		var (
 			fileContents   string
@@ -89,6 +120,1561 @@ var _ = Describe("Testing projutil helpers", func() {
 			Expect(err).ShouldNot((BeNil()))
 		})
 
+		It("Should insert content with the file's CRLF line endings", func() {
+			fileContents = "resources:\r\n- manager.yaml\r\n- service.yaml\r\n"
+			Expect(appendContent(fileContents, "- manager.yaml", "- monitor.yaml\n- role.yaml\n")).To(Equal(
+				"resources:\r\n- manager.yaml\r\n- monitor.yaml\r\n- role.yaml\r\n- service.yaml\r\n"))
+		})
+
+		It("Should insert content with the dominant line ending of a file with mixed endings", func() {
+			fileContents = "resources:\r\n- manager.yaml\r\n- service.yaml\n- role.yaml\r\n"
+			Expect(appendContent(fileContents, "resources:", "- monitor.yaml\n")).To(Equal(
+				"resources:\r\n- monitor.yaml\r\n- manager.yaml\r\n- service.yaml\n- role.yaml\r\n"))
+
+			fileContents = "resources:\n- manager.yaml\n- service.yaml\r\n"
+			Expect(appendContent(fileContents, "resources:", "- monitor.yaml\r\n")).To(Equal(
+				"resources:\n- monitor.yaml\n- manager.yaml\n- service.yaml\r\n"))
+		})
+
+		It("Should insert content in a file ending without a trailing newline", func() {
+			fileContents = "resources:\r\n- manager.yaml\r\n- service.yaml"
+			Expect(appendContent(fileContents, "- manager.yaml", "- monitor.yaml\n")).To(Equal(
+				"resources:\r\n- manager.yaml\r\n- monitor.yaml\r\n- service.yaml"))
+
+			_, err := appendContent(fileContents, "- service.yaml", "- monitor.yaml\n")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		Context("with concurrent writers", func() {
+			var filename string
+			BeforeEach(func() {
+				dir, err := ioutil.TempDir("", "projutil-rewrite")
+				Expect(err).NotTo(HaveOccurred())
+				filename = filepath.Join(dir, "kustomization.yaml")
+				Expect(ioutil.WriteFile(filename, []byte("resources:\n"), 0644)).To(Succeed())
+			})
+			AfterEach(func() {
+				Expect(os.RemoveAll(filepath.Dir(filename))).To(Succeed())
+			})
+
+			It("Should apply every rewrite and release the lock", func() {
+				const writers = 20
+				var wg sync.WaitGroup
+				errs := make(chan error, writers)
+				for i := 0; i < writers; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						errs <- RewriteFileContents(filename, "resources:", fmt.Sprintf("- res-%d.yaml\n", i))
+					}(i)
+				}
+				wg.Wait()
+				close(errs)
+				for err := range errs {
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				b, err := ioutil.ReadFile(filename)
+				Expect(err).NotTo(HaveOccurred())
+				for i := 0; i < writers; i++ {
+					Expect(strings.Count(string(b), fmt.Sprintf("- res-%d.yaml\n", i))).To(Equal(1))
+				}
+				Expect(filename + ".lock").NotTo(BeAnExistingFile())
+			})
+
+			It("Should return ErrFileLocked if the lock is not released in time", func() {
+				Expect(ioutil.WriteFile(filename+".lock", []byte("1\n"), 0644)).To(Succeed())
+				defer func(timeout time.Duration) { fileLockTimeout = timeout }(fileLockTimeout)
+				fileLockTimeout = 50 * time.Millisecond
+
+				err := RewriteFileContents(filename, "resources:", "- res.yaml\n")
+				Expect(errors.Is(err, ErrFileLocked)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring(filename + ".lock"))
+
+				b, err := ioutil.ReadFile(filename)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(b)).To(Equal("resources:\n"))
+			})
+
+			It("Should release the lock when the rewrite fails", func() {
+				Expect(RewriteFileContents(filename, "images:", "- res.yaml\n")).To(HaveOccurred())
+				Expect(filename + ".lock").NotTo(BeAnExistingFile())
+				Expect(RewriteFileContents(filename, "resources:", "- res.yaml\n")).To(Succeed())
+			})
+		})
+	})
+	Describe("Testing RewriteFileContentsBatch", func() {
+		const fileContents = "resources:\n- manager.yaml\npatchesStrategicMerge:\n- manager_patch.yaml\n"
+
+		It("Should add each edit's content after its target's line", func() {
+			Expect(appendContents(fileContents, []Edit{
+				{Target: "resources:", NewContent: "- monitor.yaml\n"},
+				{Target: "- manager_patch.yaml", NewContent: "- replicas_patch.yaml\n"},
+				{Target: "- manager.yaml", NewContent: "- role.yaml\n"},
+			})).To(Equal("resources:\n- monitor.yaml\n- manager.yaml\n- role.yaml\npatchesStrategicMerge:\n" +
+				"- manager_patch.yaml\n- replicas_patch.yaml\n"))
+		})
+		It("Should add content with the same target in edit order", func() {
+			Expect(appendContents(fileContents, []Edit{
+				{Target: "resources:", NewContent: "- monitor.yaml\n"},
+				{Target: "resources:", NewContent: "- role.yaml\n"},
+			})).To(Equal("resources:\n- monitor.yaml\n- role.yaml\n- manager.yaml\npatchesStrategicMerge:\n" +
+				"- manager_patch.yaml\n"))
+		})
+		It("Should find targets in the original contents only", func() {
+			Expect(appendContents(fileContents, []Edit{
+				{Target: "resources:", NewContent: "- manager.yaml\n"},
+				{Target: "- manager.yaml", NewContent: "- role.yaml\n"},
+			})).To(Equal("resources:\n- manager.yaml\n- manager.yaml\n- role.yaml\npatchesStrategicMerge:\n" +
+				"- manager_patch.yaml\n"))
+		})
+		It("Should insert content with the file's CRLF line endings", func() {
+			Expect(appendContents("resources:\r\n- manager.yaml\r\n", []Edit{
+				{Target: "resources:", NewContent: "- monitor.yaml\n"},
+				{Target: "- manager.yaml", NewContent: "- role.yaml\n"},
+			})).To(Equal("resources:\r\n- monitor.yaml\r\n- manager.yaml\r\n- role.yaml\r\n"))
+		})
+		It("Should name every target that cannot be found", func() {
+			_, err := appendContents("resources:\n- manager.yaml", []Edit{
+				{Target: "images:", NewContent: "- name: controller\n"},
+				{Target: "resources:", NewContent: "- monitor.yaml\n"},
+				{Target: "- manager.yaml", NewContent: "- role.yaml\n"},
+				{Target: "vars:", NewContent: "- name: NAMESPACE\n"},
+			})
+			Expect(err).To(MatchError(`targets not found in file contents: "images:", "vars:"; ` +
+				`targets with no new line at the end of their line: "- manager.yaml"`))
+		})
+		It("Should write the file once and not at all on error", func() {
+			dir, err := ioutil.TempDir("", "projutil-rewrite")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { Expect(os.RemoveAll(dir)).To(Succeed()) }()
+			filename := filepath.Join(dir, "kustomization.yaml")
+			Expect(ioutil.WriteFile(filename, []byte(fileContents), 0644)).To(Succeed())
+
+			Expect(RewriteFileContentsBatch(filename, []Edit{
+				{Target: "resources:", NewContent: "- monitor.yaml\n"},
+				{Target: "images:", NewContent: "- name: controller\n"},
+			})).To(MatchError(ContainSubstring(`"images:"`)))
+			b, err := ioutil.ReadFile(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(fileContents))
+
+			Expect(RewriteFileContentsBatch(filename, []Edit{
+				{Target: "resources:", NewContent: "- monitor.yaml\n"},
+				{Target: "patchesStrategicMerge:", NewContent: "- replicas_patch.yaml\n"},
+			})).To(Succeed())
+			b, err = ioutil.ReadFile(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("resources:\n- monitor.yaml\n- manager.yaml\npatchesStrategicMerge:\n" +
+				"- replicas_patch.yaml\n- manager_patch.yaml\n"))
+			Expect(filename + ".lock").NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("Testing RewriteFileContentsIdempotent", func() {
+		const fileContents = "import (\n" +
+			"\t\"fmt\"\n" +
+			"\n" +
+			"\t\"github.com/example/app/api/v1\"\n" +
+			")\n"
+
+		It("Should add content that is not present after the target", func() {
+			Expect(appendContentIdempotent(fileContents, "\"fmt\"", "\t\"os\"\n")).To(Equal(
+				"import (\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/example/app/api/v1\"\n)\n"))
+		})
+		It("Should return ErrContentAlreadyPresent for content present after the target, ignoring whitespace", func() {
+			for _, content := range []string{
+				"\n\t\"github.com/example/app/api/v1\"\n",
+				"    \"github.com/example/app/api/v1\"   \n)\n",
+			} {
+				_, err := appendContentIdempotent(fileContents, "\"fmt\"", content)
+				Expect(err).To(Equal(ErrContentAlreadyPresent))
+			}
+		})
+		It("Should add content present elsewhere in the file", func() {
+			Expect(appendContentIdempotent(fileContents, "import (", "\t\"github.com/example/app/api/v1\"\n")).To(Equal(
+				"import (\n\t\"github.com/example/app/api/v1\"\n\t\"fmt\"\n\n\t\"github.com/example/app/api/v1\"\n)\n"))
+		})
+		It("Should result in error when file does not have the target", func() {
+			_, err := appendContentIdempotent(fileContents, "\"os\"", "\t\"io\"\n")
+			Expect(err).To(MatchError(errors.New("no prior string \"os\" in newContent")))
+		})
+		It("Should not modify the file when re-run", func() {
+			dir, err := ioutil.TempDir("", "rewrite-")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "kustomization.yaml")
+			Expect(ioutil.WriteFile(path, []byte("resources:\n- manager.yaml\n"), 0644)).To(Succeed())
+
+			Expect(RewriteFileContentsIdempotent(path, "resources:", "- monitor.yaml\n")).To(Succeed())
+			err = RewriteFileContentsIdempotent(path, "resources:", "- monitor.yaml\n")
+			Expect(errors.Is(err, ErrContentAlreadyPresent)).To(BeTrue())
+			b, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("resources:\n- monitor.yaml\n- manager.yaml\n"))
+		})
+	})
+	Describe("Testing RewriteFileContentsDryRun", func() {
+		var path string
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "rewrite-")
+			Expect(err).NotTo(HaveOccurred())
+			path = filepath.Join(dir, "kustomization.yaml")
+			Expect(ioutil.WriteFile(path, []byte("resources:\n- manager.yaml\n"), 0644)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(filepath.Dir(path))).To(Succeed())
+		})
+
+		It("Should return a diff headed by the file path without writing the file", func() {
+			diff, err := RewriteFileContentsDryRun(path, "resources:", "- monitor.yaml\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(Equal("--- " + path + "\n+++ " + path + "\n" +
+				"@@ -1,2 +1,3 @@\n resources:\n+- monitor.yaml\n - manager.yaml\n"))
+			b, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("resources:\n- manager.yaml\n"))
+		})
+		It("Should return an empty diff if the file would not change", func() {
+			Expect(RewriteFileContentsDryRun(path, "resources:", "")).To(BeEmpty())
+		})
+		It("Should result in error when file does not have the target", func() {
+			_, err := RewriteFileContentsDryRun(path, "patches:", "- manager_patch.yaml\n")
+			Expect(err).To(MatchError(errors.New("no prior string patches: in newContent")))
+		})
+	})
+	Describe("Testing RewriteFileContentsBefore", func() {
+		const fileContents = "resources:\n" +
+			"- manager.yaml\n" +
+			"# +kubebuilder:scaffold:resources\n" +
+			"- service.yaml\n" +
+			"# +kubebuilder:scaffold:resources\n"
+
+		It("Should insert on the line before the first occurrence of the target", func() {
+			Expect(prependContent(fileContents, "# +kubebuilder:scaffold:resources", "- monitor.yaml\n")).To(Equal(
+				"resources:\n" +
+					"- manager.yaml\n" +
+					"- monitor.yaml\n" +
+					"# +kubebuilder:scaffold:resources\n" +
+					"- service.yaml\n" +
+					"# +kubebuilder:scaffold:resources\n"))
+		})
+		It("Should insert before a target in the middle of a line", func() {
+			Expect(prependContent("import (\n\t\"fmt\"\n)\n", "\"fmt\"", "\t\"errors\"\n")).To(Equal(
+				"import (\n\t\"errors\"\n\t\"fmt\"\n)\n"))
+		})
+		It("Should insert at the start of the file when the target is on the first line", func() {
+			Expect(prependContent(fileContents, "resources:", "namePrefix: memcached-\n")).To(Equal(
+				"namePrefix: memcached-\n" + fileContents))
+		})
+		It("Should result in error when file does not have the target", func() {
+			_, err := prependContent(fileContents, "patchesStrategicMerge:", "- patch.yaml\n")
+			Expect(err).Should(MatchError(errors.New("no string patchesStrategicMerge: in file contents")))
+		})
+		It("Should insert content with the file's CRLF line endings", func() {
+			Expect(prependContent("resources:\r\n- manager.yaml\r\n", "- manager.yaml", "- monitor.yaml\n")).To(Equal(
+				"resources:\r\n- monitor.yaml\r\n- manager.yaml\r\n"))
+		})
+		It("Should rewrite the file", func() {
+			dir, err := ioutil.TempDir("", "rewrite-")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "kustomization.yaml")
+			Expect(ioutil.WriteFile(path, []byte(fileContents), 0644)).To(Succeed())
+
+			Expect(RewriteFileContentsBefore(path, "resources:", "namePrefix: memcached-\n")).To(Succeed())
+			b, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("namePrefix: memcached-\n" + fileContents))
+			Expect(RewriteFileContentsBefore(filepath.Join(dir, "missing.yaml"), "resources:", "")).NotTo(Succeed())
+		})
+	})
+	Describe("printWarning", func() {
+		var buf *bytes.Buffer
+
+		BeforeEach(func() {
+			buf = &bytes.Buffer{}
+		})
+
+		It("prefixes and colors the message by level", func() {
+			printWarning(buf, WarnInfo, "info", false)
+			printWarning(buf, WarnDeprecation, "deprecated", false)
+			printWarning(buf, WarnError, "error", false)
+			Expect(buf.String()).To(Equal("\033[1;34m[Info] info\n\033[0m" +
+				"\033[1;36m[Deprecation Notice] deprecated\n\033[0m" +
+				"\033[1;31m[Error] error\n\033[0m"))
+		})
+		It("strips color when NO_COLOR is set", func() {
+			printWarning(buf, WarnError, "\033[1mbold\033[0m error", true)
+			Expect(buf.String()).To(Equal("[Error] bold error\n"))
+		})
+		It("prints an unknown level as info", func() {
+			printWarning(buf, WarnLevel(42), "info", true)
+			Expect(buf.String()).To(Equal("[Info] info\n"))
+		})
+		It("prints plain text to a stderr that is not a terminal", func() {
+			r, w, err := os.Pipe()
+			Expect(err).NotTo(HaveOccurred())
+			stderr := os.Stderr
+			defer func() { os.Stderr = stderr }()
+			os.Stderr = w
+			PrintDeprecationWarning("use the new layout")
+			Expect(w.Close()).To(Succeed())
+
+			out, err := ioutil.ReadAll(r)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal("[Deprecation Notice] use the new layout\n"))
+			Expect(string(out)).NotTo(ContainSubstring("\x1b"))
+		})
+	})
+
+	Describe("Deprecation", func() {
+		It("renders a line for each set field", func() {
+			Expect(Deprecation{
+				Feature:          "The --foo flag",
+				RemovedInVersion: "v2.0.0",
+				Replacement:      "the --bar flag",
+				DocsURL:          "https://sdk.operatorframework.io/docs/upgrading-sdk-version/",
+			}.String()).To(Equal("The --foo flag is deprecated and will be removed in v2.0.0.\n" +
+				"Use the --bar flag instead.\n" +
+				"See https://sdk.operatorframework.io/docs/upgrading-sdk-version/ for migration instructions."))
+			Expect(Deprecation{Feature: "The --foo flag"}.String()).To(Equal("The --foo flag is deprecated."))
+			Expect(Deprecation{Feature: "The --foo flag", Replacement: "the --bar flag"}.String()).To(Equal(
+				"The --foo flag is deprecated.\nUse the --bar flag instead."))
+		})
+		It("is printed as a deprecation notice", func() {
+			r, w, err := os.Pipe()
+			Expect(err).NotTo(HaveOccurred())
+			stderr := os.Stderr
+			defer func() { os.Stderr = stderr }()
+			os.Stderr = w
+			PrintDeprecation(Deprecation{Feature: "The --foo flag", RemovedInVersion: "v2.0.0"})
+			Expect(w.Close()).To(Succeed())
+
+			out, err := ioutil.ReadAll(r)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal("[Deprecation Notice] The --foo flag is deprecated and will be removed in v2.0.0.\n"))
+		})
+	})
+
+	Describe("GoModOnWithReason", func() {
+		var wd, goPath string
+		var env map[string]*string
+
+		setEnv := func(key, value string) {
+			if _, saved := env[key]; !saved {
+				if v, ok := os.LookupEnv(key); ok {
+					env[key] = &v
+				} else {
+					env[key] = nil
+				}
+			}
+			Expect(os.Setenv(key, value)).To(Succeed())
+		}
+		stubGoVersion := func(v string) {
+			getGoVersion = func() (string, error) { return v, nil }
+		}
+
+		BeforeEach(func() {
+			env = map[string]*string{}
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			goPath, err = ioutil.TempDir("", "gopath-")
+			Expect(err).NotTo(HaveOccurred())
+			src := filepath.Join(goPath, "src", "example.com", "memcached-operator")
+			Expect(os.MkdirAll(src, 0755)).To(Succeed())
+			Expect(os.Chdir(src)).To(Succeed())
+			setEnv(GoPathEnv, goPath)
+		})
+		AfterEach(func() {
+			stubGoVersion("go1.13")
+			for key, v := range env {
+				if v == nil {
+					Expect(os.Unsetenv(key)).To(Succeed())
+				} else {
+					Expect(os.Setenv(key, *v)).To(Succeed())
+				}
+			}
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(goPath)).To(Succeed())
+		})
+
+		It("reports GO111MODULE=off", func() {
+			setEnv(GoModEnv, "off")
+			on, reason, err := GoModOnWithReason()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(on).To(BeFalse())
+			Expect(reason).To(Equal("GO111MODULE=off is set"))
+			Expect(CheckGoModules()).To(MatchError(ContainSubstring("modules are off: GO111MODULE=off is set.")))
+		})
+		It("reports a project in $GOPATH/src with a toolchain older than go1.13", func() {
+			setEnv(GoModEnv, "auto")
+			stubGoVersion("go1.12.17")
+			on, reason, err := GoModOnWithReason()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(on).To(BeFalse())
+			Expect(reason).To(Equal("GO111MODULE=auto and the project is in $GOPATH/src, where go1.12.17 does not enable modules"))
+			Expect(GoModOn()).To(BeFalse())
+		})
+		It("turns modules on otherwise", func() {
+			setEnv(GoModEnv, "auto")
+			stubGoVersion("go1.13.4")
+			Expect(GoModOn()).To(BeTrue())
+			stubGoVersion("devel +a1b2c3d")
+			Expect(GoModOn()).To(BeTrue())
+
+			stubGoVersion("go1.12.17")
+			setEnv(GoModEnv, "on")
+			on, reason, err := GoModOnWithReason()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(on).To(BeTrue())
+			Expect(reason).To(BeEmpty())
+
+			setEnv(GoModEnv, "auto")
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(GoModOn()).To(BeTrue())
+			Expect(CheckGoModules()).To(Succeed())
+		})
+		It("returns an error for an unknown GO111MODULE value", func() {
+			setEnv(GoModEnv, "maybe")
+			_, err := GoModOn()
+			Expect(err).To(MatchError("unknown environment setting GO111MODULE=maybe"))
+		})
+	})
+
+	Describe("GoToolchainVersion", func() {
+		var saved func() (string, error)
+
+		BeforeEach(func() {
+			saved = getGoVersion
+			goToolchainVersion = ""
+		})
+		AfterEach(func() {
+			getGoVersion = saved
+			goToolchainVersion = ""
+		})
+
+		DescribeTable("parses the version of the go toolchain",
+			func(goVersion, expected string) {
+				getGoVersion = func() (string, error) { return goVersion, nil }
+				Expect(GoToolchainVersion()).To(Equal(expected))
+			},
+			Entry("release", "go1.15.2", "1.15.2"),
+			Entry("first release of a minor version", "go1.15", "1.15.0"),
+			Entry("release candidate", "go1.21rc1", "1.21.0-rc1"),
+			Entry("beta", "go1.16beta1", "1.16.0-beta1"),
+			Entry("development build", "devel go1.16-a1b2c3d", "1.16.0-devel"),
+		)
+		It("caches the version", func() {
+			calls := 0
+			getGoVersion = func() (string, error) { calls++; return "go1.15.2", nil }
+			Expect(GoToolchainVersion()).To(Equal("1.15.2"))
+			Expect(GoToolchainVersion()).To(Equal("1.15.2"))
+			Expect(calls).To(Equal(1))
+		})
+		It("returns an error for an unparseable version", func() {
+			getGoVersion = func() (string, error) { return "devel +a1b2c3d", nil }
+			_, err := GoToolchainVersion()
+			Expect(err).To(MatchError(`cannot parse go version "devel +a1b2c3d"`))
+		})
+		It("returns an error if go is not in $PATH", func() {
+			getGoVersion = realGetGoVersion
+			dir, err := ioutil.TempDir("", "path-")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { Expect(os.RemoveAll(dir)).To(Succeed()) }()
+			path := os.Getenv("PATH")
+			defer func() { Expect(os.Setenv("PATH", path)).To(Succeed()) }()
+			Expect(os.Setenv("PATH", dir)).To(Succeed())
+
+			_, err = GoToolchainVersion()
+			Expect(err).To(MatchError(ContainSubstring(`"go" is not in $PATH`)))
+		})
+		It("returns the version of the go toolchain in $PATH", func() {
+			getGoVersion = realGetGoVersion
+			version, err := GoToolchainVersion()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(MatchRegexp(`^\d+\.\d+\.\d+(-.+)?$`))
+		})
+	})
+
+	Describe("ValidateGoPkg", func() {
+		It("accepts valid module paths", func() {
+			for _, pkg := range []string{
+				"github.com/example/app-operator",
+				"github.com/example/App_Operator/v2",
+				"example.com/app",
+				"app-operator",
+			} {
+				Expect(ValidateGoPkg(pkg)).To(Succeed(), pkg)
+			}
+		})
+		It("names the offending path element", func() {
+			for pkg, elem := range map[string]string{
+				"GitHub.com/example/app-operator":   "GitHub.com",
+				"example.com/app-operator/v1":       "v1",
+				"github.com/example/app operator/x": "app operator",
+				"github.com/example/.app":           ".app",
+			} {
+				Expect(ValidateGoPkg(pkg)).To(MatchError(ContainSubstring(fmt.Sprintf("element %q", elem))), pkg)
+			}
+		})
+	})
+	Describe("CheckProjectRoot", func() {
+		var wd, dir string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			dir, err = ioutil.TempDir("", "project-root-check-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(dir)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		DescribeTable("checks the current directory for project markers",
+			func(files, dirs []string, isRoot bool) {
+				for _, d := range dirs {
+					Expect(os.MkdirAll(d, 0755)).To(Succeed())
+				}
+				for _, f := range files {
+					Expect(os.MkdirAll(filepath.Dir(f), 0755)).To(Succeed())
+					Expect(ioutil.WriteFile(f, []byte{}, 0644)).To(Succeed())
+				}
+				if err := CheckProjectRoot(); isRoot {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(MatchError("must run command in project root dir: project structure requires " +
+						filepath.Join("build", "Dockerfile")))
+				}
+			},
+			Entry("PROJECT file", []string{"PROJECT"}, nil, true),
+			Entry("legacy build/Dockerfile", []string{filepath.Join("build", "Dockerfile")}, nil, true),
+			Entry("legacy Ansible roles", nil, []string{"roles"}, true),
+			Entry("legacy Ansible molecule", nil, []string{"molecule"}, true),
+			Entry("legacy Ansible requirements.yml", []string{"requirements.yml"}, nil, true),
+			Entry("legacy Ansible roles with a Dockerfile", []string{filepath.Join("build", "Dockerfile")},
+				[]string{"roles"}, true),
+			Entry("legacy Helm watches.yaml", []string{"watches.yaml"}, nil, true),
+			Entry("legacy Helm watches.yaml with charts", []string{"watches.yaml"}, []string{"helm-charts"}, true),
+			Entry("no markers", nil, nil, false),
+			Entry("unrelated files", []string{"README.md", filepath.Join("deploy", "operator.yaml")}, nil, false),
+			Entry("watches.yaml directory", nil, []string{"watches.yaml"}, false),
+		)
+	})
+	Describe("GetOperatorTypeErr", func() {
+		var wd, dir string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			dir, err = ioutil.TempDir("", "operator-type-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(dir)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		writeFile := func(path, contents string) {
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		}
+		isUnknown := func(err error) bool {
+			return errors.As(err, &ErrUnknownOperatorType{})
+		}
+
+		It("detects the type from the PROJECT file layout", func() {
+			writeFile("PROJECT", "version: 3-alpha\nlayout: helm.sdk.operatorframework.io/v1\n")
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeHelm))
+			Expect(GetOperatorType()).To(Equal(OperatorTypeHelm))
+		})
+		It("detects a Go project from a version 2 PROJECT file without a layout", func() {
+			writeFile("PROJECT", "version: \"2\"\ndomain: example.com\n")
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeGo))
+		})
+		It("returns ErrUnknownOperatorType for a PROJECT file with an empty layout", func() {
+			writeFile("PROJECT", "version: 3-alpha\nlayout: \"\"\n")
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).To(HaveOccurred())
+			Expect(isUnknown(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("no layout"))
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+			Expect(GetOperatorType()).To(Equal(OperatorTypeUnknown))
+		})
+		It("returns ErrUnknownOperatorType with the layout for an unknown plugin key", func() {
+			writeFile("PROJECT", "version: 3-alpha\nlayout: java.example.com/v1\n")
+			_, err := GetOperatorTypeErr()
+			Expect(err).To(Equal(ErrUnknownOperatorType{Type: "java.example.com/v1"}))
+		})
+		It("returns the config error for a corrupt PROJECT file", func() {
+			writeFile("PROJECT", "version: [3-alpha\n")
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).To(HaveOccurred())
+			Expect(isUnknown(err)).To(BeFalse())
+			Expect(err.Error()).To(ContainSubstring("error reading config"))
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+		})
+		It("reads the PROJECT file once until the cache is reset", func() {
+			writeFile("PROJECT", "version: 3-alpha\nlayout: helm.sdk.operatorframework.io/v1\n")
+			cfg, err := GetProjectConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Layout).To(Equal("helm.sdk.operatorframework.io/v1"))
+			Expect(IsOperatorHelm()).To(BeTrue())
+
+			writeFile("PROJECT", "version: 3-alpha\nlayout: ansible.sdk.operatorframework.io/v1\n")
+			cached, err := GetProjectConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cached).To(BeIdenticalTo(cfg))
+			Expect(IsOperatorHelm()).To(BeTrue())
+			Expect(IsOperatorAnsible()).To(BeFalse())
+			Expect(GetOperatorType()).To(Equal(OperatorTypeHelm))
+
+			ResetProjectConfigCache()
+			Expect(IsOperatorAnsible()).To(BeTrue())
+			Expect(IsOperatorHelm()).To(BeFalse())
+			Expect(IsOperatorGo()).To(BeFalse())
+		})
+		It("caches PROJECT file read errors", func() {
+			writeFile("PROJECT", "version: [3-alpha\n")
+			_, err := GetProjectConfig()
+			Expect(err).To(HaveOccurred())
+
+			writeFile("PROJECT", "version: 3-alpha\nlayout: go.kubebuilder.io/v2\n")
+			_, err = IsOperatorGoErr()
+			Expect(err).To(MatchError(ContainSubstring("error reading config")))
+
+			ResetProjectConfigCache()
+			Expect(IsOperatorGoErr()).To(BeTrue())
+		})
+		It("detects a legacy Ansible project with both build/Dockerfile and roles", func() {
+			writeFile(filepath.Join("build", "Dockerfile"), "FROM quay.io/operator-framework/ansible-operator\n")
+			Expect(os.Mkdir("roles", 0755)).To(Succeed())
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeAnsible))
+		})
+		It("detects a legacy Go project", func() {
+			writeFile(filepath.Join("build", "Dockerfile"), "FROM registry.access.redhat.com/ubi8/ubi-minimal\n")
+			writeFile(filepath.Join("cmd", "manager", "main.go"), "package main\n")
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeGo))
+		})
+		It("returns the type set by the project type environment variable without detecting it", func() {
+			writeFile("PROJECT", "version: 3-alpha\nlayout: helm.sdk.operatorframework.io/v1\n")
+			Expect(os.Setenv(ProjectTypeEnv, " Ansible ")).To(Succeed())
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeAnsible))
+
+			Expect(os.Remove("PROJECT")).To(Succeed())
+			Expect(os.Setenv(ProjectTypeEnv, "go")).To(Succeed())
+			Expect(GetOperatorType()).To(Equal(OperatorTypeGo))
+		})
+		It("detects the project's type regardless of the project type environment variable", func() {
+			Expect(os.Setenv(ProjectTypeEnv, "go")).To(Succeed())
+			operatorType, err := DetectOperatorType()
+			Expect(isUnknown(err)).To(BeTrue())
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+
+			writeFile(filepath.Join("build", "Dockerfile"), "FROM quay.io/operator-framework/ansible-operator\n")
+			Expect(os.Mkdir("roles", 0755)).To(Succeed())
+			operatorType, err = DetectOperatorType()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeAnsible))
+		})
+		It("returns ErrUnknownOperatorType for an invalid project type environment variable", func() {
+			writeFile("PROJECT", "version: 3-alpha\nlayout: helm.sdk.operatorframework.io/v1\n")
+			Expect(os.Setenv(ProjectTypeEnv, "java")).To(Succeed())
+			operatorType, err := GetOperatorTypeErr()
+			Expect(err).To(Equal(fmt.Errorf("invalid %s: %w", ProjectTypeEnv, ErrUnknownOperatorType{Type: "java"})))
+			Expect(isUnknown(err)).To(BeTrue())
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+			Expect(GetOperatorType()).To(Equal(OperatorTypeUnknown))
+		})
+		It("returns ErrUnknownOperatorType outside of a project", func() {
+			writeFile(filepath.Join("build", "Dockerfile"), "FROM scratch\n")
+			operatorType, err := GetOperatorTypeErr()
+			Expect(isUnknown(err)).To(BeTrue())
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+			Expect(GetOperatorType()).To(Equal(OperatorTypeUnknown))
+		})
+		DescribeTable("distinguishes legacy and kubebuilder Go projects",
+			func(files map[string]string, isLegacy, isKubebuilder bool) {
+				for path, contents := range files {
+					writeFile(path, contents)
+				}
+				Expect(IsOperatorGoLegacy()).To(Equal(isLegacy))
+				Expect(IsOperatorGoKubebuilder()).To(Equal(isKubebuilder))
+				Expect(IsOperatorGo()).To(Equal(isLegacy || isKubebuilder))
+			},
+			Entry("kubebuilder Go layout", map[string]string{
+				"PROJECT": "version: 3-alpha\nlayout: go.kubebuilder.io/v2\n",
+				"main.go": "package main\n",
+			}, false, true),
+			Entry("project version 2", map[string]string{
+				"PROJECT": "version: \"2\"\ndomain: example.com\n",
+				"main.go": "package main\n",
+			}, false, true),
+			Entry("legacy cmd/manager layout", map[string]string{
+				filepath.Join("build", "Dockerfile"):       "FROM registry.access.redhat.com/ubi8/ubi-minimal\n",
+				filepath.Join("cmd", "manager", "main.go"): "package main\n",
+			}, true, false),
+			Entry("legacy layout with a root main.go", map[string]string{
+				filepath.Join("build", "Dockerfile"): "FROM registry.access.redhat.com/ubi8/ubi-minimal\n",
+				"main.go":                            "package main\n",
+			}, true, false),
+			Entry("kubebuilder Ansible layout", map[string]string{
+				"PROJECT": "version: 3-alpha\nlayout: ansible.sdk.operatorframework.io/v1\n",
+				"main.go": "package main\n",
+			}, false, false),
+			Entry("legacy Ansible layout", map[string]string{
+				filepath.Join("build", "Dockerfile"): "FROM quay.io/operator-framework/ansible-operator\n",
+				filepath.Join("roles", "README.md"):  "",
+			}, false, false),
+		)
+		It("checks the project root and its operator type with CheckProjectRootWithType", func() {
+			Expect(CheckProjectRootWithType(OperatorTypeGo)).To(MatchError(ContainSubstring("must run command in project root dir")))
+
+			writeFile("PROJECT", "version: 3-alpha\nlayout: ansible.sdk.operatorframework.io/v1\n")
+			Expect(CheckProjectRootWithType(OperatorTypeAnsible)).To(Succeed())
+			err := CheckProjectRootWithType(OperatorTypeGo)
+			Expect(err).To(MatchError("this command only supports Go projects but detected ansible"))
+			Expect(errors.As(err, &ErrWrongOperatorType{})).To(BeTrue())
+		})
+		It("returns ErrWrongOperatorType from CheckProjectRootWithType if the type cannot be detected", func() {
+			writeFile("PROJECT", "version: 3-alpha\nlayout: java.example.com/v1\n")
+			Expect(CheckProjectRootWithType(OperatorTypeHelm)).To(
+				MatchError("this command only supports Helm projects but the project type could not be detected"))
+		})
+		It("returns config errors from CheckProjectRootWithType", func() {
+			writeFile("PROJECT", "version: [3-alpha\n")
+			Expect(CheckProjectRootWithType(OperatorTypeGo)).To(MatchError(ContainSubstring("error reading config")))
+		})
+	})
+	Describe("GetOperatorTypeFromBundle", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "bundle-type-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		writeAnnotations := func(annotations string) {
+			Expect(os.MkdirAll(filepath.Join(dir, "metadata"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "metadata", "annotations.yaml"),
+				[]byte("annotations:\n"+annotations), 0644)).To(Succeed())
+		}
+
+		It("detects the type from the project layout annotation", func() {
+			for layout, expected := range map[string]OperatorType{
+				"go":                                  OperatorTypeGo,
+				"go.kubebuilder.io/v2":                OperatorTypeGo,
+				"helm.sdk.operatorframework.io/v1":    OperatorTypeHelm,
+				"ansible.sdk.operatorframework.io/v1": OperatorTypeAnsible,
+			} {
+				writeAnnotations("  operators.operatorframework.io.metrics.builder: operator-sdk-v1.0.0\n" +
+					"  operators.operatorframework.io.metrics.project_layout: " + layout + "\n")
+				Expect(GetOperatorTypeFromBundle(dir)).To(Equal(expected))
+			}
+		})
+		It("returns OperatorTypeUnknown with no error without a project layout annotation", func() {
+			operatorType, err := GetOperatorTypeFromBundle(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+
+			writeAnnotations("  operators.operatorframework.io.bundle.package.v1: memcached-operator\n")
+			operatorType, err = GetOperatorTypeFromBundle(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+		})
+		It("returns ErrUnknownOperatorType for an unknown layout", func() {
+			writeAnnotations("  operators.operatorframework.io.metrics.project_layout: java.example.com/v1\n")
+			operatorType, err := GetOperatorTypeFromBundle(dir)
+			Expect(err).To(MatchError(ErrUnknownOperatorType{Type: "java.example.com/v1"}))
+			Expect(operatorType).To(Equal(OperatorTypeUnknown))
+		})
+		It("returns an error for corrupt annotations", func() {
+			writeAnnotations("  - foo\n")
+			_, err := GetOperatorTypeFromBundle(dir)
+			Expect(err).To(MatchError(ContainSubstring("error unmarshalling bundle annotations")))
+		})
+	})
+	Describe("DetectProjectLayout", func() {
+		var wd, dir string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			dir, err = ioutil.TempDir("", "project-layout-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(dir)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		writeFile := func(path, contents string) {
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		}
+
+		It("detects kubebuilder layouts from the PROJECT file version", func() {
+			writeFile("PROJECT", "version: \"2\"\ndomain: example.com\n")
+			Expect(DetectProjectLayout()).To(Equal(LayoutKubebuilderV2))
+			ResetProjectConfigCache()
+			writeFile("PROJECT", "version: 3-alpha\nlayout: go.kubebuilder.io/v2\n")
+			Expect(DetectProjectLayout()).To(Equal(LayoutKubebuilderV3))
+		})
+		It("prefers the PROJECT file over legacy files", func() {
+			writeFile(filepath.Join("cmd", "manager", "main.go"), "package main\n")
+			writeFile("PROJECT", "version: 3-alpha\n")
+			Expect(DetectProjectLayout()).To(Equal(LayoutKubebuilderV3))
+		})
+		It("defaults to the version 2 layout for a PROJECT file without a version", func() {
+			writeFile("PROJECT", "domain: example.com\n")
+			Expect(DetectProjectLayout()).To(Equal(LayoutKubebuilderV2))
+		})
+		It("returns an error for an unsupported PROJECT file version", func() {
+			writeFile("PROJECT", "version: \"1\"\n")
+			layout, err := DetectProjectLayout()
+			Expect(err).To(MatchError(ContainSubstring(`unsupported PROJECT file version "1"`)))
+			Expect(layout).To(Equal(LayoutUnknown))
+		})
+		It("returns the config error for a corrupt PROJECT file", func() {
+			writeFile("PROJECT", "version: [3-alpha\n")
+			_, err := DetectProjectLayout()
+			Expect(err).To(MatchError(ContainSubstring("error reading config")))
+		})
+		It("detects a legacy Go project", func() {
+			writeFile(filepath.Join("cmd", "manager", "main.go"), "package main\n")
+			Expect(DetectProjectLayout()).To(Equal(LayoutLegacy))
+		})
+		It("detects a legacy Ansible or Helm project", func() {
+			writeFile(filepath.Join("build", "Dockerfile"), "FROM quay.io/operator-framework/helm-operator\n")
+			Expect(DetectProjectLayout()).To(Equal(LayoutLegacy))
+		})
+		It("returns an error outside of a project", func() {
+			layout, err := DetectProjectLayout()
+			Expect(err).To(HaveOccurred())
+			Expect(layout).To(Equal(LayoutUnknown))
+		})
+	})
+	Describe("FindProjectRoot and GetGoPkg", func() {
+		var wd, root string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			root, err = ioutil.TempDir("", "project-root-")
+			Expect(err).NotTo(HaveOccurred())
+			// Resolve symlinks, ex. macOS's /var -> /private/var, so paths match os.Getwd.
+			root, err = filepath.EvalSymlinks(root)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(root)).To(Succeed())
+		})
+
+		It("resolves the module path of an API package from inside its directory", func() {
+			Expect(ioutil.WriteFile(filepath.Join(root, "go.mod"),
+				[]byte("module github.com/example/memcached-operator\n\ngo 1.13\n"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(root, "PROJECT"), []byte("version: 3-alpha\n"), 0644)).To(Succeed())
+			apiDir := filepath.Join(root, "apis", "cache", "v1alpha1")
+			Expect(os.MkdirAll(apiDir, 0755)).To(Succeed())
+
+			Expect(os.Chdir(apiDir)).To(Succeed())
+			Expect(FindProjectRoot()).To(Equal(root))
+			Expect(GetGoPkg()).To(Equal("github.com/example/memcached-operator/apis/cache/v1alpha1"))
+
+			Expect(os.Chdir(root)).To(Succeed())
+			Expect(FindProjectRoot()).To(Equal(root))
+			Expect(GetGoPkg()).To(Equal("github.com/example/memcached-operator"))
+		})
+		It("finds a project root with only a PROJECT file", func() {
+			Expect(ioutil.WriteFile(filepath.Join(root, "PROJECT"), []byte("version: 3-alpha\n"), 0644)).To(Succeed())
+			chartDir := filepath.Join(root, "helm-charts", "nginx")
+			Expect(os.MkdirAll(chartDir, 0755)).To(Succeed())
+
+			Expect(os.Chdir(chartDir)).To(Succeed())
+			Expect(FindProjectRoot()).To(Equal(root))
+		})
+		It("returns ErrNoProjectRoot after reaching the filesystem root", func() {
+			start := filepath.Join(root, "a", "b")
+			Expect(os.MkdirAll(start, 0755)).To(Succeed())
+
+			_, err := findProjectRoot(start)
+			Expect(errors.Is(err, ErrNoProjectRoot)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(start))
+		})
+	})
+	DescribeTable("OperatorTypeFromString",
+		func(s string, expected OperatorType, valid bool) {
+			operatorType, err := OperatorTypeFromString(s)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(Equal(ErrUnknownOperatorType{Type: s}))
+			}
+			Expect(operatorType).To(Equal(expected))
+		},
+		Entry("go", "go", OperatorTypeGo, true),
+		Entry("ansible", "ansible", OperatorTypeAnsible, true),
+		Entry("helm with case and whitespace", " Helm\n", OperatorTypeHelm, true),
+		Entry("unknown type", "unknown", OperatorTypeUnknown, false),
+		Entry("plugin key", "go.kubebuilder.io/v2", OperatorTypeUnknown, false),
+		Entry("empty", "", OperatorTypeUnknown, false),
+	)
+
+	DescribeTable("sanitizeProjectName",
+		func(name, expected string) {
+			Expect(sanitizeProjectName(name)).To(Equal(expected))
+		},
+		Entry("valid name", "memcached-operator", "memcached-operator"),
+		Entry("uppercase", "MemcachedOperator", "memcachedoperator"),
+		Entry("invalid characters", "my_app.operator v2", "my-app-operator-v2"),
+		Entry("leading and trailing invalid characters", "_.app-operator-.", "app-operator"),
+		Entry("long name", strings.Repeat("a", 62)+"_b", strings.Repeat("a", 62)),
+		Entry("no valid characters", "__", ""),
+	)
+
+	Describe("Non-fatal project helpers", func() {
+		var wd, dir string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			dir, err = ioutil.TempDir("", "projutil-")
+			Expect(err).NotTo(HaveOccurred())
+			dir, err = filepath.EvalSymlinks(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(dir)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Chdir(wd)).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("returns the project type from IsOperator*Err", func() {
+			Expect(ioutil.WriteFile("PROJECT", []byte("version: 3-alpha\nlayout: ansible.sdk.operatorframework.io/v1\n"),
+				0644)).To(Succeed())
+			isGo, err := IsOperatorGoErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isGo).To(BeFalse())
+			isAnsible, err := IsOperatorAnsibleErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAnsible).To(BeTrue())
+			isHelm, err := IsOperatorHelmErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isHelm).To(BeFalse())
+		})
+		It("returns config errors from IsOperator*Err for a corrupt PROJECT file", func() {
+			Expect(ioutil.WriteFile("PROJECT", []byte("version: [3-alpha\n"), 0644)).To(Succeed())
+			for _, f := range []func() (bool, error){IsOperatorGoErr, IsOperatorAnsibleErr, IsOperatorHelmErr} {
+				is, err := f()
+				Expect(err).To(MatchError(ContainSubstring("error reading config")))
+				Expect(is).To(BeFalse())
+			}
+		})
+		It("returns parse errors from GetGoPkgErr for a corrupt go.mod", func() {
+			Expect(ioutil.WriteFile("go.mod", []byte("module\n"), 0644)).To(Succeed())
+			_, err := GetGoPkgErr()
+			Expect(err).To(MatchError(ContainSubstring("error parsing go.mod")))
+		})
+		It("returns an error from GetGoPkgErr for an invalid module path", func() {
+			Expect(ioutil.WriteFile("go.mod", []byte("module GitHub.com/example/app-operator\n"), 0644)).To(Succeed())
+			_, err := GetGoPkgErr()
+			Expect(err).To(MatchError(ContainSubstring(`invalid module path element "GitHub.com"`)))
+		})
+		It("returns the module path from GetGoPkgErr", func() {
+			Expect(ioutil.WriteFile("go.mod", []byte("module github.com/example/app-operator\n"), 0644)).To(Succeed())
+			pkg, err := GetGoPkgErr()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg).To(Equal("github.com/example/app-operator"))
+		})
+
+		It("returns the go directive's version from GetGoModVersion", func() {
+			Expect(ioutil.WriteFile("go.mod", []byte("module github.com/example/app-operator\n\ngo 1.13\n"), 0644)).To(Succeed())
+			Expect(GetGoModVersion()).To(Equal("1.13"))
+		})
+		It("returns an error from GetGoModVersion for a go.mod without a go directive", func() {
+			Expect(ioutil.WriteFile("go.mod", []byte("module github.com/example/app-operator\n"), 0644)).To(Succeed())
+			_, err := GetGoModVersion()
+			Expect(err).To(MatchError(ContainSubstring("has no go directive")))
+		})
+		It("returns an error from GetGoModVersion without a go.mod", func() {
+			Expect(ioutil.WriteFile("PROJECT", []byte("version: 3-alpha\n"), 0644)).To(Succeed())
+			_, err := GetGoModVersion()
+			Expect(err).To(MatchError(ContainSubstring("error reading go.mod")))
+		})
+
+		Describe("with -modfile in GOFLAGS", func() {
+			const (
+				goMod     = "module github.com/example/app-operator\n\ngo 1.13\n"
+				customMod = "module github.com/example/custom-operator\n\ngo 1.14\n"
+			)
+
+			readFile := func(path string) string {
+				b, err := ioutil.ReadFile(path)
+				ExpectWithOffset(1, err).NotTo(HaveOccurred())
+				return string(b)
+			}
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile("go.mod", []byte(goMod), 0644)).To(Succeed())
+				Expect(os.Mkdir("mod", 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join("mod", "custom.mod"), []byte(customMod), 0644)).To(Succeed())
+				Expect(os.Setenv(GoFlagsEnv, "-mod=mod -modfile=mod/custom.mod")).To(Succeed())
+			})
+
+			It("resolves the module file relative to the working directory", func() {
+				Expect(resolveGoModPath()).To(Equal(filepath.Join(dir, "mod", "custom.mod")))
+				Expect(os.Setenv(GoFlagsEnv, "")).To(Succeed())
+				Expect(resolveGoModPath()).To(Equal(filepath.Join(dir, "go.mod")))
+			})
+			It("reads and edits the custom module file", func() {
+				Expect(GetGoPkgErr()).To(Equal("github.com/example/custom-operator"))
+				Expect(GetGoModVersion()).To(Equal("1.14"))
+				Expect(AddGoModReplace("sigs.k8s.io/controller-runtime", "../controller-runtime", "")).To(Succeed())
+				Expect(readFile(filepath.Join("mod", "custom.mod"))).To(Equal(customMod +
+					"\nreplace sigs.k8s.io/controller-runtime => ../controller-runtime\n"))
+				Expect(readFile("go.mod")).To(Equal(goMod))
+			})
+			It("returns errors for a missing module file or malformed GOFLAGS", func() {
+				Expect(os.Setenv(GoFlagsEnv, "-modfile=missing.mod")).To(Succeed())
+				_, err := GetGoModVersion()
+				Expect(err).To(MatchError(ContainSubstring("error reading missing.mod")))
+				Expect(os.Setenv(GoFlagsEnv, "-modfile")).To(Succeed())
+				_, err = GetGoPkgErr()
+				Expect(err).To(MatchError(ContainSubstring(`invalid GOFLAGS: flag "-modfile" must have a value`)))
+			})
+		})
+
+		Describe("AddGoModReplace and RemoveGoModReplace", func() {
+			const goMod = "module github.com/example/app-operator\n\n" +
+				"go 1.13\n\n" +
+				"require sigs.k8s.io/controller-runtime v0.6.1\n"
+
+			readGoMod := func() string {
+				b, err := ioutil.ReadFile("go.mod")
+				Expect(err).NotTo(HaveOccurred())
+				return string(b)
+			}
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile("go.mod", []byte(goMod), 0644)).To(Succeed())
+			})
+
+			It("adds and removes a replace", func() {
+				Expect(AddGoModReplace("sigs.k8s.io/controller-runtime", "../controller-runtime", "")).To(Succeed())
+				Expect(readGoMod()).To(Equal(goMod + "\nreplace sigs.k8s.io/controller-runtime => ../controller-runtime\n"))
+				Expect(RemoveGoModReplace("sigs.k8s.io/controller-runtime")).To(Succeed())
+				Expect(readGoMod()).To(Equal(goMod))
+			})
+			It("updates an existing replace of the same module", func() {
+				Expect(ioutil.WriteFile("go.mod", []byte(goMod+"\nreplace (\n"+
+					"\tgithub.com/go-logr/logr => github.com/go-logr/logr v0.1.0\n"+
+					"\tsigs.k8s.io/controller-runtime v0.6.1 => ../controller-runtime\n"+
+					")\n"), 0644)).To(Succeed())
+				Expect(AddGoModReplace("sigs.k8s.io/controller-runtime", "github.com/example/controller-runtime", "v0.6.2")).To(Succeed())
+				Expect(readGoMod()).To(Equal(goMod + "\nreplace (\n" +
+					"\tgithub.com/go-logr/logr => github.com/go-logr/logr v0.1.0\n" +
+					"\tsigs.k8s.io/controller-runtime => github.com/example/controller-runtime v0.6.2\n" +
+					")\n"))
+			})
+			It("does not modify go.mod when removing a replace it does not have", func() {
+				Expect(RemoveGoModReplace("sigs.k8s.io/controller-runtime")).To(Succeed())
+				Expect(readGoMod()).To(Equal(goMod))
+			})
+			It("returns an error for an invalid replacement version", func() {
+				Expect(AddGoModReplace("sigs.k8s.io/controller-runtime", "../controller-runtime", "v0.6.2")).To(
+					MatchError(ContainSubstring("must be empty")))
+				Expect(AddGoModReplace("sigs.k8s.io/controller-runtime", "github.com/example/controller-runtime", "")).To(
+					MatchError(ContainSubstring("must have a version")))
+				Expect(readGoMod()).To(Equal(goMod))
+			})
+			It("returns parse errors for a corrupt go.mod", func() {
+				Expect(ioutil.WriteFile("go.mod", []byte("module\n"), 0644)).To(Succeed())
+				Expect(RemoveGoModReplace("sigs.k8s.io/controller-runtime")).To(MatchError(ContainSubstring("error parsing go.mod")))
+			})
+		})
+
+		Describe("GetPluginConfig and SetPluginConfig", func() {
+			type pluginConfig struct {
+				Image string   `json:"image,omitempty"`
+				Tests []string `json:"tests,omitempty"`
+			}
+			const project = `domain: example.com
+layout: go.kubebuilder.io/v2
+repo: github.com/example/app-operator
+version: 3-alpha
+plugins:
+  go.sdk.operatorframework.io/v2-alpha: {}
+  manifests.sdk.operatorframework.io/v2:
+    channels: [alpha]
+`
+			readProject := func() string {
+				b, err := ioutil.ReadFile("PROJECT")
+				ExpectWithOffset(1, err).NotTo(HaveOccurred())
+				return string(b)
+			}
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile("PROJECT", []byte(project), 0644)).To(Succeed())
+			})
+
+			It("round-trips a plugin config without changing other plugins", func() {
+				cfg := pluginConfig{Image: "quay.io/example/scorecard:v0.1.0", Tests: []string{"basic", "olm"}}
+				Expect(SetPluginConfig("scorecard.sdk.operatorframework.io/v2", cfg)).To(Succeed())
+
+				var got pluginConfig
+				Expect(GetPluginConfig("scorecard.sdk.operatorframework.io/v2", &got)).To(Succeed())
+				Expect(got).To(Equal(cfg))
+				var manifests struct {
+					Channels []string `json:"channels"`
+				}
+				Expect(GetPluginConfig("manifests.sdk.operatorframework.io/v2", &manifests)).To(Succeed())
+				Expect(manifests.Channels).To(Equal([]string{"alpha"}))
+				Expect(readProject()).To(Equal(`domain: example.com
+layout: go.kubebuilder.io/v2
+repo: github.com/example/app-operator
+version: 3-alpha
+plugins:
+  go.sdk.operatorframework.io/v2-alpha: {}
+  manifests.sdk.operatorframework.io/v2:
+    channels:
+    - alpha
+  scorecard.sdk.operatorframework.io/v2:
+    image: quay.io/example/scorecard:v0.1.0
+    tests:
+    - basic
+    - olm
+`))
+			})
+			It("is idempotent and leaves an unchanged config's file as-is", func() {
+				Expect(SetPluginConfig("go.sdk.operatorframework.io/v2-alpha", struct{}{})).To(Succeed())
+				Expect(readProject()).To(Equal(project))
+
+				cfg := pluginConfig{Image: "quay.io/example/scorecard:v0.1.0"}
+				Expect(SetPluginConfig("scorecard.sdk.operatorframework.io/v2", cfg)).To(Succeed())
+				written := readProject()
+				Expect(SetPluginConfig("scorecard.sdk.operatorframework.io/v2", cfg)).To(Succeed())
+				Expect(readProject()).To(Equal(written))
+			})
+			It("does not modify out for a missing key", func() {
+				got := pluginConfig{Image: "unchanged"}
+				Expect(GetPluginConfig("scorecard.sdk.operatorframework.io/v2", &got)).To(Succeed())
+				Expect(got.Image).To(Equal("unchanged"))
+			})
+			It("returns errors for v1 and missing PROJECT files", func() {
+				Expect(ioutil.WriteFile("PROJECT", []byte("version: \"1\"\n"), 0644)).To(Succeed())
+				Expect(SetPluginConfig("scorecard.sdk.operatorframework.io/v2", pluginConfig{})).To(
+					MatchError(ContainSubstring("v1 project configs do not have extra fields")))
+				Expect(os.Remove("PROJECT")).To(Succeed())
+				Expect(SetPluginConfig("scorecard.sdk.operatorframework.io/v2", pluginConfig{})).To(
+					MatchError(ContainSubstring("error reading config")))
+				Expect(GetPluginConfig("scorecard.sdk.operatorframework.io/v2", &pluginConfig{})).To(
+					MatchError(ContainSubstring("error reading config")))
+			})
+		})
+
+		Describe("ProjectVendored", func() {
+			const goMod = "module github.com/example/app-operator\n\ngo 1.15\n\n" +
+				"require (\n\tgithub.com/go-logr/logr v0.2.1\n\tsigs.k8s.io/controller-runtime v0.6.3\n)\n\n" +
+				"replace github.com/go-logr/logr => github.com/go-logr/logr v0.2.0\n"
+			const modulesTxt = "# github.com/go-logr/logr v0.2.1 => github.com/go-logr/logr v0.2.0\n## explicit\n" +
+				"github.com/go-logr/logr\n# github.com/google/gofuzz v1.1.0\ngithub.com/google/gofuzz\n" +
+				"# sigs.k8s.io/controller-runtime v0.6.3\n## explicit\nsigs.k8s.io/controller-runtime/pkg/client\n" +
+				"# github.com/go-logr/logr => github.com/go-logr/logr v0.2.0\n"
+
+			writeVendor := func(modules string) {
+				ExpectWithOffset(1, ioutil.WriteFile("go.mod", []byte(goMod), 0644)).To(Succeed())
+				ExpectWithOffset(1, os.Mkdir("vendor", 0755)).To(Succeed())
+				if modules != "" {
+					ExpectWithOffset(1, ioutil.WriteFile(filepath.Join("vendor", "modules.txt"), []byte(modules),
+						0644)).To(Succeed())
+				}
+			}
+
+			It("returns false without a vendor directory", func() {
+				Expect(ioutil.WriteFile("go.mod", []byte(goMod), 0644)).To(Succeed())
+				Expect(ProjectVendored()).To(BeFalse())
+			})
+			It("returns true for a vendor directory consistent with go.mod", func() {
+				writeVendor(modulesTxt)
+				Expect(ProjectVendored()).To(BeTrue())
+
+				Expect(os.Mkdir("api", 0755)).To(Succeed())
+				Expect(os.Chdir("api")).To(Succeed())
+				Expect(ProjectVendored()).To(BeTrue())
+			})
+			It("only checks that a modules.txt without explicit requirements exists", func() {
+				writeVendor("# github.com/go-logr/logr v0.1.0\ngithub.com/go-logr/logr\n")
+				Expect(ProjectVendored()).To(BeTrue())
+			})
+			DescribeTable("returns ErrVendorStale for a stale vendor directory",
+				func(modules, message string) {
+					writeVendor(modules)
+					vendored, err := ProjectVendored()
+					Expect(vendored).To(BeTrue())
+					Expect(errors.Is(err, ErrVendorStale)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(message)))
+				},
+				Entry("with a missing requirement",
+					strings.Replace(modulesTxt, "# sigs.k8s.io/controller-runtime v0.6.3\n## explicit\n", "", 1),
+					"go.mod requires sigs.k8s.io/controller-runtime v0.6.3, which is not in vendor/modules.txt"),
+				Entry("with a different requirement version",
+					strings.Replace(modulesTxt, "controller-runtime v0.6.3", "controller-runtime v0.6.2", 1),
+					"go.mod requires sigs.k8s.io/controller-runtime v0.6.3, but vendor/modules.txt has v0.6.2"),
+				Entry("with a requirement not marked explicit",
+					strings.Replace(modulesTxt, "v0.6.3\n## explicit\n", "v0.6.3\n", 1),
+					"vendor/modules.txt does not mark it explicit"),
+				Entry("with an explicit module go.mod does not require",
+					strings.Replace(modulesTxt, "gofuzz v1.1.0\n", "gofuzz v1.1.0\n## explicit\n", 1),
+					"vendor/modules.txt marks github.com/google/gofuzz explicit, but go.mod does not require it"),
+				Entry("without a modules.txt", "", "vendor/modules.txt does not exist"),
+			)
+		})
+
+		Describe("GetProjectName", func() {
+			It("returns projectName from the PROJECT file", func() {
+				Expect(ioutil.WriteFile("PROJECT", []byte("version: 3-alpha\nprojectName: memcached-operator\n"),
+					0644)).To(Succeed())
+				Expect(GetProjectName()).To(Equal("memcached-operator"))
+			})
+			It("falls back to the sanitized directory name", func() {
+				Expect(os.Mkdir("My_App.Operator", 0755)).To(Succeed())
+				Expect(os.Chdir("My_App.Operator")).To(Succeed())
+				Expect(GetProjectName()).To(Equal("my-app-operator"))
+
+				Expect(ioutil.WriteFile("PROJECT", []byte("version: 3-alpha\ndomain: example.com\n"), 0644)).To(Succeed())
+				Expect(GetProjectName()).To(Equal("my-app-operator"))
+			})
+			It("returns an error for a corrupt PROJECT file or an unusable directory name", func() {
+				Expect(ioutil.WriteFile("PROJECT", []byte("version: [3-alpha\n"), 0644)).To(Succeed())
+				_, err := GetProjectName()
+				Expect(err).To(MatchError(ContainSubstring("error reading config")))
+
+				Expect(os.Mkdir("__", 0755)).To(Succeed())
+				Expect(os.Chdir("__")).To(Succeed())
+				_, err = GetProjectName()
+				Expect(err).To(MatchError(ContainSubstring(`cannot derive a project name from directory "__"`)))
+			})
+		})
+
+		Describe("CheckProjectNameConsistency", func() {
+			mkProject := func(dirName, projectName string) {
+				ExpectWithOffset(1, os.Mkdir(dirName, 0755)).To(Succeed())
+				ExpectWithOffset(1, os.Chdir(dirName)).To(Succeed())
+				ExpectWithOffset(1, ioutil.WriteFile("PROJECT",
+					[]byte("version: 3-alpha\nprojectName: "+projectName+"\n"), 0644)).To(Succeed())
+			}
+
+			It("succeeds for matching names, ignoring case and sanitization", func() {
+				mkProject("Memcached_Operator", "memcached-operator")
+				Expect(CheckProjectNameConsistency()).To(Succeed())
+				ResetProjectConfigCache()
+				Expect(ioutil.WriteFile("PROJECT", []byte("version: 3-alpha\nprojectName: memcached_operator\n"),
+					0644)).To(Succeed())
+				Expect(CheckProjectNameConsistency()).To(Succeed())
+			})
+			It("succeeds without a PROJECT file or projectName", func() {
+				Expect(CheckProjectNameConsistency()).To(Succeed())
+				Expect(ioutil.WriteFile("PROJECT", []byte("version: 3-alpha\n"), 0644)).To(Succeed())
+				Expect(CheckProjectNameConsistency()).To(Succeed())
+			})
+			It("returns an ErrProjectNameMismatch for mismatched names", func() {
+				mkProject("app-operator", "memcached-operator")
+				err := CheckProjectNameConsistency()
+				Expect(err).To(Equal(ErrProjectNameMismatch{DirName: "app-operator", ProjectName: "memcached-operator"}))
+				Expect(err).To(MatchError(ContainSubstring(
+					`project directory name "app-operator" does not match projectName "memcached-operator"`)))
+			})
+			It("returns an error for a corrupt PROJECT file", func() {
+				Expect(ioutil.WriteFile("PROJECT", []byte("version: [3-alpha\n"), 0644)).To(Succeed())
+				Expect(CheckProjectNameConsistency()).To(MatchError(ContainSubstring("error reading config")))
+			})
+		})
+
+		Describe("RenameModulePath", func() {
+			const (
+				oldPath = "github.com/example/app-operator"
+				newPath = "github.com/fork/memcached-operator"
+			)
+
+			writeFile := func(path, content string) {
+				ExpectWithOffset(1, os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+				ExpectWithOffset(1, ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			}
+			readFile := func(path string) string {
+				b, err := ioutil.ReadFile(path)
+				ExpectWithOffset(1, err).NotTo(HaveOccurred())
+				return string(b)
+			}
+
+			BeforeEach(func() {
+				writeFile("go.mod", "module "+oldPath+"\n\ngo 1.13\n")
+				writeFile("PROJECT", "domain: example.com\nrepo: "+oldPath+"\nversion: 3-alpha\n")
+				writeFile("main.go", `package main
+
+import (
+	"fmt"
+
+	cachev1 "`+oldPath+`/api/v1"
+	"`+oldPath+`/controllers"
+)
+
+// See https://`+oldPath+`-extra for more.
+func main() {
+	fmt.Println(controllers.Reconcile(cachev1.Memcached{Size: 3}))
+}
+`)
+				writeFile("api/v1/types.go", "package v1\n\ntype Memcached struct {\n\tSize int\n}\n")
+				writeFile("controllers/controller.go", `package controllers
+
+import v1 "`+oldPath+`/api/v1"
+
+func Reconcile(m v1.Memcached) int {
+	return m.Size
+}
+`)
+				writeFile("vendor/example.com/dep/dep.go", "package dep\n\nimport _ \""+oldPath+"/api/v1\"\n")
+				writeFile("config/samples/kustomization.yaml", "# Built from "+oldPath+".\n"+
+					"# See "+oldPath+"/docs and "+oldPath+"-extra.\n")
+			})
+
+			It("renames the module in go.mod, imports, PROJECT, and config, and the project compiles", func() {
+				Expect(RenameModulePath(oldPath, newPath)).To(Succeed())
+
+				Expect(readFile("go.mod")).To(Equal("module " + newPath + "\n\ngo 1.13\n"))
+				Expect(readFile("PROJECT")).To(Equal("domain: example.com\nrepo: " + newPath + "\nversion: 3-alpha\n"))
+				main := readFile("main.go")
+				Expect(main).To(ContainSubstring(`cachev1 "` + newPath + `/api/v1"`))
+				Expect(main).To(ContainSubstring(`"` + newPath + `/controllers"`))
+				Expect(main).To(ContainSubstring("https://" + oldPath + "-extra"))
+				Expect(readFile("controllers/controller.go")).To(ContainSubstring(`import v1 "` + newPath + `/api/v1"`))
+				Expect(readFile("vendor/example.com/dep/dep.go")).To(ContainSubstring(oldPath))
+				Expect(readFile("config/samples/kustomization.yaml")).To(Equal("# Built from " + newPath + ".\n" +
+					"# See " + newPath + "/docs and " + oldPath + "-extra.\n"))
+
+				cmd := exec.Command("go", "build", "./...")
+				cmd.Env = append(os.Environ(), "GOFLAGS=", GoModEnv+"=on")
+				out, err := cmd.CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(out))
+			})
+			It("does not change files when renaming a module the project is not", func() {
+				Expect(RenameModulePath("github.com/example/other-operator", newPath)).To(
+					MatchError("go.mod module path is " + oldPath + ", not github.com/example/other-operator"))
+				Expect(readFile("go.mod")).To(Equal("module " + oldPath + "\n\ngo 1.13\n"))
+				Expect(readFile("main.go")).To(ContainSubstring(oldPath + "/controllers"))
+			})
+			It("returns an error for an invalid new module path", func() {
+				Expect(RenameModulePath(oldPath, "GitHub.com/fork/memcached-operator")).To(
+					MatchError(ContainSubstring("invalid module path GitHub.com/fork/memcached-operator")))
+			})
+		})
+
+		Describe("ListAPIs", func() {
+			writeFile := func(path, content string) {
+				ExpectWithOffset(1, os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+				ExpectWithOffset(1, ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			}
+
+			It("returns the resources in the PROJECT file", func() {
+				writeFile("PROJECT", "domain: example.com\nlayout: go.kubebuilder.io/v2\nrepo: github.com/example/app-operator\n"+
+					"resources:\n- group: cache\n  kind: Memcached\n  version: v1alpha1\n"+
+					"- group: ship\n  kind: Frigate\n  version: v1beta1\nversion: 3-alpha\n")
+				Expect(ListAPIs()).To(Equal([]Resource{
+					{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached", Plural: "memcacheds"},
+					{Group: "ship.example.com", Version: "v1beta1", Kind: "Frigate", Plural: "frigates"},
+				}))
+			})
+			It("returns no resources for a PROJECT file without any", func() {
+				writeFile("PROJECT", "domain: example.com\nversion: 3-alpha\n")
+				Expect(ListAPIs()).To(BeEmpty())
+			})
+			It("returns config errors for a corrupt PROJECT file", func() {
+				writeFile("PROJECT", "version: [3-alpha\n")
+				_, err := ListAPIs()
+				Expect(err).To(MatchError(ContainSubstring("error reading config")))
+			})
+			It("returns the APIs registered in pkg/apis for legacy projects", func() {
+				writeFile("build/Dockerfile", "FROM scratch\n")
+				writeFile("pkg/apis/cache/v1alpha1/doc.go", "// +k8s:deepcopy-gen=package,register\n"+
+					"// +groupName=cache.example.com\npackage v1alpha1\n")
+				writeFile("pkg/apis/cache/v1alpha1/memcached_types.go", "package v1alpha1\n\nfunc init() {\n"+
+					"\tSchemeBuilder.Register(&Memcached{}, &MemcachedList{})\n}\n")
+				writeFile("pkg/apis/cache/v1alpha1/memcachedbackup_types.go", "package v1alpha1\n\nfunc init() {\n"+
+					"\tSchemeBuilder.Register(&MemcachedBackup{}, &MemcachedBackupList{})\n}\n")
+				writeFile("pkg/apis/cache/v1alpha1/memcached_types_test.go", "package v1alpha1\n\nfunc init() {\n"+
+					"\tSchemeBuilder.Register(&Fake{})\n}\n")
+				writeFile("pkg/apis/app/v1/appservice_types.go", "package v1\n\nfunc init() {\n"+
+					"\tSchemeBuilder.Register(&AppService{}, &AppServiceList{})\n}\n")
+				writeFile("pkg/apis/apis.go", "package apis\n")
+				Expect(ListAPIs()).To(Equal([]Resource{
+					{Group: "app", Version: "v1", Kind: "AppService", Plural: "appservices"},
+					{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached", Plural: "memcacheds"},
+					{Group: "cache.example.com", Version: "v1alpha1", Kind: "MemcachedBackup", Plural: "memcachedbackups"},
+				}))
+			})
+			It("returns no APIs for legacy projects without an APIs directory", func() {
+				writeFile("build/Dockerfile", "FROM scratch\n")
+				Expect(ListAPIs()).To(BeEmpty())
+			})
+		})
+
+		Describe("AppendGoFlag", func() {
+			var goflags string
+
+			BeforeEach(func() {
+				goflags = os.Getenv(GoFlagsEnv)
+			})
+			AfterEach(func() {
+				Expect(os.Setenv(GoFlagsEnv, goflags)).To(Succeed())
+			})
+
+			DescribeTable("sets GOFLAGS",
+				func(existing, flag, expected string) {
+					Expect(os.Setenv(GoFlagsEnv, existing)).To(Succeed())
+					Expect(AppendGoFlag(flag)).To(Succeed())
+					Expect(os.Getenv(GoFlagsEnv)).To(Equal(expected))
+				},
+				Entry("when unset", "", "-trimpath", "-trimpath"),
+				Entry("by appending a new flag", "-mod=mod", "-trimpath", "-mod=mod -trimpath"),
+				Entry("without duplicating a flag", "-v -trimpath", "-trimpath", "-v -trimpath"),
+				Entry("without overriding a user-set value", "-tags=e2e -v", "-tags=integration", "-tags=e2e -v"),
+				Entry("comparing flags by name", "--v=false", "-v", "--v=false"),
+				Entry("by replacing an existing -mod", "-v -mod=mod -trimpath", "-mod=vendor", "-v -mod=vendor -trimpath"),
+				Entry("without rewriting an equal -mod", "-v  -mod=vendor", "-mod=vendor", "-v  -mod=vendor"),
+			)
+			It("reimplements SetGoVerbose", func() {
+				Expect(os.Setenv(GoFlagsEnv, "-mod=vendor")).To(Succeed())
+				Expect(SetGoVerbose()).To(Succeed())
+				Expect(SetGoVerbose()).To(Succeed())
+				Expect(os.Getenv(GoFlagsEnv)).To(Equal("-mod=vendor -v"))
+			})
+			It("returns an error for a malformed flag", func() {
+				Expect(AppendGoFlag("trimpath")).To(MatchError(ContainSubstring("must be of the form")))
+				Expect(AppendGoFlag("-=vendor")).To(MatchError(ContainSubstring("must be of the form")))
+				Expect(AppendGoFlag("-v -trimpath")).To(MatchError(ContainSubstring("must not contain whitespace")))
+			})
+			It("returns an error for a malformed GOFLAGS", func() {
+				Expect(os.Setenv(GoFlagsEnv, "-v trimpath")).To(Succeed())
+				Expect(AppendGoFlag("-trimpath")).To(MatchError(ContainSubstring("invalid GOFLAGS")))
+			})
+		})
+
+		Describe("BinaryOutputPath", func() {
+			var goos, goarch string
+
+			BeforeEach(func() {
+				goos, goarch = os.Getenv("GOOS"), os.Getenv("GOARCH")
+				Expect(os.Unsetenv("GOOS")).To(Succeed())
+				Expect(os.Unsetenv("GOARCH")).To(Succeed())
+			})
+			AfterEach(func() {
+				Expect(os.Setenv("GOOS", goos)).To(Succeed())
+				Expect(os.Setenv("GOARCH", goarch)).To(Succeed())
+			})
+
+			DescribeTable("returns a path for each platform",
+				func(goos, goarch, expected string) {
+					Expect(BinaryOutputPath("app-operator", goos, goarch)).To(Equal(filepath.Join(
+						"build", "_output", "bin", expected)))
+				},
+				Entry("linux/amd64", "linux", "amd64", "app-operator-linux-amd64"),
+				Entry("linux/arm64", "linux", "arm64", "app-operator-linux-arm64"),
+				Entry("linux/ppc64le", "linux", "ppc64le", "app-operator-linux-ppc64le"),
+				Entry("linux/s390x", "linux", "s390x", "app-operator-linux-s390x"),
+				Entry("darwin/amd64", "darwin", "amd64", "app-operator-darwin-amd64"),
+				Entry("windows/amd64", "windows", "amd64", "app-operator-windows-amd64"),
+			)
+			It("defaults to the GOOS and GOARCH environment variables", func() {
+				Expect(os.Setenv("GOOS", "linux")).To(Succeed())
+				Expect(os.Setenv("GOARCH", "s390x")).To(Succeed())
+				Expect(BinaryOutputPath("app-operator", "", "")).To(Equal(filepath.Join(
+					"build", "_output", "bin", "app-operator-linux-s390x")))
+				Expect(BinaryOutputPath("app-operator", "darwin", "")).To(Equal(filepath.Join(
+					"build", "_output", "bin", "app-operator-darwin-s390x")))
+			})
+			It("defaults to the running platform", func() {
+				Expect(BinaryOutputPath("app-operator", "", "")).To(Equal(filepath.Join(
+					"build", "_output", "bin", "app-operator-"+runtime.GOOS+"-"+runtime.GOARCH)))
+			})
+		})
+
+		Describe("UnsetGoVerbose", func() {
+			DescribeTable("removes -v from GOFLAGS",
+				func(existing, expected string) {
+					Expect(os.Setenv(GoFlagsEnv, existing)).To(Succeed())
+					Expect(UnsetGoVerbose()).To(Succeed())
+					Expect(os.Getenv(GoFlagsEnv)).To(Equal(expected))
+				},
+				Entry("at the start", "-v -mod=vendor -trimpath", "-mod=vendor -trimpath"),
+				Entry("in the middle", "-mod=vendor -v -trimpath", "-mod=vendor -trimpath"),
+				Entry("at the end", "-mod=vendor -trimpath -v", "-mod=vendor -trimpath"),
+				Entry("more than once and with a value", "-v -mod=vendor --v=true", "-mod=vendor"),
+				Entry("without removing flags starting with v", "-vet=off -v", "-vet=off"),
+				Entry("without rewriting GOFLAGS without -v", "-mod=vendor  -vet=off", "-mod=vendor  -vet=off"),
+			)
+			It("unsets GOFLAGS if only -v is set", func() {
+				Expect(os.Setenv(GoFlagsEnv, " -v ")).To(Succeed())
+				Expect(UnsetGoVerbose()).To(Succeed())
+				_, set := os.LookupEnv(GoFlagsEnv)
+				Expect(set).To(BeFalse())
+			})
+			It("reverts SetGoVerbose", func() {
+				Expect(os.Setenv(GoFlagsEnv, "-mod=vendor")).To(Succeed())
+				Expect(SetGoVerbose()).To(Succeed())
+				Expect(UnsetGoVerbose()).To(Succeed())
+				Expect(os.Getenv(GoFlagsEnv)).To(Equal("-mod=vendor"))
+			})
+			It("returns an error for a malformed GOFLAGS", func() {
+				Expect(os.Setenv(GoFlagsEnv, "-v trimpath")).To(Succeed())
+				Expect(UnsetGoVerbose()).To(MatchError(ContainSubstring("invalid GOFLAGS")))
+			})
+		})
+
+		Describe("SetWdGopath", func() {
+			var gopath string
+
+			BeforeEach(func() {
+				gopath = os.Getenv(GoPathEnv)
+			})
+			AfterEach(func() {
+				Expect(os.Setenv(GoPathEnv, gopath)).To(Succeed())
+			})
+
+			It("sets GOPATH to the path list element containing the wd", func() {
+				other := filepath.Join(string(filepath.Separator), "other", "go")
+				newGopath, err := SetWdGopath(other + string(filepath.ListSeparator) + filepath.Dir(dir))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newGopath).To(Equal(filepath.Dir(dir)))
+				Expect(os.Getenv(GoPathEnv)).To(Equal(filepath.Dir(dir)))
+			})
+			It("returns ErrNotInGopath if no path list element contains the wd", func() {
+				_, err := SetWdGopath(filepath.Join(string(filepath.Separator), "other", "go"))
+				Expect(err).To(Equal(ErrNotInGopath))
+			})
+		})
 	})
 })
 
@@ -0,0 +1,140 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package projutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	vendorDir        = "vendor"
+	vendorModulesTxt = "modules.txt"
+)
+
+// ErrVendorStale is returned by ProjectVendored when the project has a vendor directory
+// that is not consistent with its go.mod, and must be updated by running "go mod vendor".
+var ErrVendorStale = errors.New("vendor directory is out of date with go.mod")
+
+// ProjectVendored returns true if the project root found by FindProjectRoot has a vendor directory,
+// in which case "go" commands should be run with -mod=vendor. If the vendor directory has no modules.txt,
+// or its modules.txt does not list the module version of each requirement in go.mod, or lists as explicitly
+// required a module go.mod does not require, true and an error wrapping ErrVendorStale are returned.
+// A modules.txt written by go toolchains older than go1.14, which does not mark explicit requirements,
+// is only checked for existence.
+func ProjectVendored() (bool, error) {
+	root, err := FindProjectRoot()
+	if err != nil {
+		return false, fmt.Errorf("error finding project root: %w", err)
+	}
+	info, err := os.Stat(filepath.Join(root, vendorDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
+	}
+
+	modulesTxt := filepath.Join(vendorDir, vendorModulesTxt)
+	b, err := ioutil.ReadFile(filepath.Join(root, modulesTxt))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, fmt.Errorf("%w: %s does not exist; run 'go mod vendor'", ErrVendorStale, modulesTxt)
+		}
+		return true, fmt.Errorf("error reading %s: %w", modulesTxt, err)
+	}
+	vendored, explicit := parseVendorModules(b)
+	if explicit == nil {
+		return true, nil
+	}
+
+	goMod, _, mf, err := parseGoMod()
+	if err != nil {
+		return true, err
+	}
+	required := make(map[string]bool, len(mf.Require))
+	for _, r := range mf.Require {
+		required[r.Mod.Path] = true
+		version, ok := vendored[r.Mod.Path]
+		switch {
+		case !ok:
+			return true, fmt.Errorf("%w: %s requires %s %s, which is not in %s; run 'go mod vendor'",
+				ErrVendorStale, filepath.Base(goMod), r.Mod.Path, r.Mod.Version, modulesTxt)
+		case version != r.Mod.Version:
+			return true, fmt.Errorf("%w: %s requires %s %s, but %s has %s; run 'go mod vendor'",
+				ErrVendorStale, filepath.Base(goMod), r.Mod.Path, r.Mod.Version, modulesTxt, version)
+		case !explicit[r.Mod.Path]:
+			return true, fmt.Errorf("%w: %s requires %s, but %s does not mark it explicit; run 'go mod vendor'",
+				ErrVendorStale, filepath.Base(goMod), r.Mod.Path, modulesTxt)
+		}
+	}
+	paths := make([]string, 0, len(explicit))
+	for path := range explicit {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if !required[path] {
+			return true, fmt.Errorf("%w: %s marks %s explicit, but %s does not require it; run 'go mod vendor'",
+				ErrVendorStale, modulesTxt, path, filepath.Base(goMod))
+		}
+	}
+	return true, nil
+}
+
+// parseVendorModules returns the version of each module listed in the vendor/modules.txt contents b,
+// and the set of modules marked as explicit requirements, which is nil if none are marked.
+// Module lines have the form "# <path> <version>", optionally followed by "=> <replacement>",
+// and are followed by a "## explicit" line if go.mod requires the module.
+func parseVendorModules(b []byte) (versions map[string]string, explicit map[string]bool) {
+	versions = make(map[string]string)
+	var module string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if module == "" {
+				continue
+			}
+			for _, annotation := range strings.Split(strings.TrimPrefix(line, "## "), ";") {
+				if strings.TrimSpace(annotation) == "explicit" {
+					if explicit == nil {
+						explicit = make(map[string]bool)
+					}
+					explicit[module] = true
+				}
+			}
+		case strings.HasPrefix(line, "# "):
+			// Wildcard replacements, "# <path> => <replacement>", have no version and list no packages.
+			module = ""
+			if fields := strings.Fields(strings.TrimPrefix(line, "# ")); len(fields) >= 2 && fields[1] != "=>" {
+				module = fields[0]
+				versions[module] = fields[1]
+			}
+		}
+	}
+	return versions, explicit
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signutil signs container images with cosign, storing their
+// signatures in the images' registry.
+package signutil
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const (
+	// cosignBin is the cosign executable invoked to sign images.
+	// See https://github.com/sigstore/cosign
+	cosignBin = "cosign"
+	// keylessEnv enables cosign's keyless signing flow.
+	keylessEnv = "COSIGN_EXPERIMENTAL"
+)
+
+// digestRe matches a sha256 image manifest digest.
+var digestRe = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// Options configures how an image is signed.
+type Options struct {
+	// Key is the path to a cosign private key, or a KMS key URI such as
+	// "awskms:///<key ARN>". If empty, the image is signed keylessly: cosign
+	// obtains an OIDC identity token, either from the environment or by prompting
+	// for a browser login, and signs with a short-lived certificate bound to that
+	// identity.
+	Key string
+}
+
+// Sign signs image, which must already be pushed to its registry, and pushes the
+// signature to the same repository. Sign returns the reference of the signature.
+// If image is a tag, cosign signs the manifest the tag refers to when it is
+// resolved, so callers that know the digest of the image should pass a digest
+// reference built with DigestRef.
+func Sign(image string, opts Options) (string, error) {
+	if _, err := exec.LookPath(cosignBin); err != nil {
+		return "", fmt.Errorf("%s must be installed to sign images: %v", cosignBin, err)
+	}
+	if err := projutil.ExecCmd(createSignCommand(image, opts)); err != nil {
+		return "", err
+	}
+	return getSignatureRef(image)
+}
+
+// createSignCommand returns a command that signs image as configured by opts.
+func createSignCommand(image string, opts Options) *exec.Cmd {
+	args := []string{"sign"}
+	if opts.Key != "" {
+		args = append(args, "--key", opts.Key)
+	}
+	args = append(args, image)
+
+	cmd := exec.Command(cosignBin, args...)
+	if opts.Key == "" {
+		cmd.Env = append(os.Environ(), keylessEnv+"=1")
+	}
+	return cmd
+}
+
+// DigestRef returns the reference to digest in image's repository, ex.
+// "quay.io/example/operator@sha256:<digest>" for image "quay.io/example/operator:v0.0.1".
+func DigestRef(image, digest string) (string, error) {
+	if !digestRe.MatchString(digest) {
+		return "", fmt.Errorf("invalid digest %q of image %s", digest, image)
+	}
+	return Repository(image) + "@" + digest, nil
+}
+
+// Repository returns image without its tag or digest.
+func Repository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// A ':' before the last '/' separates a registry host from its port.
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// getSignatureRef returns the reference cosign stores image's signature at,
+// ex. "quay.io/example/operator:sha256-<digest>.sig".
+func getSignatureRef(image string) (string, error) {
+	out, err := createTriangulateCommand(image).Output()
+	if err != nil {
+		return "", fmt.Errorf("error getting signature reference of image %s: %v", image, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// createTriangulateCommand returns a command that prints the reference of
+// image's signature.
+func createTriangulateCommand(image string) *exec.Cmd {
+	return exec.Command(cosignBin, "triangulate", image)
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateSignCommand(t *testing.T) {
+	cases := []struct {
+		name         string
+		opts         Options
+		expectedArgs []string
+		keyless      bool
+	}{
+		{"keyless", Options{}, []string{"cosign", "sign", "img"}, true},
+		{"key", Options{Key: "cosign.key"}, []string{"cosign", "sign", "--key", "cosign.key", "img"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := createSignCommand("img", c.opts)
+			if !reflect.DeepEqual(cmd.Args, c.expectedArgs) {
+				t.Errorf("Wanted args %v, got: %v", c.expectedArgs, cmd.Args)
+			}
+			keyless := false
+			for _, env := range cmd.Env {
+				if env == keylessEnv+"=1" {
+					keyless = true
+				}
+			}
+			if keyless != c.keyless {
+				t.Errorf("Wanted keyless %v, got: %v", c.keyless, keyless)
+			}
+		})
+	}
+}
+
+const hexDigest = "c71cb4f7e8ececaffb34037c2637dc86820e4185100e18b4d02d613a9bd772af"
+
+func TestCreateTriangulateCommand(t *testing.T) {
+	const image = "quay.io/example/operator@sha256:" + hexDigest
+	cmd := createTriangulateCommand(image)
+	expectedArgs := []string{"cosign", "triangulate", image}
+	if !reflect.DeepEqual(cmd.Args, expectedArgs) {
+		t.Errorf("Wanted args %v, got: %v", expectedArgs, cmd.Args)
+	}
+}
+
+func TestDigestRef(t *testing.T) {
+	cases := []struct {
+		image       string
+		digest      string
+		expectedRef string
+		wantErr     bool
+	}{
+		{"quay.io/example/operator:v0.0.1", "sha256:" + hexDigest, "quay.io/example/operator@sha256:" + hexDigest, false},
+		{"quay.io/example/operator", "sha256:" + hexDigest, "quay.io/example/operator@sha256:" + hexDigest, false},
+		{"localhost:5000/operator:v0.0.1", "sha256:" + hexDigest, "localhost:5000/operator@sha256:" + hexDigest, false},
+		{"localhost:5000/operator", "sha256:" + hexDigest, "localhost:5000/operator@sha256:" + hexDigest, false},
+		{"quay.io/example/operator:v0.0.1@sha256:" + hexDigest, "sha256:" + hexDigest, "quay.io/example/operator@sha256:" + hexDigest, false},
+		{"quay.io/example/operator:v0.0.1", "", "", true},
+		{"quay.io/example/operator:v0.0.1", hexDigest, "", true},
+		{"quay.io/example/operator:v0.0.1", "sha256:abc", "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.image+" "+c.digest, func(t *testing.T) {
+			ref, err := DigestRef(c.image, c.digest)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("Wanted error, got ref %s", ref)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ref != c.expectedRef {
+				t.Errorf("Wanted ref %s, got: %s", c.expectedRef, ref)
+			}
+		})
+	}
+}
@@ -20,16 +20,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/operator-framework/operator-lib/handler"
+	libpredicate "github.com/operator-framework/operator-lib/predicate"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	crhandler "sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	sdkratelimiter "github.com/operator-framework/operator-sdk/internal/ratelimiter"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/events"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/runner"
 	"github.com/operator-framework/operator-sdk/pkg/predicate"
@@ -50,6 +56,21 @@ type Options struct {
 	WatchClusterScopedResources bool
 	MaxConcurrentReconciles     int
 	Selector                    metav1.LabelSelector
+	// FieldSelector selects the custom resources that trigger reconciles.
+	// Events of other custom resources are filtered out, and ansible-operator
+	// also configures the manager's cache to not list or watch them.
+	FieldSelector string
+	// SecondaryWatches are resources watched from startup whose changes
+	// enqueue their owning custom resource.
+	SecondaryWatches []schema.GroupVersionKind
+	// Logger is the base logger for this controller's reconciler and event
+	// logging. When nil, the global logger is used.
+	Logger logr.Logger
+	// RateLimiter limits how often requests are requeued. If nil, the
+	// controller-runtime default is used. A limiter built from the
+	// --reconcile-qps and --reconcile-burst flags also limits the requests
+	// enqueued by events of the watched custom resources.
+	RateLimiter ratelimiter.RateLimiter
 }
 
 // Add - Creates a new ansible operator controller and adds it to the manager
@@ -59,7 +80,7 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 	if options.EventHandlers == nil {
 		options.EventHandlers = []events.EventHandler{}
 	}
-	eventHandlers := append(options.EventHandlers, events.NewLoggingEventHandler(options.LoggingLevel))
+	eventHandlers := append(options.EventHandlers, events.NewLoggingEventHandlerWithLogger(options.LoggingLevel, options.Logger))
 
 	aor := &AnsibleOperatorReconciler{
 		Client:           mgr.GetClient(),
@@ -70,6 +91,7 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 		ManageStatus:     options.ManageStatus,
 		AnsibleDebugLogs: options.AnsibleDebugLogs,
 		APIReader:        mgr.GetAPIReader(),
+		Logger:           options.Logger,
 	}
 
 	scheme := mgr.GetScheme()
@@ -88,10 +110,7 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 
 	//Create new controller runtime controller and set the controller to watch GVK.
 	c, err := controller.New(fmt.Sprintf("%v-controller", strings.ToLower(options.GVK.Kind)), mgr,
-		controller.Options{
-			Reconciler:              aor,
-			MaxConcurrentReconciles: options.MaxConcurrentReconciles,
-		})
+		controllerOptions(aor, options))
 	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
@@ -105,12 +124,58 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 		log.Error(err, "Error in parsing selector")
 		os.Exit(1)
 	}
+	fieldPredicate, err := predicate.NewFieldFilterPredicate(options.FieldSelector)
+	if err != nil {
+		log.Error(err, "Error in parsing field selector")
+		os.Exit(1)
+	}
 
-	if err := c.Watch(&source.Kind{Type: u}, &handler.InstrumentedEnqueueRequestForObject{},
-		predicate.GenerationChangedPredicate{}, filterPredicate); err != nil {
+	if err := c.Watch(&source.Kind{Type: u}, primaryHandler(options),
+		primaryPredicate(), filterPredicate, fieldPredicate); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	if err := addSecondaryWatches(c, u, options.SecondaryWatches); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
 	return &c
 }
+
+// primaryPredicate returns the predicate for update events of watched CRs: they are reconciled when their
+// generation changes, or when PausedAnnotation changes so a paused CR resumes once the annotation is removed.
+func primaryPredicate() predicate.GenerationOrAnnotationChangedPredicate {
+	return predicate.GenerationOrAnnotationChangedPredicate{Annotations: []string{PausedAnnotation}}
+}
+
+// primaryHandler returns the event handler of watched CRs, which enqueues
+// requests at the rate allowed by options.RateLimiter.
+func primaryHandler(options Options) crhandler.EventHandler {
+	return sdkratelimiter.EventHandler(options.RateLimiter, &handler.InstrumentedEnqueueRequestForObject{})
+}
+
+// controllerOptions returns the options of the controller reconciling with r.
+func controllerOptions(r reconcile.Reconciler, options Options) controller.Options {
+	return controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
+		RateLimiter:             options.RateLimiter,
+	}
+}
+
+// addSecondaryWatches watches each of gvks, enqueueing a request for an
+// object's owner of the same type as owner when that object changes.
+func addSecondaryWatches(c controller.Controller, owner *unstructured.Unstructured, gvks []schema.GroupVersionKind) error {
+	for _, gvk := range gvks {
+		log.Info("Watching secondary resource", "kind", gvk, "enqueue_kind", owner.GroupVersionKind())
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		if err := c.Watch(&source.Kind{Type: u}, &crhandler.EnqueueRequestForOwner{OwnerType: owner},
+			libpredicate.DependentPredicate{}); err != nil {
+			return fmt.Errorf("error watching secondary resource %s: %w", gvk, err)
+		}
+	}
+	return nil
+}
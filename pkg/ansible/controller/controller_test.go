@@ -0,0 +1,277 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	crhandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/operator-framework/operator-sdk/internal/ratelimiter"
+	"github.com/operator-framework/operator-sdk/pkg/ansible/runner/eventapi"
+	"github.com/operator-framework/operator-sdk/pkg/ansible/runner/fake"
+)
+
+type watch struct {
+	src        source.Source
+	handler    crhandler.EventHandler
+	predicates []predicate.Predicate
+}
+
+// fakeController records watches instead of starting informers.
+type fakeController struct {
+	watches []watch
+}
+
+func (c *fakeController) Reconcile(reconcile.Request) (reconcile.Result, error) {
+	return reconcile.Result{}, nil
+}
+
+func (c *fakeController) Watch(src source.Source, h crhandler.EventHandler, ps ...predicate.Predicate) error {
+	c.watches = append(c.watches, watch{src: src, handler: h, predicates: ps})
+	return nil
+}
+
+func (c *fakeController) Start(<-chan struct{}) error {
+	return nil
+}
+
+func TestAddSecondaryWatches(t *testing.T) {
+	ownerGVK := schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "Memcached"}
+	secretGVK := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(ownerGVK)
+
+	c := &fakeController{}
+	if err := addSecondaryWatches(c, owner, []schema.GroupVersionKind{secretGVK}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(c.watches) != 1 {
+		t.Fatalf("Expected 1 watch, got %d", len(c.watches))
+	}
+	w := c.watches[0]
+
+	kind, ok := w.src.(*source.Kind)
+	if !ok {
+		t.Fatalf("Expected a *source.Kind source, got %T", w.src)
+	}
+	if gvk := kind.Type.GetObjectKind().GroupVersionKind(); gvk != secretGVK {
+		t.Fatalf("Expected source kind %s, got %s", secretGVK, gvk)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(ownerGVK, meta.RESTScopeNamespace)
+	if _, err := inject.SchemeInto(runtime.NewScheme(), w.handler); err != nil {
+		t.Fatalf("Unexpected error injecting scheme: %v", err)
+	}
+	if _, err := inject.MapperInto(mapper, w.handler); err != nil {
+		t.Fatalf("Unexpected error injecting mapper: %v", err)
+	}
+
+	oldSecret := &unstructured.Unstructured{}
+	oldSecret.SetGroupVersionKind(secretGVK)
+	oldSecret.SetName("memcached-secret")
+	oldSecret.SetNamespace("default")
+	oldSecret.SetResourceVersion("1")
+	oldSecret.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: ownerGVK.GroupVersion().String(),
+		Kind:       ownerGVK.Kind,
+		Name:       "example-memcached",
+		UID:        "uid",
+	}})
+	newSecret := oldSecret.DeepCopy()
+	newSecret.SetResourceVersion("2")
+	if err := unstructured.SetNestedField(newSecret.Object, "Zm9v", "data", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	evt := event.UpdateEvent{
+		MetaOld:   oldSecret,
+		ObjectOld: oldSecret,
+		MetaNew:   newSecret,
+		ObjectNew: newSecret,
+	}
+	for _, p := range w.predicates {
+		if !p.Update(evt) {
+			t.Fatalf("Expected predicate %T to pass a modified secondary resource", p)
+		}
+	}
+
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+	w.handler.Update(evt, q)
+
+	if q.Len() != 1 {
+		t.Fatalf("Expected 1 enqueued request, got %d", q.Len())
+	}
+	item, _ := q.Get()
+	expected := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "example-memcached"}}
+	if !reflect.DeepEqual(item, expected) {
+		t.Fatalf("Expected request %v, got %v", expected, item)
+	}
+}
+
+func TestControllerOptions(t *testing.T) {
+	limiter, err := ratelimiter.New(1, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r := &AnsibleOperatorReconciler{}
+	opts := controllerOptions(r, Options{MaxConcurrentReconciles: 2, RateLimiter: limiter})
+	if opts.Reconciler != r || opts.MaxConcurrentReconciles != 2 {
+		t.Fatalf("Unexpected controller options %+v", opts)
+	}
+	if opts.RateLimiter != limiter {
+		t.Fatalf("Expected the configured rate limiter, got %T", opts.RateLimiter)
+	}
+	if opts := controllerOptions(r, Options{}); opts.RateLimiter != nil {
+		t.Fatalf("Expected controller-runtime's default rate limiter, got %T", opts.RateLimiter)
+	}
+}
+
+func TestReconcileRateLimit(t *testing.T) {
+	const qps, burst, crs = 20, 2, 4
+	limiter, err := ratelimiter.New(qps, burst)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	options := Options{RateLimiter: limiter}
+	q := workqueue.NewRateLimitingQueue(controllerOptions(&AnsibleOperatorReconciler{}, options).RateLimiter)
+	defer q.ShutDown()
+
+	// A burst of new CRs is only enqueued immediately up to the burst, then at qps.
+	h := primaryHandler(options)
+	start := time.Now()
+	for i := 0; i < crs; i++ {
+		cr := &unstructured.Unstructured{}
+		cr.SetGroupVersionKind(schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "Memcached"})
+		cr.SetNamespace("default")
+		cr.SetName(fmt.Sprintf("memcached-%d", i))
+		h.Create(event.CreateEvent{Meta: cr, Object: cr}, q)
+	}
+	if q.Len() != burst {
+		t.Fatalf("Expected %d requests enqueued immediately, got %d", burst, q.Len())
+	}
+	waitForQueueLen(t, q, crs)
+	if elapsed, min := time.Since(start), (crs-burst)*time.Second/qps; elapsed < min*9/10 {
+		t.Fatalf("Expected requests beyond the burst to be enqueued after about %v, got %v", min, elapsed)
+	}
+
+	// Requeues take tokens from the same bucket, which is now empty.
+	for i := 0; i < crs; i++ {
+		item, _ := q.Get()
+		q.Done(item)
+	}
+	q.AddRateLimited(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "memcached-0"}})
+	if q.Len() != 0 {
+		t.Fatalf("Expected the requeue to be delayed, got %d enqueued requests", q.Len())
+	}
+	waitForQueueLen(t, q, 1)
+}
+
+// waitForQueueLen waits for q to have n requests.
+func waitForQueueLen(t *testing.T, q workqueue.Interface, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for q.Len() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d enqueued requests, got %d", n, q.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPausedReconcileResumes(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "operator-sdk", Version: "v1beta1", Kind: "Testing"}
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("default")
+	cr.SetName("paused")
+	cr.SetGeneration(1)
+	cr.SetAnnotations(map[string]string{PausedAnnotation: "true"})
+	c := fakeclient.NewFakeClient(cr)
+
+	errPaused := errors.New("runner must not run while paused")
+	r := &fake.Runner{Error: errPaused}
+	aor := &AnsibleOperatorReconciler{
+		GVK:          gvk,
+		Runner:       r,
+		Client:       c,
+		APIReader:    c,
+		ManageStatus: true,
+		// With no reconcile period, a paused CR is only reconciled again by a watch event.
+		ReconcilePeriod: 0,
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "paused"}}
+
+	result, err := aor.Reconcile(request)
+	if err != nil {
+		t.Fatalf("Unexpected error reconciling a paused CR: %v", err)
+	}
+	if result != (reconcile.Result{}) {
+		t.Fatalf("Unexpected result for a paused CR: %+v", result)
+	}
+
+	// Removing the annotation does not change the CR's generation, but must still pass the primary watch's predicate.
+	old := &unstructured.Unstructured{}
+	old.SetGroupVersionKind(gvk)
+	if err := c.Get(context.TODO(), request.NamespacedName, old); err != nil {
+		t.Fatalf("Unexpected error getting CR: %v", err)
+	}
+	unpaused := old.DeepCopy()
+	unpaused.SetAnnotations(nil)
+	if err := c.Update(context.TODO(), unpaused); err != nil {
+		t.Fatalf("Unexpected error updating CR: %v", err)
+	}
+	e := event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: unpaused, ObjectNew: unpaused}
+	if !primaryPredicate().Update(e) {
+		t.Fatal("Expected removing the paused annotation to trigger a reconcile")
+	}
+
+	r.Error = nil
+	r.JobEvents = []eventapi.JobEvent{{Event: eventapi.EventPlaybookOnStats, Created: eventapi.EventTime{Time: time.Now()}}}
+	if _, err := aor.Reconcile(request); err != nil {
+		t.Fatalf("Unexpected error reconciling an unpaused CR: %v", err)
+	}
+	got := &unstructured.Unstructured{}
+	got.SetGroupVersionKind(gvk)
+	if err := c.Get(context.TODO(), request.NamespacedName, got); err != nil {
+		t.Fatalf("Unexpected error getting CR: %v", err)
+	}
+	conditions, _, _ := unstructured.NestedSlice(got.Object, "status", "conditions")
+	if len(conditions) == 0 {
+		t.Fatal("Expected the unpaused CR's playbook to run and set status conditions")
+	}
+}
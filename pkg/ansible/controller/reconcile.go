@@ -32,6 +32,7 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/ansible/runner"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/runner/eventapi"
 
+	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -48,6 +49,12 @@ const (
 	// To use create a CR with an annotation "ansible.sdk.operatorframework.io/reconcile-period: 30s" or some other valid
 	// Duration. This will override the operators/or controllers reconcile period for that particular CR.
 	ReconcilePeriodAnnotation = "ansible.sdk.operatorframework.io/reconcile-period"
+
+	// PausedAnnotation - annotation used by a user to pause reconciliation of a CR.
+	// To use, set the annotation "ansible.sdk.operatorframework.io/paused: true" on a CR. While paused, the
+	// operator will not run the CR's playbook or role. Reconciliation resumes once the annotation is removed
+	// or set to "false".
+	PausedAnnotation = "ansible.sdk.operatorframework.io/paused"
 )
 
 // AnsibleOperatorReconciler - object to reconcile runner requests
@@ -60,6 +67,16 @@ type AnsibleOperatorReconciler struct {
 	ReconcilePeriod  time.Duration
 	ManageStatus     bool
 	AnsibleDebugLogs bool
+	// Logger is the base logger for reconciles. When nil, the global logger
+	// is used.
+	Logger logr.Logger
+}
+
+func (r *AnsibleOperatorReconciler) logger() logr.Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return logf.Log
 }
 
 // Reconcile - handle the event.
@@ -75,7 +92,7 @@ func (r *AnsibleOperatorReconciler) Reconcile(request reconcile.Request) (reconc
 	}
 
 	ident := strconv.Itoa(rand.Int())
-	logger := logf.Log.WithName("reconciler").WithValues(
+	logger := r.logger().WithName("reconciler").WithValues(
 		"job", ident,
 		"name", u.GetName(),
 		"namespace", u.GetNamespace(),
@@ -97,6 +114,13 @@ func (r *AnsibleOperatorReconciler) Reconcile(request reconcile.Request) (reconc
 		reconcileResult.RequeueAfter = duration
 	}
 
+	// Paused CRs are not requeued: changes to the annotation pass the primary watch's predicate,
+	// so reconciliation resumes once the annotation is removed or set to "false".
+	if isPaused(u) {
+		logger.Info("Reconciliation is paused, skipping", "annotation", PausedAnnotation)
+		return reconcile.Result{}, nil
+	}
+
 	deleted := u.GetDeletionTimestamp() != nil
 	finalizer, finalizerExists := r.Runner.GetFinalizer()
 	pendingFinalizers := u.GetFinalizers()
@@ -316,7 +340,7 @@ func (r *AnsibleOperatorReconciler) markRunning(u *unstructured.Unstructured,
 // i.e Annotations that could be incorrect
 func (r *AnsibleOperatorReconciler) markError(u *unstructured.Unstructured, namespacedName types.NamespacedName,
 	failureMessage string) error {
-	logger := logf.Log.WithName("markError")
+	logger := r.logger().WithName("markError")
 	// Immediately update metrics with failed reconciliation, since Get()
 	// may fail.
 	metrics.ReconcileFailed(r.GVK.String())
@@ -352,7 +376,7 @@ func (r *AnsibleOperatorReconciler) markError(u *unstructured.Unstructured, name
 
 func (r *AnsibleOperatorReconciler) markDone(u *unstructured.Unstructured, namespacedName types.NamespacedName,
 	statusEvent eventapi.StatusJobEvent, failureMessages eventapi.FailureMessages) error {
-	logger := logf.Log.WithName("markDone")
+	logger := r.logger().WithName("markDone")
 	// Get the latest resource to prevent updating a stale status.
 	if err := r.APIReader.Get(context.TODO(), namespacedName, u); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -400,6 +424,21 @@ func (r *AnsibleOperatorReconciler) markDone(u *unstructured.Unstructured, names
 	return r.Client.Status().Update(context.TODO(), u)
 }
 
+// isPaused returns true if u has PausedAnnotation set to a true boolean value.
+func isPaused(u *unstructured.Unstructured) bool {
+	paused, ok := u.GetAnnotations()[PausedAnnotation]
+	if !ok {
+		return false
+	}
+	value, err := strconv.ParseBool(paused)
+	if err != nil {
+		logf.Log.WithName("reconciler").Info("Could not parse annotation as a boolean",
+			"annotation", PausedAnnotation, "value informed", paused)
+		return false
+	}
+	return value
+}
+
 func contains(l []string, s string) bool {
 	for _, elem := range l {
 		if elem == s {
@@ -16,6 +16,7 @@ package controller_test
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -71,6 +72,48 @@ func TestReconcile(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:            "paused reconcile",
+			GVK:             gvk,
+			ReconcilePeriod: 5 * time.Second,
+			ManageStatus:    true,
+			Runner: &fake.Runner{
+				Error: errors.New("runner must not run while paused"),
+			},
+			Client: fakeclient.NewFakeClient(&unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":      "paused",
+						"namespace": "default",
+						"annotations": map[string]interface{}{
+							controller.PausedAnnotation: "true",
+						},
+					},
+					"apiVersion": "operator-sdk/v1beta1",
+					"kind":       "Testing",
+				},
+			}),
+			Result: reconcile.Result{},
+			Request: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "paused",
+					Namespace: "default",
+				},
+			},
+			ExpectedObject: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":      "paused",
+						"namespace": "default",
+						"annotations": map[string]interface{}{
+							controller.PausedAnnotation: "true",
+						},
+					},
+					"apiVersion": "operator-sdk/v1beta1",
+					"kind":       "Testing",
+				},
+			},
+		},
 		{
 			Name:            "completed reconcile",
 			GVK:             gvk,
@@ -20,6 +20,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/pkg/ansible/runner/eventapi"
 
+	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -45,6 +46,7 @@ type EventHandler interface {
 
 type loggingEventHandler struct {
 	LogLevel LogLevel
+	Logger   logr.Logger
 }
 
 func (l loggingEventHandler) Handle(ident string, u *unstructured.Unstructured, e eventapi.JobEvent) {
@@ -52,7 +54,11 @@ func (l loggingEventHandler) Handle(ident string, u *unstructured.Unstructured,
 		return
 	}
 
-	logger := logf.Log.WithName("logging_event_handler").WithValues(
+	base := l.Logger
+	if base == nil {
+		base = logf.Log
+	}
+	logger := base.WithName("logging_event_handler").WithValues(
 		"name", u.GetName(),
 		"namespace", u.GetNamespace(),
 		"gvk", u.GroupVersionKind().String(),
@@ -115,3 +121,12 @@ func NewLoggingEventHandler(l LogLevel) EventHandler {
 		LogLevel: l,
 	}
 }
+
+// NewLoggingEventHandlerWithLogger - Creates a Logging Event Handler to log
+// events with logger.
+func NewLoggingEventHandlerWithLogger(l LogLevel, logger logr.Logger) EventHandler {
+	return loggingEventHandler{
+		LogLevel: l,
+		Logger:   logger,
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/operator-framework/operator-sdk/internal/log/zap"
+	"github.com/operator-framework/operator-sdk/internal/ratelimiter"
 )
 
 // Flags - Options to be used by an ansible operator
@@ -36,6 +37,9 @@ type Flags struct {
 	MetricsAddress          string
 	LeaderElectionID        string
 	LeaderElectionNamespace string
+	WatchFieldSelector      string
+	ReconcileQPS            float64
+	ReconcileBurst          int
 }
 
 const AnsibleRolesPathEnvVar = "ANSIBLE_ROLES_PATH"
@@ -64,6 +68,16 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		runtime.NumCPU(),
 		"Maximum number of concurrent reconciles for controllers. Overridden by environment variable.",
 	)
+	flagSet.Float64Var(&f.ReconcileQPS,
+		"reconcile-qps",
+		ratelimiter.DefaultQPS,
+		ratelimiter.QPSUsage,
+	)
+	flagSet.IntVar(&f.ReconcileBurst,
+		"reconcile-burst",
+		ratelimiter.DefaultBurst,
+		ratelimiter.BurstUsage,
+	)
 	flagSet.IntVar(&f.AnsibleVerbosity,
 		"ansible-verbosity",
 		2,
@@ -102,4 +116,11 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 			" holding the leader lock (required if running locally with leader"+
 			" election enabled).",
 	)
+	flagSet.StringVar(&f.WatchFieldSelector,
+		"watch-field-selector",
+		"",
+		"Field selector, ex. 'metadata.namespace=foo', of custom resources that trigger reconciles. "+
+			"Custom resources only support selecting metadata.name and metadata.namespace. "+
+			"Overridden by a watch's fieldSelector.",
+	)
 }
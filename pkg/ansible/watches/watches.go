@@ -27,13 +27,16 @@ import (
 	"strings"
 	"time"
 
+	"go.uber.org/zap/zapcore"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	yaml "sigs.k8s.io/yaml"
 
+	"github.com/operator-framework/operator-sdk/internal/log/zap"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 	"github.com/operator-framework/operator-sdk/pkg/ansible/flags"
+	"github.com/operator-framework/operator-sdk/pkg/predicate"
 )
 
 var log = logf.Log.WithName("watches")
@@ -54,6 +57,9 @@ type Watch struct {
 	WatchClusterScopedResources bool                      `yaml:"watchClusterScopedResources"`
 	SnakeCaseParameters         bool                      `yaml:"snakeCaseParameters"`
 	Selector                    metav1.LabelSelector      `yaml:"selector"`
+	LogLevel                    string                    `yaml:"logLevel"`
+	FieldSelector               string                    `yaml:"fieldSelector"`
+	SecondaryWatches            []schema.GroupVersionKind `yaml:"secondaryWatches"`
 
 	// Not configurable via watches.yaml
 	MaxConcurrentReconciles int `yaml:"-"`
@@ -131,6 +137,9 @@ type alias struct {
 	Blacklist                   []schema.GroupVersionKind `yaml:"blacklist,omitempty"`
 	Finalizer                   *Finalizer                `yaml:"finalizer"`
 	Selector                    tempLabelSelector         `yaml:"selector"`
+	LogLevel                    string                    `yaml:"logLevel,omitempty"`
+	FieldSelector               string                    `yaml:"fieldSelector,omitempty"`
+	SecondaryWatches            []schema.GroupVersionKind `yaml:"secondaryWatches,omitempty"`
 }
 
 // buildWatch will build Watch based on the values parsed from alias
@@ -173,6 +182,25 @@ func (w *Watch) setValuesFromAlias(tmp alias) error {
 		return fmt.Errorf("invalid GVK: %s: %w", gvk, err)
 	}
 
+	// A per-watch log level overrides the global ansible verbosity default,
+	// but not the ANSIBLE_VERBOSITY_<KIND>_<GROUP> environment variable.
+	verbosityDefault := ansibleVerbosityDefault
+	if tmp.LogLevel != "" {
+		lvl, err := zap.ParseLevel(tmp.LogLevel)
+		if err != nil {
+			return fmt.Errorf("invalid logLevel for GVK: %s: %w", gvk, err)
+		}
+		verbosityDefault = ansibleVerbosityForLevel(lvl)
+	}
+
+	if _, err := predicate.NewFieldFilterPredicate(tmp.FieldSelector); err != nil {
+		return fmt.Errorf("invalid fieldSelector for GVK: %s: %w", gvk, err)
+	}
+
+	if err := verifySecondaryWatches(gvk, tmp.SecondaryWatches); err != nil {
+		return fmt.Errorf("invalid secondaryWatches for GVK: %s: %w", gvk, err)
+	}
+
 	// Rewrite values to struct being unmarshalled
 	w.GroupVersionKind = gvk
 	w.Playbook = tmp.Playbook
@@ -186,8 +214,11 @@ func (w *Watch) setValuesFromAlias(tmp alias) error {
 	w.SnakeCaseParameters = *tmp.SnakeCaseParameters
 	w.WatchClusterScopedResources = *tmp.WatchClusterScopedResources
 	w.Finalizer = tmp.Finalizer
-	w.AnsibleVerbosity = getAnsibleVerbosity(gvk, ansibleVerbosityDefault)
+	w.AnsibleVerbosity = getAnsibleVerbosity(gvk, verbosityDefault)
+	w.LogLevel = tmp.LogLevel
+	w.FieldSelector = tmp.FieldSelector
 	w.Blacklist = tmp.Blacklist
+	w.SecondaryWatches = tmp.SecondaryWatches
 	w.addRolePlaybookPaths()
 	w.Selector = parseLabelSelector(tmp.Selector)
 
@@ -381,6 +412,25 @@ func verifyGVK(gvk schema.GroupVersionKind) error {
 	return nil
 }
 
+// verify that each secondary watch GVK is valid, unique, and not the
+// primary GVK, whose changes are already watched.
+func verifySecondaryWatches(primary schema.GroupVersionKind, secondaries []schema.GroupVersionKind) error {
+	seen := make(map[schema.GroupVersionKind]bool, len(secondaries))
+	for _, gvk := range secondaries {
+		if err := verifyGVK(gvk); err != nil {
+			return fmt.Errorf("%s: %w", gvk, err)
+		}
+		if gvk == primary {
+			return fmt.Errorf("%s: must not be the watched GVK", gvk)
+		}
+		if seen[gvk] {
+			return fmt.Errorf("duplicate GVK: %s", gvk)
+		}
+		seen[gvk] = true
+	}
+	return nil
+}
+
 // verify that a valid path is specified for a given role or playbook
 func verifyAnsiblePath(playbook string, role string) error {
 	switch {
@@ -445,6 +495,24 @@ func getAnsibleVerbosity(gvk schema.GroupVersionKind, defValue int) int {
 	return ansibleVerbosity
 }
 
+// ansibleVerbosityForLevel maps a log level to the ansible-runner verbosity
+// producing comparable output: 'error' runs ansible quietly, 'info' uses the
+// default verbosity, and 'debug' and each higher integer level add a -v, up
+// to the maximum verbosity of 7.
+func ansibleVerbosityForLevel(lvl zapcore.Level) int {
+	switch {
+	case lvl >= zapcore.ErrorLevel:
+		return 0
+	case lvl >= zapcore.InfoLevel:
+		return 2
+	}
+	verbosity := 2 - int(lvl)
+	if verbosity > 7 {
+		verbosity = 7
+	}
+	return verbosity
+}
+
 // getIntegerEnvWithDefault returns value for MaxWorkers/Ansibleverbosity based on if envVar is set
 // sor a defvalue is used.
 func getIntegerEnvWithDefault(envVar string, defValue int) int {
@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/operator-framework/operator-sdk/internal/log/zap"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -369,6 +370,40 @@ func TestLoad(t *testing.T) {
 			},
 			ManageStatus: true,
 		},
+		Watch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Version: "v1alpha1",
+				Group:   "app.example.com",
+				Kind:    "AnsibleLogLevelTest",
+			},
+			Role:             validTemplate.ValidRole,
+			ManageStatus:     true,
+			LogLevel:         "debug",
+			AnsibleVerbosity: 3,
+		},
+		Watch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Version: "v1alpha1",
+				Group:   "app.example.com",
+				Kind:    "AnsibleFieldSelectorTest",
+			},
+			Role:          validTemplate.ValidRole,
+			ManageStatus:  true,
+			FieldSelector: "metadata.namespace=foo",
+		},
+		Watch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Version: "v1alpha1",
+				Group:   "app.example.com",
+				Kind:    "AnsibleSecondaryWatchesTest",
+			},
+			Role:         validTemplate.ValidRole,
+			ManageStatus: true,
+			SecondaryWatches: []schema.GroupVersionKind{
+				{Version: "v1", Kind: "Secret"},
+				{Version: "v1", Group: "apps", Kind: "Deployment"},
+			},
+		},
 	}
 
 	testCases := []struct {
@@ -446,6 +481,26 @@ func TestLoad(t *testing.T) {
 			path:        "testdata/invalid_status.yaml",
 			shouldError: true,
 		},
+		{
+			name:        "error invalid log level",
+			path:        "testdata/invalid_log_level.yaml",
+			shouldError: true,
+		},
+		{
+			name:        "error invalid field selector",
+			path:        "testdata/invalid_field_selector.yaml",
+			shouldError: true,
+		},
+		{
+			name:        "error invalid secondary watch GVK",
+			path:        "testdata/invalid_secondary_watches.yaml",
+			shouldError: true,
+		},
+		{
+			name:        "error duplicate secondary watch GVK",
+			path:        "testdata/duplicate_secondary_watches.yaml",
+			shouldError: true,
+		},
 		{
 			name:        "if collection env var is not set and collection is not installed to the default locations, fail",
 			path:        "testdata/invalid_collection.yaml",
@@ -547,6 +602,30 @@ func TestLoad(t *testing.T) {
 						gotWatch.Selector, expectedWatch.Selector)
 				}
 
+				if !reflect.DeepEqual(gotWatch.SecondaryWatches, expectedWatch.SecondaryWatches) {
+					t.Fatalf("Incorrect secondary watches GVK %s:\n\tgot %v\n\texpected %v", gvk,
+						gotWatch.SecondaryWatches, expectedWatch.SecondaryWatches)
+				}
+
+				if gotWatch.FieldSelector != expectedWatch.FieldSelector {
+					t.Fatalf("The GVK: %v unexpected field selector: %v expected field selector: %v", gvk,
+						gotWatch.FieldSelector, expectedWatch.FieldSelector)
+				}
+
+				if gotWatch.LogLevel != expectedWatch.LogLevel {
+					t.Fatalf("The GVK: %v unexpected log level: %v expected log level: %v", gvk,
+						gotWatch.LogLevel, expectedWatch.LogLevel)
+				}
+
+				expectedVerbosity := expectedWatch.AnsibleVerbosity
+				if expectedVerbosity == 0 {
+					expectedVerbosity = tc.ansibleVerbosity
+				}
+				if gotWatch.AnsibleVerbosity != expectedVerbosity {
+					t.Fatalf("The GVK: %v unexpected ansible verbosity: %v expected ansible verbosity: %v", gvk,
+						gotWatch.AnsibleVerbosity, expectedVerbosity)
+				}
+
 				if expectedWatch.MaxConcurrentReconciles == 0 {
 					if gotWatch.MaxConcurrentReconciles != tc.maxConcurrentReconciles {
 						t.Fatalf("Unexpected max workers: %v expected workers: %v", gotWatch.MaxConcurrentReconciles,
@@ -765,6 +844,32 @@ func TestAnsibleVerbosity(t *testing.T) {
 	}
 }
 
+func TestAnsibleVerbosityForLevel(t *testing.T) {
+	testCases := []struct {
+		logLevel      string
+		expectedValue int
+	}{
+		{logLevel: "error", expectedValue: 0},
+		{logLevel: "info", expectedValue: 2},
+		{logLevel: "debug", expectedValue: 3},
+		{logLevel: "2", expectedValue: 4},
+		{logLevel: "5", expectedValue: 7},
+		{logLevel: "10", expectedValue: 7},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.logLevel, func(t *testing.T) {
+			lvl, err := zap.ParseLevel(tc.logLevel)
+			if err != nil {
+				t.Fatalf("Unexpected error parsing log level %q: %v", tc.logLevel, err)
+			}
+			if verbosity := ansibleVerbosityForLevel(lvl); verbosity != tc.expectedValue {
+				t.Fatalf("Unexpected Verbosity: %v expected Verbosity: %v", verbosity, tc.expectedValue)
+			}
+		})
+	}
+}
+
 // Test the func getPossibleRolePaths.
 func TestGetPossibleRolePaths(t *testing.T) {
 	// Mock default Full Path based in the current directory
@@ -15,6 +15,7 @@
 package v1alpha3
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -52,4 +53,25 @@ type TestConfiguration struct {
 	Entrypoint []string `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
 	// Labels further describe the test and enable selection.
 	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// ServiceAccount configures the service account the test pod runs as.
+	// If unset, the test pod runs as the scorecard's configured service account.
+	ServiceAccount *ServiceAccountConfiguration `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+	// NodeSelector is merged into the test pod's node selector, so the test can be scheduled on
+	// specific nodes. Entries override those set for all tests with the same key.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+	// Tolerations are added to the test pod's tolerations, so the test can be scheduled on tainted nodes.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
+	// Affinity is the test pod's scheduling affinity.
+	Affinity *corev1.Affinity `json:"affinity,omitempty" yaml:"affinity,omitempty"`
+}
+
+// ServiceAccountConfiguration configures the service account a test pod runs as.
+// At least one of Name or ClusterRole must be set.
+type ServiceAccountConfiguration struct {
+	// Name is the name of an existing service account in the test namespace.
+	// If empty, a service account is created for the test and deleted during cleanup.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// ClusterRole is the name of an existing ClusterRole to bind to the service account.
+	// The ClusterRoleBinding is created for the test and deleted during cleanup.
+	ClusterRole string `json:"clusterRole,omitempty" yaml:"clusterRole,omitempty"`
 }
@@ -30,13 +30,17 @@ import (
 	crthandler "sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/yaml"
 
 	libhandler "github.com/operator-framework/operator-lib/handler"
 	"github.com/operator-framework/operator-lib/predicate"
+	sdkratelimiter "github.com/operator-framework/operator-sdk/internal/ratelimiter"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/helm/release"
+	sdkpredicate "github.com/operator-framework/operator-sdk/pkg/predicate"
 )
 
 var log = logf.Log.WithName("helm.controller")
@@ -50,7 +54,18 @@ type WatchOptions struct {
 	ReconcilePeriod         time.Duration
 	WatchDependentResources bool
 	OverrideValues          map[string]string
+	StatusFields            map[string]string
 	MaxConcurrentReconciles int
+	// FieldSelector selects the custom resources that trigger reconciles.
+	// Events of other custom resources are filtered out, and helm-operator
+	// also configures the manager's cache to not list or watch them.
+	FieldSelector   string
+	ImmutableValues []string
+	// RateLimiter limits how often requests are requeued. If nil, the
+	// controller-runtime default is used. A limiter built from the
+	// --reconcile-qps and --reconcile-burst flags also limits the requests
+	// enqueued by events of the watched custom resources.
+	RateLimiter ratelimiter.RateLimiter
 }
 
 // Add creates a new helm operator controller and adds it to the manager
@@ -64,23 +79,27 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 		ManagerFactory:  options.ManagerFactory,
 		ReconcilePeriod: options.ReconcilePeriod,
 		OverrideValues:  options.OverrideValues,
+		StatusFields:    options.StatusFields,
+		ImmutableValues: options.ImmutableValues,
 	}
 
 	// Register the GVK with the schema
 	mgr.GetScheme().AddKnownTypeWithName(options.GVK, &unstructured.Unstructured{})
 	metav1.AddToGroupVersion(mgr.GetScheme(), options.GVK.GroupVersion())
 
-	c, err := controller.New(controllerName, mgr, controller.Options{
-		Reconciler:              r,
-		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
-	})
+	c, err := controller.New(controllerName, mgr, controllerOptions(r, options))
+	if err != nil {
+		return err
+	}
+
+	fieldPredicate, err := sdkpredicate.NewFieldFilterPredicate(options.FieldSelector)
 	if err != nil {
 		return err
 	}
 
 	o := &unstructured.Unstructured{}
 	o.SetGroupVersionKind(options.GVK)
-	if err := c.Watch(&source.Kind{Type: o}, &handler.InstrumentedEnqueueRequestForObject{}); err != nil {
+	if err := c.Watch(&source.Kind{Type: o}, primaryHandler(options), fieldPredicate); err != nil {
 		return err
 	}
 
@@ -89,10 +108,26 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 	}
 
 	log.Info("Watching resource", "apiVersion", options.GVK.GroupVersion(), "kind",
-		options.GVK.Kind, "namespace", options.Namespace, "reconcilePeriod", options.ReconcilePeriod.String())
+		options.GVK.Kind, "namespace", options.Namespace, "reconcilePeriod", options.ReconcilePeriod.String(),
+		"fieldSelector", options.FieldSelector)
 	return nil
 }
 
+// primaryHandler returns the event handler of watched CRs, which enqueues
+// requests at the rate allowed by options.RateLimiter.
+func primaryHandler(options WatchOptions) crthandler.EventHandler {
+	return sdkratelimiter.EventHandler(options.RateLimiter, &handler.InstrumentedEnqueueRequestForObject{})
+}
+
+// controllerOptions returns the options of the controller reconciling with r.
+func controllerOptions(r reconcile.Reconciler, options WatchOptions) controller.Options {
+	return controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
+		RateLimiter:             options.RateLimiter,
+	}
+}
+
 // watchDependentResources adds a release hook function to the HelmOperatorReconciler
 // that adds watches for resources in released Helm charts.
 func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c controller.Controller) {
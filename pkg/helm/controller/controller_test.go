@@ -0,0 +1,79 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-sdk/internal/ratelimiter"
+)
+
+func TestControllerOptions(t *testing.T) {
+	limiter, err := ratelimiter.New(1, 5)
+	assert.NoError(t, err)
+
+	r := &HelmOperatorReconciler{}
+	opts := controllerOptions(r, WatchOptions{MaxConcurrentReconciles: 2, RateLimiter: limiter})
+	assert.Equal(t, r, opts.Reconciler)
+	assert.Equal(t, 2, opts.MaxConcurrentReconciles)
+	assert.True(t, opts.RateLimiter == limiter, "configured rate limiter is not used")
+
+	opts = controllerOptions(r, WatchOptions{})
+	assert.Nil(t, opts.RateLimiter, "controller-runtime's default rate limiter is not used")
+}
+
+func TestReconcileRateLimit(t *testing.T) {
+	const qps, burst, crs = 20, 2, 4
+	limiter, err := ratelimiter.New(qps, burst)
+	require.NoError(t, err)
+	options := WatchOptions{RateLimiter: limiter}
+	q := workqueue.NewRateLimitingQueue(controllerOptions(&HelmOperatorReconciler{}, options).RateLimiter)
+	defer q.ShutDown()
+
+	// A burst of new CRs is only enqueued immediately up to the burst, then at qps.
+	h := primaryHandler(options)
+	start := time.Now()
+	for i := 0; i < crs; i++ {
+		cr := &unstructured.Unstructured{}
+		cr.SetGroupVersionKind(schema.GroupVersionKind{Group: "app.example.com", Version: "v1alpha1", Kind: "Nginx"})
+		cr.SetNamespace("default")
+		cr.SetName(fmt.Sprintf("nginx-%d", i))
+		h.Create(event.CreateEvent{Meta: cr, Object: cr}, q)
+	}
+	require.Equal(t, burst, q.Len(), "requests beyond the burst are not delayed")
+	require.Eventually(t, func() bool { return q.Len() == crs }, 5*time.Second, 5*time.Millisecond)
+	min := (crs - burst) * time.Second / qps
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(min*9/10), "requests beyond the burst are not enqueued at qps")
+
+	// Requeues take tokens from the same bucket, which is now empty.
+	for i := 0; i < crs; i++ {
+		item, _ := q.Get()
+		q.Done(item)
+	}
+	q.AddRateLimited(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx-0"}})
+	require.Equal(t, 0, q.Len(), "requeue is not delayed")
+	require.Eventually(t, func() bool { return q.Len() == 1 }, 5*time.Second, 5*time.Millisecond)
+}
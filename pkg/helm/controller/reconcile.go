@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	rpb "helm.sh/helm/v3/pkg/release"
@@ -37,6 +38,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/util/diffutil"
 	"github.com/operator-framework/operator-sdk/pkg/helm/internal/types"
 	"github.com/operator-framework/operator-sdk/pkg/helm/release"
+	"github.com/operator-framework/operator-sdk/pkg/helm/watches"
 )
 
 // blank assignment to verify that HelmOperatorReconciler implements reconcile.Reconciler
@@ -53,11 +55,21 @@ type HelmOperatorReconciler struct {
 	ManagerFactory  release.ManagerFactory
 	ReconcilePeriod time.Duration
 	OverrideValues  map[string]string
+	StatusFields    map[string]string
+	// ImmutableValues are dot-separated chart value paths that cannot change once
+	// a release is installed.
+	ImmutableValues []string
 	releaseHook     ReleaseHookFunc
 }
 
 const (
 	finalizer = "uninstall-helm-release"
+
+	// PausedAnnotation - annotation used by a user to pause reconciliation of a CR.
+	// To use, set the annotation "helm.sdk.operatorframework.io/paused: true" on a CR. While paused, the
+	// operator will not install, upgrade, or uninstall the CR's release. Reconciliation resumes once the
+	// annotation is removed or set to "false".
+	PausedAnnotation = "helm.sdk.operatorframework.io/paused"
 )
 
 // Reconcile reconciles the requested resource by installing, updating, or
@@ -87,6 +99,11 @@ func (r HelmOperatorReconciler) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, err
 	}
 
+	if isPaused(o) {
+		log.Info("Reconciliation is paused, skipping", "annotation", PausedAnnotation)
+		return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, nil
+	}
+
 	manager, err := r.ManagerFactory.NewManager(o, r.OverrideValues)
 	if err != nil {
 		log.Error(err, "Failed to get release manager")
@@ -223,7 +240,7 @@ func (r HelmOperatorReconciler) Reconcile(request reconcile.Request) (reconcile.
 			Name:     installedRelease.Name,
 			Manifest: installedRelease.Manifest,
 		}
-		err = r.updateResourceStatus(o, status)
+		err = r.updateResourceStatusForRelease(o, status, installedRelease)
 		return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, err
 	}
 
@@ -237,6 +254,26 @@ func (r HelmOperatorReconciler) Reconcile(request reconcile.Request) (reconcile.
 	}
 
 	if manager.IsUpgradeRequired() {
+		// Reject upgrades that change immutable values up front, since they
+		// would fail or recreate resources, ex. a StatefulSet's volumeClaimTemplates.
+		if changed := manager.ChangedValues(r.ImmutableValues); len(changed) != 0 {
+			message := fmt.Sprintf("Upgrade rejected: immutable chart values %s cannot be changed "+
+				"from the installed release's values; revert them to resume upgrades",
+				strings.Join(changed, ", "))
+			log.Info("Upgrade rejected, immutable chart values changed", "values", changed)
+			r.EventRecorder.Event(o, "Warning", string(types.ReasonImmutableValues), message)
+			status.SetCondition(types.HelmAppCondition{
+				Type:    types.ConditionReleaseFailed,
+				Status:  types.StatusTrue,
+				Reason:  types.ReasonImmutableValues,
+				Message: message,
+			})
+			// Retrying cannot succeed until the custom resource changes, which
+			// triggers a reconcile, so do not return an error.
+			err := r.updateResourceStatus(o, status)
+			return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, err
+		}
+
 		for k, v := range r.OverrideValues {
 			r.EventRecorder.Eventf(o, "Warning", "OverrideValuesInUse",
 				"Chart value %q overridden to %q by operator's watches.yaml", k, v)
@@ -282,7 +319,7 @@ func (r HelmOperatorReconciler) Reconcile(request reconcile.Request) (reconcile.
 			Name:     upgradedRelease.Name,
 			Manifest: upgradedRelease.Manifest,
 		}
-		err = r.updateResourceStatus(o, status)
+		err = r.updateResourceStatusForRelease(o, status, upgradedRelease)
 		return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, err
 	}
 
@@ -320,7 +357,7 @@ func (r HelmOperatorReconciler) Reconcile(request reconcile.Request) (reconcile.
 		Name:     expectedRelease.Name,
 		Manifest: expectedRelease.Manifest,
 	}
-	err = r.updateResourceStatus(o, status)
+	err = r.updateResourceStatusForRelease(o, status, expectedRelease)
 	return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, err
 }
 
@@ -342,6 +379,21 @@ func hasHelmUpgradeForceAnnotation(o *unstructured.Unstructured) bool {
 	return value
 }
 
+// isPaused returns true if o has PausedAnnotation set to a true boolean value.
+func isPaused(o *unstructured.Unstructured) bool {
+	paused, ok := o.GetAnnotations()[PausedAnnotation]
+	if !ok {
+		return false
+	}
+	value, err := strconv.ParseBool(paused)
+	if err != nil {
+		log.Info("Could not parse annotation as a boolean",
+			"annotation", PausedAnnotation, "value informed", paused)
+		return false
+	}
+	return value
+}
+
 func (r HelmOperatorReconciler) updateResource(o runtime.Object) error {
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		return r.Client.Update(context.TODO(), o)
@@ -349,12 +401,85 @@ func (r HelmOperatorReconciler) updateResource(o runtime.Object) error {
 }
 
 func (r HelmOperatorReconciler) updateResourceStatus(o *unstructured.Unstructured, status *types.HelmAppStatus) error {
+	return r.updateResourceStatusForRelease(o, status, nil)
+}
+
+// updateResourceStatusForRelease updates o's status to status. Status fields mapped from
+// release attributes by r.StatusFields are set from rel, or preserved from o's current
+// status if rel is nil.
+func (r HelmOperatorReconciler) updateResourceStatusForRelease(o *unstructured.Unstructured,
+	status *types.HelmAppStatus, rel *rpb.Release) error {
+	newStatus, err := r.statusWithFields(o, status, rel)
+	if err != nil {
+		return err
+	}
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-		o.Object["status"] = status
+		o.Object["status"] = newStatus
 		return r.Client.Status().Update(context.TODO(), o)
 	})
 }
 
+// statusWithFields returns status with each status field mapped by r.StatusFields set.
+func (r HelmOperatorReconciler) statusWithFields(o *unstructured.Unstructured, status *types.HelmAppStatus,
+	rel *rpb.Release) (interface{}, error) {
+	if len(r.StatusFields) == 0 {
+		return status, nil
+	}
+	out, err := status.ToMap()
+	if err != nil {
+		return nil, err
+	}
+	current, _ := o.Object["status"].(map[string]interface{})
+	for attr, path := range r.StatusFields {
+		fields := strings.Split(path, ".")
+		var value interface{}
+		if rel != nil {
+			value = releaseAttribute(rel, attr)
+		} else if v, found, err := unstructured.NestedFieldCopy(current, fields...); err == nil && found {
+			value = v
+		} else {
+			continue
+		}
+		if err := unstructured.SetNestedField(out, value, fields...); err != nil {
+			return nil, fmt.Errorf("error setting status field %q: %w", path, err)
+		}
+	}
+	return out, nil
+}
+
+// releaseAttribute returns the value of rel's attribute attr.
+func releaseAttribute(rel *rpb.Release, attr string) interface{} {
+	switch attr {
+	case watches.StatusFieldName:
+		return rel.Name
+	case watches.StatusFieldNamespace:
+		return rel.Namespace
+	case watches.StatusFieldRevision:
+		return int64(rel.Version)
+	}
+	if rel.Info != nil {
+		switch attr {
+		case watches.StatusFieldStatus:
+			return rel.Info.Status.String()
+		case watches.StatusFieldDescription:
+			return rel.Info.Description
+		case watches.StatusFieldNotes:
+			return rel.Info.Notes
+		}
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		switch attr {
+		case watches.StatusFieldChartName:
+			return rel.Chart.Metadata.Name
+		case watches.StatusFieldChartVersion:
+			return rel.Chart.Metadata.Version
+		case watches.StatusFieldAppVersion:
+			return rel.Chart.Metadata.AppVersion
+		}
+	}
+	return ""
+}
+
 func (r HelmOperatorReconciler) waitForDeletion(o runtime.Object) error {
 	key, err := client.ObjectKeyFromObject(o)
 	if err != nil {
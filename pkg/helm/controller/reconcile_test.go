@@ -15,10 +15,23 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	rpb "helm.sh/helm/v3/pkg/release"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	helmtypes "github.com/operator-framework/operator-sdk/pkg/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/pkg/helm/release"
+	"github.com/operator-framework/operator-sdk/pkg/helm/watches"
 )
 
 func TestHasHelmUpgradeForceAnnotation(t *testing.T) {
@@ -86,3 +99,254 @@ func annotations(m map[string]interface{}) *unstructured.Unstructured {
 		},
 	}
 }
+
+func TestIsPaused(t *testing.T) {
+	tests := []struct {
+		input       map[string]interface{}
+		expectedVal bool
+		name        string
+	}{
+		{
+			input: map[string]interface{}{
+				PausedAnnotation: "true",
+			},
+			expectedVal: true,
+			name:        "paused",
+		},
+		{
+			input: map[string]interface{}{
+				PausedAnnotation: "false",
+			},
+			expectedVal: false,
+			name:        "not paused",
+		},
+		{
+			input:       map[string]interface{}{},
+			expectedVal: false,
+			name:        "annotation not set",
+		},
+		{
+			input: map[string]interface{}{
+				PausedAnnotation: "invalid",
+			},
+			expectedVal: false,
+			name:        "invalid value",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedVal, isPaused(annotations(test.input)), test.name)
+	}
+}
+
+// recordingManagerFactory records whether a release manager was requested,
+// which happens on every reconcile that is not paused.
+type recordingManagerFactory struct {
+	called bool
+}
+
+func (f *recordingManagerFactory) NewManager(*unstructured.Unstructured, map[string]string) (release.Manager, error) {
+	f.called = true
+	return nil, errors.New("no release manager")
+}
+
+func TestReconcilePaused(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+
+	tests := []struct {
+		paused         string
+		expectedResult reconcile.Result
+		expectedCalled bool
+		name           string
+	}{
+		{
+			paused:         "true",
+			expectedResult: reconcile.Result{RequeueAfter: time.Minute},
+			expectedCalled: false,
+			name:           "paused",
+		},
+		{
+			paused:         "false",
+			expectedResult: reconcile.Result{},
+			expectedCalled: true,
+			name:           "resumed",
+		},
+	}
+
+	for _, test := range tests {
+		o := &unstructured.Unstructured{}
+		o.SetGroupVersionKind(gvk)
+		o.SetNamespace(request.Namespace)
+		o.SetName(request.Name)
+		o.SetAnnotations(map[string]string{PausedAnnotation: test.paused})
+
+		factory := &recordingManagerFactory{}
+		r := HelmOperatorReconciler{
+			Client:          fake.NewFakeClient(o),
+			GVK:             gvk,
+			ManagerFactory:  factory,
+			ReconcilePeriod: time.Minute,
+		}
+		result, err := r.Reconcile(request)
+		assert.Equal(t, test.expectedResult, result, test.name)
+		assert.Equal(t, test.expectedCalled, err != nil, test.name)
+		assert.Equal(t, test.expectedCalled, factory.called, test.name)
+	}
+}
+
+// installManager is a release.Manager for a release that is not yet installed.
+type installManager struct {
+	release *rpb.Release
+}
+
+func (m installManager) ReleaseName() string             { return m.release.Name }
+func (m installManager) IsInstalled() bool               { return false }
+func (m installManager) IsUpgradeRequired() bool         { return false }
+func (m installManager) ChangedValues([]string) []string { return nil }
+func (m installManager) Sync(context.Context) error      { return nil }
+func (m installManager) InstallRelease(context.Context, ...release.InstallOption) (*rpb.Release, error) {
+	return m.release, nil
+}
+func (m installManager) UpgradeRelease(context.Context, ...release.UpgradeOption) (*rpb.Release, *rpb.Release, error) {
+	return nil, nil, errors.New("unexpected upgrade")
+}
+func (m installManager) ReconcileRelease(context.Context) (*rpb.Release, error) {
+	return nil, errors.New("unexpected reconcile")
+}
+func (m installManager) UninstallRelease(context.Context, ...release.UninstallOption) (*rpb.Release, error) {
+	return nil, errors.New("unexpected uninstall")
+}
+
+type installManagerFactory struct {
+	release *rpb.Release
+}
+
+func (f installManagerFactory) NewManager(*unstructured.Unstructured, map[string]string) (release.Manager, error) {
+	return installManager{release: f.release}, nil
+}
+
+func TestReconcileStatusFields(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace(request.Namespace)
+	o.SetName(request.Name)
+
+	cl := fake.NewFakeClient(o)
+	r := HelmOperatorReconciler{
+		Client: cl,
+		GVK:    gvk,
+		ManagerFactory: installManagerFactory{release: &rpb.Release{
+			Name:    "nginx",
+			Version: 3,
+			Info:    &rpb.Info{Status: rpb.StatusDeployed},
+		}},
+		StatusFields: map[string]string{
+			watches.StatusFieldRevision: "release.revision",
+			watches.StatusFieldStatus:   "releaseStatus",
+		},
+	}
+	_, err := r.Reconcile(request)
+	assert.NoError(t, err)
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	assert.NoError(t, cl.Get(context.TODO(), request.NamespacedName, actual))
+
+	revision, found, err := unstructured.NestedInt64(actual.Object, "status", "release", "revision")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(3), revision)
+
+	releaseStatus, found, err := unstructured.NestedString(actual.Object, "status", "releaseStatus")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, rpb.StatusDeployed.String(), releaseStatus)
+
+	deployedRelease, found, err := unstructured.NestedString(actual.Object, "status", "deployedRelease", "name")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "nginx", deployedRelease)
+}
+
+// upgradeManager is a release.Manager for an installed release that requires an
+// upgrade changing the values in changed.
+type upgradeManager struct {
+	installManager
+	changed []string
+}
+
+func (m upgradeManager) IsInstalled() bool       { return true }
+func (m upgradeManager) IsUpgradeRequired() bool { return true }
+func (m upgradeManager) ChangedValues(paths []string) (changed []string) {
+	for _, path := range paths {
+		if contains(m.changed, path) {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+type upgradeManagerFactory struct {
+	changed []string
+}
+
+func (f upgradeManagerFactory) NewManager(*unstructured.Unstructured, map[string]string) (release.Manager, error) {
+	return upgradeManager{installManager: installManager{release: &rpb.Release{Name: "nginx"}}, changed: f.changed}, nil
+}
+
+func TestReconcileImmutableValues(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace(request.Namespace)
+	o.SetName(request.Name)
+	o.SetFinalizers([]string{finalizer})
+
+	cl := fake.NewFakeClient(o)
+	recorder := record.NewFakeRecorder(1)
+	r := HelmOperatorReconciler{
+		Client:          cl,
+		EventRecorder:   recorder,
+		GVK:             gvk,
+		ManagerFactory:  upgradeManagerFactory{changed: []string{"image.tag", "persistence.storageClass"}},
+		ReconcilePeriod: time.Minute,
+		ImmutableValues: []string{"persistence.storageClass", "fullnameOverride"},
+		// Mapping a status field writes the status as a map, which the fake client can deep copy.
+		StatusFields: map[string]string{watches.StatusFieldStatus: "releaseStatus"},
+	}
+	// The upgrade is rejected without attempting it, which would return an error.
+	result, err := r.Reconcile(request)
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: time.Minute}, result)
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	assert.NoError(t, cl.Get(context.TODO(), request.NamespacedName, actual))
+	status := helmtypes.StatusFor(actual)
+	var failed *helmtypes.HelmAppCondition
+	for i, c := range status.Conditions {
+		if c.Type == helmtypes.ConditionReleaseFailed {
+			failed = &status.Conditions[i]
+		}
+	}
+	if assert.NotNil(t, failed) {
+		assert.Equal(t, helmtypes.StatusTrue, failed.Status)
+		assert.Equal(t, helmtypes.ReasonImmutableValues, failed.Reason)
+		assert.Contains(t, failed.Message, "persistence.storageClass")
+		assert.NotContains(t, failed.Message, "image.tag")
+	}
+
+	select {
+	case event := <-recorder.Events:
+		assert.Contains(t, event, "Warning ImmutableValuesChanged")
+		assert.Contains(t, event, "persistence.storageClass")
+	default:
+		t.Error("Expected an ImmutableValuesChanged event")
+	}
+}
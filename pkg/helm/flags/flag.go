@@ -21,6 +21,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/operator-framework/operator-sdk/internal/log/zap"
+	"github.com/operator-framework/operator-sdk/internal/ratelimiter"
 )
 
 // Flags - Options to be used by a helm operator
@@ -31,7 +32,10 @@ type Flags struct {
 	EnableLeaderElection    bool
 	LeaderElectionID        string
 	LeaderElectionNamespace string
+	WatchFieldSelector      string
 	MaxConcurrentReconciles int
+	ReconcileQPS            float64
+	ReconcileBurst          int
 }
 
 // AddTo - Add the helm operator flags to the the flagset
@@ -72,4 +76,21 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		runtime.NumCPU(),
 		"Maximum number of concurrent reconciles for controllers.",
 	)
+	flagSet.Float64Var(&f.ReconcileQPS,
+		"reconcile-qps",
+		ratelimiter.DefaultQPS,
+		ratelimiter.QPSUsage,
+	)
+	flagSet.IntVar(&f.ReconcileBurst,
+		"reconcile-burst",
+		ratelimiter.DefaultBurst,
+		ratelimiter.BurstUsage,
+	)
+	flagSet.StringVar(&f.WatchFieldSelector,
+		"watch-field-selector",
+		"",
+		"Field selector, ex. 'metadata.namespace=foo', of custom resources that trigger reconciles. "+
+			"Custom resources only support selecting metadata.name and metadata.namespace. "+
+			"Overridden by a watch's fieldSelector.",
+	)
 }
@@ -72,6 +72,7 @@ const (
 	ReasonUpgradeError        HelmAppConditionReason = "UpgradeError"
 	ReasonReconcileError      HelmAppConditionReason = "ReconcileError"
 	ReasonUninstallError      HelmAppConditionReason = "UninstallError"
+	ReasonImmutableValues     HelmAppConditionReason = "ImmutableValuesChanged"
 )
 
 type HelmAppStatus struct {
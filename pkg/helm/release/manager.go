@@ -34,6 +34,7 @@ import (
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
@@ -48,6 +49,7 @@ type Manager interface {
 	ReleaseName() string
 	IsInstalled() bool
 	IsUpgradeRequired() bool
+	ChangedValues(paths []string) []string
 	Sync(context.Context) error
 	InstallRelease(context.Context, ...InstallOption) (*rpb.Release, error)
 	UpgradeRelease(context.Context, ...UpgradeOption) (*rpb.Release, *rpb.Release, error)
@@ -133,6 +135,35 @@ func (m *manager) Sync(ctx context.Context) error {
 	return nil
 }
 
+// ChangedValues returns the dot-separated value paths in paths whose values differ
+// between the deployed release and the custom resource. It returns nil if the
+// release is not installed. A value that is unset in either is changed unless it is
+// unset in both.
+func (m manager) ChangedValues(paths []string) []string {
+	if m.deployedRelease == nil {
+		return nil
+	}
+	var changed []string
+	for _, path := range paths {
+		fields := strings.Split(path, ".")
+		deployed, deployedFound, _ := unstructured.NestedFieldNoCopy(m.deployedRelease.Config, fields...)
+		current, currentFound, _ := unstructured.NestedFieldNoCopy(m.values, fields...)
+		if deployedFound != currentFound || !equalValues(deployed, current) {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// equalValues compares values by their JSON encoding, since deployed release
+// values are decoded from JSON while custom resource values may not be,
+// ex. 1 may be a float64 in one and an int64 in the other.
+func equalValues(a, b interface{}) bool {
+	aj, aErr := json.Marshal(a)
+	bj, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aj, bj)
+}
+
 func notFoundErr(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "not found")
 }
@@ -0,0 +1,70 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOverrides(t *testing.T) {
+	overrides := map[string]string{
+		"image.repository": "quay.io/example/app",
+		"image.tag":        "v1.0.0",
+		"replicaCount":     "3",
+	}
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "quay.io/example/app",
+			"tag":        "v1.0.0",
+		},
+		"replicaCount": "3",
+	}
+	out, err := parseOverrides(overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, out)
+}
+
+func TestMergeOverridePrecedence(t *testing.T) {
+	crValues := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "docker.io/example/app",
+			"pullPolicy": "Always",
+		},
+		"replicaCount": 1,
+		"service":      "ClusterIP",
+	}
+	overrides, err := parseOverrides(map[string]string{
+		"image.repository": "quay.io/example/app",
+		"replicaCount":     "3",
+		"service.type":     "NodePort",
+	})
+	assert.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "quay.io/example/app",
+			"pullPolicy": "Always",
+		},
+		"replicaCount": "3",
+		"service": map[string]interface{}{
+			"type": "NodePort",
+		},
+	}
+	assert.Equal(t, expected, mergeMaps(crValues, overrides))
+	// The CR values must not be modified by the merge.
+	assert.Equal(t, "docker.io/example/app", crValues["image"].(map[string]interface{})["repository"])
+}
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/stretchr/testify/assert"
+	rpb "helm.sh/helm/v3/pkg/release"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -213,3 +214,24 @@ func TestManagerGenerateStrategicMergePatch(t *testing.T) {
 		assert.Equal(t, test.patch, string(diff))
 	}
 }
+
+func TestManagerChangedValues(t *testing.T) {
+	m := manager{
+		deployedRelease: &rpb.Release{Config: map[string]interface{}{
+			"replicaCount": float64(1),
+			"persistence":  map[string]interface{}{"storageClass": "standard", "size": "1Gi"},
+			"nameOverride": "nginx",
+		}},
+		values: map[string]interface{}{
+			"replicaCount": int64(1),
+			"persistence":  map[string]interface{}{"storageClass": "fast", "size": "1Gi"},
+			"image":        map[string]interface{}{"tag": "1.19"},
+		},
+	}
+	paths := []string{"replicaCount", "persistence.storageClass", "persistence.size", "nameOverride",
+		"image.tag", "unset", "replicaCount.nested"}
+	assert.Equal(t, []string{"persistence.storageClass", "nameOverride", "image.tag"}, m.ChangedValues(paths))
+
+	m.deployedRelease = nil
+	assert.Nil(t, m.ChangedValues(paths))
+}
@@ -15,19 +15,59 @@
 package watches
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 
 	"helm.sh/helm/v3/pkg/chartutil"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/pkg/predicate"
 )
 
 const WatchesFile = "watches.yaml"
 
+// Helm release attributes that can be mapped to custom resource status fields.
+const (
+	StatusFieldName         = "name"
+	StatusFieldNamespace    = "namespace"
+	StatusFieldRevision     = "revision"
+	StatusFieldStatus       = "status"
+	StatusFieldDescription  = "description"
+	StatusFieldNotes        = "notes"
+	StatusFieldChartName    = "chartName"
+	StatusFieldChartVersion = "chartVersion"
+	StatusFieldAppVersion   = "appVersion"
+)
+
+var statusFieldAttributes = map[string]struct{}{
+	StatusFieldName:         {},
+	StatusFieldNamespace:    {},
+	StatusFieldRevision:     {},
+	StatusFieldStatus:       {},
+	StatusFieldDescription:  {},
+	StatusFieldNotes:        {},
+	StatusFieldChartName:    {},
+	StatusFieldChartVersion: {},
+	StatusFieldAppVersion:   {},
+}
+
+// Status fields written by the operator itself, which cannot be mapped to.
+var reservedStatusFields = map[string]struct{}{
+	"conditions":      {},
+	"deployedRelease": {},
+}
+
+var statusPathSegmentRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
 // Watch defines options for configuring a watch for a Helm-based
 // custom resource.
 type Watch struct {
@@ -35,6 +75,15 @@ type Watch struct {
 	ChartDir                string            `json:"chart"`
 	WatchDependentResources *bool             `json:"watchDependentResources,omitempty"`
 	OverrideValues          map[string]string `json:"overrideValues,omitempty"`
+	// StatusFields maps Helm release attributes, ex. "revision", to dot-separated
+	// paths in the custom resource's status, ex. "release.revision".
+	StatusFields map[string]string `json:"statusFields,omitempty"`
+	// FieldSelector selects the custom resources that trigger reconciles,
+	// ex. "metadata.namespace=foo".
+	FieldSelector string `json:"fieldSelector,omitempty"`
+	// ImmutableValues are dot-separated paths of chart values, ex. "persistence.storageClass",
+	// that cannot change once a release is installed. Upgrades changing them are rejected.
+	ImmutableValues []string `json:"immutableValues,omitempty"`
 }
 
 // UnmarshalYAML unmarshals an individual watch from the Helm watches.yaml file
@@ -56,6 +105,53 @@ func (w *Watch) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return unmarshal((*plain)(w))
 }
 
+// UnmarshalJSON unmarshals an individual watch into a Watch struct. Nested
+// maps in overrideValues are flattened into dot-separated keys, ex.
+// "image.repository", so they can be passed to Helm like --set values.
+func (w *Watch) UnmarshalJSON(b []byte) error {
+	// hide watch data in plain struct to prevent unmarshal from calling
+	// UnmarshalJSON again
+	type plain Watch
+	aux := struct {
+		*plain
+		OverrideValues map[string]interface{} `json:"overrideValues,omitempty"`
+	}{plain: (*plain)(w)}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&aux); err != nil {
+		return err
+	}
+	if aux.OverrideValues == nil {
+		w.OverrideValues = nil
+		return nil
+	}
+	w.OverrideValues = make(map[string]string)
+	return flattenOverrides(w.OverrideValues, "", aux.OverrideValues)
+}
+
+func flattenOverrides(out map[string]string, prefix string, in map[string]interface{}) error {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if err := flattenOverrides(out, key, val); err != nil {
+				return err
+			}
+		case string:
+			out[key] = val
+		case json.Number, bool:
+			out[key] = fmt.Sprint(val)
+		default:
+			return fmt.Errorf("override value for key %q must be a scalar or a map, got %T", key, v)
+		}
+	}
+	return nil
+}
+
 // Load loads a slice of Watches from the watch file at `path`. For each entry
 // in the watches file, it verifies the configuration. If an error is
 // encountered loading the file or verifying the configuration, it will be
@@ -102,6 +198,18 @@ func LoadReader(reader io.Reader) ([]Watch, error) {
 			return nil, fmt.Errorf("invalid chart directory %s: %w", w.ChartDir, err)
 		}
 
+		if err := verifyStatusFields(w.StatusFields); err != nil {
+			return nil, fmt.Errorf("invalid status fields for GVK %s: %w", gvk, err)
+		}
+
+		if err := verifyImmutableValues(w.ImmutableValues); err != nil {
+			return nil, fmt.Errorf("invalid immutable values for GVK %s: %w", gvk, err)
+		}
+
+		if _, err := predicate.NewFieldFilterPredicate(w.FieldSelector); err != nil {
+			return nil, fmt.Errorf("invalid field selector for GVK %s: %w", gvk, err)
+		}
+
 		if _, ok := watchesMap[gvk]; ok {
 			return nil, fmt.Errorf("duplicate GVK: %s", gvk)
 		}
@@ -139,3 +247,65 @@ func verifyGVK(gvk schema.GroupVersionKind) error {
 	}
 	return nil
 }
+
+func verifyStatusFields(fields map[string]string) error {
+	attrs := make([]string, 0, len(fields))
+	for attr := range fields {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	paths := make(map[string]string, len(fields))
+	for _, attr := range attrs {
+		path := fields[attr]
+		if _, ok := statusFieldAttributes[attr]; !ok {
+			return fmt.Errorf("unknown release attribute %q", attr)
+		}
+		segments := strings.Split(path, ".")
+		for _, segment := range segments {
+			if !statusPathSegmentRe.MatchString(segment) {
+				return fmt.Errorf("release attribute %q has invalid status path %q", attr, path)
+			}
+		}
+		if _, ok := reservedStatusFields[segments[0]]; ok {
+			return fmt.Errorf("release attribute %q cannot be mapped to reserved status field %q", attr, segments[0])
+		}
+		if other, ok := paths[path]; ok {
+			return fmt.Errorf("release attributes %q and %q are mapped to the same status path %q", other, attr, path)
+		}
+		paths[path] = attr
+	}
+
+	// A path that is a prefix of another would be set to both a value and an
+	// object containing the other value.
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+	for i, path := range sorted {
+		for _, other := range sorted[i+1:] {
+			if strings.HasPrefix(other, path+".") {
+				return fmt.Errorf("release attributes %q and %q are mapped to overlapping status paths %q and %q",
+					paths[path], paths[other], path, other)
+			}
+		}
+	}
+	return nil
+}
+
+func verifyImmutableValues(paths []string) error {
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		for _, segment := range strings.Split(path, ".") {
+			if segment == "" {
+				return fmt.Errorf("invalid value path %q", path)
+			}
+		}
+		if _, ok := seen[path]; ok {
+			return fmt.Errorf("duplicate value path %q", path)
+		}
+		seen[path] = struct{}{}
+	}
+	return nil
+}
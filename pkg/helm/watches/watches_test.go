@@ -76,6 +76,37 @@ func TestLoadReader(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "valid with nested overrides",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  watchDependentResources: false
+  overrideValues:
+    image:
+      repository: ${MY_REPOSITORY}
+      tag: v1.0.0
+    replicaCount: 3
+    enabled: true
+`,
+			env: map[string]string{"MY_REPOSITORY": "quay.io/example/app"},
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &falseVal,
+					OverrideValues: map[string]string{
+						"image.repository": "quay.io/example/app",
+						"image.tag":        "v1.0.0",
+						"replicaCount":     "3",
+						"enabled":          "true",
+					},
+				},
+			},
+			expectErr: false,
+		},
 		{
 			name: "multiple gvk",
 			data: `---
@@ -154,6 +185,178 @@ func TestLoadReader(t *testing.T) {
   overrideValues:
     key1:
 		key2: value
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid list override",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  overrideValues:
+    key:
+    - value
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid status fields",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  statusFields:
+    revision: release.revision
+    notes: releaseNotes
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					StatusFields:            map[string]string{"revision": "release.revision", "notes": "releaseNotes"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "unknown status field attribute",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  statusFields:
+    foo: release.foo
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid status field path",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  statusFields:
+    revision: release..revision
+`,
+			expectErr: true,
+		},
+		{
+			name: "reserved status field path",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  statusFields:
+    revision: deployedRelease.revision
+`,
+			expectErr: true,
+		},
+		{
+			name: "duplicate status field path",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  statusFields:
+    revision: release
+    status: release
+`,
+			expectErr: true,
+		},
+		{
+			name: "overlapping status field paths",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  statusFields:
+    name: release
+    revision: release.revision
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid field selector",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  fieldSelector: metadata.namespace=foo,metadata.name!=bar
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					FieldSelector:           "metadata.namespace=foo,metadata.name!=bar",
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "unsupported field selector field",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  fieldSelector: status.phase=Running
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid immutable values",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  immutableValues:
+  - persistence.storageClass
+  - fullnameOverride
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ImmutableValues:         []string{"persistence.storageClass", "fullnameOverride"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid immutable value path",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  immutableValues:
+  - persistence..storageClass
+`,
+			expectErr: true,
+		},
+		{
+			name: "duplicate immutable value path",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  immutableValues:
+  - fullnameOverride
+  - fullnameOverride
 `,
 			expectErr: true,
 		},
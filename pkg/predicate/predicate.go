@@ -15,7 +15,10 @@
 package predicate
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -30,6 +33,33 @@ type GenerationChangedPredicate struct {
 	predicate.Funcs
 }
 
+// GenerationOrAnnotationChangedPredicate is like GenerationChangedPredicate, but also passes update events
+// that add, remove, or change the value of any of Annotations, ex. an annotation that pauses reconciliation,
+// since annotation changes do not change an object's generation.
+type GenerationOrAnnotationChangedPredicate struct {
+	GenerationChangedPredicate
+	Annotations []string
+}
+
+// Update implements default UpdateEvent filter for validating generation or annotation change
+func (p GenerationOrAnnotationChangedPredicate) Update(e event.UpdateEvent) bool {
+	if p.GenerationChangedPredicate.Update(e) {
+		return true
+	}
+	if e.MetaOld == nil || e.MetaNew == nil {
+		return false
+	}
+	oldAnnotations, newAnnotations := e.MetaOld.GetAnnotations(), e.MetaNew.GetAnnotations()
+	for _, key := range p.Annotations {
+		oldValue, oldOK := oldAnnotations[key]
+		newValue, newOK := newAnnotations[key]
+		if oldOK != newOK || oldValue != newValue {
+			return true
+		}
+	}
+	return false
+}
+
 type ResourceFilterPredicate struct {
 	predicate.Funcs
 	Selector labels.Selector
@@ -87,3 +117,57 @@ func (r ResourceFilterPredicate) Delete(e event.DeleteEvent) bool {
 func (r ResourceFilterPredicate) Generic(e event.GenericEvent) bool {
 	return r.eventFilter(e.Meta.GetLabels())
 }
+
+// Fields of a custom resource that can be selected by a field selector.
+const (
+	FieldMetadataName      = "metadata.name"
+	FieldMetadataNamespace = "metadata.namespace"
+)
+
+// FieldFilterPredicate skips events for objects whose metadata.name and
+// metadata.namespace do not match Selector. Custom resources only support
+// these fields in field selectors.
+type FieldFilterPredicate struct {
+	predicate.Funcs
+	Selector fields.Selector
+}
+
+// NewFieldFilterPredicate parses selector, ex. "metadata.name=foo", into a
+// FieldFilterPredicate. An error is returned if selector is invalid or
+// selects fields other than FieldMetadataName and FieldMetadataNamespace.
+func NewFieldFilterPredicate(selector string) (FieldFilterPredicate, error) {
+	s, err := fields.ParseSelector(selector)
+	if err != nil {
+		return FieldFilterPredicate{}, fmt.Errorf("invalid field selector %q: %w", selector, err)
+	}
+	for _, r := range s.Requirements() {
+		if r.Field != FieldMetadataName && r.Field != FieldMetadataNamespace {
+			return FieldFilterPredicate{}, fmt.Errorf("field %q in field selector %q is not supported for custom resources, "+
+				"only %s and %s are supported", r.Field, selector, FieldMetadataName, FieldMetadataNamespace)
+		}
+	}
+	return FieldFilterPredicate{Selector: s}, nil
+}
+
+func (r FieldFilterPredicate) eventFilter(obj metav1.Object) bool {
+	return r.Selector.Matches(fields.Set{
+		FieldMetadataName:      obj.GetName(),
+		FieldMetadataNamespace: obj.GetNamespace(),
+	})
+}
+
+func (r FieldFilterPredicate) Update(e event.UpdateEvent) bool {
+	return r.eventFilter(e.MetaNew)
+}
+
+func (r FieldFilterPredicate) Create(e event.CreateEvent) bool {
+	return r.eventFilter(e.Meta)
+}
+
+func (r FieldFilterPredicate) Delete(e event.DeleteEvent) bool {
+	return r.eventFilter(e.Meta)
+}
+
+func (r FieldFilterPredicate) Generic(e event.GenericEvent) bool {
+	return r.eventFilter(e.Meta)
+}
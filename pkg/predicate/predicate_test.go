@@ -0,0 +1,146 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestNewFieldFilterPredicate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		selector  string
+		expectErr bool
+	}{
+		{name: "empty selector", selector: ""},
+		{name: "name selector", selector: "metadata.name=foo"},
+		{name: "namespace and name selector", selector: "metadata.namespace==foo,metadata.name!=bar"},
+		{name: "unsupported field", selector: "status.phase=Running", expectErr: true},
+		{name: "invalid syntax", selector: "metadata.name", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewFieldFilterPredicate(tc.selector)
+			if tc.expectErr && err == nil {
+				t.Fatalf("Expected error for selector %q", tc.selector)
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("Unexpected error for selector %q: %v", tc.selector, err)
+			}
+		})
+	}
+}
+
+func TestFieldFilterPredicate(t *testing.T) {
+	p, err := NewFieldFilterPredicate("metadata.namespace=foo,metadata.name!=bar")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		namespace   string
+		objName     string
+		shouldMatch bool
+	}{
+		{name: "matching custom resource", namespace: "foo", objName: "baz", shouldMatch: true},
+		{name: "custom resource in another namespace", namespace: "other", objName: "baz", shouldMatch: false},
+		{name: "custom resource with an excluded name", namespace: "foo", objName: "bar", shouldMatch: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &unstructured.Unstructured{}
+			u.SetNamespace(tc.namespace)
+			u.SetName(tc.objName)
+
+			// Events filtered out by the predicate are never enqueued, so non-matching
+			// custom resources are not reconciled.
+			got := map[string]bool{
+				"create":  p.Create(event.CreateEvent{Meta: u, Object: u}),
+				"update":  p.Update(event.UpdateEvent{MetaOld: u, ObjectOld: u, MetaNew: u, ObjectNew: u}),
+				"delete":  p.Delete(event.DeleteEvent{Meta: u, Object: u}),
+				"generic": p.Generic(event.GenericEvent{Meta: u, Object: u}),
+			}
+			for e, matched := range got {
+				if matched != tc.shouldMatch {
+					t.Errorf("Unexpected %s event filter result: got %v, expected %v", e, matched, tc.shouldMatch)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerationOrAnnotationChangedPredicate(t *testing.T) {
+	const annotation = "ansible.sdk.operatorframework.io/paused"
+	p := GenerationOrAnnotationChangedPredicate{Annotations: []string{annotation}}
+
+	newObj := func(generation int64, annotations map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetName("foo")
+		u.SetGeneration(generation)
+		u.SetAnnotations(annotations)
+		return u
+	}
+
+	testCases := []struct {
+		name        string
+		old, new    *unstructured.Unstructured
+		shouldMatch bool
+	}{
+		{
+			name: "generation change",
+			old:  newObj(1, nil), new: newObj(2, nil),
+			shouldMatch: true,
+		},
+		{
+			name: "annotation added",
+			old:  newObj(1, nil), new: newObj(1, map[string]string{annotation: "true"}),
+			shouldMatch: true,
+		},
+		{
+			name: "annotation removed",
+			old:  newObj(1, map[string]string{annotation: "true"}), new: newObj(1, nil),
+			shouldMatch: true,
+		},
+		{
+			name: "annotation value changed",
+			old:  newObj(1, map[string]string{annotation: "true"}), new: newObj(1, map[string]string{annotation: "false"}),
+			shouldMatch: true,
+		},
+		{
+			name: "other annotation changed",
+			old:  newObj(1, map[string]string{annotation: "true"}),
+			new:  newObj(1, map[string]string{annotation: "true", "foo": "bar"}),
+		},
+		{
+			name: "no change",
+			old:  newObj(1, nil), new: newObj(1, nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := event.UpdateEvent{MetaOld: tc.old, ObjectOld: tc.old, MetaNew: tc.new, ObjectNew: tc.new}
+			if got := p.Update(e); got != tc.shouldMatch {
+				t.Errorf("Unexpected update event filter result: got %v, expected %v", got, tc.shouldMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reconciletest runs a controller's reconciler once against a recorded
+// cluster state, loaded from YAML fixture files into controller-runtime's fake
+// client, for reproducible unit tests and debugging.
+package reconciletest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Fixture is a recorded cluster state in a fake client.
+type Fixture struct {
+	// Client is a fake client containing the fixture's objects. Pass Client to
+	// the reconciler under test.
+	Client client.Client
+
+	scheme *runtime.Scheme
+	// keys of the fixture's objects and objects created through Client,
+	// in order of creation.
+	keys    []objectKey
+	hasKeys map[objectKey]bool
+}
+
+// objectKey identifies an object of any kind.
+type objectKey struct {
+	gvk schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// Result is the outcome of Fixture.Reconcile.
+type Result struct {
+	reconcile.Result
+	// Objects are the fixture's objects and objects created by the reconciler,
+	// in their state after reconcile and in order of creation. Deleted objects
+	// are omitted.
+	Objects []*unstructured.Unstructured
+}
+
+// Load returns a Fixture with the objects in the YAML files at paths.
+// A path may be a file, which may contain multiple YAML documents, or a
+// directory, in which case all .yaml, .yml, and .json files directly in it are
+// loaded in lexical order. Objects of kinds registered in scheme are decoded
+// into their Go types, so all kinds the reconciler reads should be registered.
+func Load(scheme *runtime.Scheme, paths ...string) (*Fixture, error) {
+	var objs []runtime.Object
+	for _, path := range paths {
+		files, err := fixtureFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			fileObjs, err := readObjects(scheme, file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading fixture %s: %v", file, err)
+			}
+			objs = append(objs, fileObjs...)
+		}
+	}
+	return New(scheme, objs...)
+}
+
+// New returns a Fixture with objs.
+func New(scheme *runtime.Scheme, objs ...runtime.Object) (*Fixture, error) {
+	f := &Fixture{scheme: scheme, hasKeys: map[objectKey]bool{}}
+	for _, obj := range objs {
+		if err := f.record(obj); err != nil {
+			return nil, err
+		}
+	}
+	f.Client = &recordingClient{
+		Client:  fake.NewFakeClientWithScheme(scheme, objs...),
+		fixture: f,
+	}
+	return f, nil
+}
+
+// Reconcile calls r's Reconcile once for the object with key, and returns its
+// result with the fixture's objects after reconcile. An error returned by
+// Reconcile is returned with the result.
+func (f *Fixture) Reconcile(r reconcile.Reconciler, key types.NamespacedName) (Result, error) {
+	res, rerr := r.Reconcile(reconcile.Request{NamespacedName: key})
+	objs, err := f.Objects()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Result: res, Objects: objs}, rerr
+}
+
+// Objects returns the current state of the fixture's objects and objects
+// created through f.Client, in order of creation. Deleted objects are omitted.
+func (f *Fixture) Objects() ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	for _, key := range f.keys {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(key.gvk)
+		if err := f.Client.Get(context.TODO(), key.NamespacedName, u); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("error getting %s %s: %v", key.gvk.Kind, key.NamespacedName, err)
+		}
+		objs = append(objs, u)
+	}
+	return objs, nil
+}
+
+// record records obj's key if it is not yet recorded.
+func (f *Fixture) record(obj runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, f.scheme)
+	if err != nil {
+		return err
+	}
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		return err
+	}
+	k := objectKey{gvk: gvk, NamespacedName: key}
+	if !f.hasKeys[k] {
+		f.hasKeys[k] = true
+		f.keys = append(f.keys, k)
+	}
+	return nil
+}
+
+// recordingClient records the keys of objects it creates in its fixture.
+type recordingClient struct {
+	client.Client
+	fixture *Fixture
+}
+
+func (c *recordingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	return c.fixture.record(obj)
+}
+
+// fixtureFiles returns path if it is a file, or the YAML and JSON files in
+// path if it is a directory.
+func fixtureFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, info := range infos {
+		switch filepath.Ext(info.Name()) {
+		case ".yaml", ".yml", ".json":
+			if !info.IsDir() {
+				files = append(files, filepath.Join(path, info.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// readObjects decodes each object in file, into its Go type if registered in
+// scheme and into an unstructured object otherwise.
+func readObjects(scheme *runtime.Scheme, file string) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(b), 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := dec.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				return objs, nil
+			}
+			return nil, err
+		}
+		// Skip empty documents, ex. after a trailing "---".
+		if len(u.Object) == 0 {
+			continue
+		}
+		gvk := u.GroupVersionKind()
+		if gvk.Kind == "" || u.GetName() == "" {
+			return nil, fmt.Errorf("object %d must have a kind and name", len(objs)+1)
+		}
+		if !scheme.Recognizes(gvk) {
+			objs = append(objs, u)
+			continue
+		}
+		obj, err := scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+			return nil, fmt.Errorf("error decoding %s %q: %v", gvk.Kind, u.GetName(), err)
+		}
+		objs = append(objs, obj)
+	}
+}
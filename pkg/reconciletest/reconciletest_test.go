@@ -0,0 +1,170 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciletest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const fixtureYAML = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: default
+data:
+  replicas: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: stale
+  namespace: default
+---
+`
+
+// configMapReconciler copies the data of the reconciled ConfigMap into a
+// Secret, marks the ConfigMap reconciled, and deletes the "stale" ConfigMap.
+type configMapReconciler struct {
+	client client.Client
+	err    error
+}
+
+func (r *configMapReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	ctx := context.TODO()
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, req.NamespacedName, cm); err != nil {
+		return reconcile.Result{}, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cm.Name, Namespace: cm.Namespace},
+		StringData: cm.Data,
+	}
+	if err := r.client.Create(ctx, secret); err != nil {
+		return reconcile.Result{}, err
+	}
+	cm.Data["reconciled"] = "true"
+	if err := r.client.Update(ctx, cm); err != nil {
+		return reconcile.Result{}, err
+	}
+	stale := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: cm.Namespace}}
+	if err := r.client.Delete(ctx, stale); err != nil && !apierrors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{Requeue: true}, r.err
+}
+
+func writeFixture(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	return scheme
+}
+
+func TestReconcile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reconciletest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := writeFixture(t, dir, "app.yaml", fixtureYAML)
+
+	f, err := Load(newScheme(t), path)
+	require.NoError(t, err)
+	r := &configMapReconciler{client: f.Client}
+	res, err := f.Reconcile(r, types.NamespacedName{Namespace: "default", Name: "app"})
+	require.NoError(t, err)
+	assert.True(t, res.Requeue)
+
+	require.Len(t, res.Objects, 2)
+	assert.Equal(t, "ConfigMap", res.Objects[0].GetKind())
+	assert.Equal(t, "app", res.Objects[0].GetName())
+	assert.Equal(t, map[string]interface{}{"replicas": "1", "reconciled": "true"}, res.Objects[0].Object["data"])
+	assert.Equal(t, "Secret", res.Objects[1].GetKind())
+	assert.Equal(t, "app", res.Objects[1].GetName())
+}
+
+func TestReconcileError(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string]string{"replicas": "1"},
+	}
+	f, err := New(newScheme(t), cm)
+	require.NoError(t, err)
+	reconcileErr := errors.New("transient")
+	r := &configMapReconciler{client: f.Client, err: reconcileErr}
+	res, err := f.Reconcile(r, types.NamespacedName{Namespace: "default", Name: "app"})
+	assert.Equal(t, reconcileErr, err)
+	// Object states are returned with the reconcile error.
+	assert.Len(t, res.Objects, 2)
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reconciletest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeFixture(t, dir, "a.yaml", fixtureYAML)
+	writeFixture(t, dir, "b.json", `{"apiVersion": "cache.example.com/v1alpha1", "kind": "Memcached",
+		"metadata": {"name": "example", "namespace": "default"}, "spec": {"size": 3}}`)
+	writeFixture(t, dir, "README.md", "not a fixture")
+
+	f, err := Load(newScheme(t), dir)
+	require.NoError(t, err)
+	objs, err := f.Objects()
+	require.NoError(t, err)
+	require.Len(t, objs, 3)
+	assert.Equal(t, []string{"app", "stale", "example"},
+		[]string{objs[0].GetName(), objs[1].GetName(), objs[2].GetName()})
+	assert.Equal(t, "Memcached", objs[2].GetKind())
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, f.Client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "app"}, cm))
+	assert.Equal(t, "1", cm.Data["replicas"])
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reconciletest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = Load(newScheme(t), filepath.Join(dir, "missing.yaml"))
+	assert.True(t, os.IsNotExist(err))
+
+	path := writeFixture(t, dir, "nameless.yaml", "apiVersion: v1\nkind: ConfigMap\n")
+	_, err = Load(newScheme(t), path)
+	assert.EqualError(t, err, "error reading fixture "+path+": object 1 must have a kind and name")
+
+	path = writeFixture(t, dir, "invalid.yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata: [1]\n")
+	_, err = Load(newScheme(t), path)
+	assert.Error(t, err)
+}